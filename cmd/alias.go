@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/alias"
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/profile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// Execute runs the command of args. An alias of the config file is expanded first, the commands of a chained alias run
+// one after another until one of them fails.
+func Execute(args []string) error {
+	commands, err := expandAliases(args)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	for _, args := range commands {
+		if len(commands) > 1 {
+			shared.Logger.Println(styles.Subtlef("$ space %s", strings.Join(args, " ")))
+		}
+		// every command gets a new tree, so that no flags are left over from the previous command
+		root := NewSpaceCmd()
+		root.SetArgs(args)
+		err := root.Execute()
+		// failed commands are profiled as well, cobra skips the post run hooks if a command fails
+		reportProfile()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandAliases expands the first argument if it's an alias of the config file, aliases can't replace commands
+func expandAliases(args []string) ([][]string, error) {
+	c, err := config.Load()
+	if err != nil || len(c.Aliases) == 0 {
+		return [][]string{args}, nil
+	}
+
+	commands := make(map[string]bool)
+	for _, command := range NewSpaceCmd().Commands() {
+		for _, name := range append([]string{command.Name()}, command.Aliases...) {
+			commands[name] = true
+		}
+	}
+	// cobra adds these commands on execution
+	commands["help"], commands[cobra.ShellCompRequestCmd], commands[cobra.ShellCompNoDescRequestCmd] = true, true, true
+
+	aliases := make(map[string]string)
+	for name, expansion := range c.Aliases {
+		if !commands[name] {
+			aliases[name] = expansion
+		}
+	}
+	return alias.Expand(aliases, args)
+}
+
+// reportProfile prints where the time of the command was spent if it ran with --profile
+func reportProfile() {
+	if !profile.Enabled() {
+		return
+	}
+	shared.Logger.Printf("\n%s Time spent:\n", emoji.Stopwatch)
+	profile.Report(shared.Logger.Writer())
+	profile.Reset()
+}
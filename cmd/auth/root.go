@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdAuth() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Inspect the access token and sessions of your account",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdAuthScopes())
+	cmd.AddCommand(newCmdAuthSessions())
+
+	return cmd
+}
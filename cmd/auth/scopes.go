@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdAuthScopes() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scopes",
+		Short: "Show what the access token of the CLI can do",
+		Long: `Show the kind, scopes and projects of the access token of the CLI, and the commands it can run.
+
+Account tokens, like the token of space login, can do anything your account can. CI pipelines should use a project token with only the scopes they need, commands which run in CI with an account token warn about it.`,
+		Example: `  space auth scopes
+  SPACE_ACCESS_TOKEN=$CI_TOKEN space auth scopes --output json`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := showScopes(); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+type scopesOutput struct {
+	*api.TokenInfo
+	// Commands the token can run
+	Commands []string `json:"commands"`
+}
+
+func showScopes() error {
+	info, err := shared.Client.GetTokenInfo()
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoAccessTokenFound):
+			shared.Logger.Println(shared.LoginInfo())
+		case errors.Is(err, api.ErrTokenInfoUnsupported):
+			shared.Logger.Println(styles.Errorf("%s The scopes of access tokens can't be shown yet.", emoji.ErrorExclamation))
+		default:
+			shared.Logger.Println(styles.Errorf("%s Failed to get the access token: %v", emoji.ErrorExclamation, err))
+		}
+		return err
+	}
+	commands := auth.ScopedCommands(info.Scopes)
+
+	if shared.JSONOutput() {
+		return shared.PrintJSON(&scopesOutput{TokenInfo: info, Commands: commands})
+	}
+
+	name := "Access token"
+	if info.Name != "" {
+		name += " " + styles.Blue(info.Name)
+	}
+	shared.Logger.Printf("%s %s (%s token)\n", emoji.Key, name, info.Kind)
+	scopes := make([]string, len(info.Scopes))
+	for i, scope := range info.Scopes {
+		scopes[i] = string(scope)
+	}
+	shared.Logger.Printf("  %s %s", styles.Subtle("Scopes:"), strings.Join(scopes, ", "))
+	if len(info.Projects) > 0 {
+		shared.Logger.Printf("  %s %s", styles.Subtle("Projects:"), strings.Join(info.Projects, ", "))
+	} else {
+		shared.Logger.Printf("  %s all projects of the account", styles.Subtle("Projects:"))
+	}
+	if info.ExpiresAt != "" {
+		shared.Logger.Printf("  %s %s", styles.Subtle("Expires:"), info.ExpiresAt)
+	}
+
+	if info.Kind == auth.TokenAccount {
+		shared.Logger.Printf("\nThe token can do anything your account can. Use a project token with only the scopes a pipeline needs in CI, e.g. %s for %s and %s.", styles.Code(string(auth.ScopeProjectsWrite)), styles.Code("space push"), styles.Code("space release"))
+		return nil
+	}
+	if len(commands) == 0 {
+		shared.Logger.Printf("\nThe token can't run any command of the CLI.")
+		return nil
+	}
+	shared.Logger.Printf("\nCommands the token can run:")
+	for _, command := range commands {
+		shared.Logger.Printf("  %s", styles.Code("space "+command))
+	}
+	return nil
+}
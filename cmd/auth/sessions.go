@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/pkg/components/confirm"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdAuthSessions() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions [flags]",
+		Short: "List the active sessions of your account",
+		Long: `List the active sessions of your account: the access tokens of your logins and CI pipelines, with the device they were created on and when they were last used.
+
+Revoke a session you don't recognize with space auth sessions revoke, its access token stops working immediately.`,
+		Example: `  space auth sessions
+  space auth sessions --output json`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := listSessions(); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newCmdAuthSessionsRevoke())
+
+	return cmd
+}
+
+func newCmdAuthSessionsRevoke() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [session-id]... [flags]",
+		Short: "Revoke sessions of your account",
+		Long: `Revoke sessions of your account by their id, their access tokens stop working immediately.
+
+--all revokes all sessions except the one of the CLI, e.g. after a token leaked. Revoking the session of the CLI removes its stored access token, log in again with space login.`,
+		Example: `  space auth sessions revoke 8f2c1a
+  space auth sessions revoke --all`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			if all == (len(args) > 0) {
+				return errors.New("pass the ids of the sessions to revoke or --all")
+			}
+			return nil
+		},
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			if err := revokeSessions(args, all); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "revoke all sessions except the one of the CLI")
+
+	return cmd
+}
+
+func fetchSessions() ([]*api.Session, error) {
+	sessions, err := shared.Client.ListSessions()
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+		} else {
+			shared.Logger.Println(styles.Errorf("%s Failed to list sessions: %v", emoji.ErrorExclamation, err))
+		}
+		return nil, err
+	}
+	// the sessions used last first
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt > sessions[j].LastUsedAt
+	})
+	return sessions, nil
+}
+
+func listSessions() error {
+	sessions, err := fetchSessions()
+	if err != nil {
+		return err
+	}
+	if shared.JSONOutput() {
+		return shared.PrintJSON(sessions)
+	}
+
+	shared.Logger.Printf("%s Active sessions:\n", emoji.Key)
+	for _, s := range sessions {
+		name := s.Name
+		if name == "" {
+			name = "unnamed"
+		}
+		line := styles.Code(s.ID) + " " + name + styles.Subtle(" ("+string(s.Kind)+" token)")
+		if s.Current {
+			line += " " + styles.Green("this CLI")
+		}
+		shared.Logger.Printf("  %s", line)
+
+		var details []string
+		if s.Device != "" {
+			details = append(details, s.Device)
+		}
+		details = append(details, "created "+s.CreatedAt)
+		switch {
+		case s.LastUsedAt == "":
+			details = append(details, "never used")
+		case s.LastUsedIP != "":
+			details = append(details, fmt.Sprintf("last used %s from %s", s.LastUsedAt, s.LastUsedIP))
+		default:
+			details = append(details, "last used "+s.LastUsedAt)
+		}
+		shared.Logger.Printf("    %s", styles.Subtle(strings.Join(details, ", ")))
+	}
+	shared.Logger.Printf("\nRevoke a session with %s", styles.Code("space auth sessions revoke <session-id>"))
+	return nil
+}
+
+func revokeSessions(ids []string, all bool) error {
+	sessions, err := fetchSessions()
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*api.Session)
+	for _, s := range sessions {
+		byID[s.ID] = s
+	}
+
+	var revoke []*api.Session
+	if all {
+		for _, s := range sessions {
+			if !s.Current {
+				revoke = append(revoke, s)
+			}
+		}
+	} else {
+		for _, id := range ids {
+			s, ok := byID[id]
+			if !ok {
+				shared.Logger.Println(styles.Errorf("%s Session %s doesn't exist, list the sessions with %s", emoji.ErrorExclamation, id, styles.Code("space auth sessions")))
+				return api.ErrSessionNotFound
+			}
+			revoke = append(revoke, s)
+		}
+	}
+	if len(revoke) == 0 {
+		shared.Logger.Printf("%s There are no other sessions", emoji.Check)
+		return nil
+	}
+
+	current := false
+	for _, s := range revoke {
+		current = current || s.Current
+	}
+	if shared.CanPrompt() && (all || current) {
+		prompt := fmt.Sprintf("Revoke %d sessions?", len(revoke))
+		if current {
+			prompt = "Revoke the session of this CLI? You'll have to log in again."
+		}
+		ok, err := confirm.Run("auth.sessions.revoke", prompt)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, s := range revoke {
+		if err := shared.Client.RevokeSession(s.ID); err != nil && !errors.Is(err, api.ErrSessionNotFound) {
+			shared.Logger.Printf("%s Failed to revoke %s: %s", emoji.ErrorExclamation, s.ID, err)
+			failed++
+			continue
+		}
+		shared.Logger.Printf("%s Revoked %s %s", emoji.Check, styles.Code(s.ID), s.Name)
+	}
+	if failed > 0 {
+		shared.Logger.Println(styles.Errorf("\n%s Failed to revoke %d of %d sessions", emoji.ErrorExclamation, failed, len(revoke)))
+		return fmt.Errorf("%d sessions failed", failed)
+	}
+
+	if current {
+		if err := auth.DeleteStoredAccessToken(); err != nil {
+			shared.Logger.Printf("%s Failed to remove the revoked access token: %v", emoji.Warning, err)
+		}
+		shared.Logger.Printf("\nThe CLI is logged out, log in again with %s", styles.Code("space login"))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/artifactstore"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/util/fs"
+	"github.com/spf13/cobra"
+)
+
+func newCmdCacheClear() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached API responses and archives",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := runtime.ClearCache()
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to clear the cache: %v", emoji.ErrorExclamation, err))
+				return err
+			}
+			store, err := artifactstore.Open()
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to clear the artifact store: %v", emoji.ErrorExclamation, err))
+				return err
+			}
+			archives, size, err := store.Clear()
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to clear the artifact store: %v", emoji.ErrorExclamation, err))
+				return err
+			}
+			shared.Logger.Printf("%s Cleared %d cached responses and %d archives (%s)", emoji.Check, n, archives, fs.FormatSize(size))
+			return nil
+		},
+	}
+
+	return cmd
+}
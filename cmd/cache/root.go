@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdCache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache of API responses and archives",
+		Long: `Manage the local cache of API responses and archives.
+
+Data that rarely changes, like your projects, their revisions, the regions and the latest CLI version, is cached in the cache directory of the global state for a short time. Use --no-cache to ignore the cache for a single command.
+
+The archives of your pushes are kept in the artifact store, ~/.cache/space/artifacts on Linux or $SPACE_ARTIFACTS_DIR, keyed by the digest of their files. Pushing the same files again, e.g. after switching back to a branch, reuses the archive and the code uploaded before. The least recently used archives are removed once the store exceeds 1 GB.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdCacheClear())
+
+	return cmd
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/calendar"
+	"github.com/deta/space/internal/cron"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxCalendarRuns limits the runs shown per job or action, an action running every minute would fill the calendar
+	maxCalendarRuns    = 5
+	calendarDateLayout = "2006-01-02"
+)
+
+func newCmdCalendar() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "calendar [flags]",
+		Short: "Show the upcoming freeze windows, scheduled jobs and actions and releases of your project",
+		Long: `Show a calendar of the days from --from, today by default, with the events of your project:
+
+- the freeze windows of the project config
+- the runs of the jobs of the jobs file of space cron run
+- the runs of the scheduled actions of the Spacefile at their default interval
+- the releases of the project created in the time range
+
+Space doesn't schedule releases, so past releases are only shown if --from is in the past. At most the next ` + fmt.Sprint(maxCalendarRuns) + ` runs of a job or action are shown.`,
+		Example: `  space calendar
+  space calendar --days 30 --jobs ops/jobs.yaml
+  space calendar --from 2023-12-01 --environment production`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			jobsPath, _ := cmd.Flags().GetString("jobs")
+			days, _ := cmd.Flags().GetInt("days")
+			fromDate, _ := cmd.Flags().GetString("from")
+
+			now := time.Now()
+			from := now
+			if fromDate != "" {
+				var err error
+				if from, err = time.ParseInLocation(calendarDateLayout, fromDate, time.Local); err != nil {
+					shared.Logger.Printf("%s Invalid date %s, use YYYY-MM-DD", emoji.ErrorExclamation, fromDate)
+					return err
+				}
+			}
+			if days < 1 {
+				shared.Logger.Printf("%s days must be at least 1", emoji.ErrorExclamation)
+				return shared.ErrReported
+			}
+			to := time.Date(from.Year(), from.Month(), from.Day()+days, 0, 0, 0, 0, from.Location()).Add(-time.Nanosecond)
+
+			var events []*calendar.Event
+			config, err := spaceconfig.Load(projectDir)
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+			events = append(events, freezeEvents(config, from, to)...)
+
+			// the runs of jobs and actions are never in the past
+			runsFrom := from
+			if runsFrom.Before(now) {
+				runsFrom = now
+			}
+			jobEvents, err := jobEvents(projectDir, jobsPath, cmd.Flags().Changed("jobs"), runsFrom, to)
+			if err != nil {
+				return err
+			}
+			events = append(events, jobEvents...)
+			events = append(events, actionEvents(projectDir, runsFrom, to)...)
+
+			// releases need a project, without one the calendar is still useful
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			if _, linkErr := runtime.GetProjectID(projectDir); projectID != "" || environment != "" || linkErr == nil || len(config.Environments) > 0 {
+				if projectID, err = shared.ResolveProjectID(projectDir, projectID, environment); err != nil {
+					return err
+				}
+				events = append(events, releaseEvents(projectID, from, to)...)
+			}
+
+			printCalendar(events, from, to)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of the project")
+	cmd.Flags().StringP("id", "i", "", "project id of the project to show the releases of")
+	cmd.Flags().String("environment", "", "environment of the project config to show the releases of, defaults to the environment of the current branch")
+	cmd.Flags().String("jobs", "jobs.yaml", "path of the jobs file of space cron run, a missing default file is skipped")
+	cmd.Flags().Int("days", 14, "number of days to show")
+	cmd.Flags().String("from", "", "first day to show as YYYY-MM-DD, defaults to today")
+
+	return cmd
+}
+
+func freezeEvents(config *spaceconfig.Config, from time.Time, to time.Time) []*calendar.Event {
+	var events []*calendar.Event
+	for _, w := range config.Freeze {
+		for _, p := range w.Periods(from, to) {
+			events = append(events, &calendar.Event{
+				Kind:   calendar.Freeze,
+				Name:   w.String(),
+				Start:  p.Start,
+				End:    p.End,
+				AllDay: true,
+			})
+		}
+	}
+	return events
+}
+
+func jobEvents(projectDir string, jobsPath string, explicit bool, from time.Time, to time.Time) ([]*calendar.Event, error) {
+	if _, err := os.Stat(jobsPath); !explicit && errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	jobs, err := cron.LoadJobs(jobsPath)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	state, err := cron.LoadState(cron.StatePath(projectDir))
+	if err != nil {
+		shared.Logger.Printf("%s Failed to load cron state: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	var events []*calendar.Event
+	for _, job := range jobs.Jobs {
+		for _, run := range job.Runs(state, from, to, maxCalendarRuns) {
+			events = append(events, &calendar.Event{Kind: calendar.Job, Name: job.Name, Detail: job.Schedule, Start: run})
+		}
+	}
+	return events, nil
+}
+
+// actionEvents returns the runs of the scheduled actions at their default interval, the interval of an installed app
+// can be changed in Space, so they are only a hint
+func actionEvents(projectDir string, from time.Time, to time.Time) []*calendar.Event {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		if !errors.Is(err, spacefile.ErrSpacefileNotFound) {
+			shared.Logger.Printf("%s Skipping scheduled actions: %v", emoji.Warning, err)
+		}
+		return nil
+	}
+
+	var events []*calendar.Event
+	for _, micro := range s.Micros {
+		for _, action := range micro.Actions {
+			if action.Trigger != "schedule" {
+				continue
+			}
+			name := fmt.Sprintf("%s/%s", micro.Name, action.ID)
+			schedule, err := cron.Parse(action.Interval)
+			if err != nil {
+				shared.Logger.Printf("%s Skipping action %s: %v", emoji.Warning, name, err)
+				continue
+			}
+			for _, run := range cron.Occurrences(schedule, from, to, maxCalendarRuns) {
+				events = append(events, &calendar.Event{Kind: calendar.Action, Name: name, Detail: action.Interval, Start: run})
+			}
+		}
+	}
+	return events
+}
+
+func releaseEvents(projectID string, from time.Time, to time.Time) []*calendar.Event {
+	r, err := shared.Client.ListReleases(&api.ListReleasesRequest{AppID: projectID})
+	if err != nil {
+		shared.Logger.Printf("%s Skipping releases: %v", emoji.Warning, err)
+		return nil
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	var events []*calendar.Event
+	for _, release := range r.Releases {
+		createdAt, err := time.Parse(time.RFC3339, release.CreatedAt)
+		if err != nil || createdAt.Before(start) || createdAt.After(to) {
+			continue
+		}
+		events = append(events, &calendar.Event{Kind: calendar.Release, Name: release.Version, Detail: release.Status, Start: createdAt})
+	}
+	return events
+}
+
+func printCalendar(events []*calendar.Event, from time.Time, to time.Time) {
+	shared.Logger.Printf("%s Calendar from %s to %s", emoji.Stopwatch, from.Format(calendarDateLayout), to.Format(calendarDateLayout))
+	days := calendar.Group(events, time.Local)
+	if len(days) == 0 {
+		shared.Logger.Printf("\nNothing is scheduled, no freeze windows, jobs, scheduled actions or releases")
+		return
+	}
+
+	for _, day := range days {
+		shared.Logger.Printf("\n%s", styles.Bold(day.Date.Format("Mon 2006-01-02")))
+		for _, e := range day.Events {
+			when := e.Start.In(time.Local).Format("15:04")
+			if e.AllDay {
+				when = "all day"
+			}
+			line := fmt.Sprintf("  %-7s  %-7s  %s", when, e.Kind, e.Name)
+			if e.AllDay && e.End.After(e.Start) {
+				line += styles.Subtle(" until " + e.End.Format("Mon 2006-01-02"))
+			}
+			if e.Detail != "" {
+				line += styles.Subtle(" (" + e.Detail + ")")
+			}
+			if e.Kind == calendar.Freeze {
+				line = styles.Errorf("%s", line)
+			}
+			shared.Logger.Println(line)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package ci
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/container"
+	"github.com/deta/space/internal/home"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// writeCheckFile is created and removed again to check that the global state is writable
+const writeCheckFile = ".write_check"
+
+func newCmdDockerEntrypoint() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docker-entrypoint [flags] [-- space command]",
+		Short: "Validate the environment of a container and run a space command",
+		Long: fmt.Sprintf(`Validate the environment of a container and run a space command.
+
+Checks that an access token is provided, that all variables passed with --require are set and that the global state can be written, then runs the given space command. Fails at startup instead of in the middle of a command if the container is misconfigured.
+
+In containers the access token is only read from:
+  - the %s environment variable
+  - the file in the %s environment variable
+  - the secret mounted at %s
+
+Global state is written to %s, set %s=1 to not write any state.`,
+			styles.Code("SPACE_ACCESS_TOKEN"), styles.Code(auth.SpaceAccessTokenFileEnv), styles.Code(auth.DefaultSecretPath),
+			styles.Code(home.HomeEnv), styles.Code(home.NoStateEnv)),
+		Example: `  space ci docker-entrypoint -- push --tag $CI_COMMIT_SHA
+  space ci docker-entrypoint --require DATABASE_URL -- release --version 1.0.0`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			required, _ := cmd.Flags().GetStringSlice("require")
+
+			if err := validateEnv(required); err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				return nil
+			}
+			return runSpace(args)
+		},
+	}
+
+	cmd.Flags().StringSlice("require", nil, "environment variable which must be set, can be repeated")
+	cmd.Flags().SetInterspersed(false)
+
+	return cmd
+}
+
+func validateEnv(required []string) error {
+	failed := false
+	check := func(name string, err error) {
+		if err != nil {
+			failed = true
+			shared.Logger.Printf("%s %s: %s", emoji.X, name, styles.Error(err.Error()))
+			return
+		}
+		shared.Logger.Printf("%s %s", emoji.Check, name)
+	}
+
+	if !container.Dockerized() {
+		shared.Logger.Printf("%s Not running in a container, set %s=1 to force the dockerized mode", emoji.Warning, container.DockerizedEnv)
+	}
+
+	_, err := auth.GetAccessToken()
+	if errors.Is(err, auth.ErrNoAccessTokenFound) {
+		err = fmt.Errorf("set SPACE_ACCESS_TOKEN or mount a secret at %s", auth.DefaultSecretPath)
+	}
+	check("access token", err)
+
+	for _, name := range required {
+		var err error
+		if os.Getenv(name) == "" {
+			err = errors.New("not set")
+		}
+		check(name, err)
+	}
+
+	if home.NoState() {
+		shared.Logger.Printf("%s global state disabled", emoji.Check)
+	} else {
+		check("global state writable", checkStateWritable())
+	}
+
+	if failed {
+		shared.Logger.Println(styles.Errorf("\n%s The container is not configured correctly", emoji.ErrorExclamation))
+		return errors.New("invalid environment")
+	}
+	return nil
+}
+
+func checkStateWritable() error {
+	path, err := home.PrepareWrite(writeCheckFile)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		return home.WrapWriteError(err)
+	}
+	return os.Remove(path)
+}
+
+// runSpace runs the space binary with args, a failed run is returned as is so that the cli exits with its exit code
+func runSpace(args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		shared.Logger.Printf("%s Failed to find the space binary: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			shared.Logger.Printf("%s Failed to run space: %s", emoji.ErrorExclamation, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package ci
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdCI() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Helpers for running Space CLI in CI and containers",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdDockerEntrypoint())
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/completion"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdInstall returns the install command, it is added to the completion command of cobra
+func NewCmdInstall() *cobra.Command {
+	shells := make([]string, len(completion.Shells))
+	for i, shell := range completion.Shells {
+		shells[i] = string(shell)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "install [flags]",
+		Short: "Install the completion script for your shell",
+		Long: fmt.Sprintf(`Install the completion script for your shell.
+
+Your shell is detected from $SHELL unless it's set with --shell. The script is written to the completions directory of the global state and loaded from your shell profile, fish loads it from its completions directory instead. Replaced files are backed up next to them with the time of the backup and a .bak suffix, existing backups are kept, and the completion is verified by starting your shell with its profile.
+
+Supported shells: %s`, strings.Join(shells, ", ")),
+		Args:    cobra.NoArgs,
+		PreRunE: shared.CheckOneOf("shell", append(shells, "pwsh")...),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shellName, _ := cmd.Flags().GetString("shell")
+			noVerify, _ := cmd.Flags().GetBool("no-verify")
+
+			shell, err := completion.Detect(os.Getenv, runtime.GOOS)
+			if shellName != "" {
+				shell, err = completion.ParseShell(shellName)
+			}
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s %s, choose one with %s", emoji.ErrorExclamation, err, styles.Code("--shell")))
+				return err
+			}
+
+			result, err := completion.Install(cmd.Root(), shell)
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to install the %s completion: %v", emoji.ErrorExclamation, shell, err))
+				return err
+			}
+			shared.Logger.Printf("%s Wrote the %s completion script to %s", emoji.Check, shell, styles.Code(result.Script))
+			if result.Profile != "" {
+				shared.Logger.Printf("%s Loaded it in %s", emoji.Check, styles.Code(result.Profile))
+			}
+			for _, backup := range result.Backups {
+				shared.Logger.Printf("%s Backed up the previous version to %s", styles.Info, styles.Code(backup))
+			}
+
+			if noVerify {
+				return nil
+			}
+			if err := completion.Verify(shell); err != nil {
+				if errors.Is(err, completion.ErrShellNotFound) {
+					shared.Logger.Printf("%s Couldn't verify the completion, %v", emoji.Warning, err)
+					return nil
+				}
+				shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+				return err
+			}
+			shared.Logger.Printf("%s Verified that %s loads the completion, open a new shell to use it", emoji.Sparkles, shell)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("shell", "", fmt.Sprintf("shell to install the completion for, one of %s, defaults to your shell", strings.Join(shells, ", ")))
+	cmd.Flags().Bool("no-verify", false, "don't start the shell to verify the completion")
+
+	return cmd
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdConfig() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Get and set the defaults of the CLI in the config file",
+		Long: fmt.Sprintf(`Get and set the defaults of the CLI in the config file, $%s/space/config.json or ~/.config/space/config.json. The config file of older versions in ~/.detaspace is used until the new one exists.
+
+Flags override the config file, which overrides the built-in defaults:
+
+  default_project         project of commands run outside of a project directory without --id
+  output                  format of the result, text or json, like --output
+  no_color                don't color the output, like --no-color
+  api_endpoint            root of the Space API, e.g. for a proxy
+  api_retries             how often failed API requests are retried, 0 disables retries
+  version_check_interval  how often the latest version is checked, e.g. 12h, 7d or never
+  editor                  editor for release notes and the Discovery file, e.g. "code --wait"
+  language                language of the messages, e.g. de
+  handle                  your Space handle, checked against the approver of releases
+  accessible, notify, terminal_title, bell, disable_clipboard
+
+Aliases are edited in the config file.`, config.XDGConfigHomeEnv),
+		Example: `  space config set default_project a0b1c2d3
+  space config set output json
+  space config get api_endpoint
+  space config unset no_color`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdConfigGet())
+	cmd.AddCommand(newCmdConfigSet())
+	cmd.AddCommand(newCmdConfigUnset())
+	cmd.AddCommand(newCmdConfigList())
+
+	return cmd
+}
+
+func newCmdConfigGet() *cobra.Command {
+	return &cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print the value of a key, nothing if it isn't set",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			value, err := c.Get(args[0])
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
+				return err
+			}
+			if shared.JSONOutput() {
+				return shared.PrintJSON(map[string]string{"key": args[0], "value": value})
+			}
+			if value != "" {
+				fmt.Fprintln(cmd.OutOrStdout(), value)
+			}
+			return nil
+		},
+	}
+}
+
+func newCmdConfigSet() *cobra.Command {
+	return &cobra.Command{
+		Use:               "set <key> <value>",
+		Short:             "Set the value of a key",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfig(func(c *config.Config) error {
+				return c.Set(args[0], args[1])
+			}, fmt.Sprintf("Set %s to %s", styles.Code(args[0]), styles.Code(args[1])))
+		},
+	}
+}
+
+func newCmdConfigUnset() *cobra.Command {
+	return &cobra.Command{
+		Use:               "unset <key>",
+		Short:             "Remove a key, so that its default applies",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfig(func(c *config.Config) error {
+				return c.Unset(args[0])
+			}, fmt.Sprintf("Unset %s", styles.Code(args[0])))
+		},
+	}
+}
+
+func newCmdConfigList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the keys which are set",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			values := make(map[string]string)
+			for _, key := range config.Keys() {
+				if value, _ := c.Get(key); value != "" {
+					values[key] = value
+				}
+			}
+			if shared.JSONOutput() {
+				return shared.PrintJSON(values)
+			}
+			if path, err := config.Path(); err == nil {
+				shared.Logger.Println(styles.Subtle(path))
+			}
+			for _, key := range config.Keys() {
+				if value, ok := values[key]; ok {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", key, value)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// updateConfig changes the config file with update and reports it with the message
+func updateConfig(update func(c *config.Config) error, message string) error {
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := update(c); err != nil {
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
+		return err
+	}
+	if err := c.Save(); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to write the config file: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	path, _ := config.Path()
+	shared.Logger.Printf("%s %s in %s", emoji.Check, message, styles.Subtle(path))
+	return nil
+}
+
+func loadConfig() (*config.Config, error) {
+	c, err := config.Load()
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
+		return nil, err
+	}
+	return c, nil
+}
+
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.Keys(), cobra.ShellCompDirectiveNoFileComp
+}
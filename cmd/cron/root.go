@@ -0,0 +1,19 @@
+package cron
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdCron() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cron",
+		Short: "Run local maintenance jobs against your project data",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdCronRun())
+
+	return cmd
+}
@@ -0,0 +1,187 @@
+package cron
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/cron"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/writer"
+	"github.com/spf13/cobra"
+	"mvdan.cc/sh/v3/shell"
+)
+
+func newCmdCronRun() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run [flags]",
+		Short: "Run the jobs declared in a jobs file on their schedule",
+		Long: `Run the jobs declared in a jobs file on their schedule.
+
+Jobs run locally with the project's data key injected as DETA_PROJECT_KEY, which makes them a good fit for maintenance tasks that scheduled actions can't perform, like syncing data between projects.
+
+By default the command keeps running and executes every job when it is due. Pass --due to only run the jobs that are currently due and exit, e.g. from a system crontab or a CI schedule.
+
+Example jobs file:
+
+  jobs:
+    - name: cleanup
+      schedule: "0 3 * * *"
+      command: python scripts/cleanup.py
+    - name: mirror
+      schedule: "@every 30m"
+      command: ./mirror.sh
+      project: <project id>
+
+Schedules are standard five field cron expressions, @hourly/@daily/@weekly/@monthly or "@every <duration>". Jobs that never ran before are due immediately.`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckExists("dir"),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			jobsPath, _ := cmd.Flags().GetString("file")
+			dueOnly, _ := cmd.Flags().GetBool("due")
+
+			if err := cronRun(projectDir, jobsPath, dueOnly); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of the project the jobs belong to")
+	cmd.Flags().StringP("file", "f", "jobs.yaml", "path of the jobs file")
+	cmd.Flags().Bool("due", false, "run the jobs which are due once and exit")
+
+	return cmd
+}
+
+func cronRun(projectDir string, jobsPath string, dueOnly bool) error {
+	jobs, err := cron.LoadJobs(jobsPath)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	statePath := cron.StatePath(projectDir)
+	state, err := cron.LoadState(statePath)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to load cron state: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	if dueOnly {
+		due := jobs.Due(state, time.Now())
+		if len(due) == 0 {
+			shared.Logger.Printf("%s No jobs are due", styles.Info)
+			return nil
+		}
+		return runJobs(projectDir, due, state, statePath)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	shared.Logger.Printf("%s Running %d jobs from %s, press Ctrl+C to stop", emoji.Laptop, len(jobs.Jobs), styles.Code(jobsPath))
+	for {
+		now := time.Now()
+		if due := jobs.Due(state, now); len(due) > 0 {
+			// errors are reported per job, keep the scheduler running
+			runJobs(projectDir, due, state, statePath)
+			continue
+		}
+
+		next := jobs.NextWakeup(state, now)
+		if next.IsZero() {
+			shared.Logger.Printf("%s No job will ever be due again, exiting", styles.Info)
+			return nil
+		}
+
+		select {
+		case <-time.After(time.Until(next)):
+		case <-sigs:
+			shared.Logger.Printf("\n\nShutting down...\n\n")
+			return nil
+		}
+	}
+}
+
+func runJobs(projectDir string, jobs []*cron.Job, state cron.State, statePath string) error {
+	var failed int
+	for _, job := range jobs {
+		start := time.Now()
+		shared.Logger.Printf("\n%s Running job %s...", emoji.Gear, styles.Green(job.Name))
+
+		if err := runJob(projectDir, job); err != nil {
+			failed++
+			shared.Logger.Println(styles.Errorf("%s Job %s failed: %v", emoji.ErrorExclamation, job.Name, err))
+		} else {
+			shared.Logger.Printf("%s Job %s finished in %s", emoji.Check, styles.Green(job.Name), time.Since(start).Round(time.Millisecond))
+		}
+
+		// failed jobs are not retried before their next scheduled run
+		state[job.Name] = start
+		if err := state.Save(statePath); err != nil {
+			shared.Logger.Printf("%s Failed to save cron state: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d jobs failed", failed)
+	}
+	return nil
+}
+
+func runJob(projectDir string, job *cron.Job) error {
+	projectID := job.Project
+	if projectID == "" {
+		var err error
+		projectID, err = runtime.GetProjectID(projectDir)
+		if err != nil {
+			return fmt.Errorf("no project declared for the job and no project linked in %s", projectDir)
+		}
+	}
+
+	projectKey, err := shared.GenerateDataKeyIfNotExists(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	environ := map[string]string{
+		"DETA_PROJECT_KEY": projectKey,
+	}
+	for key, value := range job.Env {
+		environ[key] = value
+	}
+
+	fields, err := shell.Fields(job.Command, func(s string) string {
+		if env, ok := environ[s]; ok {
+			return env
+		}
+		return os.Getenv(s)
+	})
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("no command found for job %s", job.Name)
+	}
+
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Env = os.Environ()
+	for key, value := range environ {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	cmd.Dir = job.Dir
+	cmd.Stdout = writer.NewPrefixer(job.Name, os.Stdout)
+	cmd.Stderr = writer.NewPrefixer(job.Name, os.Stderr)
+
+	return cmd.Run()
+}
@@ -0,0 +1,160 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/deps"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/util/fs"
+	"github.com/spf13/cobra"
+)
+
+func newCmdDepsAnalyze() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze [flags]",
+		Short: "Estimate the installed size of the dependencies of your micros",
+		Long: fmt.Sprintf(`Estimate the installed size of the dependencies of your micros and compare it with the size limit of a micro on Space (%s), before the build fails remotely.
+
+The sizes are measured in the node_modules or the virtualenv (.venv or venv) of a micro if it's installed locally. Otherwise the packages of its lockfiles are looked up in the npm registry and on PyPI: npm publishes the unpacked size of a package, python packages are estimated with the size of their wheel, so the installed size is usually larger. With --offline the registries aren't used and only the packages are listed.
+
+Development dependencies aren't installed in a micro, so they don't count toward the limit and are reported separately: the packages package-lock.json, pnpm-lock.yaml or poetry.lock mark as dev, and the devDependencies of package.json.
+
+The command fails if the dependencies of a micro exceed the limit.`, fs.FormatSize(deps.MaxMicroSize)),
+		Example: `  space deps analyze
+  space deps analyze --micro api --top 20
+  space deps analyze --output json | jq '.[].total'`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckNotEmpty("micro")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			micro, _ := cmd.Flags().GetString("micro")
+			top, _ := cmd.Flags().GetInt("top")
+			offline, _ := cmd.Flags().GetBool("offline")
+			return analyze(projectDir, micro, top, offline)
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("micro", "m", "", "only analyze the micro")
+	cmd.Flags().Int("top", 10, "number of the heaviest packages to show per micro")
+	cmd.Flags().Bool("offline", false, "don't look up the sizes in the package registries")
+
+	return cmd
+}
+
+// microReport is printed with --output json
+type microReport struct {
+	Micro string `json:"micro"`
+	Src   string `json:"src"`
+	*deps.Report
+	Limit int64 `json:"limit"`
+}
+
+func analyze(projectDir string, micro string, top int, offline bool) error {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s Failed to parse Spacefile: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	var sizer deps.Sizer
+	if !offline {
+		sizer = &deps.RegistrySizer{Client: api.HTTPClient(), NPMURL: deps.DefaultNPMURL, PyPIURL: deps.DefaultPyPIURL}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var reports []*microReport
+	found := false
+	for _, m := range s.Micros {
+		if micro != "" && m.Name != micro {
+			continue
+		}
+		found = true
+		src := path.Clean(filepath.ToSlash(m.Src))
+		if !shared.JSONOutput() {
+			shared.Logger.Printf("Analyzing the dependencies of micro %s...", styles.Code(m.Name))
+		}
+		report, err := deps.Analyze(ctx, filepath.Join(projectDir, filepath.FromSlash(src)), sizer)
+		if err != nil {
+			shared.Logger.Printf("%s Failed to analyze micro %s: %s", emoji.ErrorExclamation, m.Name, err)
+			return err
+		}
+		if report == nil {
+			if !shared.JSONOutput() {
+				shared.Logger.Printf("L %s\n", styles.Subtle("no lockfile or local install found"))
+			}
+			continue
+		}
+		reports = append(reports, &microReport{Micro: m.Name, Src: src, Report: report, Limit: deps.MaxMicroSize})
+		if !shared.JSONOutput() {
+			printReport(report, top)
+		}
+	}
+	if !found {
+		shared.Logger.Println(styles.Errorf("%s Micro %s is not in the Spacefile", emoji.ErrorExclamation, micro))
+		return fmt.Errorf("micro %s not found", micro)
+	}
+
+	if shared.JSONOutput() {
+		if err := shared.PrintJSON(reports); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range reports {
+		if r.Total > deps.MaxMicroSize {
+			return fmt.Errorf("the dependencies of micro %s exceed the size limit", r.Micro)
+		}
+	}
+	return nil
+}
+
+func printReport(r *deps.Report, top int) {
+	how := "estimated from " + r.Source
+	if r.Installed {
+		how = "measured in " + r.Source
+	}
+	total := fs.FormatSize(r.Total)
+	if r.Unknown > 0 {
+		total = "at least " + total
+	}
+	devPackages := r.DevPackages()
+	shared.Logger.Printf("L %d packages, %s of %s %s", len(r.Packages)-devPackages, total, fs.FormatSize(deps.MaxMicroSize), styles.Subtle("("+how+")"))
+
+	for _, p := range r.Heaviest(top) {
+		if p.SizeUnknown {
+			break
+		}
+		name := p.Name
+		if p.Version != "" {
+			name += " " + styles.Subtle(p.Version)
+		}
+		shared.Logger.Printf("  %10s  %s", fs.FormatSize(p.Size), name)
+	}
+	if r.Unknown > 0 {
+		shared.Logger.Printf("  %s", styles.Subtle(fmt.Sprintf("%d packages of unknown size", r.Unknown)))
+	}
+	if devPackages > 0 {
+		shared.Logger.Printf("  %s", styles.Subtle(fmt.Sprintf("%d development dependencies, %s, not counted", devPackages, fs.FormatSize(r.DevTotal))))
+	}
+
+	ratio := r.Ratio(deps.MaxMicroSize)
+	switch {
+	case ratio > 1:
+		shared.Logger.Println(styles.Errorf("%s The dependencies exceed the size limit by %s, the build will fail", emoji.ErrorExclamation, fs.FormatSize(r.Total-deps.MaxMicroSize)))
+	case ratio > deps.WarnRatio:
+		shared.Logger.Printf("%s The dependencies use %.0f%% of the size limit, leaving little room for your code", emoji.Warning, ratio*100)
+	}
+	shared.Logger.Println()
+}
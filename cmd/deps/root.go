@@ -0,0 +1,19 @@
+package deps
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDeps() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Inspect the dependencies of your micros",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdDepsAnalyze())
+
+	return cmd
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/deta/space/internal/spacefile"
 	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
-	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +26,7 @@ func newCmdDevProxy() *cobra.Command {
 The micros will be automatically discovered and proxied to.`,
 		PreRunE:  shared.CheckProjectInitialized("dir"),
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 
 			directory, _ := cmd.Flags().GetString("dir")
@@ -39,14 +38,15 @@ The micros will be automatically discovered and proxied to.`,
 				port, err = GetFreePort(devDefaultPort)
 				if err != nil {
 					shared.Logger.Printf("%s Failed to get free port: %s", emoji.ErrorExclamation, err)
-					os.Exit(1)
+					return err
 				}
 			}
 
 			if err := devProxy(directory, host, port, open); err != nil {
-				os.Exit(1)
+				return err
 			}
 
+			return nil
 		},
 	}
 
@@ -63,15 +63,19 @@ func devProxy(projectDir string, host string, port int, open bool) error {
 	addr := fmt.Sprintf("%s:%d", host, port)
 
 	microDir := filepath.Join(projectDir, ".space", "micros")
-	spacefile, _ := spacefile.ParseSpacefile(projectDir)
+	spacefile, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
 
 	if entries, err := os.ReadDir(microDir); err != nil || len(entries) == 0 {
 		shared.Logger.Printf("%s No running micros detected.", emoji.X)
 		shared.Logger.Printf("L Use %s to manually start a micro", styles.Blue("space dev up <micro>"))
-		os.Exit(1)
+		return shared.ErrReported
 	}
 
-	reverseProxy, err := proxyFromDir(spacefile.Micros, microDir)
+	reverseProxy, err := proxyFromDir(projectDir, spacefile.Micros, microDir)
 	if err != nil {
 		return err
 	}
@@ -97,7 +101,7 @@ func devProxy(projectDir string, host string, port int, open bool) error {
 	}()
 
 	if open {
-		browser.OpenURL(fmt.Sprintf("http://localhost:%d", port))
+		shared.OpenURL(fmt.Sprintf("http://localhost:%d", port))
 	}
 
 	wg.Wait()
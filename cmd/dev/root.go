@@ -18,14 +18,15 @@ import (
 
 	"github.com/alessio/shellescape"
 	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/openapi"
 	"github.com/deta/space/internal/proxy"
 	"github.com/deta/space/internal/runtime"
 	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/internal/staticsite"
 	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
 	"github.com/deta/space/pkg/writer"
 	types "github.com/deta/space/shared"
-	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 	"mvdan.cc/sh/v3/shell"
 )
@@ -54,11 +55,11 @@ func NewCmdDev() *cobra.Command {
 		Short: "Spin up a local development environment for your Space project",
 		Long: `Spin up a local development environment for your Space project.
 
-The cli will start one process for each of your micros, then expose a single enpoint for your Space app.`,
+The cli will start one process for each of your micros, then expose a single enpoint for your Space app. Micros with an OpenAPI spec, see space openapi init, get Swagger UI under ` + openapi.UIPrefix + `<micro>.`,
 
 		PreRunE:  shared.CheckAll(shared.CheckProjectInitialized("dir"), shared.CheckNotEmpty("id")),
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 
 			projectDir, _ := cmd.Flags().GetString("dir")
@@ -71,20 +72,21 @@ The cli will start one process for each of your micros, then expose a single enp
 				projectID, err = runtime.GetProjectID(projectDir)
 				if err != nil {
 					shared.Logger.Printf("%s Failed to get project id: %s", emoji.ErrorExclamation, err)
-					os.Exit(1)
+					return err
 				}
 			}
 
 			if !cmd.Flags().Changed("port") {
 				port, err = GetFreePort(devDefaultPort)
 				if err != nil {
-					os.Exit(1)
+					return err
 				}
 			}
 
 			if err := dev(projectDir, projectID, host, port, open); err != nil {
-				os.Exit(1)
+				return err
 			}
+			return nil
 		},
 	}
 
@@ -131,7 +133,7 @@ func dev(projectDir string, projectID string, host string, port int, open bool)
 	addr := fmt.Sprintf("%s:%d", host, port)
 	if err != nil {
 		shared.Logger.Printf("%s Error generating the project key", emoji.ErrorExclamation)
-		os.Exit(1)
+		return err
 	}
 
 	shared.Logger.Printf("\n%s Checking for running micros...", emoji.Eyes)
@@ -184,14 +186,19 @@ func dev(projectDir string, projectID string, host string, port int, open bool)
 		shared.Logger.Printf("L url: %s\n\n", styles.Blue(spaceUrl))
 	}
 
-	proxy, err := proxyFromDir(spacefile.Micros, routeDir)
+	proxy, err := proxyFromDir(projectDir, spacefile.Micros, routeDir)
 	if err != nil {
 		return err
 	}
 
+	for _, micro := range openapi.WithSpec(projectDir, spacefile.Micros) {
+		shared.Logger.Printf("API docs of %s", styles.Green(micro.Name))
+		shared.Logger.Printf("L url: %s\n\n", styles.Blue(fmt.Sprintf("http://%s%s%s", addr, openapi.UIPrefix, micro.Name)))
+	}
+
 	server := http.Server{
 		Addr:    addr,
-		Handler: proxy,
+		Handler: openapi.Handler(proxy, projectDir, spacefile.Micros),
 	}
 
 	wg := sync.WaitGroup{}
@@ -237,7 +244,7 @@ func dev(projectDir string, projectID string, host string, port int, open bool)
 	if open {
 		// Wait a bit for the server to start
 		time.Sleep(1 * time.Second)
-		browser.OpenURL(fmt.Sprintf("http://%s", addr))
+		shared.OpenURL(fmt.Sprintf("http://%s", addr))
 	}
 
 	wg.Wait()
@@ -259,7 +266,7 @@ func writePortFile(portfile string, port int) error {
 	return os.WriteFile(portfile, []byte(fmt.Sprintf("%d", port)), 0644)
 }
 
-func proxyFromDir(micros []*types.Micro, routeDir string) (*proxy.ReverseProxy, error) {
+func proxyFromDir(projectDir string, micros []*types.Micro, routeDir string) (*proxy.ReverseProxy, error) {
 	routes := make([]proxy.ProxyRoute, 0)
 	for _, micro := range micros {
 		portFile := filepath.Join(routeDir, fmt.Sprintf("%s.port", micro.Name))
@@ -274,10 +281,17 @@ func proxyFromDir(micros []*types.Micro, routeDir string) (*proxy.ReverseProxy,
 
 		target, _ := url.Parse(fmt.Sprintf("http://localhost:%d", microPort))
 
-		routes = append(routes, proxy.ProxyRoute{
+		route := proxy.ProxyRoute{
 			Prefix: micro.Path,
 			Target: target,
-		})
+		}
+		if micro.Static != nil {
+			root, prefix, config := filepath.Join(projectDir, micro.Src, micro.Serve), micro.Path, micro.Static
+			route.Middleware = func(next http.Handler) http.Handler {
+				return staticsite.Handler(next, root, prefix, config)
+			}
+		}
+		routes = append(routes, route)
 	}
 
 	return proxy.NewReverseProxy(routes), nil
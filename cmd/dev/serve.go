@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/pkg/components/emoji"
 	"github.com/spf13/cobra"
 )
 
@@ -14,7 +15,7 @@ func newCmdServe() *cobra.Command {
 		Hidden: true,
 		Args:   cobra.ExactArgs(1),
 		Short:  "Serve static files",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			fs := http.FileServer(http.Dir(args[0]))
 			http.Handle("/", fs)
 
@@ -23,7 +24,11 @@ func newCmdServe() *cobra.Command {
 
 			address := fmt.Sprintf("%s:%d", host, port)
 			shared.Logger.Printf("Serving %s on %s", args[0], address)
-			http.ListenAndServe(address, nil)
+			if err := http.ListenAndServe(address, nil); err != nil {
+				shared.Logger.Printf("%s Failed to serve %s: %v", emoji.ErrorExclamation, args[0], err)
+				return err
+			}
+			return nil
 		},
 	}
 
@@ -0,0 +1,75 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/discovery"
+	"github.com/deta/space/internal/editor"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdDiscoveryEdit() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit [flags]",
+		Short: "Edit the Discovery file in your editor",
+		Long: fmt.Sprintf(`Edit the %s file of the project in your editor, a new file is created from a template.
+
+The file is checked when it's saved and opened again if it's invalid. It is published with the next %s. The editor is set with SPACE_EDITOR, the editor of the config file, VISUAL or EDITOR.`, discovery.DiscoveryFilename, styles.Code("space push")),
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckExists("dir"),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+
+			if !shared.IsOutputInteractive() {
+				shared.Logger.Printf("discovery edit can only be used in interactive mode")
+				return shared.ErrReported
+			}
+
+			if err := editDiscovery(projectDir); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+
+	return cmd
+}
+
+func editDiscovery(projectDir string) error {
+	content, err := discovery.Open(projectDir)
+	if errors.Is(err, discovery.ErrDiscoveryFileNotFound) {
+		content = []byte(discovery.Template)
+	} else if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to read Discovery file, %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	help := fmt.Sprintf("The %s file is shown on Discovery. The tagline has at most %d characters,\nthe theme color is a hex color and git and homepage are urls.", discovery.DiscoveryFilename, discovery.MaxTaglineLength)
+	edited, err := editor.EditMarkdown("Discovery", help, string(content), func(content string) error {
+		return discovery.Validate([]byte(content))
+	})
+	if err != nil {
+		if errors.Is(err, editor.ErrEmpty) {
+			shared.Logger.Printf("%s Discovery file is empty, it was left as it is", emoji.Warning)
+			return nil
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to edit Discovery file: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(projectDir, discovery.DiscoveryFilename), []byte(edited+"\n"), 0644); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to write Discovery file: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+	shared.Logger.Printf("%s Saved %s, publish it with %s", emoji.Check, discovery.DiscoveryFilename, styles.Code("space push"))
+	return nil
+}
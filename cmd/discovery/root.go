@@ -0,0 +1,19 @@
+package discovery
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDiscovery() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "discovery",
+		Short: "Manage the Discovery file of a project",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdDiscoveryEdit())
+
+	return cmd
+}
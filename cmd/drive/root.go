@@ -0,0 +1,19 @@
+package drive
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDrive() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drive",
+		Short: "Manage the Drive data of your project",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdDriveSync())
+
+	return cmd
+}
@@ -0,0 +1,373 @@
+package drive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/drive"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdDriveSync() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync <localdir> <drive>[/prefix]",
+		Short: "Sync a local directory with a Drive",
+		Long: `Sync a local directory with a Drive of your project.
+
+By default files are only pushed to the Drive: new and changed files are uploaded, files are compared using their sha256 checksums. Pass --delete to also remove files from the Drive which no longer exist locally.
+
+With --bidirectional both sides are compared with the last sync of the directory, which is kept in its .space directory: files changed or deleted locally are uploaded or deleted in the Drive, files changed or deleted in the Drive are downloaded or deleted locally. Files changed on both sides are conflicts, they're left as they are until you sync without --bidirectional to keep the local files.`,
+		Example: `  space drive sync ./assets assets
+  space drive sync ./public content/public --delete --dry-run`,
+		Args:     cobra.ExactArgs(2),
+		PreRunE:  shared.CheckAll(shared.CheckNotEmpty("id"), shared.ApplyBandwidthLimit("bwlimit")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			projectID, _ := cmd.Flags().GetString("id")
+			if !cmd.Flags().Changed("id") {
+				cwd, _ := os.Getwd()
+				projectID, err = runtime.GetProjectID(cwd)
+				if err != nil {
+					shared.Logger.Printf("project id not provided and could not be inferred from current working directory")
+					return err
+				}
+			}
+
+			localDir := args[0]
+			if stat, err := os.Stat(localDir); err != nil || !stat.IsDir() {
+				shared.Logger.Printf("%s directory %s does not exist", emoji.ErrorExclamation, localDir)
+				return shared.ErrReported
+			}
+
+			target, err := drive.ParseTarget(args[1])
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			bidirectional, _ := cmd.Flags().GetBool("bidirectional")
+			del, _ := cmd.Flags().GetBool("delete")
+
+			if del && !dryRun {
+				cwd, _ := os.Getwd()
+				if err := shared.ConfirmProtected(cmd, cwd, projectID, "delete files from its drive"); err != nil {
+					return err
+				}
+			}
+
+			if err := driveSync(projectID, localDir, target, drive.Options{Bidirectional: bidirectional, Delete: del}, dryRun, concurrency); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("id", "i", "", "project id of the project owning the drive")
+	cmd.Flags().Bool("delete", false, "delete files from the drive that don't exist locally")
+	cmd.Flags().Bool("bidirectional", false, "also apply the changes made in the drive to the local directory")
+	cmd.Flags().Bool("dry-run", false, "only print the changes without applying them")
+	cmd.Flags().IntP("concurrency", "c", 4, "number of parallel transfers")
+	cmd.Flags().String("bwlimit", "", "limit the total upload bandwidth, e.g. 2MB/s")
+	cmd.MarkFlagsMutuallyExclusive("delete", "bidirectional")
+
+	return cmd
+}
+
+type syncOp struct {
+	action string
+	name   string
+}
+
+func driveSync(projectID string, localDir string, target *drive.Target, opts drive.Options, dryRun bool, concurrency int) error {
+	projectKey, err := shared.GenerateDataKeyIfNotExists(projectID)
+	if err != nil {
+		shared.Logger.Printf("%s Error generating the project key: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	ref := &api.DriveRef{ProjectKey: projectKey, Drive: target.Drive}
+
+	shared.Logger.Printf("\n%s Comparing %s with drive %s...", emoji.Eyes, styles.Code(localDir), styles.Code(targetName(target)))
+
+	local, err := drive.HashDir(localDir)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	remoteNames, err := shared.Client.ListDriveFiles(ref, target.Prefix)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to list drive files: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	var remote []string
+	manifest := make(drive.Manifest)
+	for _, name := range remoteNames {
+		localName := target.LocalName(name)
+		if localName != drive.ManifestName {
+			remote = append(remote, localName)
+			continue
+		}
+
+		manifest, err = downloadManifest(ref, name)
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to read sync manifest: %v", emoji.ErrorExclamation, err))
+			return err
+		}
+	}
+
+	basePath := drive.LocalManifestPath(localDir, target)
+	base := make(drive.Manifest)
+	if opts.Bidirectional {
+		base, err = readLocalManifest(basePath)
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to read the manifest of the last sync: %v", emoji.ErrorExclamation, err))
+			return err
+		}
+	}
+
+	plan := drive.NewPlan(local, drive.RemoteSums(remote, manifest), base, opts)
+	if plan.IsEmpty() {
+		shared.Logger.Println(styles.Greenf("\n%s Everything is up to date!", emoji.Check))
+		return nil
+	}
+
+	var ops []syncOp
+	for _, name := range plan.Uploads {
+		ops = append(ops, syncOp{action: "upload", name: name})
+	}
+	for _, name := range plan.Downloads {
+		ops = append(ops, syncOp{action: "download", name: name})
+	}
+
+	shared.Logger.Println()
+	for _, op := range ops {
+		shared.Logger.Printf("L %s %s", op.action, op.name)
+	}
+	for _, name := range plan.Deletes {
+		shared.Logger.Printf("L delete %s", name)
+	}
+	for _, name := range plan.LocalDeletes {
+		shared.Logger.Printf("L delete local %s", name)
+	}
+	for _, name := range plan.Conflicts {
+		shared.Logger.Printf("L conflict %s, changed locally and in the drive", name)
+	}
+
+	if dryRun {
+		shared.Logger.Printf("\n%s Dry run, %d uploads, %d downloads and %d deletes skipped", styles.Info, len(plan.Uploads), len(plan.Downloads), len(plan.Deletes)+len(plan.LocalDeletes))
+		return nil
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var failed int
+	jobs := make(chan syncOp)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for op := range jobs {
+				sum, err := runSyncOp(ref, target, localDir, op)
+
+				mu.Lock()
+				if err != nil {
+					failed++
+					shared.Logger.Println(styles.Errorf("%s Failed to %s %s: %v", emoji.ErrorExclamation, op.action, op.name, err))
+				} else {
+					manifest[op.name] = sum
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, op := range ops {
+		jobs <- op
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(plan.Deletes) > 0 {
+		if err := deleteRemote(ref, target, plan.Deletes); err != nil {
+			failed++
+			shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+		} else {
+			for _, name := range plan.Deletes {
+				delete(manifest, name)
+			}
+		}
+	}
+
+	for _, name := range plan.LocalDeletes {
+		localPath, err := drive.LocalPath(localDir, name)
+		if err == nil {
+			err = os.Remove(localPath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			failed++
+			shared.Logger.Println(styles.Errorf("%s Failed to delete %s: %v", emoji.ErrorExclamation, name, err))
+			continue
+		}
+		delete(manifest, name)
+	}
+
+	if err := uploadManifest(ref, target, manifest); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to update sync manifest: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	// conflicts keep the base of the last sync so that they're still conflicts the next time
+	synced := make(drive.Manifest, len(manifest))
+	for name, sum := range manifest {
+		synced[name] = sum
+	}
+	for _, name := range plan.Conflicts {
+		if sum, ok := base[name]; ok {
+			synced[name] = sum
+		} else {
+			delete(synced, name)
+		}
+	}
+	if err := writeLocalManifest(basePath, synced); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to save the manifest of the sync: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	if failed > 0 {
+		shared.Logger.Println(styles.Errorf("\n%s Sync finished with %d failed operations", emoji.ErrorExclamation, failed))
+		return fmt.Errorf("%d operations failed", failed)
+	}
+
+	if len(plan.Conflicts) > 0 {
+		shared.Logger.Println(styles.Errorf("\n%s %d files changed locally and in the drive were left as they are, sync without --bidirectional to keep the local files", emoji.ErrorExclamation, len(plan.Conflicts)))
+		return shared.ErrReported
+	}
+
+	shared.Logger.Println(styles.Greenf("\n%s Synced %d files with drive %s!", emoji.Check, len(ops)+len(plan.Deletes)+len(plan.LocalDeletes), targetName(target)))
+	return nil
+}
+
+func targetName(target *drive.Target) string {
+	return target.Drive + "/" + target.Prefix
+}
+
+// runSyncOp performs a single upload or download and returns the checksum of the transferred file
+func runSyncOp(ref *api.DriveRef, target *drive.Target, localDir string, op syncOp) (string, error) {
+	localPath, err := drive.LocalPath(localDir, op.name)
+	if err != nil {
+		return "", err
+	}
+
+	switch op.action {
+	case "upload":
+		content, err := os.ReadFile(localPath)
+		if err != nil {
+			return "", err
+		}
+		if err := shared.Client.PutDriveFile(ref, target.RemoteName(op.name), content); err != nil {
+			return "", err
+		}
+		return drive.HashFile(localPath)
+	case "download":
+		rc, err := shared.Client.GetDriveFile(ref, target.RemoteName(op.name))
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return "", err
+		}
+		f, err := os.Create(localPath)
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.Copy(f, rc); err != nil {
+			f.Close()
+			return "", err
+		}
+		if err := f.Close(); err != nil {
+			return "", err
+		}
+		return drive.HashFile(localPath)
+	}
+
+	return "", fmt.Errorf("unknown action %s", op.action)
+}
+
+func deleteRemote(ref *api.DriveRef, target *drive.Target, names []string) error {
+	for start := 0; start < len(names); start += 1000 {
+		end := start + 1000
+		if end > len(names) {
+			end = len(names)
+		}
+
+		batch := make([]string, 0, end-start)
+		for _, name := range names[start:end] {
+			batch = append(batch, target.RemoteName(name))
+		}
+		if err := shared.Client.DeleteDriveFiles(ref, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downloadManifest(ref *api.DriveRef, name string) (drive.Manifest, error) {
+	rc, err := shared.Client.GetDriveFile(ref, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return drive.ParseManifest(content)
+}
+
+func uploadManifest(ref *api.DriveRef, target *drive.Target, manifest drive.Manifest) error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return shared.Client.PutDriveFile(ref, target.RemoteName(drive.ManifestName), content)
+}
+
+func readLocalManifest(p string) (drive.Manifest, error) {
+	content, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return make(drive.Manifest), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return drive.ParseManifest(content)
+}
+
+func writeLocalManifest(p string, manifest drive.Manifest) error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, content, 0644)
+}
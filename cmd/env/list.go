@@ -0,0 +1,112 @@
+package env
+
+import (
+	"sort"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// hiddenValue replaces the values of variables unless --show-values is set
+const hiddenValue = "********"
+
+func newCmdEnvList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List the environment variables of your app",
+		Long: `List the environment variables and presets of all micros of your app, or of the micro chosen with --micro.
+
+Values are hidden unless --show-values is set, also in the json output.`,
+		Example: `  space env list
+  space env list --micro backend --show-values
+  space env list --environment staging --output json`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment", "micro")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			showValues, _ := cmd.Flags().GetBool("show-values")
+			if err := listEnv(cmd, showValues); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+	cmd.Flags().Bool("show-values", false, "show the values of the variables")
+
+	return cmd
+}
+
+func listEnv(cmd *cobra.Command, showValues bool) error {
+	_, projectID, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	var micros []api.MicroEnv
+	if name, _ := cmd.Flags().GetString("micro"); name != "" {
+		micro, err := fetchMicro(cmd, projectID)
+		if err != nil {
+			return err
+		}
+		micros = []api.MicroEnv{*micro}
+	} else {
+		micros, err = shared.Client.GetEnv(&api.GetEnvRequest{AppID: projectID})
+		if err != nil {
+			logEnvError("get the environment variables", err)
+			return err
+		}
+	}
+
+	for i := range micros {
+		sort.Slice(micros[i].Env, func(a, b int) bool {
+			return micros[i].Env[a].Name < micros[i].Env[b].Name
+		})
+		if !showValues {
+			for j := range micros[i].Env {
+				if micros[i].Env[j].IsSet {
+					micros[i].Env[j].Value = hiddenValue
+				}
+			}
+		}
+	}
+
+	if shared.JSONOutput() {
+		return shared.PrintJSON(micros)
+	}
+
+	for i, micro := range micros {
+		if i > 0 {
+			shared.Logger.Println()
+		}
+		shared.Logger.Printf("%s Environment variables of micro %s:\n", emoji.Key, styles.Blue(micro.Micro))
+		if len(micro.Env) == 0 {
+			shared.Logger.Printf("  %s", styles.Subtle("none, set one with space env set NAME=value"))
+			continue
+		}
+		for _, v := range micro.Env {
+			var line string
+			switch {
+			case v.IsSet:
+				line = styles.Code(v.Name) + " " + v.Value
+			case v.Default != "":
+				line = styles.Code(v.Name) + " " + styles.Subtle("not set, defaults to "+v.Default)
+			default:
+				line = styles.Code(v.Name) + " " + styles.Subtle("not set")
+			}
+			if v.Preset {
+				line += styles.Subtle(" (preset")
+				if v.Description != "" {
+					line += styles.Subtle(": " + v.Description)
+				}
+				line += styles.Subtle(")")
+			}
+			shared.Logger.Printf("  %s", line)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"os"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/dotenv"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdEnvPull() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull [flags]",
+		Short: "Write the environment variables of your app to a .env file",
+		Long: `Write the environment variables of a micro of your app in the .env format, e.g. to run it locally with the same configuration.
+
+The file has the values the micro runs with: the variables which are set and the defaults of presets which aren't. Without --file the variables are printed to stdout, --output with a path instead of a format works like --file but is deprecated. The file is only readable by you, don't commit it.`,
+		Example: `  space env pull --file .env
+  space env pull --micro backend --environment staging > .env.staging`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment", "micro", "file")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := shared.OutputPath(cmd, "file")
+			if err := pullEnv(cmd, file); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+	cmd.Flags().StringP("file", "f", "", "path of the .env file, stdout if not set")
+	shared.AddOutputPathFlag(cmd, "file")
+
+	return cmd
+}
+
+func pullEnv(cmd *cobra.Command, file string) error {
+	_, projectID, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	micro, err := fetchMicro(cmd, projectID)
+	if err != nil {
+		return err
+	}
+	values := effectiveValues(micro)
+
+	if file == "" {
+		return dotenv.Write(os.Stdout, values)
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to write %s: %v", emoji.ErrorExclamation, file, err))
+		return err
+	}
+	if err := dotenv.Write(f, values); err != nil {
+		f.Close()
+		shared.Logger.Println(styles.Errorf("%s Failed to write %s: %v", emoji.ErrorExclamation, file, err))
+		return err
+	}
+	if err := f.Close(); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to write %s: %v", emoji.ErrorExclamation, file, err))
+		return err
+	}
+
+	shared.Logger.Println(styles.Greenf("%s Wrote %d environment variables of micro %s to %s", emoji.Check, len(values), micro.Micro, styles.Code(file)))
+	return nil
+}
@@ -0,0 +1,116 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/dotenv"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdEnvPush() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push [flags]",
+		Short: "Set the environment variables of your app from a .env file",
+		Long: `Set the environment variables of a micro of your app to the values of a .env file, by default the .env file of the project.
+
+Only variables with a different value are changed. With --prune the variables which aren't in the file are removed, presets fall back to their default.`,
+		Example: `  space env push
+  space env push --file .env.staging --environment staging --prune`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment", "micro", "file")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			prune, _ := cmd.Flags().GetBool("prune")
+			if err := pushEnv(cmd, file, prune); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+	cmd.Flags().StringP("file", "f", "", "path of the .env file, defaults to .env in the project directory")
+	cmd.Flags().Bool("prune", false, "remove the variables which aren't in the file")
+
+	return cmd
+}
+
+// envChanges returns the variables of values which differ from the environment of the micro, and the variables which
+// are set but not in values
+func envChanges(micro *api.MicroEnv, values map[string]string) (map[string]string, []string) {
+	current := make(map[string]string)
+	for _, v := range micro.Env {
+		if v.IsSet {
+			current[v.Name] = v.Value
+		}
+	}
+
+	set := make(map[string]string)
+	for name, value := range values {
+		if existing, ok := current[name]; !ok || existing != value {
+			set[name] = value
+		}
+	}
+	var missing []string
+	for name := range current {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return set, missing
+}
+
+func pushEnv(cmd *cobra.Command, file string, prune bool) error {
+	projectDir, projectID, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		file = filepath.Join(projectDir, ".env")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to read %s: %v", emoji.ErrorExclamation, file, err))
+		return err
+	}
+	values, err := dotenv.Parse(f)
+	f.Close()
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Invalid .env file %s, %v", emoji.ErrorExclamation, file, err))
+		return err
+	}
+
+	micro, err := fetchMicro(cmd, projectID)
+	if err != nil {
+		return err
+	}
+	set, missing := envChanges(micro, values)
+	r := &api.UpdateEnvRequest{Micro: micro.Micro, Set: set}
+	if prune {
+		r.Unset = missing
+	}
+	if len(r.Set) == 0 && len(r.Unset) == 0 {
+		shared.Logger.Printf("%s The environment variables of micro %s already match %s", emoji.Check, micro.Micro, styles.Code(file))
+		return nil
+	}
+
+	if err := updateEnv(cmd, projectDir, projectID, r); err != nil {
+		return err
+	}
+	shared.Logger.Println(styles.Greenf("%s Updated the environment variables of micro %s from %s", emoji.Check, micro.Micro, styles.Code(file)))
+	shared.Logger.Printf("  %d set, %d removed, %d unchanged", len(r.Set), len(r.Unset), len(values)-len(r.Set))
+	if len(missing) > 0 && !prune {
+		shared.Logger.Printf("\n%s are set but not in the file, remove them with %s", strings.Join(missing, ", "), styles.Code("--prune"))
+	}
+	return nil
+}
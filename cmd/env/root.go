@@ -0,0 +1,132 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdEnv() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Manage the environment variables of your app",
+		Long: `Manage the environment variables of the micros of your app without the web UI.
+
+Presets are the variables declared in the presets of the Spacefile, a preset which isn't set falls back to its default. Running instances get a changed environment on their next start.
+
+Each environment of the project config is a project with its own variables, choose it with --environment like for space push and space release. Apps with several micros need --micro to choose the micro of a change.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdEnvList())
+	cmd.AddCommand(newCmdEnvSet())
+	cmd.AddCommand(newCmdEnvUnset())
+	cmd.AddCommand(newCmdEnvPull())
+	cmd.AddCommand(newCmdEnvPush())
+
+	return cmd
+}
+
+// addTargetFlags adds the flags choosing the project and micro of the environment variables
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("dir", "d", "./", "src of the project")
+	cmd.Flags().StringP("id", "i", "", "project id of the project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+	cmd.Flags().StringP("micro", "m", "", "name of the micro, required if the app has several micros")
+}
+
+// resolveProject returns the directory and id of the project targeted by the flags of cmd
+func resolveProject(cmd *cobra.Command) (string, string, error) {
+	projectDir, _ := cmd.Flags().GetString("dir")
+	projectID, _ := cmd.Flags().GetString("id")
+	environment, _ := cmd.Flags().GetString("environment")
+
+	projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+	if err != nil {
+		return "", "", err
+	}
+	return projectDir, projectID, nil
+}
+
+// logEnvError explains a failed request for the environment of a project
+func logEnvError(action string, err error) {
+	switch {
+	case errors.Is(err, auth.ErrNoAccessTokenFound):
+		shared.Logger.Println(shared.LoginInfo())
+	case errors.Is(err, api.ErrProjectNotFound):
+		shared.Logger.Println(styles.Errorf("%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
+	default:
+		shared.Logger.Println(styles.Errorf("%s Failed to %s: %v", emoji.ErrorExclamation, action, err))
+	}
+}
+
+// fetchMicro returns the environment of the micro chosen with --micro, or of the only micro of the app
+func fetchMicro(cmd *cobra.Command, projectID string) (*api.MicroEnv, error) {
+	micros, err := shared.Client.GetEnv(&api.GetEnvRequest{AppID: projectID})
+	if err != nil {
+		logEnvError("get the environment variables", err)
+		return nil, err
+	}
+	name, _ := cmd.Flags().GetString("micro")
+	micro, err := chooseMicro(micros, name)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+		return nil, err
+	}
+	return micro, nil
+}
+
+func chooseMicro(micros []api.MicroEnv, name string) (*api.MicroEnv, error) {
+	names := make([]string, len(micros))
+	for i := range micros {
+		if micros[i].Micro == name {
+			return &micros[i], nil
+		}
+		names[i] = micros[i].Micro
+	}
+	switch {
+	case len(micros) == 0:
+		return nil, errors.New("the project has no micros yet, push it first")
+	case name != "":
+		return nil, fmt.Errorf("the project has no micro %s, choose one of %s", name, strings.Join(names, ", "))
+	case len(micros) > 1:
+		return nil, fmt.Errorf("the project has several micros, choose one with --micro: %s", strings.Join(names, ", "))
+	}
+	return &micros[0], nil
+}
+
+// updateEnv sets and removes variables of the micro after a confirmation if the project is protected
+func updateEnv(cmd *cobra.Command, projectDir string, projectID string, r *api.UpdateEnvRequest) error {
+	if err := shared.ConfirmProtected(cmd, projectDir, projectID, "change its environment variables"); err != nil {
+		return err
+	}
+	r.AppID = projectID
+	if _, err := shared.Client.UpdateEnv(r); err != nil {
+		logEnvError("update the environment variables", err)
+		return err
+	}
+	return nil
+}
+
+// effectiveValues returns the values the micro runs with: the set variables and the defaults of presets which aren't set
+func effectiveValues(micro *api.MicroEnv) map[string]string {
+	values := make(map[string]string)
+	for _, v := range micro.Env {
+		switch {
+		case v.IsSet:
+			values[v.Name] = v.Value
+		case v.Preset && v.Default != "":
+			values[v.Name] = v.Default
+		}
+	}
+	return values
+}
@@ -0,0 +1,140 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/dotenv"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdEnvSet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <NAME=value>... [flags]",
+		Short: "Set environment variables of your app",
+		Long:  `Set environment variables of a micro of your app, a variable without a value like API_KEY takes its value from your environment so that it doesn't end up in your shell history.`,
+		Example: `  space env set DEBUG=false LOG_LEVEL=info
+  space env set API_KEY --micro backend --environment staging`,
+		Args:     cobra.MinimumNArgs(1),
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment", "micro")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setEnv(cmd, args); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+
+	return cmd
+}
+
+func newCmdEnvUnset() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset <NAME>... [flags]",
+		Short: "Remove environment variables of your app",
+		Long:  `Remove environment variables of a micro of your app, presets fall back to their default.`,
+		Example: `  space env unset DEBUG
+  space env unset API_KEY --micro backend`,
+		Args:     cobra.MinimumNArgs(1),
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment", "micro")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := unsetEnv(cmd, args); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+
+	return cmd
+}
+
+// parseAssignments parses variables like NAME=value, a variable without a value takes it from the environment
+func parseAssignments(args []string, lookupEnv func(string) (string, bool)) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !dotenv.ValidName(name) {
+			return nil, fmt.Errorf("invalid variable name %q, use letters, digits and _", name)
+		}
+		if !ok {
+			if value, ok = lookupEnv(name); !ok {
+				return nil, fmt.Errorf("%s is not set in your environment, use %s=value", name, name)
+			}
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func setEnv(cmd *cobra.Command, args []string) error {
+	values, err := parseAssignments(args, os.LookupEnv)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	projectDir, projectID, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	micro, err := fetchMicro(cmd, projectID)
+	if err != nil {
+		return err
+	}
+	if err := updateEnv(cmd, projectDir, projectID, &api.UpdateEnvRequest{Micro: micro.Micro, Set: values}); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	shared.Logger.Println(styles.Greenf("%s Set %s of micro %s", emoji.Check, strings.Join(names, ", "), micro.Micro))
+	return nil
+}
+
+func unsetEnv(cmd *cobra.Command, names []string) error {
+	projectDir, projectID, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	micro, err := fetchMicro(cmd, projectID)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, v := range micro.Env {
+		existing[v.Name] = v.IsSet
+	}
+	var unset []string
+	for _, name := range names {
+		if !existing[name] {
+			shared.Logger.Printf("%s %s isn't set for micro %s", emoji.Warning, name, micro.Micro)
+			continue
+		}
+		unset = append(unset, name)
+	}
+	if len(unset) == 0 {
+		return nil
+	}
+
+	if err := updateEnv(cmd, projectDir, projectID, &api.UpdateEnvRequest{Micro: micro.Micro, Unset: unset}); err != nil {
+		return err
+	}
+	shared.Logger.Println(styles.Greenf("%s Removed %s of micro %s", emoji.Check, strings.Join(unset, ", "), micro.Micro))
+	return nil
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/internal/discovery"
+	"github.com/deta/space/internal/export"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/components/text"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exportPassphraseEnv = "SPACE_EXPORT_PASSPHRASE"
+)
+
+func newCmdExport() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [flags]",
+		Short: "Export your project into a portable archive",
+		Long: `Export your project into a portable archive.
+
+The archive contains the Spacefile, the Discovery file, the names of the environment variables and the schedules declared in the Spacefile, and the source code of the latest revision. Use it to migrate a project to another account with space import or to keep a compliance snapshot.
+
+Pass --with-values to also store the values of the environment variables which are set on Space, fetched like space env pull does. Variables without a value are listed. Values are encrypted with a passphrase, which is read from the SPACE_EXPORT_PASSPHRASE environment variable or prompted for.`,
+		Example: `  space export --file my-app.zip
+  space export --local --with-values`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectInitialized("dir"), shared.CheckNotEmpty("id", "file"), shared.ApplyInsecureSkipVerify("insecure-skip-verify")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			file := shared.OutputPath(cmd, "file")
+			withValues, _ := cmd.Flags().GetBool("with-values")
+			localSource, _ := cmd.Flags().GetBool("local")
+
+			if !cmd.Flags().Changed("id") {
+				var err error
+				projectID, err = runtime.GetProjectID(projectDir)
+				if err != nil {
+					shared.Logger.Printf("%s Failed to get project id: %s", emoji.ErrorExclamation, err)
+					return err
+				}
+			}
+
+			if err := exportProject(projectDir, projectID, file, withValues, localSource); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project to export")
+	cmd.Flags().StringP("id", "i", "", "project id of project to export")
+	cmd.Flags().StringP("file", "f", "", "path of the archive, defaults to <project>-export.zip")
+	shared.AddOutputPathFlag(cmd, "file")
+	cmd.Flags().Bool("with-values", false, "export the values of the environment variables, encrypted with a passphrase")
+	cmd.Flags().Bool("local", false, "export the source code of the local directory instead of the latest revision")
+	cmd.Flags().Bool("insecure-skip-verify", false, "skip the checksum verification of the downloaded revision, not recommended")
+
+	return cmd
+}
+
+func exportProject(projectDir string, projectID string, file string, withValues bool, localSource bool) error {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, "Spacefile"))
+	if err != nil {
+		shared.Logger.Printf("%s Failed to parse Spacefile: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	// the revisions are only needed to export the latest revision
+	var project *api.GetProjectResponse
+	var revisions []*api.Revision
+	if localSource {
+		project, err = shared.Client.GetProject(&api.GetProjectRequest{ID: projectID})
+	} else {
+		var p *shared.ProjectWithRevisions
+		if p, err = shared.GetProjectWithRevisions(projectID); err == nil {
+			project, revisions = p.Project, p.Revisions
+		}
+	}
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to get project: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	b := &export.Bundle{
+		Manifest: export.Manifest{
+			FormatVersion: export.FormatVersion,
+			ExportedAt:    time.Now().UTC(),
+			CliVersion:    shared.SpaceVersion,
+			Project:       export.Project{ID: project.ID, Name: project.Name, Alias: project.Alias},
+		},
+	}
+	b.Manifest.DescribeSpacefile(s)
+
+	if b.Spacefile, err = spacefile.OpenRaw(projectDir); err != nil {
+		shared.Logger.Printf("%s Failed to read Spacefile: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	if df, err := discovery.Open(projectDir); err == nil {
+		b.Discovery = df
+	} else if !errors.Is(err, discovery.ErrDiscoveryFileNotFound) {
+		shared.Logger.Println(styles.Errorf("%s Failed to read Discovery file, %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	if localSource {
+		shared.Logger.Printf("\n%s Archiving source code of %s...", emoji.Package, styles.Code(projectDir))
+		if b.Source, _, err = runtime.ZipDir(projectDir); err != nil {
+			shared.Logger.Printf("%s Failed to zip project: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+	} else {
+		if b.Source, b.Manifest.RevisionID, err = downloadLatestRevision(revisions); err != nil {
+			return err
+		}
+	}
+
+	if withValues {
+		values, err := exportEnvValues(project.ID, b.Manifest.Env)
+		if err != nil {
+			return err
+		}
+
+		passphrase, err := readPassphrase("Passphrase to encrypt the environment variable values", true)
+		if err != nil {
+			return err
+		}
+
+		raw, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		if b.Manifest.EnvValues, err = crypt.Seal(passphrase, raw); err != nil {
+			shared.Logger.Printf("%s Failed to encrypt environment variable values: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+		shared.Logger.Printf("%s Encrypted the values of %d environment variables", emoji.Key, len(values))
+	}
+
+	if file == "" {
+		file = fmt.Sprintf("%s-export.zip", project.Alias)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to create archive: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	defer f.Close()
+
+	if err := b.Write(f); err != nil {
+		shared.Logger.Printf("%s Failed to write archive: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	shared.Logger.Println(styles.Greenf("\n%s Exported project %s to %s", emoji.Check, project.Name, file))
+	shared.Logger.Printf("L %d environment variables, %d schedules", len(b.Manifest.Env), len(b.Manifest.Schedules))
+	return nil
+}
+
+// exportEnvValues fetches the values of the environment variables which are set on Space, like space env pull does,
+// and lists the variables without a value
+func exportEnvValues(projectID string, envs []export.Env) (map[string]string, error) {
+	micros, err := shared.Client.GetEnv(&api.GetEnvRequest{AppID: projectID})
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to get the environment variables: %v", emoji.ErrorExclamation, err))
+		return nil, err
+	}
+
+	set := make(map[string]map[string]string)
+	for _, micro := range micros {
+		set[micro.Micro] = make(map[string]string)
+		for _, v := range micro.Env {
+			if v.IsSet {
+				set[micro.Micro][v.Name] = v.Value
+			}
+		}
+	}
+
+	values := make(map[string]string)
+	var missing []export.Env
+	for _, env := range envs {
+		value, ok := set[env.Micro][env.Name]
+		if !ok {
+			missing = append(missing, env)
+			continue
+		}
+		values[env.Name] = value
+	}
+
+	if len(missing) > 0 {
+		shared.Logger.Printf("%s %d environment variables have no value and are exported without one:", emoji.Warning, len(missing))
+		for _, env := range missing {
+			shared.Logger.Printf("L %s of micro %s", styles.Code(env.Name), env.Micro)
+		}
+	}
+	return values, nil
+}
+
+func downloadLatestRevision(revisions []*api.Revision) ([]byte, string, error) {
+	if len(revisions) == 0 {
+		shared.Logger.Printf(styles.Errorf("%s No revisions found. Please create a revision by running %s or export the local source with %s", emoji.ErrorExclamation, styles.Code("space push"), styles.Code("--local")))
+		return nil, "", errors.New("no revisions found")
+	}
+	revision := revisions[0]
+
+	shared.Logger.Printf("\n%s Downloading source code of revision %s...", emoji.Package, styles.Blue(revision.Tag))
+	rc, err := shared.Client.GetRevisionCode(&api.GetRevisionCodeRequest{RevisionID: revision.ID, Digest: revision.Digest})
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to download revision: %v", emoji.ErrorExclamation, err))
+		return nil, "", err
+	}
+	defer rc.Close()
+
+	source, err := io.ReadAll(rc)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to download revision: %v", emoji.ErrorExclamation, err))
+		return nil, "", err
+	}
+	return source, revision.ID, nil
+}
+
+func readPassphrase(prompt string, confirmInput bool) (string, error) {
+	if passphrase, ok := os.LookupEnv(exportPassphraseEnv); ok {
+		return passphrase, nil
+	}
+
+	if !shared.CanPrompt() {
+		shared.Logger.Printf("%s A passphrase is required, set %s in non-interactive mode", emoji.ErrorExclamation, exportPassphraseEnv)
+		return "", errors.New("passphrase required")
+	}
+
+	passphrase, err := text.Run(&text.Input{
+		Key:          "export.passphrase",
+		Prompt:       prompt,
+		PasswordMode: true,
+		Validator: func(value string) error {
+			if len(value) < 8 {
+				return fmt.Errorf("passphrase must be at least 8 characters long")
+			}
+			return nil
+		},
+	})
+	if err != nil || !confirmInput {
+		return passphrase, err
+	}
+
+	_, err = text.Run(&text.Input{
+		Key:          "export.passphrase",
+		Prompt:       "Repeat passphrase",
+		PasswordMode: true,
+		Validator: func(value string) error {
+			if value != passphrase {
+				return fmt.Errorf("passphrases do not match")
+			}
+			return nil
+		},
+	})
+	return passphrase, err
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/deta/space/internal/export"
+	"gotest.tools/v3/assert"
+)
+
+func TestExportEnvValues(t *testing.T) {
+	useServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/v0/apps/p1/env")
+		w.Write([]byte(`{"micros": [
+			{"micro": "api", "env": [{"name": "SECRET", "value": "s3cret", "is_set": true}, {"name": "EMPTY", "is_set": false, "preset": true}]},
+			{"micro": "web", "env": [{"name": "TITLE", "value": "", "is_set": true}]}
+		]}`))
+	})
+
+	envs := []export.Env{
+		{Micro: "api", Name: "SECRET"},
+		{Micro: "api", Name: "EMPTY"},
+		{Micro: "web", Name: "TITLE"},
+		{Micro: "web", Name: "SECRET"},
+	}
+
+	var values map[string]string
+	logs := captureLogs(t, func() {
+		var err error
+		values, err = exportEnvValues("p1", envs)
+		assert.NilError(t, err)
+	})
+
+	assert.DeepEqual(t, values, map[string]string{"SECRET": "s3cret", "TITLE": ""})
+	assert.Assert(t, strings.Contains(logs, "2 environment variables have no value"), logs)
+	assert.Assert(t, strings.Contains(logs, "EMPTY"), logs)
+}
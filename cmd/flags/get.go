@@ -0,0 +1,63 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/featureflags"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdFlagsGet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Print the value of a feature flag",
+		Long: `Print the value of a feature flag to stdout, json values are printed as compact json.
+
+The command fails if the flag doesn't exist.`,
+		Example: `  space flags get new-checkout
+  space flags get max-uploads --environment staging`,
+		Args:     cobra.ExactArgs(1),
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := getFlag(cmd, args[0]); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+
+	return cmd
+}
+
+func getFlag(cmd *cobra.Command, name string) error {
+	base, err := flagsBase(cmd)
+	if err != nil {
+		return err
+	}
+
+	item, err := shared.Client.GetBaseItem(base, name)
+	if err != nil {
+		if errors.Is(err, api.ErrBaseItemNotFound) {
+			shared.Logger.Println(styles.Errorf("%s Flag %s doesn't exist, create it with %s", emoji.ErrorExclamation, name, styles.Codef("space flags set %s <value>", name)))
+			return err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to get flag %s: %v", emoji.ErrorExclamation, name, err))
+		return err
+	}
+
+	flag, err := featureflags.FromItem(item)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+		return err
+	}
+	fmt.Println(flag.FormatValue())
+	return nil
+}
@@ -0,0 +1,74 @@
+package flags
+
+import (
+	"sort"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/featureflags"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdFlagsList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:      "list [flags]",
+		Short:    "List the feature flags of your app",
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := listFlags(cmd); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+
+	return cmd
+}
+
+func listFlags(cmd *cobra.Command) error {
+	base, err := flagsBase(cmd)
+	if err != nil {
+		return err
+	}
+
+	items, err := shared.Client.FetchBaseItems(base)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to list flags: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+	if len(items) == 0 {
+		shared.Logger.Printf("The project has no feature flags yet, create one with %s", styles.Code("space flags set <name> <value>"))
+		return nil
+	}
+
+	var flags []*featureflags.Flag
+	for _, item := range items {
+		flag, err := featureflags.FromItem(item)
+		if err != nil {
+			shared.Logger.Printf("%s Skipping invalid item: %v", emoji.Warning, err)
+			continue
+		}
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+
+	shared.Logger.Printf("%s Feature flags:\n", emoji.Flag)
+	for _, flag := range flags {
+		line := styles.Code(flag.Name) + " " + flag.FormatValue() + styles.Subtle(" ("+string(flag.Type)+")")
+		if flag.UpdatedBy != "" {
+			line += styles.Subtle(", updated by " + flag.UpdatedBy)
+			if flag.UpdatedAt != "" {
+				line += styles.Subtle(" at " + flag.UpdatedAt)
+			}
+		}
+		shared.Logger.Printf("  %s", line)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package flags
+
+import (
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/featureflags"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdFlags() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flags",
+		Short: "Manage the feature flags of your app",
+		Long: `Manage the feature flags of your app without a redeploy.
+
+Flags are stored in the Base "` + featureflags.BaseName + `" of the project, one item per flag keyed by its name with a type and a value. Your app reads them at runtime with the Base SDK, e.g. base.get("new-checkout")["value"].
+
+Each environment of the project config is a project with its own flags, choose it with --environment like for space push and space release.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdFlagsSet())
+	cmd.AddCommand(newCmdFlagsGet())
+	cmd.AddCommand(newCmdFlagsList())
+
+	return cmd
+}
+
+// addTargetFlags adds the flags choosing the project of the feature flags
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("dir", "d", "./", "src of the project")
+	cmd.Flags().StringP("id", "i", "", "project id of the project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+}
+
+// flagsBase returns the Base of the feature flags of the project targeted by the flags of cmd
+func flagsBase(cmd *cobra.Command) (*api.BaseRef, error) {
+	projectDir, _ := cmd.Flags().GetString("dir")
+	projectID, _ := cmd.Flags().GetString("id")
+	environment, _ := cmd.Flags().GetString("environment")
+
+	projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+	projectKey, err := shared.GenerateDataKeyIfNotExists(projectID)
+	if err != nil {
+		shared.Logger.Printf("%s Error generating the project key: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	return &api.BaseRef{ProjectKey: projectKey, Base: featureflags.BaseName}, nil
+}
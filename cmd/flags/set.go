@@ -0,0 +1,98 @@
+package flags
+
+import (
+	"errors"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/featureflags"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdFlagsSet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <name> <value>",
+		Short: "Set the value of a feature flag",
+		Long: `Set the value of a feature flag, the flag is created if it doesn't exist.
+
+The value is parsed as the type of the flag: bool, int, float, string or json. New flags are strings unless --type is set, existing flags keep their type unless --type changes it.`,
+		Example: `  space flags set new-checkout true --type bool
+  space flags set max-uploads 10 --type int --environment staging
+  space flags set rollout '{"percent": 10}' --type json`,
+		Args:     cobra.ExactArgs(2),
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			typeName, _ := cmd.Flags().GetString("type")
+			if err := setFlag(cmd, args[0], args[1], typeName); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+	cmd.Flags().StringP("type", "t", "", "type of the value: bool, int, float, string or json")
+
+	return cmd
+}
+
+func setFlag(cmd *cobra.Command, name string, rawValue string, typeName string) error {
+	if err := featureflags.ValidateName(name); err != nil {
+		shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+		return err
+	}
+	var flagType featureflags.Type
+	if typeName != "" {
+		t, err := featureflags.ParseType(typeName)
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+			return err
+		}
+		flagType = t
+	}
+
+	base, err := flagsBase(cmd)
+	if err != nil {
+		return err
+	}
+
+	if flagType == "" {
+		flagType = featureflags.String
+		item, err := shared.Client.GetBaseItem(base, name)
+		switch {
+		case err == nil:
+			if existing, err := featureflags.FromItem(item); err == nil {
+				flagType = existing.Type
+			}
+		case !errors.Is(err, api.ErrBaseItemNotFound):
+			shared.Logger.Println(styles.Errorf("%s Failed to get flag %s: %v", emoji.ErrorExclamation, name, err))
+			return err
+		}
+	}
+
+	value, err := featureflags.Parse(flagType, rawValue)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Invalid value of flag %s: %v", emoji.ErrorExclamation, name, err))
+		return err
+	}
+
+	projectDir, _ := cmd.Flags().GetString("dir")
+	flag := &featureflags.Flag{
+		Name:      name,
+		Type:      flagType,
+		Value:     value,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		UpdatedBy: shared.CurrentUser(projectDir),
+	}
+	if err := shared.Client.PutBaseItems(base, []api.BaseItem{flag.Item()}); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to set flag %s: %v", emoji.ErrorExclamation, name, err))
+		return err
+	}
+
+	shared.Logger.Println(styles.Greenf("%s Set flag %s to %s (%s)", emoji.Check, name, flag.FormatValue(), flagType))
+	return nil
+}
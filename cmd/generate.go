@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/openapi"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// clientsDir is where clients are generated in the project directory by default
+const clientsDir = "clients"
+
+// generatedClient is the json output of space generate client
+type generatedClient struct {
+	Micro string `json:"micro"`
+	Path  string `json:"path"`
+	// Spec is false for clients generated from the public routes of a micro without a spec
+	Spec bool `json:"spec"`
+}
+
+func newCmdGenerate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate code from your project",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdGenerateClient())
+
+	return cmd
+}
+
+func newCmdGenerateClient() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "client [flags]",
+		Short: "Generate typed clients for the APIs of your micros",
+		Long: fmt.Sprintf(`Generate a typed client in TypeScript or Python for the API of every micro, so that frontend micros and other consumers don't have to write fetch wrappers by hand.
+
+The operations and types are read from the %s in the src of the micro, create one with space openapi init. A micro without a spec gets a client for the GET requests to its public routes.
+
+The clients have no dependencies, they use fetch in TypeScript and urllib in Python. They call the micro on the route it's served on, pass the url of the app as the base url to call it from outside of the app.`, openapi.FileName),
+		Example: `  space generate client --lang ts --micro api --out frontend/src/api
+  space generate client --lang py`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckOneOf("lang", string(openapi.LangTypeScript), string(openapi.LangPython))),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			lang, _ := cmd.Flags().GetString("lang")
+			names, _ := cmd.Flags().GetStringSlice("micro")
+			outDir, _ := cmd.Flags().GetString("out")
+			if outDir == "" {
+				outDir = filepath.Join(projectDir, clientsDir)
+			}
+			return generateClients(projectDir, openapi.Lang(lang), names, outDir)
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("lang", "l", string(openapi.LangTypeScript), "language of the clients: ts or py")
+	cmd.Flags().StringSliceP("micro", "m", nil, "micro to generate a client for, can be repeated, defaults to every micro with a spec or public routes")
+	cmd.Flags().String("out", "", fmt.Sprintf("directory to write the clients to, defaults to %s in the project directory", clientsDir))
+	cmd.RegisterFlagCompletionFunc("micro", completeMicroNames)
+
+	return cmd
+}
+
+func generateClients(projectDir string, lang openapi.Lang, names []string, outDir string) error {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	micros := s.Micros
+	if len(names) > 0 {
+		micros = nil
+		for _, name := range names {
+			found := false
+			for _, micro := range s.Micros {
+				if micro.Name == name {
+					micros, found = append(micros, micro), true
+				}
+			}
+			if !found {
+				shared.Logger.Printf("%s Micro %s not found in the Spacefile", emoji.ErrorExclamation, name)
+				return shared.ErrReported
+			}
+		}
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		shared.Logger.Printf("%s Failed to create %s: %s", emoji.ErrorExclamation, outDir, err)
+		return err
+	}
+	var generated []*generatedClient
+	for _, micro := range micros {
+		doc, err := openapi.Load(projectDir, micro)
+		if errors.Is(err, openapi.ErrNoPublicRoutes) && len(names) == 0 {
+			continue
+		} else if errors.Is(err, openapi.ErrNoPublicRoutes) {
+			shared.Logger.Printf("%s %s, or create a spec with %s", emoji.ErrorExclamation, err, styles.Code("space openapi init "+micro.Name))
+			return shared.ErrReported
+		} else if err != nil {
+			shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+			return err
+		}
+
+		content, err := openapi.GenerateClient(doc, micro.Name, lang)
+		if err != nil {
+			shared.Logger.Printf("%s Failed to generate the client of %s: %s", emoji.ErrorExclamation, micro.Name, err)
+			return err
+		}
+		path := filepath.Join(outDir, openapi.ClientFileName(micro.Name, lang))
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			shared.Logger.Printf("%s Failed to write %s: %s", emoji.ErrorExclamation, path, err)
+			return err
+		}
+
+		_, err = os.Stat(openapi.SpecPath(projectDir, micro))
+		client := &generatedClient{Micro: micro.Name, Path: path, Spec: err == nil}
+		generated = append(generated, client)
+		if client.Spec {
+			shared.Logger.Printf("%s Generated %s from the spec of %s", emoji.Check, path, styles.Green(micro.Name))
+		} else {
+			shared.Logger.Printf("%s Generated %s from the public routes of %s", emoji.Check, path, styles.Green(micro.Name))
+		}
+	}
+
+	if len(generated) == 0 {
+		shared.Logger.Printf("%s No micro has a spec or public routes, create a spec with %s", emoji.ErrorExclamation, styles.Code("space openapi init <micro>"))
+		return shared.ErrReported
+	}
+	if shared.JSONOutput() {
+		return shared.PrintJSON(generated)
+	}
+	return nil
+}
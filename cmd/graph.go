@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/internal/topology"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+)
+
+const (
+	graphFormatMermaid = "mermaid"
+	graphFormatDOT     = "dot"
+)
+
+func newCmdGraph() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph [flags]",
+		Short: "Print the topology of your app as a mermaid or graphviz chart",
+		Long: fmt.Sprintf(`Print the topology of your app as a mermaid flowchart or in the dot language of graphviz: its micros with the routes they're served on, the public routes, the scheduled actions and the Bases, Drives and external services the micros use.
+
+The micros, routes and actions are read from the Spacefile. The data and external services aren't known to Space, declare them per micro in the dependencies of %s:
+
+  dependencies:
+    api:
+      - name: users
+        kind: base
+      - name: Stripe
+        kind: external
+        url: https://api.stripe.com
+
+Generate the chart in CI to keep the architecture docs of your project up to date.`, spaceconfig.FileName),
+		Example: `  space graph > docs/topology.mmd
+  space graph --format dot | dot -Tsvg > docs/topology.svg`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckOneOf("format", graphFormatMermaid, graphFormatDOT)),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			format, _ := cmd.Flags().GetString("format")
+
+			g, err := buildGraph(projectDir)
+			if err != nil {
+				return err
+			}
+			switch {
+			case shared.JSONOutput():
+				return shared.PrintJSON(g)
+			case format == graphFormatDOT:
+				fmt.Fprint(cmd.OutOrStdout(), g.DOT())
+			default:
+				fmt.Fprint(cmd.OutOrStdout(), g.Mermaid())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("format", "f", graphFormatMermaid, "format of the chart: mermaid or dot")
+
+	return cmd
+}
+
+// buildGraph reads the topology of the project from the Spacefile and the project config, the app is named after the
+// project directory if the Spacefile has no app_name
+func buildGraph(projectDir string) (*topology.Graph, error) {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	title := s.AppName
+	if title == "" {
+		abs, err := filepath.Abs(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		title = filepath.Base(abs)
+	}
+	g, err := topology.Build(title, s.Micros, config.Dependencies)
+	if err != nil {
+		shared.Logger.Printf("%s Invalid %s: %s", emoji.ErrorExclamation, spaceconfig.FileName, err)
+		return nil, err
+	}
+	return g, nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/manual"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// addHelpAll adds --all to the help command of cobra to print the help of all commands on a single page
+func addHelpAll(root *cobra.Command) {
+	root.InitDefaultHelpCmd()
+	helpCmd, _, err := root.Find([]string{"help"})
+	if err != nil || helpCmd == root {
+		return
+	}
+
+	helpCmd.Example = `  space help push
+  space help --all | less`
+	helpCmd.Flags().Bool("all", false, "print the help of all commands on a single page")
+
+	run := helpCmd.Run
+	helpCmd.Run = nil
+	helpCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); !all {
+			run(cmd, args)
+			return nil
+		}
+		if err := manual.WriteAll(cmd.OutOrStdout(), cmd.Root()); err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to print the help: %v", emoji.ErrorExclamation, err))
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/internal/dotenv"
+	"github.com/deta/space/internal/export"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/util/fs"
+	"github.com/spf13/cobra"
+)
+
+func newCmdImport() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <archive> [flags]",
+		Short: "Recreate a project from an archive created with space export",
+		Long: `Recreate a project from an archive created with space export.
+
+The source code, Spacefile and Discovery file are extracted into the target directory and a new project is created and linked to it. If the archive contains environment variable values, they are decrypted and written to a .env file in the target directory.`,
+		Example:  `  space import my-app-export.zip --name my-app-copy`,
+		Args:     cobra.ExactArgs(1),
+		PreRunE:  shared.CheckNotEmpty("name", "dir"),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectName, _ := cmd.Flags().GetString("name")
+			region, _ := cmd.Flags().GetString("region")
+
+			if err := importProject(args[0], projectDir, projectName, region); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "directory to import the project into")
+	cmd.Flags().StringP("name", "n", "", "name of the new project, defaults to the name of the exported project")
+	cmd.Flags().StringP("region", "r", "", "region to create the project in, defaults to the region of your space")
+
+	return cmd
+}
+
+func importProject(archivePath string, projectDir string, projectName string, region string) error {
+	b, err := export.Read(archivePath)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to read archive: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	if empty, err := fs.IsEmpty(projectDir); err != nil {
+		shared.Logger.Printf("%s Failed to check directory %s: %s", emoji.ErrorExclamation, projectDir, err)
+		return err
+	} else if !empty {
+		shared.Logger.Printf("%s Directory %s is not empty, please import into an empty directory", emoji.ErrorExclamation, projectDir)
+		return errors.New("directory not empty")
+	}
+
+	if projectName == "" {
+		projectName = b.Manifest.Project.Name
+	}
+	if err := validateProjectName(projectName); err != nil {
+		shared.Logger.Printf("%s Invalid project name: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	var values map[string]string
+	if len(b.Manifest.EnvValues) > 0 {
+		passphrase, err := readPassphrase("Passphrase to decrypt the environment variable values", false)
+		if err != nil {
+			return err
+		}
+
+		raw, err := crypt.Open(passphrase, b.Manifest.EnvValues)
+		if err != nil {
+			shared.Logger.Printf("%s Failed to decrypt environment variable values: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			shared.Logger.Printf("%s Invalid environment variable values: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+	}
+
+	shared.Logger.Printf("\n%s Extracting %s into %s...", emoji.Package, styles.Code(archivePath), styles.Code(projectDir))
+	if err := b.ExtractSource(projectDir); err != nil {
+		shared.Logger.Printf("%s Failed to extract archive: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	if len(values) > 0 {
+		if err := writeEnvFile(filepath.Join(projectDir, ".env"), values); err != nil {
+			shared.Logger.Printf("%s Failed to write .env file: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+		shared.Logger.Printf("%s Wrote %d environment variable values to %s", emoji.Key, len(values), styles.Code(".env"))
+	}
+
+	if err := runtime.AddSpaceToGitignore(projectDir); err != nil {
+		shared.Logger.Printf("failed to add .space to gitignore: %s", err)
+		return err
+	}
+
+	meta, err := createProject(projectName, region)
+	if err != nil {
+		if errors.Is(auth.ErrNoAccessTokenFound, err) {
+			shared.Logger.Println(shared.LoginInfo())
+			return err
+		}
+		shared.Logger.Printf("failed to create project: %s", err)
+		return err
+	}
+
+	if err := runtime.StoreProjectMeta(projectDir, meta); err != nil {
+		shared.Logger.Printf("failed to save project meta, %s", err)
+		return err
+	}
+
+	shared.Logger.Println(styles.Greenf("\n%s Imported %s as project %s!", emoji.Check, b.Manifest.Project.Name, projectName))
+	if len(b.Manifest.Schedules) > 0 {
+		shared.Logger.Printf("L %d schedules will be active once you push and install the project", len(b.Manifest.Schedules))
+	}
+	shared.Logger.Println(shared.ProjectNotes(projectName, meta.ID))
+	return nil
+}
+
+func writeEnvFile(path string, values map[string]string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := dotenv.Write(f, values); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/learn"
+	"github.com/deta/space/pkg/components/choose"
+	"github.com/deta/space/pkg/components/confirm"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+const (
+	learnRun  = "Run it for me"
+	learnRan  = "I ran it, check it"
+	learnHint = "Show a hint"
+	learnSkip = "Skip this step"
+	learnQuit = "Quit, continue later"
+)
+
+func newCmdLearn() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "learn [flags]",
+		Short: "Learn Space in a tutorial through new, dev, push and release",
+		Long: `Learn Space in an interactive tutorial: create a project, run it locally, push a revision and release it.
+
+The tutorial works in a sandbox project with a small static app, by default in a directory of the temp dir. Every step explains its command, runs it for you or checks that you ran it yourself and shows a hint if it isn't done yet. The progress is kept in the sandbox, space learn continues where you left off.`,
+		Example: `  space learn
+  space learn --dir ./learn-space
+  space learn --restart`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !shared.IsOutputInteractive() {
+				return errors.New("space learn is interactive, run it in a terminal")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			restart, _ := cmd.Flags().GetBool("restart")
+
+			if err := runLearn(dir, restart); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "", "directory of the sandbox project, defaults to space-learn in the temp dir")
+	cmd.MarkFlagDirname("dir")
+	cmd.Flags().Bool("restart", false, "start the tutorial from the first step, the sandbox is kept")
+
+	return cmd
+}
+
+// learnPlatform answers the checks of the tutorial with the api
+type learnPlatform struct{}
+
+func (learnPlatform) Revisions(projectID string) (int, error) {
+	res, err := shared.GetRevisions(projectID, true)
+	if err != nil {
+		return 0, err
+	}
+	return len(res.Revisions), nil
+}
+
+func (learnPlatform) Releases(projectID string) (int, error) {
+	res, err := shared.Client.ListReleases(&api.ListReleasesRequest{AppID: projectID, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	return len(res.Releases), nil
+}
+
+func runLearn(dir string, restart bool) error {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "space-learn")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to resolve the sandbox directory: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+	if err := learn.WriteSample(dir); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to create the sandbox: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	progress := &learn.Progress{}
+	if !restart {
+		if progress, err = learn.LoadProgress(dir); err != nil {
+			shared.Logger.Println(styles.Errorf("%s %v, start over with %s", emoji.ErrorExclamation, err, styles.Code("space learn --restart")))
+			return err
+		}
+	}
+
+	sandbox := &learn.Sandbox{
+		Dir:      dir,
+		Platform: learnPlatform{},
+		DevURL:   fmt.Sprintf("http://localhost:%d", learn.DevPort),
+	}
+
+	shared.Logger.Printf("%s Welcome to Space! This tutorial takes you from a new project to a released app.\n", emoji.Waving)
+	shared.Logger.Printf("The sandbox project is in %s\n", styles.Code(dir))
+	if len(progress.Done) > 0 {
+		ok, err := confirm.Run("learn.continue", fmt.Sprintf("Continue with step %d?", stepNumber(progress.Next())))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			progress = &learn.Progress{}
+		}
+	}
+
+	for step := progress.Next(); step != nil; step = progress.Next() {
+		done, err := learnStep(sandbox, step)
+		if err != nil {
+			return err
+		}
+		if !done {
+			shared.Logger.Printf("\nContinue the tutorial later with %s", styles.Code("space learn"+learnDirFlag(dir)))
+			return nil
+		}
+		progress.MarkDone(step.ID)
+		if err := progress.Save(dir); err != nil {
+			shared.Logger.Printf("%s Failed to save the progress of the tutorial: %v", emoji.Warning, err)
+		}
+	}
+
+	shared.Logger.Printf("\n%s You released your first app on Space!", emoji.PartyPopper)
+	shared.Logger.Printf("Find it in Discovery once you list the release, or start your own project with %s", styles.Code("space new"))
+	return nil
+}
+
+func learnStep(sandbox *learn.Sandbox, step *learn.Step) (bool, error) {
+	command := "space " + strings.Join(step.Command, " ")
+	shared.Logger.Printf("\n%s", styles.Boldf("Step %d/%d: %s", stepNumber(step), len(learn.Steps), step.Title))
+	shared.Logger.Printf("%s\n", step.Explain)
+	if step.Background {
+		shared.Logger.Printf("Run in another terminal:\n\n  %s\n  %s\n", styles.Code("cd "+sandbox.Dir), styles.Code(command))
+	} else {
+		shared.Logger.Printf("The command:\n\n  %s\n", styles.Code(command))
+	}
+
+	choices := []string{learnRun, learnRan, learnHint, learnSkip, learnQuit}
+	if step.Background {
+		// the tutorial can't go on while the command runs
+		choices = choices[1:]
+	}
+	for {
+		choice, err := choose.Run("learn."+step.ID, "What's next?", choices...)
+		if err != nil {
+			return false, err
+		}
+
+		switch choice {
+		case learnRun:
+			shared.Logger.Println(styles.Subtlef("$ %s", command))
+			// the command reports its own errors, the check below tells what's missing
+			Execute(append(append([]string{}, step.Command...), "--dir", sandbox.Dir))
+		case learnHint:
+			shared.Logger.Printf("%s %s\n", emoji.LightBulb, step.Hint)
+			continue
+		case learnSkip:
+			return true, nil
+		case learnQuit:
+			return false, nil
+		}
+
+		if err := step.Check(sandbox); err != nil {
+			shared.Logger.Printf("%s Not done yet: %v", emoji.Warning, err)
+			shared.Logger.Printf("%s %s\n", emoji.LightBulb, step.Hint)
+			continue
+		}
+		shared.Logger.Printf("%s %s, well done!", emoji.Check, step.Title)
+		return true, nil
+	}
+}
+
+func stepNumber(step *learn.Step) int {
+	for i, s := range learn.Steps {
+		if s == step {
+			return i + 1
+		}
+	}
+	return len(learn.Steps)
+}
+
+func learnDirFlag(dir string) string {
+	if dir == filepath.Join(os.TempDir(), "space-learn") {
+		return ""
+	}
+	return " --dir " + dir
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/deta/space/cmd/shared"
 	"github.com/deta/space/internal/api"
@@ -19,8 +18,9 @@ func newCmdLink() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:      "link [flags]",
 		Short:    "Link a local directory with an existing project",
+		Example:  `  space link --id a0abc1234`,
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 			projectDir, _ := cmd.Flags().GetString("dir")
 			projectID, _ := cmd.Flags().GetString("id")
@@ -29,13 +29,18 @@ func newCmdLink() *cobra.Command {
 				shared.Logger.Printf("Grab the %s of the project you want to link to using Teletype.\n\n", styles.Code("Project ID"))
 
 				if projectID, err = selectLinkProjectID(); err != nil {
-					os.Exit(1)
+					return err
 				}
 			}
 
-			if err := link(projectDir, projectID); err != nil {
-				os.Exit(1)
+			result, err := link(projectDir, projectID)
+			if err != nil {
+				return err
 			}
+			if shared.JSONOutput() {
+				return shared.PrintJSON(result)
+			}
+			return nil
 		},
 		PreRunE: shared.CheckAll(
 			shared.CheckExists("dir"),
@@ -51,6 +56,7 @@ func newCmdLink() *cobra.Command {
 
 func selectLinkProjectID() (string, error) {
 	promptInput := text.Input{
+		Key:         "link.id",
 		Prompt:      "Project ID",
 		Placeholder: "",
 		Validator: func(value string) error {
@@ -64,34 +70,42 @@ func selectLinkProjectID() (string, error) {
 	return text.Run(&promptInput)
 }
 
-func link(projectDir string, projectID string) error {
+// linkResult is printed with --output json
+type linkResult struct {
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	Alias     string `json:"alias,omitempty"`
+	Dir       string `json:"dir"`
+}
+
+func link(projectDir string, projectID string) (*linkResult, error) {
 	if err := runtime.AddSpaceToGitignore(projectDir); err != nil {
 		shared.Logger.Println("failed to add .space to .gitignore, %w", err)
-		return err
+		return nil, err
 	}
 
 	projectRes, err := shared.Client.GetProject(&api.GetProjectRequest{ID: projectID})
 	if err != nil {
 		if errors.Is(auth.ErrNoAccessTokenFound, err) {
 			shared.Logger.Println(shared.LoginInfo())
-			return err
+			return nil, err
 		}
 		if errors.Is(err, api.ErrProjectNotFound) {
 			shared.Logger.Println(styles.Errorf("%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
-			return err
+			return nil, err
 		}
 
 		shared.Logger.Println(styles.Errorf("%s Failed to link project, %s", emoji.ErrorExclamation, err.Error()))
-		return err
+		return nil, err
 	}
 
 	err = runtime.StoreProjectMeta(projectDir, &runtime.ProjectMeta{ID: projectRes.ID, Name: projectRes.Name, Alias: projectRes.Alias})
 	if err != nil {
 		shared.Logger.Printf("failed to link project: %s", err)
-		return err
+		return nil, err
 	}
 
 	shared.Logger.Println(styles.Greenf("%s Project", emoji.Link), styles.Pink(projectRes.Name), styles.Green("was linked!"))
 	shared.Logger.Println(shared.ProjectNotes(projectRes.Name, projectRes.ID))
-	return nil
+	return &linkResult{ProjectID: projectRes.ID, Name: projectRes.Name, Alias: projectRes.Alias, Dir: projectDir}, nil
 }
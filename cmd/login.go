@@ -10,6 +10,8 @@ import (
 	"github.com/deta/space/cmd/shared"
 	"github.com/deta/space/internal/api"
 	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/home"
+	"github.com/deta/space/internal/runtime"
 	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
 	"github.com/deta/space/pkg/components/text"
@@ -18,48 +20,75 @@ import (
 
 func newCmdLogin() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:      "login",
-		Short:    "Login to space",
+		Use:   "login",
+		Short: "Login to space",
+		Long: `Login to space with an access token, generate one in your Space settings.
+
+In CI pass the token on standard input with --token-stdin, or set SPACE_ACCESS_TOKEN: every command uses the token of the variable while it's set, space login then only checks it and stores nothing.`,
+		Example: `  space login
+  echo $SPACE_ACCESS_TOKEN | space login --token-stdin`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if os.Getenv(auth.SpaceAccessTokenEnv) != "" {
+				return nil
+			}
+			return shared.CheckInteractiveOr("token-stdin")(cmd, args)
+		},
 		PostRunE: shared.CheckLatestVersion,
 		Args:     cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
-			var err error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokenStdin, _ := cmd.Flags().GetBool("token-stdin")
 			withToken, _ := cmd.Flags().GetBool("with-token")
 
-			var accessToken string
-			if withToken {
-				input, err := io.ReadAll(os.Stdin)
-				if err != nil {
-					shared.Logger.Println("failed to read access token from standard input")
-					os.Exit(1)
+			if !tokenStdin && !withToken && os.Getenv(auth.SpaceAccessTokenEnv) != "" {
+				if err := checkEnvAccessToken(); err != nil {
+					return err
 				}
+				return nil
+			}
 
-				accessToken = strings.TrimSpace(string(input))
-			} else {
-				shared.Logger.Printf("To authenticate the Space CLI with your Space account, generate a new %s in your Space settings and paste it below:\n\n", styles.Code("access token"))
-				accessToken, err = inputAccessToken()
-				if err != nil {
-					os.Exit(1)
-				}
+			accessToken, err := readAccessToken(tokenStdin || withToken)
+			if err != nil {
+				return err
 			}
 
 			if err := login(accessToken); err != nil {
 				shared.Logger.Printf(styles.Errorf("%s Failed to login: %v", emoji.ErrorExclamation, err))
-				os.Exit(1)
+				return err
 			}
+			return nil
 		},
 	}
 
-	cmd.Flags().BoolP("with-token", "t", false, "Read token from standard input")
-	if !shared.IsOutputInteractive() {
-		cmd.MarkFlagRequired("with-token")
-	}
+	cmd.Flags().BoolP("token-stdin", "t", false, "read the access token from standard input")
+	cmd.Flags().Bool("with-token", false, "read the access token from standard input")
+	cmd.Flags().MarkDeprecated("with-token", "use --token-stdin")
 
 	return cmd
 }
 
+// readAccessToken reads the access token from standard input or prompts for it
+func readAccessToken(stdin bool) (string, error) {
+	if !stdin {
+		shared.Logger.Printf("To authenticate the Space CLI with your Space account, generate a new %s in your Space settings and paste it below:\n\n", styles.Code("access token"))
+		return inputAccessToken()
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		shared.Logger.Println("failed to read access token from standard input")
+		return "", err
+	}
+	accessToken := strings.TrimSpace(string(input))
+	if accessToken == "" {
+		shared.Logger.Println(styles.Errorf("%s No access token on standard input", emoji.ErrorExclamation))
+		return "", errors.New("no access token on standard input")
+	}
+	return accessToken, nil
+}
+
 func inputAccessToken() (string, error) {
 	promptInput := text.Input{
+		Key:         "login.token",
 		Prompt:      "Enter access token",
 		Placeholder: "",
 		Validator: func(value string) error {
@@ -75,11 +104,36 @@ func inputAccessToken() (string, error) {
 }
 
 func login(accessToken string) (err error) {
-	// Check if the access token is valid
-	_, err = shared.Client.GetSpace(&api.GetSpaceRequest{
+	if err := validateAccessToken(accessToken); err != nil {
+		return err
+	}
+
+	store, err := auth.StoreAccessToken(accessToken)
+	if errors.Is(err, home.ErrNoState) || errors.Is(err, home.ErrReadOnly) {
+		shared.Logger.Printf(styles.Errorf("%s Can't store the access token: %v", emoji.ErrorExclamation, err))
+		shared.Logger.Printf("Set %s to use the token without storing it.", styles.Code(auth.SpaceAccessTokenEnv))
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store access token: %w", err)
+	}
+
+	// cached projects and revisions belong to the previous user
+	runtime.ClearCache()
+
+	shared.Logger.Println(styles.Green("👍 Login Successful!"))
+	shared.Logger.Printf("The access token is stored in %s.", store.Name())
+	if os.Getenv(auth.SpaceAccessTokenEnv) != "" {
+		shared.Logger.Printf("%s %s is set and takes precedence over the stored token.", emoji.Warning, styles.Code(auth.SpaceAccessTokenEnv))
+	}
+	return nil
+}
+
+// validateAccessToken checks that the access token belongs to a space
+func validateAccessToken(accessToken string) error {
+	_, err := shared.Client.GetSpace(&api.GetSpaceRequest{
 		AccessToken: accessToken,
 	})
-
 	if err != nil {
 		if errors.Is(err, auth.ErrInvalidAccessToken) {
 			shared.Logger.Printf(styles.Errorf("%s Invalid access token. Please generate a valid token from your Space settings.", emoji.ErrorExclamation))
@@ -88,12 +142,17 @@ func login(accessToken string) (err error) {
 		shared.Logger.Printf(styles.Errorf("%s Failed to validate access token: %v", emoji.ErrorExclamation, err))
 		return fmt.Errorf("failed to validate access token: %w", err)
 	}
+	return nil
+}
 
-	err = auth.StoreAccessToken(accessToken)
-	if err != nil {
-		return fmt.Errorf("failed to store access token: %w", err)
+// checkEnvAccessToken checks the token of SPACE_ACCESS_TOKEN instead of storing a token, the variable takes precedence
+// over a stored token anyway
+func checkEnvAccessToken() error {
+	if err := validateAccessToken(os.Getenv(auth.SpaceAccessTokenEnv)); err != nil {
+		shared.Logger.Printf("Fix or unset %s to login.", styles.Code(auth.SpaceAccessTokenEnv))
+		return err
 	}
-
 	shared.Logger.Println(styles.Green("👍 Login Successful!"))
+	shared.Logger.Printf("Every command uses the access token of %s while it's set, it isn't stored.", styles.Code(auth.SpaceAccessTokenEnv))
 	return nil
 }
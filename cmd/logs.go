@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/logs"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdLogs() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs [flags]",
+		Short: "Show the runtime logs of your app",
+		Long: `Show the runtime logs of the micros of your app in your Space.
+
+--since only shows the logs after a duration ago like 15m or a time like 2023-05-01T10:00:00Z, with --follow new logs are streamed until you stop with Ctrl+C. With --output json the log entries are printed as json lines.`,
+		Example: `  space logs
+  space logs --micro api --since 1h
+  space logs --follow`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "micro", "since", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			micro, _ := cmd.Flags().GetString("micro")
+			sinceFlag, _ := cmd.Flags().GetString("since")
+			follow, _ := cmd.Flags().GetBool("follow")
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+
+			var since time.Time
+			if cmd.Flags().Changed("since") {
+				since, err = logs.ParseSince(sinceFlag, time.Now())
+				if err != nil {
+					shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+					return err
+				}
+			}
+
+			width, err := logsMicroWidth(projectDir, micro)
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+				return err
+			}
+
+			return tailLogs(&api.GetAppLogsRequest{AppID: projectID, Micro: micro, Since: since, Follow: follow}, width)
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("id", "i", "", "project id of an existing project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+	cmd.Flags().StringP("micro", "m", "", "only show the logs of the micro")
+	cmd.Flags().String("since", "", "only show the logs after a duration ago like 15m or a time like 2023-05-01T10:00:00Z")
+	cmd.Flags().BoolP("follow", "f", false, "stream new logs until interrupted")
+
+	return cmd
+}
+
+// logsMicroWidth returns the width the micro names are padded to, the micros are read from the Spacefile of the
+// project if it has one, which also checks that --micro is one of them
+func logsMicroWidth(projectDir string, micro string) (int, error) {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		return len(micro), nil
+	}
+
+	width := 0
+	var names []string
+	for _, m := range s.Micros {
+		if micro == "" || m.Name == micro {
+			if len(m.Name) > width {
+				width = len(m.Name)
+			}
+		}
+		names = append(names, m.Name)
+	}
+	if micro != "" && width == 0 {
+		return 0, fmt.Errorf("micro %s is not in the Spacefile, the micros are %s", micro, strings.Join(names, ", "))
+	}
+	return width, nil
+}
+
+func tailLogs(r *api.GetAppLogsRequest, width int) error {
+	readCloser, err := shared.Client.GetAppLogs(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoAccessTokenFound):
+			shared.Logger.Println(shared.LoginInfo())
+		case errors.Is(err, api.ErrProjectNotFound):
+			shared.Logger.Println(styles.Errorf("%s Project %s not found", emoji.ErrorExclamation, styles.Code(r.AppID)))
+		default:
+			shared.Logger.Println(styles.Errorf("%s Failed to get the logs: %v", emoji.ErrorExclamation, err))
+		}
+		return err
+	}
+	defer readCloser.Close()
+
+	// closing the stream stops the scanner on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		readCloser.Close()
+	}()
+
+	enc := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(readCloser)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry := logs.Parse(line)
+		if shared.JSONOutput() {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Println(logs.Format(entry, width))
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to read the logs: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+	return nil
+}
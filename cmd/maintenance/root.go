@@ -0,0 +1,23 @@
+package maintenance
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdMaintenance() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Turn the maintenance mode of your app on or off",
+		Long: `Turn the maintenance mode of your app on or off.
+
+While the maintenance mode is on, the app serves a maintenance page with your message instead of the micros, e.g. during a data migration. The mode is shown by space status.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdMaintenanceOn())
+	cmd.AddCommand(newCmdMaintenanceOff())
+
+	return cmd
+}
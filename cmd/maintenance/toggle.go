@@ -0,0 +1,102 @@
+package maintenance
+
+import (
+	"errors"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdMaintenanceOn() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "on [flags]",
+		Short: "Serve a maintenance page instead of your app",
+		Example: `  space maintenance on --message "Back at 5pm"
+  space maintenance on --environment staging`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			message, _ := cmd.Flags().GetString("message")
+			if err := setMaintenance(cmd, true, message); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+	cmd.Flags().StringP("message", "m", "", "message of the maintenance page")
+
+	return cmd
+}
+
+func newCmdMaintenanceOff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:      "off [flags]",
+		Short:    "Serve your app again",
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setMaintenance(cmd, false, ""); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+
+	return cmd
+}
+
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("dir", "d", "./", "src of the project")
+	cmd.Flags().StringP("id", "i", "", "project id of the project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+}
+
+func setMaintenance(cmd *cobra.Command, enabled bool, message string) error {
+	projectDir, _ := cmd.Flags().GetString("dir")
+	projectID, _ := cmd.Flags().GetString("id")
+	environment, _ := cmd.Flags().GetString("environment")
+
+	projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		if err := shared.ConfirmProtected(cmd, projectDir, projectID, "turn on the maintenance mode"); err != nil {
+			return err
+		}
+	}
+
+	m, err := shared.Client.SetMaintenance(&api.SetMaintenanceRequest{AppID: projectID, Enabled: enabled, Message: message})
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoAccessTokenFound):
+			shared.Logger.Println(shared.LoginInfo())
+		case errors.Is(err, api.ErrProjectNotFound):
+			shared.Logger.Println(styles.Errorf("%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
+		default:
+			shared.Logger.Println(styles.Errorf("%s Failed to set the maintenance mode: %v", emoji.ErrorExclamation, err))
+		}
+		return err
+	}
+
+	if !m.Enabled {
+		shared.Logger.Println(styles.Greenf("%s Maintenance mode is off, your app is served again", emoji.Check))
+		return nil
+	}
+	shared.Logger.Println(styles.Greenf("%s Maintenance mode is on, your app serves a maintenance page", emoji.Check))
+	if m.Message != "" {
+		shared.Logger.Printf("  %s %s", styles.Subtle("Message:"), m.Message)
+	}
+	shared.Logger.Printf("\nTurn it off with %s", styles.Code("space maintenance off"))
+	return nil
+}
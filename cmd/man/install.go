@@ -0,0 +1,71 @@
+package man
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/manual"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdManInstall() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install [flags]",
+		Short: "Install a man page for each command",
+		Long: `Install a man page for each command, e.g. man space-push.
+
+The pages are written to the man1 directory of the first directory of $MANPATH, or of ~/.local/share/man if it isn't set. Run the command again after upgrading the CLI to update them.`,
+		Example: `  space man install
+  space man install --dir /usr/local/share/man`,
+		Args:    cobra.NoArgs,
+		PreRunE: shared.CheckNotEmpty("dir"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+
+			var err error
+			if dir == "" {
+				dir, err = manual.DefaultDir()
+				if err != nil {
+					shared.Logger.Println(styles.Errorf("%s Failed to find the man directory: %v", emoji.ErrorExclamation, err))
+					return err
+				}
+			}
+
+			n, err := manual.Install(cmd.Root(), dir, shared.SpaceVersion)
+			if err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to install man pages: %v", emoji.ErrorExclamation, err))
+				return err
+			}
+			shared.Logger.Printf("%s Installed %d man pages to %s", emoji.Check, n, styles.Code(dir))
+
+			if !inManPath(dir) {
+				shared.Logger.Printf("%s %s is not searched by man, add it to %s", emoji.Warning, dir, styles.Code("MANPATH"))
+				return nil
+			}
+			shared.Logger.Printf("\nRead them with %s", styles.Code("man space"))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "", "man directory to install the pages to, defaults to the first directory of MANPATH or ~/.local/share/man")
+	cmd.MarkFlagDirname("dir")
+
+	return cmd
+}
+
+// inManPath reports if man searches dir, it's assumed to be searched if manpath isn't available
+func inManPath(dir string) bool {
+	out, err := exec.Command("manpath").Output()
+	if err != nil {
+		return true
+	}
+	for _, searched := range strings.Split(strings.TrimSpace(string(out)), ":") {
+		if strings.TrimRight(searched, "/") == strings.TrimRight(dir, "/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package man
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdMan() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "man",
+		Short: "Manage the man pages of the CLI",
+		Long: `Manage the man pages of the CLI.
+
+The man pages are generated from the help of the commands, use space help --all to read the help of all commands on a single page instead.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdManInstall())
+
+	return cmd
+}
@@ -0,0 +1,182 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/engines"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	pkgspacefile "github.com/deta/space/pkg/spacefile"
+	"github.com/spf13/cobra"
+)
+
+func newCmdMigrateEngine() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "engine --to <engine> [flags]",
+		Short: "Upgrade the engine of your micros",
+		Long: `Upgrade the engine of your micros.
+
+Updates the engine of all micros with the same runtime as the new engine, or only of the micro given with --micro. The source of the micros is checked for known incompatibilities of the upgrade, e.g. modules removed from the standard library. The Spacefile is only changed, comments and formatting are kept.`,
+		Example:  `  space migrate engine --to python3.9`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckNotEmpty("to", "micro")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			to, _ := cmd.Flags().GetString("to")
+			micro, _ := cmd.Flags().GetString("micro")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if err := migrateEngine(projectDir, to, micro, dryRun); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project to migrate")
+	cmd.Flags().String("to", "", "engine to upgrade to, e.g. python3.9")
+	cmd.Flags().StringP("micro", "m", "", "only upgrade this micro")
+	cmd.Flags().Bool("dry-run", false, "only check for incompatibilities, don't change the Spacefile")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func migrateEngine(projectDir string, to string, microName string, dryRun bool) error {
+	toVersion, err := engines.ParseVersion(to)
+	if err != nil {
+		shared.Logger.Printf("%s Only engines with versions can be migrated to: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	if !isSpacefileEngine(to) {
+		shared.Logger.Printf("%s %s is not an engine of the Spacefile, use one of %s", emoji.ErrorExclamation, styles.Code(to), strings.Join(spacefile.Engines(), ", "))
+		return shared.ErrReported
+	}
+	if err := checkEngineSupported(to); err != nil {
+		return err
+	}
+
+	spacefilePath := filepath.Join(projectDir, spacefile.SpacefileName)
+	s, err := spacefile.ParseSpacefile(spacefilePath)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to parse Spacefile: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	updates := map[string]string{}
+	findings := 0
+	for _, micro := range s.Micros {
+		if microName != "" && micro.Name != microName {
+			continue
+		}
+		if microName == "" {
+			// only upgrade micros of the same runtime
+			from, err := engines.ParseVersion(micro.Engine)
+			if err != nil || from.Runtime != toVersion.Runtime {
+				continue
+			}
+		}
+		if micro.Engine == to {
+			continue
+		}
+		updates[micro.Name] = to
+
+		shared.Logger.Printf("\n%s %s: %s -> %s", emoji.Package, styles.Bold(micro.Name), micro.Engine, styles.Blue(to))
+		microFindings, err := engines.Check(filepath.Join(projectDir, micro.Src), micro.Engine, to)
+		if err != nil {
+			shared.Logger.Printf("%s Failed to check for incompatibilities: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+		for _, f := range microFindings {
+			path, err := filepath.Rel(projectDir, f.Path)
+			if err != nil {
+				path = f.Path
+			}
+			shared.Logger.Printf("L %s %s", styles.Code(fmt.Sprintf("%s:%d", path, f.Line)), f.Rule.Message)
+		}
+		if len(microFindings) == 0 {
+			shared.Logger.Printf("L No known incompatibilities found")
+		}
+		findings += len(microFindings)
+	}
+
+	if len(updates) == 0 {
+		shared.Logger.Printf("%s No micros to upgrade to %s", emoji.Check, styles.Code(to))
+		return nil
+	}
+	if findings > 0 {
+		shared.Logger.Printf("\n%s Found %d known incompatibilities, please review them before pushing", emoji.Warning, findings)
+	}
+	if dryRun {
+		return nil
+	}
+
+	editable, err := pkgspacefile.Load(spacefilePath)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to read Spacefile: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	for name, engine := range updates {
+		if err := editable.SetEngine(name, engine); err != nil {
+			shared.Logger.Printf("%s Failed to update Spacefile: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+	}
+	// the Spacefile is only written if it stays valid
+	if err := editable.Validate(); err != nil {
+		shared.Logger.Printf("%s The upgraded Spacefile would be invalid: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	if err := editable.Write(spacefilePath); err != nil {
+		shared.Logger.Printf("%s Failed to write Spacefile: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	shared.Logger.Println(styles.Greenf("\n%s Upgraded %d micros to %s!", emoji.Sparkles, len(updates), to))
+	return nil
+}
+
+// isSpacefileEngine reports if the engine is allowed by the Spacefile schema
+func isSpacefileEngine(engine string) bool {
+	for _, e := range spacefile.Engines() {
+		if e == engine {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEngineSupported checks that the engine is supported by Space, engines can't be checked offline
+func checkEngineSupported(engine string) error {
+	res, err := shared.Client.ListEngines()
+	if err != nil {
+		shared.Logger.Printf("%s Could not check if %s is supported: %s", emoji.Warning, styles.Code(engine), err)
+		return nil
+	}
+
+	for _, e := range res.Engines {
+		if e.Name != engine {
+			continue
+		}
+		switch e.Status {
+		case api.EngineSupported:
+			return nil
+		case api.EngineDeprecated:
+			shared.Logger.Printf("%s %s is deprecated, consider upgrading to a newer engine", emoji.Warning, styles.Code(engine))
+			return nil
+		default:
+			shared.Logger.Printf("%s %s is no longer supported", emoji.ErrorExclamation, styles.Code(engine))
+			return errors.New("engine not supported")
+		}
+	}
+
+	shared.Logger.Printf("%s %s is not supported by Space", emoji.ErrorExclamation, styles.Code(engine))
+	return errors.New("engine not supported")
+}
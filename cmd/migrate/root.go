@@ -0,0 +1,19 @@
+package migrate
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdMigrate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade your project to newer versions of Space features",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdMigrateEngine())
+
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/deta/space/cmd/shared"
 	"github.com/deta/space/internal/api"
 	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/quota"
 	"github.com/deta/space/internal/runtime"
 	"github.com/deta/space/internal/spacefile"
 	"github.com/deta/space/pkg/components/confirm"
@@ -20,34 +21,45 @@ import (
 
 func newCmdNew() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:      "new [flags]",
-		Short:    "Create new project",
+		Use:   "new [flags]",
+		Short: "Create new project",
+		Example: `  space new --name my-app
+  space new --dir ./my-app --blank`,
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			projectDir, _ := cmd.Flags().GetString("dir")
 			blankProject, _ := cmd.Flags().GetBool("blank")
 			projectName, _ := cmd.Flags().GetString("name")
+			region, _ := cmd.Flags().GetString("region")
+
+			if err := shared.RequireAnswers(newAnswerKeys(cmd, projectDir, blankProject)...); err != nil {
+				return err
+			}
 
 			if !cmd.Flags().Changed("name") {
 				abs, err := filepath.Abs(projectDir)
 				if err != nil {
 					shared.Logger.Printf("%sError getting absolute path of project directory: %s", styles.ErrorExclamation, err.Error())
-					os.Exit(1)
+					return err
 				}
 
 				name := filepath.Base(abs)
 				projectName, err = selectProjectName(name)
 				if err != nil {
-					os.Exit(1)
+					return err
 				}
 			}
 
-			if err := newProject(projectDir, projectName, blankProject); err != nil {
-				os.Exit(1)
+			projectID, err := newProject(projectDir, projectName, blankProject, region)
+			if err != nil {
+				return err
 			}
+			shared.CopyToClipboard(cmd, "project id", projectID)
+			return nil
 		},
 		PreRunE: shared.CheckAll(
 			shared.CheckExists("dir"),
+			shared.CheckInteractiveOr("name"),
 			func(cmd *cobra.Command, args []string) error {
 				if cmd.Flags().Changed("name") {
 					name, _ := cmd.Flags().GetString("name")
@@ -62,14 +74,24 @@ func newCmdNew() *cobra.Command {
 	cmd.Flags().StringP("dir", "d", "./", "src of project to release")
 	cmd.MarkFlagDirname("dir")
 	cmd.Flags().BoolP("blank", "b", false, "create blank project")
-
-	if !shared.IsOutputInteractive() {
-		cmd.MarkFlagRequired("name")
-	}
+	cmd.Flags().StringP("region", "r", "", "region to create the project in, defaults to the region of your space")
+	shared.AddCopyFlag(cmd, "project id")
 
 	return cmd
 }
 
+// newAnswerKeys returns the prompts of the command which aren't replaced by flags
+func newAnswerKeys(cmd *cobra.Command, projectDir string, blankProject bool) []string {
+	var keys []string
+	if !cmd.Flags().Changed("name") {
+		keys = append(keys, "new.name")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "Spacefile")); errors.Is(err, os.ErrNotExist) && !blankProject {
+		keys = append(keys, "new.setup")
+	}
+	return keys
+}
+
 func validateProjectName(projectName string) error {
 	if len(projectName) < 4 {
 		return fmt.Errorf("project name must be at least 4 characters long")
@@ -84,6 +106,7 @@ func validateProjectName(projectName string) error {
 
 func selectProjectName(placeholder string) (string, error) {
 	promptInput := text.Input{
+		Key:         "new.name",
 		Prompt:      "What is your project's name?",
 		Placeholder: placeholder,
 		Validator:   validateProjectName,
@@ -92,13 +115,18 @@ func selectProjectName(placeholder string) (string, error) {
 	return text.Run(&promptInput)
 }
 
-func createProject(name string) (*runtime.ProjectMeta, error) {
+func createProject(name string, region string) (*runtime.ProjectMeta, error) {
+	if err := shared.CheckQuota("create a project", quota.Projects); err != nil {
+		return nil, err
+	}
 	res, err := shared.Client.CreateProject(&api.CreateProjectRequest{
-		Name: name,
+		Name:   name,
+		Region: region,
 	})
 	if err != nil {
 		return nil, err
 	}
+	shared.InvalidateCache(runtime.ProjectsCacheKey)
 
 	return &runtime.ProjectMeta{ID: res.ID, Name: res.Name, Alias: res.Alias}, nil
 }
@@ -124,13 +152,13 @@ func createSpacefile(projectDir string, projectName string, blankProject bool) e
 		shared.Logger.Printf("L engine: %s\n", styles.Blue(micro.Engine))
 	}
 
-	if !shared.IsOutputInteractive() {
+	if !shared.CanPrompt() {
 		_, err = spacefile.CreateSpacefileWithMicros(projectDir, autoDetectedMicros)
 		return err
 	}
 
 	shared.Logger.Println()
-	if ok, err := confirm.Run(fmt.Sprintf("Do you want to setup \"%s\" with this configuration?", projectName)); err != nil {
+	if ok, err := confirm.Run("new.setup", fmt.Sprintf("Do you want to setup \"%s\" with this configuration?", projectName)); err != nil {
 		return err
 	} else if !ok {
 		_, err := spacefile.CreateBlankSpacefile(projectDir)
@@ -141,41 +169,41 @@ func createSpacefile(projectDir string, projectName string, blankProject bool) e
 	return err
 }
 
-func newProject(projectDir, projectName string, blankProject bool) error {
+func newProject(projectDir, projectName string, blankProject bool, region string) (string, error) {
 	// Create spacefile if it doesn't exist
 	spaceFilePath := filepath.Join(projectDir, "Spacefile")
 	if _, err := os.Stat(spaceFilePath); errors.Is(err, os.ErrNotExist) {
 		err := createSpacefile(projectDir, projectName, blankProject)
 		if err != nil {
 			shared.Logger.Printf("failed to create spacefile: %s", err)
-			return err
+			return "", err
 		}
 	}
 
 	// add .space folder to gitignore
 	if err := runtime.AddSpaceToGitignore(projectDir); err != nil {
 		shared.Logger.Printf("failed to add .space to gitignore: %s", err)
-		return err
+		return "", err
 	}
 
 	// Create project
-	meta, err := createProject(projectName)
+	meta, err := createProject(projectName, region)
 	if err != nil {
 		if errors.Is(auth.ErrNoAccessTokenFound, err) {
 			shared.Logger.Println(shared.LoginInfo())
-			return err
+			return "", err
 		}
 		shared.Logger.Printf("failed to create project: %s", err)
-		return err
+		return "", err
 	}
 
 	if err := runtime.StoreProjectMeta(projectDir, meta); err != nil {
 		shared.Logger.Printf("failed to save project meta, %s", err)
-		return err
+		return "", err
 	}
 
 	shared.Logger.Println(styles.Greenf("\nProject %s created successfully!", projectName))
 	shared.Logger.Println(shared.ProjectNotes(projectName, meta.ID))
 
-	return nil
+	return meta.ID, nil
 }
@@ -2,23 +2,23 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/deta/space/cmd/shared"
 	"github.com/deta/space/internal/runtime"
 	"github.com/deta/space/pkg/components/emoji"
-	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
 
 func newCmdOpen() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:      "open",
-		Short:    "Open your local project in the Builder UI",
+		Use:   "open",
+		Short: "Open your local project in the Builder UI",
+		Example: `  space open
+  space open --id a0abc1234`,
 		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckNotEmpty("id")),
 		PostRunE: shared.CheckLatestVersion,
 
-		Run: open,
+		RunE: open,
 	}
 
 	cmd.Flags().StringP("id", "i", "", "project id of project to open")
@@ -27,7 +27,7 @@ func newCmdOpen() *cobra.Command {
 	return cmd
 }
 
-func open(cmd *cobra.Command, args []string) {
+func open(cmd *cobra.Command, args []string) error {
 
 	projectDir, _ := cmd.Flags().GetString("dir")
 	projectID, _ := cmd.Flags().GetString("id")
@@ -37,14 +37,14 @@ func open(cmd *cobra.Command, args []string) {
 		projectID, err = runtime.GetProjectID(projectDir)
 		if err != nil {
 			shared.Logger.Printf("%s Failed to get project id: %s", emoji.ErrorExclamation, err)
-			os.Exit(1)
+			return err
 		}
 	}
 
 	shared.Logger.Printf("Opening project in default browser...\n")
-	if err := browser.OpenURL(fmt.Sprintf("%s/%s", shared.BuilderUrl, projectID)); err != nil {
+	if err := shared.OpenURL(fmt.Sprintf("%s/%s", shared.BuilderUrl, projectID)); err != nil {
 		shared.Logger.Printf("%s Failed to open browser window %s", emoji.ErrorExclamation, err)
-		os.Exit(1)
+		return err
 	}
-
+	return nil
 }
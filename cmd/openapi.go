@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/openapi"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdOpenAPI() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Work with the OpenAPI specs of your micros",
+		Long: fmt.Sprintf(`Work with the OpenAPI specs of your micros.
+
+The spec of a micro is the %s in its src. space dev serves Swagger UI for every micro with a spec under %s<micro>.`, openapi.FileName, openapi.UIPrefix),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdOpenAPIInit())
+
+	return cmd
+}
+
+func newCmdOpenAPIInit() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init <micro>",
+		Short: "Scaffold an OpenAPI spec from the public routes of a micro",
+		Long: fmt.Sprintf(`Scaffold an OpenAPI spec from the public routes of a micro in the Spacefile, written to %s in the src of the micro.
+
+Every public route gets a GET operation to fill in, a * in a route becomes a path parameter. A micro with public: true gets a single catch-all path.`, openapi.FileName),
+		Example: `  space openapi init api
+  space openapi init api --force`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMicroNames,
+		PreRunE:           shared.CheckExists("dir"),
+		PostRunE:          shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			force, _ := cmd.Flags().GetBool("force")
+			return openAPIInit(projectDir, args[0], force)
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().BoolP("force", "f", false, "overwrite an existing spec")
+
+	return cmd
+}
+
+func openAPIInit(projectDir string, name string, force bool) error {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	for _, micro := range s.Micros {
+		if micro.Name != name {
+			continue
+		}
+
+		path := openapi.SpecPath(projectDir, micro)
+		if _, err := os.Stat(path); err == nil && !force {
+			shared.Logger.Printf("%s %s already exists, use %s to overwrite it", emoji.ErrorExclamation, path, styles.Code("--force"))
+			return shared.ErrReported
+		}
+		content, err := openapi.Scaffold(micro)
+		if errors.Is(err, openapi.ErrNoPublicRoutes) {
+			shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+			return shared.ErrReported
+		} else if err != nil {
+			shared.Logger.Printf("%s Failed to scaffold the spec: %s", emoji.ErrorExclamation, err)
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			shared.Logger.Printf("%s Failed to write %s: %s", emoji.ErrorExclamation, path, err)
+			return err
+		}
+
+		shared.Logger.Printf("%s Created %s from the public routes of %s", emoji.Check, path, styles.Green(micro.Name))
+		shared.Logger.Printf("L Describe the operations and see them in Swagger UI at %s with %s", styles.Code(openapi.UIPrefix+micro.Name), styles.Code("space dev"))
+		return nil
+	}
+
+	shared.Logger.Printf("%s Micro %s not found in the Spacefile", emoji.ErrorExclamation, name)
+	return shared.ErrReported
+}
+
+// completeMicroNames completes the first argument with the micros of the Spacefile in the project directory
+func completeMicroNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	projectDir, _ := cmd.Flags().GetString("dir")
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, micro := range s.Micros {
+		names = append(names, micro.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
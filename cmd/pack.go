@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/components/tree"
+	"github.com/deta/space/pkg/util/fs"
+	"github.com/spf13/cobra"
+)
+
+func newCmdPack() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pack [flags]",
+		Short: "Archive your project like space push without pushing it",
+		Long: `Archive your project like space push does, without pushing it, to debug why a file is or isn't pushed.
+
+The archive includes the sources of micros outside of the project, the artifacts with --prebuilt and the files generated for the registries of space registry login, like space push. Git LFS pointer files aren't excluded, see space push --lfs.
+
+By default the number of files and the size of the archive are shown. --list shows the tree of the archived files with their sizes, --explain adds the files and directories left out of it with the .spaceignore pattern which excluded them. With --output json the files are printed as json.`,
+		Example: `  space pack --list
+  space pack --explain
+  space pack --prebuilt --list`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckExists("dir"),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			list, _ := cmd.Flags().GetBool("list")
+			explain, _ := cmd.Flags().GetBool("explain")
+			prebuilt, _ := cmd.Flags().GetBool("prebuilt")
+			opts := pushOptions{prebuilt: prebuilt, zip: runtime.ZipOptions{Compression: runtime.CompressionAuto}}
+			return pack(projectDir, list || explain, explain, opts)
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project to pack")
+	cmd.Flags().Bool("list", false, "show the tree of the archived files")
+	cmd.Flags().Bool("explain", false, "show the files left out of the archive and why, implies --list")
+	cmd.Flags().Bool("prebuilt", false, "archive the artifacts of the micros like space push --prebuilt")
+
+	return cmd
+}
+
+// packedFile is printed with --output json
+type packedFile struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Dir       bool   `json:"dir,omitempty"`
+	Generated bool   `json:"generated,omitempty"`
+	Archived  bool   `json:"archived"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// pack lists the files archived with opts and shows the size of the archive, push --show-files uses it with the
+// options of the push
+func pack(projectDir string, list bool, explain bool, opts pushOptions) error {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s Failed to parse Spacefile: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	if _, err := prepareArchive(projectDir, s.Micros, &opts); err != nil {
+		return err
+	}
+
+	files, err := runtime.ListArchive(projectDir, opts.zip, explain)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to list the files of the archive: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	if shared.JSONOutput() {
+		packed := make([]*packedFile, 0, len(files))
+		for _, f := range files {
+			p := &packedFile{Name: f.Name, Size: f.Size, Dir: f.Dir, Generated: f.Path == "" && f.Reason == "", Archived: f.Reason == "", Reason: f.Reason}
+			if f.Dir {
+				p.Size = 0
+			}
+			packed = append(packed, p)
+		}
+		return shared.PrintJSON(packed)
+	}
+
+	if list {
+		printArchiveTree(files)
+	}
+
+	// the archive is written like a push to measure the compressed size
+	counter := &countingWriter{}
+	nbFiles, err := runtime.WriteZip(counter, projectDir, opts.zip)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to zip project: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	var size int64
+	for _, f := range files {
+		if f.Reason == "" {
+			size += f.Size
+		}
+	}
+	shared.Logger.Printf("%s %d files, %s, %s compressed", emoji.Package, nbFiles, fs.FormatSize(size), fs.FormatSize(counter.n))
+	return nil
+}
+
+func printArchiveTree(files []*runtime.ArchiveFile) {
+	entries := make([]*tree.Entry, 0, len(files))
+	byName := make(map[string]*runtime.ArchiveFile, len(files))
+	for _, f := range files {
+		e := &tree.Entry{Path: f.Name, Size: f.Size, Excluded: f.Reason != ""}
+		if f.Dir {
+			e.Size = 0
+		}
+		entries = append(entries, e)
+		byName[f.Name] = f
+	}
+
+	shared.Logger.Println(".")
+	for _, l := range tree.Lines(entries) {
+		if l.Entry == nil || l.Dir {
+			shared.Logger.Printf("%s%s/ %s", l.Prefix, l.Name, styles.Subtle(fs.FormatSize(l.Size)))
+			continue
+		}
+		f := byName[l.Entry.Path]
+		switch {
+		case f.Dir:
+			shared.Logger.Printf("%s%s %s", l.Prefix, styles.Subtle(l.Name+"/"), styles.Subtle("excluded by "+f.Reason))
+		case f.Reason != "":
+			shared.Logger.Printf("%s%s %s", l.Prefix, styles.Subtle(l.Name), styles.Subtle(fs.FormatSize(l.Size)+", "+f.Reason))
+		case f.Path == "":
+			shared.Logger.Printf("%s%s %s", l.Prefix, l.Name, styles.Subtle(fs.FormatSize(l.Size)+", generated"))
+		default:
+			shared.Logger.Printf("%s%s %s", l.Prefix, l.Name, styles.Subtle(fs.FormatSize(l.Size)))
+		}
+	}
+	shared.Logger.Println()
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/ping"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdPing() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ping [flags]",
+		Short: "Measure the latency to Deta Space and your apps",
+		Long: `Measure the latency from your machine to the Deta Space APIs, to every edge and to your deployed apps.
+
+Each endpoint is probed with HEAD requests over a reused connection, the first request only sets up the connection and is not counted. Pass the url of your app with --url to include it.`,
+		Example: `  space ping
+  space ping --url https://my-app-1-a1234567.deta.app --count 20`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			count, _ := cmd.Flags().GetInt("count")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			urls, _ := cmd.Flags().GetStringSlice("url")
+
+			if count < 1 {
+				shared.Logger.Printf("%s --count must be at least 1", emoji.ErrorExclamation)
+				return shared.ErrReported
+			}
+
+			if err := runPing(count, timeout, urls); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntP("count", "c", 10, "number of requests per endpoint")
+	cmd.Flags().Duration("timeout", 5*time.Second, "timeout of a single request")
+	cmd.Flags().StringSlice("url", nil, "url of a deployed app to probe, can be repeated")
+
+	return cmd
+}
+
+func pingTargets(urls []string) ([]ping.Target, error) {
+	var targets []ping.Target
+
+	endpoints := api.Endpoints()
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		targets = append(targets, ping.Target{Name: name, URL: endpoints[name]})
+	}
+
+	r, err := shared.ListRegions()
+	if err != nil {
+		return nil, err
+	}
+	for _, region := range r.Regions {
+		if region.Endpoint == "" {
+			continue
+		}
+		targets = append(targets, ping.Target{Name: fmt.Sprintf("Edge %s (%s)", region.ID, region.Location), URL: region.Endpoint})
+	}
+
+	for _, url := range urls {
+		targets = append(targets, ping.Target{Name: "App", URL: url})
+	}
+
+	return targets, nil
+}
+
+func runPing(count int, timeout time.Duration, urls []string) error {
+	targets, err := pingTargets(urls)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to list edges: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	prober := &ping.Prober{Count: count, Timeout: timeout, Client: &http.Client{}}
+
+	shared.Logger.Printf("%s Probing %d endpoints with %d requests each...\n", emoji.Earth, len(targets), count)
+	failed := false
+	for _, target := range targets {
+		res := prober.Probe(ctx, target)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		shared.Logger.Printf("%s %s", styles.Bold(target.Name), styles.Subtle(target.URL))
+		if res.Err != nil {
+			failed = true
+			shared.Logger.Printf("L %s", styles.Errorf("unreachable: %v", res.Err))
+			continue
+		}
+
+		stats, ok := res.Stats()
+		if !ok {
+			failed = true
+			shared.Logger.Printf("L %s", styles.Error("all requests failed"))
+			continue
+		}
+		shared.Logger.Printf("L min %s  p50 %s  p90 %s  p99 %s  max %s  failed %d/%d",
+			fmtLatency(stats.Min), fmtLatency(stats.P50), fmtLatency(stats.P90), fmtLatency(stats.P99), fmtLatency(stats.Max), res.Failed, count)
+	}
+
+	if failed {
+		return errors.New("some endpoints are unreachable")
+	}
+	return nil
+}
+
+func fmtLatency(d time.Duration) string {
+	return d.Round(100 * time.Microsecond).String()
+}
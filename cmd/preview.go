@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/internal/preview"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/confirm"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdPreview() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Manage preview deployments of branches",
+		Long: `Manage preview deployments of branches.
+
+Every branch is pushed to its own preview project, which is created on the first push. The name of the project is derived from the branch, so that later pushes of the same branch update the same preview.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdPreviewCreate())
+	cmd.AddCommand(newCmdPreviewCleanup())
+
+	return cmd
+}
+
+func newCmdPreviewCreate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [flags]",
+		Short: "Push the current checkout to the preview project of a branch",
+		Long: fmt.Sprintf(`Push the current checkout to the preview project of a branch and print the url of the preview.
+
+The branch defaults to the branch of the pull request or the ref of the workflow when running in GitHub Actions. The url of the preview is also set as the %s output of the step.`, styles.Code("preview_url")),
+		Example:  `  space preview create --ref feature/login`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckNotEmpty("ref"), shared.ApplyBandwidthLimit("bwlimit")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			ref, _ := cmd.Flags().GetString("ref")
+			scope, _ := cmd.Flags().GetString("id")
+			region, _ := cmd.Flags().GetString("region")
+			lfs, _ := cmd.Flags().GetString("lfs")
+
+			if ref == "" {
+				ref = defaultPreviewRef()
+			}
+			if ref == "" {
+				shared.Logger.Printf("%s No branch found, please provide it with %s", emoji.ErrorExclamation, styles.Code("--ref"))
+				return shared.ErrReported
+			}
+
+			// keep previews of branches with the same name in different projects apart
+			if scope == "" {
+				scope, _ = runtime.GetProjectID(projectDir)
+			}
+
+			url, err := previewCreate(projectDir, ref, scope, region, lfs)
+			if err != nil {
+				return err
+			}
+			shared.CopyToClipboard(cmd, "url of the preview", url)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project to preview")
+	cmd.Flags().String("ref", "", "branch to create the preview for")
+	cmd.Flags().StringP("id", "i", "", "project id of the main project, defaults to the linked project")
+	cmd.Flags().StringP("region", "r", "", "region to create the preview project in, defaults to the region of your space")
+	cmd.Flags().String("lfs", "", "how to handle Git LFS pointer files: pull, exclude or ignore, asks if not set")
+	cmd.Flags().String("bwlimit", "", "limit the upload bandwidth, e.g. 2MB/s")
+	shared.AddCopyFlag(cmd, "url of the preview")
+
+	return cmd
+}
+
+// defaultPreviewRef returns the branch of the current GitHub Actions workflow run
+func defaultPreviewRef() string {
+	if ref := os.Getenv("GITHUB_HEAD_REF"); ref != "" {
+		return ref
+	}
+	return os.Getenv("GITHUB_REF_NAME")
+}
+
+func previewCreate(projectDir string, ref string, scope string, region string, lfs string) (string, error) {
+	name := preview.Name(scope, ref)
+
+	project, err := findProjectByName(name)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return "", err
+		}
+		shared.Logger.Printf("%s Failed to look up preview project: %s", emoji.ErrorExclamation, err)
+		return "", err
+	}
+
+	projectID := ""
+	if project != nil {
+		projectID = project.ID
+		shared.Logger.Printf("%s Updating preview project %s of %s", emoji.Package, styles.Code(name), styles.Blue(ref))
+	} else {
+		meta, err := createProject(name, region)
+		if err != nil {
+			shared.Logger.Printf("%s Failed to create preview project: %s", emoji.ErrorExclamation, err)
+			return "", err
+		}
+		projectID = meta.ID
+		shared.Logger.Printf("%s Created preview project %s for %s", emoji.Check, styles.Code(name), styles.Blue(ref))
+	}
+
+	exclude, err := checkFiles(projectDir, lfs)
+	if err != nil {
+		return "", err
+	}
+
+	// the labels mark the project as a preview for space preview cleanup
+	result, err := push(projectID, projectDir, pushOptions{zip: runtime.ZipOptions{Compression: runtime.CompressionAuto, Exclude: exclude}, labels: preview.Labels(scope, ref)})
+	if err != nil {
+		return "", err
+	}
+	url := result.BuilderInstanceURL
+	if url == "" {
+		shared.Logger.Printf("%s Pushed the preview, but its url is unknown. Please check %s", emoji.Warning, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID))
+		return "", nil
+	}
+
+	shared.Logger.Printf("\n%s Preview of %s: %s", emoji.Earth, styles.Blue(ref), styles.Code(url))
+	if err := gha.SetOutput("preview_url", url); err != nil {
+		shared.Logger.Printf("%s Failed to set the preview_url output: %s", emoji.Warning, err)
+	}
+	return url, nil
+}
+
+// findProjectByName returns the project with the name or nil if there is none
+func findProjectByName(name string) (*api.Project, error) {
+	res, err := shared.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range res.Projects {
+		if project.Name == name {
+			return project, nil
+		}
+	}
+	return nil, nil
+}
+
+func newCmdPreviewCleanup() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cleanup [flags]",
+		Short: "Delete preview projects which haven't been updated for a while",
+		Long: `Delete preview projects which haven't been updated for a while.
+
+A preview is as old as its latest revision. All preview projects of your space are considered, not only the ones of the current project. A project is only a preview if its latest revision was pushed by space preview create, which labels it with the branch the name of the project was derived from, a project you named like a preview is never deleted.
+
+The previews are only deleted after you confirm it, without a terminal the command only lists them unless --yes is passed.`,
+		Example: `  space preview cleanup --older-than 7d
+  space preview cleanup --older-than 2w --yes`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckNotEmpty("older-than"),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flag, _ := cmd.Flags().GetString("older-than")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			olderThan, err := preview.ParseAge(flag)
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+
+			if err := previewCleanup(olderThan, dryRun); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("older-than", "7d", "delete previews older than this, e.g. 12h, 7d or 2w")
+	cmd.Flags().Bool("dry-run", false, "only list the previews which would be deleted")
+
+	return cmd
+}
+
+func previewCleanup(olderThan time.Duration, dryRun bool) error {
+	// a cached list could be missing projects renamed or created since
+	res, err := shared.Client.ListProjects()
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return err
+		}
+		shared.Logger.Printf("%s Failed to list projects: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	runtime.WriteCache(runtime.ProjectsCacheKey, res)
+
+	var previews []*api.Project
+	for _, project := range res.Projects {
+		if preview.IsPreview(project.Name) {
+			previews = append(previews, project)
+		}
+	}
+
+	// the revisions of all previews are fetched in parallel, the results are printed in the order of the projects
+	updatedAt := make([]time.Time, len(previews))
+	isPreview := make([]bool, len(previews))
+	errs := make([]error, len(previews))
+	indexes := make([]int, len(previews))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	shared.ForEach(indexes, func(i int) error {
+		updatedAt[i], isPreview[i], errs[i] = previewUpdatedAt(previews[i])
+		return nil
+	})
+
+	var stale []*api.Project
+	for i, project := range previews {
+		if errs[i] != nil {
+			shared.Logger.Printf("%s Skipping %s: %s", emoji.Warning, project.Name, errs[i])
+			continue
+		}
+		if !isPreview[i] {
+			shared.Logger.Printf("%s Skipping %s: its latest revision wasn't pushed by space preview create", emoji.Warning, project.Name)
+			continue
+		}
+		if time.Since(updatedAt[i]) > olderThan {
+			stale = append(stale, project)
+			shared.Logger.Printf("L %s %s", project.Name, styles.Subtle(fmt.Sprintf("(last updated %s)", updatedAt[i].Format(time.RFC1123))))
+		}
+	}
+
+	if len(stale) == 0 {
+		shared.Logger.Printf("%s No previews older than %s", emoji.Check, olderThan)
+		return nil
+	}
+	if dryRun {
+		shared.Logger.Printf("\n%d previews would be deleted", len(stale))
+		return nil
+	}
+
+	if !shared.CanPrompt() {
+		shared.Logger.Printf("\n%d previews would be deleted, pass %s to delete them without a terminal", len(stale), styles.Code("--yes"))
+		return nil
+	}
+	ok, err := confirm.Run("preview.cleanup", fmt.Sprintf("Delete %d previews?", len(stale)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	var failed bool
+	defer shared.InvalidateCache(runtime.ProjectsCacheKey)
+	for _, project := range stale {
+		if err := shared.Client.DeleteProject(&api.DeleteProjectRequest{ID: project.ID}); err != nil && !errors.Is(err, api.ErrProjectNotFound) {
+			shared.Logger.Printf("%s Failed to delete %s: %s", emoji.ErrorExclamation, project.Name, err)
+			failed = true
+			continue
+		}
+		shared.Logger.Printf("%s Deleted %s", emoji.Check, project.Name)
+	}
+	if failed {
+		return errors.New("failed to delete some previews")
+	}
+	return nil
+}
+
+// previewUpdatedAt returns the time of the latest revision of a project named like a preview and whether it's a
+// preview, which a project without revisions never is
+func previewUpdatedAt(project *api.Project) (time.Time, bool, error) {
+	res, err := shared.GetRevisions(project.ID, true)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(res.Revisions) == 0 {
+		return time.Time{}, false, nil
+	}
+	latest := res.Revisions[0]
+
+	t, err := time.Parse(time.RFC3339, latest.CreatedAt)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid timestamp %q", latest.CreatedAt)
+	}
+	return t, preview.IsPreviewProject(project.Name, latest.Labels), nil
+}
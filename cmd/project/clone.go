@@ -0,0 +1,279 @@
+package project
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newCmdProjectClone() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clone <source-id> [flags]",
+		Short: "Create a new project from the latest revision of an existing project",
+		Long: `Create a new project from the latest revision of an existing project.
+
+The source code of the latest revision is pushed to the new project, which creates its first revision. Useful to spin up staging copies of a project.
+
+Pass --with-data together with the names of the bases and drives to copy their contents into the new project as well. Bases and drives can't be listed through their APIs, so they have to be named explicitly.`,
+		Example: `  space project clone a0abc1234 --name my-app-staging
+  space project clone a0abc1234 --name my-app-staging --with-data --base users --drive uploads`,
+		Args:     cobra.ExactArgs(1),
+		PreRunE:  shared.CheckAll(shared.CheckNotEmpty("name"), shared.ApplyBandwidthLimit("bwlimit"), shared.ApplyInsecureSkipVerify("insecure-skip-verify")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			withData, _ := cmd.Flags().GetBool("with-data")
+			bases, _ := cmd.Flags().GetStringSlice("base")
+			drives, _ := cmd.Flags().GetStringSlice("drive")
+			region, _ := cmd.Flags().GetString("region")
+
+			if withData && len(bases) == 0 && len(drives) == 0 {
+				shared.Logger.Printf("%s --with-data requires at least one --base or --drive to copy", emoji.ErrorExclamation)
+				return shared.ErrReported
+			}
+			if !withData && (len(bases) > 0 || len(drives) > 0) {
+				shared.Logger.Printf("%s --base and --drive can only be used together with --with-data", emoji.ErrorExclamation)
+				return shared.ErrReported
+			}
+
+			projectID, err := clone(args[0], name, region, bases, drives)
+			if err != nil {
+				return err
+			}
+			shared.CopyToClipboard(cmd, "project id", projectID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("name", "n", "", "name of the new project")
+	cmd.Flags().StringP("region", "r", "", "region to create the new project in, defaults to the region of your space")
+	cmd.Flags().Bool("with-data", false, "copy base and drive data into the new project")
+	cmd.Flags().StringSlice("base", nil, "name of a base to copy, can be repeated")
+	cmd.Flags().StringSlice("drive", nil, "name of a drive to copy, can be repeated")
+	cmd.Flags().String("bwlimit", "", "limit the upload bandwidth, e.g. 2MB/s")
+	cmd.Flags().Bool("insecure-skip-verify", false, "skip the checksum verification of the downloaded revision, not recommended")
+	shared.AddCopyFlag(cmd, "project id")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func clone(sourceID string, name string, region string, bases []string, drives []string) (string, error) {
+	p, err := shared.GetProjectWithRevisions(sourceID)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return "", err
+		}
+		if errors.Is(err, api.ErrProjectNotFound) {
+			shared.Logger.Println(styles.Errorf("%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
+			return "", err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to get project: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+	source := p.Project
+
+	if len(p.Revisions) == 0 {
+		shared.Logger.Println(styles.Errorf("%s Project %s has no revisions to clone", emoji.ErrorExclamation, source.Name))
+		return "", errors.New("no revisions found")
+	}
+	revision := p.Revisions[0]
+
+	shared.Logger.Printf("\n%s Downloading revision %s of %s...", emoji.Package, styles.Blue(revision.Tag), styles.Pink(source.Name))
+	rc, err := shared.Client.GetRevisionCode(&api.GetRevisionCodeRequest{RevisionID: revision.ID, Digest: revision.Digest})
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to download revision: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+	code, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to download revision: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+
+	files, err := zip.NewReader(bytes.NewReader(code), int64(len(code)))
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Invalid revision archive: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+	manifest, err := readZipEntry(files, spacefile.SpacefileName)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Revision has no Spacefile: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+
+	project, err := shared.Client.CreateProject(&api.CreateProjectRequest{Name: name, Region: region})
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to create project: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+	shared.InvalidateCache(runtime.ProjectsCacheKey)
+	shared.Logger.Println(styles.Greenf("\n%s Project %s created!", emoji.Check, name))
+
+	build, err := shared.Client.CreateBuild(&api.CreateBuildRequest{AppID: project.ID, Tag: revision.Tag})
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to start build: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+
+	if _, err := shared.Client.PushSpacefile(&api.PushSpacefileRequest{Manifest: manifest, BuildID: build.ID}); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to push Spacefile, %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+
+	// the icon path in the Spacefile is relative to the project root, which is the root of the archive
+	var s spacefile.Spacefile
+	if err := yaml.Unmarshal(manifest, &s); err == nil && s.Icon != "" {
+		if icon, err := readZipEntry(files, path.Clean(s.Icon)); err == nil {
+			if _, err := shared.Client.PushIcon(&api.PushIconRequest{
+				Icon:        icon,
+				ContentType: http.DetectContentType(icon),
+				BuildID:     build.ID,
+			}); err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to push icon, %v", emoji.ErrorExclamation, err))
+				return "", err
+			}
+		}
+	}
+
+	if discoveryFile, err := readZipEntry(files, "Discovery.md"); err == nil {
+		if _, err := shared.Client.PushDiscoveryFile(&api.PushDiscoveryFileRequest{DiscoveryFile: discoveryFile, BuildID: build.ID}); err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to push Discovery file, %v", emoji.ErrorExclamation, err))
+			return "", err
+		}
+	}
+
+	if _, err := shared.Client.PushCode(&api.PushCodeRequest{BuildID: build.ID, ZippedCode: code}); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to push code, %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+	shared.Logger.Printf("\n%s Building revision %s...\n", emoji.Package, styles.Blue(revision.Tag))
+
+	logs, err := shared.Client.GetBuildLogs(&api.GetBuildLogsRequest{BuildID: build.ID})
+	if err != nil {
+		shared.Logger.Printf("%s Error: %v\n", emoji.ErrorExclamation, err)
+		return "", err
+	}
+	defer logs.Close()
+	endGroup := gha.Group("Build logs")
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		fmt.Println(scanner.Text())
+	}
+	endGroup()
+	if err := scanner.Err(); err != nil {
+		shared.Logger.Printf("%s Error: %v\n", emoji.ErrorExclamation, err)
+		return "", err
+	}
+
+	b, err := shared.Client.GetBuild(&api.GetBuildRequest{BuildID: build.ID})
+	if err != nil {
+		shared.Logger.Printf(styles.Errorf("\n%s Failed to check if the build succeeded. Please check %s", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, project.ID)))
+		return "", err
+	}
+	if b.Status != api.Complete {
+		shared.Logger.Println(styles.Errorf("\n%s Failed to build the cloned revision.", emoji.ErrorExclamation))
+		gha.Error(&gha.Annotation{Title: "Build failed", Message: fmt.Sprintf("Build of the cloned revision %s failed with status %s", revision.Tag, b.Status)})
+		return "", fmt.Errorf("build failed: %s", b.Status)
+	}
+
+	if len(bases) > 0 || len(drives) > 0 {
+		if err := copyData(sourceID, project.ID, bases, drives); err != nil {
+			return "", err
+		}
+	}
+
+	shared.Logger.Println(styles.Greenf("\n%s Cloned %s into %s!", emoji.PartyPopper, source.Name, name))
+	shared.Logger.Printf("Find your project in Builder: %s", styles.Bold(fmt.Sprintf("%s/%s", shared.BuilderUrl, project.ID)))
+	shared.Logger.Printf("Link it to a local directory with %s", styles.Codef("space link --id %s", project.ID))
+	return project.ID, nil
+}
+
+func readZipEntry(r *zip.Reader, name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func copyData(sourceID string, targetID string, bases []string, drives []string) error {
+	sourceKey, err := shared.GenerateDataKeyIfNotExists(sourceID)
+	if err != nil {
+		shared.Logger.Printf("%s Error generating the project key: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	targetKey, err := shared.GenerateDataKeyIfNotExists(targetID)
+	if err != nil {
+		shared.Logger.Printf("%s Error generating the project key: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	for _, base := range bases {
+		shared.Logger.Printf("\n%s Copying base %s...", emoji.Files, styles.Code(base))
+		items, err := shared.Client.FetchBaseItems(&api.BaseRef{ProjectKey: sourceKey, Base: base})
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+			return err
+		}
+		if err := shared.Client.PutBaseItems(&api.BaseRef{ProjectKey: targetKey, Base: base}, items); err != nil {
+			shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+			return err
+		}
+		shared.Logger.Printf("L %d items copied", len(items))
+	}
+
+	for _, drive := range drives {
+		shared.Logger.Printf("\n%s Copying drive %s...", emoji.Files, styles.Code(drive))
+		source := &api.DriveRef{ProjectKey: sourceKey, Drive: drive}
+		target := &api.DriveRef{ProjectKey: targetKey, Drive: drive}
+
+		names, err := shared.Client.ListDriveFiles(source, "")
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+			return err
+		}
+		for _, name := range names {
+			if err := copyDriveFile(source, target, name); err != nil {
+				shared.Logger.Println(styles.Errorf("%s %v", emoji.ErrorExclamation, err))
+				return err
+			}
+		}
+		shared.Logger.Printf("L %d files copied", len(names))
+	}
+
+	return nil
+}
+
+func copyDriveFile(source *api.DriveRef, target *api.DriveRef, name string) error {
+	rc, err := shared.Client.GetDriveFile(source, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return shared.Client.PutDriveFile(target, name, content)
+}
@@ -0,0 +1,19 @@
+package project
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProject() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "Manage your projects",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdProjectClone())
+
+	return cmd
+}
@@ -5,18 +5,34 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/deta/space/cmd/shared"
 	"github.com/deta/space/internal/api"
 	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/buildargs"
 	"github.com/deta/space/internal/discovery"
+	"github.com/deta/space/internal/filewatch"
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/internal/git"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/internal/labels"
+	"github.com/deta/space/internal/lockfile"
+	"github.com/deta/space/internal/profile"
+	"github.com/deta/space/internal/quota"
+	"github.com/deta/space/internal/registry"
 	"github.com/deta/space/internal/runtime"
 	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/internal/workspace"
+	"github.com/deta/space/pkg/components/choose"
 	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
-	"github.com/pkg/browser"
+	"github.com/deta/space/pkg/util/fs"
+	types "github.com/deta/space/shared"
 	"github.com/spf13/cobra"
 )
 
@@ -30,74 +46,536 @@ Space will automatically update your Builder instance with the new revision.
 
 If you don't want to follow the logs of the build and update, pass the --skip-logs argument which will exit the process as soon as the build is started instead of waiting for it to finish.
 
-Tip: Use the .spaceignore file to exclude certain files and directories from being uploaded during push.
+Tip: Use the .spaceignore file to exclude certain files and directories from being uploaded during push. It has the syntax of a .gitignore and applies on top of default patterns, like node_modules, build outputs and local environments, without touching your .gitignore. Use --show-files to list the files which would be pushed without pushing them, or space pack --explain to see why a file is left out.
+
+Git LFS pointer files are detected before uploading, as pushing them instead of their content breaks deployments. Use --lfs to choose whether to pull their content, exclude them or push them anyway.
+
+If the project config (.spaceconfig) maps branches to environments, the project of the environment of the current branch is used. Use --environment or --id to push to another project.
+
+Use --changed-since to only push if files changed since a git ref. If the directory isn't a project itself but contains projects, e.g. the root of a monorepo, it is pushed as a workspace: a plan of the changed projects and micros is printed and only the changed projects are pushed.
+
+Use --prebuilt to push artifacts built by your own build system instead of building on Space. Only the files matching the artifacts patterns of each micro in the Spacefile are pushed, the .spaceignore doesn't apply to them, and the build commands of the micros are skipped.
+
+Use --build-arg to forward environment variables to the build commands of all micros, e.g. the token of a private package registry. A micro can declare the variables it needs in build_args in the Spacefile, their values are taken from your environment. Values of build args are masked in the build logs.
+
+Use --watch to keep watching the project after the push and push again every time its files change, which is handy for rapid iteration without a local dev server. Changes are pushed once no file changed for --watch-delay, so saving several files at once results in a single revision. Changes to files excluded by the .spaceignore are ignored. A failed push doesn't stop watching, press Ctrl+C to stop.
+
+Use --compression to trade CPU time for upload size. Files which are already compressed, like images or archives, are always stored as is.
 `,
-		Args:     cobra.NoArgs,
-		PreRunE:  shared.CheckAll(shared.CheckProjectInitialized("dir"), shared.CheckNotEmpty("id", "tag")),
+		Example: `  space push
+  space push --tag v1.2.0 --open
+  space push --skip-logs --lfs exclude
+  space push --watch --skip-logs
+  space push --show-files`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// the projects of a workspace are only found when running
+			target := shared.CheckProjectTarget("dir", "id")
+			// a dry run doesn't need a project
+			if cmd.Flags().Changed("changed-since") || cmd.Flags().Changed("show-files") {
+				target = shared.CheckExists("dir")
+			}
+			return shared.CheckAll(target, shared.CheckNotEmpty("id", "tag", "environment", "changed-since"), shared.ApplyBandwidthLimit("bwlimit"))(cmd, args)
+		},
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			projectDir, _ := cmd.Flags().GetString("dir")
 			projectID, _ := cmd.Flags().GetString("id")
-			if !cmd.Flags().Changed("id") {
-				var err error
-				projectID, err = runtime.GetProjectID(projectDir)
+			environment, _ := cmd.Flags().GetString("environment")
+			pushTag, _ := cmd.Flags().GetString("tag")
+			openInBrowser, _ := cmd.Flags().GetBool("open")
+			skipLogs, _ := cmd.Flags().GetBool("skip-logs")
+			prebuilt, _ := cmd.Flags().GetBool("prebuilt")
+			skipLockfileCheck, _ := cmd.Flags().GetBool("skip-lockfile-check")
+			buildArgPairs, _ := cmd.Flags().GetStringArray("build-arg")
+			buildArgs, err := buildargs.Parse(buildArgPairs, os.LookupEnv)
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+			lfs, _ := cmd.Flags().GetString("lfs")
+			labelPairs, _ := cmd.Flags().GetStringArray("label")
+			revisionLabels, err := labels.Parse(labelPairs)
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+
+			flag, _ := cmd.Flags().GetString("compression")
+			compression, err := runtime.ParseCompression(flag)
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+
+			opts := pushOptions{
+				tag:       pushTag,
+				labels:    revisionLabels,
+				open:      openInBrowser,
+				skipLogs:  skipLogs,
+				prebuilt:  prebuilt,
+				buildArgs: buildArgs,
+				zip:       runtime.ZipOptions{Compression: compression},
+
+				skipLockfileCheck: skipLockfileCheck,
+			}
+
+			if cmd.Flags().Changed("changed-since") {
+				since, _ := cmd.Flags().GetString("changed-since")
+				started := time.Now()
+				err := pushChanged(projectDir, since, projectID, environment, lfs, opts)
+				shared.NotifyFinished(cmd, started, "Push", err)
+				return err
+			}
+
+			if showFiles, _ := cmd.Flags().GetBool("show-files"); showFiles {
+				opts.zip.Exclude, err = checkFiles(projectDir, lfs)
 				if err != nil {
-					shared.Logger.Printf("%s Failed to get project id: %s", emoji.ErrorExclamation, err)
-					os.Exit(1)
+					return err
 				}
+				if err := pack(projectDir, true, false, opts); err != nil {
+					return err
+				}
+				shared.Logger.Println(styles.Subtle("Nothing was pushed, run space push without --show-files to push these files."))
+				return nil
 			}
 
-			pushTag, _ := cmd.Flags().GetString("tag")
-			openInBrowser, _ := cmd.Flags().GetBool("open")
-			skipLogs, _ := cmd.Flags().GetBool("skip-logs")
+			projectID, err = shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
 
-			err := push(projectID, projectDir, pushTag, openInBrowser, skipLogs)
+			opts.zip.Exclude, err = checkFiles(projectDir, lfs)
 			if err != nil {
-				os.Exit(1)
+				return err
+			}
+
+			watch, _ := cmd.Flags().GetBool("watch")
+			if watch {
+				quiet, _ := cmd.Flags().GetDuration("watch-delay")
+				result, err := push(projectID, projectDir, opts)
+				if err == nil && shared.JSONOutput() {
+					if err := shared.PrintJSON(result); err != nil {
+						return err
+					}
+				}
+				return watchPush(projectDir, projectID, quiet, opts)
+			}
+
+			started := time.Now()
+			result, err := push(projectID, projectDir, opts)
+			shared.NotifyFinished(cmd, started, "Push", err)
+			if err != nil {
+				return err
 			}
+			shared.CopyToClipboard(cmd, "url of the Builder instance", result.BuilderInstanceURL)
+			if shared.JSONOutput() {
+				return shared.PrintJSON(result)
+			}
+			return nil
 		},
 	}
 
 	cmd.Flags().StringP("id", "i", "", "project id of project to push")
+	cmd.Flags().String("environment", "", "environment of the project config to push to, defaults to the environment of the current branch")
 	cmd.Flags().StringP("dir", "d", "./", "src of project to push")
 	cmd.MarkFlagDirname("dir")
 	cmd.Flags().StringP("tag", "t", "", "tag to identify this push")
+	cmd.Flags().StringArray("label", nil, "label of the revision as key=value, e.g. ci_run=123, can be repeated, key= removes a default label")
 	cmd.Flags().Bool("open", false, "open builder instance/project in browser after push")
+	shared.AddCopyFlag(cmd, "url of the Builder instance")
+	shared.AddNotifyFlag(cmd)
 	cmd.Flags().BoolP("skip-logs", "", false, "skip following logs after push")
+	cmd.Flags().String("bwlimit", "", "limit the upload bandwidth, e.g. 2MB/s")
+	cmd.Flags().String("compression", string(runtime.CompressionAuto), "compression of the uploaded code: auto, none, fast or best")
+	cmd.Flags().String("lfs", "", "how to handle Git LFS pointer files: pull, exclude or ignore, asks if not set")
+	cmd.Flags().String("changed-since", "", "only push the projects with files changed since this git ref")
+	cmd.Flags().StringArray("build-arg", nil, "environment variable of the build commands as NAME=value, NAME takes the value from your environment, can be repeated")
+	cmd.Flags().Bool("prebuilt", false, "push the artifacts of the micros built by your own build system and skip their build commands")
+	cmd.Flags().Bool("skip-lockfile-check", false, "push even if a lockfile is out of sync with its package.json, pyproject.toml or requirements.in")
+
+	cmd.Flags().Bool("watch", false, "keep watching the project and push every time its files change")
+	cmd.Flags().Duration("watch-delay", filewatch.DefaultQuiet, "how long no file has to change before a push with --watch")
+
+	cmd.MarkFlagsMutuallyExclusive("changed-since", "open")
+	cmd.Flags().Bool("show-files", false, "only list the files which would be pushed, without pushing")
+
+	cmd.MarkFlagsMutuallyExclusive("changed-since", "watch")
+	cmd.MarkFlagsMutuallyExclusive("show-files", "changed-since")
+	cmd.MarkFlagsMutuallyExclusive("show-files", "watch")
 
 	return cmd
 }
 
-func push(projectID string, projectDir string, pushTag string, openInBrowser bool, skipLogs bool) error {
+const (
+	lfsPull    = "pull"
+	lfsExclude = "exclude"
+	lfsIgnore  = "ignore"
+)
+
+// maxListedFiles is the number of files listed in warnings, the rest is summarized
+const maxListedFiles = 10
+
+func listFiles(files []runtime.File) {
+	for i, f := range files {
+		if i == maxListedFiles {
+			shared.Logger.Printf("  ...and %d more", len(files)-maxListedFiles)
+			return
+		}
+		shared.Logger.Printf("  %s %s", styles.Code(f.Path), styles.Subtle(fs.FormatSize(f.Size)))
+	}
+}
+
+// checkFiles warns about large files and Git LFS pointers, returns the paths which should be excluded from the push
+func checkFiles(projectDir string, lfs string) ([]string, error) {
+	switch lfs {
+	case "", lfsPull, lfsExclude, lfsIgnore:
+	default:
+		err := fmt.Errorf("invalid value %q for --lfs, must be one of pull, exclude or ignore", lfs)
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	report, err := runtime.CheckFiles(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to check project files: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	if len(report.LargeFiles) > 0 {
+		shared.Logger.Printf("\n%s Found %d files bigger than %s, which slow down your push:", emoji.Warning, len(report.LargeFiles), fs.FormatSize(runtime.LargeFileSize))
+		listFiles(report.LargeFiles)
+		shared.Logger.Printf("Add them to your %s file if they are not needed by your app.", styles.Code(".spaceignore"))
+	}
+
+	if len(report.LFSPointers) == 0 {
+		return nil, nil
+	}
+
+	shared.Logger.Printf("\n%s Found %d Git LFS pointer files, their content was not pulled and the pointers would be uploaded instead:", emoji.Warning, len(report.LFSPointers))
+	listFiles(report.LFSPointers)
+
+	if lfs == "" {
+		if !shared.CanPrompt() {
+			err := errors.New("git lfs pointer files found")
+			shared.Logger.Printf("%s Pass %s to decide how to handle them.", emoji.ErrorExclamation, styles.Code("--lfs pull|exclude|ignore"))
+			return nil, err
+		}
+
+		actions := []string{lfsPull, lfsExclude, lfsIgnore}
+		choices := []string{"Pull their content with git lfs pull", "Exclude them from this push", "Push the pointer files anyway"}
+		choice, err := choose.Run("push.lfs", "What do you want to do?", choices...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range choices {
+			if choices[i] == choice {
+				lfs = actions[i]
+			}
+		}
+	}
+
+	switch lfs {
+	case lfsExclude:
+		return report.LFSPointerPaths(), nil
+	case lfsIgnore:
+		return nil, nil
+	}
+
+	shared.Logger.Printf("\nPulling Git LFS content...")
+	cmd := exec.Command("git", "lfs", "pull")
+	cmd.Dir = projectDir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		shared.Logger.Printf("%s Failed to pull Git LFS content: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	report, err = runtime.CheckFiles(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to check project files: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	if len(report.LFSPointers) > 0 {
+		shared.Logger.Printf("%s %d files are still Git LFS pointers after pulling", emoji.ErrorExclamation, len(report.LFSPointers))
+		return nil, errors.New("git lfs pointer files found")
+	}
+	shared.Logger.Printf("%s Pulled Git LFS content", emoji.Check)
+	return nil, nil
+}
+
+// externalArtifacts returns the filter of the artifacts of the micros whose src is the external source
+func externalArtifacts(micros []*types.Micro, source spacefile.ExternalSource) (func(string) bool, error) {
+	var sourceMicros []*types.Micro
+	for _, micro := range micros {
+		if path.Clean(filepath.ToSlash(micro.Src)) == source.Src {
+			m := *micro
+			m.Src = "."
+			sourceMicros = append(sourceMicros, &m)
+		}
+	}
+	return spacefile.Artifacts(sourceMicros)
+}
+
+// pushOptions of a push, the zero value pushes without a tag and follows the logs
+type pushOptions struct {
+	tag    string
+	labels map[string]string
+	// open opens the Builder instance in the browser
+	open     bool
+	skipLogs bool
+	// prebuilt pushes the artifacts of the micros and skips their build commands
+	prebuilt bool
+	// buildArgs are forwarded to the builds of all micros, together with the build_args of each micro
+	buildArgs map[string]string
+	zip       runtime.ZipOptions
+	// skipLockfileCheck pushes micros with lockfiles out of sync with their manifest
+	skipLockfileCheck bool
+	// registryArgs are the build args with the credentials of the registries by the name of the micro reading from them
+	registryArgs map[string]map[string]string
+	// secrets are masked in the logs besides the build args, e.g. the tokens of the registries within a build arg
+	secrets []string
+}
+
+// prepareArchive adds the sources of the micros outside of the project, the artifacts of a prebuilt push and the
+// credentials of the registries to the zip options, the external sources are returned for the Spacefile
+func prepareArchive(projectDir string, micros []*types.Micro, opts *pushOptions) ([]spacefile.ExternalSource, error) {
+	// sources of micros outside of the project root are archived in a directory of their own
+	externalSources, err := spacefile.ExternalSources(projectDir, micros)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to resolve the src of a micro: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	for _, source := range externalSources {
+		shared.Logger.Printf("Including %s from outside of the project as %s", styles.Code(source.Src), styles.Code(source.Archive))
+		mount := runtime.Mount{Dir: source.Dir, Path: source.Archive}
+		if opts.prebuilt {
+			if mount.Include, err = externalArtifacts(micros, source); err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return nil, err
+			}
+		}
+		opts.zip.Mounts = append(opts.zip.Mounts, mount)
+	}
+
+	if opts.prebuilt {
+		if opts.zip.Include, err = spacefile.Artifacts(micros); err != nil {
+			shared.Logger.Printf("%s %s, add the patterns of its built files to %s in the Spacefile", emoji.ErrorExclamation, err, styles.Code("artifacts"))
+			return nil, err
+		}
+		shared.Logger.Printf("Pushing the artifacts of your micros, their build commands are skipped.")
+		return externalSources, nil
+	}
+
+	if err := injectRegistries(projectDir, micros, opts); err != nil {
+		return nil, err
+	}
+	return externalSources, nil
+}
+
+// injectRegistries adds the credentials of the registries of space registry login which a micro references in its
+// manifest or lockfile to its build args, and an .npmrc reading them to the micros with a package.json. Build args of
+// the flags take precedence.
+func injectRegistries(projectDir string, micros []*types.Micro, opts *pushOptions) error {
+	registries, err := registry.Load()
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	if len(registries) == 0 {
+		return nil
+	}
+
+	for _, micro := range micros {
+		src := path.Clean(filepath.ToSlash(micro.Src))
+		// micros outside of the project root are archived separately
+		if src == ".." || strings.HasPrefix(src, "../") {
+			continue
+		}
+		referenced, err := registry.Referenced(filepath.Join(projectDir, src), registries)
+		if err != nil {
+			shared.Logger.Printf("%s Failed to find the registries of micro %s: %s", emoji.ErrorExclamation, micro.Name, err)
+			return err
+		}
+		if len(referenced) == 0 {
+			continue
+		}
+		if opts.registryArgs == nil {
+			opts.registryArgs = make(map[string]map[string]string)
+		}
+		opts.registryArgs[micro.Name] = registry.BuildArgs(referenced)
+		opts.secrets = append(opts.secrets, registry.Secrets(referenced)...)
+
+		existing, err := os.ReadFile(filepath.Join(projectDir, src, ".npmrc"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			shared.Logger.Printf("%s Failed to read the .npmrc of micro %s: %s", emoji.ErrorExclamation, micro.Name, err)
+			return err
+		}
+		if npmrc := registry.Npmrc(existing, referenced); npmrc != nil {
+			if opts.zip.Files == nil {
+				opts.zip.Files = make(map[string][]byte)
+			}
+			opts.zip.Files[path.Join(src, ".npmrc")] = npmrc
+		}
+		shared.Logger.Printf("Using the credentials of %d registries of %s for micro %s", len(referenced), styles.Code("space registry login"), micro.Name)
+	}
+	return nil
+}
+
+// checkLockfiles fails if a lockfile of a micro is out of sync with its manifest, the remote build would fail late
+// and with an error that rarely points at the lockfile
+func checkLockfiles(projectDir string, micros []*types.Micro) error {
+	checked := make(map[string]bool)
+	var outdated []string
+	for _, micro := range micros {
+		src := path.Clean(filepath.ToSlash(micro.Src))
+		if checked[src] {
+			continue
+		}
+		checked[src] = true
+
+		problems, err := lockfile.Check(filepath.Join(projectDir, filepath.FromSlash(src)))
+		if err != nil {
+			shared.Logger.Printf("%s Failed to check the lockfiles of micro %s: %s", emoji.ErrorExclamation, micro.Name, err)
+			return err
+		}
+		for _, p := range problems {
+			shared.Logger.Printf("%s %s of micro %s is out of sync with %s: %s", emoji.ErrorExclamation, styles.Code(p.Lockfile), micro.Name, styles.Code(p.Manifest), strings.Join(p.Dependencies, ", "))
+			fix := p.Fix
+			if src != "." {
+				fix = fmt.Sprintf("cd %s && %s", src, p.Fix)
+			}
+			shared.Logger.Printf("L run %s to update it", styles.Code(fix))
+			outdated = append(outdated, path.Join(src, p.Lockfile))
+		}
+	}
+	if len(outdated) > 0 {
+		shared.Logger.Printf("\nUse %s to push anyway.", styles.Code("--skip-lockfile-check"))
+		return fmt.Errorf("lockfiles out of sync: %s", strings.Join(outdated, ", "))
+	}
+	return nil
+}
+
+// pushResult is printed with --output json
+type pushResult struct {
+	ProjectID string `json:"project_id"`
+	// RevisionID is the id of the build, which becomes the revision
+	RevisionID string `json:"revision_id"`
+	Tag        string `json:"tag,omitempty"`
+	// Status is the status of the build, only complete builds update the Builder instance
+	Status string `json:"status"`
+	// BuildURL is set if the logs were skipped
+	BuildURL           string `json:"build_url,omitempty"`
+	BuilderInstanceURL string `json:"builder_instance_url,omitempty"`
+}
+
+// push creates a new revision and updates the builder instance, the result has the url of the builder instance if it
+// was updated
+func push(projectID string, projectDir string, opts pushOptions) (*pushResult, error) {
+	if err := shared.CheckQuota("push", quota.BuildsPerDay, quota.Storage); err != nil {
+		return nil, err
+	}
+
+	progress := shared.StartProgress()
+	defer progress.Done()
+
 	shared.Logger.Printf("Validating your Spacefile...")
+	progress.Phase("validating")
 
+	endValidate := profile.Start("validate")
 	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, "Spacefile"))
+	endValidate()
 	if err != nil {
 		shared.Logger.Printf("%s Failed to parse Spacefile: %s", emoji.ErrorExclamation, err)
-		return err
+		shared.AnnotateSpacefileError(projectDir, err)
+		return nil, err
+	}
+
+	shared.WarnDeprecatedEngines(s)
+	warnStaleVendor(projectDir)
+	warnDevOnlyStatic(s)
+
+	externalSources, err := prepareArchive(projectDir, s.Micros, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.prebuilt && !opts.skipLockfileCheck {
+		if err := checkLockfiles(projectDir, s.Micros); err != nil {
+			return nil, err
+		}
 	}
 
+	microBuildArgs, err := buildargs.Resolve(s.Micros, opts.buildArgs, os.LookupEnv)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	for name, args := range opts.registryArgs {
+		if microBuildArgs[name] == nil {
+			microBuildArgs[name] = make(map[string]string)
+		}
+		for arg, value := range args {
+			if _, ok := microBuildArgs[name][arg]; !ok {
+				microBuildArgs[name][arg] = value
+			}
+		}
+	}
+	for _, micro := range s.Micros {
+		if args := microBuildArgs[micro.Name]; len(args) > 0 {
+			shared.Logger.Printf("Forwarding the build args %s to micro %s", strings.Join(buildargs.Names(args), ", "), micro.Name)
+			for _, value := range args {
+				gha.Mask(value)
+			}
+		}
+	}
+	for _, secret := range opts.secrets {
+		gha.Mask(secret)
+	}
+	masker := buildargs.NewMasker(microBuildArgs, opts.secrets...)
+
 	shared.Logger.Printf(styles.Green("\nYour Spacefile looks good, proceeding with your push!"))
 
 	// push code & run build steps
-	zippedCode, nbFiles, err := runtime.ZipDir(projectDir)
+	progress.Phase("archiving")
+	endArchive := profile.Start("archive")
+	zippedCode, err := archiveProject(projectDir, opts.zip)
+	endArchive()
 	if err != nil {
 		shared.Logger.Printf("%s Failed to zip project: %s", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
+	defer zippedCode.Close()
+	nbFiles := zippedCode.files
 
-	build, err := shared.Client.CreateBuild(&api.CreateBuildRequest{AppID: projectID, Tag: pushTag})
+	buildLabels, err := labels.Merge(labels.Defaults(os.Getenv, gitHead(projectDir), shared.CurrentUser(projectDir)), opts.labels)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	build, err := shared.Client.CreateBuild(&api.CreateBuildRequest{AppID: projectID, Tag: opts.tag, Labels: buildLabels, Prebuilt: opts.prebuilt, BuildArgs: microBuildArgs})
 	if err != nil {
 		shared.Logger.Printf("%s Failed to push project: %s", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
 	shared.Logger.Printf("\n%s Successfully started your build!", emoji.Check)
 
-	// push spacefile
-	raw, err := os.ReadFile(filepath.Join(projectDir, "Spacefile"))
+	endUpload := profile.Start("upload")
+	defer endUpload()
+
+	// push spacefile, includes are resolved as the server only knows plain Spacefiles
+	raw, err := spacefile.Compose(filepath.Join(projectDir, "Spacefile"))
+	if err == nil {
+		raw, err = spacefile.RewriteSrc(raw, externalSources)
+	}
+	if err == nil && opts.prebuilt {
+		raw, err = spacefile.StripCommands(raw)
+	}
 	if err != nil {
 		shared.Logger.Printf("%s Failed to read Spacefile: %s", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
 
 	_, err = shared.Client.PushSpacefile(&api.PushSpacefileRequest{
@@ -105,8 +583,8 @@ func push(projectID string, projectDir string, pushTag string, openInBrowser boo
 		BuildID:  build.ID,
 	})
 	if err != nil {
-		shared.Logger.Println(styles.Errorf("\n%s Failed to push Spacefile, %v", emoji.ErrorExclamation, err))
-		return fmt.Errorf("failed to push Spacefile: %w", err)
+		shared.Logger.Println("\n" + shared.ErrorMessage("Failed to push Spacefile", err))
+		return nil, fmt.Errorf("failed to push Spacefile: %w", err)
 	}
 	shared.Logger.Printf("%s Successfully pushed your Spacefile!", emoji.Check)
 
@@ -118,7 +596,7 @@ func push(projectID string, projectDir string, pushTag string, openInBrowser boo
 			BuildID:     build.ID,
 		}); err != nil {
 			shared.Logger.Println(styles.Errorf("\n%s Failed to push icon, %v", emoji.ErrorExclamation, err))
-			return err
+			return nil, err
 		}
 	}
 
@@ -129,35 +607,37 @@ func push(projectID string, projectDir string, pushTag string, openInBrowser boo
 			BuildID:       build.ID,
 		}); err != nil {
 			shared.Logger.Println(styles.Errorf("\n%s Failed to push Discovery file, %v", emoji.ErrorExclamation, err))
-			return err
+			return nil, err
 		}
 		shared.Logger.Printf("%s Successfully pushed your Discovery file!", emoji.Check)
 	} else if errors.Is(err, discovery.ErrDiscoveryFileWrongCase) {
 		shared.Logger.Println(styles.Errorf("\n%s The Discovery file must be called exactly 'Discovery.md'", emoji.ErrorExclamation))
-		return err
+		return nil, err
 	} else if !errors.Is(err, discovery.ErrDiscoveryFileNotFound) {
 		shared.Logger.Println(styles.Errorf("\n%s Failed to read Discovery file, %v", emoji.ErrorExclamation, err))
-		return err
+		return nil, err
 	}
 
-	if _, err = shared.Client.PushCode(&api.PushCodeRequest{
-		BuildID: build.ID, ZippedCode: zippedCode,
-	}); err != nil {
+	err = pushCode(projectID, build.ID, zippedCode, progress)
+	if err != nil {
 		if errors.Is(auth.ErrNoAccessTokenFound, err) {
 			shared.Logger.Println(shared.LoginInfo())
-			return err
+			return nil, err
 		}
 		shared.Logger.Printf("%s Failed to push code: %s", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
+	endUpload()
+	// the push creates a new revision
+	shared.InvalidateCache(runtime.RevisionsCacheKey(projectID))
 
 	shared.Logger.Printf("\n%s Pushing your code (%d files) & running build process...\n", emoji.Package, nbFiles)
 
-	if skipLogs {
+	if opts.skipLogs {
 		b, err := shared.Client.GetBuild(&api.GetBuildRequest{BuildID: build.ID})
 		if err != nil {
 			shared.Logger.Printf(styles.Errorf("\n%s Failed to check if build was started. Please check %s for the build status.", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID)))
-			return fmt.Errorf("failed to check if build was started: %w", err)
+			return nil, fmt.Errorf("failed to check if build was started: %w", err)
 		}
 
 		var url = fmt.Sprintf("%s/%s?event=bld-%s", shared.BuilderUrl, projectID, b.Tag)
@@ -165,64 +645,76 @@ func push(projectID string, projectDir string, pushTag string, openInBrowser boo
 		shared.Logger.Println(styles.Greenf("\n%s Successfully pushed your code!", emoji.PartyPopper))
 		shared.Logger.Println("\nSkipped following build process, please check build status manually:")
 		shared.Logger.Println(styles.Codef(url))
-		if openInBrowser {
-			err = browser.OpenURL(url)
+		if opts.open {
+			err = shared.OpenURL(url)
 
 			if err != nil {
 				shared.Logger.Printf("%s Failed to open browser window", emoji.ErrorExclamation)
-				return err
+				return nil, err
 			}
 		}
 
-		return nil
+		return &pushResult{ProjectID: projectID, RevisionID: build.ID, Tag: b.Tag, Status: b.Status, BuildURL: url}, nil
 	}
 
+	progress.Phase("building")
+	endBuild := profile.Start("build")
+	defer endBuild()
+
 	// get build logs
 	readCloser, err := shared.Client.GetBuildLogs(&api.GetBuildLogsRequest{
 		BuildID: build.ID,
 	})
 	if err != nil {
 		shared.Logger.Printf("%s Error: %v\n", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
 	defer readCloser.Close()
 	// stream build logs
+	endGroup := gha.Group("Build logs")
 	scanner := bufio.NewScanner(readCloser)
 	for scanner.Scan() {
-		line := scanner.Text()
-		fmt.Println(line)
+		line := masker.Mask(scanner.Text())
+		fmt.Fprintln(shared.Stdout(), line)
 	}
+	endGroup()
 	if err := scanner.Err(); err != nil {
 		shared.Logger.Printf("%s Error: %v\n", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
 
 	// check build status
 	b, err := shared.Client.GetBuild(&api.GetBuildRequest{BuildID: build.ID})
 	if err != nil {
 		shared.Logger.Printf(styles.Errorf("\n%s Failed to check if push succeded. Please check %s if a new revision was created successfully.", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID)))
-		return err
+		return nil, err
 	}
 	if b.Status != api.Complete {
 		shared.Logger.Println(styles.Errorf("\n%s Failed to push code and create a revision. Please try again!", emoji.ErrorExclamation))
-		return err
+		gha.Error(&gha.Annotation{Title: "Build failed", Message: fmt.Sprintf("Build %s failed with status %s, see the build logs for details", b.Tag, b.Status)})
+		return nil, fmt.Errorf("build failed: %s", b.Status)
 	}
 
+	endBuild()
+	endInstall := profile.Start("install")
+	defer endInstall()
+
 	// get promotion via build id (build id == revision id)
 	p, err := shared.Client.GetPromotionByRevision(&api.GetPromotionRequest{RevisionID: build.ID})
 	if err != nil {
 		shared.Logger.Printf(styles.Errorf("\n%s Failed to get promotion. Please check %s if a new revision was created successfully.", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID)))
-		return err
+		return nil, err
 	}
 
 	shared.Logger.Printf("\n%s Updating your Builder instance with the new revision...\n\n", emoji.Tools)
+	progress.Phase("updating the Builder instance")
 
 	readCloserPromotion, err := shared.Client.GetReleaseLogs(&api.GetReleaseLogsRequest{
 		ID: p.ID,
 	})
 	if err != nil {
 		shared.Logger.Println(styles.Errorf("%s Error: %v", emoji.ErrorExclamation, err))
-		return err
+		return nil, err
 	}
 
 	defer readCloserPromotion.Close()
@@ -232,18 +724,18 @@ func push(projectID string, projectDir string, pushTag string, openInBrowser boo
 	}
 	if err := scannerPromotion.Err(); err != nil {
 		shared.Logger.Printf("%s Error: %v\n", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
 
 	// check promotion status
 	p, err = shared.Client.GetReleasePromotion(&api.GetReleasePromotionRequest{PromotionID: p.ID})
 	if err != nil {
 		shared.Logger.Printf(styles.Errorf("\n%s Failed to check if Builder instance was updated. Please check %s", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID)))
-		return err
+		return nil, err
 	}
 	if p.Status != api.Complete {
 		shared.Logger.Println(styles.Errorf("\n%s Failed to update Builder instance. Please try again!", emoji.ErrorExclamation))
-		return err
+		return nil, fmt.Errorf("promotion failed: %s", p.Status)
 	}
 
 	// get installation via promotion id (promotion id == release id)
@@ -251,7 +743,7 @@ func push(projectID string, projectDir string, pushTag string, openInBrowser boo
 	if err != nil {
 		shared.Logger.Println(styles.Errorf("%s Error: %v", emoji.ErrorExclamation, err))
 		shared.Logger.Printf(styles.Errorf("\n%s Failed to get installation. Please check %s if your Builder instance is being updated.", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID)))
-		return err
+		return nil, err
 	}
 
 	readCloserInstallation, err := shared.Client.GetInstallationLogs(&api.GetInstallationLogsRequest{
@@ -259,52 +751,159 @@ func push(projectID string, projectDir string, pushTag string, openInBrowser boo
 	})
 	if err != nil {
 		shared.Logger.Println(styles.Errorf("%s Error: %v", emoji.ErrorExclamation, err))
-		return err
+		return nil, err
 	}
 
 	var instanceUrl string
 
 	defer readCloserInstallation.Close()
+	endGroup = gha.Group("Installation logs")
 	scannerInstallation := bufio.NewScanner(readCloserInstallation)
 	for scannerInstallation.Scan() {
 		line := scannerInstallation.Text()
 		if strings.Contains(line, "http") {
 			instanceUrl = line
 		} else {
-			fmt.Println(line)
+			fmt.Fprintln(shared.Stdout(), line)
 		}
 	}
+	endGroup()
 	if err := scannerInstallation.Err(); err != nil {
 		shared.Logger.Printf("%s Error: %v\n", emoji.ErrorExclamation, err)
-		return err
+		return nil, err
 	}
 
 	// check installation status
 	i, err = shared.Client.GetInstallation(&api.GetInstallationRequest{ID: i.ID})
 	if err != nil {
 		shared.Logger.Printf(styles.Errorf("\n%s Failed to check if Builder instance was updated. Please check %s", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID)))
-		return err
+		return nil, err
 	}
 	if i.Status != api.Complete {
 		shared.Logger.Println(styles.Errorf("\n%s Failed to update Builder instance. Please try again!", emoji.ErrorExclamation))
-		return err
+		gha.Error(&gha.Annotation{Title: "Installation failed", Message: "Failed to update the Builder instance, see the installation logs for details"})
+		return nil, fmt.Errorf("installation failed: %s", i.Status)
 	}
 
-	shared.Logger.Println(styles.Greenf("\n%s Successfully pushed your code and updated your Builder instance!", emoji.PartyPopper))
+	shared.Logger.Println(styles.Greenf("\n%s %s", emoji.PartyPopper, i18n.T("push.success")))
 
 	if instanceUrl != "" {
 		shared.Logger.Printf("Builder instance: %s", styles.Code(instanceUrl))
 
-		if openInBrowser {
-			err = browser.OpenURL(instanceUrl)
+		if opts.open {
+			err = shared.OpenURL(instanceUrl)
 
 			if err != nil {
 				shared.Logger.Printf("%s Failed to open browser window", emoji.ErrorExclamation)
+				return nil, err
+			}
+		}
+	}
+
+	return &pushResult{ProjectID: projectID, RevisionID: build.ID, Tag: b.Tag, Status: b.Status, BuilderInstanceURL: instanceUrl}, nil
+
+}
+
+// pushChanged pushes the projects in dir with files changed since the git ref, dir is either a single project or a workspace with many projects
+func pushChanged(dir string, since string, projectID string, environment string, lfs string, opts pushOptions) error {
+	// a project is a workspace of its own
+	projects, err := workspace.Discover(dir)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to find projects: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	if len(projects) == 0 {
+		shared.Logger.Printf("%s No projects found in %s", emoji.ErrorExclamation, styles.Code(dir))
+		return errors.New("no projects found")
+	}
+	if projectID != "" && len(projects) > 1 {
+		shared.Logger.Printf("%s %s can't be used with a workspace of %d projects", emoji.ErrorExclamation, styles.Code("--id"), len(projects))
+		return errors.New("project id with many projects")
+	}
+
+	files, err := git.ChangedFiles(dir, since)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to get changed files: %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	changes := workspace.Changes(projects, files)
+
+	changed := map[*workspace.Project]*workspace.Change{}
+	for _, change := range changes {
+		changed[change.Project] = change
+	}
+	shared.Logger.Printf("\n%s Plan for changes since %s:\n", emoji.Package, styles.Code(since))
+	for _, project := range projects {
+		change, ok := changed[project]
+		switch {
+		case !ok:
+			shared.Logger.Printf("  %s %s", project.Dir, styles.Subtle("(unchanged, skipped)"))
+		case len(change.Micros) > 0:
+			shared.Logger.Printf("+ %s %s", styles.Bold(project.Dir), styles.Subtle(fmt.Sprintf("(%d files changed in %s)", len(change.Files), strings.Join(change.Micros, ", "))))
+		default:
+			shared.Logger.Printf("+ %s %s", styles.Bold(project.Dir), styles.Subtle(fmt.Sprintf("(%d files changed)", len(change.Files))))
+		}
+	}
+
+	results := []*workspacePushResult{}
+	if len(changes) == 0 {
+		shared.Logger.Printf("\n%s Nothing changed, skipping push", emoji.Check)
+		if shared.JSONOutput() {
+			return shared.PrintJSON(results)
+		}
+		return nil
+	}
+
+	var failed []string
+	for _, change := range changes {
+		projectDir := filepath.Join(dir, filepath.FromSlash(change.Project.Dir))
+		shared.Logger.Printf("\n%s Pushing %s...", emoji.Package, styles.Code(change.Project.Dir))
+
+		result := &workspacePushResult{Dir: change.Project.Dir}
+		err := func() error {
+			id, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
 				return err
 			}
+			projectOpts := opts
+			projectOpts.zip.Exclude, err = checkFiles(projectDir, lfs)
+			if err != nil {
+				return err
+			}
+			result.pushResult, err = push(id, projectDir, projectOpts)
+			return err
+		}()
+		if err != nil {
+			result.Error = err.Error()
+			failed = append(failed, change.Project.Dir)
 		}
+		results = append(results, result)
 	}
 
+	if shared.JSONOutput() {
+		if err := shared.PrintJSON(results); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		shared.Logger.Println(styles.Errorf("\n%s Failed to push %s", emoji.ErrorExclamation, strings.Join(failed, ", ")))
+		return fmt.Errorf("failed to push %d projects", len(failed))
+	}
 	return nil
+}
 
+// workspacePushResult is the result of pushing a project of a workspace, printed with --output json
+type workspacePushResult struct {
+	Dir string `json:"dir"`
+	*pushResult
+	Error string `json:"error,omitempty"`
+}
+
+// gitHead returns the commit checked out in the project directory, empty outside of a git repository
+func gitHead(projectDir string) string {
+	sha, err := git.Head(projectDir)
+	if err != nil {
+		return ""
+	}
+	return sha
 }
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/artifactstore"
+	"github.com/deta/space/internal/delta"
+	"github.com/deta/space/internal/home"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/util/fs"
+)
+
+// pushArchive is the zipped project, either from the artifact store or spooled to a temporary file to keep memory
+// usage low for big projects
+type pushArchive struct {
+	*os.File
+	files  int
+	digest string
+	// temp archives are removed once closed
+	temp bool
+}
+
+func (a *pushArchive) Close() error {
+	err := a.File.Close()
+	if a.temp {
+		os.Remove(a.Name())
+	}
+	return err
+}
+
+// archiveProject zips the project, unless an archive with the same content is in the artifact store. A new archive is
+// stored for later pushes, the store is skipped if it can't be used.
+func archiveProject(projectDir string, opts runtime.ZipOptions) (*pushArchive, error) {
+	digest, nbFiles, err := runtime.DigestArchive(projectDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	store, storeErr := artifactstore.Open()
+	if storeErr == nil && !runtime.CacheDisabled() {
+		if f, err := store.Get(digest); err == nil {
+			shared.Logger.Printf("%s Reusing the archive of a previous push with the same files", emoji.Package)
+			return &pushArchive{File: f, files: nbFiles, digest: digest}, nil
+		}
+	}
+
+	f, err := os.CreateTemp("", "space-push-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	archive := &pushArchive{File: f, files: nbFiles, digest: digest, temp: true}
+	if _, err := runtime.WriteZip(f, projectDir, opts); err != nil {
+		archive.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		archive.Close()
+		return nil, err
+	}
+
+	if storeErr == nil && !home.NoState() {
+		// a failure to store the archive only makes a later push slower
+		if err := store.Put(digest, f); err != nil {
+			shared.Logger.Printf("%s Failed to keep the archive for later pushes: %v", emoji.Warning, err)
+		}
+	}
+	return archive, nil
+}
+
+// minDeltaSaving is how much smaller than the archive a delta has to be to be pushed instead
+const minDeltaSaving = 0.1
+
+// pushCode uploads the archive for the build. Code with the same digest uploaded before is reused if the server can,
+// otherwise a delta against the archive pushed last to the project is uploaded. The whole archive is uploaded if
+// neither works.
+func pushCode(projectID string, buildID string, archive *pushArchive, progress *shared.Progress) error {
+	store, storeErr := artifactstore.Open()
+	if err := uploadCode(store, projectID, buildID, archive, progress); err != nil {
+		return err
+	}
+	// the archive is the base of the delta of the next push
+	if storeErr == nil && !home.NoState() {
+		store.SetLast(projectID, archive.digest)
+	}
+	return nil
+}
+
+// uploadCode uploads the archive, store is nil if the artifact store can't be used
+func uploadCode(store *artifactstore.Store, projectID string, buildID string, archive *pushArchive, progress *shared.Progress) error {
+	if _, err := shared.Client.ReuseCode(&api.ReuseCodeRequest{BuildID: buildID, Digest: archive.digest}); err == nil {
+		shared.Logger.Printf("%s Reused the code uploaded by a previous push with the same files", emoji.Check)
+		return nil
+	} else if !errors.Is(err, api.ErrCodeNotFound) {
+		// the code is uploaded anyway, e.g. if the server can't reuse code
+		shared.Logger.Printf("%s Failed to reuse code uploaded before, uploading it: %v", emoji.Warning, err)
+	}
+
+	shared.Client.OnUploadProgress = func(sent int64, total int64) {
+		progress.Percent("uploading", sent, total)
+	}
+	defer func() {
+		shared.Client.OnUploadProgress = nil
+	}()
+
+	if store != nil && pushCodeDelta(store, projectID, buildID, archive) {
+		return nil
+	}
+	if ok, err := pushCodeResumable(buildID, archive); ok || err != nil {
+		return err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := shared.Client.PushCode(&api.PushCodeRequest{
+		BuildID: buildID, ZippedCodeFile: archive.File, Digest: archive.digest,
+	})
+	return err
+}
+
+// pushCodeResumable uploads big archives in chunks, so that an interrupted push continues where it stopped when pushed
+// again. It returns false if the archive has to be uploaded at once instead.
+func pushCodeResumable(buildID string, archive *pushArchive) (bool, error) {
+	stat, err := archive.Stat()
+	if err != nil || stat.Size() < api.ResumableUploadMinSize {
+		return false, nil
+	}
+
+	err = shared.Client.UploadCode(&api.UploadCodeRequest{
+		Digest: archive.digest, Code: archive.File, Size: stat.Size(),
+		OnResume: func(received int64) {
+			shared.Logger.Printf("Resuming the interrupted upload of a previous push (%s of %s uploaded)...", fs.FormatSize(received), fs.FormatSize(stat.Size()))
+		},
+	})
+	if errors.Is(err, api.ErrResumableUploadsUnsupported) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := shared.Client.ReuseCode(&api.ReuseCodeRequest{BuildID: buildID, Digest: archive.digest}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// pushCodeDelta uploads the delta of the archive against the archive pushed last to the project, it returns false if
+// the whole archive has to be uploaded instead. Failures to push the delta aren't errors, the archive is uploaded then.
+func pushCodeDelta(store *artifactstore.Store, projectID string, buildID string, archive *pushArchive) bool {
+	baseDigest, err := store.Last(projectID)
+	if err != nil || baseDigest == archive.digest {
+		return false
+	}
+	baseFile, err := store.Get(baseDigest)
+	if err != nil {
+		return false
+	}
+	defer baseFile.Close()
+	baseStat, err := baseFile.Stat()
+	if err != nil {
+		return false
+	}
+	targetStat, err := archive.Stat()
+	if err != nil {
+		return false
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return false
+	}
+
+	deltaFile, err := os.CreateTemp("", "space-push-*.delta")
+	if err != nil {
+		return false
+	}
+	defer os.Remove(deltaFile.Name())
+	defer deltaFile.Close()
+	// the archives are streamed from disk, only the delta is written to a temporary file
+	if err := delta.Encode(deltaFile, baseFile, baseStat.Size(), archive); err != nil {
+		return false
+	}
+	stat, err := deltaFile.Stat()
+	if err != nil || float64(stat.Size()) > float64(targetStat.Size())*(1-minDeltaSaving) {
+		return false
+	}
+
+	_, err = shared.Client.PushCodeDelta(&api.PushCodeDeltaRequest{
+		BuildID: buildID, BaseDigest: baseDigest, Digest: archive.digest, DeltaFile: deltaFile,
+	})
+	switch {
+	case errors.Is(err, api.ErrDeltaRejected):
+		shared.Logger.Printf("The changes couldn't be applied to the previous push, uploading all files...")
+		return false
+	case err != nil:
+		shared.Logger.Printf("%s Failed to upload the changes, uploading all files: %v", emoji.Warning, err)
+		return false
+	}
+	shared.Logger.Printf("%s Uploaded only the changes since the previous push (%s of %s)", emoji.Check, fs.FormatSize(stat.Size()), fs.FormatSize(targetStat.Size()))
+	return true
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/filewatch"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+)
+
+// maxWatchPaths is how many of the changed paths are printed before a push
+const maxWatchPaths = 3
+
+// watchPush pushes the project every time its files change until interrupted. A failed push doesn't stop watching,
+// the next change is pushed again. The files excluded by the Git LFS check of the first push stay excluded.
+func watchPush(projectDir string, projectID string, quiet time.Duration, opts pushOptions) error {
+	ignored, err := watchIgnored(projectDir, opts.prebuilt)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to read the .spaceignore: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, errs, err := filewatch.Watch(ctx, projectDir, ignored)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to watch %s: %v", emoji.ErrorExclamation, projectDir, err))
+		return err
+	}
+	batches := filewatch.Debounce(changes, quiet)
+
+	// an interrupt only stops waiting for changes, during a push it stops the cli like it does without --watch
+	interrupts := make(chan os.Signal, 1)
+	for {
+		shared.Logger.Printf("\n%s Watching %s for changes, press Ctrl+C to stop...", emoji.Eyes, styles.Code(projectDir))
+		signal.Notify(interrupts, os.Interrupt)
+
+		var paths []string
+		for paths == nil {
+			select {
+			case <-interrupts:
+				signal.Stop(interrupts)
+				shared.Logger.Printf("\nStopped watching %s.", projectDir)
+				return nil
+			case err := <-errs:
+				shared.Logger.Println(styles.Errorf("%s Failed to watch a change: %v", emoji.ErrorExclamation, err))
+			case paths = <-batches:
+			}
+		}
+		signal.Stop(interrupts)
+
+		shared.Logger.Printf("\n%s Changed %s, pushing...", emoji.Package, summarizePaths(paths))
+		// push changes its copy of the options, every push starts from the options of the flags
+		result, err := push(projectID, projectDir, opts)
+		if err != nil {
+			continue
+		}
+		if shared.JSONOutput() {
+			if err := shared.PrintJSON(result); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchIgnored returns the paths whose changes don't need a push: the paths excluded by the .spaceignore, or only the
+// version control and cli directories for a prebuilt push as its artifacts are usually in ignored directories
+func watchIgnored(projectDir string, prebuilt bool) (func(path string) bool, error) {
+	if prebuilt {
+		return func(path string) bool {
+			name := filepath.Base(path)
+			return name == ".git" || name == ".space"
+		}, nil
+	}
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.Spaceignore(abs)
+}
+
+func summarizePaths(paths []string) string {
+	if len(paths) <= maxWatchPaths {
+		return strings.Join(paths, ", ")
+	}
+	return strings.Join(paths[:maxWatchPaths], ", ") + styles.Subtlef(" and %d more", len(paths)-maxWatchPaths)
+}
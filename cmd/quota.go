@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/quota"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdQuota() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quota",
+		Short: "Show the usage of your space against the limits of the platform",
+		Long: fmt.Sprintf(`Show the usage of your space against the limits of the platform: projects, builds per day, storage and bandwidth.
+
+space push, space release and space new warn when a quota they need is over %.0f%% used, and stop before they start if it's exhausted.`, quota.WarnRatio*100),
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := showQuota(); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func showQuota() error {
+	res, err := shared.Client.GetQuota()
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to get quota: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	shared.Logger.Printf("%s Usage of your space:\n", emoji.Stopwatch)
+	for _, r := range res.Resources {
+		u := &quota.Usage{Name: r.Name, Unit: r.Unit, Used: r.Used, Limit: r.Limit}
+		line := fmt.Sprintf("  %-15s %s %s", u.Label(), u.Bar(20), u)
+		if resetsAt, err := time.Parse(time.RFC3339, r.ResetsAt); err == nil {
+			line += styles.Subtle(", resets at " + resetsAt.Local().Format("2006-01-02 15:04"))
+		}
+		switch u.Level() {
+		case quota.Exhausted:
+			line = styles.Errorf("%s", line)
+		case quota.NearlyExhausted:
+			line += " " + emoji.Warning.String()
+		}
+		shared.Logger.Println(line)
+	}
+	return nil
+}
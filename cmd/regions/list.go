@@ -0,0 +1,101 @@
+package regions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdRegionsList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List the available regions",
+		Long: `List the regions projects can be created in, use the region id with the --region flag of space new.
+
+If a project is given with --id, or the current directory is linked to a project, the edges serving the latest release of the project are shown as well.`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("id")
+			if !cmd.Flags().Changed("id") {
+				// showing the edges is optional, so a missing project is not an error
+				cwd, _ := os.Getwd()
+				projectID, _ = runtime.GetProjectID(cwd)
+			}
+
+			if err := listRegions(projectID); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("id", "i", "", "project id to show the edges of")
+
+	return cmd
+}
+
+func listRegions(projectID string) error {
+	// the edges are fetched while the regions are listed, their errors are only shown after the regions
+	var e *api.GetEdgesResponse
+	var edgesErr error
+	edgesDone := make(chan struct{})
+	go func() {
+		defer close(edgesDone)
+		if projectID != "" {
+			e, edgesErr = shared.Client.GetEdges(&api.GetEdgesRequest{AppID: projectID})
+		}
+	}()
+
+	r, err := shared.ListRegions()
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to list regions: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	shared.Logger.Printf("%s Regions:\n", emoji.Earth)
+	for _, region := range r.Regions {
+		line := fmt.Sprintf("  %s %s", styles.Code(region.ID), region.Location)
+		if region.Default {
+			line += styles.Subtle(" (default)")
+		}
+		shared.Logger.Println(line)
+	}
+
+	if projectID == "" {
+		return nil
+	}
+
+	<-edgesDone
+	if err := edgesErr; err != nil {
+		if errors.Is(err, api.ErrProjectNotFound) {
+			shared.Logger.Println(styles.Errorf("\n%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
+			return err
+		}
+		shared.Logger.Println(styles.Errorf("\n%s Failed to get edges: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	if len(e.Edges) == 0 {
+		shared.Logger.Printf("\nThe project has no release yet, create one with %s", styles.Code("space release"))
+		return nil
+	}
+
+	shared.Logger.Printf("\n%s Edges serving the latest release:\n", emoji.Rocket)
+	for _, edge := range e.Edges {
+		shared.Logger.Printf("  %s %s %s", styles.Code(edge.Region), edge.Location, styles.Subtle(edge.Status))
+	}
+	return nil
+}
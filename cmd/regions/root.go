@@ -0,0 +1,19 @@
+package regions
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdRegions() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "regions",
+		Short: "Show the regions and edges of Deta Space",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdRegionsList())
+
+	return cmd
+}
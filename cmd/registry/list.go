@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/registry"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdRegistryList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:      "list",
+		Short:    "List the private package registries space push uses",
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registries, err := registry.Load()
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+			if len(registries) == 0 {
+				shared.Logger.Printf("No registries yet, add one with %s", styles.Code("space registry login <npm|pip>"))
+				return nil
+			}
+
+			for _, r := range registries {
+				details := "token " + registry.Mask(r.Token)
+				if r.Scope != "" {
+					details = "scope " + r.Scope + ", " + details
+				}
+				if r.Username != "" {
+					details = "username " + r.Username + ", " + details
+				}
+				shared.Logger.Printf("%s %s %s", styles.Bold(r.Kind), r.URL, styles.Subtle("("+details+")"))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
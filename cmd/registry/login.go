@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"errors"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/registry"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/components/text"
+	"github.com/spf13/cobra"
+)
+
+func newCmdRegistryLogin() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login <npm|pip> [flags]",
+		Short: "Store the credentials of a private package registry",
+		Long: `Store the credentials of a private package registry, logging in again replaces the token.
+
+npm uses the public registry unless --registry is set, --scope reads only the packages of a scope from it. pip needs the url of the index, the token is its password and __token__ the default username.
+
+The token is stored in the keyring of the system, or in the local state if there is no keyring or SPACE_TOKEN_STORE is file. space push only passes it to the builds of micros which reference the registry: npm registries without a scope are used by every micro with a package.json, other registries have to appear in the manifest or lockfile of a micro, by their host or their npm scope.
+
+Without --token you're asked for it in a terminal.`,
+		Example: `  space registry login npm --token $NPM_TOKEN
+  space registry login npm --registry https://npm.pkg.github.com --scope @my-org --token $GITHUB_TOKEN
+  space registry login pip --registry https://pypi.example.com/simple --username ci --token $PIP_TOKEN`,
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: registry.Kinds,
+		PreRunE:   shared.CheckAll(shared.CheckNotEmpty("token", "registry"), shared.CheckInteractiveOr("token")),
+		PostRunE:  shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := &registry.Registry{Kind: args[0]}
+			r.URL, _ = cmd.Flags().GetString("registry")
+			r.Scope, _ = cmd.Flags().GetString("scope")
+			r.Username, _ = cmd.Flags().GetString("username")
+			r.Token, _ = cmd.Flags().GetString("token")
+
+			if r.Token == "" {
+				token, err := text.Run(&text.Input{
+					Key:          "registry.token",
+					Prompt:       "Token of the registry",
+					PasswordMode: true,
+					Validator: func(value string) error {
+						if value == "" {
+							return errors.New("the token can't be empty")
+						}
+						return nil
+					},
+				})
+				if err != nil {
+					return err
+				}
+				r.Token = token
+			}
+
+			if err := r.Validate(); err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+			registries, err := registry.Load()
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+			inKeyring, err := registry.Save(registry.Add(registries, r))
+			if err != nil {
+				shared.Logger.Printf("%s Failed to store the registry: %s", emoji.ErrorExclamation, err)
+				return err
+			}
+
+			shared.Logger.Printf("%s Logged in to the %s registry %s, space push uses it for the micros which reference it", emoji.Check, r.Kind, styles.Code(r.URL))
+			if !inKeyring {
+				shared.Logger.Printf("%s The keyring of your system isn't available, the token is stored in %s. Encrypt it with %s.", emoji.Warning, styles.Code("space_registries"), styles.Code("space state encrypt"))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("token", "", "token of the registry")
+	cmd.Flags().String("registry", "", "url of the registry, defaults to "+registry.DefaultNPMURL+" for npm")
+	cmd.Flags().String("scope", "", "only read the npm packages of this scope from the registry, e.g. @my-org")
+	cmd.Flags().String("username", "", "username of a pip index, defaults to __token__")
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package registry
+
+import (
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/registry"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+)
+
+func newCmdRegistryLogout() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout <npm|pip> [flags]",
+		Short: "Remove the credentials of private package registries",
+		Long:  `Remove the credentials of all registries of a kind, or only of the registry of --registry.`,
+		Example: `  space registry logout npm
+  space registry logout pip --registry https://pypi.example.com/simple`,
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: registry.Kinds,
+		PreRunE:   shared.CheckNotEmpty("registry"),
+		PostRunE:  shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registryURL, _ := cmd.Flags().GetString("registry")
+
+			registries, err := registry.Load()
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return err
+			}
+			registries, removed := registry.Remove(registries, args[0], registryURL)
+			if removed == 0 {
+				shared.Logger.Printf("%s No %s registry to log out from", emoji.ErrorExclamation, args[0])
+				return shared.ErrReported
+			}
+			if _, err := registry.Save(registries); err != nil {
+				shared.Logger.Printf("%s Failed to remove the registry: %s", emoji.ErrorExclamation, err)
+				return err
+			}
+
+			shared.Logger.Printf("%s Logged out from %d %s registries", emoji.Check, removed, args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().String("registry", "", "url of the registry, all registries of the kind if not set")
+
+	return cmd
+}
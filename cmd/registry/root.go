@@ -0,0 +1,25 @@
+package registry
+
+import (
+	"github.com/deta/space/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdRegistry() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Manage the credentials of private package registries",
+		Long: `Manage the credentials of private package registries, so that micros depending on private packages can be built on Space.
+
+The credentials are stored in your local Space state like your access token, encrypted if the state is encrypted with space state encrypt. space push injects them into the build: the token of an npm registry is forwarded as a build arg and read by an .npmrc added to every micro with a package.json, a pip index is forwarded with its credentials as ` + registry.PipIndexEnv + `. The tokens are masked in the build logs and aren't part of the pushed code.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdRegistryLogin())
+	cmd.AddCommand(newCmdRegistryLogout())
+	cmd.AddCommand(newCmdRegistryList())
+
+	return cmd
+}
@@ -4,35 +4,74 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/deta/space/cmd/shared"
 	"github.com/deta/space/internal/api"
 	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/conventional"
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/internal/git"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/internal/labels"
+	"github.com/deta/space/internal/profile"
+	"github.com/deta/space/internal/quota"
 	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/semver"
+	"github.com/deta/space/internal/spaceconfig"
 	"github.com/deta/space/pkg/components/choose"
 	"github.com/deta/space/pkg/components/confirm"
 	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/components/text"
 	"github.com/spf13/cobra"
 )
 
 const (
-	ReleaseChannelExp = "experimental"
+	// onConflictBump bumps the patch version until the version doesn't exist
+	onConflictBump = "bump"
+	// onConflictFail aborts the release if the version exists
+	onConflictFail = "fail"
 )
 
 func newCmdRelease() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:      "release [flags]",
-		Short:    "Create a new release from a revision",
-		PreRunE:  shared.CheckAll(shared.CheckProjectInitialized("dir"), shared.CheckNotEmpty("id", "rid", "version")),
+		Use:   "release [flags]",
+		Short: "Create a new release from a revision",
+		Long: `Create a new release from a revision.
+
+With --auto, the commits since the last release tag are read from git. The version is bumped according to the conventional commit types (fix is a patch, feat is a minor and breaking changes are a major release) and the release notes list the changes. The revision pushed from the git HEAD, which space push labels with the sha of the commit, is released and tagged in git. The release fails if no revision was pushed from HEAD.
+
+If the version already exists, you are asked to bump the patch version, pick another version or overwrite the notes of the existing release. Without a terminal, --on-conflict decides if the patch version is bumped or the release fails.
+
+With --bump patch, minor or major, the version is the next one after the version of the latest release, which has to be a semantic version. The latest release is the one created last in any channel, a rollback included.
+
+With --notes-file, the release notes are read from a file. If it's a changelog in the Keep a Changelog format, like a CHANGELOG.md, only the section of the version of the release is used.
+
+With --yes, the latest revision is released without a prompt and an existing version is bumped unless --on-conflict is set.
+
+Releases are created in the experimental channel unless --channel stable promotes them to everyone who installs the app. In a terminal you're asked for the channel if --channel isn't set, except with --auto.
+
+During a freeze window of the project config, releases fail unless --override-freeze is passed. An override is recorded on the release, a line naming the window and the user is added to its notes, and logged in .space/freeze-overrides.log.
+
+If the project config requires approval, releases need --approved-by with the handle of someone other than the user who releases. The approver is recorded with the release.`,
+		Example: `  space release --yes --version 1.2.0 --listed
+  space release --auto
+  space release --rid r0abc1234 --version 1.2.1 --notes "Fixes the login"
+  space release --yes --version 1.2.1 --notes-file CHANGELOG.md
+  space release --yes --bump minor
+  space release --yes --version 1.3.0 --approved-by octocat
+  space release --yes --version 1.3.0 --channel stable`,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "rid", "version", "environment"), shared.CheckOneOf("on-conflict", onConflictBump, onConflictFail), shared.CheckOneOf("bump", "patch", "minor", "major"), shared.CheckOneOf("channel", api.ReleaseChannels...)),
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 
-			if !shared.IsOutputInteractive() && !cmd.Flags().Changed("rid") && !cmd.Flags().Changed("confirm") {
-				shared.Logger.Printf("revision id or confirm flag must be provided in non-interactive mode")
-				os.Exit(1)
+			autoRelease, _ := cmd.Flags().GetBool("auto")
+			if !shared.CanPrompt() && !cmd.Flags().Changed("rid") && !cmd.Flags().Changed("confirm") && !autoRelease {
+				shared.Logger.Printf("rid or yes flag must be provided in non-interactive mode")
+				return shared.ErrReported
 			}
 
 			projectDir, _ := cmd.Flags().GetString("dir")
@@ -42,26 +81,94 @@ func newCmdRelease() *cobra.Command {
 			useLatestRevision, _ := cmd.Flags().GetBool("confirm")
 			listedRelease, _ := cmd.Flags().GetBool("listed")
 			releaseVersion, _ := cmd.Flags().GetString("version")
+			onConflict, _ := cmd.Flags().GetString("on-conflict")
+			channel, _ := cmd.Flags().GetString("channel")
+
+			environment, _ := cmd.Flags().GetString("environment")
+
+			projectID, err = shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+			if err := shared.ConfirmProtected(cmd, projectDir, projectID, "create a release"); err != nil {
+				return err
+			}
+			overrideFreeze, _ := cmd.Flags().GetBool("override-freeze")
+			freezeOverride, err := checkReleaseFreeze(projectDir, projectID, overrideFreeze)
+			if err != nil {
+				return err
+			}
+			approvedBy, _ := cmd.Flags().GetString("approved-by")
+			if approvedBy, err = checkReleaseApproval(projectDir, projectID, approvedBy); err != nil {
+				return err
+			}
+			if err := shared.CheckQuota("release", quota.Storage, quota.Bandwidth); err != nil {
+				return err
+			}
 
-			if !cmd.Flags().Changed("id") {
-				projectMeta, err := runtime.GetProjectMeta(projectDir)
+			var releaseTag string
+			if autoRelease {
+				var notes string
+				releaseVersion, releaseTag, notes, err = nextAutoRelease(projectDir)
 				if err != nil {
-					os.Exit(1)
+					return err
+				}
+				if releaseVersion == "" {
+					if shared.JSONOutput() {
+						return shared.PrintJSON(&releaseResult{ProjectID: projectID, Status: releaseStatusNothingToRelease})
+					}
+					return nil
+				}
+				if !cmd.Flags().Changed("notes") && !cmd.Flags().Changed("notes-file") {
+					releaseNotes = notes
+				}
+				useLatestRevision = true
+			}
+
+			if bump, _ := cmd.Flags().GetString("bump"); bump != "" {
+				if releaseVersion, err = bumpLatestRelease(projectID, releaseBumps[bump]); err != nil {
+					return err
+				}
+			}
+
+			if notesFile, _ := cmd.Flags().GetString("notes-file"); notesFile != "" {
+				if releaseNotes, err = readNotesFile(notesFile, releaseVersion); err != nil {
+					return err
+				}
+			}
+
+			if edit, _ := cmd.Flags().GetBool("edit"); edit {
+				if !shared.IsOutputInteractive() {
+					shared.Logger.Printf("edit flag can only be used in interactive mode")
+					return shared.ErrReported
+				}
+				if releaseNotes, err = editReleaseNotes(releaseVersion, releaseNotes); err != nil {
+					return err
 				}
-				projectID = projectMeta.ID
 			}
 
-			if !cmd.Flags().Changed("rid") {
+			if freezeOverride != "" {
+				releaseNotes = strings.TrimSpace(releaseNotes + "\n\n" + freezeOverride)
+			}
+
+			if !cmd.Flags().Changed("rid") && autoRelease {
+				revision, err := selectHeadRevision(projectDir, projectID)
+				if err != nil {
+					return err
+				}
+				shared.Logger.Printf("\nSelected revision: %s", styles.Blue(revision.Tag))
+				revisionID = revision.ID
+			} else if !cmd.Flags().Changed("rid") {
 				if !cmd.Flags().Changed("confirm") {
-					useLatestRevision, err = confirm.Run("Do you want to use the latest revision?")
+					useLatestRevision, err = confirm.Run("release.latest_revision", "Do you want to use the latest revision?")
 					if err != nil {
-						os.Exit(1)
+						return err
 					}
 				}
 
 				revision, err := selectRevision(projectID, useLatestRevision)
 				if err != nil {
-					os.Exit(1)
+					return err
 				}
 				shared.Logger.Printf("\nSelected revision: %s", styles.Blue(revision.Tag))
 
@@ -69,28 +176,125 @@ func newCmdRelease() *cobra.Command {
 
 			}
 
-			shared.Logger.Printf(getCreatingReleaseMsg(listedRelease, useLatestRevision))
-			if err := release(projectDir, projectID, revisionID, releaseVersion, listedRelease, releaseNotes); err != nil {
-				os.Exit(1)
+			if !cmd.Flags().Changed("channel") && shared.CanPrompt() && !autoRelease {
+				if channel, err = selectReleaseChannel(); err != nil {
+					return err
+				}
+			}
+
+			shared.Logger.Printf(getCreatingReleaseMsg(listedRelease, useLatestRevision, channel))
+			started := time.Now()
+			var result *releaseResult
+			var existingVersion string
+			for {
+				result, err = release(projectDir, projectID, revisionID, releaseVersion, channel, listedRelease, releaseNotes, approvedBy)
+				if !errors.Is(err, api.ErrReleaseVersionExists) {
+					break
+				}
+				tagPrefix := strings.TrimSuffix(releaseTag, releaseVersion)
+				existingVersion = releaseVersion
+				if releaseVersion, err = resolveVersionConflict(projectID, releaseVersion, releaseNotes, onConflict); err != nil || releaseVersion == "" {
+					break
+				}
+				if releaseTag != "" {
+					releaseTag = tagPrefix + releaseVersion
+				}
+			}
+			shared.NotifyFinished(cmd, started, "Release", err)
+			if err != nil {
+				return err
+			}
+			if releaseVersion == "" {
+				// the notes of the existing release were overwritten
+				if shared.JSONOutput() {
+					return shared.PrintJSON(&releaseResult{ProjectID: projectID, Version: existingVersion, Status: releaseStatusNotesUpdated})
+				}
+				return nil
+			}
+
+			result.FreezeOverride = freezeOverride
+			shared.CopyToClipboard(cmd, "install url of the release", result.InstallURL)
+
+			if releaseTag != "" {
+				if err := git.CreateTag(projectDir, releaseTag); err != nil {
+					shared.Logger.Printf("%s Failed to tag the release: %s", emoji.Warning, err)
+				} else {
+					result.Tag = releaseTag
+					shared.Logger.Printf("\n%s Tagged the release as %s, publish the tag with %s", emoji.Check, styles.Code(releaseTag), styles.Codef("git push origin %s", releaseTag))
+				}
 			}
+			if shared.JSONOutput() {
+				return shared.PrintJSON(result)
+			}
+			return nil
 		},
 	}
 
 	cmd.Flags().StringP("dir", "d", "./", "src of project to release")
 	cmd.Flags().StringP("id", "i", "", "project id of an existing project")
+	cmd.Flags().String("environment", "", "environment of the project config to release, defaults to the environment of the current branch")
 	cmd.Flags().String("rid", "", "revision id for release")
 	cmd.Flags().StringP("version", "v", "", "version for the release")
+	cmd.Flags().String("bump", "", "release the next patch, minor or major version after the latest release")
 	cmd.Flags().Bool("listed", false, "listed on discovery")
+	cmd.Flags().String("channel", api.ReleaseChannelExperimental, "channel of the release: experimental or stable, asks in a terminal if not set")
 	cmd.Flags().Bool("confirm", false, "confirm to use latest revision")
+	cmd.Flags().MarkDeprecated("confirm", "use --yes to release the latest revision without a prompt")
 	cmd.Flags().StringP("notes", "n", "", "release notes")
+	cmd.Flags().String("notes-file", "", "read the release notes from a file, only the section of the version if it's a changelog")
+	cmd.Flags().Bool("edit", false, "write the release notes in your editor, starting with the notes of --notes, --notes-file or --auto")
+
+	cmd.Flags().Bool("auto", false, "derive the version and notes from the conventional commits since the last release tag")
+	cmd.Flags().String("on-conflict", "", "what to do if the version exists without a terminal: bump or fail (default fail)")
+	cmd.Flags().Bool("override-freeze", false, "release during a freeze window of the project config, the override is added to the release notes")
+	shared.AddNotifyFlag(cmd)
+	shared.AddCopyFlag(cmd, "install url of the release")
+	cmd.Flags().String("approved-by", "", "handle of the person who approved the release, required if the project config requires approval")
+
+	cmd.AddCommand(newCmdReleaseNotes())
+	cmd.AddCommand(newCmdReleaseShow())
+	cmd.AddCommand(newCmdReleaseExplain())
+	cmd.AddCommand(newCmdReleaseRollback())
 
 	cmd.MarkFlagsMutuallyExclusive("confirm", "rid")
+	cmd.MarkFlagsMutuallyExclusive("auto", "version")
+	cmd.MarkFlagsMutuallyExclusive("bump", "version")
+	cmd.MarkFlagsMutuallyExclusive("bump", "auto")
+	cmd.MarkFlagsMutuallyExclusive("notes", "notes-file")
 
 	return cmd
 }
 
+// selectHeadRevision returns the latest revision pushed from the git HEAD of the project, the commits of HEAD are the
+// ones the version and notes of an automatic release are derived from
+func selectHeadRevision(projectDir string, projectID string) (*api.Revision, error) {
+	sha, err := git.Head(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to read the git HEAD: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	r, err := shared.GetRevisions(projectID, true)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return nil, err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to get revisions: %v", emoji.ErrorExclamation, err))
+		return nil, err
+	}
+	for _, revision := range r.Revisions {
+		if revision.Labels[labels.GitSHA] == sha {
+			return revision, nil
+		}
+	}
+
+	shared.Logger.Println(styles.Errorf("%s No revision was pushed from the commit %s, push it with %s first or pass the revision with %s", emoji.ErrorExclamation, sha, styles.Code("space push"), styles.Code("--rid")))
+	return nil, shared.ErrReported
+}
+
 func selectRevision(projectID string, useLatestRevision bool) (*api.Revision, error) {
-	r, err := shared.Client.GetRevisions(&api.GetRevisionsRequest{ID: projectID})
+	r, err := shared.GetRevisions(projectID, useLatestRevision)
 	if err != nil {
 		if errors.Is(err, auth.ErrNoAccessTokenFound) {
 			shared.Logger.Println(shared.LoginInfo())
@@ -103,7 +307,7 @@ func selectRevision(projectID string, useLatestRevision bool) (*api.Revision, er
 	revisions := r.Revisions
 
 	if len(r.Revisions) == 0 {
-		shared.Logger.Printf(styles.Errorf("%s No revisions found. Please create a revision by running %s", emoji.ErrorExclamation, styles.Code("space push")))
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, i18n.T("hint.no_revisions", styles.Code("space push"))))
 		return nil, err
 	}
 
@@ -123,6 +327,7 @@ func selectRevision(projectID string, useLatestRevision bool) (*api.Revision, er
 	}
 
 	tag, err := choose.Run(
+		"release.revision",
 		fmt.Sprintf("Choose a revision %s:", styles.Subtle("(most recent revisions)")),
 		tags...,
 	)
@@ -133,72 +338,404 @@ func selectRevision(projectID string, useLatestRevision bool) (*api.Revision, er
 	return revisionMap[tag], nil
 }
 
-func release(projectDir string, projectID string, revisionID string, releaseVersion string, listedRelease bool, releaseNotes string) (err error) {
+// selectReleaseChannel asks for the channel of the release, experimental is the default
+func selectReleaseChannel() (string, error) {
+	return choose.Run(
+		"release.channel",
+		fmt.Sprintf("Choose a release channel %s:", styles.Subtle("(stable releases are promoted to everyone who installs the app)")),
+		api.ReleaseChannels...,
+	)
+}
+
+// releaseResult is printed with --output json
+const (
+	// releaseStatusNotesUpdated is the status of the result if the notes of an existing release were overwritten
+	releaseStatusNotesUpdated = "notes_updated"
+	// releaseStatusNothingToRelease is the status of the result of --auto without changes since the last release
+	releaseStatusNothingToRelease = "nothing_to_release"
+)
+
+type releaseResult struct {
+	ReleaseID  string `json:"release_id"`
+	ProjectID  string `json:"project_id"`
+	RevisionID string `json:"revision_id"`
+	Version    string `json:"version"`
+	Channel    string `json:"channel"`
+	Listed     bool   `json:"listed"`
+	Status     string `json:"status"`
+	// Tag is the git tag of the release, if it was tagged
+	Tag string `json:"tag,omitempty"`
+	// FreezeOverride describes the freeze window the release was made in, if it was overridden
+	FreezeOverride string `json:"freeze_override,omitempty"`
+	// InstallURL is where the release is installed from on Discovery
+	InstallURL string `json:"install_url,omitempty"`
+}
+
+func release(projectDir string, projectID string, revisionID string, releaseVersion string, channel string, listedRelease bool, releaseNotes string, approvedBy string) (*releaseResult, error) {
 	cr, err := shared.Client.CreateRelease(&api.CreateReleaseRequest{
 		RevisionID:    revisionID,
 		AppID:         projectID,
 		Version:       releaseVersion,
 		ReleaseNotes:  releaseNotes,
 		DiscoveryList: listedRelease,
-		Channel:       ReleaseChannelExp, // always experimental release for now
+		Channel:       channel,
+		ApprovedBy:    approvedBy,
 	})
 	if err != nil {
 		if errors.Is(err, auth.ErrNoAccessTokenFound) {
 			shared.Logger.Println(shared.LoginInfo())
-			return nil
+			return nil, err
 		}
-		shared.Logger.Println(styles.Errorf("%s Failed to create release: %v", emoji.ErrorExclamation, err))
-		return err
+		if errors.Is(err, api.ErrReleaseVersionExists) {
+			// the caller resolves the conflict
+			return nil, err
+		}
+		shared.Logger.Println(shared.ErrorMessage("Failed to create release", err))
+		return nil, err
+	}
+	status, err := followRelease(projectID, cr.ID, releaseVersion, listedRelease)
+	if err != nil {
+		return nil, err
 	}
+
+	return &releaseResult{
+		ReleaseID:  cr.ID,
+		ProjectID:  projectID,
+		RevisionID: revisionID,
+		Version:    releaseVersion,
+		Channel:    channel,
+		Listed:     listedRelease,
+		Status:     status,
+		InstallURL: installURL(cr.ID),
+	}, nil
+}
+
+// installURL returns the url of the release on Discovery, where anyone can install their own copy of the app
+func installURL(releaseID string) string {
+	return fmt.Sprintf("%s/r/%s", shared.DiscoveryUrl, releaseID)
+}
+
+// followRelease streams the logs of the release with the id of its promotion until it's done and returns its status
+func followRelease(projectID string, promotionID string, releaseVersion string, listedRelease bool) (string, error) {
+	endRelease := profile.Start("release")
+	defer endRelease()
+	progress := shared.StartProgress()
+	defer progress.Done()
+	progress.Phase("releasing " + releaseVersion)
+
 	readCloser, err := shared.Client.GetReleaseLogs(&api.GetReleaseLogsRequest{
-		ID: cr.ID,
+		ID: promotionID,
 	})
 	if err != nil {
 		shared.Logger.Println(styles.Errorf("%s Error: %v", emoji.ErrorExclamation, err))
-		return err
+		return "", err
 	}
 
 	defer readCloser.Close()
+	endGroup := gha.Group("Release logs")
 	scanner := bufio.NewScanner(readCloser)
 	for scanner.Scan() {
 		line := scanner.Text()
-		fmt.Println(line)
+		fmt.Fprintln(shared.Stdout(), line)
 	}
+	endGroup()
 	if err := scanner.Err(); err != nil {
 		shared.Logger.Printf("%s Error: %v\n", emoji.ErrorExclamation, err)
-		return err
+		return "", err
 	}
 
-	r, err := shared.Client.GetReleasePromotion(&api.GetReleasePromotionRequest{PromotionID: cr.ID})
+	r, err := shared.Client.GetReleasePromotion(&api.GetReleasePromotionRequest{PromotionID: promotionID})
 	if err != nil {
 		shared.Logger.Printf(styles.Errorf("\n%s Failed to check if release succeeded. Please check %s if a new release was created successfully.", emoji.ErrorExclamation, styles.Codef("%s/%s/develop", shared.BuilderUrl, projectID)))
-		return err
+		return "", err
 	}
 
 	if r.Status == api.Complete {
 		shared.Logger.Println()
-		shared.Logger.Println(emoji.Rocket, "Lift off -- successfully created a new Release!")
-		shared.Logger.Println(emoji.Earth, "Your Release is available globally on 5 Deta Edges")
-		shared.Logger.Println(emoji.PartyFace, "Anyone can install their own copy of your app.")
+		shared.Logger.Println(emoji.Rocket, i18n.T("release.success"))
+		shared.Logger.Println(emoji.Earth, edgesMsg(projectID))
+		shared.Logger.Println(emoji.PartyFace, i18n.T("release.install"))
+		shared.Logger.Printf("L %s", installURL(promotionID))
 		if listedRelease {
-			shared.Logger.Println(emoji.CrystalBall, "Listed on Discovery for others to find!")
+			shared.Logger.Println(emoji.CrystalBall, i18n.T("release.listed"))
+		}
+	} else {
+		shared.Logger.Println(styles.Errorf("\n%s %s", emoji.ErrorExclamation, i18n.T("release.failed")))
+		gha.Error(&gha.Annotation{Title: "Release failed", Message: fmt.Sprintf("Release %s failed with status %s, see the release logs for details", releaseVersion, r.Status)})
+		return "", fmt.Errorf("release failed: %s", r.Status)
+	}
+
+	return r.Status, nil
+}
+
+// checkReleaseFreeze fails during a freeze window of the project config unless it's overridden, overrides are logged
+// with the user who released and described for the notes of the release
+func checkReleaseFreeze(projectDir string, projectID string, override bool) (string, error) {
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return "", err
+	}
+	now := time.Now()
+	window := config.ActiveFreeze(now)
+	if window == nil {
+		return "", nil
+	}
+
+	if !override {
+		shared.Logger.Println(styles.Errorf("%s Releases are frozen during %s, pass %s to release anyway", emoji.ErrorExclamation, window, styles.Code("--override-freeze")))
+		return "", errors.New("release freeze")
+	}
+
+	user := shared.CurrentUser(projectDir)
+	shared.Logger.Printf("%s Release freeze %s overridden by %s, the override is added to the release notes", emoji.Warning, window, styles.Blue(user))
+	entry := fmt.Sprintf("%s\t%s\t%s\t%s", now.UTC().Format(time.RFC3339), projectID, user, window)
+	if err := runtime.LogFreezeOverride(projectDir, entry); err != nil {
+		shared.Logger.Printf("%s Failed to log the freeze override: %v", emoji.Warning, err)
+	}
+	return fmt.Sprintf("Released during the release freeze %s, overridden by %s.", window, user), nil
+}
+
+// checkReleaseApproval returns the normalized handle of the approver, it fails if the project config requires approval
+// and there is none, or if the user who releases approves their own release
+func checkReleaseApproval(projectDir string, projectID string, approvedBy string) (string, error) {
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return "", err
+	}
+
+	approvedBy = strings.TrimPrefix(strings.TrimSpace(approvedBy), "@")
+	if approvedBy == "" {
+		if config.ApprovalRequired(projectID) {
+			shared.Logger.Println(styles.Errorf("%s Releases of this project require approval, pass the approver with %s", emoji.ErrorExclamation, styles.Code("--approved-by <handle>")))
+			return "", errors.New("release approval required")
+		}
+		return "", nil
+	}
+	if strings.ContainsAny(approvedBy, " \t\n") {
+		shared.Logger.Println(styles.Errorf("%s Invalid approver %q, the handle can't contain spaces", emoji.ErrorExclamation, approvedBy))
+		return "", errors.New("invalid approver")
+	}
+
+	handle, err := shared.CurrentHandle()
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to check the approver, your Space handle is unknown: %v", emoji.ErrorExclamation, err))
+		shared.Logger.Printf("Set it with %s", styles.Code("space config set handle <handle>"))
+		return "", shared.ErrReported
+	}
+	if strings.EqualFold(approvedBy, handle) {
+		shared.Logger.Println(styles.Errorf("%s @%s can't approve their own release, ask someone else to approve it", emoji.ErrorExclamation, handle))
+		return "", errors.New("release approved by the releaser")
+	}
+	shared.Logger.Printf("%s Release approved by %s", emoji.Check, styles.Blue(approvedBy))
+	return approvedBy, nil
+}
+
+// resolveVersionConflict returns the version to release instead of an existing version, in a terminal the user chooses
+// unless the on-conflict flag is set. The version is empty if the notes of the existing release were overwritten instead.
+func resolveVersionConflict(projectID string, version string, notes string, onConflict string) (string, error) {
+	shared.Logger.Printf("%s Version %s already exists", emoji.Warning, styles.Blue(version))
+
+	bumped, bumpErr := bumpPatch(version)
+	if onConflict == "" && shared.CanPrompt() {
+		bumpChoice := fmt.Sprintf("Bump the patch version to %s", bumped)
+		otherChoice := "Pick another version"
+		notesChoice := fmt.Sprintf("Overwrite the notes of release %s", version)
+		choices := []string{otherChoice, notesChoice, "Cancel"}
+		if bumpErr == nil {
+			choices = append([]string{bumpChoice}, choices...)
+		}
+
+		choice, err := choose.Run("release.conflict", "How do you want to continue?", choices...)
+		if err != nil {
+			return "", err
+		}
+		switch choice {
+		case bumpChoice:
+			onConflict = onConflictBump
+		case notesChoice:
+			existing, err := selectRelease(projectID, version)
+			if err != nil {
+				return "", err
+			}
+			return "", updateRelease(existing, &api.UpdateReleaseRequest{ID: existing.ID, ReleaseNotes: &notes})
+		case otherChoice:
+			return text.Run(&text.Input{
+				Key:    "release.version",
+				Prompt: "Version",
+				Validator: func(value string) error {
+					if value == version {
+						return fmt.Errorf("version %s already exists", version)
+					}
+					return nil
+				},
+			})
+		default:
+			return "", errors.New("release cancelled")
+		}
+	}
+
+	if onConflict != onConflictBump {
+		shared.Logger.Println(styles.Errorf("%s Failed to create release: version %s already exists, choose another version or use %s", emoji.ErrorExclamation, version, styles.Code("--on-conflict bump")))
+		return "", api.ErrReleaseVersionExists
+	}
+	if bumpErr != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to bump version: %v", emoji.ErrorExclamation, bumpErr))
+		return "", bumpErr
+	}
+	shared.Logger.Printf("%s Releasing version %s instead\n", emoji.Package, styles.Blue(bumped))
+	return bumped, nil
+}
+
+// bumpPatch increments the patch version of a semantic version, the v prefix is kept
+func bumpPatch(version string) (string, error) {
+	return bumpVersion(version, semver.BumpPatch)
+}
+
+// bumpVersion returns the next version, keeping a v prefix
+func bumpVersion(version string, bump semver.Bump) (string, error) {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return "", err
+	}
+	prefix := ""
+	if strings.HasPrefix(strings.TrimSpace(version), "v") {
+		prefix = "v"
+	}
+	return prefix + v.Bump(bump).String(), nil
+}
+
+// releaseBumps are the values of the bump flag
+var releaseBumps = map[string]semver.Bump{
+	"patch": semver.BumpPatch,
+	"minor": semver.BumpMinor,
+	"major": semver.BumpMajor,
+}
+
+// bumpLatestRelease returns the version after the version of the latest release of the project, the release created
+// last in any channel. The first release is bumped from 0.0.0.
+func bumpLatestRelease(projectID string, bump semver.Bump) (string, error) {
+	r, err := shared.Client.ListReleases(&api.ListReleasesRequest{AppID: projectID})
+	if err != nil {
+		if errors.Is(err, auth.ErrNoAccessTokenFound) {
+			shared.Logger.Println(shared.LoginInfo())
+			return "", err
 		}
+		shared.Logger.Println(styles.Errorf("%s Failed to list releases: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+
+	latest := "0.0.0"
+	if len(r.Releases) > 0 {
+		latest = r.Releases[0].Version
+	}
+	version, err := bumpVersion(latest, bump)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s The latest release %s isn't a semantic version, set the version with %s", emoji.ErrorExclamation, styles.Blue(latest), styles.Code("--version")))
+		return "", shared.ErrReported
+	}
+	if len(r.Releases) == 0 {
+		shared.Logger.Printf("%s No release yet, releasing %s", emoji.Check, styles.Blue(version))
 	} else {
-		shared.Logger.Println(styles.Errorf("\n%s Failed to create release. Please try again!", emoji.ErrorExclamation))
-		return fmt.Errorf("release failed: %s", r.Status)
+		shared.Logger.Printf("%s Bumped the %s version of the latest release %s to %s", emoji.Check, bump, styles.Blue(latest), styles.Blue(version))
+	}
+	return version, nil
+}
+
+func edgesMsg(projectID string) string {
+	e, err := shared.Client.GetEdges(&api.GetEdgesRequest{AppID: projectID})
+	if err != nil || len(e.Edges) == 0 {
+		return i18n.T("release.edges_unknown")
 	}
 
-	return nil
+	locations := make([]string, 0, len(e.Edges))
+	for _, edge := range e.Edges {
+		locations = append(locations, edge.Location)
+	}
+	return i18n.T("release.edges", len(e.Edges), strings.Join(locations, ", "))
 }
 
-func getCreatingReleaseMsg(listed bool, latest bool) string {
+func getCreatingReleaseMsg(listed bool, latest bool, channel string) string {
 	var listedInfo string
 	var latestInfo string
 	if listed {
 		listedInfo = " listed"
 	}
+	if channel == api.ReleaseChannelStable {
+		listedInfo += " stable"
+	}
 	if latest {
 		latestInfo = " with the latest Revision"
 	}
 	return fmt.Sprintf("\n%s Creating a%s Release%s ...\n\n", emoji.Package, listedInfo, latestInfo)
 }
+
+// nextAutoRelease derives the next version, its git tag and release notes from the conventional commits since the last release tag,
+// the version is empty if there is nothing to release
+func nextAutoRelease(projectDir string) (version string, tag string, notes string, err error) {
+	tags, err := git.Tags(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to read git tags: %s", emoji.ErrorExclamation, err)
+		return "", "", "", err
+	}
+
+	var last *semver.Version
+	var lastTag string
+	for _, t := range tags {
+		v, err := semver.Parse(t)
+		if err != nil {
+			continue
+		}
+		if last == nil || v.Compare(last) > 0 {
+			last, lastTag = v, t
+		}
+	}
+
+	gitCommits, err := git.Log(projectDir, lastTag)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to read git log: %s", emoji.ErrorExclamation, err)
+		return "", "", "", err
+	}
+
+	var commits []*conventional.Commit
+	for _, c := range gitCommits {
+		if commit, ok := conventional.Parse(c.Subject, c.Body); ok {
+			commits = append(commits, commit)
+		}
+	}
+
+	since := "the first commit"
+	if lastTag != "" {
+		since = lastTag
+	}
+	bump := conventional.Bump(commits)
+	if bump == semver.BumpNone {
+		shared.Logger.Printf("%s No features or fixes since %s, nothing to release", emoji.Check, styles.Code(since))
+		return "", "", "", nil
+	}
+
+	prefix := "v"
+	if last == nil {
+		last = &semver.Version{}
+	} else if !strings.HasPrefix(lastTag, "v") {
+		prefix = ""
+	}
+	next := last.Bump(bump)
+	notes = conventional.Notes(commits)
+
+	shared.Logger.Printf("\n%s %d commits since %s, releasing %s as a %s release\n", emoji.Package, len(gitCommits), styles.Code(since), styles.Blue(next.String()), bump)
+	shared.Logger.Println(notes)
+
+	if shared.CanPrompt() {
+		ok, err := confirm.Run("release.confirm_version", fmt.Sprintf("Do you want to release version %s?", next))
+		if err != nil {
+			return "", "", "", err
+		}
+		if !ok {
+			return "", "", "", nil
+		}
+	}
+
+	return next.String(), prefix + next.String(), notes, nil
+}
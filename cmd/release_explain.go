@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/pipeline"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+const (
+	explainFormatText    = "text"
+	explainFormatMermaid = "mermaid"
+)
+
+func newCmdReleaseExplain() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain [flags]",
+		Short: "Show the stages of the release pipeline and where a release is",
+		Long: `Show the stages a release goes through and the state of the release at each of them: the revision, its build, the promotion, the edges serving the release and its Discovery listing.
+
+A stage after one which is still running or failed is pending. With --format mermaid the pipeline is printed as a mermaid flowchart on stdout, e.g. for docs.`,
+		Example: `  space release explain
+  space release explain --version 1.2.0
+  space release explain --format mermaid > docs/release.mmd`,
+		Args: cobra.NoArgs,
+		PreRunE: shared.CheckAll(
+			shared.CheckProjectTarget("dir", "id"),
+			shared.CheckNotEmpty("id", "version", "environment"),
+			shared.CheckOneOf("format", explainFormatText, explainFormatMermaid),
+		),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			releaseVersion, _ := cmd.Flags().GetString("version")
+			format, _ := cmd.Flags().GetString("format")
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+			release, err := selectRelease(projectID, releaseVersion)
+			if err != nil {
+				return err
+			}
+
+			details := getReleaseDetails(projectID, release)
+			stages := pipeline.Explain(pipelineRelease(details))
+			switch {
+			case shared.JSONOutput():
+				return shared.PrintJSON(struct {
+					Version string            `json:"version"`
+					Stages  []*pipeline.Stage `json:"stages"`
+				}{Version: release.Version, Stages: stages})
+			case format == explainFormatMermaid:
+				fmt.Fprint(cmd.OutOrStdout(), pipeline.Mermaid("Release "+release.Version, stages))
+			default:
+				shared.Logger.Printf("%s Release %s %s\n", emoji.Rocket, styles.Blue(release.Version), styles.Subtle("("+release.ID+")"))
+				shared.Logger.Print(pipeline.Render(stages, colorState))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("id", "i", "", "project id of an existing project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+	cmd.Flags().StringP("version", "v", "", "version of the release to explain, defaults to the latest release")
+	cmd.Flags().StringP("format", "f", explainFormatText, "format of the pipeline: text or mermaid")
+
+	return cmd
+}
+
+// pipelineRelease collects what the pipeline needs from the details of the release
+func pipelineRelease(d *releaseDetails) *pipeline.Release {
+	r := &pipeline.Release{RevisionID: d.RevisionID, Listed: d.DiscoveryList}
+	if d.Revision != nil {
+		r.RevisionTag = d.Revision.Tag
+		r.BuildStartedAt, r.BuildFinishedAt = d.Revision.BuildStartedAt, d.Revision.BuildFinishedAt
+	}
+	if d.Promotion != nil {
+		r.PromotionStatus = d.Promotion.Status
+		if n := len(d.Promotion.Events); n > 0 {
+			r.PromotionAt = d.Promotion.Events[n-1].At
+		}
+	}
+	for _, e := range d.Edges {
+		r.Edges = append(r.Edges, pipeline.Edge{Location: e.Location, Status: e.Status})
+	}
+	return r
+}
+
+func colorState(state pipeline.State, s string) string {
+	switch state {
+	case pipeline.StateDone:
+		return styles.Green(s)
+	case pipeline.StateRunning:
+		return styles.Blue(s)
+	case pipeline.StateFailed:
+		return styles.Error(s)
+	}
+	return styles.Subtle(s)
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/changelog"
+	"github.com/deta/space/internal/editor"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/choose"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdReleaseNotes() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notes",
+		Short: "Manage the notes of existing releases",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdReleaseNotesEdit())
+
+	return cmd
+}
+
+func newCmdReleaseNotesEdit() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit [flags]",
+		Short: "Edit the notes and the listing of an existing release",
+		Long: `Edit the notes and the Discovery listing of an existing release.
+
+The current notes are opened in your editor, unless new notes are given with --notes or --notes-file. Use --listed or --listed=false to list the release on Discovery or to remove it.`,
+		Example: `  space release notes edit
+  space release notes edit --version 1.2.0 --notes "Fixes the login"
+  space release notes edit --version 1.2.0 --notes-file CHANGELOG.md
+  space release notes edit --listed=false`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "version", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			releaseVersion, _ := cmd.Flags().GetString("version")
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+
+			release, err := selectRelease(projectID, releaseVersion)
+			if err != nil {
+				return err
+			}
+
+			update := &api.UpdateReleaseRequest{ID: release.ID}
+			if cmd.Flags().Changed("notes") {
+				notes, _ := cmd.Flags().GetString("notes")
+				update.ReleaseNotes = &notes
+			} else if cmd.Flags().Changed("notes-file") {
+				notesFile, _ := cmd.Flags().GetString("notes-file")
+				notes, err := readNotesFile(notesFile, release.Version)
+				if err != nil {
+					return err
+				}
+				update.ReleaseNotes = &notes
+			} else if !cmd.Flags().Changed("listed") {
+				if !shared.IsOutputInteractive() {
+					shared.Logger.Printf("notes or listed flag must be provided in non-interactive mode")
+					return shared.ErrReported
+				}
+				notes, err := editReleaseNotes(release.Version, release.ReleaseNotes)
+				if err != nil {
+					return err
+				}
+				update.ReleaseNotes = &notes
+			}
+			if cmd.Flags().Changed("listed") {
+				listed, _ := cmd.Flags().GetBool("listed")
+				update.DiscoveryList = &listed
+			}
+
+			if err := updateRelease(release, update); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("id", "i", "", "project id of an existing project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+	cmd.Flags().StringP("version", "v", "", "version of the release to edit, defaults to the latest release")
+	cmd.Flags().StringP("notes", "n", "", "new release notes instead of opening the editor")
+	cmd.Flags().String("notes-file", "", "read the new release notes from a file, only the section of the version if it's a changelog")
+	cmd.Flags().Bool("listed", false, "list the release on discovery")
+
+	cmd.MarkFlagsMutuallyExclusive("notes", "notes-file")
+
+	return cmd
+}
+
+// maxReleaseNotesLength is the longest release notes shown on Discovery
+const maxReleaseNotesLength = 5000
+
+func validateReleaseNotes(notes string) error {
+	if n := utf8.RuneCountInString(notes); n > maxReleaseNotesLength {
+		return fmt.Errorf("the release notes are %d characters long, at most %d are allowed", n, maxReleaseNotesLength)
+	}
+	return nil
+}
+
+// readNotesFile reads the release notes of the version from a file. Of a changelog in the Keep a Changelog format
+// only the section of the version is used.
+func readNotesFile(path string, version string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		shared.Logger.Printf("%s Failed to read the release notes: %s", emoji.ErrorExclamation, err)
+		return "", err
+	}
+
+	notes := string(content)
+	if changelog.IsChangelog(notes) {
+		if version == "" {
+			shared.Logger.Printf("%s %s is a changelog, set the version of the release with %s to use its section", emoji.ErrorExclamation, path, styles.Code("--version"))
+			return "", shared.ErrReported
+		}
+		if notes, err = changelog.Section(notes, version); err != nil {
+			shared.Logger.Printf("%s The changelog %s has %s", emoji.ErrorExclamation, path, err)
+			return "", shared.ErrReported
+		}
+	}
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		shared.Logger.Printf("%s The release notes in %s are empty", emoji.ErrorExclamation, path)
+		return "", shared.ErrReported
+	}
+	if err := validateReleaseNotes(notes); err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return "", shared.ErrReported
+	}
+	return notes, nil
+}
+
+// editReleaseNotes opens the notes in the editor until they are valid, the editor is set with SPACE_EDITOR,
+// the editor of the config file, VISUAL or EDITOR
+func editReleaseNotes(version string, notes string) (string, error) {
+	release := "the release"
+	if version != "" {
+		release = "release " + version
+	}
+	help := fmt.Sprintf("Write the notes of %s in markdown, at most %d characters.", release, maxReleaseNotesLength)
+
+	edited, err := editor.EditMarkdown("release-notes", help, notes, validateReleaseNotes)
+	if err != nil {
+		if errors.Is(err, editor.ErrEmpty) {
+			shared.Logger.Printf("%s Release notes are empty, cancelled", emoji.Warning)
+			return "", err
+		}
+		shared.Logger.Println(styles.Errorf("%s Failed to edit release notes: %v", emoji.ErrorExclamation, err))
+		return "", err
+	}
+	return edited, nil
+}
+
+// releaseListLimit is the number of the latest releases to choose from
+const releaseListLimit = 10
+
+// selectRelease returns the release with the version, without a version the user chooses one of the latest releases
+// in a terminal and the latest release is used otherwise
+func selectRelease(projectID string, version string) (*api.Release, error) {
+	if version != "" {
+		release, err := shared.Client.FindRelease(projectID, version)
+		if errors.Is(err, api.ErrReleaseNotFound) {
+			shared.Logger.Println(styles.Errorf("%s No release with version %s found", emoji.ErrorExclamation, styles.Blue(version)))
+			return nil, fmt.Errorf("release %s not found", version)
+		} else if err != nil {
+			return nil, reportListReleasesError(err)
+		}
+		return release, nil
+	}
+
+	r, err := shared.Client.ListReleases(&api.ListReleasesRequest{AppID: projectID, Limit: releaseListLimit})
+	if err != nil {
+		return nil, reportListReleasesError(err)
+	}
+	if len(r.Releases) == 0 {
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, i18n.T("hint.no_releases", styles.Code("space release"))))
+		return nil, errors.New("no releases found")
+	}
+
+	if !shared.CanPrompt() {
+		return r.Releases[0], nil
+	}
+
+	versions := make([]string, 0, len(r.Releases))
+	releaseMap := make(map[string]*api.Release)
+	for _, release := range r.Releases {
+		versions = append(versions, release.Version)
+		releaseMap[release.Version] = release
+	}
+	version, err = choose.Run(
+		"release.notes.version",
+		fmt.Sprintf("Choose a release %s:", styles.Subtle("(most recent releases)")),
+		versions...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return releaseMap[version], nil
+}
+
+func reportListReleasesError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrNoAccessTokenFound):
+		shared.Logger.Println(shared.LoginInfo())
+	case errors.Is(err, api.ErrProjectNotFound):
+		shared.Logger.Println(styles.Errorf("%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
+	default:
+		shared.Logger.Println(styles.Errorf("%s Failed to list releases: %v", emoji.ErrorExclamation, err))
+	}
+	return err
+}
+
+func updateRelease(release *api.Release, update *api.UpdateReleaseRequest) error {
+	if (update.ReleaseNotes == nil || *update.ReleaseNotes == release.ReleaseNotes) &&
+		(update.DiscoveryList == nil || *update.DiscoveryList == release.DiscoveryList) {
+		shared.Logger.Printf("%s Release %s is unchanged", emoji.Check, styles.Blue(release.Version))
+		return nil
+	}
+
+	if _, err := shared.Client.UpdateRelease(update); err != nil {
+		shared.Logger.Println(shared.ErrorMessage("Failed to update release", err))
+		return err
+	}
+
+	shared.Logger.Printf("%s Updated release %s", emoji.Check, styles.Blue(release.Version))
+	if update.DiscoveryList != nil && *update.DiscoveryList != release.DiscoveryList {
+		if *update.DiscoveryList {
+			shared.Logger.Println(emoji.CrystalBall, i18n.T("release.listed"))
+		} else {
+			shared.Logger.Println(i18n.T("release.unlisted"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/choose"
+	"github.com/deta/space/pkg/components/confirm"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdReleaseRollback() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback [flags]",
+		Short: "Roll back to the revision of a previous release",
+		Long: `Roll back to a previous release by releasing its revision again as the latest release.
+
+Without --version you choose one of the previous releases, --yes rolls back to the release before the latest one. The new release gets the patch version after the latest release unless --new-version is set, since the version of the previous release exists already. It's created in the channel of the previous release unless --channel is set, its notes name the previous release and repeat its notes.
+
+A rollback is a release like any other: during a freeze window of the project config it needs --override-freeze, and if the project config requires approval it needs --approved-by.`,
+		Example: `  space release rollback
+  space release rollback --version 1.2.0
+  space release rollback --version 1.2.0 --new-version 1.3.1 --yes`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "version", "new-version", "environment"), shared.CheckOneOf("channel", api.ReleaseChannels...)),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			targetVersion, _ := cmd.Flags().GetString("version")
+			newVersion, _ := cmd.Flags().GetString("new-version")
+			channel, _ := cmd.Flags().GetString("channel")
+			notes, _ := cmd.Flags().GetString("notes")
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+			if err := shared.ConfirmProtected(cmd, projectDir, projectID, "roll back the latest release"); err != nil {
+				return err
+			}
+			overrideFreeze, _ := cmd.Flags().GetBool("override-freeze")
+			freezeOverride, err := checkReleaseFreeze(projectDir, projectID, overrideFreeze)
+			if err != nil {
+				return err
+			}
+			approvedBy, _ := cmd.Flags().GetString("approved-by")
+			if approvedBy, err = checkReleaseApproval(projectDir, projectID, approvedBy); err != nil {
+				return err
+			}
+
+			latest, target, err := selectRollbackRelease(projectID, targetVersion)
+			if err != nil {
+				return err
+			}
+
+			if newVersion == "" {
+				if newVersion, err = bumpPatch(latest.Version); err != nil {
+					shared.Logger.Println(styles.Errorf("%s Failed to bump version %s: %v, set the version of the new release with %s", emoji.ErrorExclamation, latest.Version, err, styles.Code("--new-version")))
+					return err
+				}
+			}
+			if channel == "" {
+				channel = target.Channel
+			}
+			if !cmd.Flags().Changed("notes") {
+				notes = strings.TrimSpace(fmt.Sprintf("Rollback to %s\n\n%s", target.Version, target.ReleaseNotes))
+			}
+			if freezeOverride != "" {
+				notes = strings.TrimSpace(notes + "\n\n" + freezeOverride)
+			}
+
+			ok, err := confirm.Run("release.rollback.confirm", fmt.Sprintf("Release the revision of %s as %s, replacing %s?", target.Version, newVersion, latest.Version))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				shared.Logger.Println("Rollback cancelled")
+				return shared.ErrReported
+			}
+
+			shared.Logger.Printf("%s Rolling back to %s as release %s...\n\n", emoji.Package, styles.Blue(target.Version), styles.Blue(newVersion))
+			result, err := release(projectDir, projectID, target.RevisionID, newVersion, channel, target.DiscoveryList, notes, approvedBy)
+			if err != nil {
+				if errors.Is(err, api.ErrReleaseVersionExists) {
+					shared.Logger.Println(styles.Errorf("%s Version %s already exists, choose another version with %s", emoji.ErrorExclamation, newVersion, styles.Code("--new-version")))
+				}
+				return err
+			}
+			result.FreezeOverride = freezeOverride
+			if shared.JSONOutput() {
+				return shared.PrintJSON(result)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("id", "i", "", "project id of an existing project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+	cmd.Flags().StringP("version", "v", "", "version of the previous release to roll back to, asks if not set")
+	cmd.Flags().String("new-version", "", "version of the new release, defaults to the patch version after the latest release")
+	cmd.Flags().String("channel", "", "channel of the new release: experimental or stable, defaults to the channel of the previous release")
+	cmd.Flags().StringP("notes", "n", "", `release notes, defaults to "Rollback to <version>" and the notes of the previous release`)
+	cmd.Flags().Bool("override-freeze", false, "roll back during a freeze window of the project config, the override is added to the release notes")
+	cmd.Flags().String("approved-by", "", "handle of the person who approved the rollback, required if the project config requires approval")
+
+	return cmd
+}
+
+// selectRollbackRelease returns the latest release and the previous release to roll back to, the one with the version
+// or the one the user chooses
+func selectRollbackRelease(projectID string, version string) (*api.Release, *api.Release, error) {
+	r, err := shared.Client.ListReleases(&api.ListReleasesRequest{AppID: projectID, Limit: releaseListLimit})
+	if err != nil {
+		return nil, nil, reportListReleasesError(err)
+	}
+	if len(r.Releases) == 0 {
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, i18n.T("hint.no_releases", styles.Code("space release"))))
+		return nil, nil, errors.New("no releases found")
+	}
+	latest, previous := r.Releases[0], r.Releases[1:]
+	if len(previous) == 0 {
+		shared.Logger.Println(styles.Errorf("%s %s is the only release, there is nothing to roll back to", emoji.ErrorExclamation, styles.Blue(latest.Version)))
+		return nil, nil, errors.New("no previous release")
+	}
+
+	if version != "" {
+		if version == latest.Version {
+			shared.Logger.Println(styles.Errorf("%s %s is the latest release already", emoji.ErrorExclamation, styles.Blue(version)))
+			return nil, nil, errors.New("release is the latest release")
+		}
+		target, err := selectRelease(projectID, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		return latest, target, nil
+	}
+
+	versions := make([]string, 0, len(previous))
+	releaseMap := make(map[string]*api.Release)
+	for _, release := range previous {
+		versions = append(versions, release.Version)
+		releaseMap[release.Version] = release
+	}
+	version, err = choose.Run(
+		"release.rollback.version",
+		fmt.Sprintf("Roll back %s to %s:", latest.Version, styles.Subtle("(previous releases)")),
+		versions...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return latest, releaseMap[version], nil
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdReleaseShow() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show [flags]",
+		Short: "Show the details of a release",
+		Long: `Show the details of a release: its version, channel, revision, notes and Discovery listing, the timeline of its promotion, the edges serving it and how often it's installed if that's known.
+
+Without --version you choose one of the latest releases in a terminal, the latest release is shown otherwise. With --output json the details are printed as json.`,
+		Example: `  space release show
+  space release show --version 1.2.0
+  space release show --version 1.2.0 --output json | jq .promotion.events`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "version", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			releaseVersion, _ := cmd.Flags().GetString("version")
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+			release, err := selectRelease(projectID, releaseVersion)
+			if err != nil {
+				return err
+			}
+
+			details := getReleaseDetails(projectID, release)
+			if shared.JSONOutput() {
+				return shared.PrintJSON(details)
+			}
+			showRelease(details)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("id", "i", "", "project id of an existing project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+	cmd.Flags().StringP("version", "v", "", "version of the release to show, defaults to the latest release")
+
+	return cmd
+}
+
+// releaseDetails is a release with the details fetched from other endpoints, a detail is nil if it can't be fetched
+type releaseDetails struct {
+	*api.Release
+	Revision  *api.Revision                    `json:"revision,omitempty"`
+	Promotion *api.GetReleasePromotionResponse `json:"promotion,omitempty"`
+	// Edges serve the release, they are only known for the latest release
+	Edges []*api.Edge `json:"edges,omitempty"`
+}
+
+func getReleaseDetails(projectID string, release *api.Release) *releaseDetails {
+	details := &releaseDetails{Release: release}
+	if release.RevisionID != "" {
+		if r, err := shared.Client.GetRevision(&api.GetRevisionRequest{AppID: projectID, ID: release.RevisionID}); err == nil {
+			details.Revision = r
+		}
+	}
+	// the id of the promotion of a release is the id of the release
+	if p, err := shared.Client.GetReleasePromotion(&api.GetReleasePromotionRequest{PromotionID: release.ID}); err == nil {
+		details.Promotion = p
+	}
+	if e, err := shared.Client.GetEdges(&api.GetEdgesRequest{AppID: projectID}); err == nil && e.ReleaseID == release.ID {
+		details.Edges = e.Edges
+	}
+	return details
+}
+
+func showRelease(d *releaseDetails) {
+	shared.Logger.Printf("%s Release %s %s\n", emoji.Rocket, styles.Blue(d.Version), styles.Subtle("("+d.ID+")"))
+	shared.Logger.Printf("  Channel: %s", d.Channel)
+	shared.Logger.Printf("  Status: %s", d.Status)
+	shared.Logger.Printf("  Created at: %s", d.CreatedAt)
+
+	switch {
+	case d.Revision != nil && d.Revision.Tag != "":
+		shared.Logger.Printf("  Revision: %s %s", d.Revision.Tag, styles.Subtle("("+d.Revision.ID+")"))
+	case d.RevisionID != "":
+		shared.Logger.Printf("  Revision: %s", d.RevisionID)
+	default:
+		shared.Logger.Printf("  Revision: %s", styles.Subtle("unknown"))
+	}
+
+	listed := "no"
+	if d.DiscoveryList {
+		listed = "yes"
+	}
+	shared.Logger.Printf("  Listed on Discovery: %s", listed)
+	if d.Installs != nil {
+		shared.Logger.Printf("  Installs: %d", *d.Installs)
+	}
+
+	switch {
+	case d.Promotion == nil:
+		shared.Logger.Printf("  Promotion: %s", styles.Subtle("unknown"))
+	case len(d.Promotion.Events) == 0:
+		shared.Logger.Printf("  Promotion: %s", d.Promotion.Status)
+	default:
+		shared.Logger.Printf("  Promotion:")
+		for _, e := range d.Promotion.Events {
+			shared.Logger.Printf("    %s %s", styles.Subtle(e.At), e.Status)
+		}
+	}
+
+	if len(d.Edges) == 0 {
+		shared.Logger.Printf("  Edges: %s", styles.Subtle("unknown, edges are only known for the latest release"))
+	} else {
+		shared.Logger.Printf("  Edges:")
+		for _, e := range d.Edges {
+			shared.Logger.Printf("    %s %s", e.Location, styles.Subtle("("+e.Region+", "+e.Status+")"))
+		}
+	}
+
+	notes := strings.TrimSpace(d.ReleaseNotes)
+	if notes == "" {
+		shared.Logger.Printf("  Notes: %s", styles.Subtle("none"))
+		return
+	}
+	shared.Logger.Printf("  Notes:")
+	for _, line := range strings.Split(notes, "\n") {
+		shared.Logger.Printf("    %s", line)
+	}
+}
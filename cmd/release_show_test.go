@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"gotest.tools/v3/assert"
+)
+
+// captureLogs returns what f logs
+func captureLogs(t *testing.T, f func()) string {
+	var buf bytes.Buffer
+	previous := shared.Logger.Writer()
+	shared.Logger.SetOutput(&buf)
+	defer shared.Logger.SetOutput(previous)
+	f()
+	return buf.String()
+}
+
+// useServer points shared.Client to a test server of the Space API
+func useServer(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+
+	previous := shared.Client
+	shared.Client = api.NewDetaClient("test", "test")
+	shared.Client.Retries = 0
+	api.SetSpaceRoot(server.URL)
+	t.Cleanup(func() {
+		shared.Client = previous
+		api.SetSpaceRoot(api.DefaultSpaceRoot)
+	})
+}
+
+func TestGetReleaseDetails(t *testing.T) {
+	cases := []struct {
+		name      string
+		responses map[string]any
+		expected  *releaseDetails
+	}{
+		{
+			name: "all details",
+			responses: map[string]any{
+				"/v0/apps/p1/revisions/r1": &api.Revision{ID: "r1", Tag: "v1"},
+				"/v0/promotions/rel1":      &api.GetReleasePromotionResponse{ID: "rel1", Status: "complete"},
+				"/v0/apps/p1/edges":        &api.GetEdgesResponse{ReleaseID: "rel1", Edges: []*api.Edge{{Region: "eu", Location: "Frankfurt", Status: "ok"}}},
+			},
+			expected: &releaseDetails{
+				Revision:  &api.Revision{ID: "r1", Tag: "v1"},
+				Promotion: &api.GetReleasePromotionResponse{ID: "rel1", Status: "complete"},
+				Edges:     []*api.Edge{{Region: "eu", Location: "Frankfurt", Status: "ok"}},
+			},
+		},
+		{
+			name: "edges of another release",
+			responses: map[string]any{
+				"/v0/apps/p1/revisions/r1": &api.Revision{ID: "r1", Tag: "v1"},
+				"/v0/apps/p1/edges":        &api.GetEdgesResponse{ReleaseID: "rel2", Edges: []*api.Edge{{Region: "eu"}}},
+			},
+			expected: &releaseDetails{
+				Revision: &api.Revision{ID: "r1", Tag: "v1"},
+			},
+		},
+		{
+			name:      "no details",
+			responses: map[string]any{},
+			expected:  &releaseDetails{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			useServer(t, func(w http.ResponseWriter, r *http.Request) {
+				response, ok := c.responses[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				json.NewEncoder(w).Encode(response)
+			})
+
+			release := &api.Release{ID: "rel1", RevisionID: "r1", Version: "1.0.0"}
+			details := getReleaseDetails("p1", release)
+			c.expected.Release = release
+			assert.DeepEqual(t, details, c.expected)
+		})
+	}
+}
+
+func TestShowRelease(t *testing.T) {
+	installs := 42
+	details := &releaseDetails{
+		Release: &api.Release{
+			ID:            "rel1",
+			Version:       "1.2.0",
+			Channel:       "stable",
+			Status:        "complete",
+			CreatedAt:     "2024-05-02T00:00:00Z",
+			RevisionID:    "r1",
+			DiscoveryList: true,
+			Installs:      &installs,
+			ReleaseNotes:  "Fixed the login\nFaster uploads",
+		},
+		Revision: &api.Revision{ID: "r1", Tag: "v1.2.0"},
+		Promotion: &api.GetReleasePromotionResponse{Status: "complete", Events: []*api.PromotionEvent{
+			{Status: "started", At: "2024-05-02T00:00:00Z"},
+			{Status: "complete", At: "2024-05-02T00:01:00Z"},
+		}},
+	}
+
+	logged := captureLogs(t, func() { showRelease(details) })
+	for _, expected := range []string{
+		"Release 1.2.0",
+		"Channel: stable",
+		"Revision: v1.2.0",
+		"Listed on Discovery: yes",
+		"Installs: 42",
+		"2024-05-02T00:01:00Z complete",
+		"Edges: unknown",
+		"    Fixed the login\n    Faster uploads",
+	} {
+		assert.Assert(t, strings.Contains(logged, expected), "%q not in %q", expected, logged)
+	}
+
+	logged = captureLogs(t, func() {
+		showRelease(&releaseDetails{Release: &api.Release{ID: "rel2", Version: "1.3.0", RevisionID: "r2"}})
+	})
+	for _, expected := range []string{"Revision: r2", "Listed on Discovery: no", "Promotion: unknown", "Notes: none"} {
+		assert.Assert(t, strings.Contains(logged, expected), "%q not in %q", expected, logged)
+	}
+	assert.Assert(t, !strings.Contains(logged, "Installs"))
+}
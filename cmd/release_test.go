@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/semver"
+	"gotest.tools/v3/assert"
+)
+
+func TestBumpVersion(t *testing.T) {
+	cases := []struct {
+		version  string
+		bump     semver.Bump
+		expected string
+		err      bool
+	}{
+		{version: "1.4.2", bump: semver.BumpPatch, expected: "1.4.3"},
+		{version: "1.4.2", bump: semver.BumpMinor, expected: "1.5.0"},
+		{version: "1.4.2", bump: semver.BumpMajor, expected: "2.0.0"},
+		{version: "v1.4.2", bump: semver.BumpMinor, expected: "v1.5.0"},
+		{version: "0.0.0", bump: semver.BumpPatch, expected: "0.0.1"},
+		{version: "latest", bump: semver.BumpPatch, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.version+" "+c.expected, func(t *testing.T) {
+			version, err := bumpVersion(c.version, c.bump)
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, version, c.expected)
+		})
+	}
+}
+
+func TestBumpLatestRelease(t *testing.T) {
+	cases := []struct {
+		name     string
+		releases []*api.Release
+		bump     semver.Bump
+		expected string
+		err      error
+	}{
+		{name: "no releases", bump: semver.BumpMinor, expected: "0.1.0"},
+		{
+			name:     "latest of any channel",
+			releases: []*api.Release{{Version: "v1.3.0", Channel: "experimental"}, {Version: "v1.2.0", Channel: "stable"}},
+			bump:     semver.BumpPatch,
+			expected: "v1.3.1",
+		},
+		{
+			name:     "major",
+			releases: []*api.Release{{Version: "1.3.0"}},
+			bump:     semver.BumpMajor,
+			expected: "2.0.0",
+		},
+		{
+			name:     "not semantic",
+			releases: []*api.Release{{Version: "beta"}, {Version: "1.0.0"}},
+			bump:     semver.BumpPatch,
+			err:      shared.ErrReported,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			useServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v0/apps/p1/releases" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]any{"releases": c.releases})
+			})
+
+			var version string
+			var err error
+			captureLogs(t, func() { version, err = bumpLatestRelease("p1", c.bump) })
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, version, c.expected)
+		})
+	}
+}
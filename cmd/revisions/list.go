@@ -0,0 +1,149 @@
+package revisions
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/labels"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdRevisionsList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List the revisions of your project",
+		Long: `List the revisions of your project, the latest revision first.
+
+--tag only lists the revisions with a matching tag, it's a glob like v1.*. If there are more revisions than --limit, the command to list the next page with --page is shown. With --output json the revisions are printed as json with the cursor of the next page.`,
+		Example: `  space revisions list
+  space revisions list --limit 50 --tag "v1.*"
+  space revisions list --output json | jq -r '.revisions[].id'`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment", "tag", "page")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			limit, _ := cmd.Flags().GetInt("limit")
+			tag, _ := cmd.Flags().GetString("tag")
+			page, _ := cmd.Flags().GetString("page")
+
+			if limit <= 0 {
+				shared.Logger.Println(styles.Errorf("%s %s must be positive", emoji.ErrorExclamation, styles.Code("--limit")))
+				return errors.New("limit must be positive")
+			}
+			if _, err := path.Match(tag, ""); err != nil {
+				shared.Logger.Println(styles.Errorf("%s Invalid tag pattern %q, use a glob like %s", emoji.ErrorExclamation, tag, styles.Code("v1.*")))
+				return fmt.Errorf("invalid tag pattern %q: %w", tag, err)
+			}
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+
+			res, err := listRevisions(projectID, limit, tag, page)
+			if err != nil {
+				switch {
+				case errors.Is(err, auth.ErrNoAccessTokenFound):
+					shared.Logger.Println(shared.LoginInfo())
+				case errors.Is(err, api.ErrProjectNotFound):
+					shared.Logger.Println(styles.Errorf("%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
+				default:
+					shared.Logger.Println(styles.Errorf("%s Failed to list revisions: %v", emoji.ErrorExclamation, err))
+				}
+				return err
+			}
+
+			if shared.JSONOutput() {
+				return shared.PrintJSON(res)
+			}
+			printRevisions(res, cmd)
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+	cmd.Flags().Int("limit", 20, "number of revisions to list")
+	cmd.Flags().String("tag", "", "only list the revisions with a tag matching the glob, e.g. v1.*")
+	cmd.Flags().String("page", "", "cursor of the page to list, shown after a page with more revisions")
+
+	return cmd
+}
+
+// listRevisions fetches pages until it has limit revisions with a tag matching the pattern or there are no more pages,
+// a page is never larger than the revisions still missing, so the cursor of the last page continues after the last
+// listed revision
+func listRevisions(projectID string, limit int, tag string, page string) (*api.ListRevisionsResponse, error) {
+	res := &api.ListRevisionsResponse{Revisions: []*api.Revision{}, NextPage: page}
+	for len(res.Revisions) < limit {
+		p, err := shared.Client.ListRevisions(&api.ListRevisionsRequest{AppID: projectID, Limit: limit - len(res.Revisions), Last: res.NextPage})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range p.Revisions {
+			if tag == "" || matchTag(tag, r.Tag) {
+				res.Revisions = append(res.Revisions, r)
+			}
+		}
+		res.NextPage = p.NextPage
+		if p.NextPage == "" || len(p.Revisions) == 0 {
+			res.NextPage = ""
+			break
+		}
+	}
+	return res, nil
+}
+
+// matchTag matches a tag with a glob, revisions without a tag never match
+func matchTag(pattern string, tag string) bool {
+	ok, _ := path.Match(pattern, tag)
+	return tag != "" && ok
+}
+
+func printRevisions(res *api.ListRevisionsResponse, cmd *cobra.Command) {
+	if len(res.Revisions) == 0 {
+		shared.Logger.Printf("No revisions found, create one with %s", styles.Code("space push"))
+		return
+	}
+
+	width := 0
+	for _, r := range res.Revisions {
+		if len(r.Tag) > width {
+			width = len(r.Tag)
+		}
+	}
+	for _, r := range res.Revisions {
+		line := fmt.Sprintf("%-*s  %s  %s", width, r.Tag, styles.Code(r.ID), styles.Subtle(r.CreatedAt))
+		if sha := r.Labels[labels.GitSHA]; sha != "" {
+			line += "  " + styles.Subtle(labels.GitSHA+"="+shortSHA(sha))
+		}
+		shared.Logger.Println(strings.TrimRight(line, " "))
+	}
+
+	if res.NextPage != "" {
+		next := fmt.Sprintf("space revisions list --page %s", res.NextPage)
+		if limit, _ := cmd.Flags().GetInt("limit"); cmd.Flags().Changed("limit") {
+			next += fmt.Sprintf(" --limit %d", limit)
+		}
+		if tag, _ := cmd.Flags().GetString("tag"); tag != "" {
+			next += fmt.Sprintf(" --tag %q", tag)
+		}
+		shared.Logger.Printf("\nMore revisions: %s", styles.Code(next))
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
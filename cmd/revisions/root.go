@@ -0,0 +1,30 @@
+package revisions
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdRevisions() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "revisions",
+		Aliases: []string{"revision"},
+		Short:   "Inspect the revisions of your project",
+		Long: `Inspect the revisions of your project.
+
+Every push creates a revision. Its labels trace it back to the commit, the CI run and who pushed it, add your own with space push --label.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdRevisionsList())
+	cmd.AddCommand(newCmdRevisionsShow())
+
+	return cmd
+}
+
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("dir", "d", "./", "src of the project")
+	cmd.Flags().StringP("id", "i", "", "project id of the project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+}
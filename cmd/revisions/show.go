@@ -0,0 +1,157 @@
+package revisions
+
+import (
+	"errors"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/labels"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/util/fs"
+	"github.com/spf13/cobra"
+)
+
+func newCmdRevisionsShow() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <revision> [flags]",
+		Short: "Show the details of a revision",
+		Long: `Show the details of a revision: its tag, labels, the digest, number and size of the pushed files, how long the build took, the engines of the micros and the releases created from it.
+
+The revision is its id or the tag of one of the latest revisions. The releases are looked up in the latest releases of the project. With --output json the details are printed as json.`,
+		Example: `  space revisions show 2f3a9c1d
+  space revisions show v1.2.0 --environment production
+  space revisions show v1.2.0 --output json | jq .labels`,
+		Args:     cobra.ExactArgs(1),
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+			revision, err := findRevision(projectID, args[0])
+			if err != nil {
+				return err
+			}
+			// the releases are optional details, a revision is shown without them if they can't be fetched
+			var releases []*api.Release
+			if r, err := shared.Client.ListReleases(&api.ListReleasesRequest{AppID: projectID}); err == nil {
+				for _, release := range r.Releases {
+					if release.RevisionID == revision.ID {
+						releases = append(releases, release)
+					}
+				}
+			}
+
+			if shared.JSONOutput() {
+				return shared.PrintJSON(&revisionDetails{Revision: revision, Releases: releases})
+			}
+			showRevision(revision, releases)
+			return nil
+		},
+	}
+
+	addTargetFlags(cmd)
+
+	return cmd
+}
+
+// revisionDetails is the json of a revision
+type revisionDetails struct {
+	*api.Revision
+	Releases []*api.Release `json:"releases"`
+}
+
+// findRevision returns the revision with the id or the tag, tags are looked up in the latest revisions
+func findRevision(projectID string, idOrTag string) (*api.Revision, error) {
+	revision, err := shared.Client.GetRevision(&api.GetRevisionRequest{AppID: projectID, ID: idOrTag})
+	if errors.Is(err, api.ErrRevisionNotFound) {
+		if r, listErr := shared.GetRevisions(projectID, true); listErr == nil {
+			for _, candidate := range r.Revisions {
+				if candidate.Tag == idOrTag {
+					revision, err = shared.Client.GetRevision(&api.GetRevisionRequest{AppID: projectID, ID: candidate.ID})
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoAccessTokenFound):
+			shared.Logger.Println(shared.LoginInfo())
+		case errors.Is(err, api.ErrRevisionNotFound):
+			shared.Logger.Println(styles.Errorf("%s No revision %s found, use the id of a revision or the tag of one of the latest revisions", emoji.ErrorExclamation, idOrTag))
+		default:
+			shared.Logger.Println(styles.Errorf("%s Failed to get revision: %v", emoji.ErrorExclamation, err))
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+func showRevision(revision *api.Revision, releases []*api.Release) {
+	title := revision.ID
+	if revision.Tag != "" {
+		title = revision.Tag
+	}
+	shared.Logger.Printf("%s %s %s\n", emoji.Package, styles.Bold(title), styles.Subtle("("+revision.ID+")"))
+	shared.Logger.Printf("  Created at: %s", revision.CreatedAt)
+	if revision.Digest != "" {
+		shared.Logger.Printf("  Digest: %s", revision.Digest)
+	}
+	if revision.FileCount > 0 {
+		shared.Logger.Printf("  Files: %d (%s)", revision.FileCount, fs.FormatSize(revision.Size))
+	}
+	if duration, ok := buildDuration(revision); ok {
+		shared.Logger.Printf("  Build duration: %s", duration)
+	}
+
+	if len(revision.Engines) > 0 {
+		shared.Logger.Printf("  Engines:")
+		for _, e := range revision.Engines {
+			engine := e.Engine
+			if e.Version != "" {
+				engine += " " + e.Version
+			}
+			shared.Logger.Printf("    %s: %s", e.Micro, engine)
+		}
+	}
+
+	if len(revision.Labels) == 0 {
+		shared.Logger.Printf("  Labels: %s", styles.Subtle("none"))
+	} else {
+		shared.Logger.Printf("  Labels:")
+		for _, key := range labels.Keys(revision.Labels) {
+			shared.Logger.Printf("    %s=%s", key, revision.Labels[key])
+		}
+	}
+
+	if len(releases) == 0 {
+		shared.Logger.Printf("  Releases: %s", styles.Subtle("none of the latest releases"))
+		return
+	}
+	shared.Logger.Printf("  Releases:")
+	for _, r := range releases {
+		shared.Logger.Printf("    %s %s", styles.Blue(r.Version), styles.Subtle("("+r.Channel+", "+r.Status+", "+r.CreatedAt+")"))
+	}
+}
+
+// buildDuration returns how long the build of the revision took, ok is false if it's unknown
+func buildDuration(revision *api.Revision) (time.Duration, bool) {
+	started, err := time.Parse(time.RFC3339, revision.BuildStartedAt)
+	if err != nil {
+		return 0, false
+	}
+	finished, err := time.Parse(time.RFC3339, revision.BuildFinishedAt)
+	if err != nil || finished.Before(started) {
+		return 0, false
+	}
+	return finished.Sub(started).Round(time.Second), true
+}
@@ -0,0 +1,112 @@
+package revisions
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"gotest.tools/v3/assert"
+)
+
+// captureLogs returns what f logs
+func captureLogs(t *testing.T, f func()) string {
+	var buf bytes.Buffer
+	previous := shared.Logger.Writer()
+	shared.Logger.SetOutput(&buf)
+	defer shared.Logger.SetOutput(previous)
+	f()
+	return buf.String()
+}
+
+func TestShowRevision(t *testing.T) {
+	revision := &api.Revision{
+		ID:              "r1",
+		Tag:             "v1.2.0",
+		CreatedAt:       "2024-05-01T10:00:00Z",
+		Labels:          map[string]string{"pushed_by": "Jane Doe", "git_sha": "abc123", "ci_run_url": "https://ci/1"},
+		Digest:          "sha256:def",
+		FileCount:       3,
+		Size:            2048,
+		BuildStartedAt:  "2024-05-01T10:00:00Z",
+		BuildFinishedAt: "2024-05-01T10:01:30Z",
+		Engines:         []*api.MicroEngine{{Micro: "api", Engine: "python3.9"}},
+	}
+	releases := []*api.Release{{Version: "1.2.0", Channel: "stable", Status: "complete", CreatedAt: "2024-05-02T00:00:00Z"}}
+
+	cases := []struct {
+		name     string
+		revision *api.Revision
+		releases []*api.Release
+		expected []string
+	}{
+		{
+			name:     "details",
+			revision: revision,
+			releases: releases,
+			expected: []string{"v1.2.0", "(r1)", "Digest: sha256:def", "Files: 3", "Build duration: 1m30s", "api: python3.9", "1.2.0"},
+		},
+		{
+			name:     "without labels and releases",
+			revision: &api.Revision{ID: "r2", CreatedAt: "2024-05-01T10:00:00Z"},
+			expected: []string{"r2", "Labels: none", "Releases: none of the latest releases"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := captureLogs(t, func() { showRevision(c.revision, c.releases) })
+			for _, expected := range c.expected {
+				assert.Assert(t, strings.Contains(out, expected), "%q not in %s", expected, out)
+			}
+		})
+	}
+
+	t.Run("labels are sorted", func(t *testing.T) {
+		out := captureLogs(t, func() { showRevision(revision, nil) })
+		ci := strings.Index(out, "ci_run_url=https://ci/1")
+		sha := strings.Index(out, "git_sha=abc123")
+		pushed := strings.Index(out, "pushed_by=Jane Doe")
+		assert.Assert(t, ci >= 0 && ci < sha && sha < pushed, out)
+	})
+}
+
+func TestBuildDuration(t *testing.T) {
+	cases := []struct {
+		name     string
+		started  string
+		finished string
+		duration time.Duration
+		ok       bool
+	}{
+		{name: "finished", started: "2024-05-01T10:00:00Z", finished: "2024-05-01T10:00:42Z", duration: 42 * time.Second, ok: true},
+		{name: "running", started: "2024-05-01T10:00:00Z", ok: false},
+		{name: "finished before started", started: "2024-05-01T10:00:00Z", finished: "2024-05-01T09:00:00Z", ok: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			duration, ok := buildDuration(&api.Revision{BuildStartedAt: c.started, BuildFinishedAt: c.finished})
+			assert.Equal(t, ok, c.ok)
+			assert.Equal(t, duration, c.duration)
+		})
+	}
+}
+
+func TestRevisionDetailsJSON(t *testing.T) {
+	details := &revisionDetails{
+		Revision: &api.Revision{ID: "r1", Tag: "v1", Labels: map[string]string{"git_sha": "abc123"}},
+		Releases: []*api.Release{{Version: "1.0.0"}},
+	}
+	content, err := json.Marshal(details)
+	assert.NilError(t, err)
+
+	var decoded map[string]any
+	assert.NilError(t, json.Unmarshal(content, &decoded))
+	assert.Equal(t, decoded["id"], "r1")
+	assert.DeepEqual(t, decoded["labels"], map[string]any{"git_sha": "abc123"})
+	assert.Equal(t, len(decoded["releases"].([]any)), 1)
+}
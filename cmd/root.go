@@ -3,36 +3,205 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/deta/space/cmd/auth"
+	"github.com/deta/space/cmd/cache"
+	"github.com/deta/space/cmd/ci"
+	"github.com/deta/space/cmd/completion"
+	"github.com/deta/space/cmd/cron"
+	"github.com/deta/space/cmd/deps"
 	"github.com/deta/space/cmd/dev"
+	"github.com/deta/space/cmd/discovery"
+	"github.com/deta/space/cmd/drive"
+	"github.com/deta/space/cmd/env"
+	"github.com/deta/space/cmd/flags"
+	"github.com/deta/space/cmd/maintenance"
+	"github.com/deta/space/cmd/man"
+	"github.com/deta/space/cmd/migrate"
+	"github.com/deta/space/cmd/project"
+	"github.com/deta/space/cmd/regions"
+	"github.com/deta/space/cmd/registry"
+	"github.com/deta/space/cmd/revisions"
 	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/cmd/state"
+	"github.com/deta/space/cmd/support"
 	"github.com/deta/space/cmd/version"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/internal/fuzzy"
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/internal/home"
+	"github.com/deta/space/internal/profile"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
 	"github.com/spf13/cobra"
 )
 
 func NewSpaceCmd() *cobra.Command {
+	crypt.SetPassphrasePrompt(shared.PromptStatePassphrase)
+	if version.UpdateSupported() {
+		shared.SetUpdater(version.Update)
+	}
+
 	cmd := &cobra.Command{
 		Use:   "space",
 		Short: "Deta Space CLI",
 		Long: fmt.Sprintf(`Deta Space command line interface for managing Deta Space projects.
 
-Complete documentation available at %s`, shared.DocsUrl),
+Global state like your access token is stored in ~/.detaspace, set %s to use another directory. Defaults like the output format are set with space config.
+
+Complete documentation available at %s`, home.HomeEnv, shared.DocsUrl),
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Usage()
 		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// flags override the config file, which overrides the defaults
+			c, err := config.Load()
+			if err != nil {
+				// a broken config file mustn't block space config from fixing it
+				shared.Logger.Printf("%s Ignoring the config file: %v", emoji.Warning, err)
+				c = &config.Config{}
+			}
+			// the accessibility mode is turned on first, so that all output is plain text
+			accessible, _ := cmd.Flags().GetBool("accessible")
+			if !cmd.Flags().Changed("accessible") {
+				accessible = c.AccessibleMode()
+			}
+			styles.SetAccessible(accessible)
+			noColor, _ := cmd.Flags().GetBool("no-color")
+			if !cmd.Flags().Changed("no-color") {
+				noColor = c.NoColorMode()
+			}
+			styles.SetNoColor(noColor)
+			if err := shared.ApplyOutput(cmd, c); err != nil {
+				return err
+			}
+			if c.APIEndpoint != "" {
+				api.SetSpaceRoot(c.APIEndpoint)
+			}
+			if err := shared.LoadAnswers(cmd); err != nil {
+				shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+				return err
+			}
+
+			shared.ApplyDebug(cmd)
+
+			noState, _ := cmd.Flags().GetBool("no-state")
+			home.SetNoState(noState)
+			shared.KeepFailedRequests()
+			if err := shared.ApplyRetries(); err != nil {
+				shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+				return err
+			}
+			if forced, _ := cmd.Flags().GetBool("force-ipv4"); forced {
+				api.SetForceIPv4(true)
+			}
+			noCache, _ := cmd.Flags().GetBool("no-cache")
+			runtime.SetCacheDisabled(noCache)
+			if cmd.Flags().Changed("gha") {
+				enabled, _ := cmd.Flags().GetBool("gha")
+				gha.SetEnabled(enabled)
+			}
+			shared.StartVersionCheck(cmd)
+			shared.StartTokenCheck(cmd)
+
+			if addr, _ := cmd.Flags().GetString("pprof"); addr != "" {
+				listening, err := profile.ServePprof(addr)
+				if err != nil {
+					shared.Logger.Printf("%s %s", emoji.Warning, err)
+				} else {
+					shared.Logger.Printf("%s Serving pprof on %s", emoji.Gear, styles.Codef("http://%s/debug/pprof/", listening))
+				}
+			}
+			if enabled, _ := cmd.Flags().GetBool("profile"); enabled {
+				profile.Enable()
+			}
+			return nil
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			shared.WarnBroadToken(cmd)
+		},
 		DisableAutoGenTag: true,
 		Version:           shared.SpaceVersion,
 	}
 
+	cmd.PersistentFlags().Bool("no-state", false, fmt.Sprintf("don't write any state outside of the project directory, also enabled by %s", home.NoStateEnv))
+	cmd.PersistentFlags().Bool("no-cache", false, "don't use cached API responses")
+	cmd.PersistentFlags().Bool("skip-version-check", false, fmt.Sprintf("don't check for a new version of the CLI, set version_check_interval in %s or %s to change how often it's checked", config.FileName, config.VersionCheckIntervalEnv))
+	cmd.PersistentFlags().Bool("force-ipv4", false, fmt.Sprintf("connect over IPv4 only, for networks with broken IPv6, also enabled by %s", api.ForceIPv4Env))
+	cmd.PersistentFlags().Bool("debug", false, fmt.Sprintf("log every API request with its status, timing and request id on stderr, also enabled by %s", shared.DebugEnv))
+	cmd.PersistentFlags().Bool("profile", false, "print where the time of the command was spent, e.g. in API calls, archiving, uploads or builds")
+	cmd.PersistentFlags().String("pprof", "", "serve the pprof endpoints on this loopback address while the command runs, e.g. localhost:6060 or :6060")
+	cmd.PersistentFlags().Bool("accessible", false, fmt.Sprintf("plain text output and line by line prompts for screen readers, without emoji, colors or redrawing, also enabled by %s", config.AccessibleEnv))
+	cmd.PersistentFlags().Bool("no-color", false, fmt.Sprintf("don't color the output, also enabled by %s or no_color in %s", config.NoColorEnv, config.FileName))
+	shared.AddAnswersFlags(cmd)
+	shared.AddOutputFlag(cmd)
+	shared.AddOverrideProtectionFlag(cmd)
+	cmd.PersistentFlags().Bool("gha", false, fmt.Sprintf("write GitHub Actions annotations and log groups, enabled by default if %s is set", gha.Env))
+
 	cmd.AddCommand(newCmdLogin())
 	cmd.AddCommand(newCmdLink())
 	cmd.AddCommand(newCmdPush())
+	cmd.AddCommand(newCmdPack())
 	cmd.AddCommand(newCmdExec())
 	cmd.AddCommand(dev.NewCmdDev())
 	cmd.AddCommand(newCmdNew())
+	cmd.AddCommand(newCmdLearn())
+	cmd.AddCommand(newCmdUI())
 	cmd.AddCommand(version.NewCmdVersion(shared.SpaceVersion, shared.Platform))
+	cmd.AddCommand(version.NewCmdUpdate(shared.SpaceVersion))
 	cmd.AddCommand(newCmdOpen())
+	cmd.AddCommand(newCmdLogs())
 	cmd.AddCommand(newCmdValidate())
 	cmd.AddCommand(newCmdRelease())
+	cmd.AddCommand(drive.NewCmdDrive())
+	cmd.AddCommand(cron.NewCmdCron())
+	cmd.AddCommand(newCmdExport())
+	cmd.AddCommand(newCmdImport())
+	cmd.AddCommand(project.NewCmdProject())
+	cmd.AddCommand(regions.NewCmdRegions())
+	cmd.AddCommand(newCmdPing())
+	cmd.AddCommand(state.NewCmdState())
+	cmd.AddCommand(ci.NewCmdCI())
+	cmd.AddCommand(newCmdPreview())
+	cmd.AddCommand(newCmdTest())
+	cmd.AddCommand(newCmdVendor())
+	cmd.AddCommand(migrate.NewCmdMigrate())
+	cmd.AddCommand(cache.NewCmdCache())
+	cmd.AddCommand(newCmdConfig())
+	cmd.AddCommand(discovery.NewCmdDiscovery())
+	cmd.AddCommand(flags.NewCmdFlags())
+	cmd.AddCommand(maintenance.NewCmdMaintenance())
+	cmd.AddCommand(env.NewCmdEnv())
+	cmd.AddCommand(auth.NewCmdAuth())
+	cmd.AddCommand(newCmdStatus())
+	cmd.AddCommand(newCmdCalendar())
+	cmd.AddCommand(newCmdGraph())
+	cmd.AddCommand(newCmdOpenAPI())
+	cmd.AddCommand(newCmdGenerate())
+	cmd.AddCommand(newCmdRoutes())
+	cmd.AddCommand(newCmdQuota())
+	cmd.AddCommand(support.NewCmdSupport())
+	cmd.AddCommand(revisions.NewCmdRevisions())
+	cmd.AddCommand(registry.NewCmdRegistry())
+	cmd.AddCommand(deps.NewCmdDeps())
+
+	cmd.AddCommand(man.NewCmdMan())
+
+	addHelpAll(cmd)
+
+	// the completion command of cobra is created early to add the install command to it
+	cmd.InitDefaultCompletionCmd()
+	if completionCmd, _, err := cmd.Find([]string{"completion"}); err == nil {
+		completionCmd.AddCommand(completion.NewCmdInstall())
+	}
+
+	fuzzy.EnableSuggestions(cmd)
+	shared.RegisterDynamicCompletions(cmd)
+	shared.SilenceReportedErrors(cmd)
 
 	return cmd
 }
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/routes"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdRoutes() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "Inspect how requests are routed to your micros",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdRoutesSimulate())
+
+	return cmd
+}
+
+func newCmdRoutesSimulate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate <path>",
+		Short: "Show which micro handles a request and which rules apply",
+		Long: `Show how a GET request of the path is handled and why: the micro it's routed to, if it's public or for signed in users only, and the redirects, headers and single page app fallback of the static config of the micro in the Spacefile.
+
+Requests are routed like by the proxy of space dev, to the micro served on the first segment of the path or else to the primary micro. Problems with the routes of the Spacefile, like redirects which never apply, are listed first.`,
+		Example: `  space routes simulate /api/users/42
+  space routes simulate /blog/hello --output json`,
+		Args:     cobra.ExactArgs(1),
+		PreRunE:  shared.CheckExists("dir"),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			return simulateRoute(projectDir, args[0])
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+
+	return cmd
+}
+
+func simulateRoute(projectDir string, path string) error {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
+
+	problems := routes.Check(s.Micros)
+	for _, problem := range problems {
+		shared.Logger.Printf("%s %s", emoji.Warning, problem)
+	}
+	if len(problems) > 0 {
+		shared.Logger.Println()
+	}
+
+	simulation := routes.Simulate(projectDir, s.Micros, path)
+	if shared.JSONOutput() {
+		return shared.PrintJSON(simulation)
+	}
+
+	shared.Logger.Printf("GET %s\n\n", styles.Bold(simulation.Path))
+	for _, step := range simulation.Steps {
+		shared.Logger.Printf("%s %s", styles.Blue(fmt.Sprintf("%-8s", step.Kind)), step.Result)
+		shared.Logger.Printf("L %s", step.Reason)
+	}
+	return nil
+}
+
+// warnRouteProblems warns about routes of the Spacefile which behave differently than declared
+func warnRouteProblems(s *spacefile.Spacefile) {
+	for _, problem := range routes.Check(s.Micros) {
+		shared.Logger.Printf("\n%s %s", emoji.Warning, problem)
+	}
+}
@@ -0,0 +1,60 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// AddAnswersFlags adds the flags to answer the prompts of all commands without a terminal
+func AddAnswersFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolP("yes", "y", false, "don't prompt, accept every confirmation and choose the default of every choice, answers of --answer and --answers take precedence")
+	cmd.PersistentFlags().String("answers", "", "answer the prompts from a yaml file of prompt keys and answers, - reads it from stdin")
+	cmd.PersistentFlags().StringArray("answer", nil, "answer a prompt, e.g. --answer new.name=my-app, can be repeated")
+}
+
+// LoadAnswers loads the answers of the flags, prompts are answered from them instead of the terminal if any of the
+// flags is set
+func LoadAnswers(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("answers")
+	values, _ := cmd.Flags().GetStringArray("answer")
+	yes, _ := cmd.Flags().GetBool("yes")
+	if path == "" && len(values) == 0 && !yes {
+		return nil
+	}
+
+	answers.Std().AssumeDefaults(yes)
+
+	if path != "" {
+		if err := answers.LoadFile(path); err != nil {
+			return err
+		}
+	}
+	for _, value := range values {
+		key, answer, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("answer %q must be of the form key=value", value)
+		}
+		answers.Std().Set(key, answer)
+	}
+	return nil
+}
+
+// RequireAnswers checks that the prompts of a flow are answered if the answers are given with flags, so that a command
+// fails before changing anything and lists all missing answers at once
+func RequireAnswers(keys ...string) error {
+	if !answers.Enabled() {
+		return nil
+	}
+	err := answers.Std().Missing(keys...)
+	var missing *answers.MissingError
+	if errors.As(err, &missing) {
+		Logger.Println(styles.Errorf("%s Missing answers for %s, add them to the answers file or pass them with %s", emoji.ErrorExclamation, strings.Join(missing.Keys, ", "), styles.Code("--answer key=value")))
+	}
+	return err
+}
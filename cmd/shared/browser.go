@@ -0,0 +1,16 @@
+package shared
+
+import (
+	"github.com/deta/space/internal/container"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/pkg/browser"
+)
+
+// OpenURL opens the url in the default browser, in containers there is no browser so the url is only printed
+func OpenURL(url string) error {
+	if container.Dockerized() {
+		Logger.Printf("Running in a container, open %s in your browser", styles.Code(url))
+		return nil
+	}
+	return browser.OpenURL(url)
+}
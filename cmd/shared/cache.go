@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/runtime"
+)
+
+// ListProjects lists the projects of the user, the list is cached for runtime.ProjectsCacheTTL
+func ListProjects() (*api.ListProjectsResponse, error) {
+	var cached api.ListProjectsResponse
+	if ok, _ := runtime.ReadCache(runtime.ProjectsCacheKey, runtime.ProjectsCacheTTL, &cached); ok {
+		return &cached, nil
+	}
+
+	res, err := Client.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	runtime.WriteCache(runtime.ProjectsCacheKey, res)
+	return res, nil
+}
+
+// ListRegions lists the regions, the list is cached for runtime.RegionsCacheTTL
+func ListRegions() (*api.ListRegionsResponse, error) {
+	var cached api.ListRegionsResponse
+	if ok, _ := runtime.ReadCache(runtime.RegionsCacheKey, runtime.RegionsCacheTTL, &cached); ok {
+		return &cached, nil
+	}
+
+	res, err := Client.ListRegions()
+	if err != nil {
+		return nil, err
+	}
+	runtime.WriteCache(runtime.RegionsCacheKey, res)
+	return res, nil
+}
+
+// GetRevisions gets the revisions of a project, they are cached for runtime.RevisionsCacheTTL.
+// Use fresh if the latest revision is needed, e.g. to release it, the cache is updated then.
+func GetRevisions(projectID string, fresh bool) (*api.GetRevisionsResponse, error) {
+	key := runtime.RevisionsCacheKey(projectID)
+	var cached api.GetRevisionsResponse
+	if !fresh {
+		if ok, _ := runtime.ReadCache(key, runtime.RevisionsCacheTTL, &cached); ok {
+			return &cached, nil
+		}
+	}
+
+	res, err := Client.GetRevisions(&api.GetRevisionsRequest{ID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	runtime.WriteCache(key, res)
+	return res, nil
+}
+
+// InvalidateCache removes cached values that changed, e.g. the projects after a project was created
+func InvalidateCache(keys ...string) {
+	for _, key := range keys {
+		runtime.InvalidateCache(key)
+	}
+}
@@ -3,13 +3,17 @@ package shared
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/config"
 	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
 	"github.com/spf13/cobra"
 )
@@ -54,6 +58,27 @@ func CheckProjectInitialized(dirFlag string) PreRunFunc {
 	})
 }
 
+// CheckProjectTarget checks that the project to deploy to can be found, either by the id flag,
+// the environments of the project config, the linked project or the default project of the config file
+func CheckProjectTarget(dirFlag string, idFlag string) PreRunFunc {
+	return CheckAll(CheckExists(dirFlag), func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed(idFlag) {
+			return nil
+		}
+
+		dir, _ := cmd.Flags().GetString(dirFlag)
+		if config, err := spaceconfig.Load(dir); err == nil && len(config.Environments) > 0 {
+			return nil
+		}
+
+		err := CheckProjectInitialized(dirFlag)(cmd, args)
+		if err != nil && defaultProject() != "" {
+			return nil
+		}
+		return err
+	})
+}
+
 func CheckNotEmpty(flagNames ...string) PreRunFunc {
 	return func(cmd *cobra.Command, args []string) error {
 		for _, flagName := range flagNames {
@@ -68,31 +93,123 @@ func CheckNotEmpty(flagNames ...string) PreRunFunc {
 	}
 }
 
+// CheckOneOf checks that the flag is set to one of the values if it's set
+func CheckOneOf(flagName string, values ...string) PreRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed(flagName) {
+			return nil
+		}
+		value, _ := cmd.Flags().GetString(flagName)
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", flagName, strings.Join(values, ", "))
+	}
+}
+
+// CheckInteractiveOr checks that one of the flags is set in non-interactive mode, the flags replace a prompt of the command
+func CheckInteractiveOr(flagNames ...string) PreRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if CanPrompt() {
+			return nil
+		}
+		for _, flagName := range flagNames {
+			if cmd.Flags().Changed(flagName) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s flag must be provided in non-interactive mode", strings.Join(flagNames, " or "))
+	}
+}
+
 func isPrerelease(version string) bool {
 	return len(strings.Split(version, "-")) > 1
 }
 
-func CheckLatestVersion(cmd *cobra.Command, args []string) error {
-	if isPrerelease(SpaceVersion) {
-		return nil
+// StartVersionCheck fetches the latest version of the cli in the background if the cached version is older than the
+// version check interval of the config, so that commands don't wait for it. CheckLatestVersion shows the notice.
+func StartVersionCheck(cmd *cobra.Command) {
+	if skip, _ := cmd.Flags().GetBool("skip-version-check"); skip || isPrerelease(SpaceVersion) {
+		return
+	}
+
+	c, err := config.Load()
+	if err != nil {
+		// the root command warns about a broken config file
+		return
+	}
+	frequency, err := c.VersionCheckFrequency()
+	if err != nil {
+		Logger.Printf("%s %s", emoji.Warning, err)
+		return
+	}
+	if frequency == 0 {
+		return
 	}
 
-	latestVersion, lastCheck, err := runtime.GetLatestCachedVersion()
-	if err != nil || time.Since(lastCheck) > 69*time.Minute {
-		Logger.Println("\nChecking for new Space CLI version...")
+	var latestVersion string
+	if ok, _ := runtime.ReadCache(runtime.VersionCacheKey, frequency, &latestVersion); ok {
+		return
+	}
+	go func() {
 		version, err := api.GetLatestCliVersion()
 		if err != nil {
-			Logger.Println("Failed to check for new Space CLI version")
-			return nil
+			// keep the last known version, so that the check isn't retried by every command
+			version = cachedLatestVersion()
 		}
-
 		runtime.CacheLatestVersion(version)
-		latestVersion = version
+	}()
+}
+
+// cachedLatestVersion returns the latest version found by a previous check, regardless of its age
+func cachedLatestVersion() string {
+	var latestVersion string
+	runtime.ReadCache(runtime.VersionCacheKey, time.Duration(math.MaxInt64), &latestVersion)
+	return latestVersion
+}
+
+// CheckLatestVersion shows a notice if a previous version check found a newer version, it never waits for the network
+func CheckLatestVersion(cmd *cobra.Command, args []string) error {
+	if skip, _ := cmd.Flags().GetBool("skip-version-check"); skip || isPrerelease(SpaceVersion) {
+		return nil
 	}
 
-	if SpaceVersion != latestVersion {
-		Logger.Println(styles.Boldf("\n%s New Space CLI version available, upgrade with %s", styles.Info, styles.Code("space version upgrade")))
+	latestVersion := cachedLatestVersion()
+	if latestVersion != "" && SpaceVersion != latestVersion && !offerUpdate(latestVersion) {
+		Logger.Println(styles.Boldf("\n%s New Space CLI version %s available, update with %s", styles.Info, latestVersion, styles.Code("space update")))
 	}
 
 	return nil
 }
+
+// ApplyBandwidthLimit parses the bandwidth of the flag and limits the uploads of the client to it
+func ApplyBandwidthLimit(flagName string) PreRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed(flagName) {
+			return nil
+		}
+
+		bwlimit, _ := cmd.Flags().GetString(flagName)
+		limit, err := api.ParseBandwidth(bwlimit)
+		if err != nil {
+			return err
+		}
+		Client.SetUploadLimit(limit)
+		return nil
+	}
+}
+
+// ApplyInsecureSkipVerify disables the checksum verification of downloads if the flag is set
+func ApplyInsecureSkipVerify(flagName string) PreRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if skip, _ := cmd.Flags().GetBool(flagName); !skip {
+			return nil
+		}
+
+		Client.SkipChecksums = true
+		Logger.Println(styles.Errorf("%s WARNING: --%s is set, downloads are not verified and could be corrupted or tampered with!", styles.ErrorExclamation, flagName))
+		return nil
+	}
+}
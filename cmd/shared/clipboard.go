@@ -0,0 +1,43 @@
+package shared
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/container"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+)
+
+// AddCopyFlag adds the --copy flag to a command which prints a single important value
+func AddCopyFlag(cmd *cobra.Command, name string) {
+	cmd.Flags().Bool("copy", false, fmt.Sprintf("copy the %s to the clipboard", name))
+}
+
+// CopyToClipboard copies the value if the copy flag is set, the clipboard can be disabled in the config file
+func CopyToClipboard(cmd *cobra.Command, name string, value string) {
+	if copy, _ := cmd.Flags().GetBool("copy"); !copy || value == "" {
+		return
+	}
+
+	c, err := config.Load()
+	if err != nil {
+		// the root command warns about a broken config file
+		return
+	}
+	if c.DisableClipboard {
+		Logger.Printf("%s The clipboard is disabled in the config, the %s was not copied", emoji.Warning, name)
+		return
+	}
+	if container.Dockerized() || clipboard.Unsupported {
+		Logger.Printf("%s No clipboard available, the %s was not copied", emoji.Warning, name)
+		return
+	}
+
+	if err := clipboard.WriteAll(value); err != nil {
+		Logger.Printf("%s Failed to copy the %s to the clipboard: %s", emoji.Warning, name, err)
+		return
+	}
+	Logger.Printf("%s Copied the %s to the clipboard", emoji.Clipboard, name)
+}
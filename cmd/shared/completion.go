@@ -0,0 +1,112 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deta/space/internal/runtime"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// completionTimeout is how long a completion waits for the api, a slow network mustn't freeze the shell
+const completionTimeout = 3 * time.Second
+
+var (
+	// projectFlags take the id of a project
+	projectFlags = []string{"id", "project"}
+	// revisionFlags take the id of a revision of the project of the command
+	revisionFlags = []string{"rid"}
+)
+
+// RegisterDynamicCompletions completes the flags of cmd and its subcommands which take the id of a project or a
+// revision with the projects and revisions of the user. They are cached like for the commands, so that repeated tabs
+// don't call the api.
+func RegisterDynamicCompletions(cmd *cobra.Command) {
+	for _, name := range projectFlags {
+		if isStringFlag(cmd.Flags().Lookup(name)) {
+			cmd.RegisterFlagCompletionFunc(name, completeProjects)
+		}
+	}
+	for _, name := range revisionFlags {
+		if isStringFlag(cmd.Flags().Lookup(name)) {
+			cmd.RegisterFlagCompletionFunc(name, completeRevisions)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		RegisterDynamicCompletions(sub)
+	}
+}
+
+func isStringFlag(flag *pflag.Flag) bool {
+	return flag != nil && flag.Value.Type() == "string"
+}
+
+// completeProjects completes the ids of the projects of the user, described by their names
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions, _ := withTimeout(func() ([]string, error) {
+		res, err := ListProjects()
+		if err != nil {
+			return nil, err
+		}
+		var completions []string
+		for _, p := range res.Projects {
+			completions = append(completions, fmt.Sprintf("%s\t%s", p.ID, p.Name))
+		}
+		return completions, nil
+	})
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRevisions completes the ids of the latest revisions of the project of the id flag or the linked project,
+// described by their tags
+func completeRevisions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	projectID := ""
+	if flag := cmd.Flags().Lookup("id"); flag != nil {
+		projectID = flag.Value.String()
+	}
+	if projectID == "" {
+		dir := "./"
+		if flag := cmd.Flags().Lookup("dir"); flag != nil {
+			dir = flag.Value.String()
+		}
+		id, err := runtime.GetProjectID(dir)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		projectID = id
+	}
+
+	completions, _ := withTimeout(func() ([]string, error) {
+		res, err := GetRevisions(projectID, false)
+		if err != nil {
+			return nil, err
+		}
+		var completions []string
+		for _, r := range res.Revisions {
+			completions = append(completions, fmt.Sprintf("%s\t%s %s", r.ID, r.Tag, r.CreatedAt))
+		}
+		return completions, nil
+	})
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// withTimeout returns the completions of f, or nothing if f takes longer than completionTimeout
+func withTimeout(f func() ([]string, error)) ([]string, error) {
+	type result struct {
+		completions []string
+		err         error
+	}
+	done := make(chan result, 1)
+	go func() {
+		completions, err := f()
+		done <- result{completions, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.completions, r.err
+	case <-time.After(completionTimeout):
+		return nil, fmt.Errorf("timed out after %s", completionTimeout)
+	}
+}
@@ -0,0 +1,42 @@
+package shared
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/deta/space/internal/debuglog"
+	"github.com/spf13/cobra"
+)
+
+// DebugEnv turns on the debug log like --debug
+const DebugEnv = "SPACE_DEBUG"
+
+// Debug is the debug log of --debug, it's nil and discards all lines unless the debug mode is on
+var Debug *debuglog.Logger
+
+// ApplyDebug turns on the debug log if --debug or DebugEnv is set: every api request is logged with its status,
+// timing and request id on stderr
+func ApplyDebug(cmd *cobra.Command) {
+	enabled, _ := cmd.Flags().GetBool("debug")
+	if !cmd.Flags().Changed("debug") {
+		enabled, _ = strconv.ParseBool(os.Getenv(DebugEnv))
+	}
+	if !enabled {
+		return
+	}
+
+	if Debug == nil {
+		Debug = debuglog.New(os.Stderr)
+	}
+	// commands of an alias run in the same process, the client is only wrapped once
+	if _, wrapped := Client.Client.Transport.(*debuglog.Transport); !wrapped {
+		next := Client.Client.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		Client.Client.Transport = &debuglog.Transport{Next: next, Logger: Debug}
+	}
+	// the args aren't logged, they may contain secrets like the values of space env set
+	Debug.Log("command", "path", cmd.CommandPath(), "version", SpaceVersion, "platform", Platform)
+}
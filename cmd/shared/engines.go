@@ -0,0 +1,60 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/engines"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+)
+
+// enginesTimeout is how long the engines of the api are waited for, validation mustn't depend on the network
+const enginesTimeout = 2 * time.Second
+
+// WarnDeprecatedEngines warns about micros which use deprecated engines. The engines shipped with the cli are
+// refreshed from the api if it answers within enginesTimeout.
+func WarnDeprecatedEngines(s *spacefile.Spacefile) {
+	var remote []*api.Engine
+	client := BackgroundClient()
+	client.Retries = 0
+	done := make(chan *api.ListEnginesResponse, 1)
+	go func() {
+		res, err := client.ListEngines()
+		if err != nil {
+			res = nil
+		}
+		done <- res
+	}()
+	select {
+	case res := <-done:
+		if res != nil {
+			remote = res.Engines
+		}
+	case <-time.After(enginesTimeout):
+	}
+	statuses := engines.Statuses(remote)
+
+	for _, micro := range s.Micros {
+		e, ok := statuses[micro.Engine]
+		if !ok || e.Status == api.EngineSupported {
+			continue
+		}
+
+		var msg string
+		switch {
+		case e.Status == api.EngineSunset:
+			msg = fmt.Sprintf("Micro %s uses the engine %s, which is no longer supported", styles.Code(micro.Name), styles.Code(e.Name))
+		case e.SunsetAt != "":
+			msg = fmt.Sprintf("Micro %s uses the deprecated engine %s, which stops working on %s", styles.Code(micro.Name), styles.Code(e.Name), e.SunsetAt)
+		default:
+			msg = fmt.Sprintf("Micro %s uses the deprecated engine %s", styles.Code(micro.Name), styles.Code(e.Name))
+		}
+		Logger.Printf("\n%s %s", emoji.Warning, msg)
+		if e.Replacement != "" {
+			Logger.Printf("L Upgrade with %s", styles.Codef("space migrate engine --to %s --micro %s", e.Replacement, micro.Name))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/git"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/choose"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+)
+
+// ResolveProjectID returns the id of the project to deploy to, in order of preference: the given project id,
+// the project of the given environment, the environment mapped to the current branch, the linked project and the
+// default project of the config file
+func ResolveProjectID(projectDir string, projectID string, environment string) (string, error) {
+	if projectID != "" {
+		return projectID, nil
+	}
+
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil {
+		Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return "", err
+	}
+
+	if environment == "" && len(config.Environments) > 0 {
+		environment, err = environmentForBranch(projectDir, config)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if environment != "" {
+		env, ok := config.Environments[environment]
+		if !ok {
+			Logger.Printf("%s Unknown environment %s, available environments: %s", emoji.ErrorExclamation, styles.Code(environment), strings.Join(config.EnvironmentNames(), ", "))
+			return "", fmt.Errorf("unknown environment %s", environment)
+		}
+		Logger.Printf("%s Using environment %s", emoji.Earth, styles.Blue(environment))
+		return env.Project, nil
+	}
+
+	projectID, err = runtime.GetProjectID(projectDir)
+	if errors.Is(err, os.ErrNotExist) {
+		// outside of a project directory the default project of the config file is used
+		if id := defaultProject(); id != "" {
+			Logger.Printf("%s Using the default project %s", styles.Blue("i"), styles.Code(id))
+			return id, nil
+		}
+	}
+	if err != nil {
+		Logger.Printf("%s Failed to get project id: %s", emoji.ErrorExclamation, err)
+		return "", err
+	}
+	return projectID, nil
+}
+
+// defaultProject returns the default_project of the config file, an empty string if there is none
+func defaultProject() string {
+	c, err := config.Load()
+	if err != nil {
+		return ""
+	}
+	return c.DefaultProject
+}
+
+// environmentForBranch returns the environment mapped to the current branch, or an empty string if there is none
+func environmentForBranch(projectDir string, config *spaceconfig.Config) (string, error) {
+	branch, err := git.CurrentBranch(projectDir)
+	if err != nil {
+		Logger.Printf("%s Could not detect the current branch (%s), using the linked project", styles.Blue("i"), err)
+		return "", nil
+	}
+
+	matches := config.Match(branch)
+	switch {
+	case len(matches) == 0:
+		Logger.Printf("%s Branch %s is not mapped to an environment, using the linked project", styles.Blue("i"), styles.Code(branch))
+		return "", nil
+	case len(matches) == 1:
+		return matches[0], nil
+	case answers.Enabled() || !IsOutputInteractive():
+		// the environment decides which project is changed, it's never chosen by a default answer
+		Logger.Printf("%s Branch %s is mapped to multiple environments (%s), please choose one with %s", emoji.ErrorExclamation, styles.Code(branch), strings.Join(matches, ", "), styles.Code("--environment"))
+		return "", errors.New("ambiguous environment")
+	}
+
+	return choose.Run("environment", fmt.Sprintf("Branch %s is mapped to multiple environments, which one do you want to use?", styles.Code(branch)), matches...)
+}
@@ -0,0 +1,110 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+)
+
+// ErrReported is returned by commands which failed after printing why, without an error of their own to return
+var ErrReported = errors.New("command failed")
+
+// ExitError ends the cli with Code instead of 1, e.g. with the exit code of the command run by space ci entrypoint
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode maps the error of a command to the exit code of the cli: 0 without an error, the code of an ExitError or
+// of a failed program, e.g. run by space exec, and 1 otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var programErr *exec.ExitError
+	if errors.As(err, &programErr) && programErr.ExitCode() > 0 {
+		return programErr.ExitCode()
+	}
+	return 1
+}
+
+// maxRecordedLog is how much of the end of the log of a command is kept to find out if it reported its error
+const maxRecordedLog = 8 << 10
+
+// logRecorder passes the log through and keeps its end
+type logRecorder struct {
+	out    io.Writer
+	logged []byte
+}
+
+func (r *logRecorder) Write(p []byte) (int, error) {
+	r.logged = append(r.logged, p...)
+	if len(r.logged) > maxRecordedLog {
+		r.logged = r.logged[len(r.logged)-maxRecordedLog:]
+	}
+	return r.out.Write(p)
+}
+
+// reported tells if a command which logged logged has printed err already: it's ErrReported, its message was logged
+// or a line was logged as an error
+func reported(err error, logged string) bool {
+	if errors.Is(err, ErrReported) || strings.Contains(logged, err.Error()) {
+		return true
+	}
+	for _, line := range strings.Split(logged, "\n") {
+		line = strings.TrimSpace(line)
+		for _, e := range []emoji.Emoji{emoji.ErrorExclamation, emoji.X} {
+			if prefix := strings.TrimSpace(e.String()); prefix != "" && strings.HasPrefix(line, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SilenceReportedErrors keeps cobra from printing the error and the usage if the RunE of a command fails after it
+// reported its error. Other errors of RunE are printed by cobra without the usage, errors of arguments, flags and
+// checks are printed with the usage.
+func SilenceReportedErrors(root *cobra.Command) {
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if runE := c.RunE; runE != nil {
+			c.RunE = func(cmd *cobra.Command, args []string) error {
+				out := Logger.Writer()
+				recorder := &logRecorder{out: out}
+				Logger.SetOutput(recorder)
+				err := runE(cmd, args)
+				Logger.SetOutput(out)
+				if err != nil {
+					cmd.SilenceUsage = true
+					cmd.SilenceErrors = reported(err, string(recorder.logged))
+				}
+				return err
+			}
+		}
+		for _, child := range c.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+}
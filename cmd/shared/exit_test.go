@@ -0,0 +1,140 @@
+package shared
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	programErr := exec.Command("sh", "-c", "exit 3").Run()
+	assert.Assert(t, programErr != nil)
+
+	cases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "no error", err: nil, expected: 0},
+		{name: "error", err: errors.New("failed"), expected: 1},
+		{name: "reported", err: ErrReported, expected: 1},
+		{name: "exit error", err: &ExitError{Code: 4}, expected: 4},
+		{name: "wrapped exit error", err: fmt.Errorf("entrypoint: %w", &ExitError{Code: 5, Err: ErrReported}), expected: 5},
+		{name: "failed program", err: fmt.Errorf("exec: %w", programErr), expected: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, ExitCode(c.err), c.expected)
+		})
+	}
+}
+
+func TestSilenceReportedErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func() error
+		args []string
+		// printed is what cobra prints, the log of the command isn't included
+		printed string
+	}{
+		{
+			name: "reported",
+			run: func() error {
+				Logger.Printf("Project not found")
+				return ErrReported
+			},
+			printed: "",
+		},
+		{
+			name: "message logged",
+			run: func() error {
+				err := errors.New("connection refused")
+				Logger.Printf("Failed to list the revisions: %v", err)
+				return err
+			},
+			printed: "",
+		},
+		{
+			name: "error logged",
+			run: func() error {
+				Logger.Printf("%s Releases are frozen", emoji.ErrorExclamation)
+				return errors.New("release freeze")
+			},
+			printed: "",
+		},
+		{
+			name: "not logged",
+			run: func() error {
+				return errors.New("limit must be positive")
+			},
+			printed: "Error: limit must be positive\n",
+		},
+		{
+			name: "progress logged",
+			run: func() error {
+				Logger.Printf("Selected revision: v1")
+				return errors.New("invalid tag pattern")
+			},
+			printed: "Error: invalid tag pattern\n",
+		},
+		{
+			name:    "succeeded",
+			run:     func() error { return nil },
+			printed: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var logged, printed bytes.Buffer
+			previous := Logger.Writer()
+			Logger.SetOutput(&logged)
+			defer Logger.SetOutput(previous)
+
+			root := &cobra.Command{Use: "space"}
+			root.AddCommand(&cobra.Command{
+				Use: "run",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return c.run()
+				},
+			})
+			root.SetOut(&printed)
+			root.SetErr(&printed)
+			root.SetArgs([]string{"run"})
+			SilenceReportedErrors(root)
+
+			root.Execute()
+			assert.Equal(t, printed.String(), c.printed)
+			assert.Equal(t, Logger.Writer(), &logged)
+		})
+	}
+}
+
+func TestSilenceReportedErrorsUsage(t *testing.T) {
+	var printed bytes.Buffer
+	root := &cobra.Command{Use: "space"}
+	cmd := &cobra.Command{
+		Use:  "revoke",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	root.AddCommand(cmd)
+	root.SetOut(&printed)
+	root.SetErr(&printed)
+	root.SetArgs([]string{"revoke"})
+	SilenceReportedErrors(root)
+
+	err := root.Execute()
+	assert.ErrorContains(t, err, "accepts 1 arg")
+	assert.Assert(t, bytes.Contains(printed.Bytes(), []byte("Usage:")))
+	assert.Equal(t, ExitCode(err), 1)
+}
@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"fmt"
+
+	"github.com/deta/space/internal/api"
+	"golang.org/x/sync/errgroup"
+)
+
+// ProjectWithRevisions is a project with its revisions, the latest revision first
+type ProjectWithRevisions struct {
+	Project   *api.GetProjectResponse
+	Revisions []*api.Revision
+}
+
+// GetProjectWithRevisions gets a project and its latest revisions in parallel, an error of the project is returned
+// before an error of the revisions, e.g. so that api.ErrProjectNotFound can be checked
+func GetProjectWithRevisions(projectID string) (*ProjectWithRevisions, error) {
+	var g errgroup.Group
+	var p ProjectWithRevisions
+	var projectErr error
+
+	g.Go(func() error {
+		p.Project, projectErr = Client.GetProject(&api.GetProjectRequest{ID: projectID})
+		return projectErr
+	})
+	g.Go(func() error {
+		r, err := GetRevisions(projectID, true)
+		if err != nil {
+			return fmt.Errorf("failed to get revisions: %w", err)
+		}
+		p.Revisions = r.Revisions
+		return nil
+	})
+
+	err := g.Wait()
+	if projectErr != nil {
+		return nil, projectErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ForEach calls fn for every item in parallel, at most api.MaxConnsPerHost at a time, and returns the first error
+func ForEach[T any](items []T, fn func(item T) error) error {
+	var g errgroup.Group
+	g.SetLimit(api.MaxConnsPerHost)
+	for _, item := range items {
+		item := item
+		g.Go(func() error {
+			return fn(item)
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,23 @@
+package shared
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/internal/spacefile"
+)
+
+// AnnotateSpacefileError writes a GitHub Actions annotation for every issue of an invalid Spacefile
+func AnnotateSpacefileError(projectDir string, err error) {
+	file := gha.WorkspacePath(filepath.Join(projectDir, spacefile.SpacefileName))
+
+	var ve *spacefile.ValidationError
+	if !errors.As(err, &ve) || len(ve.Issues) == 0 {
+		gha.Error(&gha.Annotation{File: file, Title: "Invalid Spacefile", Message: err.Error()})
+		return
+	}
+	for _, issue := range ve.Issues {
+		gha.Error(&gha.Annotation{File: file, Line: issue.Line, Title: "Invalid Spacefile", Message: issue.Message})
+	}
+}
@@ -1,13 +1,23 @@
 package shared
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/deta/space/internal/api"
 	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/home"
+	"github.com/deta/space/pkg/components/emoji"
 )
 
+// generatedKeys holds the keys generated during this process, in case they can't be stored
+var generatedKeys = make(map[string]string)
+
 func GenerateDataKeyIfNotExists(projectID string) (string, error) {
+	if projectKey, ok := generatedKeys[projectID]; ok {
+		return projectKey, nil
+	}
+
 	// check if we have already stored the project key based on the project's id
 	projectKey, err := auth.GetProjectKey(projectID)
 	if err == nil {
@@ -29,9 +39,13 @@ func GenerateDataKeyIfNotExists(projectID string) (string, error) {
 		return "", err
 	}
 
-	// store the project key locally
+	generatedKeys[projectID] = r.Value
+
+	// store the project key locally, without a writable state a new key is generated next time
 	err = auth.StoreProjectKey(projectID, r.Value)
-	if err != nil {
+	if errors.Is(err, home.ErrReadOnly) {
+		Logger.Printf("%s Failed to store the project key, a new key will be generated next time: %v", emoji.Warning, err)
+	} else if err != nil && !errors.Is(err, home.ErrNoState) {
 		return "", err
 	}
 
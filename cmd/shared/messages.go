@@ -1,9 +1,14 @@
 package shared
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/answers"
 	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
 	"github.com/mattn/go-isatty"
@@ -24,9 +29,30 @@ func ProjectNotes(projectName string, projectId string) string {
 }
 
 func LoginInfo() string {
-	return styles.Boldf("No auth token found. Run %s or provide access token to login.", styles.Code("space login"))
+	return styles.Bold(i18n.T("hint.login", styles.Code("space login")))
 }
 
+// IsOutputInteractive reports if the output is a terminal, which full screen interfaces and editors need
 func IsOutputInteractive() bool {
 	return isatty.IsTerminal(os.Stdout.Fd())
 }
+
+// CanPrompt reports if the user can be prompted, prompts are answered from the answers flags if they are set
+func CanPrompt() bool {
+	return answers.Enabled() || IsOutputInteractive()
+}
+
+// ErrorMessage formats the error of a failed action, the fields rejected by the api are listed on their own lines
+func ErrorMessage(action string, err error) string {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || len(apiErr.Fields) < 2 {
+		return styles.Errorf("%s %s: %v", emoji.ErrorExclamation, action, err)
+	}
+
+	var b strings.Builder
+	b.WriteString(styles.Errorf("%s %s:", emoji.ErrorExclamation, action))
+	for _, f := range apiErr.Fields {
+		b.WriteString(fmt.Sprintf("\n  L %s", f))
+	}
+	return b.String()
+}
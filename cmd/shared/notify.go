@@ -0,0 +1,39 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/notify"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+)
+
+// AddNotifyFlag adds the --notify flag to a command with a long operation
+func AddNotifyFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("notify", false, fmt.Sprintf("show a desktop notification when it finishes, set notify in %s to always notify", config.FileName))
+}
+
+// NotifyFinished shows a desktop notification about the finished operation if it's enabled with --notify or in the
+// config file. Only operations which took longer than notify.MinDuration notify, as the terminal is likely in the
+// background by then.
+func NotifyFinished(cmd *cobra.Command, started time.Time, operation string, err error) {
+	enabled, _ := cmd.Flags().GetBool("notify")
+	if !cmd.Flags().Changed("notify") {
+		if c, err := config.Load(); err == nil {
+			enabled = c.Notify
+		}
+	}
+	if !enabled || time.Since(started) < notify.MinDuration {
+		return
+	}
+
+	message := fmt.Sprintf("%s finished", operation)
+	if err != nil {
+		message = fmt.Sprintf("%s failed", operation)
+	}
+	if err := notify.Send("Space CLI", message); err != nil && cmd.Flags().Changed("notify") {
+		Logger.Printf("%s %s", emoji.Warning, err)
+	}
+}
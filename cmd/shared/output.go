@@ -0,0 +1,109 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// OutputText prints styled text for humans
+	OutputText = "text"
+	// OutputJSON prints the result of a command as a json object on stdout, logs go to stderr
+	OutputJSON = "json"
+)
+
+var jsonOutput bool
+
+// AddOutputFlag adds the persistent --output flag, commands with an --output flag of their own, e.g. for the path of
+// an archive, shadow it
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("output", OutputText, "format of the result: text or json, json prints the result of push, release and link as json on stdout and the logs on stderr")
+}
+
+// ApplyOutput sets the format of the --output flag of the root command, the output of the config file applies if the
+// flag isn't set
+func ApplyOutput(cmd *cobra.Command, c *config.Config) error {
+	flag := cmd.Root().PersistentFlags().Lookup("output")
+	if flag == nil {
+		return nil
+	}
+	output := flag.Value.String()
+	if !flag.Changed && c.Output != "" {
+		// a broken config file mustn't block space config from fixing it
+		if c.Output == OutputText || c.Output == OutputJSON {
+			output = c.Output
+		} else {
+			Logger.Printf("%s Ignoring the output %q of %s, it must be one of %s, %s", emoji.Warning, c.Output, config.FileName, OutputText, OutputJSON)
+		}
+	}
+	switch output {
+	case OutputText:
+		jsonOutput = false
+	case OutputJSON:
+		jsonOutput = true
+	default:
+		return fmt.Errorf("output must be one of %s, %s", OutputText, OutputJSON)
+	}
+	return nil
+}
+
+// AddOutputPathFlag adds --output as a deprecated alias of the flag name with the path of the file a command
+// writes. It shadows the persistent --output flag, so --output text and --output json still choose the format, see
+// OutputPath.
+func AddOutputPathFlag(cmd *cobra.Command, name string) {
+	cmd.Flags().StringP("output", "o", "", fmt.Sprintf("format of the result: text or json, a path works like --%s but is deprecated", name))
+}
+
+// OutputPath returns the path of the flag name, or the value of --output if it isn't a format. A format applies like
+// the persistent --output flag.
+func OutputPath(cmd *cobra.Command, name string) string {
+	path, _ := cmd.Flags().GetString(name)
+	if !cmd.Flags().Changed("output") {
+		return path
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	switch output {
+	case OutputText:
+		jsonOutput = false
+	case OutputJSON:
+		jsonOutput = true
+	default:
+		if path != "" {
+			Logger.Printf("%s Ignoring --output %s, the path of --%s is used", emoji.Warning, output, name)
+			return path
+		}
+		Logger.Printf("%s --output with a path is deprecated, use --%s %s", emoji.Warning, name, output)
+		return output
+	}
+	return path
+}
+
+// JSONOutput reports if the result of the command is printed as json
+func JSONOutput() bool {
+	return jsonOutput
+}
+
+// Stdout is where logs of builds and releases are streamed to, stderr with --output json to keep stdout parseable
+func Stdout() io.Writer {
+	if jsonOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// PrintJSON prints the result of the command as indented json on stdout
+func PrintJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to print the result: %w", err)
+	}
+	return nil
+}
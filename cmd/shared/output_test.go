@@ -0,0 +1,45 @@
+package shared
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func TestOutputPath(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+		json     bool
+	}{
+		{name: "file", args: []string{"--file", ".env"}, expected: ".env"},
+		{name: "output path", args: []string{"--output", ".env"}, expected: ".env"},
+		{name: "output json", args: []string{"--output", "json"}, json: true},
+		{name: "output text with file", args: []string{"--file", ".env", "--output", "text"}, expected: ".env"},
+		{name: "file wins", args: []string{"--file", ".env", "-o", "other.env"}, expected: ".env"},
+		{name: "none"},
+	}
+
+	previous := Logger.Writer()
+	Logger.SetOutput(io.Discard)
+	t.Cleanup(func() {
+		Logger.SetOutput(previous)
+		jsonOutput = false
+	})
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			jsonOutput = false
+			cmd := &cobra.Command{Use: "pull"}
+			cmd.Flags().StringP("file", "f", "", "")
+			AddOutputPathFlag(cmd, "file")
+			assert.NilError(t, cmd.ParseFlags(c.args))
+
+			assert.Equal(t, OutputPath(cmd, "file"), c.expected)
+			assert.Equal(t, JSONOutput(), c.json)
+		})
+	}
+}
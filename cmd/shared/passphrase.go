@@ -0,0 +1,56 @@
+package shared
+
+import (
+	"fmt"
+
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/pkg/components/text"
+)
+
+// PromptStatePassphrase asks for the passphrase of the encrypted local state
+func PromptStatePassphrase() (string, error) {
+	if !CanPrompt() {
+		return "", crypt.ErrNoPassphrase
+	}
+
+	return text.Run(&text.Input{
+		Key:          "state.passphrase",
+		Prompt:       "Passphrase of your local Space state",
+		PasswordMode: true,
+	})
+}
+
+// PromptNewStatePassphrase asks for a new passphrase for the local state and for its confirmation
+func PromptNewStatePassphrase() (string, error) {
+	if !CanPrompt() {
+		return "", crypt.ErrNoPassphrase
+	}
+
+	passphrase, err := text.Run(&text.Input{
+		Key:          "state.passphrase",
+		Prompt:       "Choose a passphrase for your local Space state",
+		PasswordMode: true,
+		Validator: func(value string) error {
+			if len(value) < 8 {
+				return fmt.Errorf("passphrase must be at least 8 characters long")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	_, err = text.Run(&text.Input{
+		Key:          "state.passphrase",
+		Prompt:       "Repeat passphrase",
+		PasswordMode: true,
+		Validator: func(value string) error {
+			if value != passphrase {
+				return fmt.Errorf("passphrases do not match")
+			}
+			return nil
+		},
+	})
+	return passphrase, err
+}
@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/termtitle"
+	"github.com/mattn/go-isatty"
+)
+
+// Progress shows the phase of a long operation in the title of the terminal and rings the bell when it finishes, both
+// are turned on with terminal_title and bell in the config file
+type Progress struct {
+	mu    sync.Mutex
+	title bool
+	bell  bool
+	last  string
+}
+
+// StartProgress starts showing the progress of an operation, nothing is shown without a terminal
+func StartProgress() *Progress {
+	p := &Progress{}
+	if !isatty.IsTerminal(os.Stderr.Fd()) {
+		return p
+	}
+	if c, err := config.Load(); err == nil {
+		p.title, p.bell = c.TerminalTitle, c.Bell
+	}
+	return p
+}
+
+// Phase shows the phase of the operation, e.g. building
+func (p *Progress) Phase(phase string) {
+	p.set(termtitle.Format(phase, -1))
+}
+
+// Percent shows the phase of the operation with how much of it is done
+func (p *Progress) Percent(phase string, done int64, total int64) {
+	if total > 0 {
+		p.set(termtitle.Format(phase, int(done*100/total)))
+	}
+}
+
+func (p *Progress) set(title string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.title || title == p.last {
+		return
+	}
+	p.last = title
+	fmt.Fprint(Logger.Writer(), termtitle.Sequence(title))
+}
+
+// Done resets the title and rings the bell
+func (p *Progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.title && p.last != "" {
+		fmt.Fprint(Logger.Writer(), termtitle.Sequence(""))
+		p.last = ""
+	}
+	if p.bell {
+		fmt.Fprint(Logger.Writer(), termtitle.Bell)
+	}
+}
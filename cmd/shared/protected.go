@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/components/text"
+	"github.com/spf13/cobra"
+)
+
+// OverrideProtectionFlag skips the typed confirmation of protected projects
+const OverrideProtectionFlag = "i-know-what-im-doing"
+
+// ErrProtected a protected project wasn't confirmed
+var ErrProtected = errors.New("the project is protected")
+
+// AddOverrideProtectionFlag adds the flag to skip the typed confirmation of protected projects
+func AddOverrideProtectionFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool(OverrideProtectionFlag, false, fmt.Sprintf("don't ask to type the project name before changing a protected project or environment of %s", spaceconfig.FileName))
+}
+
+// ConfirmProtected asks to type the name of the project before the action if it's protected in the project config.
+// Neither --yes nor answers confirm it, only typing the name in a terminal or the override flag.
+func ConfirmProtected(cmd *cobra.Command, projectDir string, projectID string, action string) error {
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil {
+		Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return err
+	}
+	protected, ok := config.ProtectedName(projectID)
+	if !ok {
+		return nil
+	}
+
+	if override, _ := cmd.Flags().GetBool(OverrideProtectionFlag); override {
+		Logger.Printf("%s %s is protected, continuing to %s because of %s", emoji.Warning, styles.Blue(protected), action, styles.Code("--"+OverrideProtectionFlag))
+		return nil
+	}
+	if answers.Enabled() || !IsOutputInteractive() {
+		Logger.Println(styles.Errorf("%s %s is protected, type the project name in a terminal to %s or pass %s", emoji.ErrorExclamation, protected, action, styles.Code("--"+OverrideProtectionFlag)))
+		return ErrProtected
+	}
+
+	project, err := Client.GetProject(&api.GetProjectRequest{ID: projectID})
+	if err != nil {
+		Logger.Println(styles.Errorf("%s Failed to get project: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	Logger.Printf("%s %s is protected, you are about to %s.", emoji.Warning, styles.Blue(protected), action)
+	_, err = text.Run(&text.Input{
+		Key:    "protected",
+		Prompt: fmt.Sprintf("Type %s to continue:", project.Name),
+		Validator: func(value string) error {
+			if value != project.Name {
+				return fmt.Errorf("type %s to continue or press ctrl+c to cancel", project.Name)
+			}
+			return nil
+		},
+	})
+	return err
+}
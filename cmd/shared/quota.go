@@ -0,0 +1,46 @@
+package shared
+
+import (
+	"fmt"
+
+	"github.com/deta/space/internal/quota"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+)
+
+// GetQuota returns the usage of the space against the limits of the platform
+func GetQuota() ([]*quota.Usage, error) {
+	res, err := Client.GetQuota()
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]*quota.Usage, 0, len(res.Resources))
+	for _, r := range res.Resources {
+		usages = append(usages, &quota.Usage{Name: r.Name, Unit: r.Unit, Used: r.Used, Limit: r.Limit})
+	}
+	return usages, nil
+}
+
+// CheckQuota warns about the nearly exhausted quotas of the resources needed to action, and fails before the action
+// if one of them is exhausted. If the quota can't be fetched the action is tried anyway.
+func CheckQuota(action string, resources ...string) error {
+	usages, err := GetQuota()
+	if err != nil {
+		return nil
+	}
+
+	var exhausted []string
+	for _, u := range quota.Check(usages, resources...) {
+		if u.Level() == quota.Exhausted {
+			exhausted = append(exhausted, u.Label())
+			Logger.Println(styles.Errorf("%s The quota of %s is exhausted: %s", emoji.ErrorExclamation, u.Label(), u))
+			continue
+		}
+		Logger.Printf("%s The quota of %s is nearly exhausted: %s", emoji.Warning, u.Label(), u)
+	}
+	if len(exhausted) > 0 {
+		Logger.Printf("\nSee %s for your usage, you can %s again once the quota is available", styles.Code("space quota"), action)
+		return fmt.Errorf("quota exhausted: %v", exhausted)
+	}
+	return nil
+}
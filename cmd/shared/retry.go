@@ -0,0 +1,30 @@
+package shared
+
+import (
+	"time"
+
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+)
+
+// ApplyRetries sets how often failed requests are retried from the config file or the environment, every retry is
+// reported so that a slow command doesn't look stuck
+func ApplyRetries() error {
+	c, err := config.Load()
+	if err != nil {
+		// the environment applies without a config file, e.g. with --no-state
+		c = &config.Config{}
+	}
+	retries, err := c.Retries(api.DefaultRetries)
+	if err != nil {
+		return err
+	}
+
+	Client.Retries = retries
+	Client.OnRetry = func(r *api.Retry) {
+		Logger.Println(styles.Subtlef("%s %s %s failed: %v, retrying in %s (%d/%d)", emoji.Warning, r.Method, r.Path, r.Err, r.Delay.Round(100*time.Millisecond), r.Attempt, retries))
+	}
+	return nil
+}
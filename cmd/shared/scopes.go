@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// tokenCheckTimeout is how long the end of a command waits for the lookup of the token
+const tokenCheckTimeout = 2 * time.Second
+
+// tokenCheck receives the token info looked up by StartTokenCheck, nil if there is no check
+var tokenCheck chan *api.TokenInfo
+
+// CommandName returns the path of the command without the name of the cli, e.g. "env set"
+func CommandName(cmd *cobra.Command) string {
+	return strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+}
+
+// runsInCI reports if the cli runs in a CI pipeline, where a leaked account token does the most harm
+func runsInCI() bool {
+	_, ci := os.LookupEnv("CI")
+	return ci || gha.Enabled()
+}
+
+// StartTokenCheck looks up the kind of the access token in the background if a command which works with a project
+// token runs in CI, so that WarnBroadToken can warn about account tokens
+func StartTokenCheck(cmd *cobra.Command) {
+	if !runsInCI() {
+		return
+	}
+	if _, ok := auth.CommandScopes(CommandName(cmd)); !ok {
+		return
+	}
+	if _, err := auth.GetAccessToken(); err != nil {
+		return
+	}
+
+	// the command changes Client while the lookup runs, e.g. to follow the progress of an upload
+	client := BackgroundClient()
+	client.Retries = 0
+	tokenCheck = make(chan *api.TokenInfo, 1)
+	go func() {
+		info, err := client.GetTokenInfo()
+		if err != nil {
+			info = nil
+		}
+		tokenCheck <- info
+	}()
+}
+
+// WarnBroadToken warns if the command ran with an account token although a project token with fewer scopes would do,
+// it waits shortly for the lookup of StartTokenCheck
+func WarnBroadToken(cmd *cobra.Command) {
+	if tokenCheck == nil {
+		return
+	}
+	var info *api.TokenInfo
+	select {
+	case info = <-tokenCheck:
+	case <-time.After(tokenCheckTimeout):
+	}
+	if info == nil || !auth.Overprivileged(info.Kind, CommandName(cmd)) {
+		return
+	}
+
+	scopes, _ := auth.CommandScopes(CommandName(cmd))
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	Logger.Printf("\n%s %s ran with an account token, which can change everything in your account if it leaks from CI. A project token with the scope %s would do, see %s.", emoji.Warning, styles.Code("space "+CommandName(cmd)), strings.Join(names, ", "), styles.Code("space auth scopes"))
+}
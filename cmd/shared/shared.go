@@ -11,6 +11,7 @@ const (
 	DocsUrl          = "https://deta.space/docs"
 	SpacefileDocsUrl = "https://deta.space/docs/en/reference/spacefile"
 	BuilderUrl       = "https://deta.space/builder"
+	DiscoveryUrl     = "https://deta.space/discovery"
 )
 
 var (
@@ -19,3 +20,11 @@ var (
 	Client       = api.NewDetaClient(SpaceVersion, Platform)
 	Logger       = log.New(os.Stderr, "", 0)
 )
+
+// BackgroundClient returns a client for the requests of goroutines which outlive a step of the command, Client is
+// changed by the command while they run, e.g. to follow the progress of an upload. It shares the transport of Client.
+func BackgroundClient() *api.DetaClient {
+	c := api.NewDetaClient(Client.Version, Client.Platform)
+	c.Client = Client.Client
+	return c
+}
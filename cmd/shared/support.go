@@ -0,0 +1,16 @@
+package shared
+
+import (
+	"time"
+
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/support"
+)
+
+// KeepFailedRequests logs the latest failed requests of the client, so that space support bundle can include their ids
+func KeepFailedRequests() {
+	Client.OnFailedRequest = func(r *api.FailedRequest) {
+		// the log is best effort, e.g. with --no-state it isn't written
+		support.LogRequest(&support.Request{Time: time.Now().UTC(), Method: r.Method, Path: r.Path, Status: r.Status, RequestID: r.RequestID})
+	}
+}
@@ -0,0 +1,51 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/confirm"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/mattn/go-isatty"
+)
+
+// updater installs a version of the cli, it's set by the version command which can't be imported here
+var updater func(version string) error
+
+// SetUpdater sets how the offer of CheckLatestVersion updates the cli
+func SetUpdater(f func(version string) error) {
+	updater = f
+}
+
+// offerUpdate asks once per version if the cli should update itself, only where the prompt can't get in the way: in
+// a terminal, outside of CI and without answers. It returns false if the update wasn't offered.
+func offerUpdate(latestVersion string) bool {
+	if updater == nil || answers.Enabled() || JSONOutput() || runsInCI() ||
+		!isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return false
+	}
+
+	var offered string
+	runtime.ReadCache(runtime.UpdateOfferCacheKey, time.Duration(math.MaxInt64), &offered)
+	if offered == latestVersion {
+		return false
+	}
+	// without a place to remember the offer, e.g. with --no-state, it would be repeated after every command
+	if err := runtime.WriteCache(runtime.UpdateOfferCacheKey, latestVersion); err != nil {
+		return false
+	}
+
+	Logger.Println()
+	update, err := confirm.Run("update.confirm", fmt.Sprintf("Space CLI %s is available, you have %s. Update now?", latestVersion, SpaceVersion))
+	if err != nil || !update {
+		Logger.Println(styles.Subtlef("Update later with %s", styles.Code("space update")))
+		return true
+	}
+	// a failed update is reported by the updater, the command itself succeeded
+	updater(latestVersion)
+	return true
+}
@@ -0,0 +1,48 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/git"
+)
+
+// ErrUnknownHandle the Space handle of the user is neither configured nor known for the access token
+var ErrUnknownHandle = errors.New("unknown space handle")
+
+// CurrentUser returns who runs the command, the actor of the CI, the name of the git user of the project or the user
+// of the system. It never contains an email, as it is sent to the api, e.g. as a label of a revision.
+func CurrentUser(projectDir string) string {
+	for _, env := range []string{"GITHUB_ACTOR", "GITLAB_USER_LOGIN"} {
+		if actor := os.Getenv(env); actor != "" {
+			return actor
+		}
+	}
+	if gitUser, err := git.User(projectDir); err == nil {
+		return gitUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown user"
+}
+
+// CurrentHandle returns the Space handle of who runs the command without the @, the handle of the config file or of
+// the account of the access token. Unlike CurrentUser it can be compared with handles of other Space users.
+func CurrentHandle() (string, error) {
+	if c, err := config.Load(); err == nil && c.Handle != "" {
+		return strings.TrimPrefix(c.Handle, "@"), nil
+	}
+	info, err := Client.GetTokenInfo()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnknownHandle, err)
+	}
+	if info.Handle == "" {
+		return "", ErrUnknownHandle
+	}
+	return strings.TrimPrefix(info.Handle, "@"), nil
+}
@@ -0,0 +1,125 @@
+package state
+
+import (
+	"os"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/internal/registry"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdStateEncrypt() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encrypt [flags]",
+		Short: "Encrypt the local state with a passphrase",
+		Long: `Encrypt the local state with a passphrase.
+
+Your access token and project keys are encrypted, as well as the .space folder of the project in --dir. The .space folders of other projects are encrypted the next time they are written.`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+
+			if crypt.StateEncrypted() {
+				shared.Logger.Printf("%s The local state is already encrypted", emoji.Check)
+				return nil
+			}
+
+			passphrase, ok := os.LookupEnv(crypt.PassphraseEnv)
+			if !ok {
+				var err error
+				if passphrase, err = shared.PromptNewStatePassphrase(); err != nil {
+					shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+					return err
+				}
+			}
+			crypt.SetPassphrase(passphrase)
+
+			if err := resealState(projectDir, true); err != nil {
+				return err
+			}
+
+			if err := crypt.SetStateEncrypted(true); err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to enable encryption: %s", emoji.ErrorExclamation, err))
+				// keep the state in plain text like the missing marker says
+				resealState(projectDir, false)
+				return err
+			}
+			shared.Logger.Println(styles.Greenf("%s Encrypted the local state", emoji.Check))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "project whose .space folder is encrypted")
+	cmd.MarkFlagDirname("dir")
+
+	return cmd
+}
+
+func newCmdStateDecrypt() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decrypt [flags]",
+		Short: "Disable the encryption of the local state",
+		Long: `Disable the encryption of the local state.
+
+Your access token and project keys are decrypted, as well as the .space folder of the project in --dir. Encrypted .space folders of other projects can still be read, they are decrypted the next time they are written.`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+
+			if !crypt.StateEncrypted() {
+				shared.Logger.Printf("%s The local state is not encrypted", emoji.Check)
+				return nil
+			}
+
+			if err := resealState(projectDir, false); err != nil {
+				return err
+			}
+
+			if err := crypt.SetStateEncrypted(false); err != nil {
+				shared.Logger.Println(styles.Errorf("%s Failed to disable encryption: %s", emoji.ErrorExclamation, err))
+				// keep the state encrypted like the marker says
+				resealState(projectDir, true)
+				return err
+			}
+			shared.Logger.Println(styles.Greenf("%s Decrypted the local state", emoji.Check))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "project whose .space folder is decrypted")
+	cmd.MarkFlagDirname("dir")
+
+	return cmd
+}
+
+// resealState rewrites all state files so that they are encrypted or not. If a file fails, the files which were
+// rewritten already are rolled back, so that all files keep matching the encryption setting.
+func resealState(projectDir string, encrypted bool) error {
+	files, err := auth.StateFiles()
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
+		return err
+	}
+	files = append(files, runtime.ProjectStateFiles(projectDir)...)
+	if registries, err := registry.StateFile(); err == nil {
+		files = append(files, registries)
+	}
+
+	for i, file := range files {
+		if err := crypt.Reseal(file, encrypted); err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to rewrite %s: %s", emoji.ErrorExclamation, file, err))
+			for _, done := range files[:i] {
+				crypt.Reseal(done, !encrypted)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package state
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdState() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Manage the encryption of the local state",
+		Long: `Manage the encryption of the local state.
+
+The local state consists of your access token, your project keys and the .space folder of your projects. On shared machines it can be encrypted at rest with a passphrase. The passphrase is asked for once per command, set SPACE_STATE_PASSPHRASE to provide it in scripts.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdStateEncrypt())
+	cmd.AddCommand(newCmdStateDecrypt())
+
+	return cmd
+}
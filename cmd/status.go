@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/components/watch"
+	"github.com/spf13/cobra"
+)
+
+func newCmdStatus() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [flags]",
+		Short: "Show the status of your project",
+		Long: `Show the status of your project: its latest revision and the promotion of it to the Builder, its latest release and if the maintenance mode is on.
+
+The project is the linked project, the environment of the current branch or the project given with --id or --environment.
+
+With --watch the status is refreshed every --interval until you quit with q, changed values are highlighted. Without a terminal or in the accessibility mode only the changes are printed line by line.`,
+		Example: `  space status
+  space status --environment production
+  space status --watch --interval 10s`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckProjectTarget("dir", "id"), shared.CheckNotEmpty("id", "environment")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			projectID, _ := cmd.Flags().GetString("id")
+			environment, _ := cmd.Flags().GetString("environment")
+			watchStatus, _ := cmd.Flags().GetBool("watch")
+			interval, _ := cmd.Flags().GetDuration("interval")
+
+			if interval < time.Second {
+				shared.Logger.Printf("%s --interval must be at least 1s", emoji.ErrorExclamation)
+				return shared.ErrReported
+			}
+
+			projectID, err := shared.ResolveProjectID(projectDir, projectID, environment)
+			if err != nil {
+				return err
+			}
+			project, err := getStatusProject(projectID)
+			if err != nil {
+				return err
+			}
+
+			if watchStatus {
+				err := watch.Run(&watch.Input{
+					Title:    fmt.Sprintf("%s (%s)", project.Name, project.ID),
+					Interval: interval,
+					Fetch: func() ([]watch.Field, error) {
+						return statusFields(projectID), nil
+					},
+				})
+				if err != nil {
+					shared.Logger.Println(styles.Errorf("%s Failed to watch the status: %v", emoji.ErrorExclamation, err))
+					return err
+				}
+				return nil
+			}
+
+			shared.Logger.Printf("%s %s %s\n", emoji.Package, styles.Bold(project.Name), styles.Subtle("("+project.ID+")"))
+			for _, f := range statusFields(projectID) {
+				shared.Logger.Printf("  %s: %s", f.Label, f.Value)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of the project")
+	cmd.Flags().StringP("id", "i", "", "project id of the project")
+	cmd.Flags().String("environment", "", "environment of the project config, defaults to the environment of the current branch")
+	cmd.Flags().BoolP("watch", "w", false, "refresh the status until you quit and highlight the changes")
+	cmd.Flags().Duration("interval", 5*time.Second, "how often the status is refreshed with --watch")
+
+	return cmd
+}
+
+func getStatusProject(projectID string) (*api.GetProjectResponse, error) {
+	project, err := shared.Client.GetProject(&api.GetProjectRequest{ID: projectID})
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoAccessTokenFound):
+			shared.Logger.Println(shared.LoginInfo())
+		case errors.Is(err, api.ErrProjectNotFound):
+			shared.Logger.Println(styles.Errorf("%s No project found. Please provide a valid Project ID.", emoji.ErrorExclamation))
+		default:
+			shared.Logger.Println(styles.Errorf("%s Failed to get project: %v", emoji.ErrorExclamation, err))
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// statusFields returns the status of the project, a value which can't be fetched is shown as unknown
+func statusFields(projectID string) []watch.Field {
+	revision, promotion := "unknown", "unknown"
+	if r, err := shared.GetRevisions(projectID, true); err == nil {
+		if len(r.Revisions) == 0 {
+			revision, promotion = "none, create one with space push", "none"
+		} else {
+			latest := r.Revisions[0]
+			revision = latest.ID
+			if latest.Tag != "" {
+				revision = latest.Tag
+			}
+			revision += " (" + latest.CreatedAt + ")"
+			if p, err := shared.Client.GetPromotionByRevision(&api.GetPromotionRequest{RevisionID: latest.ID}); err == nil {
+				promotion = p.Status
+			}
+		}
+	}
+
+	release := "unknown"
+	if r, err := shared.Client.ListReleases(&api.ListReleasesRequest{AppID: projectID}); err == nil {
+		if len(r.Releases) == 0 {
+			release = "none, create one with space release"
+		} else {
+			latest := r.Releases[0]
+			release = latest.Version + " (" + latest.Status + ", " + latest.CreatedAt + ")"
+		}
+	}
+
+	maintenance := "unknown"
+	if m, err := shared.Client.GetMaintenance(&api.GetMaintenanceRequest{AppID: projectID}); err == nil {
+		maintenance = "off"
+		if m.Enabled {
+			maintenance = "on"
+			if m.Message != "" {
+				maintenance += " (" + m.Message + ")"
+			}
+		}
+	}
+
+	return []watch.Field{
+		{Label: "Latest revision", Value: revision},
+		{Label: "Promotion", Value: promotion},
+		{Label: "Latest release", Value: release},
+		{Label: "Maintenance mode", Value: maintenance},
+	}
+}
@@ -0,0 +1,229 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/config"
+	"github.com/deta/space/internal/crash"
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/internal/ping"
+	"github.com/deta/space/internal/runtime"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/internal/support"
+	"github.com/deta/space/pkg/components/choose"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+const (
+	reviewInclude = "Include"
+	reviewShow    = "Show the content"
+	reviewExclude = "Exclude"
+)
+
+func newCmdSupportBundle() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle [flags]",
+		Short: "Create an archive of diagnostics to attach to an issue",
+		Long: `Create a zip archive of diagnostics to attach to an issue: the versions of the CLI and your system, checks of your login, project and connection, your Spacefile and project config, the CLI config, the latest crash report and the ids of the latest failed requests.
+
+Secrets like tokens, keys and passwords are redacted and your home directory is shortened to ~. Before the archive is written, you review every file and choose to include it, show its content or exclude it. Pass --yes to include all files without a review.`,
+		Example: `  space support bundle
+  space support bundle --dir ./my-app --file bundle.zip`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckExists("dir"),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			file := shared.OutputPath(cmd, "file")
+			if file == "" {
+				file = fmt.Sprintf("space-support-%s.zip", time.Now().Format("20060102-150405"))
+			}
+
+			if !shared.CanPrompt() {
+				shared.Logger.Printf("%s The files of the bundle are reviewed in a terminal, pass --yes to include all files", emoji.ErrorExclamation)
+				return shared.ErrReported
+			}
+
+			if err := createBundle(projectDir, file); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of the project to include")
+	cmd.Flags().StringP("file", "f", "", "path of the archive, defaults to space-support-<time>.zip")
+	shared.AddOutputPathFlag(cmd, "file")
+
+	return cmd
+}
+
+func createBundle(projectDir string, file string) error {
+	userHome, _ := os.UserHomeDir()
+	bundle := support.NewBundle(userHome)
+
+	shared.Logger.Printf("%s Collecting diagnostics...\n", emoji.Eyes)
+	bundle.Add("versions.txt", versions())
+	bundle.Add("diagnostics.txt", diagnostics(projectDir))
+	addFile(bundle, filepath.Join(projectDir, spacefile.SpacefileName))
+	addFile(bundle, filepath.Join(projectDir, spaceconfig.FileName))
+	if path, err := config.Path(); err == nil {
+		addFile(bundle, path)
+	}
+	if path, err := crash.Latest(); err == nil && path != "" {
+		addFile(bundle, path)
+	}
+	if requests, err := support.RecentRequests(); err == nil && len(requests) > 0 {
+		var b strings.Builder
+		for _, r := range requests {
+			fmt.Fprintf(&b, "%s %s %s %d %s\n", r.Time.Format(time.RFC3339), r.Method, r.Path, r.Status, r.RequestID)
+		}
+		bundle.Add("failed-requests.log", b.String())
+	}
+
+	if err := review(bundle); err != nil {
+		return err
+	}
+	if len(bundle.Files) == 0 {
+		shared.Logger.Printf("%s All files were excluded, no bundle was created", emoji.Warning)
+		return errors.New("empty bundle")
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to create %s: %v", emoji.ErrorExclamation, file, err))
+		return err
+	}
+	if err := bundle.Write(f); err != nil {
+		f.Close()
+		shared.Logger.Println(styles.Errorf("%s Failed to write the bundle: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+	if err := f.Close(); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to write the bundle: %v", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	shared.Logger.Println(styles.Greenf("\n%s Created %s with %d files", emoji.Check, file, len(bundle.Files)))
+	shared.Logger.Printf("Attach it to your issue at %s", styles.Code("https://github.com/deta/space-cli/issues"))
+	return nil
+}
+
+// addFile adds a file of the user to the bundle, missing files are skipped
+func addFile(bundle *support.Bundle, path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	bundle.Add(filepath.Base(path), string(content))
+}
+
+// review asks the user which files to include, a file can be shown before it's included
+func review(bundle *support.Bundle) error {
+	shared.Logger.Printf("Review the files of the bundle, secrets were redacted as %s:\n", support.Redacted)
+	// files are removed while iterating
+	files := append([]*support.File(nil), bundle.Files...)
+	for _, f := range files {
+		prompt := fmt.Sprintf("%s (%d lines, %d values redacted)", f.Name, strings.Count(f.Content, "\n")+1, f.Redactions)
+		for {
+			choice, err := choose.Run(f.Key(), prompt, reviewInclude, reviewShow, reviewExclude)
+			if err != nil {
+				return err
+			}
+			if choice == reviewShow {
+				shared.Logger.Printf("\n%s\n", styles.Subtle(strings.TrimRight(f.Content, "\n")))
+				continue
+			}
+			if choice == reviewExclude {
+				bundle.Remove(f.Name)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func versions() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "space: %s\n", shared.SpaceVersion)
+	fmt.Fprintf(&b, "platform: %s\n", shared.Platform)
+	fmt.Fprintf(&b, "go: %s %s/%s\n", goruntime.Version(), goruntime.GOOS, goruntime.GOARCH)
+	fmt.Fprintf(&b, "shell: %s\n", os.Getenv("SHELL"))
+	fmt.Fprintf(&b, "term: %s\n", os.Getenv("TERM"))
+	fmt.Fprintf(&b, "interactive: %t\n", shared.IsOutputInteractive())
+	fmt.Fprintf(&b, "accessible: %t\n", styles.Accessible())
+
+	// only the names of the variables, their values may be secrets
+	var names []string
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if strings.HasPrefix(name, "SPACE_") || strings.HasPrefix(name, "DETA_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "environment variables: %s\n", strings.Join(names, ", "))
+	return b.String()
+}
+
+// diagnostics checks the login, the project and the connection to Deta Space
+func diagnostics(projectDir string) string {
+	var b strings.Builder
+	check := func(name string, err error) {
+		if err != nil {
+			fmt.Fprintf(&b, "[fail] %s: %v\n", name, err)
+			return
+		}
+		fmt.Fprintf(&b, "[ok] %s\n", name)
+	}
+
+	if crypt.StateEncrypted() {
+		fmt.Fprintln(&b, "[skip] login: the local state is encrypted")
+	} else {
+		_, err := auth.GetAccessToken()
+		check("login", err)
+	}
+
+	projectID, err := runtime.GetProjectID(projectDir)
+	check("linked project", err)
+	if err == nil {
+		fmt.Fprintf(&b, "  project id: %s\n", projectID)
+	}
+	_, err = spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	check("Spacefile", err)
+	_, err = spaceconfig.Load(projectDir)
+	check(spaceconfig.FileName, err)
+
+	prober := &ping.Prober{Count: 1, Timeout: 5 * time.Second, Client: api.HTTPClient()}
+	endpoints := api.Endpoints()
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		res := prober.Probe(context.Background(), ping.Target{Name: name, URL: endpoints[name]})
+		if res.Err == nil && len(res.Samples) == 0 {
+			res.Err = errors.New("no response")
+		}
+		check(fmt.Sprintf("connection to %s (%s)", name, endpoints[name]), res.Err)
+		if stats, ok := res.Stats(); ok {
+			fmt.Fprintf(&b, "  latency: %s\n", stats.P50)
+		}
+	}
+	return b.String()
+}
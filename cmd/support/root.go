@@ -0,0 +1,19 @@
+package support
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdSupport() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "support",
+		Short: "Get help with problems of the CLI",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(newCmdSupportBundle())
+
+	return cmd
+}
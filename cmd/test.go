@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/gha"
+	"github.com/deta/space/internal/matrix"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+// maxOutputLines of a failed test which are printed
+const maxOutputLines = 20
+
+func newCmdTest() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [flags]",
+		Short: "Build and test your micros locally in containers",
+		Long: fmt.Sprintf(`Build and test your micros locally in containers.
+
+The build commands of a micro and its test are run in a docker container of its engine. Tests are configured in the project config (%s):
+
+  test:
+    backend:
+      run: pytest
+      matrix: [python3.9, python3.11]
+
+With --matrix every micro is tested with all engines of its matrix and a compatibility matrix is reported, to check an engine bump before changing the Spacefile. Engines can also be given as docker images, e.g. python:3.12-rc.`, spaceconfig.FileName),
+		Example: `  space test
+  space test --micro backend --matrix`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckNotEmpty("micro", "run")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			micro, _ := cmd.Flags().GetString("micro")
+			run, _ := cmd.Flags().GetString("run")
+			withMatrix, _ := cmd.Flags().GetBool("matrix")
+
+			jobs, err := testJobs(projectDir, micro, run, withMatrix)
+			if err != nil {
+				return err
+			}
+			if err := runTests(jobs); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project to test")
+	cmd.Flags().StringP("micro", "m", "", "only test this micro")
+	cmd.Flags().String("run", "", "test command, overrides the one of the project config")
+	cmd.Flags().Bool("matrix", false, "test with all engines of the matrix of each micro")
+
+	return cmd
+}
+
+// testJobs returns a job for every micro and engine to test
+func testJobs(projectDir string, microName string, run string, withMatrix bool) ([]*matrix.Job, error) {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s Failed to parse Spacefile: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	var jobs []*matrix.Job
+	for _, micro := range s.Micros {
+		if microName != "" && micro.Name != microName {
+			continue
+		}
+
+		test := config.Tests[micro.Name]
+		if test == nil {
+			test = &spaceconfig.Test{}
+		}
+		command := test.Run
+		if run != "" {
+			command = run
+		}
+		if command == "" {
+			if microName != "" {
+				shared.Logger.Printf("%s Micro %s has no test, add it to %s or use %s", emoji.ErrorExclamation, micro.Name, spaceconfig.FileName, styles.Code("--run"))
+				return nil, errors.New("no test")
+			}
+			continue
+		}
+
+		engines := []string{micro.Engine}
+		if withMatrix && len(test.Matrix) > 0 {
+			engines = test.Matrix
+		}
+
+		src, err := filepath.Abs(filepath.Join(projectDir, micro.Src))
+		if err != nil {
+			return nil, err
+		}
+		for _, engine := range engines {
+			jobs = append(jobs, &matrix.Job{Micro: micro.Name, Engine: engine, Src: src, Commands: micro.Commands, Run: command})
+		}
+	}
+
+	if microName != "" && len(jobs) == 0 {
+		shared.Logger.Printf("%s Micro %s not found in the Spacefile", emoji.ErrorExclamation, microName)
+		return nil, errors.New("micro not found")
+	}
+	if len(jobs) == 0 {
+		shared.Logger.Printf("%s No tests configured, add them to %s or use %s", emoji.ErrorExclamation, spaceconfig.FileName, styles.Code("--run"))
+		return nil, errors.New("no tests")
+	}
+	return jobs, nil
+}
+
+func runTests(jobs []*matrix.Job) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		shared.Logger.Printf("%s Tests are run in containers, please install docker", emoji.ErrorExclamation)
+		return err
+	}
+
+	var results []*matrix.Result
+	failed := 0
+	for _, job := range jobs {
+		shared.Logger.Printf("\n%s Testing %s with %s...", emoji.Package, styles.Code(job.Micro), styles.Blue(job.Engine))
+
+		endGroup := gha.Group(fmt.Sprintf("Test %s with %s", job.Micro, job.Engine))
+		r := matrix.Run(context.Background(), job, nil)
+		endGroup()
+		results = append(results, r)
+
+		if r.Passed() {
+			shared.Logger.Printf("%s Passed in %s", emoji.Check, r.Duration.Round(time.Second))
+			continue
+		}
+
+		failed++
+		shared.Logger.Println(styles.Errorf("%s Failed: %s", emoji.X, r.Err))
+		lines := strings.Split(strings.TrimRight(string(r.Output), "\n"), "\n")
+		if len(lines) > maxOutputLines {
+			lines = lines[len(lines)-maxOutputLines:]
+		}
+		for _, line := range lines {
+			shared.Logger.Printf("  %s", styles.Subtle(line))
+		}
+		gha.Error(&gha.Annotation{Title: "Test failed", Message: fmt.Sprintf("%s failed with %s: %s", job.Micro, job.Engine, r.Err)})
+	}
+
+	shared.Logger.Println()
+	if err := matrix.WriteTable(os.Stderr, results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		shared.Logger.Println(styles.Errorf("\n%s %d of %d tests failed", emoji.ErrorExclamation, failed, len(results)))
+		return fmt.Errorf("%d tests failed", failed)
+	}
+	shared.Logger.Println(styles.Greenf("\n%s All tests passed!", emoji.Sparkles))
+	return nil
+}
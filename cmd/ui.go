@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alessio/shellescape"
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/pkg/components/choose"
+	"github.com/deta/space/pkg/components/confirm"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/palette"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/deta/space/pkg/components/text"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"mvdan.cc/sh/v3/shell"
+)
+
+const (
+	uiRun    = "Run the command"
+	uiCancel = "Cancel"
+)
+
+func newCmdUI() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Find and run commands in a command palette",
+		Long: `Find a command in a palette which filters all commands as you type, e.g. rn finds release notes.
+
+After choosing a command, set its arguments and flags in a form. The palette shows the equivalent shell command before running it, so that you can run it yourself next time.`,
+		Args:     cobra.NoArgs,
+		PostRunE: shared.CheckLatestVersion,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !shared.IsOutputInteractive() {
+				return errors.New("space ui is interactive, run it in a terminal")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := runUI(cmd.Root()); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// paletteCommands returns the commands which do something, group commands which only print their usage are left out
+func paletteCommands(root *cobra.Command) []*cobra.Command {
+	var commands []*cobra.Command
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			if !sub.IsAvailableCommand() || sub.Name() == "ui" {
+				continue
+			}
+			if sub.RunE != nil || sub.Run != nil && !sub.HasSubCommands() {
+				commands = append(commands, sub)
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].CommandPath() < commands[j].CommandPath()
+	})
+	return commands
+}
+
+// commandPath returns the args naming the command, without space
+func commandPath(c *cobra.Command) []string {
+	return strings.Fields(c.CommandPath())[1:]
+}
+
+func runUI(root *cobra.Command) error {
+	commands := paletteCommands(root)
+	items := make([]palette.Item, len(commands))
+	for i, c := range commands {
+		items[i] = palette.Item{Title: strings.Join(commandPath(c), " "), Description: c.Short}
+	}
+
+	n, err := palette.Run("ui.command", &palette.Input{Prompt: "Which command?", Items: items})
+	if err != nil {
+		return err
+	}
+	c := commands[n]
+
+	args, ok, err := fillCommand(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	shared.Logger.Printf("\n%s The command:\n\n  %s\n", emoji.Clipboard, styles.Code(shellescape.QuoteCommand(append([]string{"space"}, args...))))
+	run, err := confirm.Run("ui.run", "Run it?")
+	if err != nil {
+		return err
+	}
+	if !run {
+		return nil
+	}
+	shared.Logger.Println()
+	return Execute(args)
+}
+
+// uiFlags returns the flags of the command which the form offers, the global flags of the root are left out
+func uiFlags(c *cobra.Command) []*pflag.Flag {
+	var flags []*pflag.Flag
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" || f.Name == "help" || c.Root().PersistentFlags().Lookup(f.Name) != nil {
+			return
+		}
+		flags = append(flags, f)
+	})
+	return flags
+}
+
+// usageArgs returns the arguments of the usage line of the command, e.g. [session-id]... of revoke [session-id]...
+func usageArgs(c *cobra.Command) string {
+	var args []string
+	for _, field := range strings.Fields(c.Use)[1:] {
+		if field != "[flags]" {
+			args = append(args, field)
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// fillCommand asks for the arguments and flags of the command, ok is false if the form was cancelled
+func fillCommand(c *cobra.Command) (args []string, ok bool, err error) {
+	args = commandPath(c)
+
+	if usage := usageArgs(c); usage != "" {
+		value, err := text.Run(&text.Input{
+			Key:    "ui.args",
+			Prompt: fmt.Sprintf("Arguments %s", styles.Subtle(usage)),
+			Validator: func(value string) error {
+				_, err := shell.Fields(value, nil)
+				return err
+			},
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		fields, _ := shell.Fields(value, nil)
+		args = append(args, fields...)
+	}
+
+	flags := uiFlags(c)
+	if len(flags) == 0 {
+		return args, true, nil
+	}
+
+	values := make(map[string]string)
+	for {
+		choices := make([]string, 0, len(flags)+2)
+		choices = append(choices, uiRun)
+		for _, f := range flags {
+			choice := fmt.Sprintf("--%s  %s", f.Name, f.Usage)
+			if value, set := values[f.Name]; set {
+				choice = fmt.Sprintf("--%s=%s  %s", f.Name, value, f.Usage)
+			}
+			choices = append(choices, choice)
+		}
+		choices = append(choices, uiCancel)
+
+		choice, err := choose.Run("ui.flag", "Set a flag or run the command", choices...)
+		if err != nil {
+			return nil, false, err
+		}
+		switch choice {
+		case uiRun:
+			return append(args, flagArgs(flags, values)...), true, nil
+		case uiCancel:
+			return nil, false, nil
+		}
+
+		var f *pflag.Flag
+		for i := range flags {
+			if choices[i+1] == choice {
+				f = flags[i]
+			}
+		}
+		value, err := askFlag(f)
+		if err != nil {
+			return nil, false, err
+		}
+		if value == f.DefValue {
+			delete(values, f.Name)
+		} else {
+			values[f.Name] = value
+		}
+	}
+}
+
+func askFlag(f *pflag.Flag) (string, error) {
+	if f.Value.Type() == "bool" {
+		on, err := confirm.Run("ui.flag."+f.Name, fmt.Sprintf("--%s: %s?", f.Name, f.Usage))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprint(on), nil
+	}
+
+	return text.Run(&text.Input{
+		Key:         "ui.flag." + f.Name,
+		Prompt:      fmt.Sprintf("--%s %s", f.Name, styles.Subtle(f.Usage)),
+		Placeholder: f.DefValue,
+	})
+}
+
+// flagArgs returns the args of the flags which differ from their defaults, in the order of the flags
+func flagArgs(flags []*pflag.Flag, values map[string]string) []string {
+	var args []string
+	for _, f := range flags {
+		value, set := values[f.Name]
+		if !set {
+			continue
+		}
+		switch {
+		case f.Value.Type() == "bool" && value == "true":
+			args = append(args, "--"+f.Name)
+		case f.Value.Type() == "bool":
+			args = append(args, "--"+f.Name+"="+value)
+		default:
+			args = append(args, "--"+f.Name, value)
+		}
+	}
+	return args
+}
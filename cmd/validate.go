@@ -15,30 +15,39 @@ import (
 
 func newCmdValidate() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:      "validate [flags]",
-		Short:    "Validate your Spacefile and check for errors",
+		Use:   "validate [flags]",
+		Short: "Validate your Spacefile and check for errors",
+		Long: `Validate your Spacefile and check for errors.
+
+Top level fields starting with x- are ignored and can hold yaml anchors shared by the micros. With --strict, extension fields which don't define an anchor used in the Spacefile are reported as well.`,
+		Example: `  space validate
+  space validate --dir ./my-app --strict`,
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			projectDir, _ := cmd.Flags().GetString("dir")
-			if err := validate(projectDir); err != nil {
-				os.Exit(1)
+			strict, _ := cmd.Flags().GetBool("strict")
+			if err := validate(projectDir, strict); err != nil {
+				return err
 			}
+			return nil
 		},
 		PreRunE: shared.CheckExists("dir"),
 	}
 	cmd.Flags().StringP("dir", "d", "./", "src of project to validate")
+	cmd.Flags().Bool("strict", false, "also reject extension fields without used anchors")
 
 	return cmd
 }
 
-func validate(projectDir string) error {
+func validate(projectDir string, strict bool) error {
 	shared.Logger.Printf("\n%s Validating Spacefile...", emoji.Package)
 
-	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, "Spacefile"))
+	s, err := spacefile.ParseSpacefileWithOptions(filepath.Join(projectDir, "Spacefile"), &spacefile.ParseOptions{Strict: strict})
 	if err != nil {
 		shared.Logger.Println(styles.Errorf("\n%s Detected some issues with your Spacefile. Please fix them before pushing your code.", emoji.ErrorExclamation))
 		shared.Logger.Println()
 		shared.Logger.Println(err.Error())
+		shared.AnnotateSpacefileError(projectDir, err)
 		return err
 	}
 
@@ -47,6 +56,7 @@ func validate(projectDir string) error {
 	} else {
 		if err := spacefile.ValidateIcon(s.Icon); err != nil {
 			shared.Logger.Println(styles.Errorf("\nDetected some issues with your icon. Please fix them before pushing your code."))
+			shared.AnnotateSpacefileError(projectDir, fmt.Errorf("invalid icon: %w", err))
 			switch {
 			case errors.Is(spacefile.ErrInvalidIconType, err):
 				shared.Logger.Println(styles.Error("L Invalid icon type. Please use a 512x512 sized PNG or WebP icon"))
@@ -61,6 +71,38 @@ func validate(projectDir string) error {
 		}
 	}
 
+	shared.WarnDeprecatedEngines(s)
+	warnStaleVendor(projectDir)
+	warnDevOnlyStatic(s)
+	warnSPAWithoutIndex(projectDir, s)
+	warnRouteProblems(s)
+
 	shared.Logger.Println(styles.Greenf("\n%s Spacefile looks good!", emoji.Sparkles))
 	return nil
 }
+
+// warnDevOnlyStatic warns about micros with a static config, which only the proxy of space dev applies
+func warnDevOnlyStatic(s *spacefile.Spacefile) {
+	for _, micro := range s.Micros {
+		if micro.Static == nil {
+			continue
+		}
+		shared.Logger.Printf("\n%s The %s config of micro %s is only applied by %s, deployed apps serve their files without it", emoji.Warning, styles.Code("static"), styles.Code(micro.Name), styles.Code("space dev"))
+	}
+}
+
+// warnSPAWithoutIndex warns about single page apps without an index.html to fall back to, if their files are built
+func warnSPAWithoutIndex(projectDir string, s *spacefile.Spacefile) {
+	for _, micro := range s.Micros {
+		if micro.Static == nil || !micro.Static.SPA {
+			continue
+		}
+		root := filepath.Join(projectDir, micro.Src, micro.Serve)
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, "index.html")); err != nil {
+			shared.Logger.Printf("\n%s Micro %s is a single page app but %s has no index.html to serve for its routes", emoji.Warning, styles.Code(micro.Name), root)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/internal/vendoring"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/spf13/cobra"
+)
+
+func newCmdVendor() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vendor [flags]",
+		Short: "Copy shared packages into your micros",
+		Long: fmt.Sprintf(`Copy shared packages into your micros.
+
+Code shared by several micros is copied into each micro which uses it, so that every micro is built with its own copy. The packages of a micro are configured in the project config (%s), the src is relative to the project root and the dest, which defaults to vendor/<name of src>, is relative to the src of the micro:
+
+  vendor:
+    api:
+      - src: ../shared/utils
+        dest: lib/utils
+
+The dest is replaced by every copy, don't edit the copies. space validate and space push warn about copies which differ from their src, with --check they fail the command instead.`, spaceconfig.FileName),
+		Example: `  space vendor
+  space vendor --micro api
+  space vendor --check`,
+		Args:     cobra.NoArgs,
+		PreRunE:  shared.CheckAll(shared.CheckExists("dir"), shared.CheckNotEmpty("micro")),
+		PostRunE: shared.CheckLatestVersion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, _ := cmd.Flags().GetString("dir")
+			micro, _ := cmd.Flags().GetString("micro")
+			check, _ := cmd.Flags().GetBool("check")
+
+			targets, err := vendorTargets(projectDir, micro)
+			if err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				shared.Logger.Printf("%s No packages to vendor, add them to %s", emoji.ErrorExclamation, spaceconfig.FileName)
+				return shared.ErrReported
+			}
+
+			if check {
+				if stale := staleVendorTargets(targets); len(stale) > 0 {
+					return shared.ErrReported
+				}
+				shared.Logger.Printf("%s Vendored packages are up to date", emoji.Check)
+				return nil
+			}
+
+			for _, t := range targets {
+				n, err := vendoring.Copy(t.src, t.dest)
+				if err != nil {
+					shared.Logger.Printf("%s Failed to vendor %s into micro %s: %s", emoji.ErrorExclamation, t.pkg.Src, t.micro, err)
+					return err
+				}
+				shared.Logger.Printf("%s Copied %d files of %s to %s", emoji.Check, n, styles.Code(t.pkg.Src), styles.Code(t.rel))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "./", "src of project")
+	cmd.Flags().StringP("micro", "m", "", "only vendor the packages of this micro")
+	cmd.Flags().Bool("check", false, "fail if a copy differs from its src instead of copying")
+
+	return cmd
+}
+
+// vendorTarget is a package copied into a micro
+type vendorTarget struct {
+	micro string
+	pkg   *spaceconfig.VendorPackage
+	// src and dest are absolute, rel is dest relative to the project root
+	src  string
+	dest string
+	rel  string
+}
+
+// vendorTargets returns the packages of the micros in the project config, or of one micro if microName is set
+func vendorTargets(projectDir string, microName string) ([]*vendorTarget, error) {
+	s, err := spacefile.ParseSpacefile(filepath.Join(projectDir, spacefile.SpacefileName))
+	if err != nil {
+		shared.Logger.Printf("%s Failed to parse Spacefile: %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil {
+		shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+		return nil, err
+	}
+
+	micros := make(map[string]string, len(s.Micros))
+	for _, micro := range s.Micros {
+		micros[micro.Name] = micro.Src
+	}
+	if microName != "" {
+		if _, ok := micros[microName]; !ok {
+			shared.Logger.Printf("%s Micro %s not found in the Spacefile", emoji.ErrorExclamation, microName)
+			return nil, errors.New("micro not found")
+		}
+	}
+
+	var targets []*vendorTarget
+	for _, micro := range s.Micros {
+		if microName != "" && micro.Name != microName {
+			continue
+		}
+		for _, pkg := range config.Vendor[micro.Name] {
+			t, err := newVendorTarget(projectDir, micro.Name, micro.Src, pkg)
+			if err != nil {
+				shared.Logger.Printf("%s %s", emoji.ErrorExclamation, err)
+				return nil, err
+			}
+			targets = append(targets, t)
+		}
+	}
+	for name := range config.Vendor {
+		if _, ok := micros[name]; !ok {
+			shared.Logger.Printf("%s Micro %s of the vendored packages in %s not found in the Spacefile", emoji.ErrorExclamation, name, spaceconfig.FileName)
+			return nil, errors.New("micro not found")
+		}
+	}
+	return targets, nil
+}
+
+func newVendorTarget(projectDir string, micro string, microSrc string, pkg *spaceconfig.VendorPackage) (*vendorTarget, error) {
+	src, err := filepath.Abs(filepath.Join(projectDir, pkg.Src))
+	if err != nil {
+		return nil, err
+	}
+	dest, err := filepath.Abs(filepath.Join(projectDir, microSrc, pkg.Destination()))
+	if err != nil {
+		return nil, err
+	}
+	// a copy into its own src, or over it, would destroy the src
+	if inDir(src, dest) || inDir(dest, src) {
+		return nil, fmt.Errorf("vendored package %s of micro %s overlaps with its dest %s", pkg.Src, micro, pkg.Destination())
+	}
+	rel := filepath.Join(microSrc, pkg.Destination())
+	return &vendorTarget{micro: micro, pkg: pkg, src: src, dest: dest, rel: filepath.ToSlash(rel)}, nil
+}
+
+func inDir(dir string, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// staleVendorTargets prints and returns the targets whose copy differs from the src
+func staleVendorTargets(targets []*vendorTarget) []*vendorTarget {
+	var stale []*vendorTarget
+	for _, t := range targets {
+		isStale, err := vendoring.Stale(t.src, t.dest)
+		if err != nil {
+			shared.Logger.Printf("%s Failed to compare %s with %s: %s", emoji.ErrorExclamation, t.pkg.Src, t.rel, err)
+			stale = append(stale, t)
+			continue
+		}
+		if isStale {
+			shared.Logger.Printf("%s %s of micro %s differs from %s", emoji.ErrorExclamation, styles.Code(t.rel), t.micro, styles.Code(t.pkg.Src))
+			stale = append(stale, t)
+		}
+	}
+	if len(stale) > 0 {
+		shared.Logger.Printf("Run %s to update the copies.", styles.Code("space vendor"))
+	}
+	return stale
+}
+
+// warnStaleVendor warns about vendored packages of the project whose copy differs from the src
+func warnStaleVendor(projectDir string) {
+	config, err := spaceconfig.Load(projectDir)
+	if err != nil || len(config.Vendor) == 0 {
+		return
+	}
+	// vendorTargets reports its own errors
+	targets, err := vendorTargets(projectDir, "")
+	if err != nil {
+		return
+	}
+	staleVendorTargets(targets)
+}
@@ -12,8 +12,9 @@ func NewCmdVersion(version string, platform string) *cobra.Command {
 		Use:      "version",
 		Short:    "Space CLI version",
 		PostRunE: shared.CheckLatestVersion,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			shared.Logger.Printf("%s%s %s\n", emoji.Pistol, styles.Code(version), platform)
+			return nil
 		},
 	}
 
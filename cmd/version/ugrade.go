@@ -1,95 +1,181 @@
 package version
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 
 	"github.com/deta/space/cmd/shared"
 	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/checksum"
 	detaruntime "github.com/deta/space/internal/runtime"
 	"github.com/deta/space/pkg/components/emoji"
 	"github.com/deta/space/pkg/components/styles"
 	"github.com/spf13/cobra"
 )
 
+const checksumsAsset = "checksums.txt"
+
 func newCmdVersionUpgrade(currentVersion string) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "upgrade",
-		Short:   "Upgrade Space CLI version",
+		Use:   "upgrade",
+		Short: "Upgrade Space CLI version",
+		Long: `Upgrade Space CLI version.
+
+The release archive is verified against the SHA-256 checksums published with the release before the current binary is replaced.`,
 		Example: versionUpgradeExamples(),
-		Run: func(cmd *cobra.Command, args []string) {
-			targetVersion, _ := cmd.Flags().GetString("version")
-			if !cmd.Flags().Changed("version") {
-				latestVersion, err := api.GetLatestCliVersion()
-				if err != nil {
-					shared.Logger.Println(styles.Errorf("%s Failed to get latest version. Please try again.", emoji.X))
-					os.Exit(1)
-				}
-				targetVersion = latestVersion
-			}
-
-			if currentVersion == targetVersion {
-				shared.Logger.Println(styles.Boldf("Space CLI version already %s, no upgrade required", styles.Code(targetVersion)))
-				return
-			}
-
-			switch runtime.GOOS {
-			case "linux", "darwin":
-				err := upgradeUnix(targetVersion)
-				if err != nil {
-					shared.Logger.Println(styles.Errorf("%s Upgrade failed. Please try again.", emoji.X))
-					os.Exit(1)
-				}
-			case "windows":
-				err := upgradeWin(targetVersion)
-				if err != nil {
-					shared.Logger.Println(styles.Errorf("%s Upgrade failed. Please try again.", emoji.X))
-					os.Exit(1)
-				}
-			default:
-				shared.Logger.Println(styles.Errorf("%s Upgrade not supported for %s", emoji.X, runtime.GOOS))
-				os.Exit(1)
-			}
-
-			detaruntime.CacheLatestVersion(targetVersion)
+		PreRunE: shared.ApplyInsecureSkipVerify("insecure-skip-verify"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpgrade(cmd, currentVersion)
 		},
 		Args: cobra.NoArgs,
 	}
 	cmd.Flags().StringP("version", "v", "", "version number")
+	cmd.Flags().Bool("insecure-skip-verify", false, "skip the checksum verification of the downloaded release, not recommended")
 	return cmd
 }
 
-func upgradeUnix(version string) error {
-	curlCmd := exec.Command("curl", "-fsSL", "https://get.deta.dev/space-cli.sh")
-	msg := "Upgrading Space CLI"
-	curlOutput, err := curlCmd.CombinedOutput()
+// runUpgrade upgrades to the version of the version flag, the latest version by default
+func runUpgrade(cmd *cobra.Command, currentVersion string) error {
+	targetVersion, _ := cmd.Flags().GetString("version")
+	if !cmd.Flags().Changed("version") {
+		latestVersion, err := api.GetLatestCliVersion()
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to get latest version. Please try again.", emoji.X))
+			return err
+		}
+		targetVersion = latestVersion
+	}
+
+	if strings.TrimPrefix(currentVersion, "v") == strings.TrimPrefix(targetVersion, "v") {
+		shared.Logger.Println(styles.Boldf("Space CLI version already %s, no upgrade required", styles.Code(targetVersion)))
+		return nil
+	}
+	return Update(targetVersion)
+}
+
+// UpdateSupported reports if releases are published for the current platform, like the install scripts only x64 and
+// arm64 binaries are available
+func UpdateSupported() bool {
+	switch runtime.GOOS {
+	case "linux", "darwin", "windows":
+	default:
+		return false
+	}
+	return runtime.GOARCH == "amd64" || runtime.GOARCH == "arm64"
+}
+
+// Update replaces the binary with the version for the current platform after verifying its checksum
+func Update(version string) error {
+	if !UpdateSupported() {
+		shared.Logger.Println(styles.Errorf("%s Upgrade not supported for %s/%s, only x64 and arm64 binaries are available", emoji.X, runtime.GOOS, runtime.GOARCH))
+		return shared.ErrReported
+	}
+	if err := upgrade(version); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Upgrade failed. Please try again.", emoji.X))
+		return err
+	}
+
+	detaruntime.CacheLatestVersion(strings.TrimPrefix(version, "v"))
+	return nil
+}
+
+// releaseAsset returns the name of the release archive for the current platform, matching the install scripts. The
+// platform has to be checked with UpdateSupported first.
+func releaseAsset() string {
+	arch := runtime.GOARCH
+	if arch == "amd64" {
+		arch = "x86_64"
+	}
+	return fmt.Sprintf("space-%s-%s.zip", runtime.GOOS, arch)
+}
+
+func upgrade(version string) error {
+	if !strings.HasPrefix(version, "v") {
+		version = fmt.Sprintf("v%s", version)
+	}
+	shared.Logger.Printf("Upgrading Space CLI to version %s...\n", styles.Code(version))
+
+	asset := releaseAsset()
+	archive, err := api.DownloadCliReleaseAsset(version, asset)
 	if err != nil {
-		shared.Logger.Println(string(curlOutput))
+		shared.Logger.Println(styles.Errorf("%s %s", emoji.ErrorExclamation, err))
 		return err
 	}
 
-	co := string(curlOutput)
-	shCmd := exec.Command("sh", "-c", co)
-	if version != "" {
-		if !strings.HasPrefix(version, "v") {
-			version = fmt.Sprintf("v%s", version)
+	if !shared.Client.SkipChecksums {
+		raw, err := api.DownloadCliReleaseAsset(version, checksumsAsset)
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to download checksums: %s", emoji.ErrorExclamation, err))
+			return err
 		}
-		msg = fmt.Sprintf("%s to version %s", msg, styles.Code(version))
-		shCmd = exec.Command("sh", "-c", co, "upgrade", version)
+		checksums, err := checksum.Parse(bytes.NewReader(raw))
+		if err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to parse checksums: %s", emoji.ErrorExclamation, err))
+			return err
+		}
+		if err := checksums.Verify(asset, archive); err != nil {
+			shared.Logger.Println(styles.Errorf("%s Failed to verify %s: %s", emoji.ErrorExclamation, asset, err))
+			return err
+		}
+		shared.Logger.Printf("%s Verified checksum of %s", emoji.Check, asset)
 	}
-	shared.Logger.Printf("%s...\n", msg)
 
-	shOutput, err := shCmd.CombinedOutput()
-	shared.Logger.Println(string(shOutput))
+	binary, err := extractBinary(archive)
 	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to extract binary: %s", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to find the current binary: %s", emoji.ErrorExclamation, err))
+		return err
+	}
+
+	// write the new binary next to the current one, so that it can be moved in place atomically
+	newExe := exe + ".new"
+	if err := os.WriteFile(newExe, binary, 0755); err != nil {
+		shared.Logger.Println(styles.Errorf("%s Failed to write binary: %s", emoji.ErrorExclamation, err))
+		return err
+	}
+	if err := replaceExecutable(exe, newExe); err != nil {
+		os.Remove(newExe)
+		shared.Logger.Println(styles.Errorf("%s Failed to replace binary: %s", emoji.ErrorExclamation, err))
 		return err
 	}
+
+	shared.Logger.Println(styles.Greenf("%s Upgraded Space CLI to version %s", emoji.Check, styles.Code(version)))
 	return nil
 }
 
+func extractBinary(archive []byte) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+
+	name := "space"
+	if runtime.GOOS == "windows" {
+		name = "space.exe"
+	}
+
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
 func versionUpgradeExamples() string {
 	return `
 1. space version upgrade
@@ -0,0 +1,29 @@
+package version
+
+import (
+	"github.com/deta/space/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdUpdate is space version upgrade as a command of its own
+func NewCmdUpdate(currentVersion string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update Space CLI to the latest version",
+		Long: `Update Space CLI to the latest version, or the version of --version.
+
+The release archive for the current platform is downloaded and verified against the SHA-256 checksums published with the release before the current binary is replaced.
+
+After a command, the CLI offers to update once per new version found by the version check. The offer is skipped without a terminal, in CI, with --yes or answers and with --skip-version-check.`,
+		Example: `  space update
+  space update --version 0.4.2`,
+		PreRunE: shared.ApplyInsecureSkipVerify("insecure-skip-verify"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpgrade(cmd, currentVersion)
+		},
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().StringP("version", "v", "", "version to update to, defaults to the latest version")
+	cmd.Flags().Bool("insecure-skip-verify", false, "skip the checksum verification of the downloaded release, not recommended")
+	return cmd
+}
@@ -3,6 +3,9 @@
 
 package version
 
-func upgradeWin(version string) error {
-	return nil
+import "os"
+
+// replaceExecutable moves newPath over the executable at path, running binaries can be replaced on unix
+func replaceExecutable(path string, newPath string) error {
+	return os.Rename(newPath, path)
 }
@@ -5,28 +5,22 @@ package version
 
 import (
 	"fmt"
-	"github.com/deta/space/cmd/shared"
-	"github.com/deta/space/pkg/components/styles"
-	"os/exec"
+	"os"
 )
 
-func upgradeWin(version string) error {
-	msg := "Upgrading Space CLI"
-	cmd := "iwr https://get.deta.dev/space-cli.ps1 -useb | iex"
+// replaceExecutable moves newPath over the executable at path, windows doesn't allow to overwrite
+// a running binary but allows to rename it, so the old binary is moved out of the way first
+func replaceExecutable(path string, newPath string) error {
+	oldPath := fmt.Sprintf("%s.old", path)
+	os.Remove(oldPath)
 
-	if version != "" {
-		msg = fmt.Sprintf("%s to version %s", msg, styles.Code(version))
-		cmd = fmt.Sprintf(`$v="%s"; %s`, version, cmd)
+	if err := os.Rename(path, oldPath); err != nil {
+		return err
 	}
-	shared.Logger.Printf("%s...\n", msg)
-
-	pshellCmd := exec.Command("powershell", cmd)
-
-	stdout, err := pshellCmd.CombinedOutput()
-	fmt.Println(string(stdout))
-	if err != nil {
+	if err := os.Rename(newPath, path); err != nil {
+		// restore the old binary
+		os.Rename(oldPath, path)
 		return err
 	}
-
 	return nil
 }
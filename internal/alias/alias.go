@@ -0,0 +1,127 @@
+// Package alias expands the aliases of the config file. An alias is a shortcut for a command with its arguments, e.g.
+// d for dev --open, or for commands chained with &&, e.g. ship for push && release --confirm.
+package alias
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxDepth limits aliases which use other aliases
+const maxDepth = 10
+
+// Parse splits the expansion of an alias into its commands and their arguments. Arguments are separated by spaces
+// and can be quoted with single or double quotes, commands are chained with &&.
+func Parse(expansion string) ([][]string, error) {
+	words, err := split(expansion)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands [][]string
+	var command []string
+	for _, w := range words {
+		if w.quoted {
+			command = append(command, w.value)
+			continue
+		}
+		switch w.value {
+		case "&&":
+			if len(command) == 0 {
+				return nil, errors.New("empty command before &&")
+			}
+			commands = append(commands, command)
+			command = nil
+		case "||", "|", ";", "&":
+			return nil, fmt.Errorf("%s is not supported, chain commands with &&", w.value)
+		default:
+			command = append(command, w.value)
+		}
+	}
+	if len(command) == 0 {
+		if len(commands) > 0 {
+			return nil, errors.New("empty command after &&")
+		}
+		return nil, errors.New("empty alias")
+	}
+	return append(commands, command), nil
+}
+
+// Expand returns the commands to run for args: args itself if its first argument isn't an alias, or the commands of
+// the alias with the remaining args appended to the last one. An alias may start with another alias.
+func Expand(aliases map[string]string, args []string) ([][]string, error) {
+	return expand(aliases, args, 0)
+}
+
+func expand(aliases map[string]string, args []string, depth int) ([][]string, error) {
+	if len(args) == 0 {
+		return [][]string{args}, nil
+	}
+	expansion, ok := aliases[args[0]]
+	if !ok {
+		return [][]string{args}, nil
+	}
+	if depth == maxDepth {
+		return nil, fmt.Errorf("alias %s expands into itself", args[0])
+	}
+
+	commands, err := Parse(expansion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias %s: %w", args[0], err)
+	}
+	last := len(commands) - 1
+	commands[last] = append(commands[last], args[1:]...)
+
+	var result [][]string
+	for _, command := range commands {
+		expanded, err := expand(aliases, command, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expanded...)
+	}
+	return result, nil
+}
+
+type word struct {
+	value  string
+	quoted bool
+}
+
+// split splits s into words like a shell, without variables or globs
+func split(s string) ([]word, error) {
+	var words []word
+	var b strings.Builder
+	inWord, quoted := false, false
+	var quote rune
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord, quoted = r, true, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word{value: b.String(), quoted: quoted})
+				b.Reset()
+				inWord, quoted = false, false
+			}
+		default:
+			b.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quote)
+	}
+	if inWord {
+		words = append(words, word{value: b.String(), quoted: quoted})
+	}
+	return words, nil
+}
@@ -0,0 +1,69 @@
+package alias
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		expansion string
+		expected  [][]string
+		err       bool
+	}{
+		{expansion: "dev --open", expected: [][]string{{"dev", "--open"}}},
+		{expansion: "push && release --confirm", expected: [][]string{{"push"}, {"release", "--confirm"}}},
+		{expansion: `release --notes "first release" && open`, expected: [][]string{{"release", "--notes", "first release"}, {"open"}}},
+		{expansion: `push --tag '&&'`, expected: [][]string{{"push", "--tag", "&&"}}},
+		{expansion: `push --tag ""`, expected: [][]string{{"push", "--tag", ""}}},
+		{expansion: "push || release", err: true},
+		{expansion: "push && ", err: true},
+		{expansion: "&& push", err: true},
+		{expansion: `push --tag "v1`, err: true},
+		{expansion: "  ", err: true},
+	}
+
+	for _, c := range cases {
+		commands, err := Parse(c.expansion)
+		if c.err {
+			assert.Assert(t, err != nil, c.expansion)
+			continue
+		}
+		assert.NilError(t, err, c.expansion)
+		assert.DeepEqual(t, commands, c.expected)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	aliases := map[string]string{
+		"d":    "dev --open",
+		"ship": "push && release --confirm",
+		"go":   "ship --listed",
+		"loop": "loop --again",
+	}
+
+	cases := []struct {
+		args     []string
+		expected [][]string
+		err      bool
+	}{
+		{args: []string{"push", "--tag", "v1"}, expected: [][]string{{"push", "--tag", "v1"}}},
+		{args: []string{}, expected: [][]string{{}}},
+		{args: []string{"d", "--port", "4200"}, expected: [][]string{{"dev", "--open", "--port", "4200"}}},
+		{args: []string{"ship", "--version", "1.0.0"}, expected: [][]string{{"push"}, {"release", "--confirm", "--version", "1.0.0"}}},
+		{args: []string{"go"}, expected: [][]string{{"push"}, {"release", "--confirm", "--listed"}}},
+		{args: []string{"--help", "d"}, expected: [][]string{{"--help", "d"}}},
+		{args: []string{"loop"}, err: true},
+	}
+
+	for _, c := range cases {
+		commands, err := Expand(aliases, c.args)
+		if c.err {
+			assert.Assert(t, err != nil, c.args)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.DeepEqual(t, commands, c.expected)
+	}
+}
@@ -1,28 +1,63 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/checksum"
+	"github.com/deta/space/internal/runtime"
 )
 
 const (
-	spaceRoot = "https://deta.space/api"
-	//spaceRoot = "http://localhost:9900/api"
-	version = "v0"
+	// DefaultSpaceRoot is the root of the Space API
+	DefaultSpaceRoot = "https://deta.space/api"
+	version          = "v0"
 )
 
+// spaceRoot is the root of the Space API used by the client, e.g. http://localhost:9900/api
+var spaceRoot = DefaultSpaceRoot
+
+// SetSpaceRoot changes the root of the Space API, e.g. to a proxy
+func SetSpaceRoot(root string) {
+	spaceRoot = strings.TrimSuffix(root, "/")
+}
+
 var (
 	// ErrProjectNotFound project not found error
 	ErrProjectNotFound = errors.New("project not found")
+	// ErrReleaseVersionExists is returned by CreateRelease if the project already has a release with the version
+	ErrReleaseVersionExists = errors.New("release version already exists")
+	// ErrRevisionNotFound revision not found error
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrReleaseNotFound is returned by FindRelease if the project has no release with the version
+	ErrReleaseNotFound = errors.New("release not found")
+	// ErrCodeNotFound is returned by ReuseCode if no code with the digest was uploaded before
+	ErrCodeNotFound = errors.New("code not found")
+	// ErrDeltaRejected is returned by PushCodeDelta if the server doesn't have the base of the delta or the decoded code
+	// doesn't match its digest
+	ErrDeltaRejected = errors.New("delta rejected")
+	// ErrInvalidReleaseChannel is returned by CreateRelease for channels other than ReleaseChannels
+	ErrInvalidReleaseChannel = errors.New("invalid release channel")
 
 	// Status
 	Complete = "complete"
 )
 
+// Endpoints returns the roots of the APIs used by the client keyed by their name
+func Endpoints() map[string]string {
+	return map[string]string{
+		"Space API": spaceRoot,
+		"Base API":  baseRoot,
+		"Drive API": driveRoot,
+	}
+}
+
 type GetProjectRequest struct {
 	ID string `json:"id"`
 }
@@ -51,11 +86,7 @@ func (c *DetaClient) GetProject(r *GetProjectRequest) (*GetProjectResponse, erro
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to get project: %v", msg)
+		return nil, fmt.Errorf("failed to get project: %w", o.err())
 	}
 
 	var resp GetProjectResponse
@@ -67,8 +98,9 @@ func (c *DetaClient) GetProject(r *GetProjectRequest) (*GetProjectResponse, erro
 }
 
 type CreateProjectRequest struct {
-	Name  string `json:"name"`
-	Alias string `json:"alias"`
+	Name   string `json:"name"`
+	Alias  string `json:"alias"`
+	Region string `json:"region,omitempty"`
 }
 
 type CreateProjectResponse struct {
@@ -92,11 +124,7 @@ func (c *DetaClient) CreateProject(r *CreateProjectRequest) (*CreateProjectRespo
 	}
 
 	if o.Status != 201 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to create project: %v", msg)
+		return nil, fmt.Errorf("failed to create project: %w", o.err())
 	}
 
 	var resp CreateProjectResponse
@@ -107,14 +135,27 @@ func (c *DetaClient) CreateProject(r *CreateProjectRequest) (*CreateProjectRespo
 	return &resp, nil
 }
 
+const (
+	// ReleaseChannelExperimental releases can be installed from the Builder and with the link of the release
+	ReleaseChannelExperimental = "experimental"
+	// ReleaseChannelStable releases are promoted to everyone who installs the app
+	ReleaseChannelStable = "stable"
+)
+
+// ReleaseChannels are the channels a release can be created in, the default first
+var ReleaseChannels = []string{ReleaseChannelExperimental, ReleaseChannelStable}
+
 type CreateReleaseRequest struct {
-	RevisionID    string `json:"revision_id"`
-	AppID         string `json:"app_id"`
-	Version       string `json:"version"`
-	ReleaseNotes  string `json:"release_notes"`
-	Description   string `json:"description"`
+	RevisionID   string `json:"revision_id"`
+	AppID        string `json:"app_id"`
+	Version      string `json:"version"`
+	ReleaseNotes string `json:"release_notes"`
+	Description  string `json:"description"`
+	// Channel is one of ReleaseChannels, defaults to ReleaseChannelExperimental
 	Channel       string `json:"channel"`
 	DiscoveryList bool   `json:"discovery_list"`
+	// ApprovedBy is the handle of the person who approved the release, recorded by the server
+	ApprovedBy string `json:"approved_by,omitempty"`
 }
 
 type CreateReleaseResponse struct {
@@ -122,12 +163,22 @@ type CreateReleaseResponse struct {
 }
 
 func (c *DetaClient) CreateRelease(r *CreateReleaseRequest) (*CreateReleaseResponse, error) {
+	switch r.Channel {
+	case "":
+		r.Channel = ReleaseChannelExperimental
+	case ReleaseChannelExperimental, ReleaseChannelStable:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidReleaseChannel, r.Channel)
+	}
+
 	i := &requestInput{
 		Root:      spaceRoot,
 		Path:      fmt.Sprintf("/%s/promotions", version),
 		Method:    "POST",
 		NeedsAuth: true,
 		Body:      r,
+		// a retry after a lost response mustn't create the release twice
+		IdempotencyKey: newIdempotencyKey(),
 	}
 
 	o, err := c.request(i)
@@ -135,12 +186,11 @@ func (c *DetaClient) CreateRelease(r *CreateReleaseRequest) (*CreateReleaseRespo
 		return nil, err
 	}
 
+	if o.Status == 409 {
+		return nil, fmt.Errorf("%w: %s", ErrReleaseVersionExists, r.Version)
+	}
 	if o.Status != 202 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to create release: %v", msg)
+		return nil, fmt.Errorf("failed to create release: %w", o.err())
 	}
 
 	var resp CreateReleaseResponse
@@ -170,11 +220,7 @@ func (c *DetaClient) GetReleaseLogs(r *GetReleaseLogsRequest) (io.ReadCloser, er
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to create release: %v", msg)
+		return nil, fmt.Errorf("failed to create release: %w", o.err())
 	}
 	return o.BodyReadCloser, nil
 }
@@ -187,6 +233,23 @@ type Revision struct {
 	ID        string `json:"id"`
 	Tag       string `json:"tag"`
 	CreatedAt string `json:"created_at"`
+	// Labels are attached at push time, e.g. the commit and the CI run
+	Labels map[string]string `json:"labels,omitempty"`
+	// Digest is the checksum of the pushed code
+	Digest    string `json:"digest,omitempty"`
+	FileCount int    `json:"file_count,omitempty"`
+	// Size of the pushed code in bytes
+	Size            int64          `json:"size,omitempty"`
+	BuildStartedAt  string         `json:"build_started_at,omitempty"`
+	BuildFinishedAt string         `json:"build_finished_at,omitempty"`
+	Engines         []*MicroEngine `json:"engines,omitempty"`
+}
+
+// MicroEngine is the engine a micro of a revision was built with
+type MicroEngine struct {
+	Micro   string `json:"micro"`
+	Engine  string `json:"engine"`
+	Version string `json:"version,omitempty"`
 }
 
 type Page struct {
@@ -218,11 +281,7 @@ func (c *DetaClient) GetRevisions(r *GetRevisionsRequest) (*GetRevisionsResponse
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to fetch revisions: %v", msg)
+		return nil, fmt.Errorf("failed to fetch revisions: %w", o.err())
 	}
 
 	var fetchResp fetchRevisionsResponse
@@ -239,9 +298,108 @@ func (c *DetaClient) GetRevisions(r *GetRevisionsRequest) (*GetRevisionsResponse
 	return &GetRevisionsResponse{Revisions: revisions}, nil
 }
 
+// MaxRevisionsPageSize is the most revisions ListRevisions returns at once
+const MaxRevisionsPageSize = 100
+
+type ListRevisionsRequest struct {
+	AppID string
+	// Limit is the size of the page, defaults to 20 and is capped at MaxRevisionsPageSize
+	Limit int
+	// Last is the cursor of the page, the NextPage of the previous page, empty for the first page
+	Last string
+}
+
+type ListRevisionsResponse struct {
+	Revisions []*Revision `json:"revisions"`
+	// NextPage is the cursor of the next page, empty on the last page
+	NextPage string `json:"next_page,omitempty"`
+}
+
+// ListRevisions lists a page of the revisions of a project, the latest revision first
+func (c *DetaClient) ListRevisions(r *ListRevisionsRequest) (*ListRevisionsResponse, error) {
+	limit := r.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > MaxRevisionsPageSize {
+		limit = MaxRevisionsPageSize
+	}
+	query := map[string]string{"limit": fmt.Sprint(limit)}
+	if r.Last != "" {
+		query["last"] = r.Last
+	}
+
+	o, err := c.request(&requestInput{
+		Root:        spaceRoot,
+		Path:        fmt.Sprintf("/%s/apps/%s/revisions", version, r.AppID),
+		Method:      "GET",
+		NeedsAuth:   true,
+		QueryParams: query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to list revisions: %w", o.err())
+	}
+
+	var fetchResp struct {
+		Revisions []*Revision `json:"revisions"`
+		Page      *Page       `json:"page"`
+	}
+	if err := json.Unmarshal(o.Body, &fetchResp); err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	resp := &ListRevisionsResponse{Revisions: fetchResp.Revisions}
+	if fetchResp.Page != nil && fetchResp.Page.Last != nil {
+		resp.NextPage = *fetchResp.Page.Last
+	}
+	return resp, nil
+}
+
+type GetRevisionRequest struct {
+	AppID string
+	ID    string
+}
+
+// GetRevision returns a revision of a project by its id
+func (c *DetaClient) GetRevision(r *GetRevisionRequest) (*Revision, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/apps/%s/revisions/%s", version, r.AppID, r.ID),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrRevisionNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get revision: %w", o.err())
+	}
+
+	var resp Revision
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return &resp, nil
+}
+
 type CreateBuildRequest struct {
-	AppID string `json:"app_id"`
-	Tag   string `json:"tag"`
+	AppID  string            `json:"app_id"`
+	Tag    string            `json:"tag"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Prebuilt builds skip the build commands of the micros
+	Prebuilt bool `json:"prebuilt,omitempty"`
+	// BuildArgs are environment variables of the build commands keyed by the name of the micro
+	BuildArgs map[string]map[string]string `json:"build_args,omitempty"`
 }
 
 type CreateBuildResponse struct {
@@ -263,11 +421,7 @@ func (c *DetaClient) CreateBuild(r *CreateBuildRequest) (*CreateBuildResponse, e
 	}
 
 	if o.Status != 202 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to create build request: %v", msg)
+		return nil, fmt.Errorf("failed to create build request: %w", o.err())
 	}
 
 	var resp CreateBuildResponse
@@ -307,8 +461,7 @@ func (c *DetaClient) PushSpacefile(r *PushSpacefileRequest) (*PushSpacefileRespo
 		return nil, err
 	}
 	if !(o.Status >= 200 && o.Status <= 299) {
-		msg := o.Error.Detail
-		return nil, fmt.Errorf("failed to push spacefile file, %v", msg)
+		return nil, fmt.Errorf("failed to push spacefile file, %w", o.err())
 	}
 
 	var resp PushSpacefileResponse
@@ -350,8 +503,7 @@ func (c *DetaClient) PushIcon(r *PushIconRequest) (*PushIconResponse, error) {
 	}
 
 	if !(o.Status >= 200 && o.Status <= 299) {
-		msg := o.Error.Detail
-		return nil, fmt.Errorf("failed to push icon, %v", msg)
+		return nil, fmt.Errorf("failed to push icon, %w", o.err())
 	}
 
 	var resp PushIconResponse
@@ -390,8 +542,7 @@ func (c *DetaClient) PushDiscoveryFile(r *PushDiscoveryFileRequest) (*PushDiscov
 	}
 
 	if !(o.Status >= 200 && o.Status <= 299) {
-		msg := o.Error.Detail
-		return nil, fmt.Errorf("failed to push discovery file, %v", msg)
+		return nil, fmt.Errorf("failed to push discovery file, %w", o.err())
 	}
 
 	var resp PushDiscoveryFileResponse
@@ -406,6 +557,10 @@ func (c *DetaClient) PushDiscoveryFile(r *PushDiscoveryFileRequest) (*PushDiscov
 type PushCodeRequest struct {
 	BuildID    string `json:"build_id"`
 	ZippedCode []byte `json:"zipped_code"`
+	// ZippedCodeFile is streamed instead of ZippedCode if set
+	ZippedCodeFile io.ReadSeeker `json:"-"`
+	// Digest is the sha256 digest of the content of the archive, the code can be reused by later builds with it
+	Digest string `json:"-"`
 }
 
 // PushCodeResponse push code response
@@ -421,17 +576,20 @@ func (c *DetaClient) PushCode(r *PushCodeRequest) (*PushCodeResponse, error) {
 		Method:      "POST",
 		Headers:     make(map[string]string),
 		Body:        r.ZippedCode,
+		BodyFile:    r.ZippedCodeFile,
 		NeedsAuth:   true,
 		ContentType: "application/zip",
 	}
+	if r.Digest != "" {
+		i.Headers["X-Space-Code-Digest"] = "sha256:" + r.Digest
+	}
 
 	o, err := c.request(i)
 	if err != nil {
 		return nil, err
 	}
 	if !(o.Status >= 200 && o.Status <= 299) {
-		msg := o.Error.Detail
-		return nil, fmt.Errorf("failed to push code, %v", msg)
+		return nil, fmt.Errorf("failed to push code, %w", o.err())
 	}
 
 	var resp PushCodeResponse
@@ -443,6 +601,87 @@ func (c *DetaClient) PushCode(r *PushCodeRequest) (*PushCodeResponse, error) {
 	return &resp, nil
 }
 
+// PushCodeDeltaRequest push code delta request
+type PushCodeDeltaRequest struct {
+	BuildID string
+	// BaseDigest is the digest of the code pushed before which the delta is encoded against
+	BaseDigest string
+	// Digest is the digest of the code the delta decodes to
+	Digest    string
+	DeltaFile io.ReadSeeker
+}
+
+// PushCodeDelta pushes the code as delta against code pushed before, it returns ErrDeltaRejected if the server can't
+// apply the delta and the code has to be pushed with PushCode
+func (c *DetaClient) PushCodeDelta(r *PushCodeDeltaRequest) (*PushCodeResponse, error) {
+	i := &requestInput{
+		Root:   spaceRoot,
+		Path:   fmt.Sprintf("/%s/builds/%s/code/delta", version, r.BuildID),
+		Method: "POST",
+		Headers: map[string]string{
+			"X-Space-Base-Digest": "sha256:" + r.BaseDigest,
+			"X-Space-Code-Digest": "sha256:" + r.Digest,
+		},
+		BodyFile:    r.DeltaFile,
+		NeedsAuth:   true,
+		ContentType: "application/vnd.space.delta",
+	}
+
+	o, err := c.request(i)
+	if err != nil {
+		return nil, err
+	}
+	switch o.Status {
+	case 404, 409, 412, 422:
+		return nil, ErrDeltaRejected
+	}
+	if !(o.Status >= 200 && o.Status <= 299) {
+		return nil, fmt.Errorf("failed to push code delta, %w", o.err())
+	}
+
+	var resp PushCodeResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to push code delta, %w", err)
+	}
+	return &resp, nil
+}
+
+// ReuseCodeRequest reuse code request
+type ReuseCodeRequest struct {
+	BuildID string `json:"-"`
+	// Digest is the digest of code pushed before by PushCode
+	Digest string `json:"-"`
+}
+
+// ReuseCode uses the code uploaded before with the digest for the build instead of pushing it again, it returns
+// ErrCodeNotFound if there is no such code
+func (c *DetaClient) ReuseCode(r *ReuseCodeRequest) (*PushCodeResponse, error) {
+	i := &requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/builds/%s/code/reuse", version, r.BuildID),
+		Method:    "POST",
+		Body:      map[string]string{"digest": "sha256:" + r.Digest},
+		NeedsAuth: true,
+	}
+
+	o, err := c.request(i)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status == 404 {
+		return nil, ErrCodeNotFound
+	}
+	if !(o.Status >= 200 && o.Status <= 299) {
+		return nil, fmt.Errorf("failed to reuse code, %w", o.err())
+	}
+
+	var resp PushCodeResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to reuse code, %w", err)
+	}
+	return &resp, nil
+}
+
 type GetBuildLogsRequest struct {
 	BuildID string `json:"build_id"`
 }
@@ -462,11 +701,53 @@ func (c *DetaClient) GetBuildLogs(r *GetBuildLogsRequest) (io.ReadCloser, error)
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to get build logs: %v", msg)
+		return nil, fmt.Errorf("failed to get build logs: %w", o.err())
+	}
+	return o.BodyReadCloser, nil
+}
+
+type GetAppLogsRequest struct {
+	AppID string
+	// Micro only returns the logs of the micro, all micros if empty
+	Micro string
+	// Since only returns the logs after the time, the api's default if zero
+	Since time.Time
+	// Follow keeps the stream open and streams new logs
+	Follow bool
+}
+
+// GetAppLogs returns the runtime logs of the micros of an app as json lines
+func (c *DetaClient) GetAppLogs(r *GetAppLogsRequest) (io.ReadCloser, error) {
+	query := map[string]string{}
+	if r.Micro != "" {
+		query["micro"] = r.Micro
+	}
+	if !r.Since.IsZero() {
+		query["since"] = r.Since.UTC().Format(time.RFC3339)
+	}
+	if r.Follow {
+		query["follow"] = "true"
+	}
+
+	i := &requestInput{
+		Root:             spaceRoot,
+		Path:             fmt.Sprintf("/%s/apps/%s/logs", version, r.AppID),
+		Method:           "GET",
+		NeedsAuth:        true,
+		QueryParams:      query,
+		ReturnReadCloser: true,
+	}
+
+	o, err := c.request(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get app logs: %w", o.err())
 	}
 	return o.BodyReadCloser, nil
 }
@@ -495,8 +776,7 @@ func (c *DetaClient) GetBuild(r *GetBuildRequest) (*GetBuildResponse, error) {
 	}
 
 	if !(o.Status >= 200 && o.Status <= 299) {
-		msg := o.Error.Detail
-		return nil, fmt.Errorf("failed to get build status, %v", msg)
+		return nil, fmt.Errorf("failed to get build status, %w", o.err())
 	}
 
 	var resp GetBuildResponse
@@ -516,6 +796,14 @@ type GetReleasePromotionResponse struct {
 	ID      string `json:"id" db:"id"`
 	Status  string `json:"status" db:"status"`
 	Channel string `json:"channel" db:"channel"`
+	// Events are the states the promotion went through, oldest first
+	Events []*PromotionEvent `json:"events,omitempty" db:"-"`
+}
+
+// PromotionEvent is a state of a promotion and when it was reached
+type PromotionEvent struct {
+	Status string `json:"status"`
+	At     string `json:"at"`
 }
 
 func (c *DetaClient) GetReleasePromotion(r *GetReleasePromotionRequest) (*GetReleasePromotionResponse, error) {
@@ -536,8 +824,7 @@ func (c *DetaClient) GetReleasePromotion(r *GetReleasePromotionRequest) (*GetRel
 	}
 
 	if !(o.Status >= 200 && o.Status <= 299) {
-		msg := o.Error.Detail
-		return nil, fmt.Errorf("failed to get build status, %v", msg)
+		return nil, fmt.Errorf("failed to get build status, %w", o.err())
 	}
 
 	var resp GetReleasePromotionResponse
@@ -573,11 +860,7 @@ func (c *DetaClient) GetPromotionByRevision(r *GetPromotionRequest) (*GetRelease
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to fetch promotions: %v", msg)
+		return nil, fmt.Errorf("failed to fetch promotions: %w", o.err())
 	}
 
 	var fetchResp FetchPromotionResponse
@@ -591,13 +874,142 @@ func (c *DetaClient) GetPromotionByRevision(r *GetPromotionRequest) (*GetRelease
 		promotion = &fetchResp.Promotions[0]
 	}
 
-	if promotion.Channel != "development" {
+	if promotion == nil || promotion.Channel != "development" {
 		return nil, fmt.Errorf("failed to fetch promotions for revision: %s, no development promotion found", r.RevisionID)
 	}
 
 	return promotion, nil
 }
 
+// Release is a release of a project
+type Release struct {
+	ID            string `json:"id"`
+	Version       string `json:"version"`
+	ReleaseNotes  string `json:"release_notes"`
+	Channel       string `json:"channel"`
+	DiscoveryList bool   `json:"discovery_list"`
+	Status        string `json:"status"`
+	CreatedAt     string `json:"created_at"`
+	RevisionID    string `json:"revision_id,omitempty"`
+	// Installs is the number of installations of the release, nil if it isn't known
+	Installs *int `json:"installs,omitempty"`
+}
+
+type ListReleasesRequest struct {
+	AppID string
+	// Limit is the number of releases to list, defaults to 10
+	Limit int
+	// Last is the cursor of the page to list, the Last of the previous page
+	Last string
+}
+
+type fetchReleasesResponse struct {
+	Releases []*Release `json:"releases"`
+	Page     *Page      `json:"page"`
+}
+
+type ListReleasesResponse struct {
+	Releases []*Release
+	// Last is the cursor of the next page, empty on the last page
+	Last string
+}
+
+// ListReleases lists the latest releases of a project, the latest release first
+func (c *DetaClient) ListReleases(r *ListReleasesRequest) (*ListReleasesResponse, error) {
+	limit := r.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	query := map[string]string{"limit": fmt.Sprint(limit)}
+	if r.Last != "" {
+		query["last"] = r.Last
+	}
+	i := &requestInput{
+		Root:        spaceRoot,
+		Path:        fmt.Sprintf("/%s/apps/%s/releases", version, r.AppID),
+		Method:      "GET",
+		NeedsAuth:   true,
+		QueryParams: query,
+	}
+
+	o, err := c.request(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to list releases: %w", o.err())
+	}
+
+	var fetchResp fetchReleasesResponse
+	err = json.Unmarshal(o.Body, &fetchResp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	resp := &ListReleasesResponse{Releases: fetchResp.Releases}
+	if fetchResp.Page != nil && fetchResp.Page.Last != nil {
+		resp.Last = *fetchResp.Page.Last
+	}
+	return resp, nil
+}
+
+// FindRelease pages through the releases of a project until it finds the release of the version
+func (c *DetaClient) FindRelease(appID string, releaseVersion string) (*Release, error) {
+	var last string
+	for {
+		r, err := c.ListReleases(&ListReleasesRequest{AppID: appID, Limit: 100, Last: last})
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range r.Releases {
+			if release.Version == releaseVersion {
+				return release, nil
+			}
+		}
+		if r.Last == "" || len(r.Releases) == 0 {
+			return nil, ErrReleaseNotFound
+		}
+		last = r.Last
+	}
+}
+
+// UpdateReleaseRequest changes an existing release, fields which are nil are left as they are
+type UpdateReleaseRequest struct {
+	ID            string  `json:"-"`
+	ReleaseNotes  *string `json:"release_notes,omitempty"`
+	DiscoveryList *bool   `json:"discovery_list,omitempty"`
+}
+
+// UpdateRelease changes the notes or the discovery listing of an existing release
+func (c *DetaClient) UpdateRelease(r *UpdateReleaseRequest) (*Release, error) {
+	i := &requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/promotions/%s", version, r.ID),
+		Method:    "PATCH",
+		NeedsAuth: true,
+		Body:      r,
+	}
+
+	o, err := c.request(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to update release: %w", o.err())
+	}
+
+	var resp Release
+	err = json.Unmarshal(o.Body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update release: %w", err)
+	}
+	return &resp, nil
+}
+
 type GetInstallationByReleaseRequest struct {
 	ReleaseID string `json:"release_id"`
 }
@@ -629,11 +1041,7 @@ func (c *DetaClient) GetInstallationByRelease(r *GetInstallationByReleaseRequest
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to fetch installations: %v", msg)
+		return nil, fmt.Errorf("failed to fetch installations: %w", o.err())
 	}
 
 	var fetchResp FetchInstallationsResponse
@@ -668,11 +1076,7 @@ func (c *DetaClient) GetInstallation(r *GetInstallationRequest) (*Installation,
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to fetch installation: %v", msg)
+		return nil, fmt.Errorf("failed to fetch installation: %w", o.err())
 	}
 
 	var resp Installation
@@ -703,11 +1107,7 @@ func (c *DetaClient) GetInstallationLogs(r *GetInstallationLogsRequest) (io.Read
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to get installation logs: %v", msg)
+		return nil, fmt.Errorf("failed to get installation logs: %w", o.err())
 	}
 	return o.BodyReadCloser, nil
 }
@@ -740,8 +1140,7 @@ func (c *DetaClient) GetSpace(r *GetSpaceRequest) (*GetSpaceResponse, error) {
 	}
 
 	if !(o.Status >= 200 && o.Status <= 299) {
-		msg := o.Error.Detail
-		return nil, fmt.Errorf("failed to get space, %s", msg)
+		return nil, fmt.Errorf("failed to get space, %w", o.err())
 	}
 
 	var resp GetSpaceResponse
@@ -777,11 +1176,7 @@ func (c *DetaClient) CreateProjectKey(AppID string, r *CreateProjectKeyRequest)
 	}
 
 	if o.Status != 201 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to create project key: %v", msg)
+		return nil, fmt.Errorf("failed to create project key: %w", o.err())
 	}
 
 	var resp CreateProjectKeyResponse
@@ -813,11 +1208,7 @@ func (c *DetaClient) ListProjectKeys(AppID string) (*ListProjectResponse, error)
 	}
 
 	if o.Status != 200 {
-		msg := o.Error.Detail
-		if msg == "" && len(o.Error.Errors) > 0 {
-			msg = o.Error.Errors[0]
-		}
-		return nil, fmt.Errorf("failed to create project key: %v", msg)
+		return nil, fmt.Errorf("failed to create project key: %w", o.err())
 	}
 
 	var resp ListProjectResponse
@@ -828,3 +1219,252 @@ func (c *DetaClient) ListProjectKeys(AppID string) (*ListProjectResponse, error)
 
 	return &resp, nil
 }
+
+type GetRevisionCodeRequest struct {
+	RevisionID string `json:"revision_id"`
+	// Digest is the digest of the revision, the code is verified against it if the response has no checksum
+	Digest string `json:"-"`
+}
+
+// GetRevisionCode downloads the zipped source code of a revision, verified against the checksum of the response or
+// else the digest of the revision
+func (c *DetaClient) GetRevisionCode(r *GetRevisionCodeRequest) (io.ReadCloser, error) {
+	i := &requestInput{
+		Root:             spaceRoot,
+		Path:             fmt.Sprintf("/%s/builds/%s/code", version, r.RevisionID),
+		Method:           "GET",
+		NeedsAuth:        true,
+		ReturnReadCloser: true,
+	}
+
+	o, err := c.request(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to download revision code: %w", o.err())
+	}
+
+	if c.SkipChecksums {
+		return o.BodyReadCloser, nil
+	}
+	if sum := o.Header.Get(ChecksumHeader); sum != "" {
+		return checksum.NewVerifyingReader(o.BodyReadCloser, sum), nil
+	}
+	if r.Digest == "" {
+		// there is nothing to verify the code against
+		return o.BodyReadCloser, nil
+	}
+
+	defer o.BodyReadCloser.Close()
+	code, err := io.ReadAll(o.BodyReadCloser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download revision code: %w", err)
+	}
+	if err := runtime.VerifyArchiveDigest(code, r.Digest); err != nil {
+		return nil, fmt.Errorf("%w, pass --insecure-skip-verify to download it without verification", err)
+	}
+	return io.NopCloser(bytes.NewReader(code)), nil
+}
+
+type Region struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Endpoint string `json:"endpoint"`
+	Default  bool   `json:"default"`
+}
+
+type ListRegionsResponse struct {
+	Regions []*Region `json:"regions"`
+}
+
+// ListRegions lists the regions projects can be created in
+func (c *DetaClient) ListRegions() (*ListRegionsResponse, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/regions", version),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to list regions: %w", o.err())
+	}
+
+	var resp ListRegionsResponse
+	err = json.Unmarshal(o.Body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+	return &resp, nil
+}
+
+type GetEdgesRequest struct {
+	AppID string `json:"app_id"`
+}
+
+type Edge struct {
+	Region   string `json:"region"`
+	Location string `json:"location"`
+	Status   string `json:"status"`
+}
+
+type GetEdgesResponse struct {
+	ReleaseID string  `json:"release_id"`
+	Edges     []*Edge `json:"edges"`
+}
+
+// GetEdges lists the edges serving the latest release of a project
+func (c *DetaClient) GetEdges(r *GetEdgesRequest) (*GetEdgesResponse, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/apps/%s/edges", version, r.AppID),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get edges: %w", o.err())
+	}
+
+	var resp GetEdgesResponse
+	err = json.Unmarshal(o.Body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edges: %w", err)
+	}
+	return &resp, nil
+}
+
+type Project struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Alias     string `json:"alias"`
+	CreatedAt string `json:"created_at"`
+}
+
+type fetchProjectsResponse struct {
+	Projects []*Project `json:"apps"`
+	Page     *Page      `json:"page"`
+}
+
+type ListProjectsResponse struct {
+	Projects []*Project `json:"projects"`
+}
+
+// ListProjects lists all projects of the user
+func (c *DetaClient) ListProjects() (*ListProjectsResponse, error) {
+	var projects []*Project
+	var last string
+	for {
+		query := map[string]string{"limit": "100"}
+		if last != "" {
+			query["last"] = last
+		}
+
+		o, err := c.request(&requestInput{
+			Root:        spaceRoot,
+			Path:        fmt.Sprintf("/%s/apps", version),
+			Method:      "GET",
+			NeedsAuth:   true,
+			QueryParams: query,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if o.Status != 200 {
+			return nil, fmt.Errorf("failed to list projects: %w", o.err())
+		}
+
+		var fetchResp fetchProjectsResponse
+		err = json.Unmarshal(o.Body, &fetchResp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+		projects = append(projects, fetchResp.Projects...)
+
+		if fetchResp.Page == nil || fetchResp.Page.Last == nil || *fetchResp.Page.Last == "" {
+			return &ListProjectsResponse{Projects: projects}, nil
+		}
+		last = *fetchResp.Page.Last
+	}
+}
+
+type DeleteProjectRequest struct {
+	ID string `json:"id"`
+}
+
+// DeleteProject deletes a project with all its revisions, releases and builder instance
+func (c *DetaClient) DeleteProject(r *DeleteProjectRequest) error {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/apps/%s", version, r.ID),
+		Method:    "DELETE",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.Status == 404 {
+		return ErrProjectNotFound
+	}
+
+	if !(o.Status >= 200 && o.Status <= 299) {
+		return fmt.Errorf("failed to delete project: %w", o.err())
+	}
+	return nil
+}
+
+const (
+	EngineSupported  = "supported"
+	EngineDeprecated = "deprecated"
+	EngineSunset     = "sunset"
+)
+
+type Engine struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// SunsetAt is the date after which deprecated engines stop working
+	SunsetAt    string `json:"sunset_at,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+type ListEnginesResponse struct {
+	Engines []*Engine `json:"engines"`
+}
+
+// ListEngines lists the engines of micros with their support status
+func (c *DetaClient) ListEngines() (*ListEnginesResponse, error) {
+	o, err := c.request(&requestInput{
+		Root:   spaceRoot,
+		Path:   fmt.Sprintf("/%s/engines", version),
+		Method: "GET",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to list engines: %w", o.err())
+	}
+
+	var resp ListEnginesResponse
+	err = json.Unmarshal(o.Body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list engines: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	baseRoot = "https://database.deta.sh/v1"
+
+	// MaxBasePutItems maximum number of items in a single put request
+	MaxBasePutItems = 25
+)
+
+// BaseRef identifies a base of a project, authenticated with a project key
+type BaseRef struct {
+	ProjectKey string
+	Base       string
+}
+
+func (b *BaseRef) root() string {
+	projectID := strings.Split(b.ProjectKey, "_")[0]
+	return fmt.Sprintf("%s/%s/%s", baseRoot, projectID, b.Base)
+}
+
+func (b *BaseRef) headers() map[string]string {
+	return map[string]string{driveKeyHeader: b.ProjectKey}
+}
+
+// ErrBaseItemNotFound is returned by GetBaseItem if the base has no item with the key
+var ErrBaseItemNotFound = errors.New("base item not found")
+
+// BaseItem a single item of a base, items always have a "key" field
+type BaseItem map[string]interface{}
+
+// GetBaseItem fetches the item with the key
+func (c *DetaClient) GetBaseItem(b *BaseRef, key string) (BaseItem, error) {
+	o, err := c.request(&requestInput{
+		Root:    b.root(),
+		Path:    "/items/" + url.PathEscape(key),
+		Method:  "GET",
+		Headers: b.headers(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if o.Status == 404 {
+		return nil, ErrBaseItemNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get item %s of base %s: %v", key, b.Base, driveErrorMsg(o))
+	}
+
+	var item BaseItem
+	if err := json.Unmarshal(o.Body, &item); err != nil {
+		return nil, fmt.Errorf("failed to get item %s of base %s: %w", key, b.Base, err)
+	}
+	return item, nil
+}
+
+type queryBaseRequest struct {
+	Query []interface{} `json:"query"`
+	Limit int           `json:"limit"`
+	Last  string        `json:"last,omitempty"`
+}
+
+type queryBaseResponse struct {
+	Paging struct {
+		Size int    `json:"size"`
+		Last string `json:"last"`
+	} `json:"paging"`
+	Items []BaseItem `json:"items"`
+}
+
+// FetchBaseItems fetches every item of a base
+func (c *DetaClient) FetchBaseItems(b *BaseRef) ([]BaseItem, error) {
+	var items []BaseItem
+	last := ""
+	for {
+		o, err := c.request(&requestInput{
+			Root:    b.root(),
+			Path:    "/query",
+			Method:  "POST",
+			Headers: b.headers(),
+			Body:    &queryBaseRequest{Query: []interface{}{}, Limit: 1000, Last: last},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if o.Status != 200 {
+			return nil, fmt.Errorf("failed to fetch items of base %s: %v", b.Base, driveErrorMsg(o))
+		}
+
+		var resp queryBaseResponse
+		if err := json.Unmarshal(o.Body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to fetch items of base %s: %w", b.Base, err)
+		}
+		items = append(items, resp.Items...)
+
+		if resp.Paging.Last == "" {
+			return items, nil
+		}
+		last = resp.Paging.Last
+	}
+}
+
+type putBaseItemsRequest struct {
+	Items []BaseItem `json:"items"`
+}
+
+type putBaseItemsResponse struct {
+	Failed struct {
+		Items []BaseItem `json:"items"`
+	} `json:"failed"`
+}
+
+// PutBaseItems stores items in a base, overwriting items with the same key
+func (c *DetaClient) PutBaseItems(b *BaseRef, items []BaseItem) error {
+	for start := 0; start < len(items); start += MaxBasePutItems {
+		end := start + MaxBasePutItems
+		if end > len(items) {
+			end = len(items)
+		}
+
+		o, err := c.request(&requestInput{
+			Root:    b.root(),
+			Path:    "/items",
+			Method:  "PUT",
+			Headers: b.headers(),
+			Body:    &putBaseItemsRequest{Items: items[start:end]},
+		})
+		if err != nil {
+			return err
+		}
+		if o.Status != 207 && o.Status != 200 {
+			return fmt.Errorf("failed to put items into base %s: %v", b.Base, driveErrorMsg(o))
+		}
+
+		var resp putBaseItemsResponse
+		if err := json.Unmarshal(o.Body, &resp); err != nil {
+			return fmt.Errorf("failed to put items into base %s: %w", b.Base, err)
+		}
+		if len(resp.Failed.Items) > 0 {
+			return fmt.Errorf("failed to put %d items into base %s", len(resp.Failed.Items), b.Base)
+		}
+	}
+	return nil
+}
@@ -11,43 +11,125 @@ import (
 	"time"
 
 	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/profile"
+	"golang.org/x/net/http2"
 )
 
 const (
+	// MaxConnsPerHost limits the connections to a host, commands fetch at most this many resources in parallel
+	MaxConnsPerHost = 8
+	// idleConnTimeout closes connections which weren't reused for a while
+	idleConnTimeout = 90 * time.Second
+	// keepAlive is the interval of tcp keep-alive probes
+	keepAlive = 30 * time.Second
+	// pingInterval pings http/2 connections which didn't receive a frame for a while, e.g. while following logs,
+	// and pingTimeout closes them if the ping isn't answered so that a dead connection isn't reused
+	pingInterval = 30 * time.Second
+	pingTimeout  = 15 * time.Second
+
 	SpaceClientHeader = "X-Space-Client"
+	// ChecksumHeader holds the hex encoded sha256 checksum of downloads
+	ChecksumHeader = "X-Content-Sha256"
+	// RequestIDHeader identifies a request in the logs of Deta Space
+	RequestIDHeader = "X-Request-Id"
 )
 
 type DetaClient struct {
 	Client   *http.Client
 	Version  string
 	Platform string
+	// SkipChecksums disables the verification of checksums of downloads
+	SkipChecksums bool
+	// OnFailedRequest is called for every response with an error status, e.g. to keep the request ids for support
+	OnFailedRequest func(*FailedRequest)
+	// OnUploadProgress is called while a file is uploaded, e.g. the code of a push, with the bytes sent so far
+	OnUploadProgress func(sent int64, total int64)
+	// Retries is how often a request which failed with a network error or a server error is retried, 0 disables
+	// retries. Requests which aren't idempotent are only retried if they have an idempotency key.
+	Retries int
+	// OnRetry is called before a failed request is sent again
+	OnRetry func(*Retry)
+
+	uploadLimiter *bandwidthLimiter
 }
 
+// transport is shared by all http clients of the cli, so that a command reuses its connections and tls sessions
+// for all requests, including the streamed logs and the downloads from github
+var transport = newTransport()
+
 func NewDetaClient(version string, platform string) *DetaClient {
 	return &DetaClient{
-		Client:   &http.Client{},
+		Client:   &http.Client{Transport: transport},
 		Version:  version,
 		Platform: platform,
+		Retries:  DefaultRetries,
+	}
+}
+
+// newTransport keeps enough idle connections per host for the parallel fetches of commands,
+// the default transport only keeps two and opens a new connection for every further request
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = dialContext
+	t.ForceAttemptHTTP2 = true
+	t.MaxIdleConnsPerHost = MaxConnsPerHost
+	t.MaxConnsPerHost = MaxConnsPerHost
+	t.IdleConnTimeout = idleConnTimeout
+
+	// requests are multiplexed on a single http/2 connection if the server supports it
+	h2, err := http2.ConfigureTransports(t)
+	if err == nil {
+		h2.ReadIdleTimeout = pingInterval
+		h2.PingTimeout = pingTimeout
 	}
+	return t
+}
+
+// FailedRequest is a request which failed with an error status
+type FailedRequest struct {
+	Method string
+	// Path of the url without the query, which may contain secrets
+	Path      string
+	Status    int
+	RequestID string
+}
+
+// HTTPClient returns a client which shares the connections of the DetaClient, for requests to other hosts
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: transport}
 }
 
-type errorResp struct {
-	Errors []string `json:"errors,omitempty"`
-	Detail string   `json:"detail,omitempty"`
+// SetUploadLimit limits the bandwidth of all request bodies sent by the client to limit bytes per second,
+// a limit of 0 removes the limit
+func (d *DetaClient) SetUploadLimit(limit int64) {
+	if limit <= 0 {
+		d.uploadLimiter = nil
+		return
+	}
+	d.uploadLimiter = newBandwidthLimiter(limit)
 }
 
 // requestInput input to Request function
 type requestInput struct {
-	Root             string
-	Path             string
-	Method           string
-	Headers          map[string]string
-	QueryParams      map[string]string
-	Body             interface{}
+	Root        string
+	Path        string
+	Method      string
+	Headers     map[string]string
+	QueryParams map[string]string
+	Body        interface{}
+	// BodyFile is streamed as body instead of Body, it is read twice: once to sign the request and once to send it
+	BodyFile         io.ReadSeeker
 	NeedsAuth        bool
 	ContentType      string
 	ReturnReadCloser bool
 	AccessToken      string
+	// UserContent is set for downloads of files of the user, which can be web pages, so that they aren't taken for a
+	// captive portal
+	UserContent bool
+	// IdempotencyKey is sent in IdempotencyKeyHeader, so that a request which isn't idempotent can be retried
+	IdempotencyKey string
+	// NoRetry is set for requests which are retried by their caller, e.g. the chunks of an upload
+	NoRetry bool
 }
 
 // requestOutput ouput of Request function
@@ -56,10 +138,19 @@ type requestOutput struct {
 	Body           []byte
 	BodyReadCloser io.ReadCloser
 	Header         http.Header
-	Error          *errorResp
+	Error          *Error
+}
+
+// err returns the error of the response, a response with an unexpected success status has no error body
+func (o *requestOutput) err() *Error {
+	if o.Error == nil {
+		return &Error{Status: o.Status}
+	}
+	return o.Error
 }
 
-// Request send an http request to the deta api
+// Request send an http request to the deta api, a request which failed with a transient error is retried with
+// an exponential backoff
 func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 	marshalled, _ := i.Body.([]byte)
 	if i.Body != nil && i.ContentType == "" {
@@ -72,10 +163,46 @@ func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 		}
 	}
 
-	req, err := http.NewRequest(i.Method, fmt.Sprintf("%s%s", i.Root, i.Path), bytes.NewBuffer(marshalled))
+	for attempt := 0; ; attempt++ {
+		o, err := d.send(i, marshalled)
+		if attempt >= d.Retries || !retryable(i, o, err) {
+			return o, err
+		}
+		delay := retryDelay(attempt, o)
+		if d.OnRetry != nil {
+			d.OnRetry(&Retry{Method: i.Method, Path: i.Path, Attempt: attempt + 1, Delay: delay, Err: attemptError(o, err)})
+		}
+		time.Sleep(delay)
+	}
+}
+
+// send sends a single attempt of the request
+func (d *DetaClient) send(i *requestInput, marshalled []byte) (*requestOutput, error) {
+	var body io.Reader = bytes.NewReader(marshalled)
+	contentLength := int64(len(marshalled))
+	if i.BodyFile != nil {
+		body = i.BodyFile
+		size, err := i.BodyFile.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := i.BodyFile.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		contentLength = size
+	}
+	if d.uploadLimiter != nil && contentLength > 0 {
+		body = d.uploadLimiter.reader(body)
+	}
+	if d.OnUploadProgress != nil && i.BodyFile != nil && contentLength > 0 {
+		body = &progressReader{r: body, total: contentLength, report: d.OnUploadProgress}
+	}
+
+	req, err := http.NewRequest(i.Method, fmt.Sprintf("%s%s", i.Root, i.Path), body)
 	if err != nil {
 		return nil, err
 	}
+	req.ContentLength = contentLength
 
 	// headers
 	if i.ContentType != "" {
@@ -84,6 +211,9 @@ func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 	for k, v := range i.Headers {
 		req.Header.Set(k, v)
 	}
+	if i.IdempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, i.IdempotencyKey)
+	}
 
 	clientHeader := fmt.Sprintf("cli/%s %s", d.Version, d.Platform)
 	req.Header.Set(SpaceClientHeader, clientHeader)
@@ -96,6 +226,7 @@ func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 	req.URL.RawQuery = q.Encode()
 
 	if i.NeedsAuth {
+		endAuth := profile.Track("auth")
 		if i.AccessToken == "" {
 			i.AccessToken, err = auth.GetAccessToken()
 			if err != nil {
@@ -107,22 +238,35 @@ func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 		timestamp := strconv.FormatInt(now, 10)
 
 		// compute signature
-		signature, err := auth.CalcSignature(&auth.CalcSignatureInput{
+		signatureInput := &auth.CalcSignatureInput{
 			AccessToken: i.AccessToken,
 			HTTPMethod:  i.Method,
 			URI:         req.URL.RequestURI(),
 			Timestamp:   timestamp,
 			ContentType: i.ContentType,
 			RawBody:     marshalled,
-		})
+		}
+		if i.BodyFile != nil {
+			signatureInput.Body = i.BodyFile
+		}
+		signature, err := auth.CalcSignature(signatureInput)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate auth signature: %w", err)
 		}
+		if i.BodyFile != nil {
+			// rewind the body after it was read for the signature
+			if _, err := i.BodyFile.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
 		// set needed access key auth headers
 		req.Header.Set("X-Deta-Timestamp", timestamp)
 		req.Header.Set("X-Deta-Signature", signature)
+		endAuth()
 	}
 
+	endRequest := profile.Track("api")
+	defer endRequest()
 	res, err := d.Client.Do(req)
 	if err != nil {
 		return nil, err
@@ -134,6 +278,11 @@ func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 	}
 
 	if i.ReturnReadCloser && res.StatusCode >= 200 && res.StatusCode <= 299 {
+		// streamed bodies like logs are measured by the caller, a portal is only recognized by its content type
+		if e := interception(req, res, nil); e != nil && !i.UserContent {
+			res.Body.Close()
+			return nil, e
+		}
 		o.BodyReadCloser = res.Body
 		return o, nil
 	}
@@ -143,6 +292,14 @@ func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 	if err != nil {
 		return nil, err
 	}
+	// a captive portal answers instead of the api, its page would fail to decode with a confusing error
+	if e := interception(req, res, b); e != nil {
+		return nil, e
+	}
+	// the signature of a request is only valid around its timestamp, a wrong clock fails all signed requests
+	if e := clockSkew(res, time.Now()); e != nil && i.NeedsAuth {
+		return nil, e
+	}
 	if res.StatusCode >= 200 && res.StatusCode <= 299 {
 		if res.StatusCode != 204 {
 			o.Body = b
@@ -150,21 +307,21 @@ func (d *DetaClient) request(i *requestInput) (*requestOutput, error) {
 		return o, nil
 	}
 
-	var er errorResp
+	if d.OnFailedRequest != nil {
+		d.OnFailedRequest(&FailedRequest{Method: i.Method, Path: req.URL.Path, Status: res.StatusCode, RequestID: res.Header.Get(RequestIDHeader)})
+	}
+
 	if res.StatusCode == 413 {
-		er.Detail = "Request entity too large"
-		o.Error = &er
+		o.Error = &Error{Status: res.StatusCode, Detail: "Request entity too large"}
 		return o, nil
 	}
 	if res.StatusCode == 502 {
-		er.Detail = "Internal server error"
-		o.Error = &er
+		o.Error = &Error{Status: res.StatusCode, Detail: "Internal server error"}
 		return o, nil
 	}
-	err = json.Unmarshal(b, &er)
+	o.Error, err = parseError(res.StatusCode, b)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshall error msg, request status code: %v", res.StatusCode)
 	}
-	o.Error = &er
 	return o, nil
 }
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/deta/space/internal/checksum"
+	"github.com/deta/space/internal/runtime"
+	"gotest.tools/v3/assert"
+)
+
+func TestTransportReusesConnections(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	var conns int32
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	tr := newTransport()
+	// the tls config of the transport announces http/2, only the certificate of the test server is added
+	tr.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	client := &DetaClient{Client: &http.Client{Transport: tr}}
+
+	for i := 0; i < 3; i++ {
+		o, err := client.request(&requestInput{Root: server.URL, Path: "/", Method: http.MethodGet})
+		assert.NilError(t, err)
+		assert.Equal(t, string(o.Body), "HTTP/2.0")
+	}
+	assert.Equal(t, atomic.LoadInt32(&conns), int32(1))
+}
+
+func TestUploadProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	var sent, total int64
+	client := &DetaClient{Client: server.Client(), OnUploadProgress: func(s int64, t int64) {
+		sent, total = s, t
+	}}
+	_, err := client.request(&requestInput{Root: server.URL, Path: "/", Method: http.MethodPost, BodyFile: bytes.NewReader(make([]byte, 1<<16))})
+	assert.NilError(t, err)
+	assert.Equal(t, sent, int64(1<<16))
+	assert.Equal(t, total, int64(1<<16))
+}
+
+func TestGetRevisionCodeChecksum(t *testing.T) {
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "main.py"), []byte("print('hello')"), 0644))
+	opts := runtime.ZipOptions{Compression: runtime.CompressionBest}
+	var archive bytes.Buffer
+	_, err := runtime.WriteZip(&archive, dir, opts)
+	assert.NilError(t, err)
+	code := archive.Bytes()
+	digest, _, err := runtime.DigestArchive(dir, opts)
+	assert.NilError(t, err)
+
+	cases := []struct {
+		name   string
+		header string
+		digest string
+		skip   bool
+		err    error
+	}{
+		{name: "verified", header: checksum.Sum(code)},
+		{name: "mismatch", header: checksum.Sum([]byte("other code")), err: checksum.ErrMismatch},
+		{name: "digest", digest: "sha256:" + digest},
+		{name: "digest mismatch", digest: checksum.Sum([]byte("other code")), err: checksum.ErrMismatch},
+		{name: "missing"},
+		{name: "mismatch skipped", header: checksum.Sum([]byte("other code")), skip: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if c.header != "" {
+					w.Header().Set(ChecksumHeader, c.header)
+				}
+				w.Write(code)
+			}))
+			defer server.Close()
+			serverURL, _ := url.Parse(server.URL)
+
+			client := &DetaClient{Client: &http.Client{Transport: redirectTransport{server: serverURL}}, SkipChecksums: c.skip}
+			rc, err := client.GetRevisionCode(&GetRevisionCodeRequest{RevisionID: "r1", Digest: c.digest})
+			var downloaded []byte
+			if err == nil {
+				downloaded, err = io.ReadAll(rc)
+				rc.Close()
+			}
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, downloaded, code)
+		})
+	}
+}
+
+func TestFindRelease(t *testing.T) {
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+	pages := map[string]string{
+		"":   `{"releases": [{"version": "1.2.0"}, {"version": "1.1.0"}], "page": {"last": "p2"}}`,
+		"p2": `{"releases": [{"version": "1.0.0"}], "page": {"last": null}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pages[r.URL.Query().Get("last")]))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	client := &DetaClient{Client: &http.Client{Transport: redirectTransport{server: serverURL}}}
+
+	release, err := client.FindRelease("a1", "1.0.0")
+	assert.NilError(t, err)
+	assert.Equal(t, release.Version, "1.0.0")
+
+	_, err = client.FindRelease("a1", "0.9.0")
+	assert.ErrorIs(t, err, ErrReleaseNotFound)
+}
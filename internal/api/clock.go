@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxClockSkew is how far the local clock can be off before a rejected signature is blamed on it, the Date header of
+// responses only has a resolution of seconds
+const maxClockSkew = time.Minute
+
+// ClockSkewError is a signed request which was rejected while the local clock is off. Requests are signed with the
+// local time, so the api rejects all of them if the clock is too far from its own.
+type ClockSkewError struct {
+	Status int
+	// Skew is how far the local clock is ahead of the clock of the api, it's negative if the local clock is behind
+	Skew time.Duration
+}
+
+func (e *ClockSkewError) Error() string {
+	direction := "ahead"
+	skew := e.Skew
+	if skew < 0 {
+		direction, skew = "behind", -skew
+	}
+	return fmt.Sprintf("authentication failed because your clock is %s %s, requests are signed with the local time: sync your clock, e.g. by enabling automatic date and time in your system settings, and retry", formatSkew(skew), direction)
+}
+
+// formatSkew rounds the skew to minutes, or to seconds if it's less than a few minutes
+func formatSkew(skew time.Duration) string {
+	if skew < 5*time.Minute {
+		return fmt.Sprintf("%d seconds", int(skew.Round(time.Second)/time.Second))
+	}
+	minutes := int(skew.Round(time.Minute) / time.Minute)
+	if minutes < 120 {
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+	return fmt.Sprintf("%.1f hours", skew.Hours())
+}
+
+// clockSkew returns a ClockSkewError if a signed request was rejected and the Date of the response is too far from
+// now, the local time the response was received
+func clockSkew(res *http.Response, now time.Time) *ClockSkewError {
+	if res.StatusCode != http.StatusUnauthorized && res.StatusCode != http.StatusForbidden {
+		return nil
+	}
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return nil
+	}
+	skew := now.Sub(date)
+	if skew > -maxClockSkew && skew < maxClockSkew {
+		return nil
+	}
+	return &ClockSkewError{Status: res.StatusCode, Skew: skew}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestClockSkew(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		status   int
+		date     string
+		expected string
+	}{
+		{name: "ahead", status: 401, date: now.Add(-7 * time.Minute).Format(http.TimeFormat), expected: "your clock is 7 minutes ahead"},
+		{name: "behind", status: 403, date: now.Add(90 * time.Second).Format(http.TimeFormat), expected: "your clock is 90 seconds behind"},
+		{name: "hours", status: 401, date: now.Add(3 * time.Hour).Format(http.TimeFormat), expected: "your clock is 3.0 hours behind"},
+		{name: "in sync", status: 401, date: now.Add(-20 * time.Second).Format(http.TimeFormat)},
+		{name: "not an auth failure", status: 404, date: now.Add(time.Hour).Format(http.TimeFormat)},
+		{name: "no date", status: 401},
+	}
+
+	for _, c := range cases {
+		res := &http.Response{StatusCode: c.status, Header: http.Header{}}
+		if c.date != "" {
+			res.Header.Set("Date", c.date)
+		}
+		e := clockSkew(res, now)
+		if c.expected == "" {
+			assert.Assert(t, e == nil, c.name)
+			continue
+		}
+		assert.Assert(t, e != nil, c.name)
+		assert.ErrorContains(t, e, c.expected, c.name)
+	}
+}
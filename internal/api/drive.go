@@ -0,0 +1,240 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	driveRoot      = "https://drive.deta.sh/v1"
+	driveKeyHeader = "X-API-Key"
+
+	// MaxDriveFileSize is the largest file that can be uploaded in a single request,
+	// bigger files have to be uploaded in chunks
+	MaxDriveFileSize = 10 * 1024 * 1024
+	// DriveChunkSize size of a single chunk for chunked uploads
+	DriveChunkSize = 5 * 1024 * 1024
+)
+
+// DriveRef identifies a drive of a project, authenticated with a project key
+type DriveRef struct {
+	ProjectKey string
+	Drive      string
+}
+
+func (d *DriveRef) root() string {
+	projectID := strings.Split(d.ProjectKey, "_")[0]
+	return fmt.Sprintf("%s/%s/%s", driveRoot, projectID, d.Drive)
+}
+
+func (d *DriveRef) headers() map[string]string {
+	return map[string]string{driveKeyHeader: d.ProjectKey}
+}
+
+// driveErrorMsg extracts the error message of a Drive or Base response
+func driveErrorMsg(o *requestOutput) string {
+	return o.err().Error()
+}
+
+type listDriveFilesResponse struct {
+	Paging struct {
+		Size int    `json:"size"`
+		Last string `json:"last"`
+	} `json:"paging"`
+	Names []string `json:"names"`
+}
+
+// ListDriveFiles lists the names of all files in the drive starting with prefix
+func (c *DetaClient) ListDriveFiles(d *DriveRef, prefix string) ([]string, error) {
+	var names []string
+	last := ""
+	for {
+		query := map[string]string{"limit": "1000"}
+		if prefix != "" {
+			query["prefix"] = prefix
+		}
+		if last != "" {
+			query["last"] = last
+		}
+
+		o, err := c.request(&requestInput{
+			Root:        d.root(),
+			Path:        "/files",
+			Method:      "GET",
+			Headers:     d.headers(),
+			QueryParams: query,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if o.Status != 200 {
+			return nil, fmt.Errorf("failed to list drive files: %v", driveErrorMsg(o))
+		}
+
+		var resp listDriveFilesResponse
+		if err := json.Unmarshal(o.Body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list drive files: %w", err)
+		}
+		names = append(names, resp.Names...)
+
+		if resp.Paging.Last == "" {
+			return names, nil
+		}
+		last = resp.Paging.Last
+	}
+}
+
+// PutDriveFile uploads a file to the drive, files bigger than MaxDriveFileSize are uploaded in chunks
+func (c *DetaClient) PutDriveFile(d *DriveRef, name string, content []byte) error {
+	if len(content) <= MaxDriveFileSize {
+		o, err := c.request(&requestInput{
+			Root:        d.root(),
+			Path:        "/files",
+			Method:      "POST",
+			Headers:     d.headers(),
+			QueryParams: map[string]string{"name": name},
+			Body:        content,
+			ContentType: "application/octet-stream",
+		})
+		if err != nil {
+			return err
+		}
+		if o.Status != 201 {
+			return fmt.Errorf("failed to upload %s: %v", name, driveErrorMsg(o))
+		}
+		return nil
+	}
+
+	return c.putDriveFileChunked(d, name, content)
+}
+
+type initDriveUploadResponse struct {
+	UploadID string `json:"upload_id"`
+}
+
+func (c *DetaClient) putDriveFileChunked(d *DriveRef, name string, content []byte) error {
+	o, err := c.request(&requestInput{
+		Root:        d.root(),
+		Path:        "/uploads",
+		Method:      "POST",
+		Headers:     d.headers(),
+		QueryParams: map[string]string{"name": name},
+	})
+	if err != nil {
+		return err
+	}
+	if o.Status != 202 {
+		return fmt.Errorf("failed to start upload of %s: %v", name, driveErrorMsg(o))
+	}
+
+	var resp initDriveUploadResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return fmt.Errorf("failed to start upload of %s: %w", name, err)
+	}
+	uploadPath := fmt.Sprintf("/uploads/%s", url.PathEscape(resp.UploadID))
+
+	for part, start := 1, 0; start < len(content); part, start = part+1, start+DriveChunkSize {
+		end := start + DriveChunkSize
+		if end > len(content) {
+			end = len(content)
+		}
+
+		o, err := c.request(&requestInput{
+			Root:        d.root(),
+			Path:        uploadPath + "/parts",
+			Method:      "POST",
+			Headers:     d.headers(),
+			QueryParams: map[string]string{"name": name, "part": strconv.Itoa(part)},
+			Body:        content[start:end],
+			ContentType: "application/octet-stream",
+		})
+		if err == nil && o.Status != 200 {
+			err = fmt.Errorf("failed to upload part %d of %s: %v", part, name, driveErrorMsg(o))
+		}
+		if err != nil {
+			// abort the upload so that no dangling parts are left behind
+			c.request(&requestInput{
+				Root:        d.root(),
+				Path:        uploadPath,
+				Method:      "DELETE",
+				Headers:     d.headers(),
+				QueryParams: map[string]string{"name": name},
+			})
+			return err
+		}
+	}
+
+	o, err = c.request(&requestInput{
+		Root:        d.root(),
+		Path:        uploadPath,
+		Method:      "PATCH",
+		Headers:     d.headers(),
+		QueryParams: map[string]string{"name": name},
+	})
+	if err != nil {
+		return err
+	}
+	if o.Status != 200 {
+		return fmt.Errorf("failed to finish upload of %s: %v", name, driveErrorMsg(o))
+	}
+	return nil
+}
+
+// GetDriveFile downloads a file from the drive
+func (c *DetaClient) GetDriveFile(d *DriveRef, name string) (io.ReadCloser, error) {
+	o, err := c.request(&requestInput{
+		Root:             d.root(),
+		Path:             "/files/download",
+		Method:           "GET",
+		Headers:          d.headers(),
+		QueryParams:      map[string]string{"name": name},
+		ReturnReadCloser: true,
+		UserContent:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to download %s: %v", name, driveErrorMsg(o))
+	}
+	return o.BodyReadCloser, nil
+}
+
+type deleteDriveFilesRequest struct {
+	Names []string `json:"names"`
+}
+
+type deleteDriveFilesResponse struct {
+	Failed map[string]string `json:"failed"`
+}
+
+// DeleteDriveFiles deletes files from the drive, at most 1000 files per call
+func (c *DetaClient) DeleteDriveFiles(d *DriveRef, names []string) error {
+	o, err := c.request(&requestInput{
+		Root:    d.root(),
+		Path:    "/files",
+		Method:  "DELETE",
+		Headers: d.headers(),
+		Body:    &deleteDriveFilesRequest{Names: names},
+	})
+	if err != nil {
+		return err
+	}
+	if o.Status != 200 {
+		return fmt.Errorf("failed to delete drive files: %v", driveErrorMsg(o))
+	}
+
+	var resp deleteDriveFilesResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return fmt.Errorf("failed to delete drive files: %w", err)
+	}
+	for name, reason := range resp.Failed {
+		return fmt.Errorf("failed to delete %s: %s", name, reason)
+	}
+	return nil
+}
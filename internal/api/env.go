@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EnvVar is an environment variable of a micro, presets are declared in the presets of the Spacefile and fall back to
+// their default while they aren't set
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+	IsSet bool   `json:"is_set"`
+	// Preset is set if the variable is declared in the presets of the Spacefile
+	Preset      bool   `json:"preset"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// MicroEnv is the environment of a micro of a project
+type MicroEnv struct {
+	Micro string   `json:"micro"`
+	Env   []EnvVar `json:"env"`
+}
+
+type GetEnvRequest struct {
+	AppID string
+}
+
+type envResponse struct {
+	Micros []MicroEnv `json:"micros"`
+}
+
+// GetEnv returns the environment variables of all micros of a project
+func (c *DetaClient) GetEnv(r *GetEnvRequest) ([]MicroEnv, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/apps/%s/env", version, r.AppID),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get environment variables: %w", o.err())
+	}
+
+	var resp envResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get environment variables: %w", err)
+	}
+	return resp.Micros, nil
+}
+
+type UpdateEnvRequest struct {
+	AppID string `json:"-"`
+	Micro string `json:"micro"`
+	// Set are the values of the variables to set by their name
+	Set map[string]string `json:"set,omitempty"`
+	// Unset are the names of the variables to remove, presets fall back to their default
+	Unset []string `json:"unset,omitempty"`
+}
+
+// UpdateEnv sets and removes environment variables of a micro at once, the running instances get the new environment
+// on their next start. It returns the environment of all micros after the update.
+func (c *DetaClient) UpdateEnv(r *UpdateEnvRequest) ([]MicroEnv, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/apps/%s/env", version, r.AppID),
+		Method:    "PATCH",
+		NeedsAuth: true,
+		Body:      r,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to update environment variables: %w", o.err())
+	}
+
+	var resp envResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to update environment variables: %w", err)
+	}
+	return resp.Micros, nil
+}
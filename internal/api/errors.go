@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// FieldError is a problem with a single field of a request
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (f FieldError) String() string {
+	if f.Field == "" || strings.HasPrefix(f.Message, f.Field+" ") {
+		return f.Message
+	}
+	return fmt.Sprintf("%s %s", f.Field, f.Message)
+}
+
+// Error is the error response of the api, the message of the response is kept together with the problems of the
+// single fields of the request if the response lists them
+type Error struct {
+	Status int
+	Detail string
+	Errors []string
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) > 0 {
+		messages := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			messages[i] = f.String()
+		}
+		return strings.Join(messages, ", ")
+	}
+	if e.Detail != "" {
+		return e.Detail
+	}
+	if len(e.Errors) > 0 {
+		return strings.Join(e.Errors, ", ")
+	}
+	if text := http.StatusText(e.Status); text != "" {
+		return strings.ToLower(text)
+	}
+	return fmt.Sprintf("unexpected status code %d", e.Status)
+}
+
+// errorBody is decoded from the different error responses of the api:
+//
+//	{"detail": "message"}
+//	{"errors": ["message"]}
+//	{"errors": [{"field": "version", "message": "must be semver"}]}
+//	{"detail": [{"loc": ["body", "version"], "msg": "must be semver"}]}
+//	{"violations": [{"field": "version", "message": "must be semver"}]}
+//	{"field_errors": {"version": "must be semver"}}
+type errorBody struct {
+	Detail      json.RawMessage   `json:"detail"`
+	Errors      []json.RawMessage `json:"errors"`
+	Violations  []violation       `json:"violations"`
+	FieldErrors map[string]string `json:"field_errors"`
+}
+
+type violation struct {
+	Field   string   `json:"field"`
+	Message string   `json:"message"`
+	Loc     []any    `json:"loc"`
+	Msg     string   `json:"msg"`
+	Path    []string `json:"path"`
+}
+
+// fieldError returns the field and message of a violation, the location of a validation error is prefixed with
+// the part of the request, e.g. ["body", "version"]
+func (v violation) fieldError() FieldError {
+	f := FieldError{Field: v.Field, Message: v.Message}
+	if f.Message == "" {
+		f.Message = v.Msg
+	}
+	if f.Field == "" {
+		var path []string
+		for _, part := range v.Loc {
+			path = append(path, fmt.Sprint(part))
+		}
+		if len(path) == 0 {
+			path = v.Path
+		}
+		if len(path) > 1 && (path[0] == "body" || path[0] == "query" || path[0] == "path") {
+			path = path[1:]
+		}
+		f.Field = strings.Join(path, ".")
+	}
+	return f
+}
+
+// parseError decodes the body of an error response
+func parseError(status int, body []byte) (*Error, error) {
+	var b errorBody
+	if err := json.Unmarshal(body, &b); err != nil {
+		return nil, err
+	}
+
+	e := &Error{Status: status}
+	if len(b.Detail) > 0 {
+		var violations []violation
+		if err := json.Unmarshal(b.Detail, &e.Detail); err != nil {
+			if err := json.Unmarshal(b.Detail, &violations); err != nil {
+				return nil, err
+			}
+		}
+		for _, v := range violations {
+			e.Fields = append(e.Fields, v.fieldError())
+		}
+	}
+	for _, raw := range b.Errors {
+		var message string
+		if err := json.Unmarshal(raw, &message); err == nil {
+			e.Errors = append(e.Errors, message)
+			continue
+		}
+		var v violation
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		e.Fields = append(e.Fields, v.fieldError())
+	}
+	for _, v := range b.Violations {
+		e.Fields = append(e.Fields, v.fieldError())
+	}
+
+	fields := make([]string, 0, len(b.FieldErrors))
+	for field := range b.FieldErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		e.Fields = append(e.Fields, FieldError{Field: field, Message: b.FieldErrors[field]})
+	}
+	return e, nil
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseError(t *testing.T) {
+	cases := []struct {
+		body     string
+		fields   []FieldError
+		expected string
+	}{
+		{body: `{"detail": "project not found"}`, expected: "project not found"},
+		{body: `{"errors": ["name is taken"]}`, expected: "name is taken"},
+		{body: `{}`, expected: "bad request"},
+		{
+			body:     `{"errors": [{"field": "version", "message": "must be semver"}]}`,
+			fields:   []FieldError{{Field: "version", Message: "must be semver"}},
+			expected: "version must be semver",
+		},
+		{
+			body:     `{"detail": [{"loc": ["body", "version"], "msg": "must be semver", "type": "value_error"}]}`,
+			fields:   []FieldError{{Field: "version", Message: "must be semver"}},
+			expected: "version must be semver",
+		},
+		{
+			body:     `{"violations": [{"field": "release_notes", "message": "release_notes is too long"}]}`,
+			fields:   []FieldError{{Field: "release_notes", Message: "release_notes is too long"}},
+			expected: "release_notes is too long",
+		},
+		{
+			body: `{"detail": "invalid request", "field_errors": {"version": "must be semver", "channel": "is unknown"}}`,
+			fields: []FieldError{
+				{Field: "channel", Message: "is unknown"},
+				{Field: "version", Message: "must be semver"},
+			},
+			expected: "channel is unknown, version must be semver",
+		},
+	}
+
+	for _, c := range cases {
+		e, err := parseError(400, []byte(c.body))
+		assert.NilError(t, err, c.body)
+		assert.DeepEqual(t, e.Fields, c.fields)
+		assert.Equal(t, e.Error(), c.expected, c.body)
+	}
+}
+
+func TestErrorIsWrapped(t *testing.T) {
+	e, err := parseError(400, []byte(`{"errors": [{"field": "version", "message": "must be semver"}]}`))
+	assert.NilError(t, err)
+
+	var apiErr *Error
+	assert.Assert(t, errors.As(fmt.Errorf("failed to create release: %w", e), &apiErr))
+	assert.Equal(t, apiErr.Fields[0].Field, "version")
+}
@@ -3,13 +3,14 @@ package api
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/google/go-github/v51/github"
 )
 
 func GetLatestCliVersion() (string, error) {
-	client := github.NewClient(nil)
+	client := github.NewClient(HTTPClient())
 
 	release, resp, err := client.Repositories.GetLatestRelease(context.Background(), "deta", "space-cli")
 
@@ -23,3 +24,20 @@ func GetLatestCliVersion() (string, error) {
 
 	return strings.TrimPrefix(release.GetTagName(), "v"), nil
 }
+
+const cliReleasesURL = "https://github.com/deta/space-cli/releases/download"
+
+// DownloadCliReleaseAsset downloads an asset of a release of the cli, tag is the version prefixed with v
+func DownloadCliReleaseAsset(tag string, name string) ([]byte, error) {
+	res, err := HTTPClient().Get(fmt.Sprintf("%s/%s/%s", cliReleasesURL, tag, name))
+	if err != nil {
+		return nil, fmt.Errorf("error while downloading %s: %w", name, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("error while downloading %s: %v", name, res.Status)
+	}
+
+	return io.ReadAll(res.Body)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Maintenance is the maintenance mode of a project, while enabled the app serves a maintenance page with the message
+type Maintenance struct {
+	Enabled   bool   `json:"enabled"`
+	Message   string `json:"message,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+type GetMaintenanceRequest struct {
+	AppID string
+}
+
+// GetMaintenance returns the maintenance mode of a project
+func (c *DetaClient) GetMaintenance(r *GetMaintenanceRequest) (*Maintenance, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/apps/%s/maintenance", version, r.AppID),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get maintenance mode: %w", o.err())
+	}
+
+	var resp Maintenance
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get maintenance mode: %w", err)
+	}
+	return &resp, nil
+}
+
+type SetMaintenanceRequest struct {
+	AppID   string `json:"-"`
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+}
+
+// SetMaintenance turns the maintenance mode of a project on or off, the running instances switch without a restart
+func (c *DetaClient) SetMaintenance(r *SetMaintenanceRequest) (*Maintenance, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/apps/%s/maintenance", version, r.AppID),
+		Method:    "PUT",
+		NeedsAuth: true,
+		Body:      r,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrProjectNotFound
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to set maintenance mode: %w", o.err())
+	}
+
+	var resp Maintenance
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to set maintenance mode: %w", err)
+	}
+	return &resp, nil
+}
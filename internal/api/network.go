@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// ForceIPv4Env connects over IPv4 only if set to a non empty value, same as --force-ipv4
+	ForceIPv4Env = "SPACE_FORCE_IPV4"
+
+	dialTimeout = 30 * time.Second
+	// happyEyeballsDelay is how long the first address family gets before the other one is tried in parallel, as
+	// recommended by RFC 8305, so that a broken IPv6 network only delays connections instead of hanging them
+	happyEyeballsDelay = 250 * time.Millisecond
+)
+
+// NetworkErrorKind is the likely cause of a NetworkError
+type NetworkErrorKind string
+
+const (
+	// NetworkErrorDNS the host couldn't be resolved
+	NetworkErrorDNS NetworkErrorKind = "dns"
+	// NetworkErrorTimeout the connection wasn't established in time
+	NetworkErrorTimeout NetworkErrorKind = "timeout"
+	// NetworkErrorRefused the host refused the connection
+	NetworkErrorRefused NetworkErrorKind = "refused"
+	// NetworkErrorUnreachable there is no route to the host
+	NetworkErrorUnreachable NetworkErrorKind = "unreachable"
+)
+
+// NetworkError is a connection which couldn't be established, with a hint about its likely cause
+type NetworkError struct {
+	Kind NetworkErrorKind
+	// Addr is the host and port which was dialed
+	Addr string
+	Hint string
+	Err  error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("%v (%s)", e.Err, e.Hint)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+var forceIPv4 atomic.Bool
+
+// SetForceIPv4 makes all connections of the cli use IPv4 only, e.g. on networks with broken IPv6
+func SetForceIPv4(forced bool) {
+	forceIPv4.Store(forced)
+}
+
+// ForceIPv4 reports if connections use IPv4 only
+func ForceIPv4() bool {
+	return forceIPv4.Load() || os.Getenv(ForceIPv4Env) != ""
+}
+
+// dialer tries IPv6 and IPv4 addresses in parallel after happyEyeballsDelay if a host has both
+var dialer = &net.Dialer{Timeout: dialTimeout, KeepAlive: keepAlive, FallbackDelay: happyEyeballsDelay}
+
+// dialContext dials over IPv4 only if forced and classifies the errors of failed connections
+func dialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	forced := ForceIPv4()
+	if forced && network == "tcp" {
+		network = "tcp4"
+	}
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, classifyDialError(addr, err, forced)
+	}
+	return conn, nil
+}
+
+// classifyDialError wraps err in a NetworkError with a hint about its cause, errors without a known cause and
+// canceled dials are returned as is
+func classifyDialError(addr string, err error, forcedIPv4 bool) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	host, _, splitErr := net.SplitHostPort(addr)
+	if splitErr != nil {
+		host = addr
+	}
+	ipv6Hint := ""
+	if !forcedIPv4 {
+		ipv6Hint = ", if your network has broken IPv6 retry with --force-ipv4"
+	}
+
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return &NetworkError{Kind: NetworkErrorDNS, Addr: addr, Err: err, Hint: fmt.Sprintf("%s couldn't be resolved, check your connection and DNS settings", host)}
+	case errors.As(err, &dnsErr) && dnsErr.IsTimeout:
+		return &NetworkError{Kind: NetworkErrorDNS, Addr: addr, Err: err, Hint: "the DNS server didn't answer in time, check your DNS settings"}
+	case errors.As(err, &dnsErr):
+		return &NetworkError{Kind: NetworkErrorDNS, Addr: addr, Err: err, Hint: fmt.Sprintf("%s couldn't be resolved, the DNS server failed", host)}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return &NetworkError{Kind: NetworkErrorRefused, Addr: addr, Err: err, Hint: "the connection was refused, a firewall or proxy may block it"}
+	case errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.EADDRNOTAVAIL):
+		hint := "there is no route to " + host + ", check your connection"
+		if errors.As(err, &opErr) && isIPv6(opErr.Addr) && !forcedIPv4 {
+			hint = "IPv6 seems to be broken on your network, retry with --force-ipv4"
+		}
+		return &NetworkError{Kind: NetworkErrorUnreachable, Addr: addr, Err: err, Hint: hint}
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout():
+		return &NetworkError{Kind: NetworkErrorTimeout, Addr: addr, Err: err, Hint: "the connection timed out" + ipv6Hint}
+	}
+	return err
+}
+
+func isIPv6(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.To4() == nil && tcp.IP.To16() != nil
+}
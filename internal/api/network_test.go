@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestClassifyDialError(t *testing.T) {
+	ipv6 := &net.TCPAddr{IP: net.ParseIP("2606:4700::1"), Port: 443}
+	ipv4 := &net.TCPAddr{IP: net.ParseIP("104.18.0.1"), Port: 443}
+	cases := []struct {
+		name   string
+		err    error
+		forced bool
+		kind   NetworkErrorKind
+		hint   string
+	}{
+		{
+			name: "unknown host",
+			err:  &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "deta.space", IsNotFound: true}},
+			kind: NetworkErrorDNS,
+			hint: "deta.space couldn't be resolved",
+		},
+		{
+			name: "dns timeout",
+			err:  &net.OpError{Op: "dial", Err: &net.DNSError{Err: "i/o timeout", Name: "deta.space", IsTimeout: true}},
+			kind: NetworkErrorDNS,
+			hint: "the DNS server didn't answer in time",
+		},
+		{
+			name: "refused",
+			err:  &net.OpError{Op: "dial", Addr: ipv4, Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			kind: NetworkErrorRefused,
+			hint: "the connection was refused",
+		},
+		{
+			name: "broken ipv6",
+			err:  &net.OpError{Op: "dial", Addr: ipv6, Err: os.NewSyscallError("connect", syscall.ENETUNREACH)},
+			kind: NetworkErrorUnreachable,
+			hint: "retry with --force-ipv4",
+		},
+		{
+			name:   "unreachable over ipv4",
+			err:    &net.OpError{Op: "dial", Addr: ipv4, Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)},
+			forced: true,
+			kind:   NetworkErrorUnreachable,
+			hint:   "there is no route to deta.space",
+		},
+		{
+			name: "timeout",
+			err:  &net.OpError{Op: "dial", Err: context.DeadlineExceeded},
+			kind: NetworkErrorTimeout,
+			hint: "the connection timed out, if your network has broken IPv6 retry with --force-ipv4",
+		},
+		{
+			name:   "timeout with ipv4",
+			err:    &net.OpError{Op: "dial", Err: context.DeadlineExceeded},
+			forced: true,
+			kind:   NetworkErrorTimeout,
+			hint:   "(the connection timed out)",
+		},
+	}
+
+	for _, c := range cases {
+		err := classifyDialError("deta.space:443", c.err, c.forced)
+		var netErr *NetworkError
+		assert.Assert(t, errors.As(err, &netErr), c.name)
+		assert.Equal(t, netErr.Kind, c.kind, c.name)
+		assert.ErrorContains(t, err, c.hint, c.name)
+		assert.ErrorIs(t, err, c.err)
+	}
+
+	canceled := fmt.Errorf("dial: %w", context.Canceled)
+	assert.Equal(t, classifyDialError("deta.space:443", canceled, false), canceled)
+}
+
+func TestDialForceIPv4(t *testing.T) {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 isn't available")
+	}
+	defer listener.Close()
+
+	conn, err := dialContext(context.Background(), "tcp", listener.Addr().String())
+	assert.NilError(t, err)
+	conn.Close()
+
+	SetForceIPv4(true)
+	defer SetForceIPv4(false)
+	_, err = dialContext(context.Background(), "tcp", listener.Addr().String())
+	assert.Assert(t, err != nil)
+}
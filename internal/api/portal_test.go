@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestInterception(t *testing.T) {
+	portal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<!DOCTYPE html><html><body>Welcome to the hotel wifi</body></html>")
+	}))
+	defer portal.Close()
+
+	cases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		stream   bool
+		expected string
+	}{
+		{
+			name: "redirect",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, portal.URL+"/login", http.StatusFound)
+			},
+			expected: "redirected the request to 127.0.0.1",
+		},
+		{
+			name: "page without content type",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				fmt.Fprint(w, "  <html><body>Sign in</body></html>")
+			},
+			expected: "answered with a web page",
+		},
+		{
+			name: "sign-in page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, "Access denied")
+			},
+			expected: "answered with a web page",
+		},
+		{
+			name: "streamed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				fmt.Fprint(w, "<html></html>")
+			},
+			stream:   true,
+			expected: "answered with a web page",
+		},
+		{
+			name: "proxy auth",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusProxyAuthRequired)
+			},
+			expected: "requires authentication",
+		},
+		{
+			name: "json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"id": "abc"}`)
+			},
+		},
+		{
+			name: "server error page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, "<html>Service unavailable</html>")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(c.handler)
+		client := &DetaClient{Client: server.Client()}
+		_, err := client.request(&requestInput{Root: server.URL, Path: "/v0/apps", Method: http.MethodGet, ReturnReadCloser: c.stream})
+		server.Close()
+
+		var intercepted *InterceptedError
+		if c.expected == "" {
+			assert.Assert(t, !errors.As(err, &intercepted), c.name)
+			continue
+		}
+		assert.Assert(t, errors.As(err, &intercepted), c.name)
+		assert.Assert(t, strings.Contains(err.Error(), c.expected), "%s: %v", c.name, err)
+	}
+}
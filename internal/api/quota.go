@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// QuotaResource is the usage of a resource of the space against its limit
+type QuotaResource struct {
+	// Name of the resource, e.g. projects, builds_per_day, storage or bandwidth
+	Name string `json:"name"`
+	// Unit of the usage and the limit, count or bytes
+	Unit  string `json:"unit"`
+	Used  int64  `json:"used"`
+	Limit int64  `json:"limit"`
+	// ResetsAt is when the usage of resources with a period resets, e.g. builds per day
+	ResetsAt string `json:"resets_at,omitempty"`
+}
+
+type GetQuotaResponse struct {
+	Resources []*QuotaResource `json:"resources"`
+}
+
+// GetQuota returns the usage of the space against the limits of the platform
+func (c *DetaClient) GetQuota() (*GetQuotaResponse, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/space/quota", version),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get quota: %w", o.err())
+	}
+
+	var resp GetQuotaResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get quota: %w", err)
+	}
+	return &resp, nil
+}
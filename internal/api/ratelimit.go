@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRateLimitedRead caps the size of a single read so that the rate is smoothed out over the upload
+const maxRateLimitedRead = 32 * 1024
+
+var bandwidthUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	// longest suffixes first so that "KiB" is not matched as "B"
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30},
+	{"kb", 1 << 10}, {"mb", 1 << 20}, {"gb", 1 << 30},
+	{"k", 1 << 10}, {"m", 1 << 20}, {"g", 1 << 30},
+	{"b", 1},
+}
+
+// ParseBandwidth parses a bandwidth like "2MB/s", "500k" or "1048576" into bytes per second,
+// units are powers of 1024 and the "/s" suffix is optional
+func ParseBandwidth(s string) (int64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	value = strings.TrimSuffix(value, "/s")
+
+	multiplier := int64(1)
+	for _, unit := range bandwidthUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid bandwidth %q, expected a positive value like 2MB/s or 500KB/s", s)
+	}
+
+	limit := int64(n * float64(multiplier))
+	if limit < 1 {
+		return 0, fmt.Errorf("invalid bandwidth %q, must be at least 1B/s", s)
+	}
+	return limit, nil
+}
+
+// bandwidthLimiter is shared by all uploads of a client, so that parallel uploads
+// together stay within the limit
+type bandwidthLimiter struct {
+	mu    sync.Mutex
+	limit int64
+	next  time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func newBandwidthLimiter(limit int64) *bandwidthLimiter {
+	return &bandwidthLimiter{limit: limit, now: time.Now, sleep: time.Sleep}
+}
+
+// wait blocks until n more bytes can be sent without exceeding the limit
+func (b *bandwidthLimiter) wait(n int) {
+	b.mu.Lock()
+	now := b.now()
+	if b.next.Before(now) {
+		b.next = now
+	}
+	b.next = b.next.Add(time.Duration(float64(n) / float64(b.limit) * float64(time.Second)))
+	wait := b.next.Sub(now)
+	b.mu.Unlock()
+
+	if wait > 0 {
+		b.sleep(wait)
+	}
+}
+
+// reader wraps r so that reading from it is limited by the limiter
+func (b *bandwidthLimiter) reader(r io.Reader) io.Reader {
+	return &rateLimitedReader{r: r, limiter: b}
+}
+
+type rateLimitedReader struct {
+	r       io.Reader
+	limiter *bandwidthLimiter
+}
+
+func (l *rateLimitedReader) Read(p []byte) (int, error) {
+	size := int64(maxRateLimitedRead)
+	if l.limiter.limit < size {
+		size = l.limiter.limit
+	}
+	if int64(len(p)) > size {
+		p = p[:size]
+	}
+
+	n, err := l.r.Read(p)
+	if n > 0 {
+		l.limiter.wait(n)
+	}
+	return n, err
+}
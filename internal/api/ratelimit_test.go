@@ -0,0 +1,57 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{input: "2MB/s", expected: 2 * 1024 * 1024},
+		{input: "500KB/s", expected: 500 * 1024},
+		{input: "500k", expected: 500 * 1024},
+		{input: "1.5MiB/s", expected: 1536 * 1024},
+		{input: "1G", expected: 1024 * 1024 * 1024},
+		{input: "2048", expected: 2048},
+		{input: "64B/s", expected: 64},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			limit, err := ParseBandwidth(c.input)
+			assert.NilError(t, err)
+			assert.Equal(t, limit, c.expected)
+		})
+	}
+
+	for _, input := range []string{"", "fast", "-1MB/s", "0", "0.1B"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := ParseBandwidth(input)
+			assert.Assert(t, err != nil)
+		})
+	}
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 4096)
+	limiter := newBandwidthLimiter(1024)
+
+	clock := time.Now()
+	start := clock
+	limiter.now = func() time.Time { return clock }
+	limiter.sleep = func(d time.Duration) { clock = clock.Add(d) }
+
+	read, err := io.ReadAll(limiter.reader(bytes.NewReader(content)))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, read, content)
+
+	// 4KiB at 1KiB/s takes 4 seconds
+	assert.Equal(t, clock.Sub(start), 4*time.Second)
+}
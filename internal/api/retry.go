@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetries is how often a failed request is retried if nothing is configured
+	DefaultRetries = 3
+	// IdempotencyKeyHeader makes the api answer a retried request with the response of the first one instead of
+	// acting twice, e.g. creating a release twice
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// maxRetryAfter caps the delay a Retry-After header asks for
+	maxRetryAfter = 30 * time.Second
+)
+
+var (
+	// retryBaseDelay is the delay before the first retry, it doubles with every retry up to maxRetryDelay
+	retryBaseDelay = 500 * time.Millisecond
+	maxRetryDelay  = 8 * time.Second
+)
+
+// Retry is a request which failed with a transient error and is sent again
+type Retry struct {
+	Method string
+	// Path of the url without the query, which may contain secrets
+	Path string
+	// Attempt is the number of the retry, starting with 1
+	Attempt int
+	Delay   time.Duration
+	// Err is the network error or the error status of the failed attempt
+	Err error
+}
+
+// newIdempotencyKey returns a random key for IdempotencyKeyHeader
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// idempotent reports if sending the request twice has the same effect as sending it once
+func idempotent(i *requestInput) bool {
+	switch i.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return i.IdempotencyKey != ""
+}
+
+// retryable reports if the attempt failed with a transient error: a network error or a response of an overloaded or
+// failing server
+func retryable(i *requestInput, o *requestOutput, err error) bool {
+	if i.NoRetry || !idempotent(i) {
+		return false
+	}
+	if err != nil {
+		// only errors of the connection are transient, e.g. not a missing access token or the answer of a captive
+		// portal
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+	}
+	switch o.Status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns the delay before the retry, the delay doubles with every retry and is randomized so that clients
+// don't retry in lockstep. A Retry-After header of the response takes precedence.
+func retryDelay(attempt int, o *requestOutput) time.Duration {
+	if o != nil {
+		if seconds, err := strconv.Atoi(o.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay := time.Duration(seconds) * time.Second
+			if delay > maxRetryAfter {
+				delay = maxRetryAfter
+			}
+			return delay
+		}
+	}
+
+	delay := maxRetryDelay
+	if attempt < 16 && retryBaseDelay<<attempt < maxRetryDelay {
+		delay = retryBaseDelay << attempt
+	}
+	// between half and the full delay
+	jitter, err := rand.Int(rand.Reader, big.NewInt(int64(delay/2)+1))
+	if err != nil {
+		return delay
+	}
+	return delay/2 + time.Duration(jitter.Int64())
+}
+
+// attemptError describes a failed attempt for Retry
+func attemptError(o *requestOutput, err error) error {
+	if err != nil {
+		return err
+	}
+	return o.err()
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// flakyServer fails the first requests with the status, a status of 0 closes the connection instead
+type flakyServer struct {
+	failures int
+	status   int
+	attempts int
+	keys     []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.attempts++
+	s.keys = append(s.keys, r.Header.Get(IdempotencyKeyHeader))
+	if s.attempts <= s.failures {
+		if s.status == 0 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.WriteHeader(s.status)
+		fmt.Fprint(w, `{"detail": "Try again"}`)
+		return
+	}
+	if r.Method == http.MethodPost {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	fmt.Fprint(w, `{"id": "abc"}`)
+}
+
+func TestRequestRetries(t *testing.T) {
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+	retryBaseDelay = time.Millisecond
+
+	cases := []struct {
+		name     string
+		input    *requestInput
+		failures int
+		status   int
+		retries  int
+		attempts int
+		expected int
+	}{
+		{name: "server error", input: &requestInput{Method: "GET"}, failures: 2, status: 503, retries: 3, attempts: 3, expected: 200},
+		{name: "closed connection", input: &requestInput{Method: "GET"}, failures: 1, status: 0, retries: 3, attempts: 2, expected: 200},
+		{name: "retries used up", input: &requestInput{Method: "GET"}, failures: 5, status: 500, retries: 1, attempts: 2, expected: 500},
+		{name: "disabled", input: &requestInput{Method: "GET"}, failures: 1, status: 502, retries: 0, attempts: 1, expected: 502},
+		{name: "client error", input: &requestInput{Method: "GET"}, failures: 1, status: 404, retries: 3, attempts: 1, expected: 404},
+		{name: "not idempotent", input: &requestInput{Method: "POST"}, failures: 1, status: 503, retries: 3, attempts: 1, expected: 503},
+		{name: "idempotency key", input: &requestInput{Method: "POST", IdempotencyKey: "k1"}, failures: 1, status: 503, retries: 3, attempts: 2, expected: 202},
+		{name: "no retry", input: &requestInput{Method: "PUT", NoRetry: true}, failures: 1, status: 503, retries: 3, attempts: 1, expected: 503},
+	}
+
+	for _, c := range cases {
+		s := &flakyServer{failures: c.failures, status: c.status}
+		server := httptest.NewServer(s)
+		serverURL, _ := url.Parse(server.URL)
+
+		var retried []*Retry
+		client := &DetaClient{
+			Client:  &http.Client{Transport: redirectTransport{server: serverURL}},
+			Retries: c.retries,
+			OnRetry: func(r *Retry) { retried = append(retried, r) },
+		}
+		c.input.Root, c.input.Path, c.input.NeedsAuth = spaceRoot, "/v0/apps", true
+		o, err := client.request(c.input)
+		server.Close()
+
+		assert.NilError(t, err, c.name)
+		assert.Equal(t, o.Status, c.expected, c.name)
+		assert.Equal(t, s.attempts, c.attempts, c.name)
+		assert.Equal(t, len(retried), c.attempts-1, c.name)
+		for _, key := range s.keys {
+			assert.Equal(t, key, c.input.IdempotencyKey, c.name)
+		}
+	}
+}
+
+func TestCreateReleaseRetry(t *testing.T) {
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+	retryBaseDelay = time.Millisecond
+
+	s := &flakyServer{failures: 1, status: 0}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	client := &DetaClient{Client: &http.Client{Transport: redirectTransport{server: serverURL}}, Retries: 1}
+	_, err := client.CreateRelease(&CreateReleaseRequest{RevisionID: "r1", AppID: "a1", Version: "1.0.0"})
+	assert.NilError(t, err)
+	assert.Equal(t, s.attempts, 2)
+	// the retry is recognized as the same release
+	assert.Assert(t, s.keys[0] != "")
+	assert.Equal(t, s.keys[0], s.keys[1])
+}
+
+func TestRetryDelay(t *testing.T) {
+	retryBaseDelay, maxRetryDelay = 500*time.Millisecond, 8*time.Second
+
+	for attempt := 0; attempt < 6; attempt++ {
+		delay := retryDelay(attempt, nil)
+		full := retryBaseDelay << attempt
+		if full > maxRetryDelay {
+			full = maxRetryDelay
+		}
+		assert.Assert(t, delay >= full/2 && delay <= full, "attempt %d: %s", attempt, delay)
+	}
+
+	o := &requestOutput{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, retryDelay(0, o), 2*time.Second)
+	o.Header.Set("Retry-After", "3600")
+	assert.Equal(t, retryDelay(0, o), maxRetryAfter)
+}
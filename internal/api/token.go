@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/deta/space/internal/auth"
+)
+
+// ErrTokenInfoUnsupported the server can't describe access tokens
+var ErrTokenInfoUnsupported = errors.New("the server doesn't describe access tokens")
+
+// TokenInfo describes what the access token of the cli can do
+type TokenInfo struct {
+	Name string         `json:"name"`
+	Kind auth.TokenKind `json:"kind"`
+	// Handle of the Space account the token belongs to
+	Handle string `json:"handle,omitempty"`
+	// Scopes of the token, account tokens have auth.ScopeAll
+	Scopes []auth.Scope `json:"scopes"`
+	// Projects the token is restricted to by their ids, empty if it can access all projects of the account
+	Projects  []string `json:"projects,omitempty"`
+	CreatedAt string   `json:"created_at,omitempty"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+// GetTokenInfo returns the kind and scopes of the access token of the cli
+func (c *DetaClient) GetTokenInfo() (*TokenInfo, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/auth/token", version),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status == 404 {
+		return nil, ErrTokenInfoUnsupported
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get the token info: %w", o.err())
+	}
+
+	var resp TokenInfo
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get the token info: %w", err)
+	}
+	return &resp, nil
+}
+
+// ErrSessionNotFound the session doesn't exist or was revoked already
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is an access token of the account, created by logging in on a device or for a CI pipeline
+type Session struct {
+	ID   string         `json:"id"`
+	Name string         `json:"name"`
+	Kind auth.TokenKind `json:"kind"`
+	// Device describes where the session was created, e.g. the browser and os of a login
+	Device     string `json:"device,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	LastUsedIP string `json:"last_used_ip,omitempty"`
+	// Current is set for the session of the access token of the cli
+	Current bool `json:"current"`
+}
+
+type listSessionsResponse struct {
+	Sessions []*Session `json:"sessions"`
+}
+
+// ListSessions returns the active sessions of the account
+func (c *DetaClient) ListSessions() ([]*Session, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/auth/sessions", version),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to list sessions: %w", o.err())
+	}
+
+	var resp listSessionsResponse
+	if err := json.Unmarshal(o.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return resp.Sessions, nil
+}
+
+// RevokeSession revokes a session of the account, its access token stops working immediately
+func (c *DetaClient) RevokeSession(id string) error {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/auth/sessions/%s", version, url.PathEscape(id)),
+		Method:    "DELETE",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.Status == 404 {
+		return ErrSessionNotFound
+	}
+	if !(o.Status >= 200 && o.Status <= 299) {
+		return fmt.Errorf("failed to revoke session: %w", o.err())
+	}
+	return nil
+}
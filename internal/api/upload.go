@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// CodeChunkSize is the size of the chunks of resumable code uploads
+	CodeChunkSize = 8 << 20
+	// ResumableUploadMinSize is the size from which code is uploaded in chunks, smaller code is pushed at once
+	ResumableUploadMinSize = 2 * CodeChunkSize
+	// maxChunkFailures is how often in a row a chunk can fail before the upload is given up
+	maxChunkFailures = 5
+)
+
+// chunkRetryDelay is the delay before the first retry of a chunk, it doubles with every failure in a row
+var chunkRetryDelay = time.Second
+
+// ErrResumableUploadsUnsupported the server doesn't support resumable uploads, the code has to be pushed with PushCode
+var ErrResumableUploadsUnsupported = errors.New("resumable uploads are not supported")
+
+// CodeUpload is a resumable upload of code, the server keeps the acknowledged chunks of an upload so that it can be
+// continued by a later push with the same code
+type CodeUpload struct {
+	ID string `json:"upload_id"`
+	// Received is how much of the code the server acknowledged, the upload continues from there
+	Received int64 `json:"received"`
+	Size     int64 `json:"size"`
+}
+
+// UploadCodeRequest upload code request
+type UploadCodeRequest struct {
+	// Digest is the hex sha256 digest of the code, an unfinished upload of the same code is continued
+	Digest string
+	Code   io.ReaderAt
+	Size   int64
+	// OnResume is called if an unfinished upload is continued, with how much of the code was uploaded before
+	OnResume func(received int64)
+}
+
+// UploadCode uploads code in chunks and continues where an earlier upload of the same code was interrupted. A failed
+// chunk is retried from the offset acknowledged by the server. The uploaded code is added to a build with ReuseCode.
+// It returns ErrResumableUploadsUnsupported if the server doesn't support resumable uploads.
+func (c *DetaClient) UploadCode(r *UploadCodeRequest) error {
+	upload, err := c.startCodeUpload(r.Digest, r.Size)
+	if err != nil {
+		return err
+	}
+	if upload.Received > 0 && r.OnResume != nil {
+		r.OnResume(upload.Received)
+	}
+
+	// the progress of a chunk is reported as progress of the whole code
+	report := c.OnUploadProgress
+	defer func() {
+		c.OnUploadProgress = report
+	}()
+
+	offset := upload.Received
+	failures := 0
+	for offset < r.Size {
+		n := int64(CodeChunkSize)
+		if rest := r.Size - offset; rest < n {
+			n = rest
+		}
+		if report != nil {
+			start := offset
+			c.OnUploadProgress = func(sent int64, _ int64) {
+				report(start+sent, r.Size)
+			}
+		}
+
+		received, err := c.putCodeChunk(upload.ID, offset, io.NewSectionReader(r.Code, offset, n))
+		if err == nil {
+			offset, failures = received, 0
+			continue
+		}
+		if failures++; failures >= maxChunkFailures {
+			return fmt.Errorf("failed to upload code after %d attempts, push again to continue the upload: %w", failures, err)
+		}
+		time.Sleep(chunkRetryDelay << (failures - 1))
+		// the chunk may have arrived even if its response didn't
+		if current, err := c.getCodeUpload(upload.ID); err == nil {
+			offset = current.Received
+		}
+	}
+	return c.completeCodeUpload(upload.ID)
+}
+
+func (c *DetaClient) startCodeUpload(digest string, size int64) (*CodeUpload, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/code/uploads", version),
+		Method:    "POST",
+		Body:      map[string]any{"digest": "sha256:" + digest, "size": size},
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch o.Status {
+	case 404, 405, 501:
+		return nil, ErrResumableUploadsUnsupported
+	}
+	if !(o.Status >= 200 && o.Status <= 299) {
+		return nil, fmt.Errorf("failed to start the upload, %w", o.err())
+	}
+
+	var upload CodeUpload
+	if err := json.Unmarshal(o.Body, &upload); err != nil {
+		return nil, fmt.Errorf("failed to start the upload, %w", err)
+	}
+	return &upload, nil
+}
+
+func (c *DetaClient) getCodeUpload(id string) (*CodeUpload, error) {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/code/uploads/%s", version, url.PathEscape(id)),
+		Method:    "GET",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != 200 {
+		return nil, fmt.Errorf("failed to get the upload, %w", o.err())
+	}
+
+	var upload CodeUpload
+	if err := json.Unmarshal(o.Body, &upload); err != nil {
+		return nil, fmt.Errorf("failed to get the upload, %w", err)
+	}
+	return &upload, nil
+}
+
+// putCodeChunk uploads the chunk at offset, it returns how much of the code the server received
+func (c *DetaClient) putCodeChunk(id string, offset int64, chunk io.ReadSeeker) (int64, error) {
+	o, err := c.request(&requestInput{
+		Root:        spaceRoot,
+		Path:        fmt.Sprintf("/%s/code/uploads/%s", version, url.PathEscape(id)),
+		Method:      "PUT",
+		QueryParams: map[string]string{"offset": strconv.FormatInt(offset, 10)},
+		BodyFile:    chunk,
+		NeedsAuth:   true,
+		ContentType: "application/octet-stream",
+		// a failed chunk is resumed from the offset the server received
+		NoRetry: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !(o.Status >= 200 && o.Status <= 299) {
+		return 0, fmt.Errorf("failed to upload the chunk at %d, %w", offset, o.err())
+	}
+
+	var upload CodeUpload
+	if err := json.Unmarshal(o.Body, &upload); err != nil {
+		return 0, fmt.Errorf("failed to upload the chunk at %d, %w", offset, err)
+	}
+	// a chunk the server didn't acknowledge failed, even if the response was successful
+	if upload.Received <= offset {
+		return 0, fmt.Errorf("failed to upload the chunk at %d, the server didn't acknowledge it", offset)
+	}
+	return upload.Received, nil
+}
+
+func (c *DetaClient) completeCodeUpload(id string) error {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/code/uploads/%s/complete", version, url.PathEscape(id)),
+		Method:    "POST",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return err
+	}
+	if o.Status == 409 {
+		// the upload is aborted, otherwise the next push would continue the corrupted upload
+		mismatch := o.err()
+		if err := c.abortCodeUpload(id); err != nil {
+			return fmt.Errorf("the uploaded code doesn't match its digest and the upload couldn't be aborted, %v: %w", err, mismatch)
+		}
+		return fmt.Errorf("the uploaded code doesn't match its digest, push again to upload it from the start: %w", mismatch)
+	}
+	if !(o.Status >= 200 && o.Status <= 299) {
+		return fmt.Errorf("failed to complete the upload, %w", o.err())
+	}
+	return nil
+}
+
+// abortCodeUpload drops an upload and its chunks, a later push of the same code starts a new upload
+func (c *DetaClient) abortCodeUpload(id string) error {
+	o, err := c.request(&requestInput{
+		Root:      spaceRoot,
+		Path:      fmt.Sprintf("/%s/code/uploads/%s", version, url.PathEscape(id)),
+		Method:    "DELETE",
+		NeedsAuth: true,
+	})
+	if err != nil {
+		return err
+	}
+	if !(o.Status >= 200 && o.Status <= 299) && o.Status != 404 {
+		return fmt.Errorf("failed to abort the upload, %w", o.err())
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// redirectTransport sends all requests to the test server
+type redirectTransport struct {
+	server *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme, req.URL.Host = t.server.Scheme, t.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// uploadServer keeps the chunks of one upload, the response of the chunk at failAt is lost once after it was stored.
+// A stalled server acknowledges chunks without storing them, the digest of a mismatched upload doesn't match.
+type uploadServer struct {
+	received []byte
+	failAt   int64
+	failed   bool
+	stalled  bool
+	mismatch bool
+	complete bool
+	aborted  bool
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v0/code/uploads":
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v0/code/uploads/abc":
+	case r.Method == http.MethodPut && r.URL.Path == "/api/v0/code/uploads/abc":
+		offset, _ := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+		if offset != int64(len(s.received)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		chunk, _ := io.ReadAll(r.Body)
+		if s.stalled {
+			break
+		}
+		s.received = append(s.received, chunk...)
+		if offset == s.failAt && !s.failed {
+			s.failed = true
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v0/code/uploads/abc/complete":
+		if s.mismatch {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"detail": "Digest mismatch"}`)
+			return
+		}
+		s.complete = true
+		return
+	case r.Method == http.MethodDelete && r.URL.Path == "/api/v0/code/uploads/abc":
+		s.aborted = true
+		return
+	default:
+		notFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(CodeUpload{ID: "abc", Received: int64(len(s.received))})
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprint(w, `{"detail": "Not found"}`)
+}
+
+func TestUploadCode(t *testing.T) {
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+	chunkRetryDelay = 0
+
+	code := make([]byte, 2*CodeChunkSize+100)
+	rand.New(rand.NewSource(1)).Read(code)
+
+	cases := []struct {
+		name    string
+		before  int64
+		failAt  int64
+		resumed int64
+	}{
+		{name: "lost response", failAt: CodeChunkSize, resumed: -1},
+		{name: "resumed", before: CodeChunkSize + 10, failAt: -1, resumed: CodeChunkSize + 10},
+	}
+
+	for _, c := range cases {
+		s := &uploadServer{received: append([]byte{}, code[:c.before]...), failAt: c.failAt}
+		server := httptest.NewServer(s)
+		serverURL, _ := url.Parse(server.URL)
+
+		var sent int64
+		resumed := int64(-1)
+		client := &DetaClient{
+			Client:           &http.Client{Transport: redirectTransport{server: serverURL}},
+			OnUploadProgress: func(s int64, _ int64) { sent = s },
+		}
+		err := client.UploadCode(&UploadCodeRequest{
+			Digest: "0123", Code: bytes.NewReader(code), Size: int64(len(code)),
+			OnResume: func(received int64) { resumed = received },
+		})
+		server.Close()
+
+		assert.NilError(t, err, c.name)
+		assert.Assert(t, s.complete, c.name)
+		assert.Assert(t, bytes.Equal(s.received, code), c.name)
+		assert.Equal(t, resumed, c.resumed, c.name)
+		assert.Equal(t, sent, int64(len(code)), c.name)
+	}
+}
+
+func TestUploadCodeUnsupported(t *testing.T) {
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+	server := httptest.NewServer(http.HandlerFunc(notFound))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	client := &DetaClient{Client: &http.Client{Transport: redirectTransport{server: serverURL}}}
+	err := client.UploadCode(&UploadCodeRequest{Digest: "0123", Code: bytes.NewReader(nil), Size: 0})
+	assert.ErrorIs(t, err, ErrResumableUploadsUnsupported)
+}
+
+func TestUploadCodeFailed(t *testing.T) {
+	t.Setenv("SPACE_ACCESS_TOKEN", "abc_def")
+	chunkRetryDelay = 0
+
+	code := make([]byte, 100)
+	rand.New(rand.NewSource(1)).Read(code)
+
+	cases := []struct {
+		name     string
+		server   *uploadServer
+		expected string
+		aborted  bool
+	}{
+		{
+			name:     "chunk not acknowledged",
+			server:   &uploadServer{failAt: -1, stalled: true},
+			expected: "failed to upload code after 5 attempts",
+		},
+		{
+			name:     "digest mismatch",
+			server:   &uploadServer{failAt: -1, mismatch: true},
+			expected: "doesn't match its digest, push again to upload it from the start",
+			aborted:  true,
+		},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(c.server)
+		serverURL, _ := url.Parse(server.URL)
+
+		client := &DetaClient{Client: &http.Client{Transport: redirectTransport{server: serverURL}}}
+		err := client.UploadCode(&UploadCodeRequest{Digest: "0123", Code: bytes.NewReader(code), Size: int64(len(code))})
+		server.Close()
+
+		assert.ErrorContains(t, err, c.expected, c.name)
+		assert.Assert(t, !c.server.complete, c.name)
+		assert.Equal(t, c.server.aborted, c.aborted, c.name)
+	}
+}
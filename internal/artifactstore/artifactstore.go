@@ -0,0 +1,233 @@
+// Package artifactstore keeps the archives of pushed projects keyed by the digest of their content, so that pushing
+// a tree which was pushed before, e.g. after switching back to a branch, reuses its archive instead of archiving it
+// again
+package artifactstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DirEnv overrides the directory of the store
+	DirEnv = "SPACE_ARTIFACTS_DIR"
+
+	// DefaultMaxSize is the size of the stored archives above which the least recently used ones are removed
+	DefaultMaxSize = 1 << 30
+
+	// archiveExt is the extension of the stored archives, the name of an archive is its digest
+	archiveExt = ".zip"
+	// lastDir has a file per project with the digest of the archive pushed last
+	lastDir = "last"
+)
+
+// ErrNotFound no archive is stored for the digest
+var ErrNotFound = errors.New("archive not found in the artifact store")
+
+// Store is a directory of archives named after their digest
+type Store struct {
+	Dir string
+	// MaxSize is the size of the stored archives in bytes above which the least recently used ones are removed
+	MaxSize int64
+}
+
+// DefaultDir returns the directory of the store, $SPACE_ARTIFACTS_DIR or the artifacts directory in the space
+// directory of the cache directory of the user, e.g. ~/.cache/space/artifacts on Linux
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the cache directory, set %s instead: %w", DirEnv, err)
+	}
+	return filepath.Join(cache, "space", "artifacts"), nil
+}
+
+// Open returns the store in the default directory
+func Open() (*Store, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Dir: dir, MaxSize: DefaultMaxSize}, nil
+}
+
+func (s *Store) path(digest string) (string, error) {
+	if !validName(digest) {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return filepath.Join(s.Dir, digest+archiveExt), nil
+}
+
+// validName reports if name can be used as name of a file of the store
+func validName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\.`)
+}
+
+// Get opens the archive stored for digest and marks it as used, it returns ErrNotFound if there is none
+func (s *Store) Get(digest string) (*os.File, error) {
+	path, err := s.path(digest)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	// the modification time tracks the last use, a store which can't be written is still read
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return f, nil
+}
+
+// Put stores the archive read from r under digest, then removes the least recently used archives above the maximum
+// size. The archive is renamed into place, so that a concurrent push never reads a partial archive.
+func (s *Store) Put(digest string, r io.Reader) error {
+	path, err := s.path(digest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the artifact store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.Dir, ".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to store the archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store the archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store the archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store the archive: %w", err)
+	}
+
+	_, err = s.Prune()
+	return err
+}
+
+// SetLast records the digest of the archive pushed last to the project, its archive is the base of the delta of the
+// next push
+func (s *Store) SetLast(projectID string, digest string) error {
+	if !validName(projectID) || !validName(digest) {
+		return fmt.Errorf("invalid project id %q or digest %q", projectID, digest)
+	}
+	dir := filepath.Join(s.Dir, lastDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the artifact store: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, projectID), []byte(digest), 0o644)
+}
+
+// Last returns the digest of the archive pushed last to the project, it returns ErrNotFound if none was recorded
+func (s *Store) Last(projectID string) (string, error) {
+	if !validName(projectID) {
+		return "", fmt.Errorf("invalid project id %q", projectID)
+	}
+	content, err := os.ReadFile(filepath.Join(s.Dir, lastDir, projectID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	} else if err != nil {
+		return "", err
+	}
+	digest := strings.TrimSpace(string(content))
+	if !validName(digest) {
+		return "", ErrNotFound
+	}
+	return digest, nil
+}
+
+type storedArchive struct {
+	path   string
+	size   int64
+	usedAt time.Time
+}
+
+func (s *Store) archives() ([]storedArchive, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var archives []storedArchive
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != archiveExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		archives = append(archives, storedArchive{path: filepath.Join(s.Dir, entry.Name()), size: info.Size(), usedAt: info.ModTime()})
+	}
+	return archives, nil
+}
+
+// Prune removes the least recently used archives until the stored archives fit into the maximum size, it returns the
+// number of removed archives. A MaxSize of 0 keeps all archives.
+func (s *Store) Prune() (int, error) {
+	if s.MaxSize <= 0 {
+		return 0, nil
+	}
+	archives, err := s.archives()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, a := range archives {
+		total += a.size
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].usedAt.Before(archives[j].usedAt)
+	})
+
+	removed := 0
+	for _, a := range archives {
+		if total <= s.MaxSize {
+			break
+		}
+		if err := os.Remove(a.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		total -= a.size
+		removed++
+	}
+	return removed, nil
+}
+
+// Clear removes all stored archives, it returns the number of removed archives and their size
+func (s *Store) Clear() (int, int64, error) {
+	archives, err := s.archives()
+	if err != nil {
+		return 0, 0, err
+	}
+	var size int64
+	for i, a := range archives {
+		if err := os.Remove(a.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return i, size, err
+		}
+		size += a.size
+	}
+	if err := os.RemoveAll(filepath.Join(s.Dir, lastDir)); err != nil {
+		return len(archives), size, err
+	}
+	return len(archives), size, nil
+}
@@ -0,0 +1,88 @@
+package artifactstore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPutGet(t *testing.T) {
+	s := &Store{Dir: filepath.Join(t.TempDir(), "artifacts")}
+
+	_, err := s.Get("abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NilError(t, s.Put("abc", strings.NewReader("zip")))
+	f, err := s.Get("abc")
+	assert.NilError(t, err)
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "zip")
+
+	_, err = s.Get("../abc")
+	assert.ErrorContains(t, err, "invalid digest")
+}
+
+func TestLast(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+
+	_, err := s.Last("project")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NilError(t, s.SetLast("project", "abc"))
+	assert.NilError(t, s.SetLast("project", "def"))
+	digest, err := s.Last("project")
+	assert.NilError(t, err)
+	assert.Equal(t, digest, "def")
+
+	_, _, err = s.Clear()
+	assert.NilError(t, err)
+	_, err = s.Last("project")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPrune(t *testing.T) {
+	cases := []struct {
+		name     string
+		maxSize  int64
+		used     string
+		expected []string
+	}{
+		{name: "fits", maxSize: 30, expected: []string{"a.zip", "b.zip", "c.zip"}},
+		{name: "least recently used", maxSize: 20, expected: []string{"b.zip", "c.zip"}},
+		{name: "used again", maxSize: 20, used: "a", expected: []string{"a.zip", "c.zip"}},
+		{name: "unlimited", maxSize: 0, expected: []string{"a.zip", "b.zip", "c.zip"}},
+	}
+
+	for _, c := range cases {
+		s := &Store{Dir: t.TempDir(), MaxSize: c.maxSize}
+		start := time.Now().Add(-time.Hour)
+		for i, digest := range []string{"a", "b", "c"} {
+			path := filepath.Join(s.Dir, digest+archiveExt)
+			assert.NilError(t, os.WriteFile(path, make([]byte, 10), 0o644))
+			usedAt := start.Add(time.Duration(i) * time.Minute)
+			assert.NilError(t, os.Chtimes(path, usedAt, usedAt))
+		}
+		if c.used != "" {
+			f, err := s.Get(c.used)
+			assert.NilError(t, err)
+			f.Close()
+		}
+
+		_, err := s.Prune()
+		assert.NilError(t, err)
+		entries, err := os.ReadDir(s.Dir)
+		assert.NilError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		assert.DeepEqual(t, names, c.expected)
+	}
+}
@@ -1,9 +1,10 @@
 package auth
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,22 +13,34 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+
+	"github.com/deta/space/internal/container"
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/internal/home"
+)
+
+const (
+	// SpaceAccessTokenEnv holds the access token, it takes precedence over the stored token of space login
+	SpaceAccessTokenEnv = "SPACE_ACCESS_TOKEN"
+	// SpaceAccessTokenFileEnv points to a file holding the access token, e.g. a docker secret
+	SpaceAccessTokenFileEnv = "SPACE_ACCESS_TOKEN_FILE"
+	// DefaultSecretPath is where the access token is read from in containers if no other source is set
+	DefaultSecretPath = container.SecretPath
 )
 
 const (
-	spaceAccessTokenEnv         = "SPACE_ACCESS_TOKEN"
 	spaceTokensFile             = "space_tokens"
 	spaceSignVersion            = "v0"
-	spaceDir                    = ".detaspace"
 	oldSpaceDir                 = ".deta"
 	dirModePermReadWriteExecute = 0760
 	fileModePermReadWrite       = 0660
-	spaceProjectKeysPath        = ".detaspace/space_project_keys"
+	spaceProjectKeysFile        = "space_project_keys"
 )
 
 var (
-	spaceAuthTokenPath    = filepath.Join(spaceDir, spaceTokensFile)
 	oldSpaceAuthTokenPath = filepath.Join(oldSpaceDir, spaceTokensFile)
+	// secretPath is DefaultSecretPath, replaced in tests
+	secretPath = DefaultSecretPath
 
 	// ErrNoProjectKeyFound no access token found
 	ErrNoProjectKeyFound = errors.New("no project key was found or was empty")
@@ -44,13 +57,12 @@ type Token struct {
 }
 
 func getAccessTokenFromFile(filepath string) (string, error) {
-	f, err := os.Open(filepath)
+	contents, err := crypt.ReadFile(filepath)
 	if err != nil {
 		return "", fmt.Errorf("os.Open: %w", err)
 	}
-	defer f.Close()
 	var t Token
-	if err := json.NewDecoder(f).Decode(&t); err != nil {
+	if err := json.Unmarshal(contents, &t); err != nil {
 		return "", fmt.Errorf("%w: %s", ErrBadAccessTokenFile, filepath)
 	}
 	if t.AccessToken == "" {
@@ -59,67 +71,55 @@ func getAccessTokenFromFile(filepath string) (string, error) {
 	return t.AccessToken, nil
 }
 
+// getAccessTokenFromSecret reads a file which only contains the access token
+func getAccessTokenFromSecret(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read access token from %s: %w", path, err)
+	}
+
+	accessToken := strings.TrimSpace(string(contents))
+	if accessToken == "" {
+		return "", ErrNoAccessTokenFound
+	}
+	return accessToken, nil
+}
+
 // GetAccessToken retrieves the tokens from storage or env var
 func GetAccessToken() (string, error) {
 	// preference to env var first
-	spaceAccessToken := os.Getenv(spaceAccessTokenEnv)
+	spaceAccessToken := os.Getenv(SpaceAccessTokenEnv)
 	if spaceAccessToken != "" {
 		return spaceAccessToken, nil
 	}
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	if path := os.Getenv(SpaceAccessTokenFileEnv); path != "" {
+		return getAccessTokenFromSecret(path)
 	}
 
-	tokensFilePath := filepath.Join(home, spaceAuthTokenPath)
-	accessToken, err := getAccessTokenFromFile(tokensFilePath)
-	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return accessToken, fmt.Errorf("failed to get access token from file: %w", err)
-		}
-		// fallback to old space auth token path
-		tokensFilePath = filepath.Join(home, oldSpaceAuthTokenPath)
-		accessToken, err = getAccessTokenFromFile(tokensFilePath)
-		if err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				return accessToken, fmt.Errorf("failed to get access token from file: %w", err)
-			}
+	// containers only use the environment and secret mounts, a token stored by space login is never read
+	if container.Dockerized() {
+		accessToken, err := getAccessTokenFromSecret(secretPath)
+		if errors.Is(err, os.ErrNotExist) {
 			return "", ErrNoAccessTokenFound
 		}
-		// store access token in new token directory if old directory
-		if err := StoreAccessToken(accessToken); err != nil {
-			return "", fmt.Errorf("failed to store access token from old token path to new path: %w", err)
-		}
+		return accessToken, err
 	}
-	return accessToken, nil
+
+	return getStoredAccessToken()
 }
 
 func storeAccessToken(t *Token, path string) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, dirModePermReadWriteExecute); err != nil {
-		return fmt.Errorf("failed to create dir %s: %w", dir, err)
+		return fmt.Errorf("failed to create dir %s: %w", dir, home.WrapWriteError(err))
 	}
 	marshalled, err := json.Marshal(t)
 	if err != nil {
 		return fmt.Errorf("failed to marshall token: %w", err)
 	}
-	if err := os.WriteFile(path, marshalled, fileModePermReadWrite); err != nil {
-		return fmt.Errorf("failed to write token to file %s: %w", path, err)
-	}
-	return nil
-}
-
-// StoreAccessToken in the access token directory
-func StoreAccessToken(accessToken string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get user home directory: %w", err)
-	}
-	tokensFilePath := filepath.Join(home, spaceAuthTokenPath)
-	t := &Token{AccessToken: accessToken}
-	if err := storeAccessToken(t, tokensFilePath); err != nil {
-		return err
+	if err := crypt.WriteFile(path, marshalled, fileModePermReadWrite); err != nil {
+		return fmt.Errorf("failed to write token to file %s: %w", path, home.WrapWriteError(err))
 	}
 	return nil
 }
@@ -132,6 +132,8 @@ type CalcSignatureInput struct {
 	Timestamp   string
 	ContentType string
 	RawBody     []byte
+	// Body is read instead of RawBody if set, so that big bodies don't have to be held in memory
+	Body io.Reader
 }
 
 // CalcSignature calculates the signature for signing the requests
@@ -144,16 +146,24 @@ func CalcSignature(i *CalcSignatureInput) (string, error) {
 	accessKeyID := tokenParts[0]
 	accessKeySecret := tokenParts[1]
 
-	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n",
+	body := i.Body
+	if body == nil {
+		body = bytes.NewReader(i.RawBody)
+	}
+
+	mac := hmac.New(sha256.New, []byte(accessKeySecret))
+	_, err := fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n",
 		i.HTTPMethod,
 		i.URI,
 		i.Timestamp,
 		i.ContentType,
-		i.RawBody,
 	)
-
-	mac := hmac.New(sha256.New, []byte(accessKeySecret))
-	_, err := mac.Write([]byte(stringToSign))
+	if err == nil {
+		_, err = io.Copy(mac, body)
+	}
+	if err == nil {
+		_, err = mac.Write([]byte("\n"))
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to calculate hmac: %w", err)
 	}
@@ -167,20 +177,17 @@ type Keys map[string]string
 
 // GetProjectKey retrieves a project key storage or env var
 func GetProjectKey(projectId string) (string, error) {
-	home, err := os.UserHomeDir()
+	keysFilePath, err := home.Path(spaceProjectKeysFile)
 	if err != nil {
 		return "", nil
 	}
 
-	keysFilePath := filepath.Join(home, spaceProjectKeysPath)
-	f, err := os.Open(keysFilePath)
+	contents, err := crypt.ReadFile(keysFilePath)
 	if err != nil && !os.IsNotExist(err) {
 		return "", err
 	}
-	defer f.Close()
 
 	var keys Keys
-	contents, _ := ioutil.ReadAll(f)
 	json.Unmarshal(contents, &keys)
 
 	if key, ok := keys[projectId]; ok {
@@ -191,13 +198,7 @@ func GetProjectKey(projectId string) (string, error) {
 }
 
 func StoreProjectKey(projectId string, projectKey string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	spaceDirPath := filepath.Join(home, spaceDir)
-	err = os.MkdirAll(spaceDirPath, 0760)
+	keysFilePath, err := home.PrepareWrite(spaceProjectKeysFile)
 	if err != nil {
 		return err
 	}
@@ -210,10 +211,22 @@ func StoreProjectKey(projectId string, projectKey string) error {
 		return err
 	}
 
-	keysFilePath := filepath.Join(home, spaceProjectKeysPath)
-	err = ioutil.WriteFile(keysFilePath, marshalled, 0660)
+	err = crypt.WriteFile(keysFilePath, marshalled, 0660)
 	if err != nil {
-		return err
+		return home.WrapWriteError(err)
 	}
 	return nil
 }
+
+// StateFiles returns the paths of the files holding the tokens and project keys of the user
+func StateFiles() ([]string, error) {
+	tokensFilePath, err := home.Path(spaceTokensFile)
+	if err != nil {
+		return nil, err
+	}
+	keysFilePath, err := home.Path(spaceProjectKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	return []string{tokensFilePath, keysFilePath}, nil
+}
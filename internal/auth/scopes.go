@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+)
+
+// Scope is a permission of an access token
+type Scope string
+
+const (
+	// ScopeAll is the scope of account tokens, e.g. the token of space login, which can do anything the account can
+	ScopeAll Scope = "*"
+	// ScopeProjectsRead reads projects, builds, releases, logs and environment variables
+	ScopeProjectsRead Scope = "projects:read"
+	// ScopeProjectsWrite pushes and releases projects and changes their settings, it includes ScopeProjectsRead
+	ScopeProjectsWrite Scope = "projects:write"
+	// ScopeProjectsCreate creates new projects
+	ScopeProjectsCreate Scope = "projects:create"
+	// ScopeData reads and writes the Bases and Drives of projects
+	ScopeData Scope = "data"
+)
+
+// TokenKind tells account tokens from narrow tokens
+type TokenKind string
+
+const (
+	// TokenAccount can do anything the account can
+	TokenAccount TokenKind = "account"
+	// TokenProject is restricted to some projects and scopes, e.g. for a CI pipeline
+	TokenProject TokenKind = "project"
+)
+
+// commandScopes are the scopes the commands need, keyed by their path without the leading space. Commands which
+// aren't listed either don't use the api or need an account token.
+var commandScopes = map[string][]Scope{
+	"push":               {ScopeProjectsWrite},
+	"release":            {ScopeProjectsWrite},
+	"release rollback":   {ScopeProjectsWrite},
+	"release show":       {ScopeProjectsRead},
+	"release explain":    {ScopeProjectsRead},
+	"release notes edit": {ScopeProjectsWrite},
+	"logs":               {ScopeProjectsRead},
+	"status":             {ScopeProjectsRead},
+	"open":               {ScopeProjectsRead},
+	"link":               {ScopeProjectsRead},
+	"new":                {ScopeProjectsCreate},
+	"import":             {ScopeProjectsCreate},
+	"export":             {ScopeProjectsRead},
+	"exec":               {ScopeData},
+	"preview create":     {ScopeProjectsWrite},
+	"preview cleanup":    {ScopeProjectsWrite},
+	"env list":           {ScopeProjectsRead},
+	"env pull":           {ScopeProjectsRead},
+	"env set":            {ScopeProjectsWrite},
+	"env unset":          {ScopeProjectsWrite},
+	"env push":           {ScopeProjectsWrite},
+	"flags get":          {ScopeData},
+	"flags list":         {ScopeData},
+	"flags set":          {ScopeData},
+	"drive sync":         {ScopeData},
+	"maintenance on":     {ScopeProjectsWrite},
+	"maintenance off":    {ScopeProjectsWrite},
+	"revisions list":     {ScopeProjectsRead},
+	"revisions show":     {ScopeProjectsRead},
+	"discovery edit":     {ScopeProjectsWrite},
+	"project clone":      {ScopeProjectsRead, ScopeProjectsCreate},
+	"cron run":           {ScopeData},
+	"quota":              {ScopeProjectsRead},
+	"calendar":           {ScopeProjectsRead},
+}
+
+// CommandScopes returns the scopes the command needs by its path without the leading space, e.g. "env set". It
+// returns false if the command isn't known to work with a narrow token.
+func CommandScopes(command string) ([]Scope, bool) {
+	scopes, ok := commandScopes[command]
+	return scopes, ok
+}
+
+// Allows reports if the granted scopes include scope
+func Allows(granted []Scope, scope Scope) bool {
+	for _, g := range granted {
+		if g == ScopeAll || g == scope || g == ScopeProjectsWrite && scope == ScopeProjectsRead {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopedCommands returns the commands which a token with the granted scopes can run, sorted by name
+func ScopedCommands(granted []Scope) []string {
+	var commands []string
+	for command, scopes := range commandScopes {
+		allowed := true
+		for _, scope := range scopes {
+			allowed = allowed && Allows(granted, scope)
+		}
+		if allowed {
+			commands = append(commands, command)
+		}
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// Overprivileged reports if an account token is used for a command which a project token with fewer scopes could run
+func Overprivileged(kind TokenKind, command string) bool {
+	if kind != TokenAccount {
+		return false
+	}
+	_, ok := CommandScopes(strings.TrimSpace(command))
+	return ok
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestAllows(t *testing.T) {
+	cases := []struct {
+		granted  []Scope
+		scope    Scope
+		expected bool
+	}{
+		{granted: []Scope{ScopeAll}, scope: ScopeData, expected: true},
+		{granted: []Scope{ScopeProjectsWrite}, scope: ScopeProjectsRead, expected: true},
+		{granted: []Scope{ScopeProjectsRead}, scope: ScopeProjectsWrite, expected: false},
+		{granted: []Scope{ScopeProjectsRead, ScopeData}, scope: ScopeData, expected: true},
+		{granted: nil, scope: ScopeProjectsRead, expected: false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, Allows(c.granted, c.scope), c.expected, "%v allows %s", c.granted, c.scope)
+	}
+}
+
+func TestScopedCommands(t *testing.T) {
+	commands := ScopedCommands([]Scope{ScopeProjectsRead})
+	assert.Assert(t, contains(commands, "logs"))
+	assert.Assert(t, contains(commands, "env pull"))
+	assert.Assert(t, !contains(commands, "push"))
+	assert.Assert(t, !contains(commands, "project clone"))
+
+	commands = ScopedCommands([]Scope{ScopeProjectsWrite})
+	assert.Assert(t, contains(commands, "push"))
+	assert.Assert(t, contains(commands, "logs"))
+	assert.Assert(t, !contains(commands, "flags set"))
+}
+
+func TestOverprivileged(t *testing.T) {
+	cases := []struct {
+		kind     TokenKind
+		command  string
+		expected bool
+	}{
+		{kind: TokenAccount, command: "push", expected: true},
+		{kind: TokenAccount, command: "env set", expected: true},
+		{kind: TokenAccount, command: "login", expected: false},
+		{kind: TokenProject, command: "push", expected: false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, Overprivileged(c.kind, c.command), c.expected, "%s %s", c.kind, c.command)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
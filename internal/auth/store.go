@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/deta/space/internal/container"
+	"github.com/deta/space/internal/home"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// TokenStoreEnv chooses where space login stores the access token: auto, keyring or file
+	TokenStoreEnv = "SPACE_TOKEN_STORE"
+
+	// TokenStoreAuto uses the keyring of the system if it's available and the file otherwise
+	TokenStoreAuto = "auto"
+	// TokenStoreKeyring uses the keyring of the system: the macOS Keychain, the Windows Credential Manager or the
+	// Secret Service of Linux, e.g. GNOME Keyring or KWallet
+	TokenStoreKeyring = "keyring"
+	// TokenStoreFile uses the tokens file in the directory of the global state
+	TokenStoreFile = "file"
+
+	// KeyringService is the service of the secrets of the cli in the keyring of the system
+	KeyringService = "deta-space-cli"
+	keyringUser    = "access_token"
+)
+
+// TokenStore keeps the access token of space login
+type TokenStore interface {
+	// Name describes where the token is kept, e.g. for the message of space login
+	Name() string
+	// Get returns ErrNoAccessTokenFound if the store has no token
+	Get() (string, error)
+	Set(accessToken string) error
+	// Delete removes the token, it's no error if the store has none
+	Delete() error
+}
+
+// KeyringStore keeps the access token in the keyring of the system
+type KeyringStore struct{}
+
+func (KeyringStore) Name() string {
+	return "the keyring of your system"
+}
+
+func (KeyringStore) Get() (string, error) {
+	accessToken, err := keyring.Get(KeyringService, keyringUser)
+	if errors.Is(err, keyring.ErrNotFound) || err == nil && accessToken == "" {
+		return "", ErrNoAccessTokenFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the access token from the keyring: %w", err)
+	}
+	return accessToken, nil
+}
+
+func (KeyringStore) Set(accessToken string) error {
+	if err := keyring.Set(KeyringService, keyringUser, accessToken); err != nil {
+		return fmt.Errorf("failed to store the access token in the keyring: %w", err)
+	}
+	return nil
+}
+
+func (KeyringStore) Delete() error {
+	if err := keyring.Delete(KeyringService, keyringUser); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to remove the access token from the keyring: %w", err)
+	}
+	return nil
+}
+
+// FileStore keeps the access token in the tokens file of the global state, encrypted if the state is
+type FileStore struct{}
+
+func (FileStore) Name() string {
+	path, err := home.Path(spaceTokensFile)
+	if err != nil {
+		return spaceTokensFile
+	}
+	return path
+}
+
+func (FileStore) Get() (string, error) {
+	tokensFilePath, err := home.Path(spaceTokensFile)
+	if err != nil {
+		return "", err
+	}
+
+	accessToken, err := getAccessTokenFromFile(tokensFilePath)
+	if err == nil {
+		return accessToken, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return accessToken, fmt.Errorf("failed to get access token from file: %w", err)
+	}
+
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", ErrNoAccessTokenFound
+	}
+	// fallback to old space auth token path
+	tokensFilePath = filepath.Join(userHome, oldSpaceAuthTokenPath)
+	accessToken, err = getAccessTokenFromFile(tokensFilePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return accessToken, fmt.Errorf("failed to get access token from file: %w", err)
+		}
+		return "", ErrNoAccessTokenFound
+	}
+	// store access token in new token directory if old directory, the old token keeps working if that's not possible
+	if err := (FileStore{}).Set(accessToken); err != nil && !errors.Is(err, home.ErrNoState) && !errors.Is(err, home.ErrReadOnly) {
+		return "", fmt.Errorf("failed to store access token from old token path to new path: %w", err)
+	}
+	return accessToken, nil
+}
+
+func (FileStore) Set(accessToken string) error {
+	tokensFilePath, err := home.PrepareWrite(spaceTokensFile)
+	if err != nil {
+		return err
+	}
+	return storeAccessToken(&Token{AccessToken: accessToken}, tokensFilePath)
+}
+
+func (FileStore) Delete() error {
+	tokensFilePath, err := home.Path(spaceTokensFile)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(tokensFilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return home.WrapWriteError(err)
+	}
+	return nil
+}
+
+var (
+	storedMu sync.Mutex
+	// storedToken is the stored token once it was read, so that the keyring isn't asked for every request
+	storedToken string
+)
+
+// TokenStoreMode returns the store chosen with TokenStoreEnv, it applies to the tokens of the registries as well. The
+// keyring isn't used in the dockerized mode, so the tokens file is chosen there.
+func TokenStoreMode() (string, error) {
+	mode := os.Getenv(TokenStoreEnv)
+	switch mode {
+	case "":
+		mode = TokenStoreAuto
+	case TokenStoreAuto, TokenStoreKeyring, TokenStoreFile:
+	default:
+		return "", fmt.Errorf("%s must be one of %s, %s or %s", TokenStoreEnv, TokenStoreAuto, TokenStoreKeyring, TokenStoreFile)
+	}
+	if container.Dockerized() {
+		if mode == TokenStoreKeyring {
+			return "", fmt.Errorf("%s=%s can't be used in the dockerized mode, the keyring isn't used in containers", TokenStoreEnv, mode)
+		}
+		return TokenStoreFile, nil
+	}
+	return mode, nil
+}
+
+// getStoredAccessToken returns the token stored by space login. A token in the file is moved to the keyring if the
+// keyring is available, so that it isn't kept on disk.
+func getStoredAccessToken() (string, error) {
+	storedMu.Lock()
+	defer storedMu.Unlock()
+	if storedToken != "" {
+		return storedToken, nil
+	}
+	accessToken, err := readStoredAccessToken()
+	if err != nil {
+		return accessToken, err
+	}
+	storedToken = accessToken
+	return accessToken, nil
+}
+
+func readStoredAccessToken() (string, error) {
+	mode, err := TokenStoreMode()
+	if err != nil {
+		return "", err
+	}
+	switch mode {
+	case TokenStoreKeyring:
+		return KeyringStore{}.Get()
+	case TokenStoreFile:
+		return FileStore{}.Get()
+	}
+
+	accessToken, keyringErr := KeyringStore{}.Get()
+	if keyringErr == nil {
+		return accessToken, nil
+	}
+	accessToken, err = FileStore{}.Get()
+	if err != nil {
+		return accessToken, err
+	}
+	// the keyring is only available if it answered, a failed move keeps the token in the file
+	if errors.Is(keyringErr, ErrNoAccessTokenFound) && !home.NoState() {
+		if err := (KeyringStore{}).Set(accessToken); err == nil {
+			FileStore{}.Delete()
+		}
+	}
+	return accessToken, nil
+}
+
+// StoreAccessToken stores the access token in the keyring of the system, or in the tokens file if the keyring isn't
+// available or TokenStoreEnv chooses the file. It returns the store which keeps the token.
+func StoreAccessToken(accessToken string) (TokenStore, error) {
+	store, err := storeAccessTokenIn(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	storedMu.Lock()
+	storedToken = accessToken
+	storedMu.Unlock()
+	return store, nil
+}
+
+func storeAccessTokenIn(accessToken string) (TokenStore, error) {
+	if home.NoState() {
+		return nil, home.ErrNoState
+	}
+	mode, err := TokenStoreMode()
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case TokenStoreKeyring:
+		return KeyringStore{}, KeyringStore{}.Set(accessToken)
+	case TokenStoreFile:
+		return FileStore{}, FileStore{}.Set(accessToken)
+	}
+
+	if err := (KeyringStore{}).Set(accessToken); err == nil {
+		// a token of a previous login mustn't stay on disk
+		if err := (FileStore{}).Delete(); err != nil {
+			return nil, fmt.Errorf("stored the access token in the keyring but failed to remove the old token file: %w", err)
+		}
+		return KeyringStore{}, nil
+	}
+	return FileStore{}, FileStore{}.Set(accessToken)
+}
+
+// DeleteStoredAccessToken removes the token of space login from the keyring and the tokens file, e.g. once its
+// session was revoked
+func DeleteStoredAccessToken() error {
+	storedMu.Lock()
+	storedToken = ""
+	storedMu.Unlock()
+
+	if container.Dockerized() {
+		// the keyring isn't used in containers
+		return FileStore{}.Delete()
+	}
+	keyringErr := KeyringStore{}.Delete()
+	if err := (FileStore{}).Delete(); err != nil {
+		return err
+	}
+	if mode, _ := TokenStoreMode(); mode == TokenStoreKeyring {
+		return keyringErr
+	}
+	// without a keyring there is no token to delete in it
+	return nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deta/space/internal/container"
+	"github.com/deta/space/internal/home"
+	"github.com/zalando/go-keyring"
+	"gotest.tools/v3/assert"
+)
+
+func setupStore(t *testing.T, mode string, keyringErr error) string {
+	dir := t.TempDir()
+	t.Setenv(home.HomeEnv, dir)
+	t.Setenv("HOME", dir)
+	t.Setenv(SpaceAccessTokenEnv, "")
+	t.Setenv(SpaceAccessTokenFileEnv, "")
+	t.Setenv(container.DockerizedEnv, "0")
+	t.Setenv(TokenStoreEnv, mode)
+	if keyringErr != nil {
+		keyring.MockInitWithError(keyringErr)
+	} else {
+		keyring.MockInit()
+	}
+	storedToken = ""
+	return filepath.Join(dir, spaceTokensFile)
+}
+
+func TestStoreAccessToken(t *testing.T) {
+	cases := []struct {
+		name       string
+		mode       string
+		keyringErr error
+		expected   TokenStore
+	}{
+		{name: "keyring", mode: "", expected: KeyringStore{}},
+		{name: "keyring unavailable", mode: TokenStoreAuto, keyringErr: errors.New("no secret service"), expected: FileStore{}},
+		{name: "file", mode: TokenStoreFile, expected: FileStore{}},
+	}
+
+	for _, c := range cases {
+		tokensFile := setupStore(t, c.mode, c.keyringErr)
+
+		store, err := StoreAccessToken("abc_def")
+		assert.NilError(t, err, c.name)
+		assert.Equal(t, store, c.expected, c.name)
+		_, statErr := os.Stat(tokensFile)
+		assert.Equal(t, statErr == nil, c.expected == FileStore{}, c.name)
+
+		storedToken = ""
+		token, err := GetAccessToken()
+		assert.NilError(t, err, c.name)
+		assert.Equal(t, token, "abc_def", c.name)
+	}
+}
+
+func TestStoreAccessTokenKeyringRequired(t *testing.T) {
+	setupStore(t, TokenStoreKeyring, errors.New("no secret service"))
+
+	_, err := StoreAccessToken("abc_def")
+	assert.ErrorContains(t, err, "keyring")
+}
+
+func TestMoveTokenFileToKeyring(t *testing.T) {
+	tokensFile := setupStore(t, TokenStoreAuto, nil)
+	assert.NilError(t, FileStore{}.Set("abc_def"))
+
+	token, err := GetAccessToken()
+	assert.NilError(t, err)
+	assert.Equal(t, token, "abc_def")
+
+	_, err = os.Stat(tokensFile)
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+	token, err = KeyringStore{}.Get()
+	assert.NilError(t, err)
+	assert.Equal(t, token, "abc_def")
+}
+
+func TestDeleteStoredAccessToken(t *testing.T) {
+	tokensFile := setupStore(t, TokenStoreAuto, nil)
+	assert.NilError(t, FileStore{}.Set("abc_def"))
+	assert.NilError(t, KeyringStore{}.Set("abc_def"))
+
+	assert.NilError(t, DeleteStoredAccessToken())
+	_, err := os.Stat(tokensFile)
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+	_, err = GetAccessToken()
+	assert.ErrorIs(t, err, ErrNoAccessTokenFound)
+}
+
+func TestDockerizedAccessToken(t *testing.T) {
+	tokensFile := setupStore(t, TokenStoreAuto, nil)
+	t.Setenv(container.DockerizedEnv, "1")
+	secretPath = filepath.Join(t.TempDir(), "space_access_token")
+	defer func() { secretPath = DefaultSecretPath }()
+
+	// a token stored before isn't used in containers
+	assert.NilError(t, KeyringStore{}.Set("stored_token"))
+	_, err := GetAccessToken()
+	assert.ErrorIs(t, err, ErrNoAccessTokenFound)
+
+	assert.NilError(t, os.WriteFile(secretPath, []byte("secret_token\n"), 0600))
+	token, err := GetAccessToken()
+	assert.NilError(t, err)
+	assert.Equal(t, token, "secret_token")
+
+	// the keyring isn't used to store tokens either
+	store, err := StoreAccessToken("abc_def")
+	assert.NilError(t, err)
+	assert.Equal(t, store, FileStore{})
+	_, err = os.Stat(tokensFile)
+	assert.NilError(t, err)
+	token, err = KeyringStore{}.Get()
+	assert.NilError(t, err)
+	assert.Equal(t, token, "stored_token")
+
+	t.Setenv(TokenStoreEnv, TokenStoreKeyring)
+	_, err = StoreAccessToken("abc_def")
+	assert.ErrorContains(t, err, "dockerized mode")
+}
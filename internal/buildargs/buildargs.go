@@ -0,0 +1,120 @@
+// Package buildargs forwards environment variables to the remote build of a push, e.g. the token of a private package
+// registry, and masks their values in the build logs
+package buildargs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/shared"
+)
+
+const (
+	// Mask replaces the values of build args in the build logs
+	Mask = "***"
+	// minMaskLength is the length from which values are masked, shorter values would mask ordinary words
+	minMaskLength = 4
+)
+
+var nameReg = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Parse parses build args like NPM_TOKEN=abc, an arg without a value like NPM_TOKEN takes its value from the
+// environment. A later arg overrides an earlier one with the same name.
+func Parse(pairs []string, lookupEnv func(string) (string, bool)) (map[string]string, error) {
+	args := make(map[string]string)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !nameReg.MatchString(name) {
+			return nil, fmt.Errorf("invalid build arg name %q, use letters, digits and _", name)
+		}
+		if !ok {
+			if value, ok = lookupEnv(name); !ok {
+				return nil, fmt.Errorf("build arg %s is not set in the environment, use %s=value", name, name)
+			}
+		}
+		args[name] = value
+	}
+	return args, nil
+}
+
+// Resolve returns the build args of every micro by its name: the variables declared in build_args of the micro, with
+// their value from the environment, and the args of the flags, which apply to all micros and take precedence
+func Resolve(micros []*shared.Micro, flagArgs map[string]string, lookupEnv func(string) (string, bool)) (map[string]map[string]string, error) {
+	resolved := make(map[string]map[string]string)
+	for _, micro := range micros {
+		args := make(map[string]string)
+		for _, name := range micro.BuildArgs {
+			if _, ok := flagArgs[name]; ok {
+				continue
+			}
+			value, ok := lookupEnv(name)
+			if !ok {
+				return nil, fmt.Errorf("build arg %s of micro %s is not set in the environment, set it or use --build-arg %s=value", name, micro.Name, name)
+			}
+			args[name] = value
+		}
+		for name, value := range flagArgs {
+			args[name] = value
+		}
+		if len(args) > 0 {
+			resolved[micro.Name] = args
+		}
+	}
+	return resolved, nil
+}
+
+// Names returns the names of the build args in alphabetical order
+func Names(args map[string]string) []string {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Masker replaces the values of build args in log lines
+type Masker struct {
+	replacer *strings.Replacer
+}
+
+// NewMasker returns a masker of the values of the build args of all micros and of secrets which are part of a value,
+// e.g. the tokens in the urls of a build arg
+func NewMasker(args map[string]map[string]string, secrets ...string) *Masker {
+	seen := make(map[string]bool)
+	var values []string
+	add := func(value string) {
+		if len(value) >= minMaskLength && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	for _, microArgs := range args {
+		for _, value := range microArgs {
+			add(value)
+		}
+	}
+	for _, secret := range secrets {
+		add(secret)
+	}
+	// the replacer tries the values in order, a value containing another one has to be masked first
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+
+	var oldnew []string
+	for _, value := range values {
+		oldnew = append(oldnew, value, Mask)
+	}
+	return &Masker{replacer: strings.NewReplacer(oldnew...)}
+}
+
+// Mask replaces the values of the build args in line
+func (m *Masker) Mask(line string) string {
+	return m.replacer.Replace(line)
+}
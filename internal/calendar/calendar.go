@@ -0,0 +1,66 @@
+// Package calendar collects the upcoming events of a project, like freeze windows, scheduled jobs and actions and
+// releases, into a calendar of days
+package calendar
+
+import (
+	"sort"
+	"time"
+)
+
+// Kind of an event
+type Kind string
+
+const (
+	Freeze  Kind = "freeze"
+	Job     Kind = "job"
+	Action  Kind = "action"
+	Release Kind = "release"
+)
+
+// Event happens at Start, events which span whole days like freeze windows are AllDay and last until the day of End
+type Event struct {
+	Kind   Kind
+	Name   string
+	Detail string
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+}
+
+// Day lists the events starting on a day
+type Day struct {
+	Date   time.Time
+	Events []*Event
+}
+
+// Group sorts the events and groups them by the day they start on in loc, all day events come first
+func Group(events []*Event, loc *time.Location) []*Day {
+	sorted := make([]*Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if dayA, dayB := day(a.Start, loc), day(b.Start, loc); !dayA.Equal(dayB) {
+			return dayA.Before(dayB)
+		}
+		if a.AllDay != b.AllDay {
+			return a.AllDay
+		}
+		return a.Start.Before(b.Start)
+	})
+
+	var days []*Day
+	for _, e := range sorted {
+		d := day(e.Start, loc)
+		if n := len(days); n > 0 && days[n-1].Date.Equal(d) {
+			days[n-1].Events = append(days[n-1].Events, e)
+			continue
+		}
+		days = append(days, &Day{Date: d, Events: []*Event{e}})
+	}
+	return days
+}
+
+func day(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
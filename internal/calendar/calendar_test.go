@@ -0,0 +1,25 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGroup(t *testing.T) {
+	at := func(day int, hour int) time.Time {
+		return time.Date(2023, time.November, day, hour, 0, 0, 0, time.UTC)
+	}
+	release := &Event{Kind: Release, Name: "1.2.0", Start: at(17, 15)}
+	cleanup := &Event{Kind: Job, Name: "cleanup", Start: at(18, 3)}
+	weekend := &Event{Kind: Freeze, Name: "weekend", Start: at(18, 0), End: at(19, 0), AllDay: true}
+	report := &Event{Kind: Action, Name: "report", Start: at(17, 9)}
+
+	days := Group([]*Event{release, cleanup, weekend, report}, time.UTC)
+	assert.Equal(t, len(days), 2)
+	assert.Equal(t, days[0].Date, at(17, 0))
+	assert.DeepEqual(t, days[0].Events, []*Event{report, release})
+	assert.Equal(t, days[1].Date, at(18, 0))
+	assert.DeepEqual(t, days[1].Events, []*Event{weekend, cleanup})
+}
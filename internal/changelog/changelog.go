@@ -0,0 +1,60 @@
+// Package changelog reads the sections of changelogs in the Keep a Changelog format, see https://keepachangelog.com
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// headingReg matches the heading of a section, e.g. ## [1.2.0] - 2023-03-05 or ## v1.2.0
+	headingReg = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+	// linkReg matches the link reference definitions at the end of a changelog, e.g. [1.2.0]: https://...
+	linkReg    = regexp.MustCompile(`^\[[^\]]+\]:\s*\S+`)
+	versionReg = regexp.MustCompile(`^v?\d+\.\d+\.\d+`)
+)
+
+// ErrNoSection is returned by Section if the changelog has no section of the version
+var ErrNoSection = errors.New("no section")
+
+// IsChangelog reports whether the content has sections of versions or unreleased changes
+func IsChangelog(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if name, ok := heading(line); ok && (versionReg.MatchString(name) || strings.EqualFold(name, "unreleased")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Section returns the body of the section of the version, a leading v of the version and the headings is ignored
+func Section(content string, version string) (string, error) {
+	version = strings.TrimPrefix(version, "v")
+	var lines []string
+	found := false
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		name, isHeading := heading(line)
+		if found && (isHeading || linkReg.MatchString(line)) {
+			break
+		}
+		if found {
+			lines = append(lines, line)
+			continue
+		}
+		found = isHeading && strings.TrimPrefix(name, "v") == version
+	}
+	if !found {
+		return "", fmt.Errorf("%w of version %s", ErrNoSection, version)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+func heading(line string) (string, bool) {
+	matches := headingReg.FindStringSubmatch(line)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
@@ -0,0 +1,73 @@
+package changelog
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const testChangelog = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+### Added
+- Dark mode
+
+## [1.2.0] - 2023-03-05
+
+### Fixed
+- The login
+
+### Added
+- Export to CSV
+
+## v1.1.0
+
+- First listed release
+
+[Unreleased]: https://github.com/acme/app/compare/v1.2.0...HEAD
+[1.2.0]: https://github.com/acme/app/compare/v1.1.0...v1.2.0
+`
+
+func TestSection(t *testing.T) {
+	cases := []struct {
+		version  string
+		expected string
+		err      error
+	}{
+		{version: "1.2.0", expected: "### Fixed\n- The login\n\n### Added\n- Export to CSV"},
+		{version: "v1.2.0", expected: "### Fixed\n- The login\n\n### Added\n- Export to CSV"},
+		{version: "1.1.0", expected: "- First listed release"},
+		{version: "Unreleased", expected: "### Added\n- Dark mode"},
+		{version: "1.0.0", err: ErrNoSection},
+	}
+
+	for _, c := range cases {
+		section, err := Section(testChangelog, c.version)
+		if c.err != nil {
+			assert.Assert(t, errors.Is(err, c.err), c.version)
+			continue
+		}
+		assert.NilError(t, err, c.version)
+		assert.Equal(t, section, c.expected, c.version)
+	}
+}
+
+func TestIsChangelog(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected bool
+	}{
+		{content: testChangelog, expected: true},
+		{content: "## Unreleased\n", expected: true},
+		{content: "Fixes the login\n\n## Details\n- more", expected: false},
+		{content: "", expected: false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, IsChangelog(c.content), c.expected, c.content)
+	}
+}
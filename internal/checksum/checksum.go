@@ -0,0 +1,102 @@
+package checksum
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrMismatch the checksum of the content does not match the published checksum
+	ErrMismatch = errors.New("checksum mismatch")
+	// ErrNotPublished no checksum was published for a file
+	ErrNotPublished = errors.New("checksum not published")
+)
+
+// Sum returns the hex encoded sha256 checksum of content
+func Sum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks that the sha256 checksum of content matches the hex encoded expected checksum
+func Verify(content []byte, expected string) error {
+	if actual := Sum(content); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrMismatch, expected, actual)
+	}
+	return nil
+}
+
+// File is a checksums file in the format of sha256sum, mapping file names to their checksums
+type File map[string]string
+
+// Parse parses a checksums file in the format of sha256sum, i.e. lines of "<checksum>  <name>"
+func Parse(r io.Reader) (File, error) {
+	checksums := make(File)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid checksums line %q", line)
+		}
+		sum := fields[0]
+		// sha256sum marks files read in binary mode with a leading asterisk
+		name := strings.TrimPrefix(fields[1], "*")
+
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid sha256 checksum %q for %s", sum, name)
+		}
+		checksums[name] = strings.ToLower(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return checksums, nil
+}
+
+// Verify checks the content of the file name against its published checksum
+func (f File) Verify(name string, content []byte) error {
+	expected, ok := f[name]
+	if !ok {
+		return fmt.Errorf("%w for %s", ErrNotPublished, name)
+	}
+	return Verify(content, expected)
+}
+
+// verifyingReader computes the checksum while the content is read and checks it once the end is reached
+type verifyingReader struct {
+	r        io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+// NewVerifyingReader wraps r, reading it returns ErrMismatch instead of io.EOF if the checksum of the
+// content does not match the hex encoded expected checksum
+func NewVerifyingReader(r io.ReadCloser, expected string) io.ReadCloser {
+	return &verifyingReader{r: r, hash: sha256.New(), expected: expected}
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := hex.EncodeToString(v.hash.Sum(nil)); !strings.EqualFold(actual, v.expected) {
+			return n, fmt.Errorf("%w: expected %s, got %s", ErrMismatch, v.expected, actual)
+		}
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error {
+	return v.r.Close()
+}
@@ -0,0 +1,38 @@
+package checksum
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const helloSum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestParse(t *testing.T) {
+	checksums, err := Parse(strings.NewReader(helloSum + "  space-linux-x86_64.zip\n" +
+		strings.ToUpper(helloSum) + " *space-windows-x86_64.zip\n\n"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, checksums, File{
+		"space-linux-x86_64.zip":   helloSum,
+		"space-windows-x86_64.zip": helloSum,
+	})
+
+	assert.NilError(t, checksums.Verify("space-linux-x86_64.zip", []byte("hello")))
+	assert.Assert(t, errors.Is(checksums.Verify("space-linux-x86_64.zip", []byte("hello!")), ErrMismatch))
+	assert.Assert(t, errors.Is(checksums.Verify("space-darwin-arm64.zip", []byte("hello")), ErrNotPublished))
+
+	_, err = Parse(strings.NewReader("abc space.zip\n"))
+	assert.ErrorContains(t, err, "invalid sha256 checksum")
+}
+
+func TestVerifyingReader(t *testing.T) {
+	content, err := io.ReadAll(NewVerifyingReader(io.NopCloser(strings.NewReader("hello")), helloSum))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "hello")
+
+	_, err = io.ReadAll(NewVerifyingReader(io.NopCloser(strings.NewReader("hello!")), helloSum))
+	assert.Assert(t, errors.Is(err, ErrMismatch))
+}
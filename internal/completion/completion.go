@@ -0,0 +1,322 @@
+// Package completion installs the completion scripts of the cli into the profile of the shell of the user
+package completion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/deta/space/internal/home"
+	"github.com/spf13/cobra"
+)
+
+// Shell is a shell with completion support
+type Shell string
+
+const (
+	Bash       Shell = "bash"
+	Zsh        Shell = "zsh"
+	Fish       Shell = "fish"
+	PowerShell Shell = "powershell"
+)
+
+// Shells are the supported shells
+var Shells = []Shell{Bash, Zsh, Fish, PowerShell}
+
+const (
+	blockStart = "# >>> space completion >>>"
+	blockEnd   = "# <<< space completion <<<"
+
+	// verifyTimeout is how long loading the profile to verify the completion may take
+	verifyTimeout = 10 * time.Second
+)
+
+// ErrUnknownShell the shell of the user couldn't be detected
+var ErrUnknownShell = errors.New("failed to detect your shell")
+
+// ParseShell returns the shell with the name, pwsh is an alias of powershell
+func ParseShell(name string) (Shell, error) {
+	name = strings.TrimSuffix(strings.ToLower(filepath.Base(name)), ".exe")
+	if name == "pwsh" {
+		return PowerShell, nil
+	}
+	for _, shell := range Shells {
+		if string(shell) == name {
+			return shell, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported shell %s", name)
+}
+
+// Detect returns the shell of the user from $SHELL, PowerShell is detected on windows and if its module path is set
+func Detect(getenv func(string) string, goos string) (Shell, error) {
+	if shell := getenv("SHELL"); shell != "" {
+		if shell, err := ParseShell(shell); err == nil {
+			return shell, nil
+		}
+	}
+	if goos == "windows" || getenv("PSModulePath") != "" {
+		return PowerShell, nil
+	}
+	return "", ErrUnknownShell
+}
+
+// Generate writes the completion script of root for the shell
+func Generate(root *cobra.Command, shell Shell, w io.Writer) error {
+	switch shell {
+	case Bash:
+		return root.GenBashCompletionV2(w, true)
+	case Zsh:
+		return root.GenZshCompletion(w)
+	case Fish:
+		return root.GenFishCompletion(w, true)
+	case PowerShell:
+		return root.GenPowerShellCompletionWithDesc(w)
+	}
+	return fmt.Errorf("unsupported shell %s", shell)
+}
+
+// Result lists what an installation changed
+type Result struct {
+	// Script is the path of the completion script
+	Script string
+	// Profile is the path of the changed profile, empty if the shell loads the script on its own
+	Profile string
+	// Backups are the paths of the backups of replaced files
+	Backups []string
+}
+
+// Install writes the completion script of root and loads it in the profile of the shell, replaced files are backed up
+func Install(root *cobra.Command, shell Shell) (*Result, error) {
+	var script bytes.Buffer
+	if err := Generate(root, shell, &script); err != nil {
+		return nil, err
+	}
+
+	scriptPath, err := scriptPath(shell)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Script: scriptPath}
+	if err := writeWithBackup(scriptPath, script.Bytes(), result); err != nil {
+		return nil, err
+	}
+
+	// fish loads the completions of its completions directory on its own
+	if shell == Fish {
+		return result, nil
+	}
+
+	profile, err := profilePath(shell)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(profile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", profile, err)
+	}
+	updated := UpdateBlock(string(content), loadScript(shell, scriptPath))
+	if updated != string(content) {
+		if err := writeWithBackup(profile, []byte(updated), result); err != nil {
+			return nil, err
+		}
+		result.Profile = profile
+	}
+	return result, nil
+}
+
+// loadScript returns the lines of the profile which load the completion script
+func loadScript(shell Shell, path string) string {
+	switch shell {
+	case Zsh:
+		return fmt.Sprintf("(( $+functions[compdef] )) || { autoload -Uz compinit && compinit }\nsource %q", path)
+	case PowerShell:
+		return fmt.Sprintf(". '%s'", strings.ReplaceAll(path, "'", "''"))
+	default:
+		return fmt.Sprintf("[ -f %q ] && source %q", path, path)
+	}
+}
+
+// UpdateBlock adds the lines between the markers of the space completion to a profile, or replaces the lines between
+// existing markers so that installing again doesn't add them twice
+func UpdateBlock(profile string, lines string) string {
+	block := fmt.Sprintf("%s\n%s\n%s\n", blockStart, lines, blockEnd)
+
+	start := strings.Index(profile, blockStart)
+	end := strings.Index(profile, blockEnd)
+	if start >= 0 && end > start {
+		end += len(blockEnd)
+		if end < len(profile) && profile[end] == '\n' {
+			end++
+		}
+		return profile[:start] + block + profile[end:]
+	}
+
+	if profile != "" && !strings.HasSuffix(profile, "\n") {
+		profile += "\n"
+	}
+	if profile != "" {
+		profile += "\n"
+	}
+	return profile + block
+}
+
+func writeWithBackup(path string, content []byte, result *Result) error {
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(existing, content):
+		return nil
+	case err == nil:
+		backup, err := writeBackup(path, existing, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		result.Backups = append(result.Backups, backup)
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeBackup writes content to a new backup of path named after the time of the backup, existing backups are never
+// overwritten
+func writeBackup(path string, content []byte, now time.Time) (string, error) {
+	stamp := now.Format("20060102-150405")
+	for i := 1; ; i++ {
+		backup := fmt.Sprintf("%s.%s.bak", path, stamp)
+		if i > 1 {
+			backup = fmt.Sprintf("%s.%s-%d.bak", path, stamp, i)
+		}
+		f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(content); err != nil {
+			f.Close()
+			return "", err
+		}
+		return backup, f.Close()
+	}
+}
+
+func scriptPath(shell Shell) (string, error) {
+	switch shell {
+	case Fish:
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			userHome, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(userHome, ".config")
+		}
+		return filepath.Join(dir, "fish", "completions", "space.fish"), nil
+	case PowerShell:
+		return home.PrepareWrite(filepath.Join("completions", "space.ps1"))
+	default:
+		return home.PrepareWrite(filepath.Join("completions", "space."+string(shell)))
+	}
+}
+
+func profilePath(shell Shell) (string, error) {
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	switch shell {
+	case Bash:
+		// login shells on macOS read .bash_profile instead of .bashrc
+		if goruntime.GOOS == "darwin" {
+			return filepath.Join(userHome, ".bash_profile"), nil
+		}
+		return filepath.Join(userHome, ".bashrc"), nil
+	case Zsh:
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = userHome
+		}
+		return filepath.Join(dir, ".zshrc"), nil
+	case PowerShell:
+		// the profile differs between versions of PowerShell, so it's asked for first
+		for _, name := range []string{"pwsh", "powershell"} {
+			if out, err := exec.Command(name, "-NoProfile", "-Command", "$PROFILE").Output(); err == nil {
+				if profile := strings.TrimSpace(string(out)); profile != "" {
+					return profile, nil
+				}
+			}
+		}
+		if goruntime.GOOS == "windows" {
+			return filepath.Join(userHome, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"), nil
+		}
+		return filepath.Join(userHome, ".config", "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	}
+	return "", fmt.Errorf("unsupported shell %s", shell)
+}
+
+// ErrShellNotFound the shell to verify the completion with isn't installed
+var ErrShellNotFound = errors.New("shell not found")
+
+// Verify starts the shell with the profile of the user and checks that the completion of space is loaded
+func Verify(shell Shell) error {
+	var args []string
+	switch shell {
+	case Bash:
+		args = []string{"bash", "-i", "-c", "complete -p space"}
+		// the block is in .bash_profile on macOS, which only login shells read
+		if goruntime.GOOS == "darwin" {
+			args = []string{"bash", "-l", "-i", "-c", "complete -p space"}
+		}
+	case Zsh:
+		args = []string{"zsh", "-i", "-c", "whence -w _space"}
+	case Fish:
+		args = []string{"fish", "-c", "complete -C 'space ' >/dev/null; functions -q __space_perform_completion"}
+	case PowerShell:
+		name := "pwsh"
+		if _, err := exec.LookPath(name); err != nil {
+			name = "powershell"
+		}
+		args = []string{name, "-Command", "if (-not (Get-Command __space_debug -ErrorAction SilentlyContinue)) { exit 1 }"}
+	default:
+		return fmt.Errorf("unsupported shell %s", shell)
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return fmt.Errorf("%w: %s", ErrShellNotFound, args[0])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" && ctx.Err() == nil {
+			return fmt.Errorf("the completion of space isn't loaded by %s: %s", args[0], lastLine(msg))
+		}
+		return fmt.Errorf("the completion of space isn't loaded by %s: %w", args[0], err)
+	}
+	return nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(s, "\n")
+	return lines[len(lines)-1]
+}
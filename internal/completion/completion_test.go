@@ -0,0 +1,88 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		env      map[string]string
+		goos     string
+		expected Shell
+		err      bool
+	}{
+		{env: map[string]string{"SHELL": "/bin/bash"}, goos: "linux", expected: Bash},
+		{env: map[string]string{"SHELL": "/usr/local/bin/zsh"}, goos: "darwin", expected: Zsh},
+		{env: map[string]string{"SHELL": "/usr/bin/fish"}, goos: "linux", expected: Fish},
+		{env: map[string]string{"SHELL": "/opt/microsoft/powershell/7/pwsh"}, goos: "linux", expected: PowerShell},
+		{env: map[string]string{}, goos: "windows", expected: PowerShell},
+		{env: map[string]string{"SHELL": "/bin/tcsh", "PSModulePath": "/opt/microsoft/powershell/7/Modules"}, goos: "linux", expected: PowerShell},
+		{env: map[string]string{"SHELL": "/bin/tcsh"}, goos: "linux", err: true},
+	}
+
+	for _, c := range cases {
+		shell, err := Detect(func(key string) string { return c.env[key] }, c.goos)
+		if c.err {
+			assert.ErrorIs(t, err, ErrUnknownShell)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, shell, c.expected)
+	}
+}
+
+func TestUpdateBlock(t *testing.T) {
+	cases := []struct {
+		name     string
+		profile  string
+		expected string
+	}{
+		{
+			name:     "empty profile",
+			profile:  "",
+			expected: "# >>> space completion >>>\nsource a\n# <<< space completion <<<\n",
+		},
+		{
+			name:     "appended",
+			profile:  "export EDITOR=vim",
+			expected: "export EDITOR=vim\n\n# >>> space completion >>>\nsource a\n# <<< space completion <<<\n",
+		},
+		{
+			name:     "replaced",
+			profile:  "export EDITOR=vim\n# >>> space completion >>>\nsource old\n# <<< space completion <<<\nalias ll='ls -l'\n",
+			expected: "export EDITOR=vim\n# >>> space completion >>>\nsource a\n# <<< space completion <<<\nalias ll='ls -l'\n",
+		},
+	}
+
+	for _, c := range cases {
+		updated := UpdateBlock(c.profile, "source a")
+		assert.Equal(t, updated, c.expected, c.name)
+		assert.Equal(t, UpdateBlock(updated, "source a"), updated, c.name)
+	}
+}
+
+func TestWriteBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first, err := writeBackup(path, []byte("first"), now)
+	assert.NilError(t, err)
+	assert.Equal(t, first, path+".20240102-030405.bak")
+
+	// a backup of the same second doesn't overwrite the first one
+	second, err := writeBackup(path, []byte("second"), now)
+	assert.NilError(t, err)
+	assert.Equal(t, second, path+".20240102-030405-2.bak")
+
+	content, err := os.ReadFile(first)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "first")
+	content, err = os.ReadFile(second)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "second")
+}
@@ -0,0 +1,221 @@
+// Package config reads and writes the settings of the user in the config file, ~/.config/space/config.json
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/deta/space/internal/home"
+)
+
+const (
+	// FileName is the name of the config file
+	FileName = "config.json"
+	// XDGConfigHomeEnv is the base directory of the config files of the user, ~/.config if it's not set
+	XDGConfigHomeEnv = "XDG_CONFIG_HOME"
+	// VersionCheckIntervalEnv overrides the version_check_interval of the config file
+	VersionCheckIntervalEnv = "SPACE_VERSION_CHECK_INTERVAL"
+	// RetriesEnv overrides the api_retries of the config file
+	RetriesEnv = "SPACE_API_RETRIES"
+	// AccessibleEnv turns on the accessibility mode like the accessible field of the config file
+	AccessibleEnv = "SPACE_ACCESSIBLE"
+	// NoColorEnv turns off colors like the no_color field of the config file if set to a non empty value, see
+	// https://no-color.org
+	NoColorEnv = "NO_COLOR"
+
+	// DefaultVersionCheckInterval is how often the latest version of the cli is checked if nothing is configured
+	DefaultVersionCheckInterval = 24 * time.Hour
+	// never disables the version check
+	never = "never"
+	// dirName is the directory of the config file in the config home
+	dirName      = "space"
+	filePermMode = 0600
+	dirPermMode  = 0700
+)
+
+var daysReg = regexp.MustCompile(`^(\d+)d$`)
+
+// Config holds the settings of the user
+type Config struct {
+	// VersionCheckInterval is a duration like 12h or 7d, or never to disable the version check
+	VersionCheckInterval string `json:"version_check_interval,omitempty"`
+	// Editor is the command of the editor for release notes and the Discovery file, e.g. "code --wait"
+	Editor string `json:"editor,omitempty"`
+	// DisableClipboard ignores the --copy flag of the commands
+	DisableClipboard bool `json:"disable_clipboard,omitempty"`
+	// Language of the messages, e.g. de, defaults to the locale
+	Language string `json:"language,omitempty"`
+	// Accessible turns on the accessibility mode for every command, like the --accessible flag
+	Accessible bool `json:"accessible,omitempty"`
+	// Notify shows a desktop notification when a long push or release finishes, like the --notify flag
+	Notify bool `json:"notify,omitempty"`
+	// TerminalTitle shows the progress of a push or release in the title of the terminal, tmux and iTerm
+	TerminalTitle bool `json:"terminal_title,omitempty"`
+	// Bell rings the bell of the terminal when a push or release finishes
+	Bell bool `json:"bell,omitempty"`
+	// APIRetries is how often a request to the api which failed with a network or server error is retried, 0 disables
+	// retries
+	APIRetries *int `json:"api_retries,omitempty"`
+	// DefaultProject is the id of the project of commands run outside of a project directory without --id
+	DefaultProject string `json:"default_project,omitempty"`
+	// Output is the default of --output, text or json
+	Output string `json:"output,omitempty"`
+	// NoColor turns off colors like --no-color
+	NoColor bool `json:"no_color,omitempty"`
+	// APIEndpoint is the root of the Space API, e.g. for a proxy, defaults to https://deta.space/api
+	APIEndpoint string `json:"api_endpoint,omitempty"`
+	// Handle is the Space handle of the user, compared with the approver of a release, defaults to the handle of the
+	// account of the access token
+	Handle string `json:"handle,omitempty"`
+	// Aliases are shortcuts for commands by name, e.g. "ship": "push && release --confirm"
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+// Path returns the path of the config file, $XDG_CONFIG_HOME/space/config.json or ~/.config/space/config.json.
+// The config file in the directory of the global state of older versions is used as long as the new one doesn't
+// exist.
+func Path() (string, error) {
+	path, err := xdgPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	legacy, err := home.Path(FileName)
+	if err != nil {
+		return path, nil
+	}
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	return path, nil
+}
+
+// xdgPath returns the path of the config file in the config home, where the config file is written
+func xdgPath() (string, error) {
+	configHome := os.Getenv(XDGConfigHomeEnv)
+	if configHome == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory, set %s instead: %w", XDGConfigHomeEnv, err)
+		}
+		configHome = filepath.Join(userHome, ".config")
+	}
+	return filepath.Join(configHome, dirName, FileName), nil
+}
+
+// Load reads the config file, an empty config is returned if it doesn't exist
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// VersionCheckFrequency returns how often the latest version is checked, 0 if the check is disabled
+func (c *Config) VersionCheckFrequency() (time.Duration, error) {
+	interval := c.VersionCheckInterval
+	if env := os.Getenv(VersionCheckIntervalEnv); env != "" {
+		interval = env
+	}
+
+	switch interval {
+	case "":
+		return DefaultVersionCheckInterval, nil
+	case never:
+		return 0, nil
+	}
+
+	if matches := daysReg.FindStringSubmatch(interval); len(matches) == 2 {
+		days, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid version check interval %q", interval)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid version check interval %q, use e.g. 12h, 7d or never", interval)
+	}
+	return d, nil
+}
+
+// AccessibleMode reports if the accessibility mode is turned on by the config file or the environment
+func (c *Config) AccessibleMode() bool {
+	if env := os.Getenv(AccessibleEnv); env != "" {
+		enabled, err := strconv.ParseBool(env)
+		return err == nil && enabled
+	}
+	return c.Accessible
+}
+
+// NoColorMode reports if colors are turned off by the config file or the environment
+func (c *Config) NoColorMode() bool {
+	return os.Getenv(NoColorEnv) != "" || c.NoColor
+}
+
+// Retries returns how often a failed request to the api is retried, def if nothing is configured
+func (c *Config) Retries(def int) (int, error) {
+	if env := os.Getenv(RetriesEnv); env != "" {
+		n, err := strconv.Atoi(env)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid %s %q, expected a number of retries like 3 or 0", RetriesEnv, env)
+		}
+		return n, nil
+	}
+	if c.APIRetries == nil {
+		return def, nil
+	}
+	if *c.APIRetries < 0 {
+		return 0, fmt.Errorf("invalid api_retries %d, expected a number of retries like 3 or 0", *c.APIRetries)
+	}
+	return *c.APIRetries, nil
+}
+
+// Save writes the config file to the config home, the config file of older versions is removed as it's migrated with
+// the write
+func (c *Config) Save() error {
+	path, err := xdgPath()
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirPermMode); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(content, '\n'), filePermMode); err != nil {
+		return err
+	}
+
+	legacy, err := home.Path(FileName)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(legacy); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove the config file of older versions %s: %w", legacy, err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deta/space/internal/home"
+	"gotest.tools/v3/assert"
+)
+
+func TestVersionCheckFrequency(t *testing.T) {
+	cases := []struct {
+		interval  string
+		env       string
+		frequency time.Duration
+		err       bool
+	}{
+		{interval: "", frequency: DefaultVersionCheckInterval},
+		{interval: "12h", frequency: 12 * time.Hour},
+		{interval: "7d", frequency: 7 * 24 * time.Hour},
+		{interval: "never", frequency: 0},
+		{interval: "12h", env: "never", frequency: 0},
+		{interval: "soon", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.interval+c.env, func(t *testing.T) {
+			t.Setenv(VersionCheckIntervalEnv, c.env)
+			cfg := &Config{VersionCheckInterval: c.interval}
+
+			frequency, err := cfg.VersionCheckFrequency()
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, frequency, c.frequency)
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	zero, five, negative := 0, 5, -1
+	cases := []struct {
+		name     string
+		retries  *int
+		env      string
+		expected int
+		err      bool
+	}{
+		{name: "default", expected: 3},
+		{name: "config", retries: &five, expected: 5},
+		{name: "disabled", retries: &zero, expected: 0},
+		{name: "env", retries: &five, env: "1", expected: 1},
+		{name: "invalid env", env: "many", err: true},
+		{name: "negative", retries: &negative, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(RetriesEnv, c.env)
+			cfg := &Config{APIRetries: c.retries}
+
+			retries, err := cfg.Retries(3)
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, retries, c.expected)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(home.HomeEnv, dir)
+	t.Setenv(XDGConfigHomeEnv, filepath.Join(dir, "xdg"))
+
+	c, err := Load()
+	assert.NilError(t, err)
+	assert.Equal(t, c.VersionCheckInterval, "")
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(`{"version_check_interval": "7d"}`), 0600))
+	c, err = Load()
+	assert.NilError(t, err)
+	assert.Equal(t, c.VersionCheckInterval, "7d")
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(home.HomeEnv, filepath.Join(dir, "home"))
+	t.Setenv(XDGConfigHomeEnv, filepath.Join(dir, "xdg"))
+	xdg := filepath.Join(dir, "xdg", "space", FileName)
+	legacy := filepath.Join(dir, "home", FileName)
+
+	path, err := Path()
+	assert.NilError(t, err)
+	assert.Equal(t, path, xdg)
+
+	// the config file of older versions is used until the new one exists
+	assert.NilError(t, os.MkdirAll(filepath.Dir(legacy), 0700))
+	assert.NilError(t, os.WriteFile(legacy, []byte(`{}`), 0600))
+	path, err = Path()
+	assert.NilError(t, err)
+	assert.Equal(t, path, legacy)
+
+	assert.NilError(t, os.MkdirAll(filepath.Dir(xdg), 0700))
+	assert.NilError(t, os.WriteFile(xdg, []byte(`{}`), 0600))
+	path, err = Path()
+	assert.NilError(t, err)
+	assert.Equal(t, path, xdg)
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(home.HomeEnv, dir)
+	t.Setenv(XDGConfigHomeEnv, filepath.Join(dir, "xdg"))
+
+	c := &Config{Output: "json", Aliases: map[string]string{"ship": "push"}}
+	assert.NilError(t, c.Save())
+
+	loaded, err := Load()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded, c)
+	_, err = os.Stat(filepath.Join(dir, "xdg", "space", FileName))
+	assert.NilError(t, err)
+}
+
+func TestSaveMigrates(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(home.HomeEnv, dir)
+	t.Setenv(XDGConfigHomeEnv, filepath.Join(dir, "xdg"))
+	legacy := filepath.Join(dir, FileName)
+	assert.NilError(t, os.WriteFile(legacy, []byte(`{"editor": "vim"}`), 0600))
+
+	c, err := Load()
+	assert.NilError(t, err)
+	c.Output = "json"
+	assert.NilError(t, c.Save())
+
+	// the config of older versions is moved to the config home
+	_, err = os.Stat(legacy)
+	assert.Assert(t, os.IsNotExist(err))
+	path, err := Path()
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(dir, "xdg", "space", FileName))
+	loaded, err := Load()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded, &Config{Editor: "vim", Output: "json"})
+}
+
+func TestSet(t *testing.T) {
+	cases := []struct {
+		key      string
+		value    string
+		expected string
+		err      bool
+	}{
+		{key: "default_project", value: "a1b2", expected: "a1b2"},
+		{key: "output", value: "json", expected: "json"},
+		{key: "output", value: "yaml", err: true},
+		{key: "no_color", value: "true", expected: "true"},
+		{key: "no_color", value: "false", expected: ""},
+		{key: "no_color", value: "maybe", err: true},
+		{key: "api_endpoint", value: "https://proxy.example.com/api", expected: "https://proxy.example.com/api"},
+		{key: "api_endpoint", value: "proxy", err: true},
+		{key: "handle", value: "jane", expected: "jane"},
+		{key: "handle", value: "jane doe", err: true},
+		{key: "api_retries", value: "0", expected: "0"},
+		{key: "api_retries", value: "-1", err: true},
+		{key: "version_check_interval", value: "7d", expected: "7d"},
+		{key: "version_check_interval", value: "soon", err: true},
+		{key: "aliases", value: "ship", err: true},
+		{key: "colour", value: "red", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.key+"="+c.value, func(t *testing.T) {
+			cfg := &Config{}
+			err := cfg.Set(c.key, c.value)
+			if c.err {
+				assert.Assert(t, err != nil)
+				// an invalid value doesn't change the config
+				assert.DeepEqual(t, cfg, &Config{})
+				return
+			}
+			assert.NilError(t, err)
+			value, err := cfg.Get(c.key)
+			assert.NilError(t, err)
+			assert.Equal(t, value, c.expected)
+
+			assert.NilError(t, cfg.Unset(c.key))
+			assert.DeepEqual(t, cfg, &Config{})
+		})
+	}
+}
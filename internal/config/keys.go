@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownKey is returned for keys which aren't settings of the config file
+var ErrUnknownKey = errors.New("unknown key")
+
+// validators check the value of a key before it's set
+var validators = map[string]func(c *Config) error{
+	"version_check_interval": func(c *Config) error {
+		_, err := (&Config{VersionCheckInterval: c.VersionCheckInterval}).VersionCheckFrequency()
+		return err
+	},
+	"output": func(c *Config) error {
+		if c.Output != "text" && c.Output != "json" {
+			return fmt.Errorf("invalid output %q, expected text or json", c.Output)
+		}
+		return nil
+	},
+	"api_retries": func(c *Config) error {
+		_, err := (&Config{APIRetries: c.APIRetries}).Retries(0)
+		return err
+	},
+	"handle": func(c *Config) error {
+		if c.Handle == "" || strings.ContainsAny(c.Handle, " \t\n") {
+			return fmt.Errorf("invalid handle %q, the handle can't be empty or contain spaces", c.Handle)
+		}
+		return nil
+	},
+	"api_endpoint": func(c *Config) error {
+		u, err := url.Parse(c.APIEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid api endpoint %q, expected a url like https://deta.space/api", c.APIEndpoint)
+		}
+		return nil
+	},
+}
+
+// Keys returns the keys of the settings which can be changed with Set, sorted by name. Aliases are edited in the
+// config file.
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() == reflect.Map {
+			continue
+		}
+		keys = append(keys, jsonKey(t.Field(i)))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value of the key, an empty string if it isn't set
+func (c *Config) Get(key string) (string, error) {
+	field, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() {
+			return "", nil
+		}
+		return fmt.Sprint(field.Elem().Interface()), nil
+	case reflect.Bool:
+		if !field.Bool() {
+			return "", nil
+		}
+	}
+	return fmt.Sprint(field.Interface()), nil
+}
+
+// Set parses the value for the type of the key and sets it, e.g. true for no_color
+func (c *Config) Set(key string, value string) error {
+	field, err := c.field(key)
+	if err != nil {
+		return err
+	}
+	previous := reflect.New(field.Type()).Elem()
+	previous.Set(field)
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(strings.TrimSpace(value))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q, expected true or false", key, value)
+		}
+		field.SetBool(b)
+	case reflect.Ptr:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q, expected a number", key, value)
+		}
+		field.Set(reflect.ValueOf(&n))
+	}
+
+	if validate, ok := validators[key]; ok {
+		if err := validate(c); err != nil {
+			field.Set(previous)
+			return err
+		}
+	}
+	return nil
+}
+
+// Unset removes the key, so that its default applies
+func (c *Config) Unset(key string) error {
+	field, err := c.field(key)
+	if err != nil {
+		return err
+	}
+	field.Set(reflect.Zero(field.Type()))
+	return nil
+}
+
+// field returns the settable field of the key
+func (c *Config) field(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if jsonKey(f) == key && f.Type.Kind() != reflect.Map {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%w %q, available keys: %s", ErrUnknownKey, key, strings.Join(Keys(), ", "))
+}
+
+func jsonKey(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get("json"), ",")[0]
+}
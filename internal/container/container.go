@@ -0,0 +1,81 @@
+package container
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// DockerizedEnv forces the dockerized mode on ("1") or off ("0") instead of detecting it
+	DockerizedEnv = "SPACE_DOCKERIZED"
+	// SecretPath is where the access token is mounted as a secret in containers
+	SecretPath = "/run/secrets/space_access_token"
+)
+
+var (
+	// secretPath is SecretPath, replaced in tests
+	secretPath = SecretPath
+	// markers are files created by container runtimes
+	markers = []string{"/.dockerenv", "/run/.containerenv"}
+	// cgroupPath lists the cgroups of the first process, replaced in tests
+	cgroupPath = "/proc/1/cgroup"
+)
+
+// cgroupHints are found in the cgroups of processes running in a container
+var cgroupHints = []string{"docker", "kubepods", "containerd", "libpod", "lxc"}
+
+// Forced reports whether the dockerized mode is forced on or off with DockerizedEnv, ok is false if it isn't set
+func Forced() (dockerized bool, ok bool) {
+	switch os.Getenv(DockerizedEnv) {
+	case "1", "true":
+		return true, true
+	case "0", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// Dockerized reports if the cli runs in the dockerized mode, in which case browsers and keychains are not used and
+// tokens are only read from the environment or secret mounts. It's on if it's forced with DockerizedEnv, if an access
+// token is mounted at SecretPath or if the cli runs in a container. Devcontainers which log in with space login turn
+// it off with DockerizedEnv.
+func Dockerized() bool {
+	if dockerized, ok := Forced(); ok {
+		return dockerized
+	}
+	if _, err := os.Stat(secretPath); err == nil {
+		return true
+	}
+	return InContainer()
+}
+
+// InContainer detects if the cli runs in a container from the files of container runtimes, the environment of
+// Kubernetes and the cgroups of the first process
+func InContainer() bool {
+	for _, marker := range markers {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+
+	cgroup, err := os.ReadFile(cgroupPath)
+	if err != nil {
+		return false
+	}
+	return hasCgroupHint(string(cgroup))
+}
+
+func hasCgroupHint(cgroup string) bool {
+	for _, line := range strings.Split(cgroup, "\n") {
+		for _, hint := range cgroupHints {
+			if strings.Contains(line, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// notInContainer points the detection to files which don't exist
+func notInContainer(t *testing.T) string {
+	dir := t.TempDir()
+	previousSecret, previousMarkers, previousCgroup := secretPath, markers, cgroupPath
+	secretPath = filepath.Join(dir, "space_access_token")
+	markers = []string{filepath.Join(dir, ".dockerenv")}
+	cgroupPath = filepath.Join(dir, "cgroup")
+	t.Cleanup(func() {
+		secretPath, markers, cgroupPath = previousSecret, previousMarkers, previousCgroup
+	})
+	t.Setenv(DockerizedEnv, "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	return dir
+}
+
+func TestHasCgroupHint(t *testing.T) {
+	cases := []struct {
+		name     string
+		cgroup   string
+		expected bool
+	}{
+		{name: "docker", cgroup: "12:pids:/docker/3f2a9c0b1e\n11:memory:/docker/3f2a9c0b1e", expected: true},
+		{name: "kubernetes", cgroup: "0::/kubepods/besteffort/pod1234/abcd", expected: true},
+		{name: "host", cgroup: "0::/init.scope", expected: false},
+		{name: "empty", cgroup: "", expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, hasCgroupHint(c.cgroup), c.expected)
+		})
+	}
+}
+
+func TestDockerizedOverride(t *testing.T) {
+	t.Setenv(DockerizedEnv, "1")
+	assert.Assert(t, Dockerized())
+
+	t.Setenv(DockerizedEnv, "0")
+	assert.Assert(t, !Dockerized())
+}
+
+func TestDockerized(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+	}{
+		{name: "secret", setup: func(t *testing.T, dir string) {
+			assert.NilError(t, os.WriteFile(filepath.Join(dir, "space_access_token"), []byte("abc_def"), 0600))
+		}},
+		{name: "marker", setup: func(t *testing.T, dir string) {
+			assert.NilError(t, os.WriteFile(filepath.Join(dir, ".dockerenv"), nil, 0600))
+		}},
+		{name: "cgroup", setup: func(t *testing.T, dir string) {
+			assert.NilError(t, os.WriteFile(filepath.Join(dir, "cgroup"), []byte("0::/docker/3f2a9c0b1e"), 0600))
+		}},
+		{name: "kubernetes", setup: func(t *testing.T, dir string) {
+			t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := notInContainer(t)
+			assert.Assert(t, !Dockerized())
+
+			c.setup(t, dir)
+			assert.Assert(t, Dockerized())
+
+			// devcontainers turn the mode off
+			t.Setenv(DockerizedEnv, "0")
+			assert.Assert(t, !Dockerized())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package conventional parses conventional commit messages to decide version bumps and write release notes
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/deta/space/internal/semver"
+)
+
+var (
+	headerReg   = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+	breakingReg = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+)
+
+// Commit is a parsed conventional commit
+type Commit struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// Parse parses the subject and body of a commit, ok is false if the subject doesn't follow the convention
+func Parse(subject string, body string) (commit *Commit, ok bool) {
+	matches := headerReg.FindStringSubmatch(strings.TrimSpace(subject))
+	if matches == nil {
+		return nil, false
+	}
+	return &Commit{
+		Type:        strings.ToLower(matches[1]),
+		Scope:       matches[2],
+		Description: matches[4],
+		Breaking:    matches[3] == "!" || breakingReg.MatchString(body),
+	}, true
+}
+
+// Bump returns the bump required by the commit
+func (c *Commit) Bump() semver.Bump {
+	switch {
+	case c.Breaking:
+		return semver.BumpMajor
+	case c.Type == "feat":
+		return semver.BumpMinor
+	case c.Type == "fix" || c.Type == "perf":
+		return semver.BumpPatch
+	default:
+		return semver.BumpNone
+	}
+}
+
+// Bump returns the largest bump required by the commits
+func Bump(commits []*Commit) semver.Bump {
+	bump := semver.BumpNone
+	for _, c := range commits {
+		if b := c.Bump(); b > bump {
+			bump = b
+		}
+	}
+	return bump
+}
+
+// sections of the release notes in the order they are listed
+var sections = []struct {
+	title   string
+	matches func(c *Commit) bool
+}{
+	{"Breaking Changes", func(c *Commit) bool { return c.Breaking }},
+	{"Features", func(c *Commit) bool { return !c.Breaking && c.Type == "feat" }},
+	{"Bug Fixes", func(c *Commit) bool { return !c.Breaking && c.Type == "fix" }},
+	{"Performance Improvements", func(c *Commit) bool { return !c.Breaking && c.Type == "perf" }},
+}
+
+// Notes writes markdown release notes listing the commits which require a bump
+func Notes(commits []*Commit) string {
+	var sb strings.Builder
+	for _, section := range sections {
+		var lines []string
+		for _, c := range commits {
+			if !section.matches(c) {
+				continue
+			}
+			if c.Scope != "" {
+				lines = append(lines, fmt.Sprintf("- **%s:** %s", c.Scope, c.Description))
+			} else {
+				lines = append(lines, fmt.Sprintf("- %s", c.Description))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "### %s\n\n%s\n", section.title, strings.Join(lines, "\n"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+package conventional
+
+import (
+	"testing"
+
+	"github.com/deta/space/internal/semver"
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		subject  string
+		body     string
+		expected *Commit
+	}{
+		{
+			subject:  "feat(push): add --bwlimit",
+			expected: &Commit{Type: "feat", Scope: "push", Description: "add --bwlimit"},
+		},
+		{
+			subject:  "fix!: drop support for v0 tokens",
+			expected: &Commit{Type: "fix", Description: "drop support for v0 tokens", Breaking: true},
+		},
+		{
+			subject:  "refactor: move api client",
+			body:     "BREAKING CHANGE: the client is no longer exported",
+			expected: &Commit{Type: "refactor", Description: "move api client", Breaking: true},
+		},
+		{
+			subject:  "Update README",
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.subject, func(t *testing.T) {
+			commit, ok := Parse(c.subject, c.body)
+			assert.Equal(t, ok, c.expected != nil)
+			assert.DeepEqual(t, commit, c.expected)
+		})
+	}
+}
+
+func TestBump(t *testing.T) {
+	parse := func(subjects ...string) []*Commit {
+		var commits []*Commit
+		for _, s := range subjects {
+			if c, ok := Parse(s, ""); ok {
+				commits = append(commits, c)
+			}
+		}
+		return commits
+	}
+
+	assert.Equal(t, Bump(parse("docs: typo", "chore: deps")), semver.BumpNone)
+	assert.Equal(t, Bump(parse("docs: typo", "fix: crash")), semver.BumpPatch)
+	assert.Equal(t, Bump(parse("fix: crash", "feat: login")), semver.BumpMinor)
+	assert.Equal(t, Bump(parse("feat!: new api", "fix: crash")), semver.BumpMajor)
+}
+
+func TestNotes(t *testing.T) {
+	commits := []*Commit{
+		{Type: "feat", Scope: "push", Description: "add --bwlimit"},
+		{Type: "fix", Description: "crash on empty Spacefile"},
+		{Type: "chore", Description: "update deps"},
+		{Type: "feat", Description: "new api", Breaking: true},
+	}
+
+	expected := `### Breaking Changes
+
+- new api
+
+### Features
+
+- **push:** add --bwlimit
+
+### Bug Fixes
+
+- crash on empty Spacefile
+`
+	assert.Equal(t, Notes(commits), expected)
+}
@@ -0,0 +1,163 @@
+// Package crash writes a report when the cli panics, with the stack traces of all goroutines, the command and a
+// fingerprint of the environment. Reports are only written locally, users attach them to issues themselves.
+package crash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deta/space/internal/home"
+	"github.com/deta/space/internal/support"
+)
+
+// IssuesURL is where crash reports are attached
+const IssuesURL = "https://github.com/deta/space-cli/issues"
+
+// secretFlag matches flags whose value is a secret, e.g. --token or --passphrase
+var secretFlag = regexp.MustCompile(`(?i)^--?[\w-]*(token|secret|passw(or)?d|passphrase|key)[\w-]*$`)
+
+// Report of a panic
+type Report struct {
+	Time     time.Time
+	Version  string
+	Platform string
+	Args     []string
+	Panic    interface{}
+	// Stack holds the stack traces of all goroutines
+	Stack []byte
+}
+
+// Handle writes a report if the cli panics and exits, it has to be deferred in main:
+//
+//	defer crash.Handle(version, platform)
+func Handle(version string, platform string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	stack := make([]byte, 1<<20)
+	stack = stack[:goruntime.Stack(stack, true)]
+	report := &Report{Time: time.Now(), Version: version, Platform: platform, Args: os.Args, Panic: r, Stack: stack}
+
+	fmt.Fprintf(os.Stderr, "\nThe Space CLI crashed: %v\n", r)
+	path, err := report.Save()
+	switch {
+	case errors.Is(err, home.ErrNoState):
+		fmt.Fprintf(os.Stderr, "Writing the global state is disabled, so the crash report is printed instead.\nPlease check it and attach it to an issue at %s.\n\n", IssuesURL)
+		report.Write(os.Stderr)
+	case err != nil:
+		// without a file the printed report is the only trace of the crash
+		fmt.Fprintf(os.Stderr, "Failed to write a crash report: %v\nPlease check the report below and attach it to an issue at %s.\n\n", err, IssuesURL)
+		report.Write(os.Stderr)
+	default:
+		fmt.Fprintf(os.Stderr, "A crash report was written to %s\nPlease check it and attach it to an issue at %s, it is never uploaded automatically.\n", path, IssuesURL)
+	}
+	os.Exit(2)
+}
+
+// Save writes the report to the crashes directory of the global state and returns its path. It returns
+// home.ErrNoState if writing the global state is disabled, reports are never written anywhere else.
+func (r *Report) Save() (string, error) {
+	name := fmt.Sprintf("crash-%s.txt", r.Time.Format("20060102-150405"))
+	path, err := home.PrepareWrite(filepath.Join("crashes", name))
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", home.WrapWriteError(err)
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// Latest returns the path of the latest report in the global state, it's empty if the cli never crashed
+func Latest() (string, error) {
+	dir, err := home.Path("crashes")
+	if err != nil {
+		return "", err
+	}
+	// the names sort by time
+	matches, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if err != nil || len(matches) == 0 {
+		return "", err
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
+}
+
+// Write writes the report as text, secrets in the command and the stack are redacted
+func (r *Report) Write(w io.Writer) error {
+	userHome, _ := os.UserHomeDir()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Space CLI crash report\n\n")
+	fmt.Fprintf(&b, "time: %s\n", r.Time.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "version: %s\n", r.Version)
+	fmt.Fprintf(&b, "platform: %s\n", r.Platform)
+	fmt.Fprintf(&b, "command: %s\n", strings.Join(RedactArgs(r.Args), " "))
+	b.WriteString(fingerprint())
+	fmt.Fprintf(&b, "\npanic: %v\n\n%s", r.Panic, r.Stack)
+
+	content, _ := support.Redact(b.String(), userHome)
+	_, err := io.WriteString(w, content)
+	return err
+}
+
+// RedactArgs replaces the values of flags like --token, the cli name is shortened to its base name
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	if len(redacted) > 0 {
+		redacted[0] = filepath.Base(redacted[0])
+	}
+	for i := 1; i < len(redacted); i++ {
+		arg := redacted[i]
+		if name, _, ok := strings.Cut(arg, "="); ok && secretFlag.MatchString(name) {
+			redacted[i] = name + "=" + support.Redacted
+			continue
+		}
+		if secretFlag.MatchString(arg) && i+1 < len(redacted) && !strings.HasPrefix(redacted[i+1], "-") {
+			redacted[i+1] = support.Redacted
+			i++
+		}
+	}
+	return redacted
+}
+
+// fingerprint describes the environment the cli runs in, without values which identify the user
+func fingerprint() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "go: %s %s/%s\n", goruntime.Version(), goruntime.GOOS, goruntime.GOARCH)
+	fmt.Fprintf(&b, "cpus: %d\n", goruntime.NumCPU())
+	fmt.Fprintf(&b, "shell: %s\n", filepath.Base(os.Getenv("SHELL")))
+	fmt.Fprintf(&b, "term: %s\n", os.Getenv("TERM"))
+	fmt.Fprintf(&b, "lang: %s\n", os.Getenv("LANG"))
+	_, ci := os.LookupEnv("CI")
+	fmt.Fprintf(&b, "ci: %t\n", ci)
+
+	// only the names of the variables, their values may be secrets
+	var names []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, "SPACE_") || strings.HasPrefix(name, "DETA_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "environment variables: %s\n", strings.Join(names, ", "))
+	return b.String()
+}
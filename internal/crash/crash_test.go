@@ -0,0 +1,64 @@
+package crash
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deta/space/internal/home"
+	"gotest.tools/v3/assert"
+)
+
+func TestRedactArgs(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: []string{"/usr/local/bin/space", "push", "--id", "a1"}, expected: []string{"space", "push", "--id", "a1"}},
+		{args: []string{"space", "login", "--token", "abc"}, expected: []string{"space", "login", "--token", "[REDACTED]"}},
+		{args: []string{"space", "login", "--token=abc"}, expected: []string{"space", "login", "--token=[REDACTED]"}},
+		{args: []string{"space", "login", "--token-stdin", "--yes"}, expected: []string{"space", "login", "--token-stdin", "--yes"}},
+		{args: []string{"space", "export", "--passphrase", "abc", "--dir", "."}, expected: []string{"space", "export", "--passphrase", "[REDACTED]", "--dir", "."}},
+	}
+
+	for _, c := range cases {
+		assert.DeepEqual(t, RedactArgs(c.args), c.expected)
+	}
+}
+
+func TestSave(t *testing.T) {
+	t.Setenv(home.HomeEnv, t.TempDir())
+
+	report := &Report{
+		Time:    time.Date(2023, time.November, 20, 10, 0, 0, 0, time.UTC),
+		Version: "v0.4.0",
+		Args:    []string{"space", "login", "--token", "abc"},
+		Panic:   "runtime error: index out of range [1] with length 1",
+		Stack:   []byte("goroutine 1 [running]:\nmain.main()"),
+	}
+	path, err := report.Save()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(path, "crash-20231120-100000.txt"))
+
+	latest, err := Latest()
+	assert.NilError(t, err)
+	assert.Equal(t, latest, path)
+
+	content, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), "command: space login --token [REDACTED]"))
+	assert.Assert(t, strings.Contains(string(content), "panic: runtime error: index out of range"))
+	assert.Assert(t, strings.Contains(string(content), "goroutine 1 [running]"))
+
+	// without a writable global state no file is written
+	home.SetNoState(true)
+	t.Cleanup(func() { home.SetNoState(false) })
+	report.Time = report.Time.Add(time.Second)
+	_, err = report.Save()
+	assert.Assert(t, errors.Is(err, home.ErrNoState))
+	latest, err = Latest()
+	assert.NilError(t, err)
+	assert.Equal(t, latest, path)
+}
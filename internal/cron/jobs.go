@@ -0,0 +1,157 @@
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Job a local maintenance job
+type Job struct {
+	Name     string            `yaml:"name"`
+	Schedule string            `yaml:"schedule"`
+	Command  string            `yaml:"command"`
+	Dir      string            `yaml:"dir,omitempty"`
+	Project  string            `yaml:"project,omitempty"`
+	Env      map[string]string `yaml:"env,omitempty"`
+
+	schedule Schedule
+}
+
+// JobsFile list of jobs declared in a jobs file
+type JobsFile struct {
+	Jobs []*Job `yaml:"jobs"`
+}
+
+// LoadJobs reads and validates a jobs file
+func LoadJobs(path string) (*JobsFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs file: %w", err)
+	}
+
+	var f JobsFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse jobs file: %w", err)
+	}
+
+	if len(f.Jobs) == 0 {
+		return nil, errors.New("no jobs declared in jobs file")
+	}
+
+	names := make(map[string]struct{})
+	for i, job := range f.Jobs {
+		if job.Name == "" {
+			return nil, fmt.Errorf("job at index %d has no name", i)
+		}
+		if _, ok := names[job.Name]; ok {
+			return nil, fmt.Errorf("job names have to be unique, %s is declared more than once", job.Name)
+		}
+		names[job.Name] = struct{}{}
+
+		if job.Command == "" {
+			return nil, fmt.Errorf("job %s has no command", job.Name)
+		}
+
+		job.schedule, err = Parse(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.Name, err)
+		}
+
+		// resolve job directories relative to the jobs file
+		if !filepath.IsAbs(job.Dir) {
+			job.Dir = filepath.Join(filepath.Dir(path), job.Dir)
+		}
+	}
+
+	return &f, nil
+}
+
+// State holds the last run of every job
+type State map[string]time.Time
+
+// StatePath returns the path of the state of the jobs of the project in projectDir
+func StatePath(projectDir string) string {
+	return filepath.Join(projectDir, ".space", "cron_state")
+}
+
+// LoadState reads the state file, a missing file results in an empty state
+func LoadState(path string) (State, error) {
+	s := make(State)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("invalid cron state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state to disk
+func (s State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0760); err != nil {
+		return err
+	}
+	content, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0660)
+}
+
+// NextRun returns when the job is due next, jobs that never ran are due immediately
+func (j *Job) NextRun(s State, now time.Time) time.Time {
+	last, ok := s[j.Name]
+	if !ok {
+		return now
+	}
+	return j.schedule.Next(last)
+}
+
+// Runs returns when the job runs from now until to, at most limit runs
+func (j *Job) Runs(s State, now time.Time, to time.Time, limit int) []time.Time {
+	next := j.NextRun(s, now)
+	if limit < 1 || next.IsZero() || next.After(to) {
+		return nil
+	}
+	return append([]time.Time{next}, Occurrences(j.schedule, next, to, limit-1)...)
+}
+
+// Due returns the jobs which are due at the given time, ordered by their declaration
+func (f *JobsFile) Due(s State, now time.Time) []*Job {
+	var due []*Job
+	for _, job := range f.Jobs {
+		next := job.NextRun(s, now)
+		if !next.IsZero() && !next.After(now) {
+			due = append(due, job)
+		}
+	}
+	return due
+}
+
+// NextWakeup returns the earliest time any job is due
+func (f *JobsFile) NextWakeup(s State, now time.Time) time.Time {
+	var runs []time.Time
+	for _, job := range f.Jobs {
+		if next := job.NextRun(s, now); !next.IsZero() {
+			runs = append(runs, next)
+		}
+	}
+	if len(runs) == 0 {
+		return time.Time{}
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Before(runs[j]) })
+	return runs[0]
+}
@@ -0,0 +1,97 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNext(t *testing.T) {
+	// monday
+	base := time.Date(2023, time.March, 6, 10, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{expr: "* * * * *", expected: time.Date(2023, time.March, 6, 10, 31, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", expected: time.Date(2023, time.March, 6, 10, 45, 0, 0, time.UTC)},
+		{expr: "0 3 * * *", expected: time.Date(2023, time.March, 7, 3, 0, 0, 0, time.UTC)},
+		{expr: "@hourly", expected: time.Date(2023, time.March, 6, 11, 0, 0, 0, time.UTC)},
+		{expr: "0 9 * * 1-5", expected: time.Date(2023, time.March, 7, 9, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 0", expected: time.Date(2023, time.March, 12, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 6-7", expected: time.Date(2023, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 * *", expected: time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "30 12 15,20 * *", expected: time.Date(2023, time.March, 15, 12, 30, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", expected: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "@every 90m", expected: time.Date(2023, time.March, 6, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.expr, func(t *testing.T) {
+			s, err := Parse(c.expr)
+			assert.NilError(t, err)
+			assert.Equal(t, s.Next(base), c.expected)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@every 10s",
+		"@every soon",
+	}
+
+	for _, expr := range cases {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); err == nil {
+				t.Fatalf("expected %q to be invalid", expr)
+			}
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	now := time.Date(2023, time.March, 6, 10, 30, 0, 0, time.UTC)
+	hourly, _ := Parse("@hourly")
+	f := &JobsFile{Jobs: []*Job{
+		{Name: "never-ran", schedule: hourly},
+		{Name: "ran-recently", schedule: hourly},
+		{Name: "overdue", schedule: hourly},
+	}}
+	state := State{
+		"ran-recently": now.Add(-10 * time.Minute),
+		"overdue":      now.Add(-2 * time.Hour),
+	}
+
+	due := f.Due(state, now)
+	assert.Equal(t, len(due), 2)
+	assert.Equal(t, due[0].Name, "never-ran")
+	assert.Equal(t, due[1].Name, "overdue")
+	assert.Equal(t, f.NextWakeup(State{"never-ran": now, "ran-recently": now, "overdue": now}, now), time.Date(2023, time.March, 6, 11, 0, 0, 0, time.UTC))
+}
+
+func TestRuns(t *testing.T) {
+	now := time.Date(2023, time.March, 6, 10, 30, 0, 0, time.UTC)
+	daily, _ := Parse("0 3 * * *")
+	job := &Job{Name: "cleanup", schedule: daily}
+
+	runs := job.Runs(State{"cleanup": now.Add(-time.Hour)}, now, now.AddDate(0, 0, 3), 10)
+	assert.DeepEqual(t, runs, []time.Time{
+		time.Date(2023, time.March, 7, 3, 0, 0, 0, time.UTC),
+		time.Date(2023, time.March, 8, 3, 0, 0, 0, time.UTC),
+		time.Date(2023, time.March, 9, 3, 0, 0, 0, time.UTC),
+	})
+
+	// jobs that never ran are due immediately
+	runs = job.Runs(State{}, now, now.AddDate(0, 0, 3), 2)
+	assert.DeepEqual(t, runs, []time.Time{now, time.Date(2023, time.March, 7, 3, 0, 0, 0, time.UTC)})
+}
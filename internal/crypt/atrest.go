@@ -0,0 +1,153 @@
+package crypt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/deta/space/internal/home"
+)
+
+const (
+	// PassphraseEnv holds the passphrase used to encrypt the local state, skips the prompt if set
+	PassphraseEnv = "SPACE_STATE_PASSPHRASE"
+
+	// markerFile in the directory of the global state marks the local state as encrypted
+	markerFile = "state_encrypted"
+)
+
+// ErrNoPassphrase no passphrase was provided to access the encrypted state
+var ErrNoPassphrase = errors.New("the local state is encrypted, set " + PassphraseEnv + " to provide the passphrase")
+
+var (
+	mu         sync.Mutex
+	passphrase string
+	prompt     func() (string, error)
+)
+
+// SetPassphrasePrompt sets the function used to ask for the passphrase of the local state,
+// it is called at most once per process as the passphrase is cached
+func SetPassphrasePrompt(f func() (string, error)) {
+	mu.Lock()
+	defer mu.Unlock()
+	prompt = f
+}
+
+// SetPassphrase sets the passphrase of the local state for the rest of the process
+func SetPassphrase(p string) {
+	mu.Lock()
+	defer mu.Unlock()
+	passphrase = p
+}
+
+func getPassphrase() (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if passphrase != "" {
+		return passphrase, nil
+	}
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		passphrase = p
+		return passphrase, nil
+	}
+	if prompt == nil {
+		return "", ErrNoPassphrase
+	}
+
+	p, err := prompt()
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", ErrNoPassphrase
+	}
+	passphrase = p
+	return passphrase, nil
+}
+
+// StateEncrypted reports if the local state is encrypted at rest
+func StateEncrypted() bool {
+	path, err := home.Path(markerFile)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// SetStateEncrypted enables or disables the encryption of files written with WriteFile,
+// files which were written already are not touched, reseal them before with Reseal
+func SetStateEncrypted(enabled bool) error {
+	path, err := home.PrepareWrite(markerFile)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return home.WrapWriteError(err)
+		}
+		return nil
+	}
+
+	return home.WrapWriteError(os.WriteFile(path, nil, 0660))
+}
+
+// ReadFile reads a state file, decrypting it if it is encrypted
+func ReadFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil || !IsSealed(content) {
+		return content, err
+	}
+
+	p, err := getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := Open(p, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// WriteFile writes a state file, encrypting it if the local state is encrypted
+func WriteFile(path string, content []byte, perm os.FileMode) error {
+	return writeFile(path, content, perm, StateEncrypted())
+}
+
+func writeFile(path string, content []byte, perm os.FileMode, encrypted bool) error {
+	if !encrypted {
+		return os.WriteFile(path, content, perm)
+	}
+
+	p, err := getPassphrase()
+	if err != nil {
+		return err
+	}
+	sealed, err := Seal(p, content)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, perm)
+}
+
+// Reseal rewrites an existing state file so that it is encrypted or not, independent of the current encryption
+// setting, so that the setting can be changed once all files are resealed. Missing files are skipped.
+func Reseal(path string, encrypted bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	content, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, content, info.Mode().Perm(), encrypted)
+}
@@ -0,0 +1,48 @@
+package crypt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestStateEncryption(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "correct horse battery staple")
+	t.Cleanup(func() { SetPassphrase("") })
+
+	path := filepath.Join(t.TempDir(), "meta")
+	content := []byte(`{"id":"a0abc1234"}`)
+
+	// plain files are written and read as is
+	assert.NilError(t, WriteFile(path, content, 0660))
+	raw, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, raw, content)
+
+	// resealing encrypts existing files before encryption is enabled
+	assert.NilError(t, Reseal(path, true))
+	assert.Assert(t, !StateEncrypted())
+	assert.NilError(t, SetStateEncrypted(true))
+	assert.Assert(t, StateEncrypted())
+
+	raw, err = os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Assert(t, IsSealed(raw))
+
+	read, err := ReadFile(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, read, content)
+
+	// resealing decrypts them again before encryption is disabled
+	assert.NilError(t, Reseal(path, false))
+	assert.NilError(t, SetStateEncrypted(false))
+	raw, err = os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, raw, content)
+
+	// missing files are skipped
+	assert.NilError(t, Reseal(filepath.Join(t.TempDir(), "missing"), true))
+}
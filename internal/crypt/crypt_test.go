@@ -0,0 +1,26 @@
+package crypt
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSealOpen(t *testing.T) {
+	sealed, err := Seal("correct horse", []byte("secret"))
+	assert.NilError(t, err)
+	assert.Assert(t, IsSealed(sealed))
+
+	plaintext, err := Open("correct horse", sealed)
+	assert.NilError(t, err)
+	assert.Equal(t, string(plaintext), "secret")
+
+	if _, err := Open("battery staple", sealed); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected error %v but got %v", ErrWrongPassphrase, err)
+	}
+
+	if _, err := Open("correct horse", []byte("secret")); !errors.Is(err, ErrNotSealed) {
+		t.Fatalf("expected error %v but got %v", ErrNotSealed, err)
+	}
+}
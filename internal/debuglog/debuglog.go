@@ -0,0 +1,109 @@
+// Package debuglog writes the debug log of --debug: structured lines of key=value pairs, e.g. of every api request
+package debuglog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// requestIDHeader identifies a request in the logs of Deta Space
+	requestIDHeader = "X-Request-Id"
+	// maxBodyLog is how much of the body of a failed response is logged
+	maxBodyLog = 1024
+)
+
+// Logger writes lines like "12:04:05.123 debug http response method=POST status=500", a nil logger discards them
+type Logger struct {
+	mu  sync.Mutex
+	out io.Writer
+	now func() time.Time
+}
+
+func New(out io.Writer) *Logger {
+	return &Logger{out: out, now: time.Now}
+}
+
+// Log writes the message with the fields, keyvals alternate keys and values
+func (l *Logger) Log(msg string, keyvals ...interface{}) {
+	if l == nil {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s debug %s", l.now().Format("15:04:05.000"), msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "(missing)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%s", keyvals[i], formatValue(value))
+	}
+	b.WriteString("\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.out, b.String())
+}
+
+// formatValue quotes values with spaces, quotes or equal signs, so that a line can be split into its fields
+func formatValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case time.Duration:
+		s = v.Round(time.Millisecond).String()
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// Transport logs every request sent through Next and its response
+type Transport struct {
+	Next   http.RoundTripper
+	Logger *Logger
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the query may contain secrets, the path identifies the request well enough
+	t.Logger.Log("http request", "method", req.Method, "host", req.URL.Host, "path", req.URL.Path, "bytes", req.ContentLength)
+
+	start := time.Now()
+	res, err := t.Next.RoundTrip(req)
+	duration := time.Since(start)
+	if err != nil {
+		t.Logger.Log("http error", "method", req.Method, "path", req.URL.Path, "duration", duration, "error", err)
+		return nil, err
+	}
+
+	keyvals := []interface{}{"method", req.Method, "path", req.URL.Path, "status", res.StatusCode, "duration", duration}
+	if id := res.Header.Get(requestIDHeader); id != "" {
+		keyvals = append(keyvals, "request_id", id)
+	}
+	if res.StatusCode >= 400 {
+		// the error detail of the api explains a failure better than its status
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Logger.Log("http error", "method", req.Method, "path", req.URL.Path, "duration", time.Since(start), "error", err)
+			return nil, err
+		}
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) > maxBodyLog {
+			body = body[:maxBodyLog]
+		}
+		keyvals = append(keyvals, "body", strings.TrimSpace(string(body)))
+	}
+	t.Logger.Log("http response", keyvals...)
+	return res, nil
+}
@@ -0,0 +1,76 @@
+package debuglog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLog(t *testing.T) {
+	cases := []struct {
+		keyvals  []interface{}
+		expected string
+	}{
+		{keyvals: nil, expected: "12:04:05.123 debug msg\n"},
+		{keyvals: []interface{}{"status", 500, "duration", 1234567 * time.Microsecond}, expected: "12:04:05.123 debug msg status=500 duration=1.235s\n"},
+		{keyvals: []interface{}{"body", `{"detail": "no"}`}, expected: `12:04:05.123 debug msg body="{\"detail\": \"no\"}"` + "\n"},
+		{keyvals: []interface{}{"error", errors.New("reset"), "empty", ""}, expected: `12:04:05.123 debug msg error=reset empty=""` + "\n"},
+		{keyvals: []interface{}{"odd"}, expected: "12:04:05.123 debug msg odd=(missing)\n"},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		l := New(&out)
+		l.now = func() time.Time { return time.Date(2026, 1, 2, 12, 4, 5, 123e6, time.UTC) }
+		l.Log("msg", c.keyvals...)
+		assert.Equal(t, out.String(), c.expected)
+	}
+
+	var discarded *Logger
+	discarded.Log("msg", "key", "value")
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"detail": "broken"}`)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := &http.Client{Transport: &Transport{Next: http.DefaultTransport, Logger: New(&out)}}
+
+	res, err := client.Get(server.URL + "/fail?token=secret")
+	assert.NilError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	// the body is still read by the caller
+	assert.Equal(t, string(body), `{"detail": "broken"}`)
+
+	log := out.String()
+	assert.Assert(t, strings.Contains(log, "http request method=GET"), log)
+	assert.Assert(t, strings.Contains(log, "status=500"), log)
+	assert.Assert(t, strings.Contains(log, "request_id=req-1"), log)
+	assert.Assert(t, strings.Contains(log, `body="{\"detail\": \"broken\"}"`), log)
+	assert.Assert(t, !strings.Contains(log, "secret"), log)
+
+	out.Reset()
+	res, err = client.Get(server.URL + "/ok")
+	assert.NilError(t, err)
+	res.Body.Close()
+	assert.Assert(t, strings.Contains(out.String(), "status=200"), out.String())
+	assert.Assert(t, !strings.Contains(out.String(), "body="), out.String())
+}
@@ -0,0 +1,334 @@
+// Package delta encodes an archive as the difference to a previous archive, so that pushing a project which changed
+// a little uploads a little. The unchanged files of a zip archive keep their bytes but move, so the blocks of the
+// previous archive are found anywhere in the new one with a rolling hash, like rsync does. A delta is a zstd stream of
+// operations which either copy a range of the previous archive or insert new bytes.
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// blockSize is the size of the blocks of the previous archive which are looked up in the new one
+	blockSize = 4 << 10
+	// maxCandidates is how many blocks with the same hash are compared
+	maxCandidates = 4
+	// maxInsert is the size of the largest insert, new bytes are written once they reach it so that the memory used
+	// doesn't grow with the size of the archives
+	maxInsert = 1 << 20
+	// chunkSize is how much of the archives is read at once while extending a match
+	chunkSize = 64 << 10
+
+	opCopy   = 'C'
+	opInsert = 'I'
+	opEnd    = 'E'
+)
+
+// magic starts every delta, the version is its last byte
+var magic = []byte("SPACEDELTA\x01")
+
+// ErrCorrupt the delta is invalid or was encoded against another archive
+var ErrCorrupt = errors.New("corrupt delta")
+
+// rollingHash is the weak checksum of rsync over a window of blockSize bytes, it's updated in constant time when the
+// window moves by a byte
+type rollingHash struct {
+	a, b uint32
+}
+
+func newRollingHash(window []byte) rollingHash {
+	var h rollingHash
+	for i, c := range window {
+		h.a += uint32(c)
+		h.b += uint32(len(window)-i) * uint32(c)
+	}
+	return h
+}
+
+func (h *rollingHash) roll(out byte, in byte) {
+	h.a += uint32(in) - uint32(out)
+	h.b += h.a - blockSize*uint32(out)
+}
+
+func (h rollingHash) sum() uint32 {
+	return h.a&0xffff | h.b<<16
+}
+
+// encoder writes the operations of a delta
+type encoder struct {
+	w   *bufio.Writer
+	buf [2*binary.MaxVarintLen64 + 1]byte
+}
+
+// insert writes the new bytes in inserts of at most maxInsert bytes
+func (e *encoder) insert(data []byte) error {
+	for len(data) > 0 {
+		part := data
+		if len(part) > maxInsert {
+			part = part[:maxInsert]
+		}
+		e.buf[0] = opInsert
+		n := 1 + binary.PutUvarint(e.buf[1:], uint64(len(part)))
+		if _, err := e.w.Write(e.buf[:n]); err != nil {
+			return err
+		}
+		if _, err := e.w.Write(part); err != nil {
+			return err
+		}
+		data = data[len(part):]
+	}
+	return nil
+}
+
+func (e *encoder) copy(offset int64, length int64) error {
+	e.buf[0] = opCopy
+	n := 1 + binary.PutUvarint(e.buf[1:], uint64(offset))
+	n += binary.PutUvarint(e.buf[n:], uint64(length))
+	_, err := e.w.Write(e.buf[:n])
+	return err
+}
+
+// Encode writes the delta of target against base to w. Only an index of the blocks of base and a window of target are
+// held in memory, base is read where a block matches.
+func Encode(w io.Writer, base io.ReaderAt, baseSize int64, target io.Reader) error {
+	if _, err := w.Write(magic); err != nil {
+		return err
+	}
+	zw, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return err
+	}
+	e := &encoder{w: bufio.NewWriter(zw)}
+	if err := encode(e, base, baseSize, target); err != nil {
+		zw.Close()
+		return fmt.Errorf("failed to encode the delta: %w", err)
+	}
+	if err := e.w.Flush(); err != nil {
+		zw.Close()
+		return fmt.Errorf("failed to encode the delta: %w", err)
+	}
+	return zw.Close()
+}
+
+// window holds the bytes of target which aren't written yet, buf[0] is at offset start of target
+type window struct {
+	r   io.Reader
+	buf []byte
+	eof bool
+}
+
+// fill reads until the window has n bytes, it returns false if target ends before
+func (w *window) fill(n int) (bool, error) {
+	for len(w.buf) < n && !w.eof {
+		if cap(w.buf)-len(w.buf) < chunkSize {
+			buf := make([]byte, len(w.buf), 2*cap(w.buf)+chunkSize)
+			copy(buf, w.buf)
+			w.buf = buf
+		}
+		read, err := w.r.Read(w.buf[len(w.buf):cap(w.buf)])
+		w.buf = w.buf[:len(w.buf)+read]
+		if err == io.EOF {
+			w.eof = true
+		} else if err != nil {
+			return false, err
+		}
+	}
+	return len(w.buf) >= n, nil
+}
+
+// discard drops the first n bytes of the window
+func (w *window) discard(n int) {
+	w.buf = w.buf[:copy(w.buf, w.buf[n:])]
+}
+
+func encode(e *encoder, base io.ReaderAt, baseSize int64, target io.Reader) error {
+	index := make(map[uint32][]int64, baseSize/blockSize)
+	block := make([]byte, blockSize)
+	for offset := int64(0); offset+blockSize <= baseSize; offset += blockSize {
+		if _, err := base.ReadAt(block, offset); err != nil {
+			return err
+		}
+		sum := newRollingHash(block).sum()
+		if len(index[sum]) < maxCandidates {
+			index[sum] = append(index[sum], offset)
+		}
+	}
+
+	// the bytes of the window before i aren't written yet, they are inserted before the next copy
+	win := &window{r: target}
+	baseChunk := make([]byte, chunkSize)
+	i := 0
+	var h rollingHash
+	ok, err := win.fill(blockSize)
+	if err != nil {
+		return err
+	}
+	if ok {
+		h = newRollingHash(win.buf[:blockSize])
+	}
+	for ok {
+		match := int64(-1)
+		for _, offset := range index[h.sum()] {
+			if _, err := base.ReadAt(block, offset); err != nil {
+				return err
+			}
+			if bytes.Equal(block, win.buf[i:i+blockSize]) {
+				match = offset
+				break
+			}
+		}
+
+		if match < 0 {
+			if i >= maxInsert {
+				if err := e.insert(win.buf[:i]); err != nil {
+					return err
+				}
+				win.discard(i)
+				i = 0
+			}
+			if ok, err = win.fill(i + blockSize + 1); err != nil {
+				return err
+			}
+			if ok {
+				h.roll(win.buf[i], win.buf[i+blockSize])
+				i++
+			}
+			continue
+		}
+
+		// the match usually continues before the block up to the previous operation, and beyond the block
+		back := int64(i)
+		if back > match {
+			back = match
+		}
+		before := make([]byte, back)
+		if _, err := base.ReadAt(before, match-back); err != nil {
+			return err
+		}
+		start := i
+		for start > 0 && len(before) > 0 && win.buf[start-1] == before[len(before)-1] {
+			start--
+			before = before[:len(before)-1]
+		}
+		offset := match - int64(i-start)
+
+		if err := e.insert(win.buf[:start]); err != nil {
+			return err
+		}
+		win.discard(start)
+		length, err := extendMatch(win, base, baseSize, offset, baseChunk)
+		if err != nil {
+			return err
+		}
+		if err := e.copy(offset, length); err != nil {
+			return err
+		}
+
+		i = 0
+		if ok, err = win.fill(blockSize); err != nil {
+			return err
+		}
+		if ok {
+			h = newRollingHash(win.buf[:blockSize])
+		}
+	}
+
+	if _, err := win.fill(int(^uint(0) >> 1)); err != nil {
+		return err
+	}
+	if err := e.insert(win.buf); err != nil {
+		return err
+	}
+	return e.w.WriteByte(opEnd)
+}
+
+// extendMatch discards the bytes of the window which match base from offset on, the window starts with a matching
+// block. It returns the length of the match.
+func extendMatch(win *window, base io.ReaderAt, baseSize int64, offset int64, chunk []byte) (int64, error) {
+	var length int64
+	for {
+		n := int64(len(chunk))
+		if rest := baseSize - offset - length; rest < n {
+			n = rest
+		}
+		if n == 0 {
+			return length, nil
+		}
+		if _, err := win.fill(int(n)); err != nil {
+			return length, err
+		}
+		if int64(len(win.buf)) < n {
+			n = int64(len(win.buf))
+		}
+		if n == 0 {
+			return length, nil
+		}
+		if _, err := base.ReadAt(chunk[:n], offset+length); err != nil {
+			return length, err
+		}
+
+		same := 0
+		for same < int(n) && win.buf[same] == chunk[same] {
+			same++
+		}
+		win.discard(same)
+		length += int64(same)
+		if same < int(n) {
+			return length, nil
+		}
+	}
+}
+
+// Decode writes the archive encoded by the delta against base to w
+func Decode(w io.Writer, base io.ReaderAt, baseSize int64, delta io.Reader) error {
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(delta, header); err != nil || !bytes.Equal(header, magic) {
+		return ErrCorrupt
+	}
+	zr, err := zstd.NewReader(delta, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	r := bufio.NewReader(zr)
+
+	for {
+		op, err := r.ReadByte()
+		if err != nil {
+			return ErrCorrupt
+		}
+		switch op {
+		case opEnd:
+			return nil
+		case opInsert:
+			length, err := binary.ReadUvarint(r)
+			if err != nil || length > maxInsert {
+				return ErrCorrupt
+			}
+			if _, err := io.CopyN(w, r, int64(length)); err != nil {
+				return ErrCorrupt
+			}
+		case opCopy:
+			offset, err := binary.ReadUvarint(r)
+			if err != nil {
+				return ErrCorrupt
+			}
+			length, err := binary.ReadUvarint(r)
+			if err != nil || offset > uint64(baseSize) || length > uint64(baseSize)-offset {
+				return ErrCorrupt
+			}
+			if _, err := io.Copy(w, io.NewSectionReader(base, int64(offset), int64(length))); err != nil {
+				return err
+			}
+		default:
+			return ErrCorrupt
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func randomBytes(seed int64, n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func TestEncode(t *testing.T) {
+	// random data doesn't compress, only the copies of the base make the delta small
+	base := randomBytes(1, 1<<20)
+
+	cases := []struct {
+		name    string
+		target  []byte
+		maxSize int
+	}{
+		{name: "unchanged", target: base, maxSize: 64},
+		{name: "changed in the middle", target: join(base[:500000], []byte("a small change"), base[500014:]), maxSize: 128},
+		{name: "inserted file", target: join(base[:1000], randomBytes(2, 10<<10), base[1000:]), maxSize: 12 << 10},
+		// the bytes before the removed file are shorter than a block, they are inserted
+		{name: "removed file", target: join(base[:1000], base[200000:]), maxSize: 1100},
+		{name: "moved file", target: join(base[600000:], base[:600000]), maxSize: 128},
+		{name: "new archive", target: randomBytes(3, 100<<10), maxSize: 101 << 10},
+		{name: "larger than an insert", target: randomBytes(4, 3*maxInsert+10), maxSize: 3*maxInsert + 1<<10},
+		{name: "smaller than a block", target: []byte("PK"), maxSize: 64},
+		{name: "empty", target: nil, maxSize: 64},
+	}
+
+	for _, c := range cases {
+		var delta bytes.Buffer
+		assert.NilError(t, Encode(&delta, bytes.NewReader(base), int64(len(base)), bytes.NewReader(c.target)))
+		assert.Assert(t, delta.Len() <= c.maxSize, "%s: delta of %d bytes", c.name, delta.Len())
+
+		var decoded bytes.Buffer
+		assert.NilError(t, Decode(&decoded, bytes.NewReader(base), int64(len(base)), &delta))
+		assert.Assert(t, bytes.Equal(decoded.Bytes(), c.target), c.name)
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	base := randomBytes(1, 64<<10)
+	var delta bytes.Buffer
+	assert.NilError(t, Encode(&delta, bytes.NewReader(base), int64(len(base)), bytes.NewReader(base)))
+
+	// a delta against a larger archive copies beyond the end of a smaller one
+	var decoded bytes.Buffer
+	err := Decode(&decoded, bytes.NewReader(base), 1000, bytes.NewReader(delta.Bytes()))
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	err = Decode(&decoded, bytes.NewReader(base), int64(len(base)), bytes.NewReader([]byte("PK\x03\x04")))
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
@@ -0,0 +1,206 @@
+// Package deps estimates the installed size of the dependencies of a micro, from a local install if there is one and
+// from the lockfiles and the package registries otherwise, so that micros too big for Space are caught before a build
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/internal/lockfile"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// MaxMicroSize is the size limit of an installed micro, its code and its dependencies
+	MaxMicroSize int64 = 250 << 20
+	// WarnRatio of MaxMicroSize is close to the limit
+	WarnRatio = 0.8
+	// lookupConcurrency is the number of packages looked up in the registries at once
+	lookupConcurrency = 8
+)
+
+// Package is a dependency with its installed size
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Size    int64  `json:"size"`
+	// SizeUnknown is set if the size couldn't be looked up, the size is 0 then
+	SizeUnknown bool `json:"size_unknown,omitempty"`
+	// Dev is set for development dependencies, they aren't installed in the micro and don't count toward the limit
+	Dev bool `json:"dev,omitempty"`
+}
+
+// Report is the size of the dependencies of a micro
+type Report struct {
+	// Source is what the sizes are read from, e.g. node_modules or package-lock.json
+	Source string `json:"source"`
+	// Installed is set if the sizes are measured in a local install, they are estimated from the registries otherwise
+	Installed bool `json:"installed"`
+	// Packages are sorted by size, the heaviest first
+	Packages []*Package `json:"packages"`
+	// Total is the size of the packages which count toward the limit, without the development dependencies
+	Total int64 `json:"total"`
+	// DevTotal is the size of the development dependencies
+	DevTotal int64 `json:"dev_total"`
+	// Unknown is the number of packages without a size which count toward the limit
+	Unknown int `json:"unknown"`
+}
+
+// Heaviest returns the n heaviest packages which count toward the limit
+func (r *Report) Heaviest(n int) []*Package {
+	var heaviest []*Package
+	for _, p := range r.Packages {
+		if len(heaviest) == n {
+			break
+		}
+		if !p.Dev {
+			heaviest = append(heaviest, p)
+		}
+	}
+	return heaviest
+}
+
+// DevPackages returns the number of development dependencies
+func (r *Report) DevPackages() int {
+	n := 0
+	for _, p := range r.Packages {
+		if p.Dev {
+			n++
+		}
+	}
+	return n
+}
+
+// Ratio returns the share of the limit used by the dependencies
+func (r *Report) Ratio(limit int64) float64 {
+	return float64(r.Total) / float64(limit)
+}
+
+func newReport(source string, installed bool, packages []*Package) *Report {
+	r := &Report{Source: source, Installed: installed, Packages: packages}
+	for _, p := range packages {
+		if p.Dev {
+			r.DevTotal += p.Size
+			continue
+		}
+		r.Total += p.Size
+		if p.SizeUnknown {
+			r.Unknown++
+		}
+	}
+	sort.SliceStable(r.Packages, func(i, j int) bool {
+		if r.Packages[i].Size != r.Packages[j].Size {
+			return r.Packages[i].Size > r.Packages[j].Size
+		}
+		return r.Packages[i].Name < r.Packages[j].Name
+	})
+	return r
+}
+
+// Sizer looks up the installed size of a locked package
+type Sizer interface {
+	Size(ctx context.Context, p *lockfile.Locked) (int64, error)
+}
+
+// Analyze measures the dependencies installed in dir, without a local install they're estimated from the lockfiles
+// with sizer, a nil sizer leaves the sizes unknown. The report is nil if dir has neither.
+func Analyze(ctx context.Context, dir string, sizer Sizer) (*Report, error) {
+	report, err := Installed(dir)
+	if err != nil || report != nil {
+		return report, err
+	}
+	return Estimate(ctx, dir, sizer)
+}
+
+// Estimate looks up the sizes of the packages of the lockfiles in dir, packages which can't be looked up are listed
+// with an unknown size
+func Estimate(ctx context.Context, dir string, sizer Sizer) (*Report, error) {
+	locked, lockfiles, err := lockfile.Packages(dir)
+	if err != nil || len(lockfiles) == 0 {
+		return nil, err
+	}
+
+	packages := make([]*Package, len(locked))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(lookupConcurrency)
+	for i, l := range locked {
+		i, l := i, l
+		packages[i] = &Package{Name: l.Name, Version: l.Version, SizeUnknown: true, Dev: l.Dev}
+		if sizer == nil {
+			continue
+		}
+		g.Go(func() error {
+			size, err := sizer.Size(ctx, l)
+			if err == nil {
+				packages[i].Size, packages[i].SizeUnknown = size, false
+			}
+			// a failed lookup leaves the size unknown, cancelling stops the other lookups
+			return ctx.Err()
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if err := markDev(dir, packages); err != nil {
+		return nil, err
+	}
+	return newReport(strings.Join(lockfiles, ", "), false, packages), nil
+}
+
+// markDev marks the development dependencies of the micro in dir: packages which the lockfiles mark as dev and the
+// devDependencies of its package.json which aren't dependencies as well. Lockfiles without the mark, like yarn.lock,
+// only have the direct devDependencies marked.
+func markDev(dir string, packages []*Package) error {
+	locked, _, err := lockfile.Packages(dir)
+	if err != nil {
+		return err
+	}
+	dev, installed := make(map[string]bool), make(map[string]bool)
+	for _, l := range locked {
+		if l.Dev {
+			dev[normalizeName(l.Name)] = true
+		} else {
+			installed[normalizeName(l.Name)] = true
+		}
+	}
+	// a package which is a dependency in another version is installed anyway
+	for name := range installed {
+		delete(dev, name)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		var manifest struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if err := json.Unmarshal(content, &manifest); err == nil {
+			for name := range manifest.DevDependencies {
+				if _, ok := manifest.Dependencies[name]; !ok {
+					dev[normalizeName(name)] = true
+				}
+			}
+		}
+	}
+
+	for _, p := range packages {
+		if dev[normalizeName(p.Name)] {
+			p.Dev = true
+		}
+	}
+	return nil
+}
+
+// normalizeName makes the names of the lockfiles and of the installed packages comparable, python packages are
+// installed as Foo_Bar and locked as foo-bar
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
@@ -0,0 +1,161 @@
+package deps
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deta/space/internal/lockfile"
+	"gotest.tools/v3/assert"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NilError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+}
+
+func TestInstalled(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		expected *Report
+	}{
+		{
+			name:  "no install",
+			files: map[string]string{"package.json": "{}"},
+		},
+		{
+			name: "node_modules",
+			files: map[string]string{
+				"node_modules/.bin/tsc":                          "#!/bin/sh",
+				"node_modules/express/package.json":              `{"version": "4.18.2"}`,
+				"node_modules/express/index.js":                  strings.Repeat("x", 100),
+				"node_modules/express/node_modules/debug/dep.js": strings.Repeat("x", 50),
+				"node_modules/@types/node/index.d.ts":            strings.Repeat("x", 400),
+			},
+			expected: &Report{
+				Source:    "node_modules",
+				Installed: true,
+				Packages: []*Package{
+					{Name: "@types/node", Size: 400},
+					{Name: "express", Version: "4.18.2", Size: 171},
+				},
+				Total: 571,
+			},
+		},
+		{
+			name: "node_modules with dev dependencies",
+			files: map[string]string{
+				"package.json":                        `{"dependencies": {"express": "^4.18.0"}, "devDependencies": {"@types/node": "^20.0.0"}}`,
+				"node_modules/express/index.js":       strings.Repeat("x", 100),
+				"node_modules/@types/node/index.d.ts": strings.Repeat("x", 400),
+			},
+			expected: &Report{
+				Source:    "node_modules",
+				Installed: true,
+				Packages: []*Package{
+					{Name: "@types/node", Size: 400, Dev: true},
+					{Name: "express", Size: 100},
+				},
+				Total:    100,
+				DevTotal: 400,
+			},
+		},
+		{
+			name: "virtualenv",
+			files: map[string]string{
+				".venv/lib/python3.11/site-packages/requests-2.31.0.dist-info/RECORD": "requests/__init__.py,sha256=abc,5000\nrequests/__pycache__/__init__.cpython-311.pyc,,\nrequests-2.31.0.dist-info/RECORD,,\n",
+				".venv/lib/python3.11/site-packages/pip-23.1.dist-info/RECORD":        "pip/__init__.py,sha256=abc,100\n",
+				".venv/lib/python3.11/site-packages/idna-3.4.dist-info/METADATA":      "Name: idna",
+			},
+			expected: &Report{
+				Source:    ".venv",
+				Installed: true,
+				Packages: []*Package{
+					{Name: "requests", Version: "2.31.0", Size: 5000},
+					{Name: "idna", Version: "3.4", SizeUnknown: true},
+				},
+				Total:   5000,
+				Unknown: 1,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		writeFiles(t, dir, c.files)
+		report, err := Installed(dir)
+		assert.NilError(t, err, c.name)
+		assert.DeepEqual(t, report, c.expected)
+	}
+}
+
+type fakeSizer map[string]int64
+
+func (s fakeSizer) Size(ctx context.Context, p *lockfile.Locked) (int64, error) {
+	size, ok := s[p.Name]
+	if !ok {
+		return 0, errors.New("not found")
+	}
+	return size, nil
+}
+
+func TestEstimate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {"": {}, "node_modules/left-pad": {"version": "1.3.0"}, "node_modules/aws-sdk": {"version": "2.1380.0"}, "node_modules/private": {"version": "1.0.0"}}}`,
+	})
+
+	report, err := Estimate(context.Background(), dir, fakeSizer{"left-pad": 10, "aws-sdk": 90 << 20})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, report, &Report{
+		Source: "package-lock.json",
+		Packages: []*Package{
+			{Name: "aws-sdk", Version: "2.1380.0", Size: 90 << 20},
+			{Name: "left-pad", Version: "1.3.0", Size: 10},
+			{Name: "private", Version: "1.0.0", SizeUnknown: true},
+		},
+		Total:   90<<20 + 10,
+		Unknown: 1,
+	})
+	assert.Equal(t, len(report.Heaviest(1)), 1)
+	assert.Equal(t, len(report.Heaviest(10)), 3)
+
+	report, err = Estimate(context.Background(), dir, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, report.Unknown, 3)
+
+	report, err = Analyze(context.Background(), t.TempDir(), nil)
+	assert.NilError(t, err)
+	assert.Assert(t, report == nil)
+}
+
+func TestEstimateDev(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json":      `{"dependencies": {"express": "^4.18.0"}, "devDependencies": {"typescript": "^5.0.0", "express": "^4.18.0"}}`,
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {"": {}, "node_modules/express": {"version": "4.18.2"}, "node_modules/typescript": {"version": "5.0.4", "dev": true}, "node_modules/jest": {"version": "29.5.0", "dev": true}}}`,
+	})
+
+	report, err := Estimate(context.Background(), dir, fakeSizer{"express": 2 << 20, "typescript": 60 << 20, "jest": 5 << 20})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, report, &Report{
+		Source: "package-lock.json",
+		Packages: []*Package{
+			{Name: "typescript", Version: "5.0.4", Size: 60 << 20, Dev: true},
+			{Name: "jest", Version: "29.5.0", Size: 5 << 20, Dev: true},
+			{Name: "express", Version: "4.18.2", Size: 2 << 20},
+		},
+		Total:    2 << 20,
+		DevTotal: 65 << 20,
+	})
+	assert.DeepEqual(t, report.Heaviest(1), []*Package{{Name: "express", Version: "4.18.2", Size: 2 << 20}})
+	assert.Equal(t, report.DevPackages(), 2)
+}
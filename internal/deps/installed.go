@@ -0,0 +1,200 @@
+package deps
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// virtualenvDirs are the directories of a local virtualenv of a python micro
+var virtualenvDirs = []string{".venv", "venv"}
+
+// Installed measures the dependencies installed in the node_modules or the virtualenv in dir, the report is nil if
+// there is no local install
+func Installed(dir string) (*Report, error) {
+	modules := filepath.Join(dir, "node_modules")
+	if isDir(modules) {
+		packages, err := nodeModules(modules)
+		if err != nil {
+			return nil, err
+		}
+		if err := markDev(dir, packages); err != nil {
+			return nil, err
+		}
+		return newReport("node_modules", true, packages), nil
+	}
+
+	for _, venv := range virtualenvDirs {
+		sitePackages, err := sitePackagesDir(filepath.Join(dir, venv))
+		if err != nil {
+			return nil, err
+		}
+		if sitePackages == "" {
+			continue
+		}
+		packages, err := distributions(sitePackages)
+		if err != nil {
+			return nil, err
+		}
+		if err := markDev(dir, packages); err != nil {
+			return nil, err
+		}
+		return newReport(venv, true, packages), nil
+	}
+	return nil, nil
+}
+
+func isDir(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.IsDir()
+}
+
+// nodeModules measures the packages of a node_modules, a package includes its own nested node_modules. Packages
+// linked by pnpm are measured in the store they link to.
+func nodeModules(modules string) ([]*Package, error) {
+	entries, err := os.ReadDir(modules)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, e := range entries {
+		name := e.Name()
+		// .bin, .cache, .pnpm and the like aren't packages
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !strings.HasPrefix(name, "@") {
+			dirs = append(dirs, name)
+			continue
+		}
+		scoped, err := os.ReadDir(filepath.Join(modules, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range scoped {
+			dirs = append(dirs, name+"/"+s.Name())
+		}
+	}
+
+	var packages []*Package
+	for _, name := range dirs {
+		dir, err := filepath.EvalSymlinks(filepath.Join(modules, filepath.FromSlash(name)))
+		if err != nil || !isDir(dir) {
+			continue
+		}
+		size, err := dirSize(dir)
+		if err != nil {
+			return nil, err
+		}
+		p := &Package{Name: name, Size: size}
+		var manifest struct {
+			Version string `json:"version"`
+		}
+		if content, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil && json.Unmarshal(content, &manifest) == nil {
+			p.Version = manifest.Version
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// dirSize sums the sizes of the regular files in dir, symlinks aren't followed
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// sitePackagesDir returns the site-packages of a virtualenv, lib/pythonX.Y/site-packages on unix and
+// Lib/site-packages on windows, or "" if venv isn't a virtualenv
+func sitePackagesDir(venv string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(venv, "lib", "python*", "site-packages"))
+	if err != nil {
+		return "", err
+	}
+	matches = append(matches, filepath.Join(venv, "Lib", "site-packages"))
+	for _, m := range matches {
+		if isDir(m) {
+			return m, nil
+		}
+	}
+	return "", nil
+}
+
+// distributions measures the packages installed in site-packages with the sizes of the files in the RECORD of their
+// dist-info, which lists every file the installer wrote
+func distributions(sitePackages string) ([]*Package, error) {
+	infos, err := filepath.Glob(filepath.Join(sitePackages, "*.dist-info"))
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []*Package
+	for _, info := range infos {
+		// the directory is named name-version.dist-info, names can't contain dashes
+		name, version, _ := strings.Cut(strings.TrimSuffix(filepath.Base(info), ".dist-info"), "-")
+		if name == "pip" || name == "setuptools" || name == "wheel" {
+			// tools of the virtualenv, the builder brings its own
+			continue
+		}
+		size, err := recordSize(filepath.Join(info, "RECORD"))
+		if errors.Is(err, os.ErrNotExist) {
+			packages = append(packages, &Package{Name: name, Version: version, SizeUnknown: true})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, &Package{Name: name, Version: version, Size: size})
+	}
+	return packages, nil
+}
+
+// recordSize sums the sizes of a RECORD of a dist-info, it's a csv of path, hash and size, the size of the RECORD
+// itself and of compiled files is empty
+func recordSize(record string) (int64, error) {
+	f, err := os.Open(record)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	var size int64
+	for {
+		fields, err := r.Read()
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(fields) < 3 {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			size += n
+		}
+	}
+}
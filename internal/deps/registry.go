@@ -0,0 +1,114 @@
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/deta/space/internal/lockfile"
+)
+
+const (
+	// DefaultNPMURL is the public npm registry
+	DefaultNPMURL = "https://registry.npmjs.org/"
+	// DefaultPyPIURL is the public python package index
+	DefaultPyPIURL = "https://pypi.org/"
+)
+
+// RegistrySizer looks up the sizes of packages in the public registries. npm publishes the unpacked size of a
+// package, PyPI only the size of its files, so python packages are estimated with the size of the wheel Space installs.
+type RegistrySizer struct {
+	Client  *http.Client
+	NPMURL  string
+	PyPIURL string
+}
+
+// Size looks up the size of p
+func (s *RegistrySizer) Size(ctx context.Context, p *lockfile.Locked) (int64, error) {
+	switch p.Ecosystem {
+	case lockfile.EcosystemNPM:
+		// scoped packages are looked up with an escaped slash, e.g. @types%2fnode
+		u := strings.TrimSuffix(s.NPMURL, "/") + "/" + url.PathEscape(p.Name) + "/" + url.PathEscape(p.Version)
+		var resp struct {
+			Dist struct {
+				UnpackedSize int64 `json:"unpackedSize"`
+			} `json:"dist"`
+		}
+		if err := s.get(ctx, u, &resp); err != nil {
+			return 0, err
+		}
+		if resp.Dist.UnpackedSize <= 0 {
+			return 0, fmt.Errorf("%s@%s has no unpacked size", p.Name, p.Version)
+		}
+		return resp.Dist.UnpackedSize, nil
+	case lockfile.EcosystemPyPI:
+		u := strings.TrimSuffix(s.PyPIURL, "/") + "/pypi/" + url.PathEscape(p.Name) + "/" + url.PathEscape(p.Version) + "/json"
+		var resp struct {
+			URLs []*pypiFile `json:"urls"`
+		}
+		if err := s.get(ctx, u, &resp); err != nil {
+			return 0, err
+		}
+		f := pickFile(resp.URLs)
+		if f == nil {
+			return 0, fmt.Errorf("%s %s has no files", p.Name, p.Version)
+		}
+		return f.Size, nil
+	}
+	return 0, fmt.Errorf("unknown ecosystem %s", p.Ecosystem)
+}
+
+func (s *RegistrySizer) get(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// pypiFile is a file of a release on PyPI
+type pypiFile struct {
+	Filename    string `json:"filename"`
+	PackageType string `json:"packagetype"`
+	Size        int64  `json:"size"`
+}
+
+// pickFile returns the file Space most likely installs, micros run on linux x86_64: a manylinux wheel, a pure python
+// wheel or the sdist, in that order
+func pickFile(files []*pypiFile) *pypiFile {
+	rank := func(f *pypiFile) int {
+		switch {
+		case f.PackageType == "bdist_wheel" && strings.Contains(f.Filename, "manylinux") && strings.Contains(f.Filename, "x86_64"):
+			return 3
+		case f.PackageType == "bdist_wheel" && strings.HasSuffix(f.Filename, "-none-any.whl"):
+			return 2
+		case f.PackageType == "sdist":
+			return 1
+		}
+		return 0
+	}
+
+	var picked *pypiFile
+	for _, f := range files {
+		if rank(f) == 0 {
+			continue
+		}
+		// the largest of the manylinux wheels covers the python version of the micro
+		if picked == nil || rank(f) > rank(picked) || rank(f) == rank(picked) && f.Size > picked.Size {
+			picked = f
+		}
+	}
+	return picked
+}
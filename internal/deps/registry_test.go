@@ -0,0 +1,54 @@
+package deps
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deta/space/internal/lockfile"
+	"gotest.tools/v3/assert"
+)
+
+func TestRegistrySizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/npm/@types%2Fnode/20.1.0":
+			w.Write([]byte(`{"dist": {"unpackedSize": 3700000}}`))
+		case "/pypi/numpy/1.24.3/json":
+			w.Write([]byte(`{"urls": [
+				{"filename": "numpy-1.24.3-cp311-cp311-macosx_11_0_arm64.whl", "packagetype": "bdist_wheel", "size": 13800000},
+				{"filename": "numpy-1.24.3-cp310-cp310-manylinux_2_17_x86_64.manylinux2014_x86_64.whl", "packagetype": "bdist_wheel", "size": 17300000},
+				{"filename": "numpy-1.24.3-cp311-cp311-manylinux_2_17_x86_64.manylinux2014_x86_64.whl", "packagetype": "bdist_wheel", "size": 17400000},
+				{"filename": "numpy-1.24.3.tar.gz", "packagetype": "sdist", "size": 10900000}
+			]}`))
+		case "/pypi/six/1.16.0/json":
+			w.Write([]byte(`{"urls": [{"filename": "six-1.16.0.tar.gz", "packagetype": "sdist", "size": 34000}, {"filename": "six-1.16.0-py2.py3-none-any.whl", "packagetype": "bdist_wheel", "size": 11000}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	sizer := &RegistrySizer{Client: server.Client(), NPMURL: server.URL + "/npm/", PyPIURL: server.URL}
+	cases := []struct {
+		pkg      *lockfile.Locked
+		expected int64
+		err      bool
+	}{
+		{pkg: &lockfile.Locked{Ecosystem: lockfile.EcosystemNPM, Name: "@types/node", Version: "20.1.0"}, expected: 3700000},
+		{pkg: &lockfile.Locked{Ecosystem: lockfile.EcosystemPyPI, Name: "numpy", Version: "1.24.3"}, expected: 17400000},
+		{pkg: &lockfile.Locked{Ecosystem: lockfile.EcosystemPyPI, Name: "six", Version: "1.16.0"}, expected: 11000},
+		{pkg: &lockfile.Locked{Ecosystem: lockfile.EcosystemNPM, Name: "private", Version: "1.0.0"}, err: true},
+	}
+
+	for _, c := range cases {
+		size, err := sizer.Size(context.Background(), c.pkg)
+		if c.err {
+			assert.Assert(t, err != nil, c.pkg.Name)
+			continue
+		}
+		assert.NilError(t, err, c.pkg.Name)
+		assert.Equal(t, size, c.expected, c.pkg.Name)
+	}
+}
@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// frontMatterDelimiter encloses the yaml front matter at the top of the Discovery file
+	frontMatterDelimiter = "---"
+	// MaxTaglineLength is the longest tagline shown on Discovery
+	MaxTaglineLength = 69
+
+	// Template is the content of a new Discovery file
+	Template = `---
+title: ""
+tagline: ""
+theme_color: "#4A3AFF"
+git: ""
+homepage: ""
+---
+
+Describe what your app does and how to use it.
+`
+)
+
+var themeColorReg = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// FrontMatter holds the fields of the Discovery file
+type FrontMatter struct {
+	Title      string `yaml:"title"`
+	Tagline    string `yaml:"tagline"`
+	ThemeColor string `yaml:"theme_color"`
+	Git        string `yaml:"git"`
+	Homepage   string `yaml:"homepage"`
+}
+
+// Parse splits the Discovery file into its front matter and the markdown description
+func Parse(content []byte) (*FrontMatter, string, error) {
+	var fm FrontMatter
+	text := strings.TrimLeft(string(content), "\n")
+	if !strings.HasPrefix(text, frontMatterDelimiter+"\n") {
+		return &fm, text, nil
+	}
+
+	rest := strings.TrimPrefix(text, frontMatterDelimiter+"\n")
+	end := strings.Index(rest, "\n"+frontMatterDelimiter)
+	if end == -1 {
+		return nil, "", fmt.Errorf("the front matter isn't closed with %s", frontMatterDelimiter)
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &fm); err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+	body := strings.TrimPrefix(rest[end+1+len(frontMatterDelimiter):], "\n")
+	return &fm, body, nil
+}
+
+// Validate checks the front matter of the Discovery file
+func Validate(content []byte) error {
+	fm, _, err := Parse(content)
+	if err != nil {
+		return err
+	}
+	if n := utf8.RuneCountInString(fm.Tagline); n > MaxTaglineLength {
+		return fmt.Errorf("the tagline is %d characters long, at most %d are allowed", n, MaxTaglineLength)
+	}
+	if fm.ThemeColor != "" && !themeColorReg.MatchString(fm.ThemeColor) {
+		return fmt.Errorf("invalid theme_color %q, use a hex color like #4A3AFF", fm.ThemeColor)
+	}
+	for field, value := range map[string]string{"git": fm.Git, "homepage": fm.Homepage} {
+		if value == "" {
+			continue
+		}
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid %s %q, use an http or https url", field, value)
+		}
+	}
+	return nil
+}
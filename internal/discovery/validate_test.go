@@ -0,0 +1,46 @@
+package discovery
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "template", content: Template},
+		{name: "no front matter", content: "Just a description"},
+		{
+			name:    "long tagline",
+			content: "---\ntagline: \"" + strings.Repeat("a", 70) + "\"\n---\n",
+			err:     "the tagline is 70 characters long, at most 69 are allowed",
+		},
+		{name: "theme color", content: "---\ntheme_color: blue\n---\n", err: `invalid theme_color "blue", use a hex color like #4A3AFF`},
+		{name: "git url", content: "---\ngit: github.com/deta/space-cli\n---\n", err: `invalid git "github.com/deta/space-cli", use an http or https url`},
+		{name: "unclosed", content: "---\ntitle: app\n", err: "the front matter isn't closed with ---"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Validate([]byte(c.content))
+			if c.err == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, c.err)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	fm, body, err := Parse([]byte("---\ntitle: Notes\ntagline: Take notes\n---\n\nA note taking app\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, fm.Title, "Notes")
+	assert.Equal(t, fm.Tagline, "Take notes")
+	assert.Equal(t, body, "\nA note taking app\n")
+}
@@ -0,0 +1,76 @@
+package dotenv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "plain",
+			input:    "# comment\n\nA=1\nexport B = two words\nC=\n",
+			expected: map[string]string{"A": "1", "B": "two words", "C": ""},
+		},
+		{
+			name:     "quoted",
+			input:    "A=\"line\\nbreak # not a comment\" # comment\nB='$literal \\n'\nC=\"say \\\"hi\\\"\"",
+			expected: map[string]string{"A": "line\nbreak # not a comment", "B": `$literal \n`, "C": `say "hi"`},
+		},
+		{
+			name:     "inline comment",
+			input:    "A=value # comment\nB=no#comment",
+			expected: map[string]string{"A": "value", "B": "no#comment"},
+		},
+		{
+			name:     "override",
+			input:    "A=1\nA=2",
+			expected: map[string]string{"A": "2"},
+		},
+		{
+			name:  "missing value",
+			input: "A=1\nB",
+			err:   "line 2: expected NAME=value",
+		},
+		{
+			name:  "invalid name",
+			input: "1A=1",
+			err:   "line 1: expected NAME=value",
+		},
+		{
+			name:  "unterminated",
+			input: "A=\"open",
+			err:   "line 1: unterminated quoted value",
+		},
+	}
+
+	for _, c := range cases {
+		values, err := Parse(strings.NewReader(c.input))
+		if c.err != "" {
+			assert.Error(t, err, c.err, c.name)
+			continue
+		}
+		assert.NilError(t, err, c.name)
+		assert.DeepEqual(t, values, c.expected)
+	}
+}
+
+func TestWriteParse(t *testing.T) {
+	values := map[string]string{"B": "with \"quotes\" and\nnewline", "A": "#hash", "EMPTY": ""}
+
+	var buf bytes.Buffer
+	assert.NilError(t, Write(&buf, values))
+	assert.Assert(t, strings.HasPrefix(buf.String(), "A=\"#hash\"\nB="))
+
+	parsed, err := Parse(&buf)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, parsed, values)
+}
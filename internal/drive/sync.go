@@ -0,0 +1,236 @@
+package drive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// ManifestName name of the file holding the checksums of the synced files, stored next to the synced files
+	ManifestName = ".space-sync.json"
+)
+
+// Manifest maps file names relative to the sync prefix to their sha256 checksums
+type Manifest map[string]string
+
+// ParseManifest parses a manifest, an empty manifest is returned for empty content
+func ParseManifest(content []byte) (Manifest, error) {
+	m := make(Manifest)
+	if len(content) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("invalid sync manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Target drive name and optional prefix to sync with
+type Target struct {
+	Drive  string
+	Prefix string
+}
+
+// ParseTarget parses a target of the form <drive>[/prefix]
+func ParseTarget(target string) (*Target, error) {
+	parts := strings.SplitN(strings.Trim(target, "/"), "/", 2)
+	if parts[0] == "" {
+		return nil, fmt.Errorf("invalid drive target %q, expected <drive>[/prefix]", target)
+	}
+
+	t := &Target{Drive: parts[0]}
+	if len(parts) == 2 && parts[1] != "" {
+		t.Prefix = strings.TrimSuffix(parts[1], "/") + "/"
+	}
+	return t, nil
+}
+
+// RemoteName returns the name of a file in the drive
+func (t *Target) RemoteName(name string) string {
+	return path.Join(t.Prefix, name)
+}
+
+// LocalName returns the name of a remote file relative to the prefix
+func (t *Target) LocalName(remoteName string) string {
+	return strings.TrimPrefix(remoteName, t.Prefix)
+}
+
+// HashDir walks dir and returns the sha256 checksum of every regular file keyed by its slash separated relative path
+func HashDir(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".space" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ManifestName {
+			return nil
+		}
+
+		sum, err := HashFile(p)
+		if err != nil {
+			return err
+		}
+		files[rel] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// HashFile returns the hex encoded sha256 checksum of a file
+func HashFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LocalPath returns the path of the file name in dir, names of the drive which would lead outside of dir are rejected
+func LocalPath(dir string, name string) (string, error) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	// guard against remote names like ../../.bashrc
+	if !strings.HasPrefix(p, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid file name %s, it leads outside of %s", name, dir)
+	}
+	return p, nil
+}
+
+// LocalManifestPath returns where the manifest of the last sync of dir with the target is kept, it's the common base
+// a bidirectional sync compares both sides with
+func LocalManifestPath(dir string, target *Target) string {
+	return filepath.Join(dir, ".space", "drive-sync", target.Drive, filepath.FromSlash(target.Prefix), ManifestName)
+}
+
+// RemoteSums returns the checksums of the remote files as recorded in the manifest of the drive, files which weren't
+// written by a sync have an empty checksum
+func RemoteSums(remote []string, manifest Manifest) map[string]string {
+	sums := make(map[string]string, len(remote))
+	for _, name := range remote {
+		if name != ManifestName {
+			sums[name] = manifest[name]
+		}
+	}
+	return sums
+}
+
+// Options controls how a plan is computed
+type Options struct {
+	// Bidirectional applies the changes made on either side since the last sync to the other side
+	Bidirectional bool
+	// Delete removes files from the drive that don't exist locally, only used for push-only syncs
+	Delete bool
+}
+
+// Plan list of file names, relative to the sync prefix, to transfer
+type Plan struct {
+	Uploads   []string
+	Downloads []string
+	Deletes   []string
+	// LocalDeletes are files deleted in the drive since the last sync, only used for bidirectional syncs
+	LocalDeletes []string
+	// Conflicts are files changed both locally and in the drive since the last sync, they're left as they are
+	Conflicts []string
+}
+
+// IsEmpty reports whether the plan has nothing to do
+func (p *Plan) IsEmpty() bool {
+	return len(p.Uploads) == 0 && len(p.Downloads) == 0 && len(p.Deletes) == 0 && len(p.LocalDeletes) == 0 &&
+		len(p.Conflicts) == 0
+}
+
+// NewPlan compares the local checksums with the checksums of the remote files. A push-only sync uploads the files
+// which differ from the drive. A bidirectional sync compares both sides with base, the manifest of the last sync:
+// a change on one side is applied to the other side, a file deleted on one side is deleted on the other and a file
+// changed on both sides is a conflict.
+func NewPlan(local map[string]string, remote map[string]string, base Manifest, opts Options) *Plan {
+	p := &Plan{}
+	if !opts.Bidirectional {
+		for name, sum := range local {
+			if remoteSum, ok := remote[name]; !ok || remoteSum != sum {
+				p.Uploads = append(p.Uploads, name)
+			}
+		}
+		if opts.Delete {
+			for name := range remote {
+				if _, ok := local[name]; !ok {
+					p.Deletes = append(p.Deletes, name)
+				}
+			}
+		}
+		p.sort()
+		return p
+	}
+
+	names := make(map[string]struct{})
+	for _, m := range []map[string]string{local, remote, base} {
+		for name := range m {
+			names[name] = struct{}{}
+		}
+	}
+	for name := range names {
+		localSum, inLocal := local[name]
+		remoteSum, inRemote := remote[name]
+		baseSum, inBase := base[name]
+		localChanged := inLocal != inBase || inLocal && localSum != baseSum
+		// a remote file without a checksum was written outside of a sync, it may have changed
+		remoteChanged := inRemote != inBase || inRemote && (remoteSum == "" || remoteSum != baseSum)
+
+		switch {
+		case !localChanged && !remoteChanged:
+		case localChanged && !remoteChanged && inLocal:
+			p.Uploads = append(p.Uploads, name)
+		case localChanged && !remoteChanged:
+			p.Deletes = append(p.Deletes, name)
+		case !localChanged && inRemote:
+			p.Downloads = append(p.Downloads, name)
+		case !localChanged:
+			p.LocalDeletes = append(p.LocalDeletes, name)
+		case !inLocal && !inRemote, inLocal && inRemote && localSum == remoteSum:
+			// both sides made the same change
+		default:
+			p.Conflicts = append(p.Conflicts, name)
+		}
+	}
+	p.sort()
+	return p
+}
+
+func (p *Plan) sort() {
+	sort.Strings(p.Uploads)
+	sort.Strings(p.Downloads)
+	sort.Strings(p.Deletes)
+	sort.Strings(p.LocalDeletes)
+	sort.Strings(p.Conflicts)
+}
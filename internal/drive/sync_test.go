@@ -0,0 +1,194 @@
+package drive
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseTarget(t *testing.T) {
+	cases := []struct {
+		target string
+		drive  string
+		prefix string
+	}{
+		{target: "assets", drive: "assets", prefix: ""},
+		{target: "assets/", drive: "assets", prefix: ""},
+		{target: "assets/public", drive: "assets", prefix: "public/"},
+		{target: "assets/public/img/", drive: "assets", prefix: "public/img/"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.target, func(t *testing.T) {
+			target, err := ParseTarget(c.target)
+			assert.NilError(t, err)
+			assert.Equal(t, target.Drive, c.drive)
+			assert.Equal(t, target.Prefix, c.prefix)
+		})
+	}
+
+	if _, err := ParseTarget("/"); err == nil {
+		t.Fatalf("expected error for empty drive name")
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	dir := filepath.Join("sync", "assets")
+	cases := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{name: "logo.png", expected: filepath.Join(dir, "logo.png")},
+		{name: "img/a/../logo.png", expected: filepath.Join(dir, "img", "logo.png")},
+		{name: "../../.bashrc", err: true},
+		{name: "img/../../assets-2/logo.png", err: true},
+		{name: "..", err: true},
+		{name: "", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := LocalPath(dir, c.name)
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, p, c.expected)
+		})
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	local := map[string]string{
+		"unchanged.txt": "a",
+		"changed.txt":   "b",
+		"new.txt":       "c",
+	}
+	remote := map[string]string{
+		"unchanged.txt":   "a",
+		"changed.txt":     "old",
+		"remote-only.txt": "d",
+	}
+
+	t.Run("push", func(t *testing.T) {
+		plan := NewPlan(local, remote, nil, Options{})
+		assert.DeepEqual(t, plan.Uploads, []string{"changed.txt", "new.txt"})
+		assert.Equal(t, len(plan.Downloads), 0)
+		assert.Equal(t, len(plan.Deletes), 0)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		plan := NewPlan(local, remote, nil, Options{Delete: true})
+		assert.DeepEqual(t, plan.Deletes, []string{"remote-only.txt"})
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		plan := NewPlan(map[string]string{"unchanged.txt": "a"}, map[string]string{"unchanged.txt": "a"}, nil, Options{})
+		assert.Assert(t, plan.IsEmpty())
+	})
+}
+
+func TestNewPlanBidirectional(t *testing.T) {
+	base := Manifest{"file.txt": "a"}
+
+	cases := []struct {
+		name   string
+		local  map[string]string
+		remote map[string]string
+		base   Manifest
+		plan   *Plan
+	}{
+		{
+			name:   "unchanged",
+			local:  map[string]string{"file.txt": "a"},
+			remote: map[string]string{"file.txt": "a"},
+			base:   base,
+			plan:   &Plan{},
+		},
+		{
+			name:   "changed locally",
+			local:  map[string]string{"file.txt": "b"},
+			remote: map[string]string{"file.txt": "a"},
+			base:   base,
+			plan:   &Plan{Uploads: []string{"file.txt"}},
+		},
+		{
+			name:   "changed remotely",
+			local:  map[string]string{"file.txt": "a"},
+			remote: map[string]string{"file.txt": "b"},
+			base:   base,
+			plan:   &Plan{Downloads: []string{"file.txt"}},
+		},
+		{
+			name:   "changed remotely outside of a sync",
+			local:  map[string]string{"file.txt": "a"},
+			remote: map[string]string{"file.txt": ""},
+			base:   base,
+			plan:   &Plan{Downloads: []string{"file.txt"}},
+		},
+		{
+			name:   "changed on both sides",
+			local:  map[string]string{"file.txt": "b"},
+			remote: map[string]string{"file.txt": "c"},
+			base:   base,
+			plan:   &Plan{Conflicts: []string{"file.txt"}},
+		},
+		{
+			name:   "same change on both sides",
+			local:  map[string]string{"file.txt": "b"},
+			remote: map[string]string{"file.txt": "b"},
+			base:   base,
+			plan:   &Plan{},
+		},
+		{
+			name:   "deleted locally",
+			local:  map[string]string{},
+			remote: map[string]string{"file.txt": "a"},
+			base:   base,
+			plan:   &Plan{Deletes: []string{"file.txt"}},
+		},
+		{
+			name:   "deleted remotely",
+			local:  map[string]string{"file.txt": "a"},
+			remote: map[string]string{},
+			base:   base,
+			plan:   &Plan{LocalDeletes: []string{"file.txt"}},
+		},
+		{
+			name:   "deleted locally and changed remotely",
+			local:  map[string]string{},
+			remote: map[string]string{"file.txt": "b"},
+			base:   base,
+			plan:   &Plan{Conflicts: []string{"file.txt"}},
+		},
+		{
+			name:   "deleted on both sides",
+			local:  map[string]string{},
+			remote: map[string]string{},
+			base:   base,
+			plan:   &Plan{},
+		},
+		{
+			name:   "added on either side without a previous sync",
+			local:  map[string]string{"local.txt": "a"},
+			remote: map[string]string{"remote.txt": "b"},
+			base:   Manifest{},
+			plan:   &Plan{Uploads: []string{"local.txt"}, Downloads: []string{"remote.txt"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			plan := NewPlan(c.local, c.remote, c.base, Options{Bidirectional: true})
+			assert.DeepEqual(t, plan, c.plan)
+		})
+	}
+}
+
+func TestRemoteSums(t *testing.T) {
+	sums := RemoteSums([]string{"synced.txt", "uploaded.txt", ManifestName}, Manifest{"synced.txt": "a", "deleted.txt": "b"})
+	assert.DeepEqual(t, sums, map[string]string{"synced.txt": "a", "uploaded.txt": ""})
+}
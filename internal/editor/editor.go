@@ -0,0 +1,119 @@
+// Package editor lets the user edit text in the editor of their choice
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/deta/space/internal/config"
+)
+
+// EditorEnv overrides the editor of the config file and of the environment
+const EditorEnv = "SPACE_EDITOR"
+
+var (
+	// ErrNoEditor is returned if the editor can't be found
+	ErrNoEditor = errors.New("no editor available")
+	// ErrEmpty is returned by EditMarkdown if the saved file is empty, which cancels the edit
+	ErrEmpty = errors.New("empty file, the edit was cancelled")
+)
+
+// editorEnvs hold the editor of the user, the first one which is set is used
+var editorEnvs = []string{"VISUAL", "EDITOR"}
+
+// commentReg matches the html comments of a markdown file, they hold the help of a template
+var commentReg = regexp.MustCompile(`(?s)<!--.*?-->\n?`)
+
+// Command returns the command of the editor, e.g. "code --wait". It is read from SPACE_EDITOR, the config file,
+// VISUAL and EDITOR, the default is vi or notepad on windows.
+func Command() string {
+	if editor := strings.TrimSpace(os.Getenv(EditorEnv)); editor != "" {
+		return editor
+	}
+	if c, err := config.Load(); err == nil && strings.TrimSpace(c.Editor) != "" {
+		return strings.TrimSpace(c.Editor)
+	}
+	for _, env := range editorEnvs {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// Edit opens content in the editor and returns the saved content, pattern is the name of the temporary file,
+// e.g. "notes-*.md" so that the editor highlights markdown
+func Edit(content string, pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	// the editor can have arguments, e.g. "code --wait"
+	args := strings.Fields(Command())
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("%w: %s not found", ErrNoEditor, args[0])
+		}
+		return "", fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
+
+// EditMarkdown opens a markdown file with the help as a comment above the content. The comments are removed from the
+// saved content, which is checked by validate. If it's invalid, the file is opened again with the error in the comment.
+func EditMarkdown(name string, help string, content string, validate func(content string) error) (string, error) {
+	var invalid error
+	for {
+		header := help + "\nSave an empty file to cancel."
+		if invalid != nil {
+			header = fmt.Sprintf("Error: %s\n\n%s", invalid, header)
+		}
+
+		edited, err := Edit(fmt.Sprintf("<!--\n%s\n-->\n%s", header, content), name+"-*.md")
+		if err != nil {
+			return "", err
+		}
+		edited = strings.TrimSpace(StripComments(edited))
+		if edited == "" {
+			return "", ErrEmpty
+		}
+		if validate == nil {
+			return edited, nil
+		}
+		if invalid = validate(edited); invalid == nil {
+			return edited, nil
+		}
+		content = edited + "\n"
+	}
+}
+
+// StripComments removes the html comments from markdown
+func StripComments(content string) string {
+	return commentReg.ReplaceAllString(content, "")
+}
@@ -0,0 +1,57 @@
+package editor
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/deta/space/internal/home"
+	"gotest.tools/v3/assert"
+)
+
+func TestCommand(t *testing.T) {
+	cases := []struct {
+		visual   string
+		editor   string
+		expected string
+	}{
+		{visual: "code --wait", editor: "nano", expected: "code --wait"},
+		{visual: " ", editor: "nano", expected: "nano"},
+	}
+
+	t.Setenv(home.HomeEnv, t.TempDir())
+	t.Setenv(EditorEnv, "")
+	for _, c := range cases {
+		t.Setenv("VISUAL", c.visual)
+		t.Setenv("EDITOR", c.editor)
+		assert.Equal(t, Command(), c.expected)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the editor of the test is gnu sed")
+	}
+	t.Setenv(EditorEnv, "sed -i s/draft/final/")
+
+	edited, err := Edit("the draft notes\n", "notes-*.md")
+	assert.NilError(t, err)
+	assert.Equal(t, edited, "the final notes\n")
+}
+
+func TestEditMarkdown(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the editor of the test is gnu sed")
+	}
+	t.Setenv(EditorEnv, "sed -i s/draft/final/")
+
+	edited, err := EditMarkdown("notes", "Write the notes of the release.", "the draft notes\n", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, edited, "the final notes")
+
+	_, err = EditMarkdown("notes", "Write the notes of the release.", "", nil)
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestStripComments(t *testing.T) {
+	assert.Equal(t, StripComments("<!--\nhelp\n-->\n# Notes\n<!-- inline -->text"), "# Notes\ntext")
+}
@@ -0,0 +1,201 @@
+// Package engines compares engine versions and finds known incompatibilities of engine upgrades
+package engines
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	_ "embed"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules.yaml
+var rulesYaml []byte
+
+var (
+	pythonReg = regexp.MustCompile(`^python(\d+(?:\.\d+)*)$`)
+	nodeReg   = regexp.MustCompile(`^nodejs(\d+)(?:\.x)?$`)
+
+	// skippedDirs are not scanned for incompatibilities
+	skippedDirs = map[string]bool{".git": true, "node_modules": true, "__pycache__": true, ".venv": true, "venv": true, ".space": true}
+)
+
+// Version of the runtime of an engine, e.g. python 3.9 for python3.9
+type Version struct {
+	Runtime string
+	Parts   []int
+}
+
+// ParseVersion returns the runtime version of a versioned engine
+func ParseVersion(engine string) (*Version, error) {
+	runtime, raw := "", ""
+	if matches := pythonReg.FindStringSubmatch(engine); matches != nil {
+		runtime, raw = "python", matches[1]
+	} else if matches := nodeReg.FindStringSubmatch(engine); matches != nil {
+		runtime, raw = "node", matches[1]
+	} else {
+		return nil, fmt.Errorf("engine %s has no version", engine)
+	}
+
+	v := &Version{Runtime: runtime}
+	for _, part := range strings.Split(raw, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version of engine %s", engine)
+		}
+		v.Parts = append(v.Parts, n)
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal or higher than other, missing parts count as 0
+func (v *Version) Compare(other *Version) int {
+	for i := 0; i < len(v.Parts) || i < len(other.Parts); i++ {
+		var a, b int
+		if i < len(v.Parts) {
+			a = v.Parts[i]
+		}
+		if i < len(other.Parts) {
+			b = other.Parts[i]
+		}
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Rule is a known incompatibility introduced by a version of a runtime
+type Rule struct {
+	Runtime string `yaml:"runtime"`
+	Version string `yaml:"version"`
+	// Files is a glob matched against the names of files
+	Files   string `yaml:"files"`
+	Pattern string `yaml:"pattern"`
+	Message string `yaml:"message"`
+
+	version *Version
+	pattern *regexp.Regexp
+}
+
+// Rules returns the rules of the rules database
+func Rules() ([]*Rule, error) {
+	var rules []*Rule
+	if err := yaml.Unmarshal(rulesYaml, &rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	for _, r := range rules {
+		var err error
+		if r.pattern, err = regexp.Compile(r.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern of rule %q: %w", r.Message, err)
+		}
+		parts := strings.Split(r.Version, ".")
+		r.version = &Version{Runtime: r.Runtime}
+		for _, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version of rule %q", r.Message)
+			}
+			r.version.Parts = append(r.version.Parts, n)
+		}
+	}
+	return rules, nil
+}
+
+// applies reports if the upgrade from one version to another crosses the version of the rule
+func (r *Rule) applies(from *Version, to *Version) bool {
+	return r.Runtime == to.Runtime && from.Compare(r.version) < 0 && to.Compare(r.version) >= 0
+}
+
+// Finding is a line which matches a rule
+type Finding struct {
+	Path string
+	Line int
+	Rule *Rule
+}
+
+// Check scans the files in dir for known incompatibilities of the upgrade from one engine to another
+func Check(dir string, from string, to string) ([]*Finding, error) {
+	toVersion, err := ParseVersion(to)
+	if err != nil {
+		return nil, err
+	}
+	fromVersion, err := ParseVersion(from)
+	if err != nil || fromVersion.Runtime != toVersion.Runtime {
+		// switching runtimes is not covered by the rules
+		return nil, nil
+	}
+
+	rules, err := Rules()
+	if err != nil {
+		return nil, err
+	}
+	var applying []*Rule
+	for _, r := range rules {
+		if r.applies(fromVersion, toVersion) {
+			applying = append(applying, r)
+		}
+	}
+	if len(applying) == 0 {
+		return nil, nil
+	}
+
+	var findings []*Finding
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if skippedDirs[info.Name()] && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var matching []*Rule
+		for _, r := range applying {
+			if ok, _ := filepath.Match(r.Files, info.Name()); ok {
+				matching = append(matching, r)
+			}
+		}
+		if len(matching) == 0 {
+			return nil
+		}
+
+		fileFindings, err := scan(path, matching)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, fileFindings...)
+		return nil
+	})
+	return findings, err
+}
+
+func scan(path string, rules []*Rule) ([]*Finding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var findings []*Finding
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		for _, r := range rules {
+			if r.pattern.MatchString(scanner.Text()) {
+				findings = append(findings, &Finding{Path: path, Line: line, Rule: r})
+			}
+		}
+	}
+	return findings, scanner.Err()
+}
@@ -0,0 +1,91 @@
+package engines
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deta/space/internal/api"
+	"github.com/deta/space/internal/spacefile"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	cases := []struct {
+		engine  string
+		runtime string
+		parts   []int
+		err     bool
+	}{
+		{engine: "python3.9", runtime: "python", parts: []int{3, 9}},
+		{engine: "python3.12", runtime: "python", parts: []int{3, 12}},
+		{engine: "nodejs16", runtime: "node", parts: []int{16}},
+		{engine: "nodejs18.x", runtime: "node", parts: []int{18}},
+		{engine: "react", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.engine, func(t *testing.T) {
+			v, err := ParseVersion(c.engine)
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, v.Runtime, c.runtime)
+			assert.DeepEqual(t, v.Parts, c.parts)
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	_, err := Rules()
+	assert.NilError(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "setup.py"), []byte("import os\nfrom distutils.core import setup\n"), 0644))
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "node_modules"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "node_modules", "x.py"), []byte("import distutils\n"), 0644))
+
+	findings, err := Check(dir, "python3.9", "python3.12")
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 1)
+	assert.Equal(t, findings[0].Path, filepath.Join(dir, "setup.py"))
+	assert.Equal(t, findings[0].Line, 2)
+
+	findings, err = Check(dir, "python3.9", "python3.11")
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 0)
+}
+
+func TestStatuses(t *testing.T) {
+	embedded := Statuses(nil)
+	assert.Equal(t, embedded["python3.8"].Status, api.EngineDeprecated)
+	assert.Equal(t, embedded["python3.8"].Replacement, "python3.9")
+	schemaEngines := map[string]bool{}
+	for _, engine := range spacefile.Engines() {
+		schemaEngines[engine] = true
+	}
+	for name, e := range embedded {
+		assert.Equal(t, e.Name, name)
+		assert.Assert(t, e.Status == api.EngineSupported || e.Status == api.EngineDeprecated || e.Status == api.EngineSunset, name)
+		// the warnings only match engines of valid Spacefiles and suggest valid replacements
+		assert.Assert(t, schemaEngines[name], "%s is not an engine of the Spacefile", name)
+		if e.Replacement != "" {
+			assert.Assert(t, schemaEngines[e.Replacement], "replacement %s is not an engine of the Spacefile", e.Replacement)
+		}
+	}
+
+	merged := Statuses([]*api.Engine{
+		{Name: "python3.8", Status: api.EngineSunset},
+		{Name: "python3.9", Status: api.EngineSupported},
+	})
+	assert.Equal(t, merged["python3.8"].Status, api.EngineSunset)
+	assert.Equal(t, merged["python3.9"].Status, api.EngineSupported)
+
+	// engines the api doesn't list keep their embedded status
+	merged = Statuses([]*api.Engine{{Name: "python3.9", Status: api.EngineSupported}})
+	assert.Equal(t, merged["python3.8"].Status, api.EngineDeprecated)
+}
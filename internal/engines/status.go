@@ -0,0 +1,35 @@
+package engines
+
+import (
+	_ "embed"
+
+	"github.com/deta/space/internal/api"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed statuses.yaml
+var statusesYaml []byte
+
+type status struct {
+	Name        string `yaml:"name"`
+	Status      string `yaml:"status"`
+	SunsetAt    string `yaml:"sunset_at"`
+	Replacement string `yaml:"replacement"`
+}
+
+// Statuses returns the support status of the engines shipped with the cli, overridden by the engines of the api if
+// it could be listed
+func Statuses(remote []*api.Engine) map[string]*api.Engine {
+	var embedded []*status
+	// the embedded list is checked by the tests
+	_ = yaml.Unmarshal(statusesYaml, &embedded)
+
+	engines := make(map[string]*api.Engine, len(embedded)+len(remote))
+	for _, s := range embedded {
+		engines[s.Name] = &api.Engine{Name: s.Name, Status: s.Status, SunsetAt: s.SunsetAt, Replacement: s.Replacement}
+	}
+	for _, e := range remote {
+		engines[e.Name] = e
+	}
+	return engines
+}
@@ -0,0 +1,234 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deta/space/internal/spacefile"
+)
+
+const (
+	// FormatVersion version of the archive layout
+	FormatVersion = 1
+
+	manifestFile  = "space-export.json"
+	spacefileFile = "Spacefile"
+	discoveryFile = "Discovery.md"
+	sourceFile    = "source.zip"
+)
+
+var (
+	// ErrInvalidArchive the archive was not created by space export
+	ErrInvalidArchive = errors.New("not a space export archive")
+)
+
+// Project project details at the time of the export
+type Project struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+}
+
+// Env environment variable declared by a micro, the value is only present if values were exported
+type Env struct {
+	Micro       string `json:"micro"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// Schedule scheduled action declared by a micro
+type Schedule struct {
+	Micro    string `json:"micro"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Interval string `json:"interval"`
+}
+
+// Manifest describes the contents of an export archive
+type Manifest struct {
+	FormatVersion int        `json:"format_version"`
+	ExportedAt    time.Time  `json:"exported_at"`
+	CliVersion    string     `json:"cli_version"`
+	Project       Project    `json:"project"`
+	RevisionID    string     `json:"revision_id,omitempty"`
+	Env           []Env      `json:"env"`
+	Schedules     []Schedule `json:"schedules"`
+	// EnvValues encrypted json object of env names to values, empty if values were not exported
+	EnvValues []byte `json:"env_values,omitempty"`
+}
+
+// Bundle everything needed to recreate a project
+type Bundle struct {
+	Manifest  Manifest
+	Spacefile []byte
+	Discovery []byte
+	// Source zipped source code of the project
+	Source []byte
+}
+
+// DescribeSpacefile fills the env and schedule declarations of the manifest from the Spacefile
+func (m *Manifest) DescribeSpacefile(s *spacefile.Spacefile) {
+	for _, micro := range s.Micros {
+		if micro.Presets != nil {
+			for _, env := range micro.Presets.Env {
+				m.Env = append(m.Env, Env{
+					Micro:       micro.Name,
+					Name:        env.Name,
+					Description: env.Description,
+					Default:     env.Default,
+				})
+			}
+		}
+		for _, action := range micro.Actions {
+			if action.Trigger != "schedule" {
+				continue
+			}
+			m.Schedules = append(m.Schedules, Schedule{
+				Micro:    micro.Name,
+				ID:       action.ID,
+				Name:     action.Name,
+				Interval: action.Interval,
+			})
+		}
+	}
+}
+
+// Write writes the bundle as a zip archive
+func (b *Bundle) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{manifestFile, manifest},
+		{spacefileFile, b.Spacefile},
+		{discoveryFile, b.Discovery},
+		{sourceFile, b.Source},
+	}
+	for _, file := range files {
+		if file.content == nil {
+			continue
+		}
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to archive: %w", file.name, err)
+		}
+		if _, err := f.Write(file.content); err != nil {
+			return fmt.Errorf("failed to add %s to archive: %w", file.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// Read reads a bundle from an export archive
+func Read(archivePath string) (*Bundle, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer r.Close()
+
+	var b Bundle
+	var hasManifest bool
+	for _, f := range r.File {
+		content, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		switch f.Name {
+		case manifestFile:
+			if err := json.Unmarshal(content, &b.Manifest); err != nil {
+				return nil, fmt.Errorf("%w: invalid manifest: %v", ErrInvalidArchive, err)
+			}
+			hasManifest = true
+		case spacefileFile:
+			b.Spacefile = content
+		case discoveryFile:
+			b.Discovery = content
+		case sourceFile:
+			b.Source = content
+		}
+	}
+
+	if !hasManifest {
+		return nil, ErrInvalidArchive
+	}
+	if b.Manifest.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("archive format version %d is not supported, please upgrade the Space CLI", b.Manifest.FormatVersion)
+	}
+	return &b, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from archive: %w", f.Name, err)
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// ExtractSource unzips the source code into dir, the Spacefile and Discovery file of the bundle take precedence
+func (b *Bundle) ExtractSource(dir string) error {
+	if b.Source != nil {
+		r, err := zip.NewReader(bytes.NewReader(b.Source), int64(len(b.Source)))
+		if err != nil {
+			return fmt.Errorf("failed to read source archive: %w", err)
+		}
+
+		for _, f := range r.File {
+			target := filepath.Join(dir, filepath.FromSlash(f.Name))
+			// guard against zip slip
+			if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+				return fmt.Errorf("invalid file path %s in source archive", f.Name)
+			}
+			if f.FileInfo().IsDir() {
+				continue
+			}
+
+			content, err := readZipFile(f)
+			if err != nil {
+				return err
+			}
+			if err := writeFile(target, content); err != nil {
+				return err
+			}
+		}
+	}
+
+	if b.Spacefile != nil {
+		if err := writeFile(filepath.Join(dir, spacefileFile), b.Spacefile); err != nil {
+			return err
+		}
+	}
+	if b.Discovery != nil {
+		if err := writeFile(filepath.Join(dir, discoveryFile), b.Discovery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,148 @@
+// Package featureflags stores the feature flags of an app as items of a Base of its project, so that apps can read
+// them at runtime and operators can toggle features without a redeploy. Every item is a flag keyed by its name:
+//
+//	{"key": "new-checkout", "type": "bool", "value": true, "updated_at": "...", "updated_by": "..."}
+package featureflags
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BaseName is the name of the Base holding the flags of a project
+const BaseName = "feature_flags"
+
+// Type of the value of a flag
+type Type string
+
+const (
+	Bool   Type = "bool"
+	Int    Type = "int"
+	Float  Type = "float"
+	String Type = "string"
+	JSON   Type = "json"
+)
+
+// Types lists the types of flag values
+var Types = []Type{Bool, Int, Float, String, JSON}
+
+// Flag is a feature flag with a typed value
+type Flag struct {
+	Name      string
+	Type      Type
+	Value     interface{}
+	UpdatedAt string
+	UpdatedBy string
+}
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ValidateName checks that the name of a flag is a valid Base key which is easy to use in code, e.g. new-checkout
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid flag name %q, use letters, numbers, dots, dashes and underscores", name)
+	}
+	return nil
+}
+
+// ParseType parses the name of a type, e.g. bool
+func ParseType(s string) (Type, error) {
+	for _, t := range Types {
+		if string(t) == strings.ToLower(s) {
+			return t, nil
+		}
+	}
+	names := make([]string, 0, len(Types))
+	for _, t := range Types {
+		names = append(names, string(t))
+	}
+	return "", fmt.Errorf("unknown flag type %q, use one of %s", s, strings.Join(names, ", "))
+}
+
+// Parse parses the value of a flag of type t from the command line
+func Parse(t Type, s string) (interface{}, error) {
+	switch t {
+	case Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a bool, use true or false", s)
+		}
+		return v, nil
+	case Int:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an int", s)
+		}
+		return v, nil
+	case Float:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a float", s)
+		}
+		return v, nil
+	case String:
+		return s, nil
+	case JSON:
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("%q is not valid json: %w", s, err)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown flag type %q", t)
+}
+
+// FromItem returns the flag stored in a Base item
+func FromItem(item map[string]interface{}) (*Flag, error) {
+	name, _ := item["key"].(string)
+	typeName, _ := item["type"].(string)
+	t, err := ParseType(typeName)
+	if err != nil {
+		return nil, fmt.Errorf("flag %s: %w", name, err)
+	}
+
+	value := item["value"]
+	// numbers are decoded as floats from json
+	if n, ok := value.(float64); ok && t == Int {
+		value = int64(n)
+	}
+
+	f := &Flag{Name: name, Type: t, Value: value}
+	f.UpdatedAt, _ = item["updated_at"].(string)
+	f.UpdatedBy, _ = item["updated_by"].(string)
+	return f, nil
+}
+
+// Item returns the Base item storing the flag
+func (f *Flag) Item() map[string]interface{} {
+	item := map[string]interface{}{
+		"key":   f.Name,
+		"type":  string(f.Type),
+		"value": f.Value,
+	}
+	if f.UpdatedAt != "" {
+		item["updated_at"] = f.UpdatedAt
+	}
+	if f.UpdatedBy != "" {
+		item["updated_by"] = f.UpdatedBy
+	}
+	return item
+}
+
+// FormatValue formats the value like it's parsed, json values are compact json
+func (f *Flag) FormatValue() string {
+	if f.Type == JSON {
+		content, err := json.Marshal(f.Value)
+		if err != nil {
+			return fmt.Sprint(f.Value)
+		}
+		return string(content)
+	}
+	if v, ok := f.Value.(float64); ok {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(f.Value)
+}
@@ -0,0 +1,61 @@
+package featureflags
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		typ      Type
+		value    string
+		expected interface{}
+		err      bool
+	}{
+		{typ: Bool, value: "true", expected: true},
+		{typ: Bool, value: "yes", err: true},
+		{typ: Int, value: "42", expected: int64(42)},
+		{typ: Int, value: "4.2", err: true},
+		{typ: Float, value: "0.25", expected: 0.25},
+		{typ: String, value: "blue", expected: "blue"},
+		{typ: JSON, value: `{"percent": 10}`, expected: map[string]interface{}{"percent": 10.0}},
+		{typ: JSON, value: `{percent}`, err: true},
+	}
+
+	for _, c := range cases {
+		value, err := Parse(c.typ, c.value)
+		if c.err {
+			assert.Assert(t, err != nil, c.value)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.DeepEqual(t, value, c.expected)
+	}
+}
+
+func TestItem(t *testing.T) {
+	flag := &Flag{Name: "max-uploads", Type: Int, Value: int64(5), UpdatedBy: "octocat"}
+
+	// items are stored as json, so numbers come back as floats
+	content, err := json.Marshal(flag.Item())
+	assert.NilError(t, err)
+	var item map[string]interface{}
+	assert.NilError(t, json.Unmarshal(content, &item))
+
+	parsed, err := FromItem(item)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, parsed, flag)
+	assert.Equal(t, parsed.FormatValue(), "5")
+
+	_, err = FromItem(map[string]interface{}{"key": "broken", "type": "date"})
+	assert.ErrorContains(t, err, "unknown flag type")
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NilError(t, ValidateName("new-checkout"))
+	assert.NilError(t, ValidateName("checkout.v2_enabled"))
+	assert.Assert(t, ValidateName("new checkout") != nil)
+	assert.Assert(t, ValidateName("-checkout") != nil)
+}
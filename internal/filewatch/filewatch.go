@@ -0,0 +1,164 @@
+// Package filewatch watches the files of a project and reports changes in batches, so that saving several files at
+// once results in a single batch
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultQuiet is how long no file has to change before a batch is reported
+const DefaultQuiet = 500 * time.Millisecond
+
+// Watch watches dir and the directories in it and sends the changed paths relative to dir, paths for which ignored
+// returns true aren't watched. ignored gets absolute paths and can be nil. The channels are closed once ctx is done.
+func Watch(ctx context.Context, dir string, ignored func(path string) bool) (<-chan string, <-chan error, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ignored == nil {
+		ignored = func(string) bool { return false }
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	if err := addDirs(watcher, root, root, ignored); err != nil {
+		watcher.Close()
+		return nil, nil, err
+	}
+
+	changes := make(chan string)
+	errs := make(chan error)
+	go func() {
+		defer close(changes)
+		defer close(errs)
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Name == root || ignored(event.Name) {
+					continue
+				}
+				// a new directory has to be watched as well, the files created in it before are reported by the walk
+				if event.Has(fsnotify.Create) {
+					if stat, err := os.Stat(event.Name); err == nil && stat.IsDir() {
+						if err := addDirs(watcher, root, event.Name, ignored); err != nil {
+							send(ctx, errs, err)
+						}
+					}
+				}
+				rel, err := filepath.Rel(root, event.Name)
+				if err != nil {
+					continue
+				}
+				send(ctx, changes, filepath.ToSlash(rel))
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				send(ctx, errs, err)
+			}
+		}
+	}()
+	return changes, errs, nil
+}
+
+// addDirs watches dir and the directories in it which aren't ignored
+func addDirs(watcher *fsnotify.Watcher, root string, dir string, ignored func(path string) bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the directory can be gone by the time it's walked
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && ignored(path) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+func send[T any](ctx context.Context, ch chan<- T, v T) {
+	select {
+	case ch <- v:
+	case <-ctx.Done():
+	}
+}
+
+// Debounce collects the changes into batches of distinct sorted paths, a batch is sent once no path changed for
+// quiet. Changes keep being collected while a batch isn't received. The batches are closed once changes is.
+func Debounce(changes <-chan string, quiet time.Duration) <-chan []string {
+	batches := make(chan []string)
+	go func() {
+		defer close(batches)
+		pending := make(map[string]bool)
+		timer := time.NewTimer(quiet)
+		timer.Stop()
+		var ready bool
+
+		for {
+			// only offer the batch once it's ready, a nil channel blocks forever
+			var out chan []string
+			var batch []string
+			if ready {
+				out = batches
+				batch = sortedPaths(pending)
+			}
+
+			select {
+			case path, ok := <-changes:
+				if !ok {
+					return
+				}
+				pending[path] = true
+				ready = false
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(quiet)
+			case <-timer.C:
+				ready = len(pending) > 0
+			case out <- batch:
+				pending = make(map[string]bool)
+				ready = false
+			}
+		}
+	}()
+	return batches
+}
+
+func sortedPaths(paths map[string]bool) []string {
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
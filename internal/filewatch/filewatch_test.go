@@ -0,0 +1,56 @@
+package filewatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDebounce(t *testing.T) {
+	cases := []struct {
+		name     string
+		changes  []string
+		expected []string
+	}{
+		{name: "single change", changes: []string{"main.py"}, expected: []string{"main.py"}},
+		{name: "burst", changes: []string{"b.js", "a.js", "b.js"}, expected: []string{"a.js", "b.js"}},
+	}
+
+	for _, c := range cases {
+		changes := make(chan string)
+		batches := Debounce(changes, 20*time.Millisecond)
+		for _, path := range c.changes {
+			changes <- path
+		}
+		assert.DeepEqual(t, <-batches, c.expected)
+		close(changes)
+		_, ok := <-batches
+		assert.Assert(t, !ok, c.name)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o755))
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "node_modules"), 0o755))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ignored := func(path string) bool { return strings.Contains(path, "node_modules") }
+	changes, _, err := Watch(ctx, dir, ignored)
+	assert.NilError(t, err)
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "node_modules", "dep.js"), nil, 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "src", "main.js"), nil, 0o644))
+	select {
+	case path := <-changes:
+		assert.Equal(t, path, "src/main.js")
+	case <-ctx.Done():
+		t.Fatal("no change reported")
+	}
+}
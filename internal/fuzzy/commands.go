@@ -0,0 +1,167 @@
+package fuzzy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// EnableSuggestions makes every command with subcommands reject unknown commands with suggestions from the whole
+// command tree, and unknown flags with the closest flags of the command
+func EnableSuggestions(root *cobra.Command) {
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		// commands with their own argument validation take positional arguments
+		if c.HasSubCommands() && c.Args == nil {
+			c.Args = unknownCommand
+		}
+		for _, child := range c.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	root.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		if suggestions := SuggestFlags(c, err); len(suggestions) > 0 {
+			return fmt.Errorf("%w%s", err, formatSuggestions(suggestions))
+		}
+		return err
+	})
+}
+
+func unknownCommand(c *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unknown command %q for %q%s", args[0], c.CommandPath(), formatSuggestions(SuggestCommands(c, args)))
+}
+
+// formatSuggestions matches the suggestions of cobra
+func formatSuggestions(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nDid you mean this?\n")
+	for _, s := range suggestions {
+		fmt.Fprintf(&b, "\t%s\n", s)
+	}
+	return b.String()
+}
+
+// names returns the names and aliases of the available subcommands of c by subcommand
+func names(c *cobra.Command) map[string]*cobra.Command {
+	result := make(map[string]*cobra.Command)
+	for _, child := range c.Commands() {
+		if !child.IsAvailableCommand() {
+			continue
+		}
+		result[child.Name()] = child
+		for _, alias := range child.Aliases {
+			result[alias] = child
+		}
+	}
+	return result
+}
+
+// SuggestCommands returns the command paths the unknown args of c could mean. The subcommands of c which are close to
+// the first arg come first, then the commands anywhere in the tree with that name, e.g. space release notes for
+// space notes. A suggestion includes the next arg if it's a subcommand of the suggested command, so that releases
+// promote suggests release promote instead of release.
+func SuggestCommands(c *cobra.Command, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	word := args[0]
+
+	var suggestions []string
+	seen := make(map[*cobra.Command]bool)
+	add := func(match *cobra.Command) {
+		if seen[match] || len(suggestions) == maxMatches {
+			return
+		}
+		seen[match] = true
+		path := match.CommandPath()
+		if len(args) > 1 {
+			if next, ok := names(match)[args[1]]; ok {
+				path = next.CommandPath()
+			}
+		}
+		suggestions = append(suggestions, path)
+	}
+
+	children := names(c)
+	candidates := make([]string, 0, len(children))
+	for name := range children {
+		candidates = append(candidates, name)
+	}
+	matches := Matches(word, candidates)
+	// a match which also knows the next arg is the most likely
+	if len(args) > 1 {
+		for _, name := range matches {
+			if _, ok := names(children[name])[args[1]]; ok {
+				add(children[name])
+			}
+		}
+	}
+	for _, name := range matches {
+		add(children[name])
+	}
+
+	var walk func(parent *cobra.Command)
+	walk = func(parent *cobra.Command) {
+		for _, child := range parent.Commands() {
+			if !child.IsAvailableCommand() {
+				continue
+			}
+			if parent != c && nameMatches(child, word) {
+				add(child)
+			}
+			walk(child)
+		}
+	}
+	walk(c.Root())
+
+	return suggestions
+}
+
+// nameMatches reports if the name or an alias of c is the word or a typo of a longer word
+func nameMatches(c *cobra.Command, word string) bool {
+	for _, name := range append([]string{c.Name()}, c.Aliases...) {
+		if name == word || len(word) > 3 && Distance(name, word) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+var unknownFlag = regexp.MustCompile(`^unknown flag: --(\S+)$`)
+
+// SuggestFlags returns the flags of c which an unknown flag error could mean
+func SuggestFlags(c *cobra.Command, err error) []string {
+	m := unknownFlag.FindStringSubmatch(err.Error())
+	if m == nil {
+		return nil
+	}
+
+	var candidates []string
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden {
+			candidates = append(candidates, f.Name)
+		}
+	})
+	c.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden {
+			candidates = append(candidates, f.Name)
+		}
+	})
+
+	matches := Matches(m[1], candidates)
+	for i, name := range matches {
+		matches[i] = "--" + name
+	}
+	return matches
+}
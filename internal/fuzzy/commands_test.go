@@ -0,0 +1,89 @@
+package fuzzy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func testCommand() *cobra.Command {
+	run := func(cmd *cobra.Command, args []string) {}
+	root := &cobra.Command{Use: "space", Run: run}
+	push := &cobra.Command{Use: "push", Run: run}
+	push.Flags().String("tag", "", "")
+	push.Flags().Bool("skip-logs", false, "")
+	release := &cobra.Command{Use: "release", Run: run}
+	promote := &cobra.Command{Use: "promote", Run: run}
+	notes := &cobra.Command{Use: "notes", Run: run}
+	notes.AddCommand(&cobra.Command{Use: "edit", Run: run})
+	release.AddCommand(promote, notes)
+	project := &cobra.Command{Use: "project", Run: run}
+	project.AddCommand(&cobra.Command{Use: "clone", Run: run}, &cobra.Command{Use: "list", Aliases: []string{"ls"}, Run: run})
+	root.AddCommand(push, release, project, &cobra.Command{Use: "internal", Hidden: true, Run: run})
+	root.PersistentFlags().Bool("no-cache", false, "")
+	return root
+}
+
+func TestSuggestCommands(t *testing.T) {
+	root := testCommand()
+	project, _, _ := root.Find([]string{"project"})
+
+	cases := []struct {
+		name     string
+		cmd      *cobra.Command
+		args     []string
+		expected []string
+	}{
+		{name: "typo", cmd: root, args: []string{"pussh"}, expected: []string{"space push"}},
+		{name: "plural group", cmd: root, args: []string{"releases", "promote"}, expected: []string{"space release promote"}},
+		{name: "nested command", cmd: root, args: []string{"promote"}, expected: []string{"space release promote"}},
+		{name: "nested group", cmd: root, args: []string{"notes", "edit"}, expected: []string{"space release notes edit"}},
+		{name: "subcommand", cmd: project, args: []string{"clne"}, expected: []string{"space project clone"}},
+		{name: "alias", cmd: root, args: []string{"ls"}, expected: []string{"space project list"}},
+		{name: "hidden", cmd: root, args: []string{"internl"}, expected: nil},
+		{name: "unrelated", cmd: root, args: []string{"deploy"}, expected: nil},
+	}
+
+	for _, c := range cases {
+		assert.DeepEqual(t, SuggestCommands(c.cmd, c.args), c.expected)
+	}
+}
+
+func TestEnableSuggestions(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "unknown subcommand",
+			args:     []string{"project", "clne"},
+			expected: "unknown command \"clne\" for \"space project\"\n\nDid you mean this?\n\tspace project clone\n",
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"push", "--tga", "v1"},
+			expected: "unknown flag: --tga\n\nDid you mean this?\n\t--tag\n",
+		},
+		{
+			name:     "unknown inherited flag",
+			args:     []string{"push", "--no-cahce"},
+			expected: "unknown flag: --no-cahce\n\nDid you mean this?\n\t--no-cache\n",
+		},
+	}
+
+	for _, c := range cases {
+		root := testCommand()
+		EnableSuggestions(root)
+		root.SetArgs(c.args)
+		root.SilenceErrors, root.SilenceUsage = true, true
+		err := root.Execute()
+		assert.Error(t, err, c.expected, c.name)
+	}
+
+	// flag errors without suggestions are kept
+	root := testCommand()
+	assert.Assert(t, SuggestFlags(root, errors.New("unknown shorthand flag: 'x' in -x")) == nil)
+}
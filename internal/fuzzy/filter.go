@@ -0,0 +1,68 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// scoreWordStart is added for a matched rune at the start of a word, e.g. the r of release in space release
+	scoreWordStart = 10
+	// scoreConsecutive is added for a matched rune right after the previous one
+	scoreConsecutive = 5
+)
+
+// Score matches the runes of the query in order against the candidate, like the filter of a command palette: rn
+// matches release notes. Case and spaces of the query are ignored. A higher score is a better match, ok is false if
+// the candidate doesn't contain the runes of the query.
+func Score(query string, candidate string) (score int, ok bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	c := []rune(strings.ToLower(candidate))
+
+	last := -2
+	qi := 0
+	for ci := 0; ci < len(c) && qi < len(q); ci++ {
+		if c[ci] != q[qi] {
+			continue
+		}
+		score++
+		if ci == 0 || !unicode.IsLetter(c[ci-1]) && !unicode.IsDigit(c[ci-1]) {
+			score += scoreWordStart
+		}
+		if ci == last+1 {
+			score += scoreConsecutive
+		}
+		last = ci
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
+
+// Filter returns the indices of the candidates which match the query, the best match first. Candidates which match
+// equally well keep their order, an empty query matches all candidates.
+func Filter(query string, candidates []string) []int {
+	type match struct {
+		index int
+		score int
+	}
+
+	var matches []match
+	for i, c := range candidates {
+		if score, ok := Score(query, c); ok {
+			matches = append(matches, match{index: i, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]int, len(matches))
+	for i, m := range matches {
+		result[i] = m.index
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestScore(t *testing.T) {
+	cases := []struct {
+		query     string
+		candidate string
+		ok        bool
+	}{
+		{query: "rn", candidate: "release notes", ok: true},
+		{query: "Push", candidate: "push", ok: true},
+		{query: "env set", candidate: "env set", ok: true},
+		{query: "np", candidate: "push new", ok: false},
+		{query: "", candidate: "push", ok: true},
+	}
+
+	for _, c := range cases {
+		_, ok := Score(c.query, c.candidate)
+		assert.Equal(t, ok, c.ok, "%s %s", c.query, c.candidate)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	candidates := []string{"preview", "push", "release notes", "project clone", "revisions"}
+	cases := []struct {
+		query    string
+		expected []int
+	}{
+		{query: "", expected: []int{0, 1, 2, 3, 4}},
+		{query: "pu", expected: []int{1}},
+		// word starts beat runes in the middle of a word
+		{query: "rn", expected: []int{2, 4, 3}},
+		{query: "pc", expected: []int{3}},
+		{query: "xyz", expected: []int{}},
+	}
+
+	for _, c := range cases {
+		assert.DeepEqual(t, Filter(c.query, candidates), c.expected)
+	}
+}
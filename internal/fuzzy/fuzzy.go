@@ -0,0 +1,109 @@
+// Package fuzzy matches mistyped words against the known ones, for the did you mean suggestions of commands, flags
+// and Spacefile fields
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMatches is the number of matches returned by Matches
+const maxMatches = 3
+
+// Distance is the number of edits between two words, an edit inserts, deletes or replaces a character or swaps two
+// adjacent characters. Case is ignored.
+func Distance(a string, b string) int {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	// d[i][j] is the distance between the first i runes of a and the first j runes of b
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// maxDistance is the largest distance of a typo, longer words may have more typos. Short words allow a single typo,
+// otherwise every short command would be suggested for every other one.
+func maxDistance(word string) int {
+	return utf8.RuneCountInString(word)/4 + 1
+}
+
+// Closest returns the candidate closest to the word, or an empty string if none is close enough to be a typo
+func Closest(word string, candidates []string) string {
+	best, bestDistance := "", maxDistance(word)+1
+	for _, c := range candidates {
+		if d := Distance(word, c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// Matches returns up to three candidates which the word could be a typo or an abbreviation of, the closest first
+func Matches(word string, candidates []string) []string {
+	type match struct {
+		candidate string
+		distance  int
+	}
+
+	var matches []match
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if seen[c] || c == "" {
+			continue
+		}
+		seen[c] = true
+
+		d := Distance(word, c)
+		// an abbreviation like rel for release is as good as a typo of a single character
+		if utf8.RuneCountInString(word) >= 2 && strings.HasPrefix(strings.ToLower(c), strings.ToLower(word)) && d > 1 {
+			d = 1
+		}
+		if d <= maxDistance(word) {
+			matches = append(matches, match{candidate: c, distance: d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+	if len(matches) > maxMatches {
+		matches = matches[:maxMatches]
+	}
+
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.candidate
+	}
+	return result
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,46 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "push", b: "push", expected: 0},
+		{a: "pussh", b: "push", expected: 1},
+		{a: "relaese", b: "release", expected: 1},
+		{a: "Release", b: "release", expected: 0},
+		{a: "clne", b: "clone", expected: 1},
+		{a: "new", b: "dev", expected: 2},
+		{a: "", b: "dev", expected: 3},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, Distance(c.a, c.b), c.expected, "%s %s", c.a, c.b)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	candidates := []string{"push", "release", "new", "dev", "preview", "project", "profile", "regions"}
+	cases := []struct {
+		word     string
+		expected []string
+	}{
+		{word: "pussh", expected: []string{"push"}},
+		{word: "releases", expected: []string{"release"}},
+		{word: "rel", expected: []string{"release"}},
+		{word: "pr", expected: []string{"preview", "profile", "project"}},
+		{word: "ls", expected: []string{}},
+		{word: "previw", expected: []string{"preview"}},
+	}
+
+	for _, c := range cases {
+		assert.DeepEqual(t, Matches(c.word, candidates), c.expected)
+	}
+}
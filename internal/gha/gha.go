@@ -0,0 +1,135 @@
+// Package gha writes GitHub Actions workflow commands, e.g. annotations and log groups
+package gha
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Env is set to true by GitHub Actions
+const Env = "GITHUB_ACTIONS"
+
+var (
+	mu       sync.Mutex
+	override *bool
+
+	// Output is where workflow commands are written to, GitHub Actions only reads them from stdout
+	Output io.Writer = os.Stdout
+)
+
+// SetEnabled overrides the detection of GitHub Actions
+func SetEnabled(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	override = &enabled
+}
+
+// Enabled reports if workflow commands should be written
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if override != nil {
+		return *override
+	}
+	return os.Getenv(Env) == "true"
+}
+
+// Annotation is shown by GitHub on the workflow run and next to the file in pull requests
+type Annotation struct {
+	File string
+	// Line is not set if it is 0
+	Line    int
+	Title   string
+	Message string
+}
+
+// String formats the annotation as an error workflow command
+func (a *Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(filepath.ToSlash(a.File)))
+	}
+	if a.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", a.Line))
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+
+	cmd := "::error"
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return fmt.Sprintf("%s::%s", cmd, escapeData(a.Message))
+}
+
+// Error writes an error annotation if enabled
+func Error(a *Annotation) {
+	if !Enabled() {
+		return
+	}
+	fmt.Fprintln(Output, a.String())
+}
+
+// Group starts a collapsible group of log lines if enabled, the returned func ends the group
+func Group(name string) func() {
+	if !Enabled() {
+		return func() {}
+	}
+	fmt.Fprintf(Output, "::group::%s\n", escapeData(name))
+	return func() {
+		fmt.Fprintln(Output, "::endgroup::")
+	}
+}
+
+// Mask hides the value in the logs of the workflow run if enabled
+func Mask(value string) {
+	if !Enabled() || value == "" {
+		return
+	}
+	fmt.Fprintf(Output, "::add-mask::%s\n", escapeData(value))
+}
+
+// WorkspacePath returns path relative to the checked out repository so that annotations point to the right file
+func WorkspacePath(path string) string {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		return filepath.Clean(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	rel, err := filepath.Rel(workspace, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Clean(path)
+	}
+	return rel
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// SetOutput sets an output of the current step which can be used by later steps of the workflow
+func SetOutput(name string, value string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if !Enabled() || path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s=%s\n", name, value)
+	return err
+}
@@ -0,0 +1,68 @@
+package gha
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestAnnotationString(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation Annotation
+		expected   string
+	}{
+		{
+			name:       "message only",
+			annotation: Annotation{Message: "build failed"},
+			expected:   "::error::build failed",
+		},
+		{
+			name:       "file and line",
+			annotation: Annotation{File: "Spacefile", Line: 12, Message: "unknown field"},
+			expected:   "::error file=Spacefile,line=12::unknown field",
+		},
+		{
+			name:       "escaped",
+			annotation: Annotation{File: "a,b:c", Title: "100%", Message: "L one\nL two"},
+			expected:   "::error file=a%2Cb%3Ac,title=100%25::L one%0AL two",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.annotation.String(), c.expected)
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var buf bytes.Buffer
+	Output = &buf
+
+	SetEnabled(false)
+	Group("Build logs")()
+	Error(&Annotation{Message: "failed"})
+	assert.Equal(t, buf.String(), "")
+
+	SetEnabled(true)
+	end := Group("Build logs")
+	Error(&Annotation{Message: "failed"})
+	end()
+	assert.Equal(t, buf.String(), "::group::Build logs\n::error::failed\n::endgroup::\n")
+}
+
+func TestMask(t *testing.T) {
+	var buf bytes.Buffer
+	Output = &buf
+
+	SetEnabled(false)
+	Mask("npm_s3cr3t")
+	assert.Equal(t, buf.String(), "")
+
+	SetEnabled(true)
+	Mask("npm_s3cr3t")
+	Mask("")
+	assert.Equal(t, buf.String(), "::add-mask::npm_s3cr3t\n")
+}
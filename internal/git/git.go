@@ -0,0 +1,141 @@
+// Package git runs git commands against the repository of a project
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNotRepository is returned if the directory is not inside a git repository
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrDetachedHead is returned if no branch is checked out and none is set by the CI
+	ErrDetachedHead = errors.New("no branch is checked out")
+)
+
+// ciBranchEnvs hold the branch in CI checkouts, which usually have a detached HEAD
+var ciBranchEnvs = []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "BRANCH_NAME"}
+
+// run runs git in dir and returns its trimmed output
+func run(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "not a git repository") {
+			return "", ErrNotRepository
+		}
+		if msg == "" {
+			return "", err
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// CurrentBranch returns the branch checked out in dir, the branch set by the CI is preferred
+func CurrentBranch(dir string) (string, error) {
+	for _, env := range ciBranchEnvs {
+		if branch := os.Getenv(env); branch != "" {
+			return branch, nil
+		}
+	}
+
+	// symbolic-ref fails without a message if HEAD is detached
+	branch, err := run(dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", ErrDetachedHead
+	}
+	return branch, err
+}
+
+// Commit is a commit in the log of a repository
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// Log returns the commits reachable from HEAD but not from since, newest first, all commits if since is empty
+func Log(dir string, since string) ([]*Commit, error) {
+	args := []string{"log", "--format=%H%x1f%s%x1f%b%x1e"}
+	if since != "" {
+		args = append(args, since+"..HEAD")
+	}
+	out, err := run(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*Commit
+	for _, entry := range strings.Split(out, "\x1e") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "\x1f", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		commits = append(commits, &Commit{Hash: parts[0], Subject: parts[1], Body: strings.TrimSpace(parts[2])})
+	}
+	return commits, nil
+}
+
+// Tags returns the tags reachable from HEAD
+func Tags(dir string) ([]string, error) {
+	out, err := run(dir, "tag", "--merged", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// User returns the name of the git user of dir, e.g. Jane Doe, without the email
+func User(dir string) (string, error) {
+	name, err := run(dir, "config", "user.name")
+	if err != nil || name == "" {
+		return "", errors.New("no git user configured")
+	}
+	return name, nil
+}
+
+// Head returns the hash of the commit checked out in dir
+func Head(dir string) (string, error) {
+	return run(dir, "rev-parse", "HEAD")
+}
+
+// CreateTag tags HEAD
+func CreateTag(dir string, name string) error {
+	_, err := run(dir, "tag", name)
+	return err
+}
+
+// ChangedFiles returns the files in dir which changed since ref, including uncommitted and untracked files,
+// the paths are slash separated and relative to dir
+func ChangedFiles(dir string, ref string) ([]string, error) {
+	diff, err := run(dir, "diff", "--name-only", "--relative", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := run(dir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, out := range []string{diff, untracked} {
+		for _, file := range strings.Split(out, "\n") {
+			if file != "" {
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
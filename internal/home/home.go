@@ -0,0 +1,98 @@
+package home
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+const (
+	// HomeEnv overrides the directory of the global state
+	HomeEnv = "SPACE_HOME"
+	// NoStateEnv disables writing the global state if set to a non empty value, same as --no-state
+	NoStateEnv = "SPACE_NO_STATE"
+
+	// defaultDir is the directory of the global state relative to the home directory of the user
+	defaultDir = ".detaspace"
+
+	dirPermMode = 0760
+)
+
+var (
+	// ErrNoState writing the global state is disabled
+	ErrNoState = errors.New("writing the global state is disabled with --no-state or " + NoStateEnv)
+	// ErrReadOnly the directory of the global state is not writable
+	ErrReadOnly = errors.New("the directory of the global state is not writable, set " + HomeEnv + " to a writable directory or use --no-state")
+)
+
+var (
+	mu      sync.Mutex
+	noState bool
+)
+
+// SetNoState disables or enables writing the global state
+func SetNoState(disabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	noState = disabled
+}
+
+// NoState reports if writing the global state is disabled, the global state can still be read
+func NoState() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return noState || os.Getenv(NoStateEnv) != ""
+}
+
+// Dir returns the directory of the global state, $SPACE_HOME or ~/.detaspace
+func Dir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory, set %s instead: %w", HomeEnv, err)
+	}
+	return filepath.Join(home, defaultDir), nil
+}
+
+// Path returns the path of a file in the directory of the global state
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// PrepareWrite returns the path of a file in the directory of the global state, making sure the directory exists.
+// Returns ErrNoState if writing the global state is disabled and ErrReadOnly if the directory is not writable
+func PrepareWrite(name string) (string, error) {
+	if NoState() {
+		return "", ErrNoState
+	}
+
+	path, err := Path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirPermMode); err != nil {
+		return "", WrapWriteError(err)
+	}
+	return path, nil
+}
+
+// WrapWriteError wraps errors caused by a read-only file system or missing permissions with ErrReadOnly
+func WrapWriteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, syscall.EROFS) || errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("%w: %s", ErrReadOnly, err)
+	}
+	return err
+}
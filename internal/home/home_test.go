@@ -0,0 +1,58 @@
+package home
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDir(t *testing.T) {
+	userHome := t.TempDir()
+	t.Setenv("HOME", userHome)
+	t.Setenv(HomeEnv, "")
+
+	dir, err := Dir()
+	assert.NilError(t, err)
+	assert.Equal(t, dir, filepath.Join(userHome, defaultDir))
+
+	spaceHome := t.TempDir()
+	t.Setenv(HomeEnv, spaceHome)
+
+	path, err := Path("space_tokens")
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(spaceHome, "space_tokens"))
+}
+
+func TestPrepareWrite(t *testing.T) {
+	spaceHome := filepath.Join(t.TempDir(), "state")
+	t.Setenv(HomeEnv, spaceHome)
+	t.Setenv(NoStateEnv, "")
+
+	path, err := PrepareWrite("space_tokens")
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(spaceHome, "space_tokens"))
+	_, err = os.Stat(spaceHome)
+	assert.NilError(t, err)
+
+	SetNoState(true)
+	t.Cleanup(func() { SetNoState(false) })
+	_, err = PrepareWrite("space_tokens")
+	assert.Assert(t, errors.Is(err, ErrNoState))
+}
+
+func TestWrapWriteError(t *testing.T) {
+	err := WrapWriteError(&os.PathError{Op: "open", Path: "/home/space", Err: syscall.EROFS})
+	assert.Assert(t, errors.Is(err, ErrReadOnly))
+
+	err = WrapWriteError(&os.PathError{Op: "open", Path: "/home/space", Err: syscall.EACCES})
+	assert.Assert(t, errors.Is(err, ErrReadOnly))
+
+	err = WrapWriteError(os.ErrNotExist)
+	assert.Assert(t, !errors.Is(err, ErrReadOnly))
+
+	assert.NilError(t, WrapWriteError(nil))
+}
@@ -0,0 +1,139 @@
+// Package i18n translates the messages of the cli. The catalogs are json files in the locales directory, one per
+// language, mapping the message keys to fmt formats. Keys missing from a catalog fall back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deta/space/internal/config"
+)
+
+const (
+	// LanguageEnv overrides the language of the config file and of the locale
+	LanguageEnv = "SPACE_LANG"
+	// DefaultLanguage is used if no catalog exists for the language of the user
+	DefaultLanguage = "en"
+)
+
+// localeEnvs hold the locale of the user in the order of precedence of gettext
+var localeEnvs = []string{"LC_ALL", "LC_MESSAGES", "LANG"}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	mu       sync.Mutex
+	language string
+	catalogs = map[string]map[string]string{}
+)
+
+// Languages returns the languages which have a catalog
+func Languages() []string {
+	entries, _ := locales.ReadDir("locales")
+	languages := make([]string, 0, len(entries))
+	for _, e := range entries {
+		languages = append(languages, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// SetLanguage sets the language of the messages, e.g. de or de_DE.UTF-8, an unknown language falls back to English
+func SetLanguage(lang string) {
+	mu.Lock()
+	defer mu.Unlock()
+	language = normalize(lang)
+}
+
+// Language returns the language of the messages, it is detected from SPACE_LANG, the config file and the locale
+// if it wasn't set
+func Language() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if language == "" {
+		language = detect()
+	}
+	return language
+}
+
+// T returns the message of the key in the language of the user, formatted with args
+func T(key string, args ...any) string {
+	format, ok := lookup(Language(), key)
+	if !ok {
+		format, ok = lookup(DefaultLanguage, key)
+	}
+	if !ok {
+		// a missing message is a bug, the key is at least more helpful than an empty message
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func detect() string {
+	if lang := os.Getenv(LanguageEnv); lang != "" {
+		return normalize(lang)
+	}
+	if c, err := config.Load(); err == nil && c.Language != "" {
+		return normalize(c.Language)
+	}
+	for _, env := range localeEnvs {
+		if lang := os.Getenv(env); lang != "" {
+			return normalize(lang)
+		}
+	}
+	return DefaultLanguage
+}
+
+// normalize turns a locale like de_DE.UTF-8 into the language of a catalog, C and POSIX are English
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "_-.@"); i != -1 {
+		lang = lang[:i]
+	}
+	if _, err := locales.Open(catalogPath(lang)); lang == "" || err != nil {
+		return DefaultLanguage
+	}
+	return lang
+}
+
+func catalogPath(lang string) string {
+	return path.Join("locales", lang+".json")
+}
+
+func lookup(lang string, key string) (string, bool) {
+	catalog, err := loadCatalog(lang)
+	if err != nil {
+		return "", false
+	}
+	message, ok := catalog[key]
+	return message, ok
+}
+
+func loadCatalog(lang string) (map[string]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if catalog, ok := catalogs[lang]; ok {
+		return catalog, nil
+	}
+
+	content, err := locales.ReadFile(catalogPath(lang))
+	if err != nil {
+		return nil, err
+	}
+	var catalog map[string]string
+	if err := json.Unmarshal(content, &catalog); err != nil {
+		return nil, fmt.Errorf("invalid catalog %s: %w", lang, err)
+	}
+	catalogs[lang] = catalog
+	return catalog, nil
+}
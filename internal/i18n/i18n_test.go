@@ -0,0 +1,54 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+var verbReg = regexp.MustCompile(`%[a-z]`)
+
+func TestCatalogs(t *testing.T) {
+	english, err := loadCatalog(DefaultLanguage)
+	assert.NilError(t, err)
+
+	for _, lang := range Languages() {
+		catalog, err := loadCatalog(lang)
+		assert.NilError(t, err, lang)
+		for key, message := range catalog {
+			source, ok := english[key]
+			assert.Assert(t, ok, "%s: %s is not an English message", lang, key)
+			assert.DeepEqual(t, verbReg.FindAllString(message, -1), verbReg.FindAllString(source, -1))
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		lang     string
+		expected string
+	}{
+		{lang: "de", expected: "de"},
+		{lang: "de_DE.UTF-8", expected: "de"},
+		{lang: "DE-at", expected: "de"},
+		{lang: "C", expected: DefaultLanguage},
+		{lang: "xx_XX", expected: DefaultLanguage},
+		{lang: "", expected: DefaultLanguage},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, normalize(c.lang), c.expected, c.lang)
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	SetLanguage("de_DE.UTF-8")
+	assert.Equal(t, T("components.chars", 3), "3 Zeichen")
+	assert.Equal(t, T("unknown.key"), "unknown.key")
+
+	SetLanguage("fr")
+	assert.Equal(t, T("components.chars", 3), "3 chars")
+}
@@ -0,0 +1,104 @@
+// Package labels parses the labels of revisions, key value pairs attached at push time to trace a revision back to
+// its commit, its CI run and who pushed it
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxLabels is the maximum number of labels of a revision
+	MaxLabels = 32
+	// GitSHA is the commit checked out when the revision was pushed
+	GitSHA = "git_sha"
+	// CIRunURL is the url of the CI run which pushed the revision
+	CIRunURL = "ci_run_url"
+	// PushedBy is who pushed the revision, the actor of the CI or the git user
+	PushedBy = "pushed_by"
+
+	maxKeyLength   = 63
+	maxValueLength = 256
+)
+
+var keyReg = regexp.MustCompile(`^[a-z0-9]([a-z0-9_.-]*[a-z0-9])?$`)
+
+// Parse parses labels like ci_run=123, a later label overrides an earlier one with the same key. A label without a
+// value, e.g. git_sha=, removes the label.
+func Parse(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q, use key=value", pair)
+		}
+		if len(key) > maxKeyLength || !keyReg.MatchString(key) {
+			return nil, fmt.Errorf("invalid label key %q, use up to %d lowercase letters, digits, _, - and .", key, maxKeyLength)
+		}
+		if len(value) > maxValueLength {
+			return nil, fmt.Errorf("the value of the label %s is longer than %d characters", key, maxValueLength)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// Defaults returns the labels detected from the environment of the push: the commit, the CI run and who pushed
+func Defaults(getenv func(string) string, gitSHA string, pushedBy string) map[string]string {
+	labels := make(map[string]string)
+	if gitSHA != "" {
+		labels[GitSHA] = gitSHA
+	}
+	if url := ciRunURL(getenv); url != "" {
+		labels[CIRunURL] = url
+	}
+	if pushedBy != "" {
+		labels[PushedBy] = pushedBy
+	}
+	return labels
+}
+
+func ciRunURL(getenv func(string) string) string {
+	if server, repo, run := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"), getenv("GITHUB_RUN_ID"); server != "" && repo != "" && run != "" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, run)
+	}
+	// gitlab, circleci and jenkins
+	for _, env := range []string{"CI_JOB_URL", "CIRCLE_BUILD_URL", "BUILD_URL"} {
+		if url := getenv(env); url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+// Merge returns the defaults overridden by the labels, labels with an empty value are removed. It fails if there are
+// more than MaxLabels labels.
+func Merge(defaults map[string]string, labels map[string]string) (map[string]string, error) {
+	merged := make(map[string]string, len(defaults)+len(labels))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range labels {
+		if value == "" {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	if len(merged) > MaxLabels {
+		return nil, fmt.Errorf("a revision can have at most %d labels", MaxLabels)
+	}
+	return merged, nil
+}
+
+// Keys returns the keys of the labels sorted
+func Keys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
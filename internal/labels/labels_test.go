@@ -0,0 +1,69 @@
+package labels
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		pairs    []string
+		expected map[string]string
+		err      bool
+	}{
+		{pairs: []string{"ci_run=123", "builder=gha"}, expected: map[string]string{"ci_run": "123", "builder": "gha"}},
+		{pairs: []string{"url=https://example.com/?a=b"}, expected: map[string]string{"url": "https://example.com/?a=b"}},
+		{pairs: []string{"ci_run=1", "ci_run=2"}, expected: map[string]string{"ci_run": "2"}},
+		{pairs: []string{"git_sha="}, expected: map[string]string{"git_sha": ""}},
+		{pairs: []string{"ci_run"}, err: true},
+		{pairs: []string{"CI_RUN=1"}, err: true},
+		{pairs: []string{"_run=1"}, err: true},
+		{pairs: []string{"=1"}, err: true},
+	}
+
+	for _, c := range cases {
+		labels, err := Parse(c.pairs)
+		if c.err {
+			assert.Assert(t, err != nil, c.pairs)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.DeepEqual(t, labels, c.expected)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_REPOSITORY": "deta/space-cli",
+		"GITHUB_RUN_ID":     "42",
+	}
+	labels := Defaults(func(key string) string { return env[key] }, "abc123", "octocat")
+	assert.DeepEqual(t, labels, map[string]string{
+		GitSHA:   "abc123",
+		CIRunURL: "https://github.com/deta/space-cli/actions/runs/42",
+		PushedBy: "octocat",
+	})
+
+	env = map[string]string{"CI_JOB_URL": "https://gitlab.com/deta/space/-/jobs/7"}
+	labels = Defaults(func(key string) string { return env[key] }, "", "")
+	assert.DeepEqual(t, labels, map[string]string{CIRunURL: "https://gitlab.com/deta/space/-/jobs/7"})
+}
+
+func TestMerge(t *testing.T) {
+	merged, err := Merge(
+		map[string]string{GitSHA: "abc123", PushedBy: "octocat"},
+		map[string]string{PushedBy: "", "ci_run": "123"},
+	)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, merged, map[string]string{GitSHA: "abc123", "ci_run": "123"})
+	assert.DeepEqual(t, Keys(merged), []string{"ci_run", GitSHA})
+
+	many := make(map[string]string)
+	for i := 0; i <= MaxLabels; i++ {
+		many[string(rune('a'+i%26))+string(rune('a'+i/26))] = "x"
+	}
+	_, err = Merge(nil, many)
+	assert.Assert(t, err != nil)
+}
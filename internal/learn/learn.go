@@ -0,0 +1,254 @@
+package learn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deta/space/internal/runtime"
+)
+
+const (
+	// DevPort is the port space dev is run on in the tutorial
+	DevPort = 4200
+
+	// ProgressFile keeps the steps done in the sandbox, so that space learn continues where it was left
+	ProgressFile = ".space/learn.json"
+
+	spacefile = `# Spacefile Docs: https://go.deta.dev/docs/spacefile/v0
+v: 0
+micros:
+  - name: hello
+    src: ./
+    engine: static
+    serve: ./
+`
+
+	indexHTML = `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>Hello Space</title>
+  </head>
+  <body>
+    <h1>Hello Space</h1>
+    <p>Edit index.html, space dev shows the change right away.</p>
+  </body>
+</html>
+`
+)
+
+// Platform answers the checks of the steps which need the api
+type Platform interface {
+	// Revisions returns the number of revisions of the project
+	Revisions(projectID string) (int, error)
+	// Releases returns the number of releases of the project
+	Releases(projectID string) (int, error)
+}
+
+// Sandbox is the project the tutorial runs in
+type Sandbox struct {
+	Dir      string
+	Platform Platform
+	// DevURL is where space dev serves the sandbox
+	DevURL string
+}
+
+// Step is a step of the tutorial
+type Step struct {
+	ID      string
+	Title   string
+	Explain string
+	// Command is the space command of the step, without "space"
+	Command []string
+	// Background steps keep running, like space dev, the user runs them in another terminal
+	Background bool
+	Hint       string
+	// Check returns why the step isn't done yet
+	Check func(s *Sandbox) error
+}
+
+// Steps of the tutorial in the order they are taken
+var Steps = []*Step{
+	{
+		ID:    "new",
+		Title: "Create a project",
+		Explain: `Every app on Space is a project. space new creates the project in your Space and links the directory to it,
+the Spacefile describes the micros of the app. The sandbox has a Spacefile with a single static micro already.`,
+		Command: []string{"new", "--name", "learn-space"},
+		Hint:    "Run space new in the sandbox directory, it stores the id of the project in .space/meta. Log in with space login first if you haven't.",
+		Check:   checkNew,
+	},
+	{
+		ID:    "dev",
+		Title: "Run the app locally",
+		Explain: `space dev runs the micros of the Spacefile on your machine, static micros are served as they are.
+It keeps running, so start it in another terminal and open the url it prints.`,
+		Command:    []string{"dev", "--port", fmt.Sprint(DevPort)},
+		Background: true,
+		Hint:       "Open another terminal, change into the sandbox directory and run space dev --port 4200. Keep it running while this step is checked.",
+		Check:      checkDev,
+	},
+	{
+		ID:    "push",
+		Title: "Push a revision",
+		Explain: `space push uploads the code and builds a revision of the app in your Space, the revision is installed
+for you only, so you can try it before releasing it.`,
+		Command: []string{"push"},
+		Hint:    "Run space push in the sandbox directory and wait for the build to finish, space push --skip-logs returns before that.",
+		Check:   checkPush,
+	},
+	{
+		ID:    "release",
+		Title: "Release the app",
+		Explain: `space release publishes the latest revision, an unlisted release can be installed by everyone with its link
+and a listed release also shows up in Discovery.`,
+		Command: []string{"release", "--yes"},
+		Hint:    "Run space release --yes in the sandbox directory, it releases the revision of the previous step.",
+		Check:   checkRelease,
+	},
+}
+
+// StepByID returns nil if there is no step with the id
+func StepByID(id string) *Step {
+	for _, step := range Steps {
+		if step.ID == id {
+			return step
+		}
+	}
+	return nil
+}
+
+// WriteSample writes the app of the tutorial into dir, existing files are kept
+func WriteSample(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	files := map[string]string{"Spacefile": spacefile, "index.html": indexHTML}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkNew(s *Sandbox) error {
+	if _, err := os.Stat(filepath.Join(s.Dir, "Spacefile")); err != nil {
+		return errors.New("the sandbox has no Spacefile")
+	}
+	if _, err := runtime.GetProjectID(s.Dir); err != nil {
+		return errors.New("the sandbox isn't linked to a project yet")
+	}
+	return nil
+}
+
+func checkDev(s *Sandbox) error {
+	client := http.Client{Timeout: 2 * time.Second}
+	res, err := client.Get(s.DevURL)
+	if err != nil {
+		return fmt.Errorf("space dev doesn't answer on %s", s.DevURL)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("space dev answered with %s", res.Status)
+	}
+	return nil
+}
+
+func checkPush(s *Sandbox) error {
+	projectID, err := runtime.GetProjectID(s.Dir)
+	if err != nil {
+		return errors.New("the sandbox isn't linked to a project yet")
+	}
+	n, err := s.Platform.Revisions(projectID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("the project has no revision yet")
+	}
+	return nil
+}
+
+func checkRelease(s *Sandbox) error {
+	projectID, err := runtime.GetProjectID(s.Dir)
+	if err != nil {
+		return errors.New("the sandbox isn't linked to a project yet")
+	}
+	n, err := s.Platform.Releases(projectID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("the project has no release yet")
+	}
+	return nil
+}
+
+// Progress are the steps done in a sandbox
+type Progress struct {
+	Done []string `json:"done"`
+}
+
+// LoadProgress returns no steps done if the sandbox has no progress yet
+func LoadProgress(dir string) (*Progress, error) {
+	var p Progress
+	b, err := os.ReadFile(filepath.Join(dir, ProgressFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("failed to read the progress of the tutorial: %w", err)
+	}
+	return &p, nil
+}
+
+// Save writes the progress into the sandbox
+func (p *Progress) Save(dir string) error {
+	path := filepath.Join(dir, ProgressFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0760); err != nil {
+		return err
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0660)
+}
+
+func (p *Progress) IsDone(id string) bool {
+	for _, done := range p.Done {
+		if done == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Progress) MarkDone(id string) {
+	if !p.IsDone(id) {
+		p.Done = append(p.Done, id)
+	}
+}
+
+// Next returns the first step which isn't done, nil once all steps are
+func (p *Progress) Next() *Step {
+	for _, step := range Steps {
+		if !p.IsDone(step.ID) {
+			return step
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package learn
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deta/space/internal/home"
+	"github.com/deta/space/internal/runtime"
+	"gotest.tools/v3/assert"
+)
+
+type fakePlatform struct {
+	revisions int
+	releases  int
+	err       error
+}
+
+func (p *fakePlatform) Revisions(projectID string) (int, error) {
+	return p.revisions, p.err
+}
+
+func (p *fakePlatform) Releases(projectID string) (int, error) {
+	return p.releases, p.err
+}
+
+func TestProgress(t *testing.T) {
+	dir := t.TempDir()
+
+	p, err := LoadProgress(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, p.Next().ID, "new")
+
+	p.MarkDone("new")
+	p.MarkDone("new")
+	p.MarkDone("push")
+	assert.NilError(t, p.Save(dir))
+
+	p, err = LoadProgress(dir)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, p.Done, []string{"new", "push"})
+	assert.Equal(t, p.Next().ID, "dev")
+
+	for _, step := range Steps {
+		p.MarkDone(step.ID)
+	}
+	assert.Assert(t, p.Next() == nil)
+}
+
+func TestWriteSample(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("mine"), 0644))
+
+	assert.NilError(t, WriteSample(dir))
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(index), "mine")
+	_, err = os.Stat(filepath.Join(dir, "Spacefile"))
+	assert.NilError(t, err)
+}
+
+func TestChecks(t *testing.T) {
+	t.Setenv(home.HomeEnv, t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	unlinked := t.TempDir()
+	assert.NilError(t, WriteSample(unlinked))
+	linked := t.TempDir()
+	assert.NilError(t, WriteSample(linked))
+	assert.NilError(t, runtime.StoreProjectMeta(linked, &runtime.ProjectMeta{ID: "a1b2c3", Name: "learn-space"}))
+
+	cases := []struct {
+		step     string
+		sandbox  *Sandbox
+		expected string
+	}{
+		{step: "new", sandbox: &Sandbox{Dir: unlinked}, expected: "isn't linked"},
+		{step: "new", sandbox: &Sandbox{Dir: linked}},
+		{step: "dev", sandbox: &Sandbox{DevURL: server.URL}},
+		{step: "dev", sandbox: &Sandbox{DevURL: "http://127.0.0.1:1"}, expected: "doesn't answer"},
+		{step: "push", sandbox: &Sandbox{Dir: linked, Platform: &fakePlatform{}}, expected: "no revision"},
+		{step: "push", sandbox: &Sandbox{Dir: linked, Platform: &fakePlatform{revisions: 1}}},
+		{step: "release", sandbox: &Sandbox{Dir: linked, Platform: &fakePlatform{revisions: 1}}, expected: "no release"},
+		{step: "release", sandbox: &Sandbox{Dir: linked, Platform: &fakePlatform{err: errors.New("offline")}}, expected: "offline"},
+		{step: "release", sandbox: &Sandbox{Dir: linked, Platform: &fakePlatform{releases: 1}}},
+	}
+
+	for _, c := range cases {
+		err := StepByID(c.step).Check(c.sandbox)
+		if c.expected == "" {
+			assert.NilError(t, err, c.step)
+		} else {
+			assert.ErrorContains(t, err, c.expected, c.step)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package lockfile checks that the lockfiles of a micro are in sync with its manifest before a push, an outdated
+// lockfile only fails late in the remote build with an error that rarely points at the lockfile
+package lockfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Problem is a lockfile which is out of sync with its manifest
+type Problem struct {
+	// Manifest is the file declaring the dependencies, e.g. package.json
+	Manifest string
+	// Lockfile is the out of sync lockfile, e.g. package-lock.json
+	Lockfile string
+	// Dependencies are declared in the manifest but missing in the lockfile or locked with another version range
+	Dependencies []string
+	// Fix is the command which updates the lockfile
+	Fix string
+}
+
+func (p *Problem) Error() string {
+	return fmt.Sprintf("%s is out of sync with %s: %s", p.Lockfile, p.Manifest, strings.Join(p.Dependencies, ", "))
+}
+
+// checker checks a lockfile of a package manager against the manifest, both are read from dir
+type checker struct {
+	manifest string
+	lockfile string
+	fix      string
+	// outdated returns the dependencies of the manifest which are out of sync with the lockfile
+	outdated func(manifest []byte, lockfile []byte) ([]string, error)
+}
+
+var checkers = []checker{
+	{manifest: "package.json", lockfile: "package-lock.json", fix: "npm install", outdated: npmOutdated},
+	{manifest: "package.json", lockfile: "yarn.lock", fix: "yarn install", outdated: yarnOutdated},
+	{manifest: "package.json", lockfile: "pnpm-lock.yaml", fix: "pnpm install", outdated: pnpmOutdated},
+	{manifest: "pyproject.toml", lockfile: "poetry.lock", fix: "poetry lock --no-update", outdated: poetryOutdated},
+	{manifest: "pyproject.toml", lockfile: "uv.lock", fix: "uv lock", outdated: uvOutdated},
+	{manifest: "requirements.in", lockfile: "requirements.txt", fix: "pip-compile requirements.in", outdated: pipToolsOutdated},
+}
+
+// Check checks the lockfiles in dir, dirs without a manifest or a lockfile have nothing to check
+func Check(dir string) ([]*Problem, error) {
+	var problems []*Problem
+	for _, c := range checkers {
+		manifest, err := os.ReadFile(filepath.Join(dir, c.manifest))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		lockfile, err := os.ReadFile(filepath.Join(dir, c.lockfile))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		deps, err := c.outdated(manifest, lockfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", filepath.Join(dir, c.lockfile), err)
+		}
+		if len(deps) > 0 {
+			sort.Strings(deps)
+			problems = append(problems, &Problem{Manifest: c.manifest, Lockfile: c.lockfile, Dependencies: deps, Fix: c.fix})
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,277 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const packageJSON = `{
+  "name": "app",
+  "dependencies": {"express": "^4.18.0", "lodash": "^4.17.21"},
+  "devDependencies": {"typescript": "^5.0.0"}
+}`
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		expected []*Problem
+	}{
+		{
+			name:  "no lockfile",
+			files: map[string]string{"package.json": packageJSON},
+		},
+		{
+			name: "npm in sync",
+			files: map[string]string{
+				"package.json":      packageJSON,
+				"package-lock.json": `{"lockfileVersion": 3, "packages": {"": {"dependencies": {"express": "^4.18.0", "lodash": "^4.17.21"}, "devDependencies": {"typescript": "^5.0.0"}}}}`,
+			},
+		},
+		{
+			name: "npm out of sync",
+			files: map[string]string{
+				"package.json":      packageJSON,
+				"package-lock.json": `{"lockfileVersion": 3, "packages": {"": {"dependencies": {"express": "^4.17.0", "left-pad": "^1.0.0"}, "devDependencies": {"typescript": "^5.0.0"}}}}`,
+			},
+			expected: []*Problem{{Manifest: "package.json", Lockfile: "package-lock.json", Dependencies: []string{"express", "left-pad", "lodash"}, Fix: "npm install"}},
+		},
+		{
+			name: "npm lockfile version 1",
+			files: map[string]string{
+				"package.json":      packageJSON,
+				"package-lock.json": `{"lockfileVersion": 1, "dependencies": {"express": {"version": "4.18.2"}, "typescript": {"version": "5.0.4"}}}`,
+			},
+			expected: []*Problem{{Manifest: "package.json", Lockfile: "package-lock.json", Dependencies: []string{"lodash"}, Fix: "npm install"}},
+		},
+		{
+			name: "yarn",
+			files: map[string]string{
+				"package.json": packageJSON,
+				"yarn.lock": `# yarn lockfile v1
+
+express@^4.18.0:
+  version "4.18.2"
+
+"lodash@^4.17.20", "lodash@npm:^4.17.21":
+  version "4.17.21"
+`,
+			},
+			expected: []*Problem{{Manifest: "package.json", Lockfile: "yarn.lock", Dependencies: []string{"typescript"}, Fix: "yarn install"}},
+		},
+		{
+			name: "pnpm",
+			files: map[string]string{
+				"package.json": packageJSON,
+				"pnpm-lock.yaml": `lockfileVersion: '6.0'
+importers:
+  .:
+    dependencies:
+      express:
+        specifier: ^4.18.0
+        version: 4.18.2
+      lodash:
+        specifier: ^4.17.21
+        version: 4.17.21
+    devDependencies:
+      typescript:
+        specifier: ^4.9.0
+        version: 4.9.5
+`,
+			},
+			expected: []*Problem{{Manifest: "package.json", Lockfile: "pnpm-lock.yaml", Dependencies: []string{"typescript"}, Fix: "pnpm install"}},
+		},
+		{
+			name: "pnpm lockfile version 5",
+			files: map[string]string{
+				"package.json":   packageJSON,
+				"pnpm-lock.yaml": "lockfileVersion: 5.4\nspecifiers:\n  express: ^4.18.0\n  lodash: ^4.17.21\n  typescript: ^5.0.0\n",
+			},
+		},
+		{
+			name: "poetry",
+			files: map[string]string{
+				"pyproject.toml": `[tool.poetry.dependencies]
+python = "^3.9"
+fastapi = "^0.95.0"
+Flask_Cors = { version = "^3.0" }
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^7.0"
+`,
+				"poetry.lock": `[[package]]
+name = "fastapi"
+version = "0.95.1"
+
+[[package]]
+name = "flask-cors"
+version = "3.0.10"
+`,
+			},
+			expected: []*Problem{{Manifest: "pyproject.toml", Lockfile: "poetry.lock", Dependencies: []string{"pytest"}, Fix: "poetry lock --no-update"}},
+		},
+		{
+			name: "uv",
+			files: map[string]string{
+				"pyproject.toml": `[project]
+name = "app"
+dependencies = [
+    "requests[socks]>=2.31",  # http
+    "pydantic>=2",
+]
+`,
+				"uv.lock": "version = 1\n\n[[package]]\nname = \"app\"\n\n[[package]]\nname = \"requests\"\n",
+			},
+			expected: []*Problem{{Manifest: "pyproject.toml", Lockfile: "uv.lock", Dependencies: []string{"pydantic"}, Fix: "uv lock"}},
+		},
+		{
+			name: "pip-tools",
+			files: map[string]string{
+				"requirements.in":  "-c constraints.txt\nDjango>=4.2\ngunicorn  # server\nmylib @ https://example.com/mylib.zip\n",
+				"requirements.txt": "django==4.2.1\n    # via -r requirements.in\nasgiref==3.6.0\n",
+			},
+			expected: []*Problem{{Manifest: "requirements.in", Lockfile: "requirements.txt", Dependencies: []string{"gunicorn", "mylib"}, Fix: "pip-compile requirements.in"}},
+		},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		for name, content := range c.files {
+			assert.NilError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		problems, err := Check(dir)
+		assert.NilError(t, err, c.name)
+		assert.DeepEqual(t, problems, c.expected)
+	}
+}
+
+func TestCheckInvalid(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(packageJSON), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte("<<<<<<< HEAD"), 0644))
+	_, err := Check(dir)
+	assert.ErrorContains(t, err, "invalid package-lock.json")
+}
+
+func TestPackages(t *testing.T) {
+	cases := []struct {
+		name      string
+		files     map[string]string
+		expected  []*Locked
+		lockfiles []string
+	}{
+		{
+			name: "package-lock.json",
+			files: map[string]string{
+				"package-lock.json": `{"lockfileVersion": 3, "packages": {
+  "": {"name": "app"},
+  "node_modules/@types/node": {"version": "20.1.0", "dev": true},
+  "node_modules/express": {"version": "4.18.2"},
+  "node_modules/express/node_modules/debug": {"version": "2.6.9"},
+  "node_modules/shared": {"resolved": "../shared", "link": true}
+}}`,
+			},
+			expected: []*Locked{
+				{Ecosystem: EcosystemNPM, Name: "@types/node", Version: "20.1.0", Dev: true},
+				{Ecosystem: EcosystemNPM, Name: "debug", Version: "2.6.9"},
+				{Ecosystem: EcosystemNPM, Name: "express", Version: "4.18.2"},
+			},
+			lockfiles: []string{"package-lock.json"},
+		},
+		{
+			name: "yarn.lock",
+			files: map[string]string{
+				"yarn.lock": `"@babel/core@^7.0.0", "@babel/core@^7.21.0":
+  version "7.21.4"
+
+lodash@npm:^4.17.21:
+  version: 4.17.21
+  resolution: "lodash@npm:4.17.21"
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+`,
+			},
+			expected: []*Locked{
+				{Ecosystem: EcosystemNPM, Name: "@babel/core", Version: "7.21.4"},
+				{Ecosystem: EcosystemNPM, Name: "lodash", Version: "4.17.21"},
+			},
+			lockfiles: []string{"yarn.lock"},
+		},
+		{
+			name: "pnpm-lock.yaml",
+			files: map[string]string{
+				"pnpm-lock.yaml": "lockfileVersion: '6.0'\npackages:\n  /@types/node@20.1.0:\n    dev: true\n  /react-dom@18.2.0(react@18.2.0):\n    dev: false\n  /react/18.2.0:\n    dev: false\n",
+			},
+			expected: []*Locked{
+				{Ecosystem: EcosystemNPM, Name: "@types/node", Version: "20.1.0", Dev: true},
+				{Ecosystem: EcosystemNPM, Name: "react", Version: "18.2.0"},
+				{Ecosystem: EcosystemNPM, Name: "react-dom", Version: "18.2.0"},
+			},
+			lockfiles: []string{"pnpm-lock.yaml"},
+		},
+		{
+			name: "python lockfiles",
+			files: map[string]string{
+				"uv.lock": `version = 1
+
+[[package]]
+name = "app"
+source = { editable = "." }
+
+[[package]]
+name = "Pydantic_Core"
+version = "2.14.5"
+
+[package.optional-dependencies]
+version = ["not-a-version"]
+
+[[package]]
+name = "requests"
+version = "2.31.0"
+`,
+				"requirements.txt": "requests==2.31.0 ; python_version >= '3.8'\ncertifi==2023.5.7 \\\n    --hash=sha256:abc\nidna>=3\n",
+			},
+			expected: []*Locked{
+				{Ecosystem: EcosystemPyPI, Name: "certifi", Version: "2023.5.7"},
+				{Ecosystem: EcosystemPyPI, Name: "pydantic-core", Version: "2.14.5"},
+				{Ecosystem: EcosystemPyPI, Name: "requests", Version: "2.31.0"},
+			},
+			lockfiles: []string{"uv.lock", "requirements.txt"},
+		},
+		{
+			name: "poetry.lock with dev dependencies",
+			files: map[string]string{
+				"poetry.lock": `[[package]]
+name = "flask"
+version = "2.3.2"
+category = "main"
+
+[[package]]
+name = "pytest"
+version = "7.3.1"
+category = "dev"
+`,
+			},
+			expected: []*Locked{
+				{Ecosystem: EcosystemPyPI, Name: "flask", Version: "2.3.2"},
+				{Ecosystem: EcosystemPyPI, Name: "pytest", Version: "7.3.1", Dev: true},
+			},
+			lockfiles: []string{"poetry.lock"},
+		},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		for name, content := range c.files {
+			assert.NilError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		packages, lockfiles, err := Packages(dir)
+		assert.NilError(t, err, c.name)
+		assert.DeepEqual(t, packages, c.expected)
+		assert.DeepEqual(t, lockfiles, c.lockfiles)
+	}
+}
@@ -0,0 +1,165 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// npmDependencyFields are the fields of a package.json with dependencies which are locked
+var npmDependencyFields = []string{"dependencies", "devDependencies", "optionalDependencies"}
+
+// npmDependencies returns the dependencies of a package.json with their version range
+func npmDependencies(manifest []byte) (map[string]string, error) {
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(manifest, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+	deps := make(map[string]string)
+	for _, field := range npmDependencyFields {
+		raw, ok := pkg[field]
+		if !ok {
+			continue
+		}
+		var m map[string]string
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, fmt.Errorf("invalid %s in package.json: %w", field, err)
+		}
+		for name, spec := range m {
+			deps[name] = spec
+		}
+	}
+	return deps, nil
+}
+
+// npmOutdated compares the package.json with the root package of a package-lock.json, lockfiles of version 1 don't
+// have the version ranges, so only missing dependencies are found
+func npmOutdated(manifest []byte, lockfile []byte) ([]string, error) {
+	deps, err := npmDependencies(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var lock struct {
+		Packages map[string]map[string]json.RawMessage `json:"packages"`
+		// Dependencies are the locked packages of lockfiles of version 1
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	if err := json.Unmarshal(lockfile, &lock); err != nil {
+		return nil, fmt.Errorf("invalid package-lock.json: %w", err)
+	}
+
+	root, ok := lock.Packages[""]
+	if !ok {
+		var outdated []string
+		for name := range deps {
+			if _, ok := lock.Dependencies[name]; !ok {
+				outdated = append(outdated, name)
+			}
+		}
+		return outdated, nil
+	}
+
+	locked := make(map[string]string)
+	for _, field := range npmDependencyFields {
+		var m map[string]string
+		if raw, ok := root[field]; ok {
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return nil, fmt.Errorf("invalid %s in package-lock.json: %w", field, err)
+			}
+		}
+		for name, spec := range m {
+			locked[name] = spec
+		}
+	}
+	return outdatedSpecs(deps, locked), nil
+}
+
+// outdatedSpecs returns the dependencies missing in locked or locked with another version range, and the locked
+// dependencies removed from the manifest
+func outdatedSpecs(deps map[string]string, locked map[string]string) []string {
+	var outdated []string
+	for name, spec := range deps {
+		if lockedSpec, ok := locked[name]; !ok || lockedSpec != spec {
+			outdated = append(outdated, name)
+		}
+	}
+	for name := range locked {
+		if _, ok := deps[name]; !ok {
+			outdated = append(outdated, name)
+		}
+	}
+	return outdated
+}
+
+// yarnOutdated looks up the dependencies of the package.json in the entries of a yarn.lock, which are keyed by the
+// name and version range, e.g. "lodash@^4.17.0" or "lodash@npm:^4.17.0" since yarn 2
+func yarnOutdated(manifest []byte, lockfile []byte) ([]string, error) {
+	deps, err := npmDependencies(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]bool)
+	for _, line := range strings.Split(string(lockfile), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || line[0] == ' ' || line[0] == '#' || !strings.HasSuffix(line, ":") {
+			continue
+		}
+		for _, key := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+			entries[strings.Trim(strings.TrimSpace(key), `"`)] = true
+		}
+	}
+
+	var outdated []string
+	for name, spec := range deps {
+		if !entries[name+"@"+spec] && !entries[name+"@npm:"+spec] {
+			outdated = append(outdated, name)
+		}
+	}
+	return outdated, nil
+}
+
+// pnpmOutdated compares the package.json with the specifiers of the root project of a pnpm-lock.yaml, they're a map
+// of specifiers up to lockfile version 5 and a specifier per dependency since version 6
+func pnpmOutdated(manifest []byte, lockfile []byte) ([]string, error) {
+	deps, err := npmDependencies(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	type project struct {
+		Specifiers           map[string]string `yaml:"specifiers"`
+		Dependencies         map[string]any    `yaml:"dependencies"`
+		DevDependencies      map[string]any    `yaml:"devDependencies"`
+		OptionalDependencies map[string]any    `yaml:"optionalDependencies"`
+	}
+	var lock struct {
+		project   `yaml:",inline"`
+		Importers map[string]project `yaml:"importers"`
+	}
+	if err := yaml.Unmarshal(lockfile, &lock); err != nil {
+		return nil, fmt.Errorf("invalid pnpm-lock.yaml: %w", err)
+	}
+
+	root := lock.project
+	if p, ok := lock.Importers["."]; ok {
+		root = p
+	}
+
+	locked := make(map[string]string)
+	for name, spec := range root.Specifiers {
+		locked[name] = spec
+	}
+	for _, m := range []map[string]any{root.Dependencies, root.DevDependencies, root.OptionalDependencies} {
+		for name, v := range m {
+			if dep, ok := v.(map[string]any); ok {
+				if spec, ok := dep["specifier"].(string); ok {
+					locked[name] = spec
+				}
+			}
+		}
+	}
+	return outdatedSpecs(deps, locked), nil
+}
@@ -0,0 +1,231 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EcosystemNPM packages are installed from the npm registry
+	EcosystemNPM = "npm"
+	// EcosystemPyPI packages are installed from the python package index
+	EcosystemPyPI = "pypi"
+)
+
+// Locked is a package pinned by a lockfile
+type Locked struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	// Dev is set for packages only needed for development, e.g. devDependencies and their dependencies, if the
+	// lockfile records it: package-lock.json, pnpm-lock.yaml up to version 6 and poetry.lock before poetry 1.2
+	Dev bool `json:"dev,omitempty"`
+}
+
+// lister lists the packages of a lockfile
+type lister struct {
+	lockfile string
+	list     func(content []byte) ([]*Locked, error)
+}
+
+var listers = []lister{
+	{lockfile: "package-lock.json", list: npmPackages},
+	{lockfile: "yarn.lock", list: yarnPackages},
+	{lockfile: "pnpm-lock.yaml", list: pnpmPackages},
+	{lockfile: "poetry.lock", list: pythonLockPackages},
+	{lockfile: "uv.lock", list: pythonLockPackages},
+	{lockfile: "requirements.txt", list: pinnedRequirements},
+}
+
+// Packages returns the packages locked by the lockfiles in dir sorted by name and the lockfiles they were read from,
+// packages locked with the same version by several lockfiles are listed once
+func Packages(dir string) ([]*Locked, []string, error) {
+	var packages []*Locked
+	var lockfiles []string
+	seen := make(map[Locked]bool)
+	for _, l := range listers {
+		content, err := os.ReadFile(filepath.Join(dir, l.lockfile))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		locked, err := l.list(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, l.lockfile), err)
+		}
+		lockfiles = append(lockfiles, l.lockfile)
+		for _, p := range locked {
+			if !seen[*p] {
+				seen[*p] = true
+				packages = append(packages, p)
+			}
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages, lockfiles, nil
+}
+
+// npmPackages lists the installed packages of a package-lock.json, nested node_modules are included and linked
+// workspace packages aren't
+func npmPackages(content []byte) ([]*Locked, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+			Link    bool   `json:"link"`
+			Dev     bool   `json:"dev"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+			Dev     bool   `json:"dev"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("invalid package-lock.json: %w", err)
+	}
+
+	var packages []*Locked
+	for key, p := range lock.Packages {
+		i := strings.LastIndex(key, "node_modules/")
+		if i < 0 || p.Link || p.Version == "" {
+			continue
+		}
+		packages = append(packages, &Locked{Ecosystem: EcosystemNPM, Name: key[i+len("node_modules/"):], Version: p.Version, Dev: p.Dev})
+	}
+	// lockfiles of version 1 only have the top level dependencies
+	if lock.Packages == nil {
+		for name, p := range lock.Dependencies {
+			packages = append(packages, &Locked{Ecosystem: EcosystemNPM, Name: name, Version: p.Version, Dev: p.Dev})
+		}
+	}
+	return packages, nil
+}
+
+// yarnPackages lists the entries of a yarn.lock with their resolved version
+func yarnPackages(content []byte) ([]*Locked, error) {
+	var packages []*Locked
+	name := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line[0] != ' ' {
+			name = ""
+			key, _, _ := strings.Cut(strings.TrimSuffix(line, ":"), ",")
+			key = strings.Trim(strings.TrimSpace(key), `"`)
+			// scoped packages start with an @, the version range follows the last one
+			if i := strings.LastIndex(key, "@"); i > 0 {
+				name = key[:i]
+			}
+			continue
+		}
+		field := strings.TrimSpace(line)
+		if name == "" || !strings.HasPrefix(field, "version") {
+			continue
+		}
+		version := strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(field, "version"), ":")), `"`)
+		// the root workspace of yarn 2 is listed with version 0.0.0-use.local
+		if version != "" && !strings.HasSuffix(version, "-use.local") {
+			packages = append(packages, &Locked{Ecosystem: EcosystemNPM, Name: name, Version: version})
+		}
+		name = ""
+	}
+	return packages, nil
+}
+
+// pnpmPackages lists the packages of a pnpm-lock.yaml, they're keyed by /name/version up to lockfile version 5,
+// /name@version since version 6 and name@version since version 9, peer dependencies are appended in parentheses
+func pnpmPackages(content []byte) ([]*Locked, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Dev bool `yaml:"dev"`
+		} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("invalid pnpm-lock.yaml: %w", err)
+	}
+
+	var packages []*Locked
+	for key, p := range lock.Packages {
+		key, _, _ = strings.Cut(strings.TrimPrefix(key, "/"), "(")
+		sep := "@"
+		if i := strings.LastIndex(key, "@"); i <= 0 {
+			sep = "/"
+		}
+		i := strings.LastIndex(key, sep)
+		if i <= 0 {
+			continue
+		}
+		packages = append(packages, &Locked{Ecosystem: EcosystemNPM, Name: key[:i], Version: key[i+1:], Dev: p.Dev})
+	}
+	return packages, nil
+}
+
+// pythonLockPackages lists the packages of a poetry.lock or uv.lock, every [[package]] starts with its name
+func pythonLockPackages(content []byte) ([]*Locked, error) {
+	var packages []*Locked
+	var current *Locked
+	for _, v := range tomlValues(content) {
+		if v.table != "package" {
+			continue
+		}
+		switch v.key {
+		case "name":
+			current = &Locked{Ecosystem: EcosystemPyPI}
+			if names := tomlStrings(v.value); len(names) == 1 {
+				current.Name = normalizeName(names[0])
+			}
+			packages = append(packages, current)
+		case "version":
+			if versions := tomlStrings(v.value); current != nil && len(versions) == 1 {
+				current.Version = versions[0]
+			}
+		case "category":
+			// poetry before 1.2 puts the dev dependencies in the dev category
+			if categories := tomlStrings(v.value); current != nil && len(categories) == 1 {
+				current.Dev = categories[0] == "dev"
+			}
+		}
+	}
+
+	// editable and virtual packages, like the project itself in a uv.lock, have no version
+	locked := packages[:0]
+	for _, p := range packages {
+		if p.Name != "" && p.Version != "" {
+			locked = append(locked, p)
+		}
+	}
+	return locked, nil
+}
+
+// pinnedRequirements lists the requirements pinned with == in a requirements.txt, other requirements aren't locked
+func pinnedRequirements(content []byte) ([]*Locked, error) {
+	var packages []*Locked
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line, _, _ = strings.Cut(line, ";")
+		line = strings.TrimSpace(line)
+		name, version, ok := strings.Cut(line, "==")
+		if !ok || strings.HasPrefix(line, "-") {
+			continue
+		}
+		version, _, _ = strings.Cut(strings.TrimSpace(version), " ")
+		if name := requirementName(name); name != "" && version != "" {
+			packages = append(packages, &Locked{Ecosystem: EcosystemPyPI, Name: name, Version: version})
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,176 @@
+package lockfile
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// pythonName matches the name at the start of a requirement like requests[socks]>=2.0
+	pythonName     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+	nameSeparators = regexp.MustCompile(`[-_.]+`)
+	tomlString     = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// normalizeName normalizes the name of a python package as pip does, Foo_Bar and foo-bar are the same package
+func normalizeName(name string) string {
+	return strings.ToLower(nameSeparators.ReplaceAllString(name, "-"))
+}
+
+// requirementName returns the normalized name of a requirement, or "" if it has none
+func requirementName(requirement string) string {
+	return normalizeName(pythonName.FindString(strings.TrimSpace(requirement)))
+}
+
+// tomlValue is a key of a toml file with its raw value, arrays may span multiple lines
+type tomlValue struct {
+	table string
+	key   string
+	value string
+}
+
+// tomlValues reads the keys of a toml file, this is enough for the dependencies of a pyproject.toml and the package
+// names of the lockfiles without a full toml parser
+func tomlValues(content []byte) []tomlValue {
+	var values []tomlValue
+	table := ""
+	lines := strings.Split(string(content), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		// multiline arrays continue until their brackets are balanced
+		for depth := bracketDepth(value); depth > 0 && i+1 < len(lines); depth = bracketDepth(value) {
+			i++
+			value += "\n" + strings.TrimSpace(lines[i])
+		}
+		values = append(values, tomlValue{table: table, key: strings.Trim(strings.TrimSpace(key), `"'`), value: value})
+	}
+	return values
+}
+
+// bracketDepth counts the unclosed brackets of value outside of strings and comments
+func bracketDepth(value string) int {
+	depth := 0
+	var quote rune
+	for _, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return depth
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// tomlStrings returns the strings of a toml array
+func tomlStrings(array string) []string {
+	var values []string
+	for _, m := range tomlString.FindAllStringSubmatch(array, -1) {
+		values = append(values, m[1]+m[2])
+	}
+	return values
+}
+
+// pyprojectDependencies returns the normalized names of the dependencies of a pyproject.toml, declared as PEP 621
+// project dependencies or in the dependency tables of poetry
+func pyprojectDependencies(manifest []byte) []string {
+	var deps []string
+	for _, v := range tomlValues(manifest) {
+		switch {
+		case v.table == "project" && v.key == "dependencies":
+			for _, requirement := range tomlStrings(v.value) {
+				if name := requirementName(requirement); name != "" {
+					deps = append(deps, name)
+				}
+			}
+		case v.table == "tool.poetry.dependencies" || v.table == "tool.poetry.dev-dependencies" ||
+			strings.HasPrefix(v.table, "tool.poetry.group.") && strings.HasSuffix(v.table, ".dependencies"):
+			if v.key != "python" {
+				deps = append(deps, normalizeName(v.key))
+			}
+		}
+	}
+	return deps
+}
+
+// lockedPackages returns the normalized names of the packages of a poetry.lock or uv.lock
+func lockedPackages(lockfile []byte) map[string]bool {
+	packages := make(map[string]bool)
+	for _, v := range tomlValues(lockfile) {
+		if v.table == "package" && v.key == "name" {
+			if names := tomlStrings(v.value); len(names) == 1 {
+				packages[normalizeName(names[0])] = true
+			}
+		}
+	}
+	return packages
+}
+
+// missing returns the deps which aren't locked
+func missing(deps []string, locked map[string]bool) []string {
+	var outdated []string
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		if !locked[dep] && !seen[dep] {
+			outdated = append(outdated, dep)
+		}
+		seen[dep] = true
+	}
+	return outdated
+}
+
+// poetryOutdated looks up the dependencies of the pyproject.toml in the packages of a poetry.lock, version changes
+// aren't found as checking them would need a resolver
+func poetryOutdated(manifest []byte, lockfile []byte) ([]string, error) {
+	return missing(pyprojectDependencies(manifest), lockedPackages(lockfile)), nil
+}
+
+// uvOutdated looks up the dependencies of the pyproject.toml in the packages of a uv.lock
+func uvOutdated(manifest []byte, lockfile []byte) ([]string, error) {
+	return missing(pyprojectDependencies(manifest), lockedPackages(lockfile)), nil
+}
+
+// requirements returns the normalized names of a requirements file, options like -r and -e are skipped
+func requirements(content []byte) []string {
+	var names []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") && !strings.Contains(line, " @ ") {
+			continue
+		}
+		if name := requirementName(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// pipToolsOutdated looks up the requirements of a requirements.in in the requirements.txt compiled by pip-tools
+func pipToolsOutdated(manifest []byte, lockfile []byte) ([]string, error) {
+	locked := make(map[string]bool)
+	for _, name := range requirements(lockfile) {
+		locked[name] = true
+	}
+	return missing(requirements(manifest), locked), nil
+}
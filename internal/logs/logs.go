@@ -0,0 +1,56 @@
+// Package logs parses and formats the runtime logs of the micros of an app, which are streamed as json lines
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Entry is a line logged by a micro
+type Entry struct {
+	Time    *time.Time `json:"timestamp,omitempty"`
+	Micro   string     `json:"micro,omitempty"`
+	Message string     `json:"message"`
+}
+
+// Parse parses a line of the log stream, lines which aren't json are kept as the message
+func Parse(line string) *Entry {
+	var e Entry
+	if err := json.Unmarshal([]byte(line), &e); err != nil || e.Message == "" && e.Micro == "" {
+		return &Entry{Message: line}
+	}
+	return &e
+}
+
+// Format formats the entry as time, micro and message, micro is padded to width
+func Format(e *Entry, width int) string {
+	var parts []string
+	if e.Time != nil {
+		parts = append(parts, e.Time.Local().Format("2006-01-02 15:04:05"))
+	}
+	if e.Micro != "" {
+		parts = append(parts, fmt.Sprintf("%-*s |", width, e.Micro))
+	}
+	parts = append(parts, strings.TrimRight(e.Message, "\n"))
+	return strings.Join(parts, " ")
+}
+
+// ParseSince parses --since, either a duration before now like 15m or a time in RFC3339 like 2023-05-01T10:00:00Z
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("since must be a positive duration, e.g. 15m")
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q, use a duration like 15m or a time like 2023-05-01T10:00:00Z", value)
+	}
+	if t.After(now) {
+		return time.Time{}, fmt.Errorf("since %s is in the future", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,47 @@
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	e := Parse(`{"timestamp": "2023-05-01T10:00:00Z", "micro": "api", "message": "GET /items 200"}`)
+	assert.Equal(t, e.Micro, "api")
+	assert.Equal(t, e.Message, "GET /items 200")
+	assert.Assert(t, e.Time != nil && e.Time.Equal(time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)))
+
+	e = Parse("Traceback (most recent call last):")
+	assert.DeepEqual(t, e, &Entry{Message: "Traceback (most recent call last):"})
+
+	assert.Equal(t, Format(&Entry{Micro: "api", Message: "started\n"}, 8), "api      | started")
+	assert.Equal(t, Format(&Entry{Message: "plain"}, 8), "plain")
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value    string
+		expected time.Time
+		err      bool
+	}{
+		{value: "15m", expected: time.Date(2023, 5, 1, 11, 45, 0, 0, time.UTC)},
+		{value: "2h", expected: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "2023-05-01T10:00:00Z", expected: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "2023-05-02T10:00:00Z", err: true},
+		{value: "-5m", err: true},
+		{value: "yesterday", err: true},
+	}
+
+	for _, c := range cases {
+		since, err := ParseSince(c.value, now)
+		if c.err {
+			assert.Assert(t, err != nil, c.value)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Assert(t, since.Equal(c.expected), c.value)
+	}
+}
@@ -0,0 +1,125 @@
+// Package manual generates the offline documentation of the cli from its commands, the man pages and a single help
+// page of all commands
+package manual
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// ErrUnsupported man pages aren't supported on windows
+var ErrUnsupported = errors.New("man pages are not supported on windows")
+
+// Commands returns root and all of its available commands, depth first in the order of the help
+func Commands(root *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{root}
+	for _, c := range root.Commands() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		commands = append(commands, Commands(c)...)
+	}
+	return commands
+}
+
+// WriteAll writes the help of root and all of its commands as a single page
+func WriteAll(w io.Writer, root *cobra.Command) error {
+	for i, c := range Commands(root) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := writeCommand(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCommand(w io.Writer, c *cobra.Command) error {
+	title := c.CommandPath()
+	fmt.Fprintf(w, "%s\n%s\n\n", title, strings.Repeat("=", len(title)))
+
+	description := c.Long
+	if description == "" {
+		description = c.Short
+	}
+	if description != "" {
+		fmt.Fprintf(w, "%s\n\n", strings.TrimSpace(description))
+	}
+
+	fmt.Fprintf(w, "Usage:\n  %s\n", c.UseLine())
+	if c.HasAvailableSubCommands() {
+		fmt.Fprintf(w, "  %s [command]\n", c.CommandPath())
+	}
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases:\n  %s\n", strings.Join(append([]string{c.Name()}, c.Aliases...), ", "))
+	}
+	if c.HasExample() {
+		fmt.Fprintf(w, "\nExamples:\n%s\n", strings.TrimRight(c.Example, "\n"))
+	}
+	// the global flags are listed once with the root command
+	if c.HasParent() {
+		if c.HasAvailableLocalFlags() {
+			fmt.Fprintf(w, "\nFlags:\n%s", c.LocalFlags().FlagUsages())
+		}
+		return nil
+	}
+	if flags := c.LocalNonPersistentFlags(); flags.HasAvailableFlags() {
+		fmt.Fprintf(w, "\nFlags:\n%s", flags.FlagUsages())
+	}
+	if c.HasAvailablePersistentFlags() {
+		fmt.Fprintf(w, "\nGlobal Flags:\n%s", c.PersistentFlags().FlagUsages())
+	}
+	return nil
+}
+
+// DefaultDir returns the directory of the man pages of the user, the first directory of $MANPATH or the man directory
+// of the data directory, e.g. ~/.local/share/man
+func DefaultDir() (string, error) {
+	if goruntime.GOOS == "windows" {
+		return "", ErrUnsupported
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("MANPATH")) {
+		if dir != "" {
+			return dir, nil
+		}
+	}
+
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(userHome, ".local", "share")
+	}
+	return filepath.Join(dataDir, "man"), nil
+}
+
+// Install writes a man page for root and each of its commands to the man1 directory of dir, it returns the number of
+// written pages
+func Install(root *cobra.Command, dir string, version string) (int, error) {
+	section := filepath.Join(dir, "man1")
+	if err := os.MkdirAll(section, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", section, err)
+	}
+
+	header := &doc.GenManHeader{
+		Title:   strings.ToUpper(root.Name()),
+		Section: "1",
+		Source:  fmt.Sprintf("Space CLI %s", version),
+		Manual:  "Space CLI Manual",
+	}
+	if err := doc.GenManTree(root, header, section); err != nil {
+		return 0, fmt.Errorf("failed to write man pages: %w", err)
+	}
+	return len(Commands(root)), nil
+}
@@ -0,0 +1,78 @@
+package manual
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func testCommand() *cobra.Command {
+	root := &cobra.Command{Use: "space", Short: "Deta Space CLI", DisableAutoGenTag: true}
+	root.PersistentFlags().Bool("no-cache", false, "don't use cached API responses")
+
+	push := &cobra.Command{
+		Use:     "push [flags]",
+		Short:   "Push your changes to Space",
+		Example: "  space push --tag v1.2.0",
+		Run:     func(cmd *cobra.Command, args []string) {},
+	}
+	push.Flags().StringP("tag", "t", "", "tag to identify this push")
+
+	hidden := &cobra.Command{Use: "internal", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+	root.AddCommand(push, hidden)
+	return root
+}
+
+func TestCommands(t *testing.T) {
+	var paths []string
+	for _, c := range Commands(testCommand()) {
+		paths = append(paths, c.CommandPath())
+	}
+	assert.DeepEqual(t, paths, []string{"space", "space push"})
+}
+
+func TestWriteAll(t *testing.T) {
+	var out bytes.Buffer
+	assert.NilError(t, WriteAll(&out, testCommand()))
+	assert.Equal(t, out.String(), `space
+=====
+
+Deta Space CLI
+
+Usage:
+  space
+  space [command]
+
+Global Flags:
+      --no-cache   don't use cached API responses
+
+space push
+==========
+
+Push your changes to Space
+
+Usage:
+  space push [flags]
+
+Examples:
+  space push --tag v1.2.0
+
+Flags:
+  -t, --tag string   tag to identify this push
+`)
+}
+
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	n, err := Install(testCommand(), dir, "v0.4.0")
+	assert.NilError(t, err)
+	assert.Equal(t, n, 2)
+
+	page, err := os.ReadFile(filepath.Join(dir, "man1", "space-push.1"))
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Contains(page, []byte("space push --tag v1.2.0")))
+}
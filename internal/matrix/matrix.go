@@ -0,0 +1,146 @@
+// Package matrix runs the build commands and tests of micros in containers of different engine versions
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deta/space/shared"
+)
+
+var (
+	pythonReg = regexp.MustCompile(`^python(\d+\.\d+)$`)
+	nodeReg   = regexp.MustCompile(`^nodejs(\d+)(?:\.x)?$`)
+)
+
+// Image returns the docker image to test an engine with, engines can also be given as images, e.g. python:3.12-rc
+func Image(engine string) (string, error) {
+	if strings.Contains(engine, ":") {
+		return engine, nil
+	}
+
+	// frontend engines are built with node
+	if shared.IsFrontendEngine(engine) || shared.IsFullstackEngine(engine) {
+		engine = shared.EnginesToRuntimes[engine]
+	}
+
+	if matches := pythonReg.FindStringSubmatch(engine); matches != nil {
+		return fmt.Sprintf("python:%s-slim", matches[1]), nil
+	}
+	if matches := nodeReg.FindStringSubmatch(engine); matches != nil {
+		return fmt.Sprintf("node:%s-slim", matches[1]), nil
+	}
+	return "", fmt.Errorf("no image for engine %s, use an image like python:3.11 instead", engine)
+}
+
+// Job tests a micro with one engine
+type Job struct {
+	Micro  string
+	Engine string
+	// Src is the absolute path of the source of the micro
+	Src      string
+	Commands []string
+	Run      string
+}
+
+// Result of a job, Output holds the combined output of the commands
+type Result struct {
+	Job      *Job
+	Err      error
+	Duration time.Duration
+	Output   []byte
+}
+
+// Passed reports if all commands of the job succeeded
+func (r *Result) Passed() bool {
+	return r.Err == nil
+}
+
+// script copies the source so that builds don't write into the project, then runs the commands
+func (j *Job) script() string {
+	commands := append([]string{"cp -a /src/. /app", "cd /app"}, j.Commands...)
+	if j.Run != "" {
+		commands = append(commands, j.Run)
+	}
+	return "set -e\n" + strings.Join(commands, "\n")
+}
+
+// Run runs the job in a container, the output is also written to w if set
+func Run(ctx context.Context, job *Job, w io.Writer) *Result {
+	result := &Result{Job: job}
+
+	image, err := Image(job.Engine)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	var out bytes.Buffer
+	var output io.Writer = &out
+	if w != nil {
+		output = io.MultiWriter(&out, w)
+	}
+
+	cmd := exec.CommandContext(ctx, "docker", "run", "--rm", "-v", job.Src+":/src:ro", image, "sh", "-c", job.script())
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	start := time.Now()
+	result.Err = cmd.Run()
+	result.Duration = time.Since(start)
+	result.Output = out.Bytes()
+	return result
+}
+
+// WriteTable writes the results as a table with a row per micro and a column per engine
+func WriteTable(w io.Writer, results []*Result) error {
+	var micros, engines []string
+	cells := map[string]map[string]*Result{}
+	for _, r := range results {
+		if _, ok := cells[r.Job.Micro]; !ok {
+			cells[r.Job.Micro] = map[string]*Result{}
+			micros = append(micros, r.Job.Micro)
+		}
+		cells[r.Job.Micro][r.Job.Engine] = r
+		if !contains(engines, r.Job.Engine) {
+			engines = append(engines, r.Job.Engine)
+		}
+	}
+	sort.Strings(engines)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(tw, "MICRO\t%s\n", strings.Join(engines, "\t"))
+	for _, micro := range micros {
+		row := []string{micro}
+		for _, engine := range engines {
+			r, ok := cells[micro][engine]
+			switch {
+			case !ok:
+				row = append(row, "-")
+			case r.Passed():
+				row = append(row, fmt.Sprintf("pass (%s)", r.Duration.Round(time.Second)))
+			default:
+				row = append(row, "fail")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
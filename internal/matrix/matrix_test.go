@@ -0,0 +1,55 @@
+package matrix
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestImage(t *testing.T) {
+	cases := []struct {
+		engine   string
+		expected string
+		err      bool
+	}{
+		{engine: "python3.9", expected: "python:3.9-slim"},
+		{engine: "python3.11", expected: "python:3.11-slim"},
+		{engine: "nodejs16", expected: "node:16-slim"},
+		{engine: "nodejs18.x", expected: "node:18-slim"},
+		{engine: "react", expected: "node:14-slim"},
+		{engine: "python:3.12-rc", expected: "python:3.12-rc"},
+		{engine: "custom", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.engine, func(t *testing.T) {
+			image, err := Image(c.engine)
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, image, c.expected)
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	results := []*Result{
+		{Job: &Job{Micro: "backend", Engine: "python3.9"}, Duration: 12 * time.Second},
+		{Job: &Job{Micro: "backend", Engine: "python3.11"}, Err: errors.New("exit status 1")},
+		{Job: &Job{Micro: "frontend", Engine: "nodejs16"}, Duration: 3 * time.Second},
+	}
+
+	var buf bytes.Buffer
+	assert.NilError(t, WriteTable(&buf, results))
+
+	expected := `MICRO      nodejs16    python3.11   python3.9
+backend    -           fail         pass (12s)
+frontend   pass (3s)   -            -
+`
+	assert.Equal(t, buf.String(), expected)
+}
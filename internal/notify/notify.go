@@ -0,0 +1,68 @@
+// Package notify shows desktop notifications with the tools of the platform: osascript on macOS, notify-send on Linux
+// and a toast shown by PowerShell on Windows
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// MinDuration is how long an operation has to take for a notification. The cli can't tell if its terminal is in the
+// background, but after a while it likely is.
+const MinDuration = 15 * time.Second
+
+// ErrUnsupported desktop notifications aren't supported on the platform
+var ErrUnsupported = errors.New("desktop notifications are not supported on this platform")
+
+// appName is shown as the sender of the toast on Windows
+const appName = "Space CLI"
+
+// Command returns the command which shows the notification on goos
+func Command(goos string, title string, message string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--app-name", appName, title, message}, nil
+	case "windows":
+		script := strings.Join([]string{
+			"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+			"$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+			"$text = $template.GetElementsByTagName('text')",
+			fmt.Sprintf("$text.Item(0).AppendChild($template.CreateTextNode(%s)) > $null", powerShellString(title)),
+			fmt.Sprintf("$text.Item(1).AppendChild($template.CreateTextNode(%s)) > $null", powerShellString(message)),
+			fmt.Sprintf("[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(%s).Show([Windows.UI.Notifications.ToastNotification]::new($template))", powerShellString(appName)),
+		}, "; ")
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, nil
+	}
+	return "", nil, ErrUnsupported
+}
+
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Send shows a desktop notification
+func Send(title string, message string) error {
+	name, args, err := Command(runtime.GOOS, title, message)
+	if err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s is needed for desktop notifications: %w", name, err)
+	}
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to show the notification: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
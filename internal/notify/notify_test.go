@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCommand(t *testing.T) {
+	cases := []struct {
+		goos     string
+		name     string
+		contains string
+		err      bool
+	}{
+		{goos: "darwin", name: "osascript", contains: `display notification "Release \"1.0.0\" done" with title "my-app"`},
+		{goos: "linux", name: "notify-send", contains: `Release "1.0.0" done`},
+		{goos: "windows", name: "powershell", contains: `CreateTextNode('it''s done')`},
+		{goos: "plan9", err: true},
+	}
+
+	for _, c := range cases {
+		message := `Release "1.0.0" done`
+		if c.goos == "windows" {
+			message = "it's done"
+		}
+		name, args, err := Command(c.goos, "my-app", message)
+		if c.err {
+			assert.ErrorIs(t, err, ErrUnsupported)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, name, c.name)
+		assert.Assert(t, strings.Contains(strings.Join(args, " "), c.contains), args)
+	}
+}
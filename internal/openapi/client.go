@@ -0,0 +1,270 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Lang of a generated client
+type Lang string
+
+const (
+	LangTypeScript Lang = "ts"
+	LangPython     Lang = "py"
+)
+
+// Langs are the languages clients can be generated in
+var Langs = []Lang{LangTypeScript, LangPython}
+
+// restParam is the name of a trailing parameter which matches the rest of the path, as scaffolded for a trailing *.
+// The clients keep its slashes instead of escaping them.
+const restParam = "path"
+
+// methods in the order of the operations of a path in the clients
+var methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// operation is an operation of the spec as a method of a client
+type operation struct {
+	name         string
+	method       string
+	summary      string
+	segments     []segment
+	pathParams   []*Parameter
+	queryParams  []*Parameter
+	body         *Schema
+	bodyRequired bool
+	result       *Schema
+}
+
+// segment of a path, either literal text or a parameter
+type segment struct {
+	text  string
+	param string
+	rest  bool
+}
+
+// ClientFileName returns the name of the file of the client of the micro, e.g. api_client.ts
+func ClientFileName(micro string, lang Lang) string {
+	return snake(micro) + "_client." + string(lang)
+}
+
+// GenerateClient returns the source of a client of the micro for the operations of the document, with a type for every
+// schema of its components. The client has no dependencies: fetch for TypeScript and urllib for Python.
+func GenerateClient(doc *Document, micro string, lang Lang) ([]byte, error) {
+	server := ""
+	if len(doc.Servers) > 0 {
+		server = strings.TrimSuffix(doc.Servers[0].URL, "/")
+	}
+	var schemas map[string]*Schema
+	if doc.Components != nil {
+		schemas = doc.Components.Schemas
+	}
+
+	switch lang {
+	case LangTypeScript:
+		return []byte(typeScriptClient(micro, server, schemas, operations(doc))), nil
+	case LangPython:
+		return []byte(pythonClient(micro, server, schemas, operations(doc))), nil
+	default:
+		return nil, fmt.Errorf("unsupported language %s", lang)
+	}
+}
+
+// operations returns the operations of the document sorted by path and method
+func operations(doc *Document) []*operation {
+	var paths []string
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ops []*operation
+	for _, path := range paths {
+		item := doc.Paths[path].Operations()
+		for _, method := range methods {
+			op, ok := item[method]
+			if !ok {
+				continue
+			}
+			ops = append(ops, newOperation(method, path, op))
+		}
+	}
+	return ops
+}
+
+func newOperation(method string, path string, op *Operation) *operation {
+	o := &operation{name: op.OperationID, method: method, summary: op.Summary}
+	if o.name == "" {
+		o.name = operationID(method, path)
+	}
+
+	declared := make(map[string]*Parameter)
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			declared[p.Name] = p
+		case "query":
+			o.queryParams = append(o.queryParams, p)
+		}
+	}
+
+	parts := strings.Split(path, "/")
+	text := ""
+	for i, part := range parts {
+		if i > 0 {
+			text += "/"
+		}
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			text += part
+			continue
+		}
+		name := strings.Trim(part, "{}")
+		p, ok := declared[name]
+		if !ok {
+			p = &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		}
+		o.segments = append(o.segments, segment{text: text}, segment{param: name, rest: name == restParam && i == len(parts)-1})
+		o.pathParams = append(o.pathParams, p)
+		text = ""
+	}
+	if text != "" {
+		o.segments = append(o.segments, segment{text: text})
+	}
+
+	if op.RequestBody != nil {
+		if media, ok := op.RequestBody.Content["application/json"]; ok {
+			o.body, o.bodyRequired = media.Schema, op.RequestBody.Required
+		}
+	}
+	o.result = resultSchema(op.Responses)
+	return o
+}
+
+// resultSchema returns the json schema of the first successful response
+func resultSchema(responses map[string]*Response) *Schema {
+	var codes []string
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if media, ok := responses[code].Content["application/json"]; ok && media.Schema != nil {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func refName(ref string) string {
+	return pascal(ref[strings.LastIndex(ref, "/")+1:])
+}
+
+func isRequired(s *Schema, property string) bool {
+	for _, name := range s.Required {
+		if name == property {
+			return true
+		}
+	}
+	return false
+}
+
+// words splits a name into its words at separators and at the humps of camel case, e.g. getUserID into get, user, id
+func words(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words, word = append(words, strings.ToLower(string(word))), nil
+			}
+			continue
+		}
+		hump := unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))
+		if hump && len(word) > 0 {
+			words, word = append(words, strings.ToLower(string(word))), nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, strings.ToLower(string(word)))
+	}
+	return words
+}
+
+func title(word string) string {
+	return strings.ToUpper(word[:1]) + word[1:]
+}
+
+func pascal(name string) string {
+	s := ""
+	for _, word := range words(name) {
+		s += title(word)
+	}
+	return identifier(s)
+}
+
+func camel(name string) string {
+	s := ""
+	for i, word := range words(name) {
+		if i > 0 {
+			word = title(word)
+		}
+		s += word
+	}
+	return identifier(s)
+}
+
+func snake(name string) string {
+	return identifier(strings.Join(words(name), "_"))
+}
+
+// identifier prefixes names which would start with a digit
+func identifier(name string) string {
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		return "_" + name
+	}
+	return name
+}
+
+// isIdentifier reports whether a property can be written without quotes
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if !(r == '_' || unicode.IsLetter(r) || i > 0 && unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+func isNumeric(s *Schema) bool {
+	return s.Type == "integer" || s.Type == "number"
+}
+
+// enumValues returns the values of an enum as literals
+func enumValues(s *Schema) []string {
+	var values []string
+	for _, v := range s.Enum {
+		if isNumeric(s) {
+			values = append(values, v)
+			continue
+		}
+		values = append(values, strconv.Quote(v))
+	}
+	return values
+}
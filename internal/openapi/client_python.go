@@ -0,0 +1,227 @@
+package openapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pythonRuntime is the part of the Python client which doesn't depend on the spec
+const pythonRuntime = `from __future__ import annotations
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Any, Dict, List, Literal, Optional, TypedDict
+
+`
+
+const pythonErrors = `class RequestError(Exception):
+    def __init__(self, status: int, body: Any):
+        super().__init__(f"request failed with status {status}")
+        self.status = status
+        self.body = body
+
+
+def _segment(value: Any) -> str:
+    return urllib.parse.quote(str(value), safe="")
+
+
+def _rest(value: Any) -> str:
+    return urllib.parse.quote(str(value), safe="/")
+
+
+def _decode(content: bytes, content_type: str) -> Any:
+    text = content.decode()
+    return json.loads(text) if text and "json" in content_type else text
+
+
+`
+
+const pythonRequest = `
+    def _request(self, method: str, path: str, query: Dict[str, Any], body: Any = None) -> Any:
+        params = urllib.parse.urlencode(
+            {k: str(v).lower() if isinstance(v, bool) else v for k, v in query.items() if v is not None}
+        )
+        url = self.base_url + SERVER + path + ("?" + params if params else "")
+        headers = dict(self.headers)
+        data = None
+        if body is not None:
+            data = json.dumps(body).encode()
+            headers["Content-Type"] = "application/json"
+        req = urllib.request.Request(url, data=data, headers=headers, method=method)
+        try:
+            with urllib.request.urlopen(req) as res:
+                return _decode(res.read(), res.headers.get("Content-Type", ""))
+        except urllib.error.HTTPError as e:
+            raise RequestError(e.code, _decode(e.read(), e.headers.get("Content-Type", ""))) from e
+`
+
+func pythonClient(micro string, server string, schemas map[string]*Schema, ops []*operation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Code generated by space generate client for the micro %s. DO NOT EDIT.\n", micro)
+	b.WriteString(pythonRuntime)
+	for _, name := range sortedKeys(schemas) {
+		b.WriteString(pyDefinition(pascal(name), schemas[name]))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(pythonErrors)
+	fmt.Fprintf(&b, "SERVER = %s\n\n\n", strconv.Quote(server))
+
+	fmt.Fprintf(&b, "class %sClient:\n", pascal(micro))
+	b.WriteString("    def __init__(self, base_url: str = \"\", headers: Optional[Dict[str, str]] = None):\n")
+	b.WriteString("        self.base_url = base_url\n")
+	b.WriteString("        self.headers = headers or {}\n")
+	for _, op := range ops {
+		args := []string{"self"}
+		for _, p := range op.pathParams {
+			args = append(args, fmt.Sprintf("%s: %s", pyName(p.Name), pyType(p.Schema, false)))
+		}
+		body := "None"
+		if op.body != nil {
+			body = "body"
+			if op.bodyRequired {
+				args = append(args, "body: "+pyType(op.body, false))
+			} else {
+				args = append(args, fmt.Sprintf("body: Optional[%s] = None", pyType(op.body, false)))
+			}
+		}
+		var query []string
+		if len(op.queryParams) > 0 {
+			args = append(args, "*")
+			for _, p := range op.queryParams {
+				if p.Required {
+					args = append(args, fmt.Sprintf("%s: %s", pyName(p.Name), pyType(p.Schema, false)))
+				} else {
+					args = append(args, fmt.Sprintf("%s: Optional[%s] = None", pyName(p.Name), pyType(p.Schema, false)))
+				}
+				query = append(query, fmt.Sprintf("%s: %s", strconv.Quote(p.Name), pyName(p.Name)))
+			}
+		}
+
+		fmt.Fprintf(&b, "\n    def %s(%s) -> %s:\n", pyName(op.name), strings.Join(args, ", "), pyType(op.result, false))
+		if op.summary != "" {
+			summary := strings.NewReplacer(`\`, `\\`, `"""`, `\"\"\"`).Replace(op.summary)
+			fmt.Fprintf(&b, "        \"\"\"%s\"\"\"\n", summary)
+		}
+		fmt.Fprintf(&b, "        return self._request(%s, %s, {%s}, %s)\n", strconv.Quote(op.method), pyPath(op.segments), strings.Join(query, ", "), body)
+	}
+	b.WriteString(pythonRequest)
+	return b.String()
+}
+
+// pyDefinition defines the type of a schema of the components, objects as a TypedDict which is total if all their
+// properties are required
+func pyDefinition(name string, s *Schema) string {
+	if len(s.Properties) == 0 || s.Ref != "" {
+		return fmt.Sprintf("%s = %s\n", name, pyType(s, true))
+	}
+
+	total := len(s.Required) == len(s.Properties)
+	valid := true
+	for property := range s.Properties {
+		valid = valid && isIdentifier(property) && !pyKeywords[property]
+	}
+	if !valid {
+		var fields []string
+		for _, property := range sortedKeys(s.Properties) {
+			fields = append(fields, fmt.Sprintf("%s: %s", strconv.Quote(property), pyType(s.Properties[property], true)))
+		}
+		return fmt.Sprintf("%s = TypedDict(%s, {%s}, total=%s)\n", name, strconv.Quote(name), strings.Join(fields, ", "), pyBool(total))
+	}
+
+	var b strings.Builder
+	if total {
+		fmt.Fprintf(&b, "class %s(TypedDict):\n", name)
+	} else {
+		fmt.Fprintf(&b, "class %s(TypedDict, total=False):\n", name)
+	}
+	for _, property := range sortedKeys(s.Properties) {
+		fmt.Fprintf(&b, "    %s: %s\n", property, pyType(s.Properties[property], false))
+	}
+	return b.String()
+}
+
+func pyBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+// pyKeywords can't name a parameter or a property of a class
+var pyKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true, "await": true,
+	"break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true, "import": true,
+	"in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pyName is the name of a method or of a parameter, e.g. user_id for userId
+func pyName(name string) string {
+	name = snake(name)
+	if pyKeywords[name] || name == "self" || name == "body" {
+		name += "_"
+	}
+	return name
+}
+
+// pyPath is an f-string of the path of an operation, or a plain string if it has no parameters
+func pyPath(segments []segment) string {
+	if len(segments) == 1 && segments[0].param == "" {
+		return strconv.Quote(segments[0].text)
+	}
+	path := `f"`
+	for _, s := range segments {
+		switch {
+		case s.param == "":
+			path += strings.NewReplacer(`\`, `\\`, `"`, `\"`, "{", "{{", "}", "}}").Replace(s.text)
+		case s.rest:
+			path += fmt.Sprintf("{_rest(%s)}", pyName(s.param))
+		default:
+			path += fmt.Sprintf("{_segment(%s)}", pyName(s.param))
+		}
+	}
+	return path + `"`
+}
+
+// pyType is the annotation of a schema, references are quoted where they're evaluated before all types are defined
+func pyType(s *Schema, quoteRefs bool) string {
+	if s == nil {
+		return "Any"
+	}
+	t := pyBaseType(s, quoteRefs)
+	if s.Nullable {
+		t = "Optional[" + t + "]"
+	}
+	return t
+}
+
+func pyBaseType(s *Schema, quoteRefs bool) string {
+	switch {
+	case s.Ref != "" && quoteRefs:
+		return strconv.Quote(refName(s.Ref))
+	case s.Ref != "":
+		return refName(s.Ref)
+	case len(s.Enum) > 0:
+		return "Literal[" + strings.Join(enumValues(s), ", ") + "]"
+	}
+	switch s.Type {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "List[" + pyType(s.Items, quoteRefs) + "]"
+	case "object":
+		return "Dict[str, Any]"
+	default:
+		return "Any"
+	}
+}
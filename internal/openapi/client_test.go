@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+)
+
+const testSpec = `openapi: 3.0.3
+info: {title: api, version: 1.0.0}
+servers: [{url: /backend}]
+paths:
+  /users/{userId}:
+    get:
+      summary: Get a user
+      operationId: getUser
+      parameters:
+        - {name: userId, in: path, required: true, schema: {type: string}}
+        - {name: verbose, in: query, schema: {type: boolean}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User"}
+  /files/{path}:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {type: array, items: {type: string}}
+      responses:
+        "204": {description: OK}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        role: {type: string, enum: [admin, member]}
+        manager: {$ref: "#/components/schemas/User", nullable: true}
+`
+
+func TestGenerateClient(t *testing.T) {
+	var doc Document
+	assert.NilError(t, yaml.Unmarshal([]byte(testSpec), &doc))
+
+	cases := []struct {
+		lang     Lang
+		contains []string
+	}{
+		{
+			lang: LangTypeScript,
+			contains: []string{
+				"export interface User {\n  id: string;\n  manager?: User | null;\n  role?: \"admin\" | \"member\";\n}",
+				`const SERVER = "/backend";`,
+				"export class ApiClient {",
+				"  /** Get a user */\n  getUser(userId: string, query: { verbose?: boolean } = {}): Promise<User> {",
+				"this.request<User>(\"GET\", `/users/${encodeSegment(userId)}`, query, undefined);",
+				"  postFilesPath(path: string, body: string[]): Promise<unknown> {",
+				"`/files/${encodeRest(path)}`, {}, body);",
+			},
+		},
+		{
+			lang: LangPython,
+			contains: []string{
+				"class User(TypedDict, total=False):\n    id: str\n    manager: Optional[User]\n    role: Literal[\"admin\", \"member\"]\n",
+				`SERVER = "/backend"`,
+				"class ApiClient:",
+				"    def get_user(self, user_id: str, *, verbose: Optional[bool] = None) -> User:\n        \"\"\"Get a user\"\"\"",
+				`self._request("GET", f"/users/{_segment(user_id)}", {"verbose": verbose}, None)`,
+				"    def post_files_path(self, path: str, body: List[str]) -> Any:",
+				`f"/files/{_rest(path)}", {}, body)`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		content, err := GenerateClient(&doc, "api", c.lang)
+		assert.NilError(t, err)
+		for _, s := range c.contains {
+			assert.Assert(t, strings.Contains(string(content), s), "%s: %s\n%s", c.lang, s, content)
+		}
+	}
+
+	_, err := GenerateClient(&doc, "api", Lang("go"))
+	assert.ErrorContains(t, err, "unsupported language")
+}
+
+func TestWords(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected []string
+	}{
+		{name: "getUserID", expected: []string{"get", "user", "id"}},
+		{name: "user_id", expected: []string{"user", "id"}},
+		{name: "HTTPServer", expected: []string{"http", "server"}},
+		{name: "my-app2", expected: []string{"my", "app2"}},
+	}
+
+	for _, c := range cases {
+		assert.DeepEqual(t, words(c.name), c.expected)
+	}
+}
@@ -0,0 +1,202 @@
+package openapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// typeScriptRuntime is the part of the TypeScript client which doesn't depend on the spec
+const typeScriptRuntime = `export class RequestError extends Error {
+  constructor(public status: number, public body: unknown) {
+    super(` + "`request failed with status ${status}`" + `);
+  }
+}
+
+type Init = Omit<RequestInit, "headers"> & { headers?: Record<string, string> };
+
+const encodeSegment = (value: unknown) => encodeURIComponent(String(value));
+const encodeRest = (value: unknown) => String(value).split("/").map(encodeURIComponent).join("/");
+`
+
+const typeScriptRequest = `
+  private async request<T>(method: string, path: string, query: Record<string, unknown>, body?: unknown): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined && value !== null) params.append(key, String(value));
+    }
+    const search = params.toString();
+    const res = await fetch(` + "`${this.baseUrl}${SERVER}${path}${search ? \"?\" + search : \"\"}`" + `, {
+      ...this.init,
+      method,
+      headers: { ...(body !== undefined ? { "Content-Type": "application/json" } : {}), ...this.init.headers },
+      body: body !== undefined ? JSON.stringify(body) : undefined,
+    });
+    const text = await res.text();
+    const data = text && res.headers.get("Content-Type")?.includes("json") ? JSON.parse(text) : text;
+    if (!res.ok) throw new RequestError(res.status, data);
+    return data as T;
+  }
+}
+`
+
+func typeScriptClient(micro string, server string, schemas map[string]*Schema, ops []*operation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by space generate client for the micro %s. DO NOT EDIT.\n\n", micro)
+	for _, name := range sortedKeys(schemas) {
+		s := schemas[name]
+		if len(s.Properties) > 0 && s.Ref == "" {
+			fmt.Fprintf(&b, "export interface %s {\n", pascal(name))
+			for _, property := range sortedKeys(s.Properties) {
+				fmt.Fprintf(&b, "  %s: %s;\n", tsProperty(s, property), tsType(s.Properties[property]))
+			}
+			b.WriteString("}\n\n")
+			continue
+		}
+		fmt.Fprintf(&b, "export type %s = %s;\n\n", pascal(name), tsType(s))
+	}
+	b.WriteString(typeScriptRuntime)
+	fmt.Fprintf(&b, "\nconst SERVER = %s;\n\n", strconv.Quote(server))
+
+	fmt.Fprintf(&b, "export class %sClient {\n", pascal(micro))
+	b.WriteString("  constructor(private baseUrl = \"\", private init: Init = {}) {}\n")
+	for _, op := range ops {
+		b.WriteString("\n")
+		if op.summary != "" {
+			fmt.Fprintf(&b, "  /** %s */\n", strings.ReplaceAll(op.summary, "*/", "*\\/"))
+		}
+
+		var args []string
+		for _, p := range op.pathParams {
+			args = append(args, fmt.Sprintf("%s: %s", tsName(p.Name), tsType(p.Schema)))
+		}
+		body := "undefined"
+		if op.body != nil {
+			body = "body"
+			if op.bodyRequired {
+				args = append(args, "body: "+tsType(op.body))
+			} else {
+				args = append(args, "body?: "+tsType(op.body))
+			}
+		}
+		query := "{}"
+		if len(op.queryParams) > 0 {
+			query = "query"
+			var fields []string
+			optional := true
+			for _, p := range op.queryParams {
+				name := strconv.Quote(p.Name)
+				if isIdentifier(p.Name) {
+					name = p.Name
+				}
+				if !p.Required {
+					name += "?"
+				}
+				optional = optional && !p.Required
+				fields = append(fields, fmt.Sprintf("%s: %s", name, tsType(p.Schema)))
+			}
+			arg := fmt.Sprintf("query: { %s }", strings.Join(fields, "; "))
+			if optional {
+				arg += " = {}"
+			}
+			args = append(args, arg)
+		}
+
+		result := tsType(op.result)
+		fmt.Fprintf(&b, "  %s(%s): Promise<%s> {\n", camel(op.name), strings.Join(args, ", "), result)
+		fmt.Fprintf(&b, "    return this.request<%s>(%s, %s, %s, %s);\n", result, strconv.Quote(op.method), tsPath(op.segments), query, body)
+		b.WriteString("  }\n")
+	}
+	b.WriteString(typeScriptRequest)
+	return b.String()
+}
+
+// tsReserved are the reserved words of TypeScript which can't name a parameter
+var tsReserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true, "import": true, "in": true,
+	"instanceof": true, "new": true, "null": true, "return": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"body": true, "query": true,
+}
+
+// tsName is the name of the parameter of a method for a path parameter, e.g. userId for user_id
+func tsName(name string) string {
+	name = camel(name)
+	if tsReserved[name] {
+		name += "_"
+	}
+	return name
+}
+
+// tsPath is a template literal of the path of an operation
+func tsPath(segments []segment) string {
+	path := "`"
+	for _, s := range segments {
+		switch {
+		case s.param == "":
+			path += strings.NewReplacer("`", "\\`", "${", "\\${").Replace(s.text)
+		case s.rest:
+			path += fmt.Sprintf("${encodeRest(%s)}", tsName(s.param))
+		default:
+			path += fmt.Sprintf("${encodeSegment(%s)}", tsName(s.param))
+		}
+	}
+	return path + "`"
+}
+
+func tsProperty(s *Schema, property string) string {
+	name := strconv.Quote(property)
+	if isIdentifier(property) {
+		name = property
+	}
+	if !isRequired(s, property) {
+		name += "?"
+	}
+	return name
+}
+
+func tsType(s *Schema) string {
+	if s == nil {
+		return "unknown"
+	}
+	t := tsBaseType(s)
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func tsBaseType(s *Schema) string {
+	switch {
+	case s.Ref != "":
+		return refName(s.Ref)
+	case len(s.Enum) > 0:
+		return strings.Join(enumValues(s), " | ")
+	case len(s.Properties) > 0:
+		var fields []string
+		for _, property := range sortedKeys(s.Properties) {
+			fields = append(fields, fmt.Sprintf("%s: %s", tsProperty(s, property), tsType(s.Properties[property])))
+		}
+		return fmt.Sprintf("{ %s }", strings.Join(fields, "; "))
+	}
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := tsType(s.Items)
+		if strings.Contains(item, " ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		return "Record<string, unknown>"
+	default:
+		return "unknown"
+	}
+}
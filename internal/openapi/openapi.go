@@ -0,0 +1,207 @@
+// Package openapi scaffolds OpenAPI specs from the public routes of micros, serves them with Swagger UI under space
+// dev and generates typed clients from them
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deta/space/shared"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName of the spec in the src of a micro
+	FileName = "openapi.yaml"
+	// Version of the OpenAPI specification of the scaffolded specs
+	Version = "3.0.3"
+)
+
+// ErrNoPublicRoutes is returned by Scaffold for micros without public routes
+var ErrNoPublicRoutes = errors.New("no public routes")
+
+// Document is the part of an OpenAPI document which is scaffolded and read to generate clients
+type Document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Info       Info                 `yaml:"info"`
+	Servers    []Server             `yaml:"servers"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components *Components          `yaml:"components,omitempty"`
+}
+
+type Info struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+type PathItem struct {
+	Get    *Operation `yaml:"get,omitempty"`
+	Post   *Operation `yaml:"post,omitempty"`
+	Put    *Operation `yaml:"put,omitempty"`
+	Patch  *Operation `yaml:"patch,omitempty"`
+	Delete *Operation `yaml:"delete,omitempty"`
+}
+
+// Operations returns the operations of the path keyed by their method in upper case, e.g. GET
+func (p *PathItem) Operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for method, op := range map[string]*Operation{"GET": p.Get, "POST": p.Post, "PUT": p.Put, "PATCH": p.Patch, "DELETE": p.Delete} {
+		if op != nil {
+			operations[method] = op
+		}
+	}
+	return operations
+}
+
+type Operation struct {
+	Summary     string               `yaml:"summary"`
+	OperationID string               `yaml:"operationId"`
+	Parameters  []*Parameter         `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Required    bool    `yaml:"required"`
+	Description string  `yaml:"description,omitempty"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `yaml:"required,omitempty"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+type Response struct {
+	Description string                `yaml:"description"`
+	Content     map[string]*MediaType `yaml:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `yaml:"schemas,omitempty"`
+}
+
+// Schema is the part of a JSON schema which is turned into types of the clients
+type Schema struct {
+	Ref        string             `yaml:"$ref,omitempty"`
+	Type       string             `yaml:"type,omitempty"`
+	Format     string             `yaml:"format,omitempty"`
+	Nullable   bool               `yaml:"nullable,omitempty"`
+	Enum       []string           `yaml:"enum,omitempty"`
+	Items      *Schema            `yaml:"items,omitempty"`
+	Properties map[string]*Schema `yaml:"properties,omitempty"`
+	Required   []string           `yaml:"required,omitempty"`
+}
+
+// Path converts a public route of the Spacefile to a path template: a * segment becomes a parameter, a trailing one
+// matches the rest of the path
+func Path(route string) (string, []*Parameter) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	var params []*Parameter
+	for i, segment := range segments {
+		if !strings.Contains(segment, "*") {
+			continue
+		}
+		p := &Parameter{Name: fmt.Sprintf("param%d", len(params)+1), In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		if i == len(segments)-1 && segment == "*" {
+			p.Name, p.Description = "path", "rest of the path, may contain slashes"
+		}
+		segments[i] = "{" + p.Name + "}"
+		params = append(params, p)
+	}
+	return "/" + strings.Join(segments, "/"), params
+}
+
+// Scaffold returns a spec with a GET operation for every public route of the micro, to be filled in by hand
+func Scaffold(micro *shared.Micro) ([]byte, error) {
+	doc, err := fromRoutes(micro)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Load returns the spec of the micro, or the document scaffolded from its public routes if it has none
+func Load(projectDir string, micro *shared.Micro) (*Document, error) {
+	content, err := os.ReadFile(SpecPath(projectDir, micro))
+	if errors.Is(err, os.ErrNotExist) {
+		doc, err := fromRoutes(micro)
+		if err != nil {
+			return nil, err
+		}
+		// the summaries of the scaffold are placeholders to be filled in by hand
+		for _, item := range doc.Paths {
+			item.Get.Summary = ""
+		}
+		return doc, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid %s of micro %s: %w", FileName, micro.Name, err)
+	}
+	return &doc, nil
+}
+
+// fromRoutes returns a document with a GET operation for every public route of the micro
+func fromRoutes(micro *shared.Micro) (*Document, error) {
+	routes := micro.PublicRoutes
+	if micro.Public {
+		routes = []string{"/*"}
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("micro %s has %w, add public_routes to the Spacefile", micro.Name, ErrNoPublicRoutes)
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: micro.Name, Version: "0.1.0", Description: fmt.Sprintf("Public API of the micro %s", micro.Name)},
+		Servers: []Server{{URL: micro.Route()}},
+		Paths:   make(map[string]*PathItem),
+	}
+	for _, route := range routes {
+		path, params := Path(route)
+		doc.Paths[path] = &PathItem{Get: &Operation{
+			Summary:     "TODO: describe GET " + path,
+			OperationID: operationID("GET", path),
+			Parameters:  params,
+			Responses:   map[string]*Response{"200": {Description: "OK"}},
+		}}
+	}
+	return doc, nil
+}
+
+// operationID is a camel case id of the operation, e.g. getWebhooksPath for GET /webhooks/{path}
+func operationID(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	if id == strings.ToLower(method) {
+		id += "Root"
+	}
+	return id
+}
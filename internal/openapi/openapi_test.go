@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deta/space/shared"
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+)
+
+func TestPath(t *testing.T) {
+	cases := []struct {
+		route    string
+		expected string
+		params   []string
+	}{
+		{route: "/health", expected: "/health"},
+		{route: "/webhooks/*", expected: "/webhooks/{path}", params: []string{"path"}},
+		{route: "/users/*/avatar", expected: "/users/{param1}/avatar", params: []string{"param1"}},
+		{route: "/files/*/v*", expected: "/files/{param1}/{param2}", params: []string{"param1", "param2"}},
+		{route: "/*", expected: "/{path}", params: []string{"path"}},
+	}
+
+	for _, c := range cases {
+		path, params := Path(c.route)
+		assert.Equal(t, path, c.expected, c.route)
+		var names []string
+		for _, p := range params {
+			names = append(names, p.Name)
+		}
+		assert.DeepEqual(t, names, c.params)
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	micro := &shared.Micro{Name: "api", Path: "backend", PublicRoutes: []string{"/health", "/webhooks/*"}}
+	content, err := Scaffold(micro)
+	assert.NilError(t, err)
+
+	var doc Document
+	assert.NilError(t, yaml.Unmarshal(content, &doc))
+	assert.Equal(t, doc.OpenAPI, Version)
+	assert.Equal(t, doc.Servers[0].URL, "/backend")
+	assert.Equal(t, len(doc.Paths), 2)
+	assert.Equal(t, doc.Paths["/health"].Get.OperationID, "getHealth")
+	assert.Equal(t, doc.Paths["/webhooks/{path}"].Get.OperationID, "getWebhooksPath")
+	assert.Equal(t, doc.Paths["/webhooks/{path}"].Get.Parameters[0].In, "path")
+
+	_, err = Scaffold(&shared.Micro{Name: "frontend"})
+	assert.Assert(t, errors.Is(err, ErrNoPublicRoutes))
+
+	content, err = Scaffold(&shared.Micro{Name: "frontend", Primary: true, Public: true})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), "/{path}:"), string(content))
+}
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	micros := []*shared.Micro{{Name: "api", Src: "api"}, {Name: "frontend", Src: "frontend"}}
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "api"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "api", FileName), []byte("openapi: 3.0.3\n"), 0644))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "micro") })
+	server := httptest.NewServer(Handler(next, dir, micros))
+	defer server.Close()
+
+	cases := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{path: "/", status: 200, contains: "micro"},
+		{path: UIPrefix, status: 200, contains: `<a href="api">api</a>`},
+		{path: UIPrefix + "api", status: 200, contains: `url: "\/__space\/openapi\/api\/openapi.yaml"`},
+		{path: UIPrefix + "api/" + FileName, status: 200, contains: "openapi: 3.0.3"},
+		{path: UIPrefix + "frontend/" + FileName, status: 404},
+		{path: UIPrefix + "worker", status: 404},
+	}
+
+	for _, c := range cases {
+		res, err := http.Get(server.URL + c.path)
+		assert.NilError(t, err, c.path)
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, res.StatusCode, c.status, c.path)
+		assert.Assert(t, strings.Contains(string(body), c.contains), "%s: %s", c.path, body)
+	}
+}
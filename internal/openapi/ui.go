@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deta/space/shared"
+)
+
+// UIPrefix is the path of Swagger UI on the proxy of space dev
+const UIPrefix = "/__space/openapi/"
+
+// swaggerUIDist is where the browser loads Swagger UI from
+const swaggerUIDist = "https://unpkg.com/swagger-ui-dist@5"
+
+var indexPage = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>API docs</title></head>
+<body>
+<h1>API docs</h1>
+{{if .}}<ul>{{range .}}<li><a href="{{.}}">{{.}}</a></li>{{end}}</ul>
+{{else}}<p>No micro has an ` + FileName + `, create one with <code>space openapi init &lt;micro&gt;</code>.</p>
+{{end}}</body>
+</html>
+`))
+
+var uiPage = template.Must(template.New("ui").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Micro}} API</title>
+<link rel="stylesheet" href="{{.Dist}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.Dist}}/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: "{{.Spec}}", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`))
+
+// SpecPath returns the path of the spec of the micro
+func SpecPath(projectDir string, micro *shared.Micro) string {
+	return filepath.Join(projectDir, micro.Src, FileName)
+}
+
+// WithSpec returns the micros which have a spec
+func WithSpec(projectDir string, micros []*shared.Micro) []*shared.Micro {
+	var found []*shared.Micro
+	for _, micro := range micros {
+		if _, err := os.Stat(SpecPath(projectDir, micro)); err == nil {
+			found = append(found, micro)
+		}
+	}
+	return found
+}
+
+// Handler serves Swagger UI for the specs of the micros under UIPrefix and passes every other request to next. The
+// specs are read on every request, so that a reload shows their changes.
+func Handler(next http.Handler, projectDir string, micros []*shared.Micro) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, UIPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name, file, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, UIPrefix), "/")
+		if name == "" {
+			var names []string
+			for _, micro := range WithSpec(projectDir, micros) {
+				names = append(names, micro.Name)
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			indexPage.Execute(w, names)
+			return
+		}
+
+		var micro *shared.Micro
+		for _, m := range micros {
+			if m.Name == name {
+				micro = m
+			}
+		}
+		if micro == nil {
+			http.NotFound(w, r)
+			return
+		}
+		switch file {
+		case "":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			uiPage.Execute(w, map[string]string{"Micro": name, "Dist": swaggerUIDist, "Spec": UIPrefix + name + "/" + FileName})
+		case FileName:
+			content, err := os.ReadFile(SpecPath(projectDir, micro))
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package ping
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Target is an endpoint whose latency is measured
+type Target struct {
+	Name string
+	URL  string
+}
+
+// Result holds the latencies measured for a target
+type Result struct {
+	Target  Target
+	Samples []time.Duration
+	Failed  int
+	Err     error
+}
+
+// Stats summarizes the samples of a result
+type Stats struct {
+	Min time.Duration
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// Prober sends requests to targets and measures the time until the response headers arrive
+type Prober struct {
+	Count   int
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Probe measures the latency of a target, the first request is used to warm up
+// the connection (dns, tcp and tls handshakes) and is not part of the samples
+func (p *Prober) Probe(ctx context.Context, t Target) *Result {
+	res := &Result{Target: t}
+	if err := p.do(ctx, t.URL, nil); err != nil {
+		res.Err = err
+		return res
+	}
+
+	for i := 0; i < p.Count; i++ {
+		var d time.Duration
+		if err := p.do(ctx, t.URL, &d); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			res.Failed++
+			continue
+		}
+		res.Samples = append(res.Samples, d)
+	}
+	return res
+}
+
+func (p *Prober) do(ctx context.Context, url string, d *time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if d != nil {
+		*d = time.Since(start)
+	}
+
+	// any response, even an error status, means the endpoint is reachable
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Stats computes the percentiles of the samples, returns false if there are no samples
+func (r *Result) Stats() (Stats, bool) {
+	if len(r.Samples) == 0 {
+		return Stats{}, false
+	}
+
+	sorted := make([]time.Duration, len(r.Samples))
+	copy(sorted, r.Samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return Stats{
+		Min: sorted[0],
+		P50: Percentile(sorted, 50),
+		P90: Percentile(sorted, 90),
+		P99: Percentile(sorted, 99),
+		Max: sorted[len(sorted)-1],
+	}, true
+}
+
+// Percentile returns the p-th percentile of sorted samples using the nearest-rank method
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted)) + 0.999999)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
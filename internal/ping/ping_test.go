@@ -0,0 +1,48 @@
+package ping
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	samples := make([]time.Duration, 0, 10)
+	for i := 1; i <= 10; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	cases := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{p: 0, expected: 1 * time.Millisecond},
+		{p: 50, expected: 5 * time.Millisecond},
+		{p: 90, expected: 9 * time.Millisecond},
+		{p: 99, expected: 10 * time.Millisecond},
+		{p: 100, expected: 10 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("p%v", c.p), func(t *testing.T) {
+			assert.Equal(t, Percentile(samples, c.p), c.expected)
+		})
+	}
+
+	assert.Equal(t, Percentile(nil, 50), time.Duration(0))
+}
+
+func TestStats(t *testing.T) {
+	r := &Result{Samples: []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond}}
+
+	stats, ok := r.Stats()
+	assert.Assert(t, ok)
+	assert.Equal(t, stats.Min, 10*time.Millisecond)
+	assert.Equal(t, stats.P50, 20*time.Millisecond)
+	assert.Equal(t, stats.Max, 30*time.Millisecond)
+
+	_, ok = (&Result{}).Stats()
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,212 @@
+// Package pipeline describes the stages a release goes through, from the revision to its Discovery listing, for
+// space release explain
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// State of a stage of the pipeline
+type State string
+
+const (
+	StateDone    State = "done"
+	StateRunning State = "running"
+	StateFailed  State = "failed"
+	// StatePending stages wait for an earlier stage
+	StatePending State = "pending"
+	// StateSkipped stages don't apply to the release, e.g. the listing of an unlisted release
+	StateSkipped State = "skipped"
+	// StateUnknown stages couldn't be fetched
+	StateUnknown State = "unknown"
+)
+
+// States in the order of the legend
+var States = []State{StateDone, StateRunning, StateFailed, StatePending, StateSkipped, StateUnknown}
+
+// Stage of the pipeline
+type Stage struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	State  State  `json:"state"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Edge is an edge serving the release
+type Edge struct {
+	Location string
+	Status   string
+}
+
+// Release is what's known about a release, empty fields weren't fetched
+type Release struct {
+	RevisionID string
+	// RevisionTag is empty if the revision couldn't be fetched
+	RevisionTag     string
+	BuildStartedAt  string
+	BuildFinishedAt string
+	// PromotionStatus is empty if the promotion couldn't be fetched
+	PromotionStatus string
+	// PromotionAt is when the promotion reached its status
+	PromotionAt string
+	// Edges are only known for the latest release
+	Edges  []Edge
+	Listed bool
+}
+
+// StateOf maps a status of the api to a state, e.g. complete is done
+func StateOf(status string) State {
+	s := strings.ToLower(status)
+	switch {
+	case s == "":
+		return StateUnknown
+	case s == "complete" || s == "completed" || s == "active" || s == "live" || s == "ready" || s == "deployed":
+		return StateDone
+	case strings.Contains(s, "fail") || strings.Contains(s, "error"):
+		return StateFailed
+	}
+	return StateRunning
+}
+
+// Explain returns the stages of the release: revision, build, promotion, edges and listing. A stage after one which
+// isn't done is pending.
+func Explain(r *Release) []*Stage {
+	revision := &Stage{ID: "revision", Name: "Revision", State: StateUnknown}
+	if r.RevisionID != "" {
+		revision.State, revision.Detail = StateDone, r.RevisionID
+		if r.RevisionTag != "" {
+			revision.Detail = fmt.Sprintf("%s (%s)", r.RevisionTag, r.RevisionID)
+		}
+	}
+
+	build := &Stage{ID: "build", Name: "Build", State: StateUnknown}
+	switch {
+	case r.BuildFinishedAt != "":
+		build.State, build.Detail = StateDone, "finished "+r.BuildFinishedAt
+	case r.BuildStartedAt != "":
+		build.State, build.Detail = StateRunning, "started "+r.BuildStartedAt
+	case r.RevisionTag != "":
+		// a revision is only created by a successful build
+		build.State = StateDone
+	}
+
+	promotion := &Stage{ID: "promotion", Name: "Promotion", State: StateOf(r.PromotionStatus), Detail: r.PromotionStatus}
+	if r.PromotionAt != "" {
+		promotion.Detail += " " + r.PromotionAt
+	}
+
+	edges := &Stage{ID: "edges", Name: "Edges", State: StateUnknown, Detail: "only known for the latest release"}
+	if len(r.Edges) > 0 {
+		var serving []string
+		var failed, running bool
+		for _, e := range r.Edges {
+			switch StateOf(e.Status) {
+			case StateDone:
+				serving = append(serving, e.Location)
+			case StateFailed:
+				failed = true
+			default:
+				running = true
+			}
+		}
+		switch {
+		case failed:
+			edges.State = StateFailed
+		case running:
+			edges.State = StateRunning
+		default:
+			edges.State = StateDone
+		}
+		edges.Detail = fmt.Sprintf("%d/%d serving", len(serving), len(r.Edges))
+		if len(serving) > 0 {
+			edges.Detail += ": " + strings.Join(serving, ", ")
+		}
+	}
+
+	listing := &Stage{ID: "listing", Name: "Listing", State: StateSkipped, Detail: "unlisted, installable with its link"}
+	if r.Listed {
+		listing.State, listing.Detail = StateDone, "listed on Discovery"
+	}
+	if promotion.State != StateDone && promotion.State != StateUnknown {
+		listing.State = StatePending
+	}
+
+	stages := []*Stage{revision, build, promotion, edges, listing}
+	for i := 1; i < len(stages); i++ {
+		if prev := stages[i-1].State; (prev == StateRunning || prev == StateFailed || prev == StatePending) && stages[i].State != StateSkipped {
+			stages[i].State = StatePending
+		}
+	}
+	return stages
+}
+
+// symbols of the states in the terminal art
+var symbols = map[State]string{
+	StateDone:    "●",
+	StateRunning: "◐",
+	StateFailed:  "✗",
+	StatePending: "○",
+	StateSkipped: "–",
+	StateUnknown: "?",
+}
+
+// Symbol returns the symbol of the state in the terminal art
+func Symbol(state State) string {
+	return symbols[state]
+}
+
+// Render draws the stages top to bottom, color styles the symbol of a stage by its state
+func Render(stages []*Stage, color func(State, string) string) string {
+	width := 0
+	for _, s := range stages {
+		if len(s.Name) > width {
+			width = len(s.Name)
+		}
+	}
+
+	var b strings.Builder
+	for i, s := range stages {
+		if i > 0 {
+			b.WriteString("│\n")
+		}
+		fmt.Fprintf(&b, "%s %-*s  %s", color(s.State, Symbol(s.State)), width, s.Name, s.State)
+		if s.Detail != "" {
+			fmt.Fprintf(&b, "  %s", s.Detail)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// mermaidStyles color the nodes of the mermaid chart by their state
+var mermaidStyles = map[State]string{
+	StateDone:    "fill:#d3f9d8,stroke:#2b8a3e",
+	StateRunning: "fill:#fff3bf,stroke:#f08c00",
+	StateFailed:  "fill:#ffe3e3,stroke:#c92a2a",
+	StatePending: "fill:#f1f3f5,stroke:#868e96",
+	StateSkipped: "fill:#f8f9fa,stroke:#ced4da,stroke-dasharray:4",
+	StateUnknown: "fill:#f8f9fa,stroke:#adb5bd",
+}
+
+// Mermaid returns the stages as a mermaid flowchart, e.g. for docs
+func Mermaid(title string, stages []*Stage) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", title)
+	b.WriteString("---\nflowchart LR\n")
+	for i, s := range stages {
+		label := fmt.Sprintf("%s<br/><i>%s</i>", s.Name, s.State)
+		if s.Detail != "" {
+			label += "<br/>" + strings.ReplaceAll(s.Detail, `"`, "#quot;")
+		}
+		fmt.Fprintf(&b, "    %s[\"%s\"]:::%s\n", s.ID, label, s.State)
+		if i > 0 {
+			fmt.Fprintf(&b, "    %s --> %s\n", stages[i-1].ID, s.ID)
+		}
+	}
+	for _, state := range States {
+		fmt.Fprintf(&b, "    classDef %s %s\n", state, mermaidStyles[state])
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func states(stages []*Stage) []State {
+	result := make([]State, len(stages))
+	for i, s := range stages {
+		result[i] = s.State
+	}
+	return result
+}
+
+func TestExplain(t *testing.T) {
+	cases := []struct {
+		name     string
+		release  *Release
+		expected []State
+	}{
+		{
+			name:     "live and listed",
+			release:  &Release{RevisionID: "r1", RevisionTag: "v1", PromotionStatus: "complete", Edges: []Edge{{Location: "Frankfurt", Status: "active"}}, Listed: true},
+			expected: []State{StateDone, StateDone, StateDone, StateDone, StateDone},
+		},
+		{
+			name:     "unlisted older release",
+			release:  &Release{RevisionID: "r1", BuildFinishedAt: "2023-01-01", PromotionStatus: "complete"},
+			expected: []State{StateDone, StateDone, StateDone, StateUnknown, StateSkipped},
+		},
+		{
+			name:     "promoting",
+			release:  &Release{RevisionID: "r1", RevisionTag: "v1", PromotionStatus: "deploying", Listed: true},
+			expected: []State{StateDone, StateDone, StateRunning, StatePending, StatePending},
+		},
+		{
+			name:     "promotion failed",
+			release:  &Release{RevisionID: "r1", RevisionTag: "v1", PromotionStatus: "failed"},
+			expected: []State{StateDone, StateDone, StateFailed, StatePending, StatePending},
+		},
+		{
+			name:     "edge failed",
+			release:  &Release{RevisionID: "r1", RevisionTag: "v1", PromotionStatus: "complete", Edges: []Edge{{Location: "Frankfurt", Status: "active"}, {Location: "Virginia", Status: "error"}}},
+			expected: []State{StateDone, StateDone, StateDone, StateFailed, StateSkipped},
+		},
+		{
+			name:     "nothing fetched",
+			release:  &Release{},
+			expected: []State{StateUnknown, StateUnknown, StateUnknown, StateUnknown, StateSkipped},
+		},
+	}
+
+	for _, c := range cases {
+		assert.DeepEqual(t, states(Explain(c.release)), c.expected)
+	}
+}
+
+func TestRender(t *testing.T) {
+	stages := Explain(&Release{RevisionID: "r1", RevisionTag: "v1", PromotionStatus: "complete", Edges: []Edge{{Location: "Frankfurt", Status: "active"}, {Location: "Virginia", Status: "syncing"}}})
+	art := Render(stages, func(_ State, s string) string { return s })
+
+	lines := strings.Split(strings.TrimSpace(art), "\n")
+	assert.Equal(t, len(lines), 9)
+	assert.Equal(t, lines[0], "● Revision   done  v1 (r1)")
+	assert.Equal(t, lines[1], "│")
+	assert.Equal(t, lines[6], "◐ Edges      running  1/2 serving: Frankfurt")
+	assert.Equal(t, lines[8], "– Listing    skipped  unlisted, installable with its link")
+}
+
+func TestMermaid(t *testing.T) {
+	chart := Mermaid("my-app 1.0.0", Explain(&Release{RevisionID: "r1", PromotionStatus: "complete", Listed: true}))
+
+	assert.Assert(t, strings.HasPrefix(chart, "---\ntitle: my-app 1.0.0\n---\nflowchart LR\n"), chart)
+	assert.Assert(t, strings.Contains(chart, `revision["Revision<br/><i>done</i><br/>r1"]:::done`), chart)
+	assert.Assert(t, strings.Contains(chart, "promotion --> edges"), chart)
+	assert.Assert(t, strings.Contains(chart, "classDef failed "), chart)
+}
@@ -0,0 +1,92 @@
+// Package preview names the projects used for preview deployments of branches
+package preview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix of the names of all preview projects
+	Prefix = "pv-"
+
+	// RefLabel is the label of the revisions pushed by space preview create holding the branch of the preview
+	RefLabel = "preview_ref"
+	// ScopeLabel is the label holding the scope the name of the preview was derived from
+	ScopeLabel = "preview_scope"
+
+	// project names are at most 16 characters long: prefix, slug, dash and hash
+	maxSlugLength = 6
+	hashLength    = 6
+)
+
+var (
+	nameReg    = regexp.MustCompile(`^pv-(?:[a-z0-9]+-)?[0-9a-f]{6}$`)
+	nonSlugReg = regexp.MustCompile(`[^a-z0-9]+`)
+	ageReg     = regexp.MustCompile(`^(\d+)([dw])$`)
+)
+
+// Name returns the project name of the preview of ref, the scope, e.g. the id of the main project,
+// keeps previews of branches with the same name in different projects apart
+func Name(scope string, ref string) string {
+	ref = strings.TrimPrefix(ref, "refs/heads/")
+
+	sum := sha256.Sum256([]byte(scope + "\x00" + ref))
+	hash := hex.EncodeToString(sum[:])[:hashLength]
+
+	slug := nonSlugReg.ReplaceAllString(strings.ToLower(ref), "")
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	if slug == "" {
+		return Prefix + hash
+	}
+	return fmt.Sprintf("%s%s-%s", Prefix, slug, hash)
+}
+
+// IsPreview reports if name is the name of a preview project
+func IsPreview(name string) bool {
+	return nameReg.MatchString(name)
+}
+
+// Labels returns the labels of the revisions of the preview of ref which mark its project as a preview
+func Labels(scope string, ref string) map[string]string {
+	labels := map[string]string{RefLabel: ref}
+	if scope != "" {
+		labels[ScopeLabel] = scope
+	}
+	return labels
+}
+
+// IsPreviewProject reports if the project with the name was created by space preview create, which is the case if
+// the labels of its latest revision derive its name. A project named like a preview by hand isn't one.
+func IsPreviewProject(name string, revisionLabels map[string]string) bool {
+	ref := revisionLabels[RefLabel]
+	return ref != "" && IsPreview(name) && Name(revisionLabels[ScopeLabel], ref) == name
+}
+
+// ParseAge parses durations like 7d or 2w in addition to the ones accepted by time.ParseDuration
+func ParseAge(s string) (time.Duration, error) {
+	if matches := ageReg.FindStringSubmatch(s); len(matches) == 3 {
+		n, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		days := n
+		if matches[2] == "w" {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q, use e.g. 12h, 7d or 2w", s)
+	}
+	return d, nil
+}
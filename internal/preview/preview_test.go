@@ -0,0 +1,90 @@
+package preview
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestName(t *testing.T) {
+	cases := []struct {
+		scope string
+		ref   string
+	}{
+		{scope: "", ref: "main"},
+		{scope: "a1b2c3", ref: "feature/Add-Login-Page"},
+		{scope: "", ref: "refs/heads/fix-123"},
+		{scope: "", ref: "___"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			name := Name(c.scope, c.ref)
+			assert.Assert(t, len(name) >= 4 && len(name) <= 16, name)
+			assert.Assert(t, IsPreview(name), name)
+			assert.Equal(t, name, Name(c.scope, c.ref))
+		})
+	}
+
+	assert.Equal(t, Name("", "refs/heads/main"), Name("", "main"))
+	assert.Assert(t, Name("a", "main") != Name("b", "main"))
+	assert.Assert(t, Name("", "feature-a") != Name("", "feature-b"))
+}
+
+func TestIsPreview(t *testing.T) {
+	assert.Assert(t, IsPreview("pv-main-0a1b2c"))
+	assert.Assert(t, IsPreview("pv-0a1b2c"))
+	assert.Assert(t, !IsPreview("pv-my-app"))
+	assert.Assert(t, !IsPreview("my-app"))
+}
+
+func TestIsPreviewProject(t *testing.T) {
+	name := Name("a1b2c3", "feature/login")
+
+	cases := []struct {
+		name     string
+		project  string
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "labelled", project: name, labels: Labels("a1b2c3", "feature/login"), expected: true},
+		{name: "without scope", project: Name("", "main"), labels: Labels("", "main"), expected: true},
+		{name: "no labels", project: name, labels: nil, expected: false},
+		{name: "other branch", project: name, labels: Labels("a1b2c3", "main"), expected: false},
+		{name: "other scope", project: name, labels: Labels("d4e5f6", "feature/login"), expected: false},
+		{name: "named like a preview", project: "pv-main-0a1b2c", labels: map[string]string{"git_sha": "abc"}, expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, IsPreviewProject(c.project, c.labels), c.expected)
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	cases := []struct {
+		age      string
+		expected time.Duration
+		err      bool
+	}{
+		{age: "7d", expected: 7 * 24 * time.Hour},
+		{age: "2w", expected: 14 * 24 * time.Hour},
+		{age: "12h", expected: 12 * time.Hour},
+		{age: "7 days", err: true},
+		{age: "-1h", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.age, func(t *testing.T) {
+			d, err := ParseAge(c.age)
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, d, c.expected)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package profile measures where the time of a command goes, e.g. API calls, archiving, uploads and waiting on builds
+package profile
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Stat is the time spent in a phase of a command
+type Stat struct {
+	Name  string
+	Count int
+	Total time.Duration
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	started time.Time
+	active  int
+	stats   = map[string]*Stat{}
+	order   []string
+)
+
+// Enable starts measuring, the wall time of the report starts now
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+	started = time.Now()
+}
+
+// Reset stops measuring and forgets the measured phases, e.g. before the next command of an alias
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = false
+	active = 0
+	stats = map[string]*Stat{}
+	order = nil
+}
+
+// Enabled reports if the phases are measured
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Start measures a phase of a command until the returned func is called
+func Start(name string) func() {
+	return start(name, false)
+}
+
+// Track measures generic work like API requests until the returned func is called. It is not measured if a phase is
+// running, so that e.g. the request of an upload counts for the upload phase.
+func Track(name string) func() {
+	return start(name, true)
+}
+
+func start(name string, tracked bool) func() {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled || tracked && active > 0 {
+		return func() {}
+	}
+	if !tracked {
+		active++
+	}
+
+	begin := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			add(name, time.Since(begin), !tracked)
+		})
+	}
+}
+
+func add(name string, d time.Duration, phase bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if phase {
+		active--
+	}
+	s, ok := stats[name]
+	if !ok {
+		s = &Stat{Name: name}
+		stats[name] = s
+		order = append(order, name)
+	}
+	s.Count++
+	s.Total += d
+}
+
+// Stats returns the measured phases in the order they were first started
+func Stats() []Stat {
+	mu.Lock()
+	defer mu.Unlock()
+	result := make([]Stat, 0, len(order))
+	for _, name := range order {
+		result = append(result, *stats[name])
+	}
+	return result
+}
+
+// Report writes a breakdown of the measured phases, the time not spent in any of them is shown as other
+func Report(w io.Writer) {
+	mu.Lock()
+	wall := time.Since(started)
+	mu.Unlock()
+
+	stats := Stats()
+	var measured time.Duration
+	for _, s := range stats {
+		measured += s.Total
+	}
+	if other := wall - measured; other > 0 {
+		stats = append(stats, Stat{Name: "other", Total: other})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tCALLS\tTIME\tSHARE")
+	for _, s := range stats {
+		calls := "-"
+		if s.Count > 0 {
+			calls = fmt.Sprint(s.Count)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f%%\n", s.Name, calls, s.Total.Round(time.Millisecond), 100*float64(s.Total)/float64(wall))
+	}
+	fmt.Fprintf(tw, "total\t\t%s\t\n", wall.Round(time.Millisecond))
+	tw.Flush()
+}
+
+// ServePprof serves the pprof endpoints on addr in the background and returns the address it listens on, see
+// loopbackAddr
+func ServePprof(addr string) (string, error) {
+	addr, err := loopbackAddr(addr)
+	if err != nil {
+		return "", err
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	go http.Serve(l, mux)
+
+	return l.Addr().String(), nil
+}
+
+// loopbackAddr returns addr with 127.0.0.1 as host if it has none, the pprof endpoints expose the memory and command
+// line of the process, so other hosts than loopback addresses are rejected
+func loopbackAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", fmt.Errorf("pprof is only served on loopback addresses, %s isn't one", host)
+	}
+	return addr, nil
+}
@@ -0,0 +1,72 @@
+package profile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProfile(t *testing.T) {
+	Start("disabled")()
+	assert.Equal(t, len(Stats()), 0)
+
+	Enable()
+	endUpload := Start("upload")
+	// requests during a phase count for the phase
+	Track("api")()
+	time.Sleep(5 * time.Millisecond)
+	endUpload()
+	endUpload()
+
+	Track("api")()
+	Track("api")()
+
+	stats := Stats()
+	assert.Equal(t, len(stats), 2)
+	assert.Equal(t, stats[0].Name, "upload")
+	assert.Equal(t, stats[0].Count, 1)
+	assert.Assert(t, stats[0].Total >= 5*time.Millisecond)
+	assert.Equal(t, stats[1].Name, "api")
+	assert.Equal(t, stats[1].Count, 2)
+
+	var buf bytes.Buffer
+	Report(&buf)
+	for _, name := range []string{"PHASE", "upload", "api", "total"} {
+		assert.Assert(t, strings.Contains(buf.String(), name), buf.String())
+	}
+
+	Reset()
+	assert.Assert(t, !Enabled())
+	assert.Equal(t, len(Stats()), 0)
+}
+
+func TestLoopbackAddr(t *testing.T) {
+	cases := []struct {
+		addr     string
+		expected string
+		valid    bool
+	}{
+		{addr: ":6060", expected: "127.0.0.1:6060", valid: true},
+		{addr: "localhost:6060", expected: "localhost:6060", valid: true},
+		{addr: "127.0.0.1:0", expected: "127.0.0.1:0", valid: true},
+		{addr: "[::1]:6060", expected: "[::1]:6060", valid: true},
+		{addr: "0.0.0.0:6060"},
+		{addr: "example.com:6060"},
+		{addr: "6060"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.addr, func(t *testing.T) {
+			addr, err := loopbackAddr(c.addr)
+			if !c.valid {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, addr, c.expected)
+		})
+	}
+}
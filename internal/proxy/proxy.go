@@ -10,16 +10,21 @@ import (
 type ProxyRoute struct {
 	Prefix string   `json:"prefix"`
 	Target *url.URL `json:"target"`
+	// Middleware wraps the proxy of the route if it's set, it gets the requests with the prefix stripped
+	Middleware func(http.Handler) http.Handler `json:"-"`
 }
 
 type ReverseProxy struct {
-	prefixToProxy map[string]*httputil.ReverseProxy
+	prefixToProxy map[string]http.Handler
 }
 
 func NewReverseProxy(routes []ProxyRoute) *ReverseProxy {
-	prefixToProxy := make(map[string]*httputil.ReverseProxy)
+	prefixToProxy := make(map[string]http.Handler)
 	for _, route := range routes {
-		proxy := httputil.NewSingleHostReverseProxy(route.Target)
+		var proxy http.Handler = httputil.NewSingleHostReverseProxy(route.Target)
+		if route.Middleware != nil {
+			proxy = route.Middleware(proxy)
+		}
 		prefixToProxy[route.Prefix] = proxy
 	}
 
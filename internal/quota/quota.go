@@ -0,0 +1,103 @@
+// Package quota evaluates the usage of the resources of a space against the limits of the platform, so that commands
+// can warn before a quota is exhausted instead of failing halfway
+package quota
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deta/space/pkg/util/fs"
+)
+
+// Names of the resources with a quota
+const (
+	Projects     = "projects"
+	BuildsPerDay = "builds_per_day"
+	Storage      = "storage"
+	Bandwidth    = "bandwidth"
+)
+
+// UnitBytes is the unit of resources measured in bytes, other resources are counted
+const UnitBytes = "bytes"
+
+// WarnRatio is the share of a quota from which it's nearly exhausted
+const WarnRatio = 0.9
+
+// Level of the usage of a quota
+type Level int
+
+const (
+	OK Level = iota
+	NearlyExhausted
+	Exhausted
+)
+
+// Usage of a resource, a limit of 0 is unlimited
+type Usage struct {
+	Name  string
+	Unit  string
+	Used  int64
+	Limit int64
+}
+
+// Level returns how close the usage is to the limit
+func (u *Usage) Level() Level {
+	switch {
+	case u.Limit <= 0:
+		return OK
+	case u.Used >= u.Limit:
+		return Exhausted
+	case float64(u.Used) >= WarnRatio*float64(u.Limit):
+		return NearlyExhausted
+	}
+	return OK
+}
+
+// Label returns the name of the resource for humans, e.g. builds per day
+func (u *Usage) Label() string {
+	return strings.ReplaceAll(u.Name, "_", " ")
+}
+
+func (u *Usage) format(value int64) string {
+	if u.Unit == UnitBytes {
+		return fs.FormatSize(value)
+	}
+	return fmt.Sprint(value)
+}
+
+// String formats the usage, e.g. 9 of 10 or 1.5 GB of 2.0 GB
+func (u *Usage) String() string {
+	if u.Limit <= 0 {
+		return u.format(u.Used) + " (unlimited)"
+	}
+	return fmt.Sprintf("%s of %s", u.format(u.Used), u.format(u.Limit))
+}
+
+// Bar draws the usage as a bar of width characters
+func (u *Usage) Bar(width int) string {
+	filled := 0
+	if u.Limit > 0 {
+		filled = int(float64(width) * float64(u.Used) / float64(u.Limit))
+	}
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
+
+// Check returns the usages of the named resources which are nearly exhausted or exhausted
+func Check(usages []*Usage, names ...string) []*Usage {
+	var result []*Usage
+	for _, u := range usages {
+		if u.Level() == OK {
+			continue
+		}
+		for _, name := range names {
+			if u.Name == name {
+				result = append(result, u)
+				break
+			}
+		}
+	}
+	return result
+}
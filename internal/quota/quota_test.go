@@ -0,0 +1,48 @@
+package quota
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLevel(t *testing.T) {
+	cases := []struct {
+		used     int64
+		limit    int64
+		expected Level
+	}{
+		{used: 5, limit: 10, expected: OK},
+		{used: 9, limit: 10, expected: NearlyExhausted},
+		{used: 10, limit: 10, expected: Exhausted},
+		{used: 12, limit: 10, expected: Exhausted},
+		{used: 1000, limit: 0, expected: OK},
+	}
+
+	for _, c := range cases {
+		u := &Usage{Name: BuildsPerDay, Used: c.used, Limit: c.limit}
+		assert.Equal(t, u.Level(), c.expected, "%d of %d", c.used, c.limit)
+	}
+}
+
+func TestString(t *testing.T) {
+	storage := &Usage{Name: Storage, Unit: UnitBytes, Used: 1536 * 1024 * 1024, Limit: 2 * 1024 * 1024 * 1024}
+	assert.Equal(t, storage.String(), "1.5 GB of 2.0 GB")
+	assert.Equal(t, storage.Bar(8), "[######..]")
+
+	builds := &Usage{Name: BuildsPerDay, Used: 12, Limit: 10}
+	assert.Equal(t, builds.String(), "12 of 10")
+	assert.Equal(t, builds.Label(), "builds per day")
+	assert.Equal(t, builds.Bar(4), "[####]")
+}
+
+func TestCheck(t *testing.T) {
+	projects := &Usage{Name: Projects, Used: 10, Limit: 10}
+	builds := &Usage{Name: BuildsPerDay, Used: 19, Limit: 20}
+	storage := &Usage{Name: Storage, Used: 1, Limit: 20}
+	usages := []*Usage{projects, builds, storage}
+
+	assert.DeepEqual(t, Check(usages, BuildsPerDay, Storage), []*Usage{builds})
+	assert.DeepEqual(t, Check(usages, Projects), []*Usage{projects})
+	assert.Equal(t, len(Check(usages, Bandwidth)), 0)
+}
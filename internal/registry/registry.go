@@ -0,0 +1,399 @@
+// Package registry stores the credentials of private package registries and injects them into the builds of a push:
+// npm gets a generated .npmrc which reads the token of the host of the registry from a build arg, pip gets the index
+// with the credentials as a build arg. The tokens are kept in the keyring of the system like the access token.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/crypt"
+	"github.com/deta/space/internal/home"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// NPM registries are used by npm, yarn and pnpm through the .npmrc of a micro
+	NPM = "npm"
+	// Pip indexes are used by pip through PIP_EXTRA_INDEX_URL
+	Pip = "pip"
+
+	// DefaultNPMURL is the public npm registry
+	DefaultNPMURL = "https://registry.npmjs.org/"
+	// PipIndexEnv is the build arg holding the extra indexes of pip with their credentials
+	PipIndexEnv = "PIP_EXTRA_INDEX_URL"
+
+	// stateFile in the directory of the global state holds the registries, their tokens are only in the file if the
+	// keyring isn't available
+	stateFile = "space_registries"
+	fileMode  = 0600
+	// keyringUserPrefix is the prefix of the keys of the tokens in the keyring
+	keyringUserPrefix = "registry:"
+)
+
+var nonAlnumReg = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// manifestFiles of a micro reference the registries it reads from, e.g. with the urls of resolved packages
+var manifestFiles = map[string][]string{
+	NPM: {"package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml"},
+	Pip: {"requirements.txt", "requirements.in", "Pipfile", "Pipfile.lock", "pyproject.toml", "poetry.lock", "uv.lock"},
+}
+
+// Kinds of registries which are supported
+var Kinds = []string{NPM, Pip}
+
+// Registry is a private package registry and the credentials to read from it
+type Registry struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+	// Scope of the npm packages of the registry, e.g. @my-org, all packages are read from it without a scope
+	Scope string `json:"scope,omitempty"`
+	// Username of a pip index, the token is the password
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// storedRegistry is a registry in the state file, without its token if the token is in the keyring
+type storedRegistry struct {
+	*Registry
+	Keyring bool `json:"keyring,omitempty"`
+}
+
+// Host returns the host of the url of the registry, the tokens are bound to it
+func (r *Registry) Host() string {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return r.URL
+	}
+	return u.Host
+}
+
+// keyringUser is the key of the token of the registry in the keyring
+func (r *Registry) keyringUser() string {
+	return keyringUserPrefix + r.Kind + ":" + r.URL + ":" + r.Scope
+}
+
+// Validate checks the kind and url of the registry and fills in the default url of npm
+func (r *Registry) Validate() error {
+	switch r.Kind {
+	case NPM:
+		if r.URL == "" {
+			r.URL = DefaultNPMURL
+		}
+		if r.Scope != "" && !strings.HasPrefix(r.Scope, "@") {
+			r.Scope = "@" + r.Scope
+		}
+	case Pip:
+		if r.URL == "" {
+			return errors.New("pip needs the url of the index, e.g. https://pypi.example.com/simple")
+		}
+		if r.Scope != "" {
+			return errors.New("scopes are only supported by npm")
+		}
+	default:
+		return fmt.Errorf("unsupported registry %q, use one of %s", r.Kind, strings.Join(Kinds, ", "))
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid registry url %q", r.URL)
+	}
+	if u.User != nil {
+		return errors.New("the url must not contain credentials, use --token and --username")
+	}
+	if r.Token == "" {
+		return errors.New("empty token")
+	}
+	return nil
+}
+
+// Add adds the registry to the list, replacing a registry of the same kind, url and scope
+func Add(registries []*Registry, r *Registry) []*Registry {
+	for i, existing := range registries {
+		if existing.Kind == r.Kind && existing.URL == r.URL && existing.Scope == r.Scope {
+			registries[i] = r
+			return registries
+		}
+	}
+	return append(registries, r)
+}
+
+// Remove removes the registries of the kind, only the one with the url if it's set
+func Remove(registries []*Registry, kind string, registryURL string) ([]*Registry, int) {
+	kept := []*Registry{}
+	for _, r := range registries {
+		if r.Kind == kind && (registryURL == "" || r.URL == registryURL) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept, len(registries) - len(kept)
+}
+
+// StateFile returns the path of the file holding the registries
+func StateFile() (string, error) {
+	return home.Path(stateFile)
+}
+
+func loadStored() ([]*storedRegistry, error) {
+	path, err := StateFile()
+	if err != nil {
+		return nil, err
+	}
+	content, err := crypt.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the registries: %w", err)
+	}
+	var stored []*storedRegistry
+	if err := json.Unmarshal(content, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse the registries: %w", err)
+	}
+	return stored, nil
+}
+
+// Load reads the stored registries with their tokens, none are stored if the file is missing
+func Load() ([]*Registry, error) {
+	stored, err := loadStored()
+	if err != nil {
+		return nil, err
+	}
+	var registries []*Registry
+	for _, s := range stored {
+		if s.Keyring {
+			token, err := keyring.Get(auth.KeyringService, s.keyringUser())
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the token of the registry %s from the keyring, log in to it again: %w", s.URL, err)
+			}
+			s.Token = token
+		}
+		registries = append(registries, s.Registry)
+	}
+	return registries, nil
+}
+
+// Save stores the registries. The tokens are kept in the keyring of the system, or in the file, encrypted if the local
+// state is encrypted, if the keyring isn't available or auth.TokenStoreEnv chooses the file. It returns whether the
+// tokens are in the keyring. Tokens of registries which were removed are deleted from the keyring.
+func Save(registries []*Registry) (bool, error) {
+	path, err := home.PrepareWrite(stateFile)
+	if err != nil {
+		return false, err
+	}
+	mode, err := auth.TokenStoreMode()
+	if err != nil {
+		return false, err
+	}
+	previous, _ := loadStored()
+
+	inKeyring := mode != auth.TokenStoreFile
+	if inKeyring {
+		if err := setTokens(registries); err != nil {
+			if mode == auth.TokenStoreKeyring {
+				return false, err
+			}
+			inKeyring = false
+		}
+	}
+	var stored []*storedRegistry
+	kept := make(map[string]bool)
+	for _, r := range registries {
+		s := &storedRegistry{Registry: r}
+		if inKeyring {
+			withoutToken := *r
+			withoutToken.Token = ""
+			s = &storedRegistry{Registry: &withoutToken, Keyring: true}
+		}
+		stored = append(stored, s)
+		kept[r.keyringUser()] = true
+	}
+
+	content, err := json.Marshal(stored)
+	if err != nil {
+		return false, err
+	}
+	if err := crypt.WriteFile(path, content, fileMode); err != nil {
+		return false, home.WrapWriteError(err)
+	}
+	for _, p := range previous {
+		if p.Keyring && (!inKeyring || !kept[p.keyringUser()]) {
+			keyring.Delete(auth.KeyringService, p.keyringUser())
+		}
+	}
+	return inKeyring, nil
+}
+
+// setTokens stores the tokens of the registries in the keyring, none are kept if one can't be stored
+func setTokens(registries []*Registry) error {
+	for i, r := range registries {
+		if err := keyring.Set(auth.KeyringService, r.keyringUser(), r.Token); err != nil {
+			for _, set := range registries[:i] {
+				keyring.Delete(auth.KeyringService, set.keyringUser())
+			}
+			return fmt.Errorf("failed to store the token in the keyring: %w", err)
+		}
+	}
+	return nil
+}
+
+// tokenEnv is the name of the build arg with the token of the npm registries of a host, e.g.
+// SPACE_NPM_TOKEN_NPM_PKG_GITHUB_COM
+func tokenEnv(host string) string {
+	return "SPACE_NPM_TOKEN_" + strings.Trim(nonAlnumReg.ReplaceAllString(strings.ToUpper(host), "_"), "_")
+}
+
+func npmRegistries(registries []*Registry) []*Registry {
+	var npm []*Registry
+	for _, r := range registries {
+		if r.Kind == NPM {
+			npm = append(npm, r)
+		}
+	}
+	return npm
+}
+
+// BuildArgs returns the build args holding the credentials of the registries, npm binds a token to the host of the
+// registry, the token of the last registry of a host is used
+func BuildArgs(registries []*Registry) map[string]string {
+	args := make(map[string]string)
+	for _, r := range npmRegistries(registries) {
+		args[tokenEnv(r.Host())] = r.Token
+	}
+
+	var indexes []string
+	for _, r := range registries {
+		if r.Kind != Pip {
+			continue
+		}
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			continue
+		}
+		username := r.Username
+		if username == "" {
+			username = "__token__"
+		}
+		u.User = url.UserPassword(username, r.Token)
+		indexes = append(indexes, u.String())
+	}
+	if len(indexes) > 0 {
+		sort.Strings(indexes)
+		args[PipIndexEnv] = strings.Join(indexes, " ")
+	}
+	return args
+}
+
+// Secrets returns the tokens of the registries to mask in the logs, with the escaped form the tokens take in the
+// urls of pip indexes. BuildArgs joins the indexes of pip, masking the joined build arg misses a token logged alone.
+func Secrets(registries []*Registry) []string {
+	seen := make(map[string]bool)
+	var secrets []string
+	for _, r := range registries {
+		values := []string{r.Token}
+		if r.Kind == Pip {
+			values = append(values, strings.TrimPrefix(url.UserPassword("", r.Token).String(), ":"))
+		}
+		for _, value := range values {
+			if value != "" && !seen[value] {
+				seen[value] = true
+				secrets = append(secrets, value)
+			}
+		}
+	}
+	return secrets
+}
+
+// Npmrc returns the .npmrc of a micro with the npm registries appended to its existing content, the tokens are read
+// from the build args so that they aren't part of the archive. It returns nil without npm registries.
+func Npmrc(existing []byte, registries []*Registry) []byte {
+	npm := npmRegistries(registries)
+	if len(npm) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.Write(existing)
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("# added by space push for the registries of space registry login\n")
+	authenticated := make(map[string]bool)
+	for _, r := range npm {
+		registryURL := r.URL
+		if !strings.HasSuffix(registryURL, "/") {
+			registryURL += "/"
+		}
+		switch {
+		case r.Scope != "":
+			fmt.Fprintf(&b, "%s:registry=%s\n", r.Scope, registryURL)
+		case registryURL != DefaultNPMURL:
+			fmt.Fprintf(&b, "registry=%s\n", registryURL)
+		}
+		// the token is bound to the host of the registry
+		if host := r.Host(); !authenticated[host] {
+			authenticated[host] = true
+			fmt.Fprintf(&b, "//%s/:_authToken=${%s}\n", host, tokenEnv(host))
+		}
+	}
+	return []byte(b.String())
+}
+
+// Referenced returns the registries a micro in dir reads from: npm registries without a scope serve all packages of a
+// micro with a package.json, other registries have to be named in its manifest or lockfile, by their host or, on npm,
+// by their scope. pip indexes are named e.g. with --extra-index-url in requirements.txt or as a source of poetry.
+func Referenced(dir string, registries []*Registry) ([]*Registry, error) {
+	contents := make(map[string]string)
+	for kind, files := range manifestFiles {
+		if kind == Pip {
+			if matches, err := filepath.Glob(filepath.Join(dir, "requirements*.txt")); err == nil {
+				for _, match := range matches {
+					files = append(files, filepath.Base(match))
+				}
+			}
+		}
+		var b strings.Builder
+		for _, file := range files {
+			content, err := os.ReadFile(filepath.Join(dir, file))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			b.Write(content)
+			b.WriteString("\n")
+		}
+		contents[kind] = b.String()
+	}
+	_, err := os.Stat(filepath.Join(dir, "package.json"))
+	hasPackageJSON := err == nil
+
+	var referenced []*Registry
+	for _, r := range registries {
+		content := contents[r.Kind]
+		switch {
+		case r.Kind == NPM && r.Scope == "" && hasPackageJSON,
+			r.Kind == NPM && r.Scope != "" && strings.Contains(content, r.Scope+"/"),
+			content != "" && strings.Contains(content, r.Host()):
+			referenced = append(referenced, r)
+		}
+	}
+	return referenced, nil
+}
+
+// Mask returns the token with all but its last 4 characters hidden
+func Mask(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-4) + token[len(token)-4:]
+}
@@ -0,0 +1,234 @@
+// Package routes simulates how a request is routed to the micros of an app and which of their rules apply, and finds
+// rules which can never apply
+package routes
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/internal/staticsite"
+	"github.com/deta/space/shared"
+)
+
+// Kind of a step of a simulation
+type Kind string
+
+const (
+	KindMicro    Kind = "micro"
+	KindAccess   Kind = "access"
+	KindRedirect Kind = "redirect"
+	KindHeader   Kind = "header"
+	KindFallback Kind = "fallback"
+)
+
+// Step is a decision made for a request and why it was made
+type Step struct {
+	Kind   Kind   `json:"kind"`
+	Result string `json:"result"`
+	Reason string `json:"reason"`
+}
+
+// Simulation is how a request is handled
+type Simulation struct {
+	Path string `json:"path"`
+	// Micro handles the request
+	Micro string `json:"micro"`
+	// MicroPath is the path of the request as the micro gets it
+	MicroPath string  `json:"micro_path"`
+	Public    bool    `json:"public"`
+	Steps     []*Step `json:"steps"`
+}
+
+// microFor returns the micro serving the path like the proxy of space dev: the micro served on the first segment of
+// the path, or the primary micro. It returns the path the micro gets and why it was chosen.
+func microFor(micros []*shared.Micro, p string) (*shared.Micro, string, string) {
+	prefix := "/" + strings.SplitN(strings.TrimPrefix(p, "/"), "/", 2)[0]
+	var micro, primary *shared.Micro
+	for _, m := range micros {
+		if m.Primary {
+			primary = m
+		}
+		if prefix != "/" && m.Path == prefix {
+			micro = m
+		}
+	}
+	if micro != nil {
+		microPath := strings.TrimPrefix(p, prefix)
+		if microPath == "" {
+			microPath = "/"
+		}
+		return micro, microPath, fmt.Sprintf("%s is the path of micro %s, it gets %s", prefix, micro.Name, microPath)
+	}
+	if primary == nil {
+		return nil, "", "the app has no primary micro"
+	}
+	if prefix == "/" {
+		return primary, p, fmt.Sprintf("%s is the root of the app, which is served by the primary micro", p)
+	}
+	return primary, p, fmt.Sprintf("no micro is served on %s, so the primary micro gets the request", prefix)
+}
+
+// Simulate returns how a GET request of the path is handled by the micros of the project in projectDir, whose files
+// are checked for the fallback of single page apps. The path may be a url, its query is ignored.
+func Simulate(projectDir string, micros []*shared.Micro, p string) *Simulation {
+	if u, err := url.Parse(p); err == nil {
+		p = u.Path
+	}
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	s := &Simulation{Path: p}
+	micro, microPath, reason := microFor(micros, p)
+	if micro == nil {
+		s.Steps = append(s.Steps, &Step{Kind: KindMicro, Result: "not found", Reason: reason})
+		return s
+	}
+	s.Micro, s.MicroPath = micro.Name, microPath
+	s.Steps = append(s.Steps, &Step{Kind: KindMicro, Result: micro.Name, Reason: reason})
+
+	s.Steps = append(s.Steps, access(s, micro, microPath))
+
+	if micro.Engine != shared.Static || micro.Static == nil {
+		return s
+	}
+	config := micro.Static
+	for _, redirect := range config.Redirects {
+		rest, ok := staticsite.Match(redirect.Source, microPath)
+		if !ok {
+			continue
+		}
+		status := redirect.Status
+		if status == 0 {
+			status = 301
+		}
+		s.Steps = append(s.Steps, &Step{
+			Kind:   KindRedirect,
+			Result: fmt.Sprintf("%d to %s", status, staticsite.Location(redirect.Destination, rest, micro.Path)),
+			Reason: fmt.Sprintf("%s matches the source %s, the first matching redirect applies", microPath, redirect.Source),
+		})
+		return s
+	}
+
+	// a header of a later rule overrides the one of an earlier rule
+	applied := make(map[string]*Step)
+	sources := make(map[string]string)
+	var names []string
+	for _, rule := range config.Headers {
+		if _, ok := staticsite.Match(rule.Source, microPath); !ok {
+			continue
+		}
+		for _, name := range sortedNames(rule.Headers) {
+			step := &Step{Kind: KindHeader, Result: fmt.Sprintf("%s: %s", name, rule.Headers[name]), Reason: fmt.Sprintf("%s matches the source %s", microPath, rule.Source)}
+			key := strings.ToLower(name)
+			if source, ok := sources[key]; ok {
+				step.Reason += ", overriding the header of " + source
+			} else {
+				names = append(names, key)
+			}
+			applied[key], sources[key] = step, rule.Source
+		}
+	}
+	for _, name := range names {
+		s.Steps = append(s.Steps, applied[name])
+	}
+
+	if config.SPA && path.Ext(microPath) == "" {
+		root := filepath.Join(projectDir, micro.Src, micro.Serve)
+		file := filepath.Join(root, filepath.FromSlash(path.Clean(microPath)))
+		if _, err := os.Stat(root); err != nil {
+			s.Steps = append(s.Steps, &Step{Kind: KindFallback, Result: "index.html unless a file exists", Reason: fmt.Sprintf("micro %s is a single page app and %s isn't built, paths without an extension which aren't files get its index.html", micro.Name, root)})
+		} else if _, err := os.Stat(file); err != nil {
+			s.Steps = append(s.Steps, &Step{Kind: KindFallback, Result: "index.html", Reason: fmt.Sprintf("micro %s is a single page app and %s isn't a file in %s", micro.Name, microPath, root)})
+		}
+	}
+	return s
+}
+
+func access(s *Simulation, micro *shared.Micro, microPath string) *Step {
+	if micro.Public {
+		s.Public = true
+		return &Step{Kind: KindAccess, Result: "public", Reason: fmt.Sprintf("micro %s is public", micro.Name)}
+	}
+	for _, route := range micro.PublicRoutes {
+		if _, ok := staticsite.Match(route, microPath); ok {
+			s.Public = true
+			return &Step{Kind: KindAccess, Result: "public", Reason: fmt.Sprintf("%s matches the public route %s of micro %s", microPath, route, micro.Name)}
+		}
+	}
+	if len(micro.PublicRoutes) == 0 {
+		return &Step{Kind: KindAccess, Result: "signed in users only", Reason: fmt.Sprintf("micro %s has no public routes", micro.Name)}
+	}
+	return &Step{Kind: KindAccess, Result: "signed in users only", Reason: fmt.Sprintf("%s matches none of the public routes %s of micro %s", microPath, strings.Join(micro.PublicRoutes, ", "), micro.Name)}
+}
+
+func sortedNames(headers map[string]string) []string {
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check returns problems of the routes of the micros which make them behave differently than declared: micros on
+// the same path, paths which are never routed, public routes which never reach their micro and redirects which never
+// apply or redirect to themselves
+func Check(micros []*shared.Micro) []string {
+	var problems []string
+	paths := make(map[string]string)
+	for _, micro := range micros {
+		if micro.Primary {
+			continue
+		}
+		if other, ok := paths[micro.Path]; ok {
+			problems = append(problems, fmt.Sprintf("micros %s and %s are both served on %s, only one of them gets its requests", other, micro.Name, micro.Path))
+		}
+		paths[micro.Path] = micro.Name
+		if strings.Count(micro.Path, "/") > 1 {
+			problems = append(problems, fmt.Sprintf("the path %s of micro %s has more than one segment, requests are routed by their first segment", micro.Path, micro.Name))
+		}
+	}
+
+	for _, micro := range micros {
+		if micro.Primary {
+			for _, route := range micro.PublicRoutes {
+				prefix := "/" + strings.SplitN(strings.TrimPrefix(route, "/"), "/", 2)[0]
+				if other, ok := paths[prefix]; ok {
+					problems = append(problems, fmt.Sprintf("the public route %s of micro %s never reaches it, %s is served by micro %s", route, micro.Name, prefix, other))
+				}
+			}
+		}
+		if micro.Static == nil {
+			continue
+		}
+
+		redirects := micro.Static.Redirects
+		for i, redirect := range redirects {
+			for _, earlier := range redirects[:i] {
+				if earlier.Source == redirect.Source || !strings.Contains(redirect.Source, "*") && matches(earlier.Source, redirect.Source) {
+					problems = append(problems, fmt.Sprintf("the redirect of %s of micro %s never applies, the earlier redirect of %s matches first", redirect.Source, micro.Name, earlier.Source))
+					break
+				}
+			}
+			if !strings.HasPrefix(redirect.Destination, "/") || strings.HasPrefix(redirect.Destination, "//") {
+				continue
+			}
+			// the destination of a sample path matched by the source, relative to the micro
+			location := staticsite.Location(redirect.Destination, "page", "/")
+			if matches(redirect.Source, location) {
+				problems = append(problems, fmt.Sprintf("the redirect of %s of micro %s redirects to %s, which it matches itself", redirect.Source, micro.Name, location))
+			}
+		}
+	}
+	return problems
+}
+
+func matches(pattern string, p string) bool {
+	_, ok := staticsite.Match(pattern, p)
+	return ok
+}
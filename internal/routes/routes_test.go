@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deta/space/shared"
+	"gotest.tools/v3/assert"
+)
+
+func testMicros() []*shared.Micro {
+	return []*shared.Micro{
+		{Name: "api", Engine: "python3.9", Path: "/api", PublicRoutes: []string{"/health"}},
+		{
+			Name: "frontend", Engine: shared.Static, Src: "frontend", Serve: "dist", Path: "/", Primary: true,
+			PublicRoutes: []string{"/assets/*"},
+			Static: &shared.StaticConfig{
+				SPA: true,
+				Headers: []shared.HeaderRule{
+					{Source: "/assets/*", Headers: map[string]string{"Cache-Control": "immutable"}},
+				},
+				Redirects: []shared.Redirect{{Source: "/blog/*", Destination: "/posts/*", Status: 308}},
+			},
+		},
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "frontend", "dist", "assets"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "frontend", "dist", "assets", "app.js"), nil, 0644))
+
+	cases := []struct {
+		path      string
+		micro     string
+		microPath string
+		public    bool
+		steps     map[Kind]string
+	}{
+		{path: "/api/health", micro: "api", microPath: "/health", public: true, steps: map[Kind]string{KindAccess: "public"}},
+		{path: "/api", micro: "api", microPath: "/", steps: map[Kind]string{KindAccess: "signed in users only"}},
+		{path: "/assets/app.js", micro: "frontend", microPath: "/assets/app.js", public: true, steps: map[Kind]string{KindHeader: "Cache-Control: immutable"}},
+		{path: "/settings?tab=1", micro: "frontend", microPath: "/settings", steps: map[Kind]string{KindFallback: "index.html"}},
+		{path: "https://app.deta.app/blog/hello", micro: "frontend", microPath: "/blog/hello", steps: map[Kind]string{KindRedirect: "308 to /posts/hello"}},
+	}
+
+	for _, c := range cases {
+		s := Simulate(dir, testMicros(), c.path)
+		assert.Equal(t, s.Micro, c.micro, c.path)
+		assert.Equal(t, s.MicroPath, c.microPath, c.path)
+		assert.Equal(t, s.Public, c.public, c.path)
+		results := make(map[Kind]string)
+		for _, step := range s.Steps {
+			results[step.Kind] = step.Result
+		}
+		for kind, result := range c.steps {
+			assert.Equal(t, results[kind], result, "%s %s", c.path, kind)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	assert.Equal(t, len(Check(testMicros())), 0)
+
+	micros := testMicros()
+	micros = append(micros, &shared.Micro{Name: "admin", Path: "/api"}, &shared.Micro{Name: "v2", Path: "/api/v2"})
+	micros[1].PublicRoutes = append(micros[1].PublicRoutes, "/api/*")
+	micros[1].Static.Redirects = append(micros[1].Static.Redirects,
+		shared.Redirect{Source: "/blog/old", Destination: "/new"},
+		shared.Redirect{Source: "/docs/*", Destination: "/docs/v2/*"},
+	)
+
+	assert.DeepEqual(t, Check(micros), []string{
+		"micros api and admin are both served on /api, only one of them gets its requests",
+		"the path /api/v2 of micro v2 has more than one segment, requests are routed by their first segment",
+		"the public route /api/* of micro frontend never reaches it, /api is served by micro admin",
+		"the redirect of /blog/old of micro frontend never applies, the earlier redirect of /blog/* matches first",
+		"the redirect of /docs/* of micro frontend redirects to /docs/v2/page, which it matches itself",
+	})
+}
@@ -0,0 +1,194 @@
+package runtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deta/space/internal/auth"
+	"github.com/deta/space/internal/home"
+)
+
+const (
+	// cacheDir is the directory of the cache in the directory of the global state
+	cacheDir = "cache"
+
+	// ProjectsCacheTTL is how long the list of projects is cached
+	ProjectsCacheTTL = 5 * time.Minute
+	// RevisionsCacheTTL is how long the revisions of a project are cached
+	RevisionsCacheTTL = time.Minute
+	// RegionsCacheTTL is how long the regions are cached
+	RegionsCacheTTL = 24 * time.Hour
+
+	// ProjectsCacheKey caches the projects of the user
+	ProjectsCacheKey = "projects"
+	// RegionsCacheKey caches the regions
+	RegionsCacheKey = "regions"
+	// VersionCacheKey caches the latest version of the cli
+	VersionCacheKey = "latest_version"
+	// UpdateOfferCacheKey caches the version the user was last offered to update to
+	UpdateOfferCacheKey = "update_offer"
+)
+
+// RevisionsCacheKey caches the revisions of a project
+func RevisionsCacheKey(projectID string) string {
+	return "revisions/" + projectID
+}
+
+var (
+	cacheMu       sync.Mutex
+	cacheDisabled bool
+
+	// globalCacheKeys are the same for every account, all other values are cached per access token
+	globalCacheKeys = map[string]bool{VersionCacheKey: true, UpdateOfferCacheKey: true}
+	// accessToken returns the token the values are cached for, replaced in tests
+	accessToken = auth.GetAccessToken
+)
+
+type cacheEntry struct {
+	StoredAt int64           `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// SetCacheDisabled disables reading from the cache, values are still written so that later commands can use them
+func SetCacheDisabled(disabled bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheDisabled = disabled
+}
+
+// CacheDisabled reports if reading from the cache is disabled
+func CacheDisabled() bool {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return cacheDisabled
+}
+
+// cachePath returns the path of the value cached under key. Values of the api are kept in a directory of the
+// fingerprint of the access token, so that commands never show what was cached for another account.
+func cachePath(key string) string {
+	name := strings.ReplaceAll(key, "/", "_") + ".json"
+	if globalCacheKeys[key] {
+		return filepath.Join(cacheDir, name)
+	}
+	return filepath.Join(cacheDir, tokenFingerprint(), name)
+}
+
+func tokenFingerprint() string {
+	token, err := accessToken()
+	if err != nil || token == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ReadCache decodes the value cached under key into v, it returns false if there is no value younger than ttl
+func ReadCache(key string, ttl time.Duration, v any) (bool, time.Time) {
+	cacheMu.Lock()
+	disabled := cacheDisabled
+	cacheMu.Unlock()
+	if disabled {
+		return false, time.Time{}
+	}
+
+	path, err := home.Path(cachePath(key))
+	if err != nil {
+		return false, time.Time{}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, time.Time{}
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return false, time.Time{}
+	}
+	storedAt := time.Unix(entry.StoredAt, 0)
+	if time.Since(storedAt) > ttl {
+		return false, storedAt
+	}
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return false, time.Time{}
+	}
+	return true, storedAt
+}
+
+// WriteCache stores v under key
+func WriteCache(key string, v any) error {
+	path, err := home.PrepareWrite(cachePath(key))
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(cacheEntry{StoredAt: time.Now().Unix(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	// the value is renamed into place, as the background version check can be stopped while writing when the command exits
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return home.WrapWriteError(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return home.WrapWriteError(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return home.WrapWriteError(os.Rename(tmp.Name(), path))
+}
+
+// InvalidateCache removes the value cached under key
+func InvalidateCache(key string) error {
+	path, err := home.Path(cachePath(key))
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return home.WrapWriteError(err)
+	}
+	return nil
+}
+
+// ClearCache removes all cached values, it returns the number of removed values
+func ClearCache() (int, error) {
+	dir, err := home.Path(cacheDir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, home.WrapWriteError(err)
+	}
+	return count, nil
+}
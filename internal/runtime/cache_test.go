@@ -0,0 +1,91 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deta/space/internal/home"
+	"gotest.tools/v3/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Setenv(home.HomeEnv, t.TempDir())
+	useToken(t, "abc_def")
+
+	var projects []string
+	ok, _ := ReadCache(ProjectsCacheKey, time.Minute, &projects)
+	assert.Assert(t, !ok)
+
+	assert.NilError(t, WriteCache(ProjectsCacheKey, []string{"a", "b"}))
+	assert.NilError(t, WriteCache(RevisionsCacheKey("id"), []string{"r1"}))
+
+	ok, storedAt := ReadCache(ProjectsCacheKey, time.Minute, &projects)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, projects, []string{"a", "b"})
+	assert.Assert(t, time.Since(storedAt) < time.Minute)
+
+	cases := []struct {
+		name     string
+		ttl      time.Duration
+		disabled bool
+		ok       bool
+	}{
+		{name: "fresh", ttl: time.Minute, ok: true},
+		{name: "expired", ttl: -time.Second, ok: false},
+		{name: "disabled", ttl: time.Minute, disabled: true, ok: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			SetCacheDisabled(c.disabled)
+			defer SetCacheDisabled(false)
+
+			var revisions []string
+			ok, _ := ReadCache(RevisionsCacheKey("id"), c.ttl, &revisions)
+			assert.Equal(t, ok, c.ok)
+		})
+	}
+
+	assert.NilError(t, InvalidateCache(ProjectsCacheKey))
+	ok, _ = ReadCache(ProjectsCacheKey, time.Minute, &projects)
+	assert.Assert(t, !ok)
+
+	n, err := ClearCache()
+	assert.NilError(t, err)
+	assert.Equal(t, n, 1)
+	var revisions []string
+	ok, _ = ReadCache(RevisionsCacheKey("id"), time.Minute, &revisions)
+	assert.Assert(t, !ok)
+}
+
+// useToken caches values for the access token
+func useToken(t *testing.T, token string) {
+	previous := accessToken
+	accessToken = func() (string, error) { return token, nil }
+	t.Cleanup(func() { accessToken = previous })
+}
+
+func TestCachePerAccount(t *testing.T) {
+	t.Setenv(home.HomeEnv, t.TempDir())
+
+	useToken(t, "abc_def")
+	assert.NilError(t, WriteCache(ProjectsCacheKey, []string{"a"}))
+	assert.NilError(t, WriteCache(VersionCacheKey, "v1.0.0"))
+
+	useToken(t, "ghi_jkl")
+	var projects []string
+	ok, _ := ReadCache(ProjectsCacheKey, time.Minute, &projects)
+	assert.Assert(t, !ok)
+	var version string
+	ok, _ = ReadCache(VersionCacheKey, time.Minute, &version)
+	assert.Assert(t, ok)
+	assert.Equal(t, version, "v1.0.0")
+
+	useToken(t, "abc_def")
+	ok, _ = ReadCache(ProjectsCacheKey, time.Minute, &projects)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, projects, []string{"a"})
+
+	n, err := ClearCache()
+	assert.NilError(t, err)
+	assert.Equal(t, n, 2)
+}
@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Compression controls how files are compressed when zipping a project
+type Compression string
+
+const (
+	// CompressionAuto deflates files with the default level
+	CompressionAuto Compression = "auto"
+	// CompressionNone stores files without compression
+	CompressionNone Compression = "none"
+	// CompressionFast deflates files favoring speed over size
+	CompressionFast Compression = "fast"
+	// CompressionBest deflates files favoring size over speed
+	CompressionBest Compression = "best"
+)
+
+// Compressions lists all valid compression settings
+var Compressions = []Compression{CompressionAuto, CompressionNone, CompressionFast, CompressionBest}
+
+// entropyThreshold in bits per byte above which content is considered already compressed
+const entropyThreshold = 7.5
+
+// entropySampleSize number of bytes of a file used to estimate its entropy
+const entropySampleSize = 64 * 1024
+
+// compressedExts are extensions of formats which are compressed already
+var compressedExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".avif": true, ".ico": true,
+	".mp3": true, ".mp4": true, ".m4a": true, ".ogg": true, ".webm": true, ".mov": true,
+	".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".zst": true, ".7z": true, ".rar": true,
+	".woff": true, ".woff2": true, ".jar": true, ".whl": true, ".pdf": true,
+}
+
+// ParseCompression parses a compression setting
+func ParseCompression(s string) (Compression, error) {
+	for _, c := range Compressions {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+
+	names := make([]string, 0, len(Compressions))
+	for _, c := range Compressions {
+		names = append(names, string(c))
+	}
+	return "", fmt.Errorf("invalid compression %q, must be one of %s", s, strings.Join(names, ", "))
+}
+
+// method returns the zip method used for a file with the given content
+func (c Compression) method(name string, content []byte) uint16 {
+	if c == CompressionNone || isCompressed(name, content) {
+		return zip.Store
+	}
+	return zip.Deflate
+}
+
+// register registers the compressors needed for the compression on the zip writer
+func (c Compression) register(w *zip.Writer) {
+	switch c {
+	case CompressionFast:
+		w.RegisterCompressor(zip.Deflate, deflater(flate.BestSpeed))
+	case CompressionBest:
+		w.RegisterCompressor(zip.Deflate, deflater(flate.BestCompression))
+	}
+}
+
+func deflater(level int) zip.Compressor {
+	return func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, level)
+	}
+}
+
+// isCompressed reports if a file is unlikely to shrink when compressed again,
+// either because of its extension or because of the entropy of its content
+func isCompressed(name string, content []byte) bool {
+	if compressedExts[strings.ToLower(filepath.Ext(name))] {
+		return true
+	}
+
+	sample := content
+	if len(sample) > entropySampleSize {
+		sample = sample[:entropySampleSize]
+	}
+	// small files don't have enough bytes for a meaningful estimate
+	if len(sample) < 1024 {
+		return false
+	}
+	return entropy(sample) > entropyThreshold
+}
+
+// entropy computes the shannon entropy of b in bits per byte
+func entropy(b []byte) float64 {
+	var counts [256]int
+	for _, c := range b {
+		counts[c]++
+	}
+
+	var e float64
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(len(b))
+		e -= p * math.Log2(p)
+	}
+	return e
+}
@@ -0,0 +1,49 @@
+package runtime
+
+import (
+	"archive/zip"
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCompressionMethod(t *testing.T) {
+	text := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog\n"), 100)
+	random := make([]byte, 8*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	cases := []struct {
+		name        string
+		compression Compression
+		file        string
+		content     []byte
+		expected    uint16
+	}{
+		{name: "auto text", compression: CompressionAuto, file: "main.py", content: text, expected: zip.Deflate},
+		{name: "auto image", compression: CompressionAuto, file: "logo.PNG", content: text, expected: zip.Store},
+		{name: "auto random", compression: CompressionAuto, file: "model.bin", content: random, expected: zip.Store},
+		{name: "auto small", compression: CompressionAuto, file: "small.bin", content: random[:100], expected: zip.Deflate},
+		{name: "none text", compression: CompressionNone, file: "main.py", content: text, expected: zip.Store},
+		{name: "best text", compression: CompressionBest, file: "main.py", content: text, expected: zip.Deflate},
+		{name: "best archive", compression: CompressionBest, file: "deps.tar.gz", content: text, expected: zip.Store},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.compression.method(c.file, c.content), c.expected)
+		})
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range Compressions {
+		parsed, err := ParseCompression(string(c))
+		assert.NilError(t, err)
+		assert.Equal(t, parsed, c)
+	}
+
+	_, err := ParseCompression("gzip")
+	assert.ErrorContains(t, err, "invalid compression")
+}
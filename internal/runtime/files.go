@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// LargeFileSize is the size above which files are reported as large when checking a project
+const LargeFileSize = 20 * 1024 * 1024
+
+// lfsPointerPrefix is the first line of every Git LFS pointer file
+var lfsPointerPrefix = []byte("version https://git-lfs.github.com/spec/v1")
+
+// lfsPointerMaxSize pointer files are small text files, bigger files are never pointers
+const lfsPointerMaxSize = 1024
+
+// File is a file of a project, Path is relative to the project root
+type File struct {
+	Path string
+	Size int64
+}
+
+// FilesReport lists files of a project which are likely to break or slow down a push
+type FilesReport struct {
+	// LFSPointers are Git LFS pointer files whose content was not pulled
+	LFSPointers []File
+	// LargeFiles are files bigger than LargeFileSize
+	LargeFiles []File
+}
+
+// IsEmpty reports if no problems were found
+func (r *FilesReport) IsEmpty() bool {
+	return len(r.LFSPointers) == 0 && len(r.LargeFiles) == 0
+}
+
+// LFSPointerPaths returns the paths of the LFS pointer files
+func (r *FilesReport) LFSPointerPaths() []string {
+	paths := make([]string, 0, len(r.LFSPointers))
+	for _, f := range r.LFSPointers {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+// CheckFiles checks the files that would be pushed for Git LFS pointers and large files
+func CheckFiles(sourceDir string) (*FilesReport, error) {
+	report := &FilesReport{}
+	err := walkProject(sourceDir, true, func(path string, relPath string, info os.FileInfo) error {
+		file := File{Path: relPath, Size: info.Size()}
+		if info.Size() > LargeFileSize {
+			report.LargeFiles = append(report.LargeFiles, file)
+			return nil
+		}
+
+		if info.Size() > lfsPointerMaxSize {
+			return nil
+		}
+
+		isPointer, err := isLFSPointer(path)
+		if err != nil {
+			return err
+		}
+		if isPointer {
+			report.LFSPointers = append(report.LFSPointers, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func isLFSPointer(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	head := make([]byte, len(lfsPointerPrefix))
+	if _, err := io.ReadFull(f, head); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(head, lfsPointerPrefix), nil
+}
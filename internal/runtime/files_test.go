@@ -0,0 +1,31 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCheckFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"main.py": []byte("print('hello')"),
+		"models/weights.bin": []byte(`version https://git-lfs.github.com/spec/v1
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345
+`),
+		"node_modules/pointer.bin": []byte("version https://git-lfs.github.com/spec/v1\n"),
+	})
+
+	large, err := os.Create(filepath.Join(dir, "video.mp4"))
+	assert.NilError(t, err)
+	assert.NilError(t, large.Truncate(LargeFileSize+1))
+	assert.NilError(t, large.Close())
+
+	report, err := CheckFiles(dir)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, report.LFSPointerPaths(), []string{"models/weights.bin"})
+	assert.DeepEqual(t, report.LargeFiles, []File{{Path: "video.mp4", Size: LargeFileSize + 1}})
+}
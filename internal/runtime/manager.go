@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+
+	"github.com/deta/space/internal/crypt"
 )
 
 const (
@@ -42,7 +44,7 @@ func StoreProjectMeta(projectDir string, p *ProjectMeta) error {
 	spaceReadmeNotes := "Don't commit this folder (.space) to git as it may contain security-sensitive data."
 	ioutil.WriteFile(filepath.Join(projectDir, spaceDir, "README"), []byte(spaceReadmeNotes), filePermMode)
 
-	return ioutil.WriteFile(filepath.Join(projectDir, spaceDir, projectMetaFile), marshalled, filePermMode)
+	return crypt.WriteFile(filepath.Join(projectDir, spaceDir, projectMetaFile), marshalled, filePermMode)
 }
 
 func GetProjectID(projectDir string) (string, error) {
@@ -55,7 +57,7 @@ func GetProjectID(projectDir string) (string, error) {
 
 // GetProjectMeta gets the project info stored
 func GetProjectMeta(projectDir string) (*ProjectMeta, error) {
-	contents, err := os.ReadFile(filepath.Join(projectDir, spaceDir, projectMetaFile))
+	contents, err := crypt.ReadFile(filepath.Join(projectDir, spaceDir, projectMetaFile))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, err
@@ -71,6 +73,11 @@ func GetProjectMeta(projectDir string) (*ProjectMeta, error) {
 	return projectMeta, nil
 }
 
+// ProjectStateFiles returns the paths of the files in the .space folder of the project holding sensitive data
+func ProjectStateFiles(projectDir string) []string {
+	return []string{filepath.Join(projectDir, spaceDir, projectMetaFile)}
+}
+
 func IsProjectInitialized(projectDir string) (bool, error) {
 	_, err := os.Stat(filepath.Join(projectDir, spaceDir, projectMetaFile))
 	if err != nil {
@@ -120,3 +127,22 @@ func AddSpaceToGitignore(projectDir string) error {
 	}
 	return nil
 }
+
+// freezeOverridesFile logs the overridden release freezes of a project
+const freezeOverridesFile = "freeze-overrides.log"
+
+// LogFreezeOverride appends an overridden release freeze to the log in the .space folder of the project
+func LogFreezeOverride(projectDir string, entry string) error {
+	if err := os.MkdirAll(filepath.Join(projectDir, spaceDir), dirPermMode); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(projectDir, spaceDir, freezeOverridesFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePermMode)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, entry); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -2,13 +2,6 @@ package runtime
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
-	"time"
-)
-
-const (
-	spaceVersionPath = ".detaspace/space_latest_version"
 )
 
 // ProjectMeta xx
@@ -28,56 +21,7 @@ func projectMetaFromBytes(data []byte) (*ProjectMeta, error) {
 	return &p, nil
 }
 
-type Version struct {
-	Version   string `json:"version"`
-	UpdatedAt int64  `json:"updatedAt"`
-}
-
+// CacheLatestVersion stores the latest version of the cli, so that it's not checked again until the check interval passed
 func CacheLatestVersion(version string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	spaceDirPath := filepath.Join(home, spaceDir)
-	err = os.MkdirAll(spaceDirPath, 0760)
-	if err != nil {
-		return err
-	}
-
-	versionsFilePath := filepath.Join(home, spaceVersionPath)
-	content, err := json.Marshal(Version{
-		Version:   version,
-		UpdatedAt: int64(time.Now().Unix()),
-	})
-	if err != nil {
-		return err
-	}
-
-	os.WriteFile(versionsFilePath, content, 0644)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func GetLatestCachedVersion() (string, time.Time, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", time.Time{}, err
-	}
-
-	versionsFilePath := filepath.Join(home, spaceVersionPath)
-	content, err := os.ReadFile(versionsFilePath)
-	if err != nil {
-		return "", time.Time{}, err
-	}
-
-	var version Version
-	if err = json.Unmarshal(content, &version); err != nil {
-		return "", time.Time{}, err
-	}
-
-	return version.Version, time.Unix(version.UpdatedAt, 0), nil
+	return WriteCache(VersionCacheKey, version)
 }
@@ -2,14 +2,20 @@ package runtime
 
 import (
 	"archive/zip"
+	"bufio"
 	"bytes"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/deta/space/internal/checksum"
 	ignore "github.com/sabhiram/go-gitignore"
 )
 
@@ -20,40 +26,90 @@ var (
 //go:embed .spaceignore
 var defaultSpaceignore string
 
+// ZipOptions configures how a project is zipped
+type ZipOptions struct {
+	Compression Compression
+	// Exclude lists paths relative to the project root which are left out of the archive
+	Exclude []string
+	// Mounts are directories outside of the project which are archived too
+	Mounts []Mount
+	// Include selects the files of the project by their path relative to the project root, the .spaceignore doesn't
+	// apply to the project then, e.g. to push build artifacts
+	Include func(relPath string) bool
+	// Files are generated files keyed by their path relative to the project root, they replace project files with the
+	// same path
+	Files map[string][]byte
+}
+
+// Mount puts the files of Dir under Path in the archive, Dir has its own .spaceignore
+type Mount struct {
+	Dir  string
+	Path string
+	// Include selects the files of Dir like ZipOptions.Include
+	Include func(relPath string) bool
+}
+
 func ZipDir(sourceDir string) ([]byte, int, error) {
+	return ZipDirWithOptions(sourceDir, ZipOptions{Compression: CompressionAuto})
+}
+
+// ZipDirWithOptions zips the project into memory, use WriteZip for big projects
+func ZipDirWithOptions(sourceDir string, opts ZipOptions) ([]byte, int, error) {
+	buf := new(bytes.Buffer)
+	nbFiles, err := WriteZip(buf, sourceDir, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), nbFiles, nil
+}
+
+// walkProject calls fn for every file of the project which is not excluded by the .spaceignore file, or for every file
+// if useSpaceignore is false
+func walkProject(sourceDir string, useSpaceignore bool, fn func(path string, relPath string, info os.FileInfo) error) error {
+	return walkProjectExplained(sourceDir, useSpaceignore, fn, nil)
+}
+
+// walkProjectExplained is walkProject which also calls ignored, if it's not nil, for every file or directory excluded
+// by the .spaceignore with the reason, the files of an excluded directory aren't walked
+func walkProjectExplained(sourceDir string, useSpaceignore bool, fn func(path string, relPath string, info os.FileInfo) error, ignored func(relPath string, info os.FileInfo, reason string)) error {
 	absDir, err := filepath.Abs(sourceDir)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to resolve absolute path for dir %s to zip, %w", sourceDir, err)
+		return fmt.Errorf("failed to resolve absolute path for dir %s, %w", sourceDir, err)
 	}
 
 	// check if dir exists
 	if stat, err := os.Stat(absDir); err != nil && stat.IsDir() {
 		if os.IsNotExist(err) {
-			return nil, 0, fmt.Errorf("source dir %s not found, %w", absDir, err)
+			return fmt.Errorf("source dir %s not found, %w", absDir, err)
 		}
 	}
 
-	lines := strings.Split(string(defaultSpaceignore), "\n")
-	spaceIgnorePath := filepath.Join(sourceDir, spaceignoreFile)
-	if _, err := os.Stat(spaceIgnorePath); err == nil {
-		bytes, err := os.ReadFile(spaceIgnorePath)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to read .spaceignore: %w", err)
-		}
-		lines = append(lines, strings.Split(string(bytes), "\n")...)
+	spaceignore, defaultLines, err := compileSpaceignore(sourceDir)
+	if err != nil {
+		return err
+	}
+	if !useSpaceignore {
+		spaceignore = ignore.CompileIgnoreLines()
 	}
 
-	spaceignore := ignore.CompileIgnoreLines(lines...)
+	return filepath.Walk(absDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 
-	files := make(map[string][]byte)
-	// go through the dir and read all the files
-	err = filepath.Walk(absDir, func(path string, info os.FileInfo, err error) error {
+		// relative path of file from absolute locations of dir and path
+		relPath, err := filepath.Rel(absDir, path)
 		if err != nil {
 			return err
 		}
+		// ensures to use forward slashes
+		relPath = filepath.ToSlash(relPath)
 
 		// skip if shouldSkip according to skipPaths which are derived from .spaceignore
-		shouldSkip := spaceignore.MatchesPath(path)
+		shouldSkip, pattern := spaceignore.MatchesPathHow(path)
+		if shouldSkip && ignored != nil && relPath != "." {
+			ignored(relPath, info, ignoreReason(pattern, defaultLines))
+		}
 		if shouldSkip && info.IsDir() {
 			return filepath.SkipDir
 		}
@@ -66,57 +122,286 @@ func ZipDir(sourceDir string) ([]byte, int, error) {
 			return nil
 		}
 
-		absPath, err := filepath.Abs(path)
+		return fn(path, relPath, info)
+	})
+}
+
+// compileSpaceignore compiles the default patterns followed by the .spaceignore of sourceDir, it returns the number of
+// default lines as well
+func compileSpaceignore(sourceDir string) (*ignore.GitIgnore, int, error) {
+	lines := strings.Split(string(defaultSpaceignore), "\n")
+	defaultLines := len(lines)
+	spaceIgnorePath := filepath.Join(sourceDir, spaceignoreFile)
+	if _, err := os.Stat(spaceIgnorePath); err == nil {
+		bytes, err := os.ReadFile(spaceIgnorePath)
 		if err != nil {
-			return err
+			return nil, 0, fmt.Errorf("failed to read .spaceignore: %w", err)
 		}
+		lines = append(lines, strings.Split(string(bytes), "\n")...)
+	}
+	return ignore.CompileIgnoreLines(lines...), defaultLines, nil
+}
 
-		// relative path of file from absolute locations of dir and path
-		relPath, err := filepath.Rel(absDir, absPath)
+// Spaceignore returns a matcher of the paths excluded by the .spaceignore of sourceDir and its default patterns, the
+// paths are absolute like the paths of a walk of the project
+func Spaceignore(sourceDir string) (func(path string) bool, error) {
+	spaceignore, _, err := compileSpaceignore(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	return spaceignore.MatchesPath, nil
+}
+
+// ignoreReason names the line of the .spaceignore which excluded a file, the default patterns come first
+func ignoreReason(pattern *ignore.IgnorePattern, defaultLines int) string {
+	if pattern == nil {
+		return spaceignoreFile
+	}
+	if pattern.LineNo <= defaultLines {
+		return fmt.Sprintf("default %s: %s", spaceignoreFile, strings.TrimSpace(pattern.Line))
+	}
+	return fmt.Sprintf("%s line %d: %s", spaceignoreFile, pattern.LineNo-defaultLines, strings.TrimSpace(pattern.Line))
+}
+
+// ArchiveFile is a file of the archive, or a file or directory left out of it
+type ArchiveFile struct {
+	// Path is the path of the file on disk, empty for generated files
+	Path string
+	// Name is the path in the archive
+	Name string
+	Size int64
+	// Dir is set for excluded directories, their files aren't listed
+	Dir bool
+	// Reason is why the file is left out of the archive, empty for archived files
+	Reason string
+}
+
+// walkArchive calls fn for every file of the archive in the order they are archived, with explain also for the
+// files left out of it
+func walkArchive(sourceDir string, opts ZipOptions, explain bool, fn func(f *ArchiveFile) error) error {
+	excluded := make(map[string]bool, len(opts.Exclude))
+	for _, path := range opts.Exclude {
+		excluded[path] = true
+	}
+
+	// the ignored files are passed to fn when explaining, its first error stops the walk after the current file
+	var ignoredErr error
+	explainIgnored := func(prefix string) func(string, os.FileInfo, string) {
+		if !explain {
+			return nil
+		}
+		return func(relPath string, info os.FileInfo, reason string) {
+			if ignoredErr == nil {
+				ignoredErr = fn(&ArchiveFile{Name: prefix + relPath, Size: info.Size(), Dir: info.IsDir(), Reason: reason})
+			}
+		}
+	}
+
+	err := walkProjectExplained(sourceDir, opts.Include == nil, func(path string, relPath string, info os.FileInfo) error {
+		f := &ArchiveFile{Path: path, Name: relPath, Size: info.Size()}
+		switch _, generated := opts.Files[relPath]; {
+		case generated:
+			f.Reason = "replaced by a generated file"
+		case excluded[relPath]:
+			f.Reason = "excluded from the push"
+		case opts.Include != nil && !opts.Include(relPath):
+			f.Reason = "not an artifact"
+		}
+		if f.Reason != "" && !explain {
+			return nil
+		}
+		return fn(f)
+	}, explainIgnored(""))
+	if err == nil {
+		err = ignoredErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot scan contents of dir %s to zip, %w", sourceDir, err)
+	}
+
+	for _, mount := range opts.Mounts {
+		err := walkProjectExplained(mount.Dir, mount.Include == nil, func(path string, relPath string, info os.FileInfo) error {
+			f := &ArchiveFile{Path: path, Name: mount.Path + "/" + relPath, Size: info.Size()}
+			if mount.Include != nil && !mount.Include(relPath) {
+				if !explain {
+					return nil
+				}
+				f.Reason = "not an artifact"
+			}
+			return fn(f)
+		}, explainIgnored(mount.Path+"/"))
+		if err == nil {
+			err = ignoredErr
+		}
 		if err != nil {
+			return fmt.Errorf("cannot scan contents of dir %s to zip, %w", mount.Dir, err)
+		}
+	}
+
+	names := make([]string, 0, len(opts.Files))
+	for name := range opts.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := fn(&ArchiveFile{Name: name, Size: int64(len(opts.Files[name]))}); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// ensures to use forward slashes
-		relPath = filepath.ToSlash(relPath)
+// ListArchive lists the files of the archive WriteZip writes, with explain the files and directories left out of it are
+// listed too with the reason
+func ListArchive(sourceDir string, opts ZipOptions, explain bool) ([]*ArchiveFile, error) {
+	var files []*ArchiveFile
+	err := walkArchive(sourceDir, opts, explain, func(f *ArchiveFile) error {
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// DigestArchive returns the sha256 digest of the content of the archive WriteZip writes with opts, in hex, and the
+// number of its files. The archive has no timestamps, so the same files give the same archive and the same digest.
+func DigestArchive(sourceDir string, opts ZipOptions) (string, int, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "compression:%s\n", opts.Compression)
 
-		f, e := os.Open(path)
-		if e != nil {
-			return e
+	nbFiles := 0
+	err := walkArchive(sourceDir, opts, false, func(f *ArchiveFile) error {
+		// the name and the size delimit the content of every file
+		fmt.Fprintf(h, "%s\x00%d\x00", f.Name, f.Size)
+		nbFiles++
+		if f.Path == "" {
+			h.Write(opts.Files[f.Name])
+			return nil
 		}
-		defer f.Close()
-		contents, e := io.ReadAll(f)
-		if e != nil {
-			return e
+		file, err := os.Open(f.Path)
+		if err != nil {
+			return fmt.Errorf("cannot read file %s, %w", f.Name, err)
+		}
+		defer file.Close()
+		// a file which changed size while hashing is hashed with its new size
+		n, err := io.Copy(h, file)
+		if err != nil {
+			return fmt.Errorf("cannot read file %s, %w", f.Name, err)
+		}
+		if n != f.Size {
+			fmt.Fprintf(h, "\x00%d", n)
 		}
-
-		files[relPath] = contents
 		return nil
 	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("cannot scan contents of dir %s to zip, %w", sourceDir, err)
+		return "", 0, err
 	}
+	return hex.EncodeToString(h.Sum(nil)), nbFiles, nil
+}
 
-	buf := new(bytes.Buffer)
-	w := zip.NewWriter(buf)
+// VerifyArchiveDigest checks that the content of a zip archive written by WriteZip has the digest DigestArchive
+// returned for it, with or without the sha256: prefix. The compression is part of the digest, so every compression is
+// tried.
+func VerifyArchiveDigest(archive []byte, digest string) error {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return fmt.Errorf("invalid archive, %w", err)
+	}
 
-	filenames := make([]string, 0, len(files))
-	for name, content := range files {
-		filenames = append(filenames, name)
-		f, err := w.Create(name)
+	hashes := make([]hash.Hash, len(Compressions))
+	writers := make([]io.Writer, len(Compressions))
+	for i, c := range Compressions {
+		hashes[i] = sha256.New()
+		fmt.Fprintf(hashes[i], "compression:%s\n", c)
+		writers[i] = hashes[i]
+	}
+	h := io.MultiWriter(writers...)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", f.Name, f.UncompressedSize64)
+		rc, err := f.Open()
 		if err != nil {
-			return nil, 0, fmt.Errorf("cannot compress file %s of dir %s, %w", name, sourceDir, err)
+			return fmt.Errorf("cannot read file %s, %w", f.Name, err)
 		}
-		_, err = f.Write(content)
+		_, err = io.Copy(h, rc)
+		rc.Close()
 		if err != nil {
-			return nil, 0, fmt.Errorf("cannot compress file %s of dir %s, %w", name, sourceDir, err)
+			return fmt.Errorf("cannot read file %s, %w", f.Name, err)
 		}
 	}
 
+	expected := strings.TrimPrefix(strings.TrimSpace(digest), "sha256:")
+	for _, h := range hashes {
+		if strings.EqualFold(hex.EncodeToString(h.Sum(nil)), expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: the archive doesn't have the digest %s", checksum.ErrMismatch, digest)
+}
+
+// WriteZip streams the zipped project to w one file at a time, so that memory usage
+// does not grow with the size of the project
+func WriteZip(out io.Writer, sourceDir string, opts ZipOptions) (int, error) {
+	w := zip.NewWriter(out)
+	opts.Compression.register(w)
+
+	// the reader is reused for all files, its buffer holds the sample used to pick the compression
+	r := bufio.NewReaderSize(nil, entropySampleSize)
+
+	nbFiles := 0
+	// go through the dir and write the files one by one
+	err := walkArchive(sourceDir, opts, false, func(f *ArchiveFile) error {
+		if f.Path == "" {
+			fw, err := w.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate})
+			if err == nil {
+				_, err = fw.Write(opts.Files[f.Name])
+			}
+			if err != nil {
+				return fmt.Errorf("cannot write generated file %s, %w", f.Name, err)
+			}
+		} else if err := writeZipFile(w, r, f.Path, f.Name, opts.Compression); err != nil {
+			return fmt.Errorf("cannot compress file %s, %w", f.Name, err)
+		}
+		nbFiles++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
 	err = w.Close()
 	if err != nil {
-		return nil, 0, fmt.Errorf("cannot close zip writer for dir %s, %w", sourceDir, err)
+		return 0, fmt.Errorf("cannot close zip writer for dir %s, %w", sourceDir, err)
+	}
+
+	return nbFiles, nil
+}
+
+func writeZipFile(w *zip.Writer, r *bufio.Reader, path string, name string, compression Compression) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r.Reset(f)
+
+	sample, err := r.Peek(entropySampleSize)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	fw, err := w.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: compression.method(name, sample),
+	})
+	if err != nil {
+		return err
 	}
 
-	return buf.Bytes(), len(filenames), nil
+	_, err = io.Copy(fw, r)
+	return err
 }
@@ -0,0 +1,272 @@
+package runtime
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deta/space/internal/checksum"
+	"gotest.tools/v3/assert"
+)
+
+func writeFiles(t testing.TB, dir string, files map[string][]byte) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NilError(t, os.WriteFile(path, content, 0644))
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"main.py":               []byte("print('hello')"),
+		"static/logo.png":       bytes.Repeat([]byte{0x89}, 2048),
+		"node_modules/index.js": []byte("module.exports = {}"),
+		".env":                  []byte("SECRET=1"),
+	})
+
+	var buf bytes.Buffer
+	nbFiles, err := WriteZip(&buf, dir, ZipOptions{Compression: CompressionAuto})
+	assert.NilError(t, err)
+	assert.Equal(t, nbFiles, 2)
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NilError(t, err)
+
+	methods := make(map[string]uint16)
+	for _, f := range r.File {
+		methods[f.Name] = f.Method
+	}
+	assert.DeepEqual(t, methods, map[string]uint16{
+		"main.py":         zip.Deflate,
+		"static/logo.png": zip.Store,
+	})
+
+	f, err := r.Open("main.py")
+	assert.NilError(t, err)
+	content, err := io.ReadAll(f)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "print('hello')")
+}
+
+// benchmarkProject creates a project with text files and a few big binary files
+func benchmarkProject(b *testing.B) string {
+	dir := b.TempDir()
+	rng := rand.New(rand.NewSource(1))
+
+	files := make(map[string][]byte)
+	for i := 0; i < 200; i++ {
+		files[fmt.Sprintf("src/module_%d.js", i)] = bytes.Repeat([]byte(fmt.Sprintf("export const value%d = %d;\n", i, i)), 200)
+	}
+	for i := 0; i < 4; i++ {
+		content := make([]byte, 8*1024*1024)
+		rng.Read(content)
+		files[fmt.Sprintf("assets/blob_%d.bin", i)] = content
+	}
+	writeFiles(b, dir, files)
+
+	return dir
+}
+
+func BenchmarkZipDir(b *testing.B) {
+	dir := benchmarkProject(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ZipDir(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWriteZip allocations per op should stay far below the size of the project,
+// as files are streamed instead of being held in memory
+func BenchmarkWriteZip(b *testing.B) {
+	dir := benchmarkProject(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := WriteZip(io.Discard, dir, ZipOptions{Compression: CompressionAuto}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestWriteZipMounts(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{"main.py": []byte("import lib")})
+	lib := t.TempDir()
+	writeFiles(t, lib, map[string][]byte{
+		"lib/__init__.py":      []byte(""),
+		"lib/.venv/bin/python": []byte(""),
+	})
+
+	var buf bytes.Buffer
+	nbFiles, err := WriteZip(&buf, dir, ZipOptions{Mounts: []Mount{{Dir: lib, Path: ".space/external/shared-lib"}}})
+	assert.NilError(t, err)
+	assert.Equal(t, nbFiles, 2)
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NilError(t, err)
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	assert.DeepEqual(t, names, []string{"main.py", ".space/external/shared-lib/lib/__init__.py"})
+}
+
+func TestWriteZipInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"src/main.ts":   []byte("export {}"),
+		"dist/index.js": []byte("console.log('hello')"),
+	})
+
+	var buf bytes.Buffer
+	include := func(relPath string) bool { return filepath.Dir(relPath) == "dist" }
+	nbFiles, err := WriteZip(&buf, dir, ZipOptions{Include: include})
+	assert.NilError(t, err)
+	// dist is in the default .spaceignore, which doesn't apply to included files
+	assert.Equal(t, nbFiles, 1)
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NilError(t, err)
+	assert.Equal(t, r.File[0].Name, "dist/index.js")
+}
+
+func TestWriteZipFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"web/package.json": []byte("{}"),
+		"web/.npmrc":       []byte("engine-strict=true"),
+	})
+
+	var buf bytes.Buffer
+	nbFiles, err := WriteZip(&buf, dir, ZipOptions{Files: map[string][]byte{"web/.npmrc": []byte("engine-strict=true\nregistry=https://npm.example.com/")}})
+	assert.NilError(t, err)
+	assert.Equal(t, nbFiles, 2)
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NilError(t, err)
+	f, err := r.Open("web/.npmrc")
+	assert.NilError(t, err)
+	content, err := io.ReadAll(f)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "engine-strict=true\nregistry=https://npm.example.com/")
+}
+
+func TestListArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"main.py":               []byte("print('hello')"),
+		"debug.log":             []byte("log"),
+		"node_modules/index.js": []byte("module.exports = {}"),
+		"web/.npmrc":            []byte("engine-strict=true"),
+		"data.bin":              []byte("pointer"),
+		".spaceignore":          []byte("# logs\n*.log\n"),
+	})
+	opts := ZipOptions{Exclude: []string{"data.bin"}, Files: map[string][]byte{"web/.npmrc": []byte("registry=x")}}
+
+	files, err := ListArchive(dir, opts, false)
+	assert.NilError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	assert.DeepEqual(t, names, []string{"main.py", "web/.npmrc"})
+	assert.Equal(t, files[1].Path, "")
+	assert.Equal(t, files[1].Size, int64(len("registry=x")))
+
+	files, err = ListArchive(dir, opts, true)
+	assert.NilError(t, err)
+	reasons := make(map[string]string)
+	for _, f := range files {
+		if f.Path != "" || f.Reason != "" {
+			reasons[f.Name] = f.Reason
+		}
+	}
+	assert.DeepEqual(t, reasons, map[string]string{
+		".spaceignore": "default .spaceignore: .spaceignore",
+		"data.bin":     "excluded from the push",
+		"debug.log":    ".spaceignore line 2: *.log",
+		"main.py":      "",
+		"node_modules": "default .spaceignore: node_modules",
+		"web/.npmrc":   "replaced by a generated file",
+	})
+}
+
+func TestDigestArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"main.py":               []byte("print('hello')"),
+		"node_modules/index.js": []byte("module.exports = {}"),
+	})
+	opts := ZipOptions{Compression: CompressionAuto}
+
+	digest, nbFiles, err := DigestArchive(dir, opts)
+	assert.NilError(t, err)
+	assert.Equal(t, nbFiles, 1)
+	assert.Equal(t, len(digest), 64)
+
+	// ignored files don't change the digest
+	writeFiles(t, dir, map[string][]byte{"node_modules/index.js": []byte("module.exports = 1")})
+	same, _, err := DigestArchive(dir, opts)
+	assert.NilError(t, err)
+	assert.Equal(t, same, digest)
+
+	cases := []struct {
+		name  string
+		files map[string][]byte
+		opts  ZipOptions
+	}{
+		{name: "content", files: map[string][]byte{"main.py": []byte("print('hi')")}, opts: opts},
+		{name: "new file", files: map[string][]byte{"util.py": nil}, opts: opts},
+		{name: "compression", opts: ZipOptions{Compression: CompressionBest}},
+		{name: "generated file", opts: ZipOptions{Compression: CompressionAuto, Files: map[string][]byte{".npmrc": []byte("registry=x")}}},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string][]byte{"main.py": []byte("print('hello')")})
+		writeFiles(t, dir, c.files)
+		other, _, err := DigestArchive(dir, c.opts)
+		assert.NilError(t, err)
+		assert.Assert(t, other != digest, c.name)
+	}
+}
+
+func TestVerifyArchiveDigest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"main.py":       []byte("print('hello')"),
+		"static/a.css":  []byte("body {}"),
+		"static/b.png":  []byte("not really a png"),
+		"requirements":  nil,
+		".spaceignore":  []byte("*.log"),
+		"debug.log":     []byte("ignored"),
+		"lib/util/x.py": []byte("x = 1"),
+	})
+
+	for _, compression := range Compressions {
+		t.Run(string(compression), func(t *testing.T) {
+			opts := ZipOptions{Compression: compression, Files: map[string][]byte{".npmrc": []byte("registry=x")}}
+			var archive bytes.Buffer
+			_, err := WriteZip(&archive, dir, opts)
+			assert.NilError(t, err)
+			digest, _, err := DigestArchive(dir, opts)
+			assert.NilError(t, err)
+
+			assert.NilError(t, VerifyArchiveDigest(archive.Bytes(), digest))
+			assert.NilError(t, VerifyArchiveDigest(archive.Bytes(), "sha256:"+digest))
+			assert.ErrorIs(t, VerifyArchiveDigest(archive.Bytes(), "sha256:0123"), checksum.ErrMismatch)
+		})
+	}
+}
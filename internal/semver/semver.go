@@ -0,0 +1,149 @@
+// Package semver parses and bumps semantic versions like 1.2.3 or v1.2.3-beta.1
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Bump is the part of a version which is incremented
+type Bump int
+
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+var versionReg = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Version is a semantic version, build metadata is dropped when parsing
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// Parse parses a version with an optional v prefix
+func Parse(s string) (*Version, error) {
+	matches := versionReg.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return nil, fmt.Errorf("invalid semantic version %q", s)
+	}
+
+	var v Version
+	var err error
+	for i, part := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		if *part, err = strconv.Atoi(matches[i+1]); err != nil {
+			return nil, fmt.Errorf("invalid semantic version %q", s)
+		}
+	}
+	v.Prerelease = matches[4]
+	return &v, nil
+}
+
+// String formats the version without a v prefix
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Bump returns the next version, a prerelease is released as is by a bump which doesn't go beyond it
+func (v *Version) Bump(b Bump) *Version {
+	next := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch b {
+	case BumpMajor:
+		if v.Prerelease == "" || v.Minor != 0 || v.Patch != 0 {
+			next.Major++
+		}
+		next.Minor, next.Patch = 0, 0
+	case BumpMinor:
+		if v.Prerelease == "" || v.Patch != 0 {
+			next.Minor++
+		}
+		next.Patch = 0
+	case BumpPatch:
+		if v.Prerelease == "" {
+			next.Patch++
+		}
+	default:
+		next.Prerelease = v.Prerelease
+	}
+	return &next
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal or higher than other
+func (v *Version) Compare(other *Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// comparePrerelease compares the dot separated identifiers, numeric ones are compared numerically
+func comparePrerelease(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case as[i] < bs[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
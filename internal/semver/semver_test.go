@@ -0,0 +1,71 @@
+package semver
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		version  string
+		expected string
+		err      bool
+	}{
+		{version: "1.2.3", expected: "1.2.3"},
+		{version: "v0.10.0", expected: "0.10.0"},
+		{version: "1.0.0-beta.1+build.5", expected: "1.0.0-beta.1"},
+		{version: "1.2", err: true},
+		{version: "01.2.3", err: true},
+		{version: "latest", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.version, func(t *testing.T) {
+			v, err := Parse(c.version)
+			if c.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, v.String(), c.expected)
+		})
+	}
+}
+
+func TestBump(t *testing.T) {
+	cases := []struct {
+		version  string
+		bump     Bump
+		expected string
+	}{
+		{version: "1.2.3", bump: BumpPatch, expected: "1.2.4"},
+		{version: "1.2.3", bump: BumpMinor, expected: "1.3.0"},
+		{version: "1.2.3", bump: BumpMajor, expected: "2.0.0"},
+		{version: "1.2.3", bump: BumpNone, expected: "1.2.3"},
+		{version: "2.0.0-rc.1", bump: BumpMajor, expected: "2.0.0"},
+		{version: "2.0.0-rc.1", bump: BumpPatch, expected: "2.0.0"},
+		{version: "1.2.1-rc.1", bump: BumpMinor, expected: "1.3.0"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.version+" "+c.bump.String(), func(t *testing.T) {
+			v, err := Parse(c.version)
+			assert.NilError(t, err)
+			assert.Equal(t, v.Bump(c.bump).String(), c.expected)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ordered := []string{"0.9.0", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0", "1.0.1", "1.10.0", "2.0.0"}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, err := Parse(ordered[i])
+		assert.NilError(t, err)
+		b, err := Parse(ordered[i+1])
+		assert.NilError(t, err)
+		assert.Equal(t, a.Compare(b), -1, "%s < %s", ordered[i], ordered[i+1])
+		assert.Equal(t, b.Compare(a), 1, "%s > %s", ordered[i+1], ordered[i])
+		assert.Equal(t, a.Compare(a), 0)
+	}
+}
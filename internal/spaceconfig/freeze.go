@@ -0,0 +1,186 @@
+package spaceconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// yearlyLayout is a date repeated every year
+	yearlyLayout = "01-02"
+	// dateLayout is a single date
+	dateLayout = "2006-01-02"
+)
+
+// FreezeWindow is a time in which releases are frozen, on days of the week or from one date to another. The dates
+// are inclusive and either repeat every year, e.g. 12-24 to 01-02, or are single dates, e.g. 2023-11-20.
+type FreezeWindow struct {
+	Name string   `yaml:"name,omitempty"`
+	Days []string `yaml:"days,omitempty"`
+	From string   `yaml:"from,omitempty"`
+	To   string   `yaml:"to,omitempty"`
+	// Timezone of the days and dates, e.g. Europe/Berlin, defaults to the local time
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+func (w *FreezeWindow) String() string {
+	var parts []string
+	if len(w.Days) > 0 {
+		parts = append(parts, "on "+strings.Join(w.Days, ", "))
+	}
+	if w.From != "" {
+		parts = append(parts, fmt.Sprintf("from %s to %s", w.From, w.To))
+	}
+	description := strings.Join(parts, " and ")
+	if w.Name != "" {
+		description = fmt.Sprintf("%s (%s)", w.Name, description)
+	}
+	return description
+}
+
+func (w *FreezeWindow) validate() error {
+	if len(w.Days) == 0 && w.From == "" && w.To == "" {
+		return fmt.Errorf("freeze window %s has neither days nor dates", w.Name)
+	}
+	for _, day := range w.Days {
+		if _, err := parseWeekday(day); err != nil {
+			return err
+		}
+	}
+	if (w.From == "") != (w.To == "") {
+		return fmt.Errorf("freeze window %s needs both from and to", w.Name)
+	}
+	if w.From != "" {
+		from, yearly, err := parseDate(w.From)
+		if err != nil {
+			return err
+		}
+		to, toYearly, err := parseDate(w.To)
+		if err != nil {
+			return err
+		}
+		if yearly != toYearly {
+			return fmt.Errorf("freeze window %s mixes yearly dates and single dates", w.Name)
+		}
+		if !yearly && to.Before(from) {
+			return fmt.Errorf("freeze window %s ends before it starts", w.Name)
+		}
+	}
+	if _, err := w.location(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *FreezeWindow) location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("freeze window %s has an unknown timezone %s", w.Name, w.Timezone)
+	}
+	return loc, nil
+}
+
+// Contains reports if releases are frozen at t
+func (w *FreezeWindow) Contains(t time.Time) bool {
+	loc, err := w.location()
+	if err != nil {
+		return false
+	}
+	t = t.In(loc)
+
+	for _, day := range w.Days {
+		if weekday, err := parseWeekday(day); err == nil && weekday == t.Weekday() {
+			return true
+		}
+	}
+	if w.From == "" {
+		return false
+	}
+
+	from, yearly, err := parseDate(w.From)
+	if err != nil {
+		return false
+	}
+	to, _, err := parseDate(w.To)
+	if err != nil {
+		return false
+	}
+	if !yearly {
+		day := t.Format(dateLayout)
+		return day >= from.Format(dateLayout) && day <= to.Format(dateLayout)
+	}
+
+	// yearly windows may wrap around the end of the year, e.g. 12-24 to 01-02
+	day, start, end := t.Format(yearlyLayout), from.Format(yearlyLayout), to.Format(yearlyLayout)
+	if start <= end {
+		return day >= start && day <= end
+	}
+	return day >= start || day <= end
+}
+
+// ActiveFreeze returns the first freeze window which contains t, or nil if releases aren't frozen
+func (c *Config) ActiveFreeze(t time.Time) *FreezeWindow {
+	for _, w := range c.Freeze {
+		if w.Contains(t) {
+			return w
+		}
+	}
+	return nil
+}
+
+// Period is a range of days, Start and End are the first and the last day at midnight
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Periods returns the frozen days from the day of from to the day of to, consecutive days are merged into one period.
+// The days are in the timezone of the window.
+func (w *FreezeWindow) Periods(from time.Time, to time.Time) []Period {
+	loc, err := w.location()
+	if err != nil {
+		return nil
+	}
+	from, to = from.In(loc), to.In(loc)
+
+	var periods []Period
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for !day.After(to) {
+		// noon is on the same day even if the clocks change
+		if w.Contains(day.Add(12 * time.Hour)) {
+			if n := len(periods); n > 0 && periods[n-1].End.AddDate(0, 0, 1).Equal(day) {
+				periods[n-1].End = day
+			} else {
+				periods = append(periods, Period{Start: day, End: day})
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return periods
+}
+
+func parseWeekday(day string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s := strings.ToLower(day); s == name || s == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q in freeze window", day)
+}
+
+// parseDate parses a yearly date like 12-24 or a single date like 2023-12-24
+func parseDate(s string) (date time.Time, yearly bool, err error) {
+	if date, err := time.Parse(yearlyLayout, s); err == nil {
+		return date, true, nil
+	}
+	date, err = time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q in freeze window, use MM-DD or YYYY-MM-DD", s)
+	}
+	return date, false, nil
+}
@@ -0,0 +1,85 @@
+package spaceconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFreezeWindowContains(t *testing.T) {
+	weekend := &FreezeWindow{Name: "weekend", Days: []string{"saturday", "Sun"}, Timezone: "UTC"}
+	holidays := &FreezeWindow{Name: "holidays", From: "12-24", To: "01-02", Timezone: "UTC"}
+	launch := &FreezeWindow{Name: "launch", From: "2023-11-20", To: "2023-11-22", Timezone: "UTC"}
+
+	cases := []struct {
+		window   *FreezeWindow
+		time     string
+		expected bool
+	}{
+		{window: weekend, time: "2023-11-18T10:00:00Z", expected: true},
+		{window: weekend, time: "2023-11-19T23:59:00Z", expected: true},
+		{window: weekend, time: "2023-11-20T00:00:00Z", expected: false},
+		{window: holidays, time: "2023-12-24T00:00:00Z", expected: true},
+		{window: holidays, time: "2024-01-02T18:00:00Z", expected: true},
+		{window: holidays, time: "2024-01-03T00:00:00Z", expected: false},
+		{window: holidays, time: "2023-12-23T23:59:00Z", expected: false},
+		{window: launch, time: "2023-11-22T12:00:00Z", expected: true},
+		{window: launch, time: "2024-11-21T12:00:00Z", expected: false},
+	}
+
+	for _, c := range cases {
+		tm, err := time.Parse(time.RFC3339, c.time)
+		assert.NilError(t, err)
+		assert.Equal(t, c.window.Contains(tm), c.expected, "%s %s", c.window.Name, c.time)
+	}
+}
+
+func TestLoadFreeze(t *testing.T) {
+	cases := []struct {
+		config string
+		err    string
+	}{
+		{config: "freeze:\n  - name: weekend\n    days: [saturday, sunday]\n"},
+		{config: "freeze:\n  - name: holidays\n    from: 12-24\n    to: 01-02\n"},
+		{config: "freeze:\n  - name: weekend\n    days: [caturday]\n", err: "unknown day"},
+		{config: "freeze:\n  - name: holidays\n    from: 12-24\n", err: "needs both from and to"},
+		{config: "freeze:\n  - name: launch\n    from: 2023-11-22\n    to: 2023-11-20\n", err: "ends before it starts"},
+		{config: "freeze:\n  - name: mixed\n    from: 12-24\n    to: 2024-01-02\n", err: "mixes yearly dates"},
+		{config: "freeze:\n  - name: empty\n", err: "neither days nor dates"},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(c.config), 0644))
+		_, err := Load(dir)
+		if c.err != "" {
+			assert.ErrorContains(t, err, c.err)
+			continue
+		}
+		assert.NilError(t, err)
+	}
+}
+
+func TestFreezeWindowPeriods(t *testing.T) {
+	weekend := &FreezeWindow{Name: "weekend", Days: []string{"saturday", "sunday"}, Timezone: "UTC"}
+	holidays := &FreezeWindow{Name: "holidays", From: "12-24", To: "01-02", Timezone: "UTC"}
+
+	day := func(s string) time.Time {
+		d, err := time.Parse(dateLayout, s)
+		assert.NilError(t, err)
+		return d
+	}
+
+	// from a thursday to the monday after next
+	periods := weekend.Periods(day("2023-11-16").Add(15*time.Hour), day("2023-11-27"))
+	assert.DeepEqual(t, periods, []Period{
+		{Start: day("2023-11-18"), End: day("2023-11-19")},
+		{Start: day("2023-11-25"), End: day("2023-11-26")},
+	})
+
+	periods = holidays.Periods(day("2023-12-30"), day("2024-01-31"))
+	assert.DeepEqual(t, periods, []Period{{Start: day("2023-12-30"), End: day("2024-01-02")}})
+}
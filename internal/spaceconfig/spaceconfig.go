@@ -0,0 +1,233 @@
+// Package spaceconfig reads the .spaceconfig file, which holds project settings shared by a team
+package spaceconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName of the config file in the root of a project
+const FileName = ".spaceconfig"
+
+// Config of a project, committed together with the Spacefile
+type Config struct {
+	Environments map[string]*Environment `yaml:"environments,omitempty"`
+	// Protected lists the ids of projects which are protected like a protected environment, e.g. the linked project
+	Protected []string `yaml:"protected,omitempty"`
+	// Tests are keyed by the name of the micro
+	Tests map[string]*Test `yaml:"test,omitempty"`
+	// Freeze lists the windows in which space release refuses to run without --override-freeze
+	Freeze []*FreezeWindow `yaml:"freeze,omitempty"`
+	// RequireApproval requires space release --approved-by for every project, see Environment.RequireApproval
+	RequireApproval bool `yaml:"require_approval,omitempty"`
+	// Vendor lists the shared packages copied into a micro by space vendor, keyed by the name of the micro
+	Vendor map[string][]*VendorPackage `yaml:"vendor,omitempty"`
+	// Dependencies lists the data and the external services used by a micro, keyed by the name of the micro, for
+	// space graph
+	Dependencies map[string][]*Dependency `yaml:"dependencies,omitempty"`
+}
+
+// Environment is a project which is deployed from a set of branches
+type Environment struct {
+	// Branches are matched with path.Match, e.g. release/*
+	Branches []string `yaml:"branches"`
+	Project  string   `yaml:"project"`
+	// Protected environments require typing the name of the project before destructive commands like releases
+	Protected bool `yaml:"protected,omitempty"`
+	// RequireApproval requires a second person to approve releases of the project with space release --approved-by
+	RequireApproval bool `yaml:"require_approval,omitempty"`
+}
+
+// Test of a micro run by space test
+type Test struct {
+	Run string `yaml:"run"`
+	// Matrix lists the engines to test with, e.g. python3.9 and python3.11
+	Matrix []string `yaml:"matrix,omitempty"`
+}
+
+// VendorPackage is a directory shared by several micros which is copied into each of them
+type VendorPackage struct {
+	// Src is relative to the project root, e.g. ../shared/utils
+	Src string `yaml:"src"`
+	// Dest is relative to the src of the micro, defaults to vendor/<name of src>
+	Dest string `yaml:"dest,omitempty"`
+}
+
+// kinds of dependencies
+const (
+	DependencyBase     = "base"
+	DependencyDrive    = "drive"
+	DependencyExternal = "external"
+)
+
+// DependencyKinds are the kinds of dependencies
+var DependencyKinds = []string{DependencyBase, DependencyDrive, DependencyExternal}
+
+// Dependency is a Base, a Drive or an external service used by a micro. Micros which use a dependency with the same
+// kind and name share it.
+type Dependency struct {
+	Name string `yaml:"name"`
+	// Kind is base, drive or external
+	Kind string `yaml:"kind"`
+	// URL of an external service, e.g. https://api.stripe.com
+	URL string `yaml:"url,omitempty"`
+}
+
+func (d *Dependency) validate(micro string) error {
+	if d.Name == "" {
+		return fmt.Errorf("dependency of micro %s has no name", micro)
+	}
+	for _, kind := range DependencyKinds {
+		if d.Kind == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("dependency %s of micro %s has an invalid kind %q, expected one of %s", d.Name, micro, d.Kind, strings.Join(DependencyKinds, ", "))
+}
+
+// Destination returns the directory relative to the src of the micro the package is copied to
+func (p *VendorPackage) Destination() string {
+	if p.Dest != "" {
+		return filepath.Clean(p.Dest)
+	}
+	return filepath.Join("vendor", filepath.Base(filepath.Clean(p.Src)))
+}
+
+func (p *VendorPackage) validate(micro string) error {
+	if p.Src == "" {
+		return fmt.Errorf("vendored package of micro %s has no src", micro)
+	}
+	// the destination is replaced by every copy, so it has to be a directory of its own inside of the micro
+	dest := filepath.ToSlash(p.Destination())
+	if filepath.IsAbs(p.Destination()) || dest == "." || dest == ".." || strings.HasPrefix(dest, "../") {
+		return fmt.Errorf("vendored package %s of micro %s needs a dest inside of the micro", p.Src, micro)
+	}
+	return nil
+}
+
+// Load reads the config of the project in dir, a missing file results in an empty config
+func Load(dir string) (*Config, error) {
+	content, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	return &c, nil
+}
+
+func (c *Config) validate() error {
+	for name, env := range c.Environments {
+		if env == nil || env.Project == "" {
+			return fmt.Errorf("environment %s has no project", name)
+		}
+		for _, branch := range env.Branches {
+			if _, err := path.Match(branch, ""); err != nil {
+				return fmt.Errorf("environment %s has an invalid branch pattern %q", name, branch)
+			}
+		}
+	}
+	for micro, test := range c.Tests {
+		if test == nil || test.Run == "" {
+			return fmt.Errorf("test of micro %s has no run command", micro)
+		}
+	}
+	for micro, packages := range c.Vendor {
+		for _, p := range packages {
+			if p == nil {
+				return fmt.Errorf("empty vendored package of micro %s", micro)
+			}
+			if err := p.validate(micro); err != nil {
+				return err
+			}
+		}
+	}
+	for micro, dependencies := range c.Dependencies {
+		for _, d := range dependencies {
+			if d == nil {
+				return fmt.Errorf("empty dependency of micro %s", micro)
+			}
+			if err := d.validate(micro); err != nil {
+				return err
+			}
+		}
+	}
+	for _, w := range c.Freeze {
+		if w == nil {
+			return fmt.Errorf("empty freeze window")
+		}
+		if err := w.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnvironmentNames returns the names of all environments in alphabetical order
+func (c *Config) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProtectedName returns the name of the protected environment of the project, or the project id if the project itself
+// is protected. It returns false if the project isn't protected.
+func (c *Config) ProtectedName(projectID string) (string, bool) {
+	for _, name := range c.EnvironmentNames() {
+		if env := c.Environments[name]; env.Protected && env.Project == projectID {
+			return name, true
+		}
+	}
+	for _, id := range c.Protected {
+		if id == projectID {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// ApprovalRequired reports if releases of the project must name an approver
+func (c *Config) ApprovalRequired(projectID string) bool {
+	if c.RequireApproval {
+		return true
+	}
+	for _, env := range c.Environments {
+		if env.RequireApproval && env.Project == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the names of the environments deployed from branch in alphabetical order
+func (c *Config) Match(branch string) []string {
+	var matches []string
+	for _, name := range c.EnvironmentNames() {
+		for _, pattern := range c.Environments[name].Branches {
+			if ok, _ := path.Match(pattern, branch); ok {
+				matches = append(matches, name)
+				break
+			}
+		}
+	}
+	return matches
+}
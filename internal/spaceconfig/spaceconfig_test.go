@@ -0,0 +1,127 @@
+package spaceconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const testConfig = `environments:
+  production:
+    branches: [main]
+    project: a1
+  staging:
+    branches: [develop, "release/*"]
+    project: b2
+  hotfix:
+    branches: ["release/*"]
+    project: c3
+`
+
+func TestMatch(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(testConfig), 0644))
+
+	config, err := Load(dir)
+	assert.NilError(t, err)
+
+	cases := []struct {
+		branch   string
+		expected []string
+	}{
+		{branch: "main", expected: []string{"production"}},
+		{branch: "develop", expected: []string{"staging"}},
+		{branch: "release/1.0", expected: []string{"hotfix", "staging"}},
+		{branch: "feature/login", expected: nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.branch, func(t *testing.T) {
+			assert.DeepEqual(t, config.Match(c.branch), c.expected)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	c, err := Load(t.TempDir())
+	assert.NilError(t, err)
+	assert.Equal(t, len(c.Environments), 0)
+
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte("environments:\n  production:\n    branches: [main]\n"), 0644))
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "has no project")
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte("test:\n  backend:\n    matrix: [python3.9]\n"), 0644))
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "has no run command")
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte("dependencies:\n  backend:\n    - name: users\n      kind: table\n"), 0644))
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, `invalid kind "table"`)
+}
+
+func TestProtectedName(t *testing.T) {
+	dir := t.TempDir()
+	content := testConfig + "    protected: true\nprotected: [d4]\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644))
+
+	config, err := Load(dir)
+	assert.NilError(t, err)
+
+	cases := []struct {
+		projectID string
+		expected  string
+		protected bool
+	}{
+		{projectID: "c3", expected: "hotfix", protected: true},
+		{projectID: "d4", expected: "d4", protected: true},
+		{projectID: "a1", protected: false},
+	}
+
+	for _, c := range cases {
+		name, protected := config.ProtectedName(c.projectID)
+		assert.Equal(t, protected, c.protected, c.projectID)
+		assert.Equal(t, name, c.expected, c.projectID)
+	}
+}
+
+func TestApprovalRequired(t *testing.T) {
+	dir := t.TempDir()
+	content := testConfig + "    require_approval: true\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644))
+
+	config, err := Load(dir)
+	assert.NilError(t, err)
+	assert.Assert(t, config.ApprovalRequired("c3"))
+	assert.Assert(t, !config.ApprovalRequired("a1"))
+
+	config.RequireApproval = true
+	assert.Assert(t, config.ApprovalRequired("a1"))
+}
+
+func TestVendorDestination(t *testing.T) {
+	cases := []struct {
+		pkg      VendorPackage
+		expected string
+		err      bool
+	}{
+		{pkg: VendorPackage{Src: "../shared/utils/"}, expected: "vendor/utils"},
+		{pkg: VendorPackage{Src: "libs/auth", Dest: "app/auth"}, expected: "app/auth"},
+		{pkg: VendorPackage{Src: "libs/auth", Dest: "."}, err: true},
+		{pkg: VendorPackage{Src: "libs/auth", Dest: "../auth"}, err: true},
+		{pkg: VendorPackage{Dest: "auth"}, err: true},
+	}
+
+	for _, c := range cases {
+		err := c.pkg.validate("api")
+		if c.err {
+			assert.Assert(t, err != nil, c.pkg)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, filepath.ToSlash(c.pkg.Destination()), c.expected)
+	}
+}
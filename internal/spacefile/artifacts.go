@@ -0,0 +1,71 @@
+package spacefile
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/deta/space/shared"
+	ignore "github.com/sabhiram/go-gitignore"
+	"gopkg.in/yaml.v3"
+)
+
+// Artifacts returns a filter of the files of the project which are pushed with space push --prebuilt: a file is pushed
+// if it matches the artifacts of a micro whose src contains it. Every micro needs artifacts.
+func Artifacts(micros []*shared.Micro) (func(relPath string) bool, error) {
+	type matcher struct {
+		src      string
+		patterns *ignore.GitIgnore
+	}
+	var matchers []matcher
+	for _, micro := range micros {
+		if len(micro.Artifacts) == 0 {
+			return nil, fmt.Errorf("micro %s has no artifacts to push", micro.Name)
+		}
+		matchers = append(matchers, matcher{
+			src:      path.Clean(filepath.ToSlash(micro.Src)),
+			patterns: ignore.CompileIgnoreLines(micro.Artifacts...),
+		})
+	}
+
+	return func(relPath string) bool {
+		for _, m := range matchers {
+			rel := relPath
+			if m.src != "." {
+				var ok bool
+				if rel, ok = cutDir(relPath, m.src); !ok {
+					continue
+				}
+			}
+			if m.patterns.MatchesPath(rel) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// cutDir returns p relative to dir if it's inside of dir
+func cutDir(p string, dir string) (string, bool) {
+	if !strings.HasPrefix(p, dir+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(p, dir+"/"), true
+}
+
+// StripCommands removes the build commands of the micros from the Spacefile content, the artifacts of a prebuilt push
+// are already built
+func StripCommands(content []byte) ([]byte, error) {
+	var root map[string]any
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+	micros, _ := root["micros"].([]any)
+	for _, m := range micros {
+		if micro, ok := m.(map[string]any); ok {
+			delete(micro, "commands")
+		}
+	}
+	return yaml.Marshal(root)
+}
@@ -0,0 +1,50 @@
+package spacefile
+
+import (
+	"testing"
+
+	"github.com/deta/space/shared"
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+)
+
+func TestArtifacts(t *testing.T) {
+	include, err := Artifacts([]*shared.Micro{
+		{Name: "web", Src: ".", Artifacts: []string{"dist/"}},
+		{Name: "api", Src: "./api", Artifacts: []string{"bin/server", "*.toml", "!dev.toml"}},
+	})
+	assert.NilError(t, err)
+
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "dist/index.html", expected: true},
+		{path: "dist/assets/app.js", expected: true},
+		{path: "src/main.ts", expected: false},
+		{path: "api/bin/server", expected: true},
+		{path: "api/config.toml", expected: true},
+		{path: "api/dev.toml", expected: false},
+		{path: "api/main.go", expected: false},
+		{path: "bin/server", expected: false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, include(c.path), c.expected, c.path)
+	}
+
+	_, err = Artifacts([]*shared.Micro{{Name: "api", Src: "api"}})
+	assert.ErrorContains(t, err, "micro api has no artifacts")
+}
+
+func TestStripCommands(t *testing.T) {
+	content := []byte("v: 0\nmicros:\n  - name: api\n    src: api\n    commands:\n      - go build -o server\n    run: ./server\n")
+
+	stripped, err := StripCommands(content)
+	assert.NilError(t, err)
+
+	var s Spacefile
+	assert.NilError(t, yaml.Unmarshal(stripped, &s))
+	assert.Equal(t, len(s.Micros[0].Commands), 0)
+	assert.Equal(t, s.Micros[0].Run, "./server")
+}
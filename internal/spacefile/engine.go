@@ -0,0 +1,23 @@
+package spacefile
+
+import (
+	"encoding/json"
+)
+
+// Engines returns the engines a micro of a Spacefile can have, as listed by the schema
+func Engines() []string {
+	var schema struct {
+		Definitions struct {
+			Micro struct {
+				Properties struct {
+					Engine struct {
+						Enum []string `json:"enum"`
+					} `json:"engine"`
+				} `json:"properties"`
+			} `json:"micro"`
+		} `json:"definitions"`
+	}
+	// the schema is compiled when the package is loaded, so it's valid json
+	_ = json.Unmarshal([]byte(spacefileSchemaString), &schema)
+	return schema.Definitions.Micro.Properties.Engine.Enum
+}
@@ -0,0 +1,118 @@
+package spacefile
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/deta/space/shared"
+	"gopkg.in/yaml.v3"
+)
+
+// ExternalDir is the directory of the archive the sources of micros outside of the project root are put in, it's
+// ignored by the .spaceignore so that it can't clash with files of the project
+const ExternalDir = ".space/external"
+
+// ExternalSource is the src of a micro outside of the project root, like ../shared-lib in a monorepo
+type ExternalSource struct {
+	// Src is the src of the micro in the Spacefile
+	Src string
+	// Dir is the absolute path of the source
+	Dir string
+	// Archive is the path of the source in the archive
+	Archive string
+}
+
+// ExternalSources returns the sources of the micros outside of rootDir. Micros with the same src share a source and
+// sources are named after their directory. A source can't contain the project, it would be archived twice.
+func ExternalSources(rootDir string, micros []*shared.Micro) ([]ExternalSource, error) {
+	root, err := realPath(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []ExternalSource
+	seen := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, micro := range micros {
+		src := path.Clean(filepath.ToSlash(micro.Src))
+		if seen[src] {
+			continue
+		}
+		if filepath.IsAbs(micro.Src) {
+			return nil, fmt.Errorf("micro %s: src %s has to be relative to the project root", micro.Name, micro.Src)
+		}
+
+		dir, err := realPath(filepath.Join(rootDir, filepath.FromSlash(src)))
+		if err != nil {
+			return nil, fmt.Errorf("micro %s: %w", micro.Name, err)
+		}
+		if within(root, dir) {
+			continue
+		}
+		if within(dir, root) {
+			return nil, fmt.Errorf("micro %s: src %s contains the project root", micro.Name, micro.Src)
+		}
+		if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+			return nil, fmt.Errorf("micro %s: src %s is not a directory", micro.Name, micro.Src)
+		}
+
+		name := filepath.Base(dir)
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s-%d", filepath.Base(dir), i)
+		}
+		names[name] = true
+		seen[src] = true
+		sources = append(sources, ExternalSource{Src: src, Dir: dir, Archive: path.Join(ExternalDir, name)})
+	}
+	return sources, nil
+}
+
+// realPath returns the absolute path with the symlinks resolved, so that a symlink can't hide where a src is
+func realPath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// within returns true if p is dir or inside of it
+func within(dir string, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// RewriteSrc replaces the src of the micros of the Spacefile content with the path of their external source in the
+// archive
+func RewriteSrc(content []byte, sources []ExternalSource) ([]byte, error) {
+	if len(sources) == 0 {
+		return content, nil
+	}
+	archived := make(map[string]string, len(sources))
+	for _, source := range sources {
+		archived[source.Src] = source.Archive
+	}
+
+	var root map[string]any
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+	micros, _ := root["micros"].([]any)
+	for _, m := range micros {
+		micro, ok := m.(map[string]any)
+		if !ok {
+			continue
+		}
+		src, ok := micro["src"].(string)
+		if !ok {
+			continue
+		}
+		if p, ok := archived[path.Clean(filepath.ToSlash(src))]; ok {
+			micro["src"] = p
+		}
+	}
+	return yaml.Marshal(root)
+}
@@ -0,0 +1,69 @@
+package spacefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deta/space/shared"
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+)
+
+func TestExternalSources(t *testing.T) {
+	monorepo := t.TempDir()
+	for _, dir := range []string{"app/backend", "shared-lib", "libs/shared-lib"} {
+		assert.NilError(t, os.MkdirAll(filepath.Join(monorepo, dir), 0755))
+	}
+	rootDir := filepath.Join(monorepo, "app")
+
+	cases := []struct {
+		name     string
+		srcs     []string
+		expected []string
+		err      bool
+	}{
+		{name: "inside the project", srcs: []string{"backend", "."}},
+		{name: "outside the project", srcs: []string{"backend", "../shared-lib"}, expected: []string{".space/external/shared-lib"}},
+		{name: "same src", srcs: []string{"../shared-lib", "./../shared-lib/"}, expected: []string{".space/external/shared-lib"}},
+		{
+			name:     "same name",
+			srcs:     []string{"../shared-lib", "../libs/shared-lib"},
+			expected: []string{".space/external/shared-lib", ".space/external/shared-lib-2"},
+		},
+		{name: "contains the project", srcs: []string{".."}, err: true},
+		{name: "missing", srcs: []string{"../missing"}, err: true},
+	}
+
+	for _, c := range cases {
+		var micros []*shared.Micro
+		for i, src := range c.srcs {
+			micros = append(micros, &shared.Micro{Name: string(rune('a' + i)), Src: src})
+		}
+		sources, err := ExternalSources(rootDir, micros)
+		if c.err {
+			assert.Assert(t, err != nil, c.name)
+			continue
+		}
+		assert.NilError(t, err, c.name)
+
+		var archived []string
+		for _, source := range sources {
+			archived = append(archived, source.Archive)
+		}
+		assert.DeepEqual(t, archived, c.expected)
+	}
+}
+
+func TestRewriteSrc(t *testing.T) {
+	content := []byte("v: 0\nmicros:\n  - name: api\n    src: ../shared-lib/\n  - name: web\n    src: web\n")
+	sources := []ExternalSource{{Src: "../shared-lib", Archive: ".space/external/shared-lib"}}
+
+	rewritten, err := RewriteSrc(content, sources)
+	assert.NilError(t, err)
+
+	var s Spacefile
+	assert.NilError(t, yaml.Unmarshal(rewritten, &s))
+	assert.Equal(t, s.Micros[0].Src, ".space/external/shared-lib")
+	assert.Equal(t, s.Micros[1].Src, "web")
+}
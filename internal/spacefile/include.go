@@ -0,0 +1,254 @@
+package spacefile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey lists fragment files which are merged into the Spacefile
+const includeKey = "include"
+
+var ErrIncludeCycle = errors.New("Spacefile fragments include each other")
+
+// Compose reads the Spacefile and merges the fragments of its include list into it.
+// The content is returned as is if the Spacefile has no includes, yaml aliases or extension fields.
+//
+// Fragments are partial Spacefiles, which can include other fragments themselves. Their micros are added to the
+// micros of the Spacefile or merged into the micro with the same name, e.g. to add shared actions. The src of micros
+// in fragments is relative to the directory of the fragment.
+func Compose(spacefilePath string) ([]byte, error) {
+	content, err := os.ReadFile(spacefilePath)
+	if err != nil {
+		return nil, err
+	}
+	composed, _, err := compose(spacefilePath, content)
+	return composed, err
+}
+
+// compose merges the includes of the Spacefile content and resolves its aliases, included is false if it has no includes
+func compose(spacefilePath string, content []byte) (composed []byte, included bool, err error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, false, &ValidationError{Issues: yamlIssues(err), msg: ErrInvalidSpacefile.Error(), err: ErrInvalidSpacefile}
+	}
+	var root map[string]any
+	if err := node.Decode(&root); err != nil {
+		return nil, false, &ValidationError{Issues: yamlIssues(err), msg: ErrInvalidSpacefile.Error(), err: ErrInvalidSpacefile}
+	}
+
+	_, included = root[includeKey]
+	if !included && !needsFlattening(&node) {
+		return content, false, nil
+	}
+	stripExtensions(root)
+
+	if included {
+		rootDir := filepath.Dir(spacefilePath)
+		abs, err := filepath.Abs(spacefilePath)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := resolveIncludes(root, rootDir, rootDir, map[string]bool{abs: true}); err != nil {
+			return nil, false, err
+		}
+	}
+	composed, err = yaml.Marshal(root)
+	return composed, included, err
+}
+
+// resolveIncludes merges the fragments included by doc, a fragment in dir, into it
+func resolveIncludes(doc map[string]any, rootDir string, dir string, visiting map[string]bool) error {
+	raw, ok := doc[includeKey]
+	if !ok {
+		return nil
+	}
+	delete(doc, includeKey)
+
+	patterns, ok := toStrings(raw)
+	if !ok {
+		return fmt.Errorf("%s has to be a list of files", includeKey)
+	}
+
+	for _, pattern := range patterns {
+		paths, err := includedPaths(dir, pattern)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			if visiting[abs] {
+				return fmt.Errorf("%w: %s", ErrIncludeCycle, path)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read included file %s: %w", path, err)
+			}
+			var fragment map[string]any
+			if err := yaml.Unmarshal(content, &fragment); err != nil {
+				return fmt.Errorf("failed to parse included file %s: %w", path, err)
+			}
+
+			visiting[abs] = true
+			err = resolveIncludes(fragment, rootDir, filepath.Dir(path), visiting)
+			delete(visiting, abs)
+			if err != nil {
+				return err
+			}
+
+			if err := rebaseSrc(fragment, rootDir, filepath.Dir(path)); err != nil {
+				return err
+			}
+			if err := merge(doc, fragment); err != nil {
+				return fmt.Errorf("failed to include %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// includedPaths returns the files matching a pattern in alphabetical order, patterns without wildcards have to exist
+func includedPaths(dir string, pattern string) ([]string, error) {
+	full := filepath.Join(dir, filepath.FromSlash(pattern))
+	paths, err := filepath.Glob(full)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern %s: %w", pattern, err)
+	}
+	if len(paths) == 0 && !hasMeta(pattern) {
+		return nil, fmt.Errorf("included file %s: %w", full, os.ErrNotExist)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func hasMeta(pattern string) bool {
+	for _, c := range pattern {
+		if c == '*' || c == '?' || c == '[' {
+			return true
+		}
+	}
+	return false
+}
+
+// rebaseSrc makes the src of the micros of a fragment in dir relative to the root directory
+func rebaseSrc(fragment map[string]any, rootDir string, dir string) error {
+	micros, ok := fragment["micros"].([]any)
+	if !ok {
+		return nil
+	}
+	for _, m := range micros {
+		micro, ok := m.(map[string]any)
+		if !ok {
+			continue
+		}
+		src, ok := micro["src"].(string)
+		if !ok {
+			continue
+		}
+		rel, err := filepath.Rel(rootDir, filepath.Join(dir, src))
+		if err != nil {
+			return err
+		}
+		micro["src"] = filepath.ToSlash(rel)
+	}
+	return nil
+}
+
+// merge merges src into dst: micros are merged by name, other lists are appended, maps are merged
+// and scalars have to be equal
+func merge(dst map[string]any, src map[string]any) error {
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+
+		switch e := existing.(type) {
+		case map[string]any:
+			v, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("conflicting values for %s", key)
+			}
+			if err := merge(e, v); err != nil {
+				return err
+			}
+		case []any:
+			v, ok := value.([]any)
+			if !ok {
+				return fmt.Errorf("conflicting values for %s", key)
+			}
+			if key == "micros" {
+				merged, err := mergeMicros(e, v)
+				if err != nil {
+					return err
+				}
+				dst[key] = merged
+			} else {
+				dst[key] = append(e, v...)
+			}
+		default:
+			if !reflect.DeepEqual(existing, value) {
+				return fmt.Errorf("conflicting values for %s: %v and %v", key, existing, value)
+			}
+		}
+	}
+	return nil
+}
+
+func mergeMicros(dst []any, src []any) ([]any, error) {
+	byName := map[string]map[string]any{}
+	for _, m := range dst {
+		if micro, ok := m.(map[string]any); ok {
+			if name, ok := micro["name"].(string); ok {
+				byName[name] = micro
+			}
+		}
+	}
+
+	for _, m := range src {
+		micro, ok := m.(map[string]any)
+		if !ok {
+			dst = append(dst, m)
+			continue
+		}
+		name, _ := micro["name"].(string)
+		existing, ok := byName[name]
+		if !ok {
+			dst = append(dst, micro)
+			continue
+		}
+		if err := merge(existing, micro); err != nil {
+			return nil, fmt.Errorf("micro %s: %w", name, err)
+		}
+	}
+	return dst, nil
+}
+
+func toStrings(v any) ([]string, bool) {
+	if s, ok := v.(string); ok {
+		return []string{s}, true
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	var values []string
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, s)
+	}
+	return values, true
+}
@@ -0,0 +1,56 @@
+package spacefile
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestComposeIncludes(t *testing.T) {
+	s, err := ParseSpacefile("testdata/include/Spacefile")
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(s.Micros), 2)
+	backend := s.Micros[1]
+	assert.Equal(t, backend.Name, "backend")
+	assert.Equal(t, backend.Src, "backend")
+	assert.Equal(t, len(backend.Actions), 1)
+	assert.Equal(t, backend.Actions[0].ID, "cleanup")
+}
+
+func TestComposeWithoutIncludes(t *testing.T) {
+	raw, err := os.ReadFile("testdata/spacefile/single_micro.yaml")
+	assert.NilError(t, err)
+
+	content, err := Compose("testdata/spacefile/single_micro.yaml")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, content, raw)
+}
+
+func TestComposeErrors(t *testing.T) {
+	var cases = []struct {
+		name      string
+		spacefile string
+		err       error
+	}{
+		{
+			name:      "cycle",
+			spacefile: "testdata/include/cycle/Spacefile",
+			err:       ErrIncludeCycle,
+		},
+		{
+			name:      "missing fragment",
+			spacefile: "testdata/include/missing/Spacefile",
+			err:       os.ErrNotExist,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Compose(c.spacefile)
+			assert.Assert(t, errors.Is(err, c.err), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package spacefile
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlLineReg     = regexp.MustCompile(`line (\d+): (.*)`)
+	unknownFieldReg = regexp.MustCompile(`'([^']+)'`)
+)
+
+// Issue is a single problem found in a Spacefile, Line is 0 if it is not known
+type Issue struct {
+	Line    int
+	Message string
+}
+
+// ValidationError is returned by ParseSpacefile if the Spacefile is not valid yaml or doesn't match the schema
+type ValidationError struct {
+	Issues []Issue
+	msg    string
+	err    error
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// yamlIssues extracts the lines of a yaml syntax or type error
+func yamlIssues(err error) []Issue {
+	var messages []string
+	if te, ok := err.(*yaml.TypeError); ok {
+		messages = te.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	var issues []Issue
+	for _, msg := range messages {
+		matches := yamlLineReg.FindStringSubmatch(msg)
+		if len(matches) != 3 {
+			issues = append(issues, Issue{Message: strings.TrimPrefix(msg, "yaml: ")})
+			continue
+		}
+		line, _ := strconv.Atoi(matches[1])
+		issues = append(issues, Issue{Line: line, Message: matches[2]})
+	}
+	return issues
+}
+
+// schemaIssues returns an issue for every leaf of the validation error with the line of the offending value
+func schemaIssues(ve *jsonschema.ValidationError, root *yaml.Node) []Issue {
+	if len(ve.Causes) > 0 {
+		var issues []Issue
+		for _, c := range ve.Causes {
+			issues = append(issues, schemaIssues(c, root)...)
+		}
+		return issues
+	}
+
+	pointer := ve.InstanceLocation
+	// point to the first unknown field instead of the object containing it
+	if strings.HasSuffix(ve.KeywordLocation, "/additionalProperties") {
+		if matches := unknownFieldReg.FindStringSubmatch(ve.Message); len(matches) == 2 {
+			pointer = pointer + "/" + matches[1]
+		}
+	}
+
+	message := unknownFieldMessage(ve)
+	if ve.InstanceLocation != "" {
+		message = ve.InstanceLocation + " -> " + message
+	}
+	return []Issue{{Line: lineOf(root, pointer), Message: message}}
+}
+
+// lineOf returns the line of the node at the json pointer, or 0 if it can't be found
+func lineOf(root *yaml.Node, pointer string) int {
+	if root == nil {
+		return 0
+	}
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					next = node.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return node.Line
+			}
+			node = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return node.Line
+			}
+			node = node.Content[i]
+		default:
+			return node.Line
+		}
+	}
+	return node.Line
+}
@@ -0,0 +1,35 @@
+package spacefile
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestValidationIssues(t *testing.T) {
+	cases := []struct {
+		spacefile string
+		line      int
+	}{
+		{
+			spacefile: "testdata/spacefile/unknown_field.yaml",
+			line:      7,
+		},
+		{
+			spacefile: "testdata/spacefile/invalid_yaml.yaml",
+			line:      3,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.spacefile, func(t *testing.T) {
+			_, err := ParseSpacefile(c.spacefile)
+
+			var ve *ValidationError
+			assert.Assert(t, errors.As(err, &ve))
+			assert.Assert(t, len(ve.Issues) > 0)
+			assert.Equal(t, ve.Issues[0].Line, c.line)
+		})
+	}
+}
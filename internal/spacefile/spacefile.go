@@ -157,7 +157,7 @@ func PrettyValidationErrors(ve *jsonschema.ValidationError, v any, prefix string
 
 	// If there are no causes, just print the message
 	if len(ve.Causes) == 0 {
-		message := strings.Replace(ve.Message, "additionalProperties", "unknown field", 1)
+		message := unknownFieldMessage(ve)
 		parts := strings.Split(ve.InstanceLocation, "/")
 
 		leaf := parts[len(parts)-1]
@@ -241,7 +241,13 @@ func PrettyValidationErrors(ve *jsonschema.ValidationError, v any, prefix string
 	return strings.Join(rows, "\n")
 }
 
+// ParseSpacefile parses and validates the Spacefile at spacefilePath
 func ParseSpacefile(spacefilePath string) (*Spacefile, error) {
+	return ParseSpacefileWithOptions(spacefilePath, &ParseOptions{})
+}
+
+// ParseSpacefileWithOptions parses and validates the Spacefile at spacefilePath, includes and aliases are resolved
+func ParseSpacefileWithOptions(spacefilePath string, opts *ParseOptions) (*Spacefile, error) {
 	if _, err := os.Stat(spacefilePath); os.IsNotExist(err) {
 		return nil, ErrSpacefileNotFound
 	} else if err != nil {
@@ -254,37 +260,41 @@ func ParseSpacefile(spacefilePath string) (*Spacefile, error) {
 		return nil, fmt.Errorf("failed to read contents of spacefile file: %w", err)
 	}
 
-	var v any
-	if err := yaml.Unmarshal(content, &v); err != nil {
-		return nil, ErrInvalidSpacefile
+	raw := content
+	content, included, err := compose(spacefilePath, content)
+	if err != nil {
+		return nil, err
 	}
 
-	// validate against schema
-	if err := spacefileSchema.Validate(v); err != nil {
-		var ve *jsonschema.ValidationError
-		if errors.As(err, &ve) {
-			return nil, fmt.Errorf(PrettyValidationErrors(ve, v, ""))
+	if opts.Strict {
+		var root yaml.Node
+		yaml.Unmarshal(raw, &root)
+		if issues := strictIssues(&root); len(issues) > 0 {
+			messages := make([]string, 0, len(issues))
+			for _, issue := range issues {
+				messages = append(messages, fmt.Sprintf("L line %d: %s", issue.Line, issue.Message))
+			}
+			return nil, &ValidationError{Issues: issues, msg: "Spacefile\n" + strings.Join(messages, "\n")}
 		}
 	}
 
-	var spacefile Spacefile
-	if err := yaml.Unmarshal(content, &spacefile); err != nil {
-		return nil, ErrInvalidSpacefile
+	// lines of Spacefiles composed from fragments don't match any file
+	var root *yaml.Node
+	if !included {
+		root = &yaml.Node{}
+		yaml.Unmarshal(raw, root)
+	}
+	spacefile, err := parse(content, root)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMicros(spacefile.Micros); err != nil {
+		return nil, err
 	}
 
 	foundPrimaryMicro := false
-	micros := make(map[string]struct{})
 	for i, micro := range spacefile.Micros {
-		if _, ok := micros[micro.Name]; ok {
-			return nil, ErrDuplicateMicros
-		}
-		micros[micro.Name] = struct{}{}
-
 		if micro.Primary {
-			if foundPrimaryMicro {
-				return nil, ErrMultiplePrimary
-			}
-
 			foundPrimaryMicro = true
 			spacefile.Micros[i].Path = "/"
 			continue
@@ -308,17 +318,77 @@ func ParseSpacefile(spacefilePath string) (*Spacefile, error) {
 	}
 
 	if !foundPrimaryMicro {
-		if len(spacefile.Micros) == 1 {
-			spacefile.Micros[0].Primary = true
-			spacefile.Micros[0].Path = "/"
-		} else {
-			return nil, ErrNoPrimaryMicro
+		spacefile.Micros[0].Primary = true
+		spacefile.Micros[0].Path = "/"
+	}
+
+	return spacefile, nil
+}
+
+// Validate checks Spacefile content against the schema and the rules for micros, without resolving includes or
+// checking that the sources of the micros exist
+func Validate(content []byte) error {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return &ValidationError{Issues: yamlIssues(err), msg: ErrInvalidSpacefile.Error(), err: ErrInvalidSpacefile}
+	}
+	s, err := parse(content, root)
+	if err != nil {
+		return err
+	}
+	return checkMicros(s.Micros)
+}
+
+// parse validates the content against the schema and decodes it, issues point to the lines of root if it's not nil
+func parse(content []byte, root *yaml.Node) (*Spacefile, error) {
+	var v any
+	if err := yaml.Unmarshal(content, &v); err != nil {
+		return nil, &ValidationError{Issues: yamlIssues(err), msg: ErrInvalidSpacefile.Error(), err: ErrInvalidSpacefile}
+	}
+	if root != nil && needsFlattening(root) {
+		doc, _ := v.(map[string]any)
+		stripExtensions(doc)
+	}
+
+	// validate against schema
+	if err := spacefileSchema.Validate(v); err != nil {
+		var ve *jsonschema.ValidationError
+		if errors.As(err, &ve) {
+			return nil, &ValidationError{Issues: schemaIssues(ve, root), msg: PrettyValidationErrors(ve, v, "")}
 		}
 	}
 
+	var spacefile Spacefile
+	if err := yaml.Unmarshal(content, &spacefile); err != nil {
+		return nil, &ValidationError{Issues: yamlIssues(err), msg: ErrInvalidSpacefile.Error(), err: ErrInvalidSpacefile}
+	}
 	return &spacefile, nil
 }
 
+// checkMicros checks that the micro names are unique and that there is one primary micro
+func checkMicros(micros []*shared.Micro) error {
+	foundPrimaryMicro := false
+	names := make(map[string]struct{})
+	for _, micro := range micros {
+		if _, ok := names[micro.Name]; ok {
+			return ErrDuplicateMicros
+		}
+		names[micro.Name] = struct{}{}
+
+		if micro.Primary {
+			if foundPrimaryMicro {
+				return ErrMultiplePrimary
+			}
+			foundPrimaryMicro = true
+		}
+	}
+
+	if !foundPrimaryMicro && len(micros) != 1 {
+		return ErrNoPrimaryMicro
+	}
+	return nil
+}
+
 // OpenRaw returns the raw spacefile file content from sourceDir if it exists
 func OpenRaw(sourceDir string) ([]byte, error) {
 	var exists bool
@@ -68,3 +68,73 @@ func TestImplicitPrimary(t *testing.T) {
 		t.Fatalf("expected primary to be true but got false")
 	}
 }
+
+func TestStaticConfig(t *testing.T) {
+	cases := []struct {
+		name  string
+		micro string
+		valid bool
+	}{
+		{
+			name: "valid",
+			micro: `    engine: static
+    serve: dist
+    static:
+      spa: true
+      headers:
+        - source: /assets/*
+          headers:
+            Cache-Control: public, max-age=31536000
+      redirects:
+        - source: /blog/*
+          destination: /posts/*
+          status: 308`,
+			valid: true,
+		},
+		{
+			name: "react",
+			micro: `    engine: react
+    serve: build
+    static:
+      spa: true`,
+			valid: true,
+		},
+		{
+			name: "not static",
+			micro: `    engine: python3.9
+    static:
+      spa: true`,
+		},
+		{
+			name: "relative source",
+			micro: `    engine: static
+    serve: dist
+    static:
+      redirects:
+        - source: old
+          destination: /new`,
+		},
+		{
+			name: "invalid status",
+			micro: `    engine: static
+    serve: dist
+    static:
+      redirects:
+        - source: /old
+          destination: /new
+          status: 200`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Validate([]byte("v: 0\nmicros:\n  - name: frontend\n    src: .\n" + c.micro + "\n"))
+			if c.valid && err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if !c.valid && err == nil {
+				t.Fatalf("expected error but got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package spacefile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/internal/fuzzy"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// extensionPrefix marks top level fields which are ignored, e.g. to define yaml anchors that are used by the micros
+const extensionPrefix = "x-"
+
+// ParseOptions changes how strict ParseSpacefileWithOptions is
+type ParseOptions struct {
+	// Strict rejects extension fields which don't define an anchor that is used in the Spacefile
+	Strict bool
+}
+
+// isExtension reports if a top level field is an extension field
+func isExtension(key string) bool {
+	return strings.HasPrefix(key, extensionPrefix)
+}
+
+// needsFlattening reports if the document uses yaml features the Space builder doesn't know, aliases and extension fields
+func needsFlattening(root *yaml.Node) bool {
+	doc := documentRoot(root)
+	if doc.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			if isExtension(doc.Content[i].Value) {
+				return true
+			}
+		}
+	}
+	return len(aliases(doc)) > 0
+}
+
+// aliases returns the names of the anchors referenced in the node
+func aliases(node *yaml.Node) map[string]bool {
+	names := make(map[string]bool)
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.AliasNode {
+			names[n.Value] = true
+			return
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(node)
+	return names
+}
+
+// stripExtensions removes the extension fields of a decoded Spacefile
+func stripExtensions(doc map[string]any) {
+	for key := range doc {
+		if isExtension(key) {
+			delete(doc, key)
+		}
+	}
+}
+
+// strictIssues returns an issue for every extension field which doesn't define a used anchor
+func strictIssues(root *yaml.Node) []Issue {
+	doc := documentRoot(root)
+	if doc.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	used := aliases(doc)
+	var issues []Issue
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		if !isExtension(key.Value) {
+			continue
+		}
+		switch {
+		case value.Anchor == "":
+			issues = append(issues, Issue{Line: key.Line, Message: fmt.Sprintf("extension field '%s' doesn't define an anchor", key.Value)})
+		case !used[value.Anchor]:
+			issues = append(issues, Issue{Line: key.Line, Message: fmt.Sprintf("anchor '%s' of extension field '%s' is never used", value.Anchor, key.Value)})
+		}
+	}
+	return issues
+}
+
+// unknownFieldMessage rewrites an additionalProperties error and suggests the closest known field for every unknown one
+func unknownFieldMessage(ve *jsonschema.ValidationError) string {
+	message := strings.Replace(ve.Message, "additionalProperties", "unknown field", 1)
+	if !strings.HasSuffix(ve.KeywordLocation, "/additionalProperties") {
+		return message
+	}
+
+	known := schemaFields(ve.InstanceLocation)
+	var suggestions []string
+	for _, matches := range unknownFieldReg.FindAllStringSubmatch(ve.Message, -1) {
+		if s := suggest(matches[1], known); s != "" {
+			suggestions = append(suggestions, fmt.Sprintf("'%s'", s))
+		}
+	}
+	if len(suggestions) == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s, did you mean %s?", message, strings.Join(suggestions, " or "))
+}
+
+// schemaFields returns the fields allowed at the json pointer of a Spacefile
+func schemaFields(pointer string) []string {
+	s := resolveRef(spacefileSchema)
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		if numberReg.MatchString(part) {
+			items, ok := s.Items.(*jsonschema.Schema)
+			if !ok {
+				return nil
+			}
+			s = resolveRef(items)
+			continue
+		}
+		next, ok := s.Properties[part]
+		if !ok {
+			return nil
+		}
+		s = resolveRef(next)
+	}
+
+	fields := make([]string, 0, len(s.Properties)+1)
+	for name := range s.Properties {
+		fields = append(fields, name)
+	}
+	if pointer == "" {
+		fields = append(fields, includeKey)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func resolveRef(s *jsonschema.Schema) *jsonschema.Schema {
+	for s.Ref != nil {
+		s = s.Ref
+	}
+	return s
+}
+
+// suggest returns the candidate closest to the unknown field, or an empty string if none is close enough to be a typo
+func suggest(field string, candidates []string) string {
+	return fuzzy.Closest(field, candidates)
+}
+
+func documentRoot(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		return n.Content[0]
+	}
+	return n
+}
+
+// mappingValue returns the value of key in a mapping node or nil
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
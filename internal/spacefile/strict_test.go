@@ -0,0 +1,89 @@
+package spacefile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseAnchors(t *testing.T) {
+	s, err := ParseSpacefile("testdata/spacefile/anchors.yaml")
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(s.Micros), 2)
+	for _, micro := range s.Micros {
+		assert.Equal(t, micro.Engine, "python3.9")
+		assert.Equal(t, len(micro.Presets.Env), 1)
+	}
+
+	content, err := Compose("testdata/spacefile/anchors.yaml")
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(content), extensionPrefix))
+}
+
+func TestParseStrict(t *testing.T) {
+	cases := []struct {
+		spacefile string
+		strict    bool
+		line      int
+	}{
+		{
+			spacefile: "testdata/spacefile/unused_anchor.yaml",
+			strict:    false,
+		},
+		{
+			spacefile: "testdata/spacefile/unused_anchor.yaml",
+			strict:    true,
+			line:      2,
+		},
+		{
+			spacefile: "testdata/spacefile/anchors.yaml",
+			strict:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.spacefile, func(t *testing.T) {
+			_, err := ParseSpacefileWithOptions(c.spacefile, &ParseOptions{Strict: c.strict})
+			if c.line == 0 {
+				assert.NilError(t, err)
+				return
+			}
+
+			var ve *ValidationError
+			assert.Assert(t, errors.As(err, &ve))
+			assert.Equal(t, ve.Issues[0].Line, c.line)
+		})
+	}
+}
+
+func TestUnknownFieldSuggestion(t *testing.T) {
+	_, err := ParseSpacefile("testdata/spacefile/typo.yaml")
+
+	var ve *ValidationError
+	assert.Assert(t, errors.As(err, &ve))
+	assert.Equal(t, ve.Issues[0].Line, 11)
+	assert.Assert(t, strings.Contains(ve.Issues[0].Message, "did you mean 'description'?"), ve.Issues[0].Message)
+	assert.Assert(t, strings.Contains(err.Error(), "did you mean 'description'?"), err.Error())
+}
+
+func TestSuggest(t *testing.T) {
+	cases := []struct {
+		field    string
+		expected string
+	}{
+		{field: "inclde", expected: "include"},
+		{field: "shedule", expected: "schedule"},
+		{field: "primay", expected: "primary"},
+		{field: "unknown", expected: ""},
+	}
+
+	candidates := []string{"include", "schedule", "primary", "run", "name"}
+	for _, c := range cases {
+		t.Run(c.field, func(t *testing.T) {
+			assert.Equal(t, suggest(c.field, candidates), c.expected)
+		})
+	}
+}
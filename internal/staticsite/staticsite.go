@@ -0,0 +1,122 @@
+// Package staticsite applies the static config of the Spacefile, its redirects, headers and SPA fallback, to the
+// requests the proxy of space dev passes to a static micro
+package staticsite
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/deta/space/shared"
+)
+
+// Match reports whether the path matches the pattern of a source. A * matches a part of a segment and a trailing /*
+// the rest of the path, which is returned.
+func Match(pattern string, p string) (string, bool) {
+	if strings.HasSuffix(pattern, "/*") {
+		prefix := strings.TrimSuffix(pattern, "/*")
+		segments := strings.Count(prefix, "/")
+		parts := strings.SplitN(p, "/", segments+2)
+		if len(parts) < segments+1 {
+			return "", false
+		}
+		head := strings.Join(parts[:segments+1], "/")
+		if matched, _ := path.Match(prefix, head); !matched {
+			return "", false
+		}
+		if len(parts) == segments+2 {
+			return parts[segments+1], true
+		}
+		return "", true
+	}
+	matched, _ := path.Match(pattern, p)
+	return "", matched
+}
+
+// Handler applies the config of a static micro served on route from root to the requests passed to next, which serves
+// the files. The paths of the requests are relative to the route, as the proxy strips it.
+func Handler(next http.Handler, root string, route string, config *shared.StaticConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, redirect := range config.Redirects {
+			rest, ok := Match(redirect.Source, r.URL.Path)
+			if !ok {
+				continue
+			}
+			status := redirect.Status
+			if status == 0 {
+				status = http.StatusMovedPermanently
+			}
+			location := Location(redirect.Destination, rest, route)
+			if r.URL.RawQuery != "" && !strings.Contains(location, "?") {
+				location += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, location, status)
+			return
+		}
+
+		headers := make(map[string]string)
+		for _, rule := range config.Headers {
+			if _, ok := Match(rule.Source, r.URL.Path); ok {
+				for name, value := range rule.Headers {
+					headers[name] = value
+				}
+			}
+		}
+		if len(headers) > 0 {
+			w = &headerWriter{ResponseWriter: w, headers: headers}
+		}
+
+		if config.SPA && (r.Method == http.MethodGet || r.Method == http.MethodHead) && path.Ext(r.URL.Path) == "" {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(path.Clean("/"+r.URL.Path)))); err != nil {
+				r.URL.Path = "/"
+				r.URL.RawPath = ""
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Location is the location of a redirect, a trailing /* is replaced with the rest matched by the source and paths
+// are prefixed with the route of the micro
+func Location(dest string, rest string, route string) string {
+	if strings.HasSuffix(dest, "/*") {
+		dest = strings.TrimSuffix(dest, "/*") + "/" + rest
+	}
+	if !strings.HasPrefix(dest, "/") || strings.HasPrefix(dest, "//") {
+		return dest
+	}
+	return strings.TrimSuffix(route, "/") + dest
+}
+
+// headerWriter sets the headers of the matching rules on the response, over the ones of the micro
+type headerWriter struct {
+	http.ResponseWriter
+	headers     map[string]string
+	wroteHeader bool
+}
+
+func (w *headerWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		for name, value := range w.headers {
+			w.ResponseWriter.Header().Set(name, value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *headerWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush lets the proxy stream responses through the writer
+func (w *headerWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,91 @@
+package staticsite
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deta/space/shared"
+	"gotest.tools/v3/assert"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		rest    string
+		matched bool
+	}{
+		{pattern: "/old", path: "/old", matched: true},
+		{pattern: "/old", path: "/old/page", matched: false},
+		{pattern: "/*.html", path: "/about.html", matched: true},
+		{pattern: "/*.html", path: "/docs/about.html", matched: false},
+		{pattern: "/blog/*", path: "/blog/2023/post", rest: "2023/post", matched: true},
+		{pattern: "/blog/*", path: "/blog", matched: true},
+		{pattern: "/blog/*", path: "/blogs/post", matched: false},
+		{pattern: "/*", path: "/anything/at/all", rest: "anything/at/all", matched: true},
+	}
+
+	for _, c := range cases {
+		rest, matched := Match(c.pattern, c.path)
+		assert.Equal(t, matched, c.matched, "%s %s", c.pattern, c.path)
+		assert.Equal(t, rest, c.rest, "%s %s", c.pattern, c.path)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	root := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(root, "assets"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "index.html"), []byte("index"), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "assets", "app.js"), []byte("app"), 0644))
+
+	config := &shared.StaticConfig{
+		SPA: true,
+		Headers: []shared.HeaderRule{
+			{Source: "/assets/*", Headers: map[string]string{"Cache-Control": "immutable"}},
+		},
+		Redirects: []shared.Redirect{
+			{Source: "/blog/*", Destination: "/posts/*"},
+			{Source: "/docs", Destination: "https://docs.example.com", Status: http.StatusFound},
+		},
+	}
+	next := http.FileServer(http.Dir(root))
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	cases := []struct {
+		route    string
+		path     string
+		status   int
+		body     string
+		location string
+		header   string
+	}{
+		{route: "/", path: "/settings/profile", status: 200, body: "index"},
+		{route: "/", path: "/assets/app.js", status: 200, body: "app", header: "immutable"},
+		{route: "/", path: "/assets/missing.js", status: 404, header: "immutable"},
+		{route: "/", path: "/blog/hello?ref=feed", status: 301, location: "/posts/hello?ref=feed"},
+		{route: "/app", path: "/blog/hello", status: 301, location: "/app/posts/hello"},
+		{route: "/", path: "/docs", status: 302, location: "https://docs.example.com"},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(Handler(next, root, c.route, config))
+		res, err := client.Get(server.URL + c.path)
+		assert.NilError(t, err, c.path)
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		server.Close()
+
+		assert.Equal(t, res.StatusCode, c.status, c.path)
+		if c.body != "" {
+			assert.Equal(t, string(body), c.body, c.path)
+		}
+		assert.Equal(t, res.Header.Get("Location"), c.location, c.path)
+		assert.Equal(t, res.Header.Get("Cache-Control"), c.header, c.path)
+	}
+}
@@ -0,0 +1,71 @@
+// Package support builds support bundles, archives of sanitized diagnostics which users attach to issues
+package support
+
+import (
+	"archive/zip"
+	"io"
+	"strings"
+	"time"
+)
+
+// File of a bundle
+type File struct {
+	Name    string
+	Content string
+	// Redactions is the number of secrets replaced in the content
+	Redactions int
+}
+
+// Bundle collects the files of a support bundle, their secrets are redacted when they're added
+type Bundle struct {
+	Files   []*File
+	homeDir string
+}
+
+// NewBundle returns an empty bundle, paths in homeDir are shortened to ~ in all files
+func NewBundle(homeDir string) *Bundle {
+	return &Bundle{homeDir: homeDir}
+}
+
+// Add adds a file with the redacted content
+func (b *Bundle) Add(name string, content string) *File {
+	redacted, count := Redact(content, b.homeDir)
+	f := &File{Name: name, Content: redacted, Redactions: count}
+	b.Files = append(b.Files, f)
+	return f
+}
+
+// Remove removes a file, e.g. after the user excluded it during the review
+func (b *Bundle) Remove(name string) {
+	for i, f := range b.Files {
+		if f.Name == name {
+			b.Files = append(b.Files[:i], b.Files[i+1:]...)
+			return
+		}
+	}
+}
+
+// Key returns the prompt key of the review of the file, e.g. support.spacefile for Spacefile
+func (f *File) Key() string {
+	name := strings.ToLower(strings.TrimPrefix(f.Name, "."))
+	if i := strings.LastIndex(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return "support." + strings.NewReplacer("-", "_", " ", "_").Replace(name)
+}
+
+// Write writes the bundle as a zip archive
+func (b *Bundle) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	for _, f := range b.Files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
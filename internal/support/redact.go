@@ -0,0 +1,47 @@
+package support
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secrets in the files of a bundle
+const Redacted = "[REDACTED]"
+
+var (
+	// secretAssignment matches values of keys which look like secrets in yaml, json, env files and urls, e.g.
+	// api_key: abc, "password": "abc", TOKEN=abc or ?token=abc
+	secretAssignment = regexp.MustCompile(`(?i)((?:"|\b)[\w.-]*(?:token|secret|passw(?:or)?d|api[_-]?key|project[_-]?key|credential|authorization)[\w.-]*"?\s*[:=]\s*"?)((?:bearer\s+)?[^\s"',&]+)`)
+	// detaKey matches the format of the project keys and access tokens of Deta, e.g. a0abcdef_1234...
+	detaKey = regexp.MustCompile(`\b[a-zA-Z0-9]{8,12}_[a-zA-Z0-9]{32}\b`)
+	// bearer matches authorization headers
+	bearer = regexp.MustCompile(`(?i)(bearer\s+)[\w.~+/-]+=*`)
+)
+
+// Redact replaces the secrets of content and the home directory of the user, it returns the redacted content and the
+// number of replaced secrets
+func Redact(content string, homeDir string) (string, int) {
+	count := 0
+	content = secretAssignment.ReplaceAllStringFunc(content, func(match string) string {
+		m := secretAssignment.FindStringSubmatch(match)
+		if m[2] == Redacted {
+			return match
+		}
+		count++
+		return m[1] + Redacted
+	})
+	for _, re := range []*regexp.Regexp{detaKey, bearer} {
+		content = re.ReplaceAllStringFunc(content, func(match string) string {
+			count++
+			if m := re.FindStringSubmatch(match); len(m) > 1 {
+				return m[1] + Redacted
+			}
+			return Redacted
+		})
+	}
+	// the home directory contains the name of the user
+	if homeDir != "" && homeDir != "/" {
+		content = strings.ReplaceAll(content, homeDir, "~")
+	}
+	return content, count
+}
@@ -0,0 +1,89 @@
+package support
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/deta/space/internal/home"
+)
+
+const (
+	requestsFile = "failed_requests.log"
+	// maxRequests is the number of failed requests which are kept
+	maxRequests = 20
+)
+
+// mu serializes the writes of parallel requests
+var mu sync.Mutex
+
+// Request is a failed request to Deta Space, the request id lets the support find it in the logs
+type Request struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// LogRequest adds a failed request to the log in the directory of the global state, only the latest requests are kept
+func LogRequest(r *Request) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	requests, err := RecentRequests()
+	if err != nil {
+		return err
+	}
+	requests = append(requests, r)
+	if len(requests) > maxRequests {
+		requests = requests[len(requests)-maxRequests:]
+	}
+
+	var b bytes.Buffer
+	for _, r := range requests {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	path, err := home.PrepareWrite(requestsFile)
+	if err != nil {
+		return err
+	}
+	return home.WrapWriteError(os.WriteFile(path, b.Bytes(), 0660))
+}
+
+// RecentRequests returns the latest failed requests, the oldest first
+func RecentRequests() ([]*Request, error) {
+	path, err := home.Path(requestsFile)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var requests []*Request
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Request
+		// a broken line doesn't lose the other requests
+		if err := json.Unmarshal(scanner.Bytes(), &r); err == nil {
+			requests = append(requests, &r)
+		}
+	}
+	return requests, scanner.Err()
+}
@@ -0,0 +1,73 @@
+package support
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/deta/space/internal/home"
+	"gotest.tools/v3/assert"
+)
+
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected string
+		count    int
+	}{
+		{content: "api_key: abc123", expected: "api_key: [REDACTED]", count: 1},
+		{content: `{"access_token": "abc123", "name": "app"}`, expected: `{"access_token": "[REDACTED]", "name": "app"}`, count: 1},
+		{content: "DB_PASSWORD=hunter2", expected: "DB_PASSWORD=[REDACTED]", count: 1},
+		{content: "GET /v1/items?token=abc&limit=1", expected: "GET /v1/items?token=[REDACTED]&limit=1", count: 1},
+		{content: "key a0abcdef_" + "0123456789abcdef0123456789abcdef", expected: "key [REDACTED]", count: 1},
+		{content: "Authorization: Bearer eyJhbGciOi.abc", expected: "Authorization: [REDACTED]", count: 1},
+		{content: "src: /home/jane/app", expected: "src: ~/app", count: 0},
+		{content: "engine: python3.9", expected: "engine: python3.9", count: 0},
+	}
+
+	for _, c := range cases {
+		redacted, count := Redact(c.content, "/home/jane")
+		assert.Equal(t, redacted, c.expected, c.content)
+		assert.Equal(t, count, c.count, c.content)
+	}
+}
+
+func TestBundle(t *testing.T) {
+	b := NewBundle("")
+	b.Add("versions.txt", "space v1.0.0")
+	spacefile := b.Add("Spacefile", "presets:\n  env:\n    - name: SECRET\n      default: secret: abc\n")
+	b.Add("failed-requests.log", "")
+	b.Remove("failed-requests.log")
+	assert.Equal(t, spacefile.Key(), "support.spacefile")
+	assert.Equal(t, (&File{Name: ".spaceconfig"}).Key(), "support.spaceconfig")
+	assert.Equal(t, (&File{Name: "failed-requests.log"}).Key(), "support.failed_requests")
+
+	var buf bytes.Buffer
+	assert.NilError(t, b.Write(&buf))
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NilError(t, err)
+	assert.Equal(t, len(r.File), 2)
+
+	f, err := r.File[1].Open()
+	assert.NilError(t, err)
+	content, err := io.ReadAll(f)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), spacefile.Content)
+}
+
+func TestLogRequest(t *testing.T) {
+	t.Setenv(home.HomeEnv, t.TempDir())
+
+	for i := 0; i < maxRequests+5; i++ {
+		assert.NilError(t, LogRequest(&Request{Time: time.Unix(int64(i), 0).UTC(), Method: "GET", Path: "/v0/apps", Status: 500, RequestID: fmt.Sprint(i)}))
+	}
+
+	requests, err := RecentRequests()
+	assert.NilError(t, err)
+	assert.Equal(t, len(requests), maxRequests)
+	assert.Equal(t, requests[0].RequestID, "5")
+	assert.Equal(t, requests[maxRequests-1].RequestID, fmt.Sprint(maxRequests+4))
+}
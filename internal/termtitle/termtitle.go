@@ -0,0 +1,34 @@
+// Package termtitle shows the progress of an operation in the title of the terminal, which is also the title of the
+// tab in iTerm and of the pane in tmux, and rings the bell of the terminal
+package termtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bell rings the bell of the terminal
+const Bell = "\a"
+
+// Format returns the title of a phase of an operation, e.g. space: uploading 43%, a negative percent is left out
+func Format(phase string, percent int) string {
+	if percent < 0 {
+		return "space: " + phase
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	return fmt.Sprintf("space: %s %d%%", phase, percent)
+}
+
+// Sequence returns the escape sequence which sets the title, an empty title resets it to the default of the terminal
+func Sequence(title string) string {
+	title = strings.Map(func(r rune) rune {
+		// control characters would end the sequence early
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, title)
+	return "\x1b]2;" + title + "\a"
+}
@@ -0,0 +1,29 @@
+package termtitle
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		phase    string
+		percent  int
+		expected string
+	}{
+		{phase: "building", percent: -1, expected: "space: building"},
+		{phase: "uploading", percent: 43, expected: "space: uploading 43%"},
+		{phase: "uploading", percent: 120, expected: "space: uploading 100%"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, Format(c.phase, c.percent), c.expected)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	assert.Equal(t, Sequence("space: building"), "\x1b]2;space: building\a")
+	assert.Equal(t, Sequence("bad\x07\x1b]title"), "\x1b]2;bad]title\a")
+	assert.Equal(t, Sequence(""), "\x1b]2;\a")
+}
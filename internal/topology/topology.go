@@ -0,0 +1,215 @@
+// Package topology describes how the micros of an app are reached and what they use, from the Spacefile and the
+// dependencies of the project config, as a mermaid or graphviz chart for space graph
+package topology
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/shared"
+)
+
+// Kind of a node of the graph
+type Kind string
+
+const (
+	// KindApp is the entry of the app, users which are signed in to Space
+	KindApp Kind = "app"
+	// KindPublic is the entry of the public routes, reachable without signing in
+	KindPublic   Kind = "public"
+	KindMicro    Kind = "micro"
+	KindSchedule Kind = "schedule"
+	KindBase     Kind = "base"
+	KindDrive    Kind = "drive"
+	KindExternal Kind = "external"
+)
+
+// Kinds in the order of the legend
+var Kinds = []Kind{KindApp, KindPublic, KindMicro, KindSchedule, KindBase, KindDrive, KindExternal}
+
+// Node of the graph
+type Node struct {
+	ID     string `json:"id"`
+	Kind   Kind   `json:"kind"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Edge of the graph
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label,omitempty"`
+}
+
+// Graph is the topology of an app
+type Graph struct {
+	Title string  `json:"title"`
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+var idReg = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// nodeID is a valid id in mermaid and graphviz, e.g. base_users
+func nodeID(kind Kind, name string) string {
+	return string(kind) + "_" + idReg.ReplaceAllString(name, "_")
+}
+
+// Build returns the graph of the micros and their dependencies keyed by the name of the micro. Dependencies of micros
+// which aren't in the Spacefile are an error, so that the graph doesn't silently drop them.
+func Build(title string, micros []*shared.Micro, dependencies map[string][]*spaceconfig.Dependency) (*Graph, error) {
+	names := make(map[string]bool)
+	for _, micro := range micros {
+		names[micro.Name] = true
+	}
+	for micro := range dependencies {
+		if !names[micro] {
+			return nil, fmt.Errorf("dependencies of micro %s which is not in the Spacefile", micro)
+		}
+	}
+
+	g := &Graph{Title: title}
+	seen := make(map[string]bool)
+	addNode := func(n *Node) {
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+
+	addNode(&Node{ID: string(KindApp), Kind: KindApp, Name: title})
+	for _, micro := range micros {
+		id := nodeID(KindMicro, micro.Name)
+		detail := micro.Engine
+		if micro.Primary {
+			detail += ", primary"
+		}
+		addNode(&Node{ID: id, Kind: KindMicro, Name: micro.Name, Detail: detail})
+		g.Edges = append(g.Edges, &Edge{From: string(KindApp), To: id, Label: micro.Route()})
+
+		if micro.Public || len(micro.PublicRoutes) > 0 {
+			addNode(&Node{ID: string(KindPublic), Kind: KindPublic, Name: "Public"})
+			label := "all routes"
+			if !micro.Public {
+				label = strings.Join(micro.PublicRoutes, ", ")
+			}
+			g.Edges = append(g.Edges, &Edge{From: string(KindPublic), To: id, Label: label})
+		}
+
+		for _, action := range micro.Actions {
+			if action.Trigger != "" && action.Trigger != "schedule" {
+				continue
+			}
+			name := action.Name
+			if name == "" {
+				name = action.ID
+			}
+			scheduleID := nodeID(KindSchedule, micro.Name+"_"+action.ID)
+			addNode(&Node{ID: scheduleID, Kind: KindSchedule, Name: name, Detail: action.Interval})
+			g.Edges = append(g.Edges, &Edge{From: scheduleID, To: id, Label: action.Path})
+		}
+
+		for _, d := range dependencies[micro.Name] {
+			kind := Kind(d.Kind)
+			depID := nodeID(kind, d.Name)
+			addNode(&Node{ID: depID, Kind: kind, Name: d.Name, Detail: d.URL})
+			g.Edges = append(g.Edges, &Edge{From: id, To: depID})
+		}
+	}
+	return g, nil
+}
+
+// mermaidShapes are the brackets of the nodes of a kind
+var mermaidShapes = map[Kind][2]string{
+	KindApp:      {"([", "])"},
+	KindPublic:   {"([", "])"},
+	KindMicro:    {"[", "]"},
+	KindSchedule: {">", "]"},
+	KindBase:     {"[(", ")]"},
+	KindDrive:    {"[(", ")]"},
+	KindExternal: {"{{", "}}"},
+}
+
+// mermaidStyles color the nodes of the mermaid chart by their kind
+var mermaidStyles = map[Kind]string{
+	KindApp:      "fill:#e7f5ff,stroke:#1971c2",
+	KindPublic:   "fill:#fff4e6,stroke:#e8590c",
+	KindMicro:    "fill:#f3f0ff,stroke:#6741d9",
+	KindSchedule: "fill:#fff9db,stroke:#f08c00",
+	KindBase:     "fill:#ebfbee,stroke:#2b8a3e",
+	KindDrive:    "fill:#e6fcf5,stroke:#0c8599",
+	KindExternal: "fill:#f8f9fa,stroke:#868e96,stroke-dasharray:4",
+}
+
+func mermaidText(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+// Mermaid returns the graph as a mermaid flowchart
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", g.Title)
+	b.WriteString("---\nflowchart LR\n")
+	for _, n := range g.Nodes {
+		label := mermaidText(n.Name)
+		if n.Detail != "" {
+			label += "<br/><i>" + mermaidText(n.Detail) + "</i>"
+		}
+		shape := mermaidShapes[n.Kind]
+		fmt.Fprintf(&b, "    %s%s\"%s\"%s:::%s\n", n.ID, shape[0], label, shape[1], n.Kind)
+	}
+	for _, e := range g.Edges {
+		if e.Label == "" {
+			fmt.Fprintf(&b, "    %s --> %s\n", e.From, e.To)
+			continue
+		}
+		fmt.Fprintf(&b, "    %s -->|\"%s\"| %s\n", e.From, mermaidText(e.Label), e.To)
+	}
+	for _, kind := range Kinds {
+		fmt.Fprintf(&b, "    classDef %s %s\n", kind, mermaidStyles[kind])
+	}
+	return b.String()
+}
+
+// dotShapes are the graphviz shapes of the nodes of a kind
+var dotShapes = map[Kind]string{
+	KindApp:      "oval",
+	KindPublic:   "oval",
+	KindMicro:    "box",
+	KindSchedule: "cds",
+	KindBase:     "cylinder",
+	KindDrive:    "folder",
+	KindExternal: "hexagon",
+}
+
+func dotText(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// DOT returns the graph in the dot language of graphviz
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotText(g.Title))
+	b.WriteString("    rankdir=LR;\n")
+	fmt.Fprintf(&b, "    label=%s;\n", dotText(g.Title))
+	for _, n := range g.Nodes {
+		label := n.Name
+		if n.Detail != "" {
+			label += "\n" + n.Detail
+		}
+		fmt.Fprintf(&b, "    %s [label=%s, shape=%s];\n", n.ID, dotText(label), dotShapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		if e.Label == "" {
+			fmt.Fprintf(&b, "    %s -> %s;\n", e.From, e.To)
+			continue
+		}
+		fmt.Fprintf(&b, "    %s -> %s [label=%s];\n", e.From, e.To, dotText(e.Label))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deta/space/internal/spaceconfig"
+	"github.com/deta/space/shared"
+	"gotest.tools/v3/assert"
+)
+
+var testMicros = []*shared.Micro{
+	{Name: "frontend", Engine: "svelte", Primary: true},
+	{
+		Name: "api", Engine: "python3.9", Path: "backend", PublicRoutes: []string{"/webhooks/*"},
+		Actions: []shared.Action{{ID: "cleanup", Name: "Clean up", Trigger: "schedule", Interval: "0 * * * *", Path: "/cleanup"}},
+	},
+}
+
+var testDependencies = map[string][]*spaceconfig.Dependency{
+	"api": {
+		{Name: "users", Kind: "base"},
+		{Name: "Stripe", Kind: "external", URL: "https://api.stripe.com"},
+	},
+	"frontend": {{Name: "users", Kind: "base"}},
+}
+
+func TestBuild(t *testing.T) {
+	g, err := Build("shop", testMicros, testDependencies)
+	assert.NilError(t, err)
+
+	var nodes []string
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	// the base used by both micros is one node
+	assert.DeepEqual(t, nodes, []string{"app", "micro_frontend", "base_users", "micro_api", "public", "schedule_api_cleanup", "external_Stripe"})
+
+	var edges []string
+	for _, e := range g.Edges {
+		edges = append(edges, e.From+" "+e.To+" "+e.Label)
+	}
+	assert.DeepEqual(t, edges, []string{
+		"app micro_frontend /",
+		"micro_frontend base_users ",
+		"app micro_api /backend",
+		"public micro_api /webhooks/*",
+		"schedule_api_cleanup micro_api /cleanup",
+		"micro_api base_users ",
+		"micro_api external_Stripe ",
+	})
+
+	_, err = Build("shop", testMicros, map[string][]*spaceconfig.Dependency{"worker": {{Name: "jobs", Kind: "base"}}})
+	assert.ErrorContains(t, err, "micro worker")
+}
+
+func TestMermaid(t *testing.T) {
+	g, err := Build("shop", testMicros, testDependencies)
+	assert.NilError(t, err)
+
+	chart := g.Mermaid()
+	for _, line := range []string{
+		"flowchart LR",
+		`    micro_api["api<br/><i>python3.9</i>"]:::micro`,
+		`    base_users[("users")]:::base`,
+		`    app -->|"/backend"| micro_api`,
+		"    micro_api --> base_users",
+		"    classDef schedule fill:#fff9db,stroke:#f08c00",
+	} {
+		assert.Assert(t, strings.Contains(chart, line+"\n"), "missing %q in\n%s", line, chart)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	g := &Graph{
+		Title: `my "app"`,
+		Nodes: []*Node{{ID: "app", Kind: KindApp, Name: `my "app"`}, {ID: "micro_api", Kind: KindMicro, Name: "api", Detail: "python3.9"}},
+		Edges: []*Edge{{From: "app", To: "micro_api", Label: "/api"}},
+	}
+
+	assert.Equal(t, g.DOT(), `digraph "my \"app\"" {
+    rankdir=LR;
+    label="my \"app\"";
+    app [label="my \"app\"", shape=oval];
+    micro_api [label="api\npython3.9", shape=box];
+    app -> micro_api [label="/api"];
+}
+`)
+}
@@ -0,0 +1,128 @@
+// Package vendoring copies shared packages of a project into its micros, so that every micro is pushed with its own
+// copy of the code it shares with other micros
+package vendoring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// skipped directories are neither copied nor compared
+var skipped = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// Copy replaces dst with a copy of the files in src and returns the number of copied files
+func Copy(src string, dst string) (int, error) {
+	files, err := list(src)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return 0, fmt.Errorf("failed to remove %s: %w", dst, err)
+	}
+	for _, name := range files {
+		if err := copyFile(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+			return 0, err
+		}
+	}
+	return len(files), nil
+}
+
+// Stale reports if dst is missing or its files differ from the files in src
+func Stale(src string, dst string) (bool, error) {
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		return true, nil
+	}
+	srcDigest, err := digest(src)
+	if err != nil {
+		return false, err
+	}
+	dstDigest, err := digest(dst)
+	if err != nil {
+		return false, err
+	}
+	return srcDigest != dstDigest, nil
+}
+
+// list returns the relative paths of the regular files in dir in lexical order
+func list(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipped[d.Name()] && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// digest is the sha256 checksum of the paths and contents of the files in dir
+func digest(dir string) (string, error) {
+	files, err := list(dir)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, name := range files {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(name))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,40 @@
+package vendoring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCopyAndStale(t *testing.T) {
+	src := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(src, "utils", ".git"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(src, "utils", "__init__.py"), []byte("def slug(s): ..."), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(src, "utils", ".git", "HEAD"), []byte("ref: main"), 0644))
+	dst := filepath.Join(t.TempDir(), "vendor", "shared")
+
+	stale, err := Stale(src, dst)
+	assert.NilError(t, err)
+	assert.Assert(t, stale, "missing copy")
+
+	// files of a previous copy are removed
+	assert.NilError(t, os.MkdirAll(dst, 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dst, "old.py"), []byte(""), 0644))
+
+	n, err := Copy(src, dst)
+	assert.NilError(t, err)
+	assert.Equal(t, n, 1)
+	_, err = os.Stat(filepath.Join(dst, "old.py"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	stale, err = Stale(src, dst)
+	assert.NilError(t, err)
+	assert.Assert(t, !stale, "fresh copy")
+
+	assert.NilError(t, os.WriteFile(filepath.Join(src, "utils", "__init__.py"), []byte("def slug(s): return s"), 0644))
+	stale, err = Stale(src, dst)
+	assert.NilError(t, err)
+	assert.Assert(t, stale, "changed source")
+}
@@ -0,0 +1,132 @@
+// Package workspace finds the projects of a monorepo and which of them changed
+package workspace
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/deta/space/internal/spacefile"
+)
+
+// skippedDirs are never searched for projects
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"__pycache__":  true,
+	"vendor":       true,
+}
+
+// Project is a directory with a Spacefile
+type Project struct {
+	// Dir is slash separated and relative to the workspace root, "." for the root itself
+	Dir string
+	// Micros maps the names of the micros to their slash separated src relative to Dir
+	Micros map[string]string
+}
+
+// Change lists the changed files of a project
+type Change struct {
+	Project *Project
+	// Files are relative to the workspace root
+	Files []string
+	// Micros are the names of the micros with changed files in their src
+	Micros []string
+}
+
+// Discover finds all projects in root, projects nested in other projects are not searched for
+func Discover(root string) ([]*Project, error) {
+	var projects []*Project
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if p != root && (strings.HasPrefix(info.Name(), ".") || skippedDirs[info.Name()]) {
+			return filepath.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(p, spacefile.SpacefileName)); err != nil {
+			return nil
+		}
+		project, err := load(root, p)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, project)
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// load reads the micros of the project in dir
+func load(root string, dir string) (*Project, error) {
+	s, err := spacefile.ParseSpacefile(filepath.Join(dir, spacefile.SpacefileName))
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Project{Dir: filepath.ToSlash(rel), Micros: map[string]string{}}
+	for _, micro := range s.Micros {
+		project.Micros[micro.Name] = path.Clean(filepath.ToSlash(micro.Src))
+	}
+	return project, nil
+}
+
+// Changes returns the projects with changed files, files have to be slash separated and relative to the workspace root
+func Changes(projects []*Project, files []string) []*Change {
+	var changes []*Change
+	for _, project := range projects {
+		change := &Change{Project: project}
+		micros := map[string]bool{}
+
+		for _, file := range files {
+			rel, ok := within(project.Dir, file)
+			if !ok {
+				continue
+			}
+			change.Files = append(change.Files, file)
+			for name, src := range project.Micros {
+				if _, ok := within(src, rel); ok {
+					micros[name] = true
+				}
+			}
+		}
+
+		if len(change.Files) == 0 {
+			continue
+		}
+		for name := range micros {
+			change.Micros = append(change.Micros, name)
+		}
+		sort.Strings(change.Micros)
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// within returns the path of file relative to dir if it is inside of it
+func within(dir string, file string) (string, bool) {
+	file = path.Clean(file)
+	if dir == "." {
+		return file, true
+	}
+	if file == dir {
+		return ".", true
+	}
+	if strings.HasPrefix(file, dir+"/") {
+		return strings.TrimPrefix(file, dir+"/"), true
+	}
+	return "", false
+}
@@ -0,0 +1,58 @@
+package workspace
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestChanges(t *testing.T) {
+	api := &Project{Dir: "services/api", Micros: map[string]string{"backend": "backend", "frontend": "frontend"}}
+	site := &Project{Dir: "site", Micros: map[string]string{"site": "."}}
+	projects := []*Project{api, site}
+
+	cases := []struct {
+		name     string
+		files    []string
+		expected []*Change
+	}{
+		{
+			name:     "nothing changed",
+			files:    []string{"README.md", "services/other/main.go"},
+			expected: nil,
+		},
+		{
+			name:  "micro changed",
+			files: []string{"services/api/backend/main.go", "README.md"},
+			expected: []*Change{
+				{Project: api, Files: []string{"services/api/backend/main.go"}, Micros: []string{"backend"}},
+			},
+		},
+		{
+			name:  "spacefile and other project changed",
+			files: []string{"services/api/Spacefile", "site/index.html"},
+			expected: []*Change{
+				{Project: api, Files: []string{"services/api/Spacefile"}},
+				{Project: site, Files: []string{"site/index.html"}, Micros: []string{"site"}},
+			},
+		},
+		{
+			name:     "prefix of another directory",
+			files:    []string{"sitemap/index.html"},
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.DeepEqual(t, Changes(projects, c.files), c.expected)
+		})
+	}
+}
+
+func TestChangesRoot(t *testing.T) {
+	root := &Project{Dir: ".", Micros: map[string]string{"app": "."}}
+	changes := Changes([]*Project{root}, []string{"main.py"})
+	assert.Equal(t, len(changes), 1)
+	assert.DeepEqual(t, changes[0].Micros, []string{"app"})
+}
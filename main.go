@@ -4,12 +4,15 @@ import (
 	"os"
 
 	"github.com/deta/space/cmd"
+	"github.com/deta/space/cmd/shared"
+	"github.com/deta/space/internal/crash"
 )
 
 func main() {
-	cmd := cmd.NewSpaceCmd()
-	err := cmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	defer crash.Handle(shared.SpaceVersion, shared.Platform)
+
+	if err := cmd.Execute(os.Args[1:]); err != nil {
+		// the commands or cobra reported the error, only the exit code is left to set
+		os.Exit(shared.ExitCode(err))
 	}
 }
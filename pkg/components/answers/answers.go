@@ -0,0 +1,277 @@
+// Package answers answers the prompts of the components from a file or from flags instead of the terminal, so that
+// interactive commands like space new can be scripted. Every prompt has a key, e.g. new.name, and a prompt without an
+// answer fails instead of waiting for input, unless the defaults are assumed like with --yes.
+package answers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/emoji"
+	"github.com/deta/space/pkg/components/styles"
+	"gopkg.in/yaml.v3"
+)
+
+// MissingError lists the keys of the prompts without an answer
+type MissingError struct {
+	Keys []string
+	// Prompt is the question of the prompt if a single prompt failed
+	Prompt string
+}
+
+func (e *MissingError) Error() string {
+	if len(e.Keys) == 1 && e.Prompt != "" {
+		return fmt.Sprintf("missing answer %s for %q", e.Keys[0], e.Prompt)
+	}
+	return fmt.Sprintf("missing answers %s", strings.Join(e.Keys, ", "))
+}
+
+// Answers answers prompts by their key, the questions and answers are written to out as a transcript
+type Answers struct {
+	mu     sync.Mutex
+	values map[string]string
+	out    io.Writer
+	// defaults answers the prompts without an answer like pressing enter in the terminal
+	defaults bool
+}
+
+// New returns empty answers writing the transcript to out
+func New(out io.Writer) *Answers {
+	return &Answers{values: make(map[string]string), out: out}
+}
+
+// Parse reads yaml answers, nested keys are joined with dots so that
+//
+//	new:
+//	  name: my-app
+//
+// answers the prompt new.name
+func (a *Answers) Parse(r io.Reader) error {
+	var values map[string]any
+	if err := yaml.NewDecoder(r).Decode(&values); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse answers: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	flatten("", values, a.values)
+	return nil
+}
+
+func flatten(prefix string, values map[string]any, dst map[string]string) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(key, value, dst)
+		case nil:
+			dst[key] = ""
+		default:
+			dst[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// Set answers the prompt with the key
+func (a *Answers) Set(key string, value string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.values[key] = value
+}
+
+// AssumeDefaults answers the prompts without an answer with their default: a confirmation is accepted, the first
+// choice is chosen and a text is its placeholder. Given answers still take precedence.
+func (a *Answers) AssumeDefaults(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.defaults = enabled
+}
+
+// Missing returns an error listing the keys without an answer, commands check the prompts of a flow up front with it
+// to fail before changing anything. Nothing is missing if the defaults are assumed.
+func (a *Answers) Missing(keys ...string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.defaults {
+		return nil
+	}
+	var missing []string
+	for _, key := range keys {
+		if _, ok := a.values[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &MissingError{Keys: missing}
+}
+
+// lookup returns the answer with the key, ok is false if the default should be used instead
+func (a *Answers) lookup(key string) (value string, ok bool, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if value, ok := a.values[key]; ok {
+		return value, true, nil
+	}
+	if a.defaults {
+		return "", false, nil
+	}
+	return "", false, &MissingError{Keys: []string{key}}
+}
+
+func (a *Answers) missing(key string, prompt string) error {
+	fmt.Fprintln(a.out, styles.Errorf("%s %s", emoji.ErrorExclamation, i18n.T("answers.missing", key, prompt)))
+	return &MissingError{Keys: []string{key}, Prompt: prompt}
+}
+
+func (a *Answers) invalid(key string, err error) error {
+	fmt.Fprintln(a.out, styles.Errorf("%s %s", emoji.ErrorExclamation, i18n.T("answers.invalid", key, err)))
+	return fmt.Errorf("invalid answer %s: %w", key, err)
+}
+
+func (a *Answers) answered(prompt string, answer string) {
+	fmt.Fprintf(a.out, "%s %s %s\n", styles.Question, styles.Bold(prompt), answer)
+}
+
+// Confirm answers a yes or no question, the answer is true, false, yes, no, y or n
+func (a *Answers) Confirm(key string, prompt string) (bool, error) {
+	value, ok, err := a.lookup(key)
+	if err != nil {
+		return false, a.missing(key, prompt)
+	}
+	if !ok {
+		a.answered(prompt, "y")
+		return true, nil
+	}
+
+	var confirmed bool
+	switch strings.ToLower(value) {
+	case "y", "yes":
+		confirmed = true
+	case "n", "no":
+		confirmed = false
+	default:
+		confirmed, err = strconv.ParseBool(value)
+		if err != nil {
+			return false, a.invalid(key, fmt.Errorf("%q is not yes or no", value))
+		}
+	}
+
+	if confirmed {
+		a.answered(prompt, "y")
+	} else {
+		a.answered(prompt, "n")
+	}
+	return confirmed, nil
+}
+
+// Choose answers with one of the choices, the answer is the choice or its number starting at 1
+func (a *Answers) Choose(key string, prompt string, choices ...string) (string, error) {
+	if len(choices) == 0 {
+		return "", errors.New("no choices")
+	}
+	value, ok, err := a.lookup(key)
+	if err != nil {
+		return "", a.missing(key, prompt)
+	}
+	if !ok {
+		a.answered(prompt, choices[0])
+		return choices[0], nil
+	}
+
+	choice, ok := match(value, choices)
+	if !ok {
+		return "", a.invalid(key, fmt.Errorf("%q is not one of %s", value, strings.Join(choices, ", ")))
+	}
+	a.answered(prompt, choice)
+	return choice, nil
+}
+
+func match(value string, choices []string) (string, bool) {
+	for _, choice := range choices {
+		if choice == value {
+			return choice, true
+		}
+	}
+	for _, choice := range choices {
+		if strings.EqualFold(choice, value) {
+			return choice, true
+		}
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= len(choices) {
+		return choices[n-1], true
+	}
+	return "", false
+}
+
+// Text answers with a value, an empty answer is the placeholder. The answer must pass the validator. A text without
+// a placeholder has no default, so it must be answered even if the defaults are assumed.
+func (a *Answers) Text(key string, prompt string, placeholder string, validator func(string) error, password bool) (string, error) {
+	value, ok, err := a.lookup(key)
+	if err != nil || !ok && placeholder == "" {
+		return "", a.missing(key, prompt)
+	}
+	if value == "" {
+		value = placeholder
+	}
+	if validator != nil {
+		if err := validator(value); err != nil {
+			return "", a.invalid(key, err)
+		}
+	}
+
+	if password {
+		a.answered(prompt, strings.Repeat("*", 8))
+	} else {
+		a.answered(prompt, value)
+	}
+	return value, nil
+}
+
+var (
+	stdMu sync.Mutex
+	std   *Answers
+)
+
+// LoadFile adds the answers of a yaml file, - reads them from stdin. Prompts are answered from the loaded answers
+// afterwards instead of the terminal.
+func LoadFile(path string) error {
+	r := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to read answers: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return Std().Parse(r)
+}
+
+// Std returns the answers of the command and enables answering prompts from them
+func Std() *Answers {
+	stdMu.Lock()
+	defer stdMu.Unlock()
+	if std == nil {
+		std = New(os.Stderr)
+	}
+	return std
+}
+
+// Enabled reports if prompts are answered from the answers of the command instead of the terminal
+func Enabled() bool {
+	stdMu.Lock()
+	defer stdMu.Unlock()
+	return std != nil
+}
@@ -0,0 +1,148 @@
+package answers
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	err := a.Parse(strings.NewReader(`
+new:
+  name: my-app
+  setup: false
+release.revision: 2
+login.token:
+`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, a.values, map[string]string{
+		"new.name":         "my-app",
+		"new.setup":        "false",
+		"release.revision": "2",
+		"login.token":      "",
+	})
+}
+
+func TestConfirm(t *testing.T) {
+	cases := []struct {
+		answer   string
+		expected bool
+		err      bool
+	}{
+		{answer: "true", expected: true},
+		{answer: "yes", expected: true},
+		{answer: "N", expected: false},
+		{answer: "0", expected: false},
+		{answer: "maybe", err: true},
+	}
+
+	for _, c := range cases {
+		a := New(&bytes.Buffer{})
+		a.Set("new.setup", c.answer)
+		confirmed, err := a.Confirm("new.setup", "Do you want to setup \"my-app\" with this configuration?")
+		if c.err {
+			assert.Assert(t, err != nil, c.answer)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, confirmed, c.expected, c.answer)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	cases := []struct {
+		answer   string
+		expected string
+		err      bool
+	}{
+		{answer: "lazy-dog", expected: "lazy-dog"},
+		{answer: "Lazy-Dog", expected: "lazy-dog"},
+		{answer: "1", expected: "quick-fox"},
+		{answer: "3", err: true},
+		{answer: "sleepy-cat", err: true},
+	}
+
+	for _, c := range cases {
+		a := New(&bytes.Buffer{})
+		a.Set("release.revision", c.answer)
+		choice, err := a.Choose("release.revision", "Choose a revision:", "quick-fox", "lazy-dog")
+		if c.err {
+			assert.Assert(t, err != nil, c.answer)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, choice, c.expected, c.answer)
+	}
+}
+
+func TestText(t *testing.T) {
+	validator := func(value string) error {
+		if len(value) < 4 {
+			return errors.New("project name must be at least 4 characters long")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	a := New(&out)
+	a.Set("new.name", "")
+	name, err := a.Text("new.name", "What is your project's name?", "module", validator, false)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "module")
+
+	a.Set("new.name", "app")
+	_, err = a.Text("new.name", "What is your project's name?", "module", validator, false)
+	assert.ErrorContains(t, err, "at least 4 characters")
+
+	a.Set("login.token", "secret-token")
+	_, err = a.Text("login.token", "Enter access token", "", nil, true)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(out.String(), "secret-token"))
+}
+
+func TestMissing(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	a.Set("new.name", "my-app")
+
+	_, err := a.Confirm("new.setup", "Do you want to setup \"my-app\" with this configuration?")
+	var missing *MissingError
+	assert.Assert(t, errors.As(err, &missing))
+	assert.DeepEqual(t, missing.Keys, []string{"new.setup"})
+
+	err = a.Missing("release.revision", "new.name", "new.setup")
+	assert.Error(t, err, "missing answers new.setup, release.revision")
+	assert.NilError(t, a.Missing("new.name"))
+}
+
+func TestAssumeDefaults(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	a.AssumeDefaults(true)
+	a.Set("release.latest_revision", "no")
+
+	confirmed, err := a.Confirm("new.setup", "Do you want to setup \"my-app\" with this configuration?")
+	assert.NilError(t, err)
+	assert.Assert(t, confirmed)
+
+	confirmed, err = a.Confirm("release.latest_revision", "Do you want to use the latest revision?")
+	assert.NilError(t, err)
+	assert.Assert(t, !confirmed)
+
+	choice, err := a.Choose("release.revision", "Choose a revision:", "quick-fox", "lazy-dog")
+	assert.NilError(t, err)
+	assert.Equal(t, choice, "quick-fox")
+
+	name, err := a.Text("new.name", "What is your project's name?", "module", nil, false)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "module")
+
+	// a text without a placeholder has no default
+	_, err = a.Text("link.id", "Project ID", "", nil, false)
+	var missing *MissingError
+	assert.Assert(t, errors.As(err, &missing))
+
+	assert.NilError(t, a.Missing("new.name", "new.setup"))
+}
@@ -1,9 +1,13 @@
 package choose
 
 import (
+	"errors"
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/plain"
 	"github.com/deta/space/pkg/components/styles"
 )
 
@@ -100,7 +104,15 @@ func RenderChoice(choice string, chosen bool) string {
 	return fmt.Sprintf("  %s", choice)
 }
 
-func Run(prompt string, choices ...string) (string, error) {
+// Run asks to choose one of the choices, the key names the prompt in the answers
+func Run(key string, prompt string, choices ...string) (string, error) {
+	if answers.Enabled() {
+		return answers.Std().Choose(key, prompt, choices...)
+	}
+	if styles.Accessible() {
+		return plain.Std.Choose(prompt, choices...)
+	}
+
 	program := tea.NewProgram(initialModel(&Input{
 		Prompt:  prompt,
 		Choices: choices,
@@ -117,7 +129,7 @@ func Run(prompt string, choices ...string) (string, error) {
 	}
 
 	if model.Cancelled {
-		return "", fmt.Errorf("cancelled")
+		return "", errors.New(i18n.T("components.cancelled"))
 	}
 
 	return model.Selection(), nil
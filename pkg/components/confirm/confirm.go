@@ -1,9 +1,13 @@
 package confirm
 
 import (
+	"errors"
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/plain"
 	"github.com/deta/space/pkg/components/styles"
 )
 
@@ -66,7 +70,15 @@ func (m Model) View() string {
 	return fmt.Sprintf("%s %s %s\n", styles.Question, styles.Bold(m.Prompt), styles.Subtle(input))
 }
 
-func Run(input string) (bool, error) {
+// Run asks a yes or no question, the key names the prompt in the answers
+func Run(key string, input string) (bool, error) {
+	if answers.Enabled() {
+		return answers.Std().Confirm(key, input)
+	}
+	if styles.Accessible() {
+		return plain.Std.Confirm(input)
+	}
+
 	program := tea.NewProgram(initialModel(input))
 
 	m, err := program.Run()
@@ -80,7 +92,7 @@ func Run(input string) (bool, error) {
 	}
 
 	if model.Cancelled {
-		return false, fmt.Errorf("cancelled")
+		return false, errors.New(i18n.T("components.cancelled"))
 	}
 	return model.Confirm, nil
 }
@@ -10,17 +10,17 @@ var (
 	Gear             = Emoji{Emoji: "⚙️ ", Fallback: ""}
 	PointDown        = Emoji{Emoji: "👇 ", Fallback: ""}
 	Link             = Emoji{Emoji: "🔗 ", Fallback: ""}
-	ErrorExclamation = Emoji{Emoji: "❗", Fallback: styles.ErrorExclamation}
-	ThumbsUp         = Emoji{Emoji: "👍 ", Fallback: styles.CheckMark}
-	Check            = Emoji{Emoji: styles.CheckMark, Fallback: styles.CheckMark}
-	PartyPopper      = Emoji{Emoji: "🎉 ", Fallback: styles.CheckMark}
-	Rocket           = Emoji{Emoji: "🚀 ", Fallback: ""}
+	ErrorExclamation = Emoji{Emoji: "❗", Fallback: styles.ErrorExclamation, Label: "Error:"}
+	ThumbsUp         = Emoji{Emoji: "👍 ", Fallback: styles.CheckMark, Label: "OK:"}
+	Check            = Emoji{Emoji: styles.CheckMark, Fallback: styles.CheckMark, Label: "OK:"}
+	PartyPopper      = Emoji{Emoji: "🎉 ", Fallback: styles.CheckMark, Label: "Success:"}
+	Rocket           = Emoji{Emoji: "🚀 ", Fallback: "", Label: "Success:"}
 	Earth            = Emoji{Emoji: "🌍 ", Fallback: ""}
 	PartyFace        = Emoji{Emoji: "🥳 ", Fallback: ""}
-	X                = Emoji{Emoji: "❌ ", Fallback: styles.X}
+	X                = Emoji{Emoji: "❌ ", Fallback: styles.X, Label: "Failed:"}
 	Waving           = Emoji{Emoji: "👋 ", Fallback: ""}
 	Swirl            = Emoji{Emoji: "🌀 ", Fallback: ""}
-	Sparkles         = Emoji{Emoji: "✨ ", Fallback: styles.CheckMark}
+	Sparkles         = Emoji{Emoji: "✨ ", Fallback: styles.CheckMark, Label: "OK:"}
 	File             = Emoji{Emoji: "📄 ", Fallback: ""}
 	Files            = Emoji{Emoji: "🗂️ ", Fallback: ""}
 	Package          = Emoji{Emoji: "📦 ", Fallback: styles.Boldf("~")}
@@ -32,4 +32,8 @@ var (
 	CrystalBall      = Emoji{Emoji: "🔮 ", Fallback: ""}
 	Label            = Emoji{Emoji: "🏷️ ", Fallback: ""}
 	Key              = Emoji{Emoji: "🔑 ", Fallback: ""}
+	Warning          = Emoji{Emoji: "⚠️ ", Fallback: styles.ErrorExclamation, Label: "Warning:"}
+	Stopwatch        = Emoji{Emoji: "⏱️ ", Fallback: ""}
+	Clipboard        = Emoji{Emoji: "📋 ", Fallback: styles.CheckMark, Label: "OK:"}
+	Flag             = Emoji{Emoji: "🚩 ", Fallback: ""}
 )
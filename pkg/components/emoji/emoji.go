@@ -5,15 +5,24 @@ import (
 	"runtime"
 	"syscall"
 
+	"github.com/deta/space/pkg/components/styles"
 	"golang.org/x/term"
 )
 
 type Emoji struct {
 	Emoji    string
 	Fallback string
+	// Label replaces the emoji in the accessibility mode, decorative emoji have no label
+	Label string
 }
 
 func (e Emoji) String() string {
+	if styles.Accessible() {
+		if e.Label == "" {
+			return ""
+		}
+		return e.Label + " "
+	}
 
 	if SupportsEmoji() {
 		return e.Emoji
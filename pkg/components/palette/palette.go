@@ -0,0 +1,189 @@
+package palette
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/deta/space/internal/fuzzy"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/choose"
+	"github.com/deta/space/pkg/components/plain"
+	"github.com/deta/space/pkg/components/styles"
+)
+
+// maxRows is the number of items shown at once
+const maxRows = 10
+
+// Item is an entry of the palette
+type Item struct {
+	Title       string
+	Description string
+}
+
+type Model struct {
+	Filter    textinput.Model
+	Cursor    int
+	Chosen    bool
+	Cancelled bool
+	Prompt    string
+	Items     []Item
+	// Matches are the indices of the items matching the filter, the best match first
+	Matches []int
+}
+
+type Input struct {
+	Prompt string
+	Items  []Item
+}
+
+func initialModel(i *Input) Model {
+	ti := textinput.New()
+	ti.Placeholder = "type to filter"
+	ti.Focus()
+
+	m := Model{
+		Filter: ti,
+		Prompt: i.Prompt,
+		Items:  i.Items,
+	}
+	m.Matches = m.match()
+	return m
+}
+
+func (m Model) match() []int {
+	titles := make([]string, len(m.Items))
+	for i, item := range m.Items {
+		titles[i] = item.Title
+	}
+	return fuzzy.Filter(m.Filter.Value(), titles)
+}
+
+func (m Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Selection returns the index of the chosen item, -1 if no item matches
+func (m Model) Selection() int {
+	if m.Cursor >= len(m.Matches) {
+		return -1
+	}
+	return m.Matches[m.Cursor]
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEnter:
+			if m.Selection() < 0 {
+				return m, nil
+			}
+			m.Chosen = true
+			return m, tea.Quit
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.Cancelled = true
+			return m, tea.Quit
+		case tea.KeyDown, tea.KeyCtrlN:
+			m.Cursor++
+			if m.Cursor >= len(m.Matches) {
+				m.Cursor = 0
+			}
+			return m, nil
+		case tea.KeyUp, tea.KeyCtrlP:
+			m.Cursor--
+			if m.Cursor < 0 {
+				m.Cursor = len(m.Matches) - 1
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	query := m.Filter.Value()
+	m.Filter, cmd = m.Filter.Update(msg)
+	if m.Filter.Value() != query {
+		m.Matches = m.match()
+		m.Cursor = 0
+	}
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if m.Chosen {
+		return fmt.Sprintf("%s %s %s\n", styles.Question, styles.Bold(m.Prompt), m.Items[m.Selection()].Title)
+	}
+
+	s := fmt.Sprintf("%s %s %s\n", styles.Question, styles.Bold(m.Prompt), m.Filter.View())
+
+	// the rows scroll with the cursor
+	start := 0
+	if m.Cursor >= maxRows {
+		start = m.Cursor - maxRows + 1
+	}
+	end := start + maxRows
+	if end > len(m.Matches) {
+		end = len(m.Matches)
+	}
+	for i := start; i < end; i++ {
+		item := m.Items[m.Matches[i]]
+		row := item.Title
+		if item.Description != "" {
+			row += "  " + styles.Subtle(item.Description)
+		}
+		s += "\n" + choose.RenderChoice(row, i == m.Cursor)
+	}
+	if len(m.Matches) == 0 {
+		s += "\n  " + styles.Subtle("no matches")
+	}
+	s += "\n\n" + styles.Subtlef("%d/%d  ↑/↓ move  enter choose  esc cancel", len(m.Matches), len(m.Items)) + "\n"
+	return s
+}
+
+// Run asks to choose one of the items with a fuzzy filter and returns its index, the key names the prompt in the
+// answers
+func Run(key string, i *Input) (int, error) {
+	titles := make([]string, len(i.Items))
+	for n, item := range i.Items {
+		titles[n] = item.Title
+	}
+	var title string
+	var err error
+	if answers.Enabled() {
+		title, err = answers.Std().Choose(key, i.Prompt, titles...)
+	} else if styles.Accessible() {
+		title, err = plain.Std.Choose(i.Prompt, titles...)
+	} else {
+		return runProgram(i)
+	}
+	if err != nil {
+		return -1, err
+	}
+	for n, t := range titles {
+		if t == title {
+			return n, nil
+		}
+	}
+	return -1, fmt.Errorf("no item %s", title)
+}
+
+func runProgram(i *Input) (int, error) {
+	program := tea.NewProgram(initialModel(i))
+
+	m, err := program.Run()
+	if err != nil {
+		return -1, err
+	}
+
+	model, ok := m.(Model)
+	if !ok {
+		return -1, fmt.Errorf("invalid model type")
+	}
+
+	if model.Cancelled {
+		return -1, errors.New(i18n.T("components.cancelled"))
+	}
+
+	return model.Selection(), nil
+}
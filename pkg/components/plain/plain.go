@@ -0,0 +1,134 @@
+// Package plain asks questions line by line without redrawing the terminal, for screen readers in the accessibility
+// mode. The answers are read from stdin and the questions are written to stdout.
+package plain
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/deta/space/internal/i18n"
+	"golang.org/x/term"
+)
+
+// Prompter asks questions on a reader and a writer
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// password reads a line without echoing it, nil if the input isn't a terminal
+	password func() (string, error)
+}
+
+// Std asks on stdin and stdout, it is shared so that buffered answers aren't lost between questions
+var Std = newStd()
+
+func newStd() *Prompter {
+	p := New(os.Stdin, os.Stdout)
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		p.password = func() (string, error) {
+			b, err := term.ReadPassword(fd)
+			fmt.Fprintln(p.out)
+			return string(b), err
+		}
+	}
+	return p
+}
+
+// New returns a Prompter reading the answers from in and writing the questions to out
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// readLine reads an answer, the end of the input cancels the question
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return "", errors.New(i18n.T("components.cancelled"))
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes or no question, yes is the default
+func (p *Prompter) Confirm(prompt string) (bool, error) {
+	for {
+		fmt.Fprintf(p.out, "%s %s ", prompt, i18n.T("components.confirm_hint"))
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		// english answers are understood in every language
+		switch answer = strings.ToLower(answer); answer {
+		case "", "y", "yes", i18n.T("components.yes"):
+			return true, nil
+		case "n", "no", i18n.T("components.no"):
+			return false, nil
+		}
+		fmt.Fprintln(p.out, i18n.T("components.confirm_invalid"))
+	}
+}
+
+// Choose asks to choose one of the numbered choices, the first choice is the default
+func (p *Prompter) Choose(prompt string, choices ...string) (string, error) {
+	if len(choices) == 0 {
+		return "", errors.New("no choices")
+	}
+
+	fmt.Fprintln(p.out, prompt)
+	for i, choice := range choices {
+		fmt.Fprintf(p.out, "  %d. %s\n", i+1, choice)
+	}
+	for {
+		fmt.Fprintf(p.out, "%s ", i18n.T("components.choose_hint", len(choices)))
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return choices[0], nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		fmt.Fprintln(p.out, i18n.T("components.choose_invalid", len(choices)))
+	}
+}
+
+// Text asks for a value until it's valid, the placeholder is the default. A password isn't echoed if the input is
+// a terminal.
+func (p *Prompter) Text(prompt string, placeholder string, validator func(string) error, password bool) (string, error) {
+	for {
+		fmt.Fprint(p.out, prompt)
+		if placeholder != "" && !password {
+			fmt.Fprintf(p.out, " %s", i18n.T("components.text_default", placeholder))
+		}
+		fmt.Fprint(p.out, ": ")
+
+		var answer string
+		var err error
+		if password && p.password != nil {
+			answer, err = p.password()
+		} else {
+			answer, err = p.readLine()
+		}
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = placeholder
+		}
+		if validator != nil {
+			if err := validator(answer); err != nil {
+				fmt.Fprintln(p.out, i18n.T("components.error", err))
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
@@ -0,0 +1,67 @@
+package plain
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+		err      bool
+	}{
+		{input: "\n", expected: true},
+		{input: "yes\n", expected: true},
+		{input: "N\n", expected: false},
+		{input: "maybe\nno\n", expected: false},
+		{input: "", err: true},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		confirmed, err := New(strings.NewReader(c.input), &out).Confirm("Do you want to use the latest revision?")
+		if c.err {
+			assert.Assert(t, err != nil)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, confirmed, c.expected, c.input)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	var out bytes.Buffer
+	choice, err := New(strings.NewReader("4\n2\n"), &out).Choose("Choose a revision:", "quick-fox", "lazy-dog")
+	assert.NilError(t, err)
+	assert.Equal(t, choice, "lazy-dog")
+	assert.Equal(t, out.String(), `Choose a revision:
+  1. quick-fox
+  2. lazy-dog
+Enter the number of your choice, 1 to 2: Please enter a number from 1 to 2.
+Enter the number of your choice, 1 to 2: `)
+}
+
+func TestText(t *testing.T) {
+	validator := func(value string) error {
+		if len(value) < 4 {
+			return errors.New("project name must be at least 4 characters long")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	p := New(strings.NewReader("app\nmy-app\n\n"), &out)
+	name, err := p.Text("What is your project's name?", "module", validator, false)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "my-app")
+	assert.Assert(t, strings.Contains(out.String(), "Error: project name must be at least 4 characters long"))
+
+	name, err = p.Text("What is your project's name?", "module", validator, false)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "module")
+}
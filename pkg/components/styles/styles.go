@@ -2,8 +2,10 @@ package styles
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 var (
@@ -91,3 +93,41 @@ var (
 	ErrorExclamation = BoldStyle.Render(ErrorStyle.Render("!"))
 	Info             = BoldStyle.Render(Blue("i"))
 )
+
+var (
+	accessibleMu sync.Mutex
+	accessible   bool
+)
+
+// SetAccessible turns on the accessibility mode, the output is plain text without colors and the marks are words,
+// so that nothing is signaled by color alone
+func SetAccessible(enabled bool) {
+	accessibleMu.Lock()
+	defer accessibleMu.Unlock()
+	accessible = enabled
+	if !enabled {
+		return
+	}
+
+	lipgloss.SetColorProfile(termenv.Ascii)
+	Question = "?"
+	SelectTag = ">"
+	CheckMark = "ok"
+	X = "failed"
+	ErrorExclamation = "error"
+	Info = "info"
+}
+
+// SetNoColor turns off the colors of the output, bold text and emoji are kept
+func SetNoColor(disabled bool) {
+	if disabled {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// Accessible reports if the accessibility mode is on
+func Accessible() bool {
+	accessibleMu.Lock()
+	defer accessibleMu.Unlock()
+	return accessible
+}
@@ -1,10 +1,14 @@
 package text
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/deta/space/internal/i18n"
+	"github.com/deta/space/pkg/components/answers"
+	"github.com/deta/space/pkg/components/plain"
 	"github.com/deta/space/pkg/components/styles"
 )
 
@@ -17,6 +21,8 @@ type Model struct {
 }
 
 type Input struct {
+	// Key names the prompt in the answers
+	Key          string
 	Prompt       string
 	Placeholder  string
 	Validator    func(value string) error
@@ -64,7 +70,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				err := m.Validator(value)
 				if err != nil {
-					m.ValidationMsg = fmt.Sprintf("❗ Error: %s", err.Error())
+					m.ValidationMsg = "❗ " + i18n.T("components.error", err.Error())
 					return m, nil
 				}
 				m.ValidationMsg = ""
@@ -88,7 +94,7 @@ func (m Model) View() string {
 			"%s %s (%s) %s\n",
 			styles.Question,
 			styles.Bold(m.Prompt),
-			fmt.Sprintf("%d chars", len(m.TextInput.Value())),
+			i18n.T("components.chars", len(m.TextInput.Value())),
 			m.TextInput.View(),
 		)
 	} else {
@@ -106,6 +112,13 @@ func (m Model) View() string {
 }
 
 func Run(i *Input) (string, error) {
+	if answers.Enabled() {
+		return answers.Std().Text(i.Key, i.Prompt, i.Placeholder, i.Validator, i.PasswordMode)
+	}
+	if styles.Accessible() {
+		return plain.Std.Text(i.Prompt, i.Placeholder, i.Validator, i.PasswordMode)
+	}
+
 	program := tea.NewProgram(initialModel(i))
 
 	m, err := program.Run()
@@ -119,7 +132,7 @@ func Run(i *Input) (string, error) {
 	}
 
 	if model.Cancelled {
-		return "", fmt.Errorf("cancelled")
+		return "", errors.New(i18n.T("components.cancelled"))
 	}
 
 	return model.Value(), nil
@@ -0,0 +1,94 @@
+// Package tree lays out slash separated paths as a tree like the tree command, directories are listed before files
+package tree
+
+import (
+	"sort"
+	"strings"
+)
+
+// Entry is a path of the tree
+type Entry struct {
+	Path string
+	Size int64
+	// Excluded entries don't count towards the size of their directories, e.g. files left out of an archive
+	Excluded bool
+}
+
+// Line is a line of the tree
+type Line struct {
+	// Prefix draws the branches, e.g. "│   ├── "
+	Prefix string
+	Name   string
+	// Size is the size of the entry, or the sum of the entries in it which aren't excluded for a directory
+	Size int64
+	Dir  bool
+	// Entry is nil for directories which aren't entries themselves
+	Entry *Entry
+}
+
+type node struct {
+	name     string
+	size     int64
+	entry    *Entry
+	children map[string]*node
+}
+
+func (n *node) child(name string) *node {
+	if n.children == nil {
+		n.children = make(map[string]*node)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &node{name: name}
+		n.children[name] = c
+	}
+	return c
+}
+
+// Lines returns the lines of the tree of the entries, without a line for the root
+func Lines(entries []*Entry) []*Line {
+	root := &node{}
+	for _, e := range entries {
+		n := root
+		for _, part := range strings.Split(strings.Trim(e.Path, "/"), "/") {
+			if !e.Excluded {
+				n.size += e.Size
+			}
+			n = n.child(part)
+		}
+		n.entry = e
+		if !e.Excluded {
+			n.size += e.Size
+		}
+	}
+
+	var lines []*Line
+	var walk func(n *node, indent string)
+	walk = func(n *node, indent string) {
+		children := make([]*node, 0, len(n.children))
+		for _, c := range n.children {
+			children = append(children, c)
+		}
+		sort.Slice(children, func(i, j int) bool {
+			if (children[i].children != nil) != (children[j].children != nil) {
+				return children[i].children != nil
+			}
+			return children[i].name < children[j].name
+		})
+
+		for i, c := range children {
+			branch, next := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, next = "└── ", "    "
+			}
+			line := &Line{Prefix: indent + branch, Name: c.name, Size: c.size, Dir: c.children != nil, Entry: c.entry}
+			if !line.Dir && c.entry != nil {
+				line.Size = c.entry.Size
+			}
+			lines = append(lines, line)
+			walk(c, indent+next)
+		}
+	}
+	walk(root, "")
+	return lines
+}
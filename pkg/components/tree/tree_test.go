@@ -0,0 +1,40 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLines(t *testing.T) {
+	entries := []*Entry{
+		{Path: "main.py", Size: 10},
+		{Path: "web/src/app.js", Size: 100},
+		{Path: "web/.npmrc", Size: 5},
+		{Path: "node_modules", Excluded: true},
+		{Path: "web/debug.log", Size: 1000, Excluded: true},
+		{Path: "api/main.go", Size: 20},
+	}
+
+	var out []string
+	for _, l := range Lines(entries) {
+		out = append(out, fmt.Sprintf("%s%s %d", l.Prefix, l.Name, l.Size))
+	}
+	assert.Equal(t, strings.Join(out, "\n"), strings.Join([]string{
+		"├── api 20",
+		"│   └── main.go 20",
+		"├── web 105",
+		"│   ├── src 100",
+		"│   │   └── app.js 100",
+		"│   ├── .npmrc 5",
+		"│   └── debug.log 1000",
+		"├── main.py 10",
+		"└── node_modules 0",
+	}, "\n"))
+
+	lines := Lines(entries)
+	assert.Assert(t, lines[0].Dir && lines[0].Entry == nil)
+	assert.Assert(t, !lines[len(lines)-1].Dir && lines[len(lines)-1].Entry.Excluded)
+}
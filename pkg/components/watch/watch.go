@@ -0,0 +1,164 @@
+// Package watch refreshes a list of fields every interval and highlights the values which changed since the previous
+// refresh, so that a status can be followed without running a command in a loop
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/deta/space/pkg/components/styles"
+	"github.com/mattn/go-isatty"
+)
+
+// Field is a labeled value of the watched status
+type Field struct {
+	Label string
+	Value string
+}
+
+// Changed returns the labels of the fields of cur whose value differs from the field with the same label in prev, new
+// fields are changed too. Nothing changed without a previous refresh.
+func Changed(prev []Field, cur []Field) map[string]bool {
+	changed := make(map[string]bool)
+	if prev == nil {
+		return changed
+	}
+	values := make(map[string]string, len(prev))
+	for _, f := range prev {
+		values[f.Label] = f.Value
+	}
+	for _, f := range cur {
+		if value, ok := values[f.Label]; !ok || value != f.Value {
+			changed[f.Label] = true
+		}
+	}
+	return changed
+}
+
+type Input struct {
+	Title    string
+	Interval time.Duration
+	// Fetch returns the current fields, a failed refresh keeps the previous fields
+	Fetch func() ([]Field, error)
+}
+
+type refreshMsg struct {
+	fields []Field
+	err    error
+	at     time.Time
+}
+
+type tickMsg struct{}
+
+type Model struct {
+	input   *Input
+	fields  []Field
+	changed map[string]bool
+	err     error
+	updated time.Time
+}
+
+func (m Model) refresh() tea.Msg {
+	fields, err := m.input.Fetch()
+	return refreshMsg{fields: fields, err: err, at: time.Now()}
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.refresh
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	case refreshMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.changed = Changed(m.fields, msg.fields)
+			m.fields = msg.fields
+			m.updated = msg.at
+		}
+		return m, tea.Tick(m.input.Interval, func(time.Time) tea.Msg {
+			return tickMsg{}
+		})
+	case tickMsg:
+		return m, m.refresh
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	var b strings.Builder
+	status := fmt.Sprintf("every %s, press q to quit", m.input.Interval)
+	if !m.updated.IsZero() {
+		status = fmt.Sprintf("every %s, updated %s, press q to quit", m.input.Interval, m.updated.Format("15:04:05"))
+	}
+	fmt.Fprintf(&b, "%s %s\n\n", styles.Bold(m.input.Title), styles.Subtle(status))
+
+	for _, f := range m.fields {
+		if m.changed[f.Label] {
+			fmt.Fprintf(&b, "%s %s: %s\n", styles.Green("»"), f.Label, styles.Green(f.Value))
+		} else {
+			fmt.Fprintf(&b, "  %s: %s\n", f.Label, f.Value)
+		}
+	}
+	if m.err != nil {
+		fmt.Fprintf(&b, "\n%s\n", styles.Errorf("Failed to refresh: %v", m.err))
+	}
+	return b.String()
+}
+
+// Run refreshes the fields every interval until it's quit. In the accessibility mode or without a terminal the fields
+// are printed once and then only the changed fields are printed line by line.
+func Run(input *Input) error {
+	if styles.Accessible() || !isatty.IsTerminal(os.Stdout.Fd()) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return runPlain(ctx, os.Stdout, input)
+	}
+
+	_, err := tea.NewProgram(Model{input: input}).Run()
+	return err
+}
+
+func runPlain(ctx context.Context, w io.Writer, input *Input) error {
+	fmt.Fprintf(w, "%s, every %s\n", input.Title, input.Interval)
+
+	var prev []Field
+	for {
+		fields, err := input.Fetch()
+		at := time.Now().Format("15:04:05")
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "%s Failed to refresh: %v\n", at, err)
+		case prev == nil:
+			for _, f := range fields {
+				fmt.Fprintf(w, "%s %s: %s\n", at, f.Label, f.Value)
+			}
+			prev = fields
+		default:
+			changed := Changed(prev, fields)
+			for _, f := range fields {
+				if changed[f.Label] {
+					fmt.Fprintf(w, "%s %s changed: %s\n", at, f.Label, f.Value)
+				}
+			}
+			prev = fields
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(input.Interval):
+		}
+	}
+}
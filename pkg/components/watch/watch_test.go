@@ -0,0 +1,75 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestChanged(t *testing.T) {
+	cases := []struct {
+		name     string
+		prev     []Field
+		cur      []Field
+		expected map[string]bool
+	}{
+		{
+			name:     "first refresh",
+			cur:      []Field{{Label: "Latest revision", Value: "v1"}},
+			expected: map[string]bool{},
+		},
+		{
+			name:     "unchanged",
+			prev:     []Field{{Label: "Latest revision", Value: "v1"}},
+			cur:      []Field{{Label: "Latest revision", Value: "v1"}},
+			expected: map[string]bool{},
+		},
+		{
+			name:     "new revision",
+			prev:     []Field{{Label: "Latest revision", Value: "v1"}, {Label: "Promotion", Value: "complete"}},
+			cur:      []Field{{Label: "Latest revision", Value: "v2"}, {Label: "Promotion", Value: "complete"}},
+			expected: map[string]bool{"Latest revision": true},
+		},
+		{
+			name:     "new field",
+			prev:     []Field{{Label: "Latest revision", Value: "v1"}},
+			cur:      []Field{{Label: "Latest revision", Value: "v1"}, {Label: "Promotion", Value: "started"}},
+			expected: map[string]bool{"Promotion": true},
+		},
+	}
+
+	for _, c := range cases {
+		assert.DeepEqual(t, Changed(c.prev, c.cur), c.expected)
+	}
+}
+
+func TestRunPlain(t *testing.T) {
+	states := []string{"started", "started", "complete"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := 0
+	input := &Input{
+		Title:    "my-app",
+		Interval: time.Millisecond,
+		Fetch: func() ([]Field, error) {
+			fields := []Field{{Label: "Promotion", Value: states[n]}}
+			if n++; n == len(states) {
+				cancel()
+			}
+			return fields, nil
+		},
+	}
+
+	var out bytes.Buffer
+	assert.NilError(t, runPlain(ctx, &out, input))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, len(lines), 3)
+	assert.Equal(t, lines[0], "my-app, every 1ms")
+	assert.Assert(t, strings.HasSuffix(lines[1], " Promotion: started"))
+	assert.Assert(t, strings.HasSuffix(lines[2], " Promotion changed: complete"))
+}
@@ -0,0 +1,184 @@
+// Package spacefile loads, validates, edits and writes Spacefiles.
+//
+// A Spacefile keeps the yaml document it was loaded from. When it is written again, only the values that changed are
+// updated in the document, so that comments, the order of fields and yaml anchors of unchanged values are kept.
+package spacefile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	internal "github.com/deta/space/internal/spacefile"
+	"github.com/deta/space/shared"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the Spacefile in a project
+const FileName = internal.SpacefileName
+
+var (
+	// ErrMicroExists is returned by AddMicro if a micro with the same name exists
+	ErrMicroExists = errors.New("micro already exists")
+	// ErrMicroNotFound is returned by the mutation helpers if the micro doesn't exist
+	ErrMicroNotFound = errors.New("micro not found")
+)
+
+// Spacefile is the typed content of a Spacefile, the fields can be changed directly or with the mutation helpers
+type Spacefile struct {
+	V       int             `yaml:"v"`
+	Icon    string          `yaml:"icon,omitempty"`
+	AppName string          `yaml:"app_name,omitempty"`
+	Micros  []*shared.Micro `yaml:"micros,omitempty"`
+
+	doc *yaml.Node
+}
+
+// New returns an empty Spacefile
+func New() *Spacefile {
+	return &Spacefile{}
+}
+
+// Load reads the Spacefile at path, it isn't validated
+func Load(path string) (*Spacefile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Parse decodes the content of a Spacefile, it isn't validated
+func Parse(content []byte) (*Spacefile, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %s", internal.ErrInvalidSpacefile, err)
+	}
+
+	s := &Spacefile{}
+	if doc.Kind == 0 {
+		return s, nil
+	}
+	if err := doc.Decode(s); err != nil {
+		return nil, fmt.Errorf("%w: %s", internal.ErrInvalidSpacefile, err)
+	}
+	s.doc = &doc
+	return s, nil
+}
+
+// Validate checks the Spacefile against the schema, a *ValidationError lists the issues if the schema doesn't match.
+// Includes are not resolved and the sources of the micros are not checked.
+func (s *Spacefile) Validate() error {
+	content, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	return internal.Validate(content)
+}
+
+// ValidationError lists the issues of an invalid Spacefile with their lines
+type ValidationError = internal.ValidationError
+
+// Issue is a single problem of an invalid Spacefile
+type Issue = internal.Issue
+
+// Bytes returns the yaml content of the Spacefile
+func (s *Spacefile) Bytes() ([]byte, error) {
+	var updated yaml.Node
+	if err := updated.Encode(s); err != nil {
+		return nil, fmt.Errorf("failed to encode Spacefile: %w", err)
+	}
+
+	if s.doc == nil {
+		s.doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{pruneZero(&updated)}}
+	} else {
+		if err := syncNode(documentRoot(s.doc), &updated, true); err != nil {
+			return nil, err
+		}
+	}
+
+	plainMergeKeys(s.doc)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s.doc); err != nil {
+		return nil, fmt.Errorf("failed to encode Spacefile: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode Spacefile: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the Spacefile to path
+func (s *Spacefile) Write(path string) error {
+	content, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// Micro returns the micro with the name or nil
+func (s *Spacefile) Micro(name string) *shared.Micro {
+	for _, micro := range s.Micros {
+		if micro.Name == name {
+			return micro
+		}
+	}
+	return nil
+}
+
+// AddMicro appends a micro, it becomes the primary micro if it's the first one
+func (s *Spacefile) AddMicro(micro *shared.Micro) error {
+	if s.Micro(micro.Name) != nil {
+		return fmt.Errorf("%w: %s", ErrMicroExists, micro.Name)
+	}
+	if len(s.Micros) == 0 {
+		micro.Primary = true
+	}
+	s.Micros = append(s.Micros, micro)
+	return nil
+}
+
+// RemoveMicro removes the micro with the name
+func (s *Spacefile) RemoveMicro(name string) error {
+	for i, micro := range s.Micros {
+		if micro.Name == name {
+			s.Micros = append(s.Micros[:i], s.Micros[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMicroNotFound, name)
+}
+
+// SetEngine changes the engine of a micro
+func (s *Spacefile) SetEngine(microName string, engine string) error {
+	micro := s.Micro(microName)
+	if micro == nil {
+		return fmt.Errorf("%w: %s", ErrMicroNotFound, microName)
+	}
+	micro.Engine = engine
+	return nil
+}
+
+// SetEnv adds an environment variable preset to a micro or updates the default value of an existing one
+func (s *Spacefile) SetEnv(microName string, name string, defaultValue string) error {
+	micro := s.Micro(microName)
+	if micro == nil {
+		return fmt.Errorf("%w: %s", ErrMicroNotFound, microName)
+	}
+	if micro.Presets == nil {
+		micro.Presets = &shared.Presets{}
+	}
+	for i := range micro.Presets.Env {
+		if micro.Presets.Env[i].Name == name {
+			micro.Presets.Env[i].Default = defaultValue
+			return nil
+		}
+	}
+	micro.Presets.Env = append(micro.Presets.Env, shared.Environment{Name: name, Default: defaultValue})
+	return nil
+}
@@ -0,0 +1,119 @@
+package spacefile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deta/space/shared"
+	"gotest.tools/v3/assert"
+)
+
+func TestRoundTrip(t *testing.T) {
+	raw, err := os.ReadFile("testdata/Spacefile")
+	assert.NilError(t, err)
+
+	s, err := Parse(raw)
+	assert.NilError(t, err)
+	assert.Equal(t, len(s.Micros), 2)
+	assert.Equal(t, s.Micro("api").Engine, "python3.9")
+
+	content, err := s.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), string(raw))
+}
+
+func TestMutations(t *testing.T) {
+	s, err := Load("testdata/Spacefile")
+	assert.NilError(t, err)
+
+	assert.NilError(t, s.SetEnv("frontend", "API_URL", "/api"))
+	assert.NilError(t, s.AddMicro(&shared.Micro{Name: "worker", Src: "./worker", Engine: "nodejs16"}))
+	assert.ErrorIs(t, s.AddMicro(&shared.Micro{Name: "worker"}), ErrMicroExists)
+	assert.ErrorIs(t, s.SetEnv("unknown", "API_URL", ""), ErrMicroNotFound)
+	s.Micro("frontend").Serve = "build"
+
+	path := filepath.Join(t.TempDir(), FileName)
+	assert.NilError(t, s.Write(path))
+	content, err := os.ReadFile(path)
+	assert.NilError(t, err)
+
+	expected := `# Spacefile Docs: https://go.deta.dev/docs/spacefile/v0
+v: 0
+x-python: &python
+  src: ./api
+  engine: python3.9
+micros:
+  # the frontend is served from the root
+  - name: frontend
+    src: ./frontend
+    engine: static
+    serve: build
+    primary: true
+    presets:
+      env:
+        - name: API_URL
+          default: /api
+  - name: api
+    <<: *python
+    public: false # only the frontend calls the api
+  - name: worker
+    src: ./worker
+    engine: nodejs16
+`
+	assert.Equal(t, string(content), expected)
+
+	reloaded, err := Load(path)
+	assert.NilError(t, err)
+	assert.Equal(t, len(reloaded.Micros), 3)
+	assert.Equal(t, reloaded.Micro("frontend").Presets.Env[0].Default, "/api")
+}
+
+func TestValidate(t *testing.T) {
+	s := New()
+	s.AddMicro(&shared.Micro{Name: "api", Src: ".", Engine: "python3.9"})
+	assert.NilError(t, s.Validate())
+
+	s.AddMicro(&shared.Micro{Name: "worker", Src: "worker", Engine: "unknown"})
+	var ve *ValidationError
+	assert.Assert(t, errors.As(s.Validate(), &ve))
+	assert.Assert(t, len(ve.Issues) > 0)
+}
+
+func TestNew(t *testing.T) {
+	s := New()
+	assert.NilError(t, s.AddMicro(&shared.Micro{Name: "api", Src: ".", Engine: "python3.9"}))
+
+	content, err := s.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `v: 0
+micros:
+  - name: api
+    src: .
+    engine: python3.9
+    primary: true
+`)
+}
+
+func TestSetEngine(t *testing.T) {
+	raw := `# Spacefile Docs: https://go.deta.dev/docs/spacefile/v0
+v: 0
+micros:
+  - name: backend
+    src: backend
+    engine: python3.8 # keep this comment
+  - name: frontend
+    src: frontend
+    engine: nodejs16
+`
+	s, err := Parse([]byte(raw))
+	assert.NilError(t, err)
+	assert.NilError(t, s.SetEngine("backend", "python3.9"))
+	assert.ErrorIs(t, s.SetEngine("api", "python3.9"), ErrMicroNotFound)
+
+	content, err := s.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), strings.Replace(raw, "python3.8", "python3.9", 1))
+}
@@ -0,0 +1,203 @@
+package spacefile
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// mergeKey is the yaml key to merge a mapping from an anchor
+	mergeKey = "<<"
+	// versionKey is required although its only value is zero
+	versionKey = "v"
+)
+
+// keptKey reports if a field is kept in the document although it's not part of the typed Spacefile,
+// includes and extension fields are only known at the top level
+func keptKey(key string, top bool) bool {
+	return key == mergeKey || top && (key == "include" || strings.HasPrefix(key, "x-"))
+}
+
+func documentRoot(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		return n.Content[0]
+	}
+	return n
+}
+
+// mappingValue returns the value of key in a mapping node or nil
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// syncNode updates dst to hold the values of src, nodes with unchanged values are kept as they are
+func syncNode(dst *yaml.Node, src *yaml.Node, top bool) error {
+	equal, err := equalValues(dst, src)
+	if err != nil || equal {
+		return err
+	}
+
+	if dst.Kind != src.Kind || dst.Kind == yaml.AliasNode {
+		head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+		*dst = *src
+		dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+		return nil
+	}
+
+	switch dst.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			if existing := mappingValue(dst, key.Value); existing != nil {
+				if err := syncNode(existing, value, false); err != nil {
+					return err
+				}
+				continue
+			}
+			zero, err := isZero(value)
+			if err != nil {
+				return err
+			}
+			if !zero || top && key.Value == versionKey {
+				dst.Content = append(dst.Content, key, pruneZero(value))
+			}
+		}
+
+		// remove the fields which were cleared, explicit zero values are left as they are
+		content := make([]*yaml.Node, 0, len(dst.Content))
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			key := dst.Content[i].Value
+			zero, err := isZero(dst.Content[i+1])
+			if err != nil {
+				return err
+			}
+			if zero || keptKey(key, top) || mappingValue(src, key) != nil {
+				content = append(content, dst.Content[i], dst.Content[i+1])
+			}
+		}
+		dst.Content = content
+	case yaml.SequenceNode:
+		for i, item := range src.Content {
+			if i >= len(dst.Content) {
+				dst.Content = append(dst.Content, pruneZero(item))
+				continue
+			}
+			if err := syncNode(dst.Content[i], item, false); err != nil {
+				return err
+			}
+		}
+		dst.Content = dst.Content[:len(src.Content)]
+	case yaml.ScalarNode:
+		if dst.Tag != src.Tag {
+			dst.Style = src.Style
+		}
+		dst.Value, dst.Tag = src.Value, src.Tag
+	default:
+		return fmt.Errorf("unexpected yaml node on line %d", dst.Line)
+	}
+	return nil
+}
+
+// pruneZero removes the fields with zero values from new nodes, as the typed micros encode them even if they are unset
+func pruneZero(n *yaml.Node) *yaml.Node {
+	switch n.Kind {
+	case yaml.MappingNode:
+		content := make([]*yaml.Node, 0, len(n.Content))
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if zero, err := isZero(n.Content[i+1]); err == nil && zero && n.Content[i].Value != versionKey {
+				continue
+			}
+			content = append(content, n.Content[i], pruneZero(n.Content[i+1]))
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			n.Content[i] = pruneZero(item)
+		}
+	}
+	return n
+}
+
+// plainMergeKeys drops the explicit tags of merge keys, the encoder writes them as !!merge otherwise
+func plainMergeKeys(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if key := n.Content[i]; key.Value == mergeKey && key.Tag == "!!merge" {
+				key.Tag = ""
+			}
+		}
+	}
+	for _, c := range n.Content {
+		plainMergeKeys(c)
+	}
+}
+
+// equalValues compares the decoded values of two nodes, fields with zero values are treated as unset
+func equalValues(a *yaml.Node, b *yaml.Node) (bool, error) {
+	var va, vb any
+	if err := a.Decode(&va); err != nil {
+		return false, err
+	}
+	if err := b.Decode(&vb); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(withoutZero(va), withoutZero(vb)), nil
+}
+
+func isZero(n *yaml.Node) (bool, error) {
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return false, err
+	}
+	return withoutZero(v) == nil, nil
+}
+
+// withoutZero removes zero values from decoded yaml, it returns nil if the value itself is zero
+func withoutZero(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any)
+		for key, value := range v {
+			if value := withoutZero(value); value != nil {
+				m[key] = value
+			}
+		}
+		if len(m) == 0 {
+			return nil
+		}
+		return m
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = withoutZero(item)
+		}
+		return items
+	case string:
+		if v == "" {
+			return nil
+		}
+	case bool:
+		if !v {
+			return nil
+		}
+	case int:
+		if v == 0 {
+			return nil
+		}
+	}
+	return v
+}
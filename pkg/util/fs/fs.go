@@ -120,3 +120,17 @@ func CheckIfAnyFileExists(dir string, filenames ...string) (bool, error) {
 	}
 	return false, nil
 }
+
+// FormatSize formats a size in bytes in a human readable way using powers of 1024, e.g. 1.5 MB
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
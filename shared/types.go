@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 
 	"github.com/deta/space/pkg/writer"
 	"mvdan.cc/sh/v3/shell"
@@ -117,6 +118,28 @@ type Action struct {
 	Path        string `yaml:"path"`
 }
 
+// StaticConfig is how a micro with a static, react, vue or svelte engine serves its files. Only the proxy of
+// space dev applies it, deployed micros serve their files without it.
+type StaticConfig struct {
+	// SPA serves the index.html for paths without an extension which aren't files, for client side routing
+	SPA       bool         `yaml:"spa,omitempty"`
+	Headers   []HeaderRule `yaml:"headers,omitempty"`
+	Redirects []Redirect   `yaml:"redirects,omitempty"`
+}
+
+// HeaderRule sets headers on the responses to the paths matching Source
+type HeaderRule struct {
+	Source  string            `yaml:"source"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Redirect redirects the paths matching Source to Destination, with a 301 if Status is 0
+type Redirect struct {
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination"`
+	Status      int    `yaml:"status,omitempty"`
+}
+
 // Micro xx
 type Micro struct {
 	Name         string   `yaml:"name"`
@@ -130,10 +153,16 @@ type Micro struct {
 	Runtime      string   `yaml:"runtime,omitempty"`
 	Commands     []string `yaml:"commands,omitempty"`
 	Include      []string `yaml:"include,omitempty"`
-	Actions      []Action `yaml:"actions,omitempty"`
-	Serve        string   `yaml:"serve,omitempty"`
-	Run          string   `yaml:"run,omitempty"`
-	Dev          string   `yaml:"dev,omitempty"`
+	// Artifacts are gitignore patterns of the files pushed by space push --prebuilt, relative to the src
+	Artifacts []string `yaml:"artifacts,omitempty"`
+	// BuildArgs are the names of environment variables forwarded from the environment of the push to the build
+	BuildArgs []string `yaml:"build_args,omitempty"`
+	Actions   []Action `yaml:"actions,omitempty"`
+	Serve     string   `yaml:"serve,omitempty"`
+	Run       string   `yaml:"run,omitempty"`
+	Dev       string   `yaml:"dev,omitempty"`
+	// Static configures how the proxy of space dev serves the files of the micro
+	Static *StaticConfig `yaml:"static,omitempty"`
 }
 
 func (m Micro) Type() string {
@@ -143,6 +172,17 @@ func (m Micro) Type() string {
 	return "normal"
 }
 
+// Route returns the path the micro is served on in the app, the primary micro is served on the root
+func (m Micro) Route() string {
+	if m.Primary {
+		return "/"
+	}
+	if m.Path != "" {
+		return "/" + strings.Trim(m.Path, "/")
+	}
+	return "/" + m.Name
+}
+
 var ErrNoDevCommand = errors.New("no dev command found for micro")
 
 func (micro *Micro) Command(directory, projectKey string, port int) (*exec.Cmd, error) {